@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -26,7 +27,10 @@ type ProductName string
 type ProductVersion string
 type OperatorVersion string
 type PreflightStatus string
+type PreflightCheckSeverity string
+type QuotaChangePhase string
 type StageName string
+type NetworkPolicyMode string
 
 var (
 	PhaseNone                   StatusPhase = ""
@@ -47,6 +51,10 @@ var (
 	InstallationTypeSelfManaged           InstallationType = "self-managed"
 	InstallationTypeMultitenantManagedApi InstallationType = "multitenant-managed-api"
 
+	NetworkPolicyModeAudit    NetworkPolicyMode = "audit"
+	NetworkPolicyModeEnforce  NetworkPolicyMode = "enforce"
+	NetworkPolicyModeDisabled NetworkPolicyMode = "disabled"
+
 	BootstrapStage               StageName = "bootstrap"
 	CloudResourcesStage          StageName = "cloud-resources"
 	MonitoringStage              StageName = "monitoring"
@@ -115,6 +123,16 @@ var (
 	PreflightSuccess    PreflightStatus = "successful"
 	PreflightFail       PreflightStatus = "failed"
 
+	// Preflight checks that are blocking stop the installation when they
+	// fail, the warnings are reported only
+	PreflightSeverityBlocking PreflightCheckSeverity = "blocking"
+	PreflightSeverityWarning  PreflightCheckSeverity = "warning"
+
+	// A change of quota is blocked while the cluster doesn't have the
+	// capacity for it, then rolled out one workload at a time
+	QuotaChangeBlocked    QuotaChangePhase = "blocked"
+	QuotaChangeInProgress QuotaChangePhase = "in progress"
+
 	// Operator image tags
 	OperatorVersionAMQStreams       OperatorVersion = "1.1.0"
 	OperatorVersionAMQOnline        OperatorVersion = "1.4"
@@ -184,6 +202,12 @@ type RHMISpec struct {
 	// password
 	SMTPSecret string `json:"smtpSecret,omitempty"`
 
+	// SMTPFailoverSecrets are the names of secrets in the installation
+	// namespace containing the SMTP connection details of fallback
+	// providers, in order of preference, with the same fields as the
+	// SMTPSecret. Email is sent with the first healthy provider
+	SMTPFailoverSecrets []string `json:"smtpFailoverSecrets,omitempty"`
+
 	// PagerDutySecret is the name of a secret in the
 	// installation namespace containing PagerDuty account
 	// details. The secret must contain the following fields:
@@ -198,6 +222,31 @@ type RHMISpec struct {
 	//
 	// url
 	DeadMansSnitchSecret string `json:"deadMansSnitchSecret,omitempty"`
+
+	// SecretRotation overrides the rotation policies of the secrets
+	// generated by the operator. Secrets not listed keep their default
+	// policy. The oauth-client-secrets, grafana-k8s-proxy,
+	// tenant-account-passwords, credential-rhsso and credential-rhssouser
+	// secrets can be rotated
+	SecretRotation []SecretRotationPolicy `json:"secretRotation,omitempty"`
+
+	// NetworkPolicyMode is how the NetworkPolicies isolating the RHOAM
+	// namespaces are reconciled. In audit mode, the default, the flows the
+	// policies would block are reported without creating the policies. In
+	// enforce mode the policies are created, and in disabled mode they are
+	// removed
+	// +kubebuilder:validation:Enum=audit;enforce;disabled
+	NetworkPolicyMode NetworkPolicyMode `json:"networkPolicyMode,omitempty"`
+}
+
+// SecretRotationPolicy is the rotation policy of a secret generated by the
+// operator
+type SecretRotationPolicy struct {
+	// Secret is the name of the secret the policy applies to
+	Secret string `json:"secret"`
+	// MaxAgeDays is the age after which the values of the secret are
+	// rotated. 0 disables the rotation
+	MaxAgeDays int32 `json:"maxAgeDays"`
 }
 
 type PullSecretSpec struct {
@@ -225,6 +274,67 @@ type RHMIStatus struct {
 	ToVersion          string                        `json:"toVersion,omitempty"`
 	Quota              string                        `json:"quota,omitempty"`
 	ToQuota            string                        `json:"toQuota,omitempty"`
+
+	// PreflightChecks are the results of the last run of the preflight
+	// checks
+	PreflightChecks []PreflightCheckResult `json:"preflightChecks,omitempty"`
+
+	// QuotaChange is the progress of the change from the Quota to the
+	// ToQuota, while it's blocked or rolling out
+	QuotaChange *QuotaChangeStatus `json:"quotaChange,omitempty"`
+
+	// CapacityForecasts are the estimated number of days until the Postgres
+	// instances run out of storage and the Redis instances run out of
+	// memory. Instances whose usage is not growing are not listed
+	CapacityForecasts []CapacityForecast `json:"capacityForecasts,omitempty"`
+
+	// SMTPActiveSecret is the name of the SMTP secret email is sent with,
+	// the first healthy provider of the SMTPSecret and SMTPFailoverSecrets
+	SMTPActiveSecret string `json:"smtpActiveSecret,omitempty"`
+
+	// Conditions of the installation
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// PreflightCheckResult is the result of a preflight check of the
+// installation
+type PreflightCheckResult struct {
+	Name string `json:"name"`
+	// Severity of the check, the installation doesn't start while a blocking
+	// check fails
+	// +kubebuilder:validation:Enum=blocking;warning
+	Severity PreflightCheckSeverity `json:"severity"`
+	Passed   bool                   `json:"passed"`
+	Message  string                 `json:"message,omitempty"`
+}
+
+// QuotaChangeStatus is the progress of a change of quota. The resources of
+// the new quota are applied to the workloads of the products one at a time,
+// each once the previous one is ready
+type QuotaChangeStatus struct {
+	From    string           `json:"from"`
+	To      string           `json:"to"`
+	Phase   QuotaChangePhase `json:"phase"`
+	Message string           `json:"message,omitempty"`
+	// RequestsDelta is the change of the CPU and memory requested by the
+	// products
+	RequestsDelta corev1.ResourceList `json:"requestsDelta,omitempty"`
+	// RolledOut are the workloads ready with the resources of the new quota
+	RolledOut []string `json:"rolledOut,omitempty"`
+}
+
+// CapacityForecast is the capacity forecast of a cloud resource, projected
+// from the growth of its usage over the last 6 hours
+type CapacityForecast struct {
+	// Type of the cloud resource, postgres or redis
+	Type          string `json:"type"`
+	Name          string `json:"name"`
+	Namespace     string `json:"namespace"`
+	ProductName   string `json:"productName,omitempty"`
+	DaysUntilFull int32  `json:"daysUntilFull"`
+
+	// AllocatedStorage of Postgres instances in GiB
+	AllocatedStorage int64 `json:"allocatedStorage,omitempty"`
 }
 
 type RHMIStageStatus struct {
@@ -242,6 +352,9 @@ type RHMIProductStatus struct {
 	Mobile          bool            `json:"mobile,omitempty"`
 	Phase           StatusPhase     `json:"status"`
 	Uninstall       bool            `json:"uninstall,omitempty"`
+
+	// PhaseTransitionTime is the time the product entered its phase
+	PhaseTransitionTime *metav1.Time `json:"phaseTransitionTime,omitempty"`
 }
 
 // +kubebuilder:object:root=true
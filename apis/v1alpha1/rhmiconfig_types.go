@@ -21,8 +21,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"net/url"
 	"reflect"
+	"regexp"
 	"strings"
 	"time"
 
@@ -48,6 +51,26 @@ type RHMIConfigSpec struct {
 	Upgrade     Upgrade     `json:"upgrade,omitempty"`
 	Maintenance Maintenance `json:"maintenance,omitempty"`
 	Backup      Backup      `json:"backup,omitempty"`
+
+	// UserRoles grant 3scale and RHSSO roles to users, on top of the admin
+	// roles granted to the members of the dedicated-admins group
+	// +optional
+	UserRoles []UserRoleMapping `json:"userRoles,omitempty"`
+
+	// IdentityProviders are external identity providers federated in the
+	// RHSSO and user SSO realms, alongside the OpenShift identity provider
+	// +optional
+	IdentityProviders []IdentityProvider `json:"identityProviders,omitempty"`
+
+	// Branding is a custom Keycloak theme for the login, account and email
+	// pages of the user SSO realm
+	// +optional
+	Branding *Branding `json:"branding,omitempty"`
+
+	// CloudResources overrides the cloud provider strategies of the Postgres
+	// and Redis instances provisioned by the cloud resource operator
+	// +optional
+	CloudResources *CloudResources `json:"cloudResources,omitempty"`
 }
 
 // RHMIConfigStatus defines the observed state of RHMIConfig
@@ -63,6 +86,21 @@ type RHMIConfigStatus struct {
 	Maintenance      RHMIConfigStatusMaintenance `json:"maintenance,omitempty"`
 	Upgrade          RHMIConfigStatusUpgrade     `json:"upgrade,omitempty"`
 	UpgradeAvailable *UpgradeAvailable           `json:"upgradeAvailable,omitempty"`
+	Branding         *RHMIConfigStatusBranding   `json:"branding,omitempty"`
+	// UpgradeNotifications are the upgrade notifications sent for the
+	// available upgrade, one per stage and channel, and one per contact the
+	// notification of a stage is delivered to
+	UpgradeNotifications []UpgradeNotification `json:"upgradeNotifications,omitempty"`
+}
+
+// RHMIConfigStatusBranding reports whether the branding theme was loaded
+// and set on the user SSO realm
+type RHMIConfigStatusBranding struct {
+	ThemeName string `json:"themeName,omitempty"`
+
+	// phase: one of Pending, Applied, Failed
+	Phase   BrandingPhase `json:"phase,omitempty"`
+	Message string        `json:"message,omitempty"`
 }
 
 type RHMIConfigStatusMaintenance struct {
@@ -75,6 +113,23 @@ type RHMIConfigStatusUpgrade struct {
 	Scheduled *UpgradeSchedule `json:"scheduled,omitempty"`
 }
 
+// UpgradeNotification records an upgrade notification sent through a channel
+type UpgradeNotification struct {
+	// Version of the upgrade
+	Version string `json:"version"`
+	// Stage of the upgrade the notification is sent for: Available,
+	// Scheduled or Reminder
+	Stage string `json:"stage"`
+	// Channel the notification is sent through
+	Channel string `json:"channel"`
+	// Contact the notification is delivered to, the email address or the
+	// webhook URL. Empty once the notification is sent to every contact of
+	// the channel
+	Contact string `json:"contact,omitempty"`
+	// SentAt is when the notification was sent
+	SentAt v1.Time `json:"sentAt"`
+}
+
 type UpgradeSchedule struct {
 	// For is the calculated time when the upgrade is scheduled for, in format "2 Jan 2006 15:04"
 	For string `json:"for,omitempty"`
@@ -92,6 +147,8 @@ const DateFormat = "2 Jan 2006 15:04"
 type Upgrade struct {
 	// contacts: list of contacts which are comma separated
 	// "user1@example.com,user2@example.com"
+	// Upgrade notifications are emailed to email addresses and posted to
+	// https webhook URLs
 	Contacts string `json:"contacts,omitempty"`
 
 	// If this value is true, upgrades will be approved in the next maintenance window
@@ -120,6 +177,295 @@ type Backup struct {
 	ApplyOn string `json:"applyOn,omitempty"`
 }
 
+const (
+	ThreeScaleRoleAdmin  = "admin"
+	ThreeScaleRoleMember = "member"
+)
+
+// ThreeScaleAllowedSections are the sections of the 3scale admin portal that
+// can be granted to members
+var ThreeScaleAllowedSections = []string{
+	"portal",
+	"finance",
+	"settings",
+	"partners",
+	"monitoring",
+	"plans",
+	"policy_registry",
+}
+
+// UserRoleMapping grants roles to the users that are members of any of the
+// groups, or that have an identity with all the claims
+type UserRoleMapping struct {
+	Name string `json:"name"`
+
+	// OpenShift groups whose members are granted the roles
+	// +optional
+	Groups []string `json:"groups,omitempty"`
+
+	// Identity provider claims, as stored in the extra fields of the OpenShift
+	// identities. An identity must match all the claims
+	// +optional
+	Claims map[string]string `json:"claims,omitempty"`
+
+	// +optional
+	ThreeScale *ThreeScaleUserRole `json:"threeScale,omitempty"`
+
+	// +optional
+	RHSSO *RHSSOUserRole `json:"rhsso,omitempty"`
+}
+
+// ThreeScaleUserRole is the role of the users in the 3scale admin portal.
+// Users are never demoted from admin, removing a mapping only stops new
+// users from being promoted
+type ThreeScaleUserRole struct {
+	// role: "admin" or "member"
+	Role string `json:"role"`
+
+	// Sections of the admin portal members can access, one of
+	// portal, finance, settings, partners, monitoring, plans, policy_registry
+	// +optional
+	AllowedSections []string `json:"allowedSections,omitempty"`
+}
+
+// RHSSOUserRole are roles and groups added to the users in the master realm
+// of the user SSO. Users matching the mapping are created in the master realm
+// if they don't exist
+type RHSSOUserRole struct {
+	// +optional
+	RealmRoles []string `json:"realmRoles,omitempty"`
+
+	// Client roles, keyed by client ID
+	// +optional
+	ClientRoles map[string][]string `json:"clientRoles,omitempty"`
+
+	// +optional
+	Groups []string `json:"groups,omitempty"`
+}
+
+const (
+	IdentityProviderTypeOIDC   = "oidc"
+	IdentityProviderTypeSAML   = "saml"
+	IdentityProviderTypeGitLab = "gitlab"
+	IdentityProviderTypeGoogle = "google"
+	IdentityProviderTypeLDAP   = "ldap"
+
+	IdentityProviderInstanceRHSSO     = "rhsso"
+	IdentityProviderInstanceRHSSOUser = "rhssouser"
+)
+
+// IdentityProviderTypes are the supported identity provider types
+var IdentityProviderTypes = []string{
+	IdentityProviderTypeOIDC,
+	IdentityProviderTypeSAML,
+	IdentityProviderTypeGitLab,
+	IdentityProviderTypeGoogle,
+	IdentityProviderTypeLDAP,
+}
+
+// reservedIdentityProviderAliases are the aliases of the identity providers
+// managed by the operator
+var reservedIdentityProviderAliases = []string{"openshift-v4", "github"}
+
+// IdentityProvider is an external identity provider. LDAP providers are set
+// up as user federation providers, every other type as a broker
+type IdentityProvider struct {
+	Alias string `json:"alias"`
+
+	// type: one of oidc, saml, gitlab, google, ldap
+	Type string `json:"type"`
+
+	// +optional
+	DisplayName string `json:"displayName,omitempty"`
+
+	// Instances the provider is set up in, rhsso and/or rhssouser. Defaults
+	// to both
+	// +optional
+	Instances []string `json:"instances,omitempty"`
+
+	// Name of the secret in the operator namespace holding the credentials
+	// of the provider, e.g. clientId and clientSecret, or bindDn and
+	// bindCredential for LDAP. Its keys are added to the provider config and
+	// the provider is updated whenever the secret changes
+	SecretRef string `json:"secretRef"`
+
+	// Provider config, e.g. authorizationUrl and tokenUrl for OIDC,
+	// singleSignOnServiceUrl for SAML or connectionUrl and usersDn for LDAP
+	// +optional
+	Config map[string]string `json:"config,omitempty"`
+
+	// +optional
+	Mappers []IdentityProviderMapper `json:"mappers,omitempty"`
+
+	// Alias of the flow run on the first login through the provider.
+	// Defaults to "first broker login"
+	// +optional
+	FirstBrokerLoginFlowAlias string `json:"firstBrokerLoginFlowAlias,omitempty"`
+
+	// +optional
+	TrustEmail bool `json:"trustEmail,omitempty"`
+}
+
+// IdentityProviderMapper maps the claims, assertions or LDAP attributes of
+// the provider to the users of the realm
+type IdentityProviderMapper struct {
+	Name string `json:"name"`
+
+	// Mapper type, e.g. oidc-user-attribute-idp-mapper, or
+	// user-attribute-ldap-mapper for LDAP providers
+	Type string `json:"type"`
+
+	// +optional
+	Config map[string]string `json:"config,omitempty"`
+}
+
+type BrandingPhase string
+
+const (
+	BrandingPhasePending BrandingPhase = "Pending"
+	BrandingPhaseApplied BrandingPhase = "Applied"
+	BrandingPhaseFailed  BrandingPhase = "Failed"
+
+	BrandingThemeTypeLogin   = "login"
+	BrandingThemeTypeAccount = "account"
+	BrandingThemeTypeEmail   = "email"
+
+	// BrandingPathSeparator replaces "/" in the keys of the branding
+	// ConfigMap, as ConfigMap keys can't contain slashes
+	BrandingPathSeparator = "__"
+)
+
+// BrandingThemeTypes are the theme types that can be set on the realm
+var BrandingThemeTypes = []string{
+	BrandingThemeTypeLogin,
+	BrandingThemeTypeAccount,
+	BrandingThemeTypeEmail,
+}
+
+// reservedThemeNames are the names of the themes shipped with RHSSO
+var reservedThemeNames = []string{"base", "keycloak", "keycloak-preview", "rh-sso"}
+
+var themeNameRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// Branding is a Keycloak theme, provided either as a ConfigMap with the theme
+// files or as a theme archive
+type Branding struct {
+	// Name of the theme, as referenced by the realm
+	ThemeName string `json:"themeName"`
+
+	// Name of a ConfigMap in the operator namespace holding the theme files,
+	// such as theme.properties, CSS, logos and message bundles. Keys are the
+	// paths of the files in the theme directory, with "__" in place of "/",
+	// e.g. login__theme.properties or login__resources__css__login.css.
+	// Binary files such as logos go in binaryData
+	// +optional
+	ConfigMapRef string `json:"configMapRef,omitempty"`
+
+	// URL of a theme archive (JAR) deployed as a Keycloak extension
+	// +optional
+	ArchiveURL string `json:"archiveURL,omitempty"`
+
+	// Theme types set on the realm: login, account and/or email. Defaults
+	// to all of them
+	// +optional
+	ThemeTypes []string `json:"themeTypes,omitempty"`
+}
+
+// GetThemeTypes returns the theme types set on the realm
+func (b *Branding) GetThemeTypes() []string {
+	if len(b.ThemeTypes) == 0 {
+		return BrandingThemeTypes
+	}
+	return b.ThemeTypes
+}
+
+// CloudResources are the strategy overrides for the cloud resources. The
+// overrides of a resource are applied on top of the production strategy of
+// its type. Removing an override reverts the value to the production
+// strategy, except for the Postgres storage size which is never decreased
+type CloudResources struct {
+	// Postgres strategies, keyed by the name of the Postgres resource, e.g.
+	// threescale-postgres-rhoam
+	// +optional
+	Postgres map[string]CloudResourceStrategy `json:"postgres,omitempty"`
+
+	// Redis strategies, keyed by the name of the Redis resource, e.g.
+	// threescale-backend-redis-rhoam
+	// +optional
+	Redis map[string]CloudResourceStrategy `json:"redis,omitempty"`
+
+	// CIDR block of the VPC the cloud resources are provisioned in. It can't
+	// be changed once set
+	// +optional
+	CIDR string `json:"cidr,omitempty"`
+
+	// StorageAutoResize raises the maximum storage the Postgres instances
+	// can scale up to when one of them is forecast to run out of storage
+	// +optional
+	StorageAutoResize *StorageAutoResize `json:"storageAutoResize,omitempty"`
+}
+
+// StorageAutoResize is the threshold at which the maximum storage of the
+// Postgres instances is raised, and by how much
+type StorageAutoResize struct {
+	// Number of days until an instance is forecast to run out of storage
+	// at which the maximum storage is raised
+	ThresholdDays int32 `json:"thresholdDays"`
+
+	// Percentage of the allocated storage the maximum storage is raised
+	// above it. Defaults to 25
+	// +optional
+	IncreasePercent int32 `json:"increasePercent,omitempty"`
+}
+
+// CloudResourceStrategy is the create strategy of a cloud resource
+type CloudResourceStrategy struct {
+	// Instance class, e.g. db.m5.xlarge for Postgres or cache.m5.large
+	// for Redis
+	// +optional
+	InstanceClass string `json:"instanceClass,omitempty"`
+
+	// Allocated storage in GiB. Postgres only, it can't be decreased
+	// +optional
+	StorageSize *int64 `json:"storageSize,omitempty"`
+
+	// +optional
+	MultiAZ *bool `json:"multiAZ,omitempty"`
+
+	// Engine version, e.g. 10.15 for Postgres or 5.0.6 for Redis
+	// +optional
+	EngineVersion string `json:"engineVersion,omitempty"`
+
+	// Number of days automated snapshots are retained
+	// +optional
+	SnapshotRetention *int64 `json:"snapshotRetention,omitempty"`
+}
+
+const (
+	postgresInstanceClassPrefix = "db."
+	redisInstanceClassPrefix    = "cache."
+
+	minPostgresStorageSize = 20
+	maxPostgresStorageSize = 65536
+	maxSnapshotRetention   = 35
+
+	minCIDRPrefixLength = 16
+	maxCIDRPrefixLength = 26
+
+	maxAutoResizeThresholdDays   = 30
+	minAutoResizeIncreasePercent = 10
+	maxAutoResizeIncreasePercent = 100
+
+	// DefaultAutoResizeIncreasePercent is used when the increase percent of
+	// the storage auto resize is not set
+	DefaultAutoResizeIncreasePercent = 25
+)
+
+var (
+	instanceClassRegexp = regexp.MustCompile(`^[a-z0-9]+\.[a-z0-9]+$`)
+	engineVersionRegexp = regexp.MustCompile(`^[0-9]+(\.[0-9]+){0,2}$`)
+)
+
 type UpgradeAvailable struct {
 	// Time of new update becoming available
 	// Format: "DDD hh:mm" > "sun 23:00". UTC time
@@ -155,7 +501,16 @@ func init() {
 }
 
 func (c *RHMIConfig) ValidateCreate() error {
-	return nil
+	if err := ValidateUserRoles(c.Spec.UserRoles); err != nil {
+		return err
+	}
+	if err := ValidateIdentityProviders(c.Spec.IdentityProviders); err != nil {
+		return err
+	}
+	if err := ValidateBranding(c.Spec.Branding); err != nil {
+		return err
+	}
+	return ValidateCloudResources(c.Spec.CloudResources, nil)
 }
 
 func (c *RHMIConfig) ValidateUpdate(old runtime.Object) error {
@@ -173,7 +528,21 @@ func (c *RHMIConfig) ValidateUpdate(old runtime.Object) error {
 		}
 	}
 
-	return nil
+	if err := ValidateUserRoles(c.Spec.UserRoles); err != nil {
+		return err
+	}
+	if err := ValidateIdentityProviders(c.Spec.IdentityProviders); err != nil {
+		return err
+	}
+	if err := ValidateBranding(c.Spec.Branding); err != nil {
+		return err
+	}
+
+	var oldCloudResources *CloudResources
+	if oldConfig, ok := old.(*RHMIConfig); ok {
+		oldCloudResources = oldConfig.Spec.CloudResources
+	}
+	return ValidateCloudResources(c.Spec.CloudResources, oldCloudResources)
 }
 
 func (c *RHMIConfig) ValidateDelete() error {
@@ -331,6 +700,205 @@ func ValidateBackupAndMaintenance(backupApplyOn, maintenanceApplyFrom string) (s
 	return backupApplyOn, maintenanceApplyFrom, nil
 }
 
+// ValidateUserRoles ensures that the user role mappings are named uniquely,
+// match users and only use known 3scale roles and sections
+func ValidateUserRoles(mappings []UserRoleMapping) error {
+	names := map[string]bool{}
+	for _, mapping := range mappings {
+		if mapping.Name == "" {
+			return errors.New("spec.userRoles: name is required")
+		}
+		if names[mapping.Name] {
+			return fmt.Errorf("spec.userRoles: duplicate mapping %s", mapping.Name)
+		}
+		names[mapping.Name] = true
+
+		if len(mapping.Groups) == 0 && len(mapping.Claims) == 0 {
+			return fmt.Errorf("spec.userRoles: mapping %s must have groups or claims", mapping.Name)
+		}
+
+		if mapping.ThreeScale == nil {
+			continue
+		}
+		switch mapping.ThreeScale.Role {
+		case ThreeScaleRoleAdmin:
+			if len(mapping.ThreeScale.AllowedSections) > 0 {
+				return fmt.Errorf("spec.userRoles: mapping %s can't restrict the sections of admins", mapping.Name)
+			}
+		case ThreeScaleRoleMember:
+			for _, section := range mapping.ThreeScale.AllowedSections {
+				if !contains(ThreeScaleAllowedSections, section) {
+					return fmt.Errorf("spec.userRoles: mapping %s has unknown 3scale section %s, expected one of %s", mapping.Name, section, strings.Join(ThreeScaleAllowedSections, ", "))
+				}
+			}
+		default:
+			return fmt.Errorf("spec.userRoles: mapping %s has unknown 3scale role %s, expected %s or %s", mapping.Name, mapping.ThreeScale.Role, ThreeScaleRoleAdmin, ThreeScaleRoleMember)
+		}
+	}
+
+	return nil
+}
+
+// ValidateIdentityProviders ensures that the identity providers have unique,
+// non reserved aliases, a supported type and instances, and credentials
+func ValidateIdentityProviders(providers []IdentityProvider) error {
+	aliases := map[string]bool{}
+	for _, provider := range providers {
+		if provider.Alias == "" {
+			return errors.New("spec.identityProviders: alias is required")
+		}
+		if contains(reservedIdentityProviderAliases, provider.Alias) {
+			return fmt.Errorf("spec.identityProviders: alias %s is reserved", provider.Alias)
+		}
+		if aliases[provider.Alias] {
+			return fmt.Errorf("spec.identityProviders: duplicate alias %s", provider.Alias)
+		}
+		aliases[provider.Alias] = true
+
+		if !contains(IdentityProviderTypes, provider.Type) {
+			return fmt.Errorf("spec.identityProviders: provider %s has unknown type %s, expected one of %s", provider.Alias, provider.Type, strings.Join(IdentityProviderTypes, ", "))
+		}
+		for _, instance := range provider.Instances {
+			if instance != IdentityProviderInstanceRHSSO && instance != IdentityProviderInstanceRHSSOUser {
+				return fmt.Errorf("spec.identityProviders: provider %s has unknown instance %s, expected %s or %s", provider.Alias, instance, IdentityProviderInstanceRHSSO, IdentityProviderInstanceRHSSOUser)
+			}
+		}
+		if provider.SecretRef == "" {
+			return fmt.Errorf("spec.identityProviders: provider %s must have a secretRef", provider.Alias)
+		}
+
+		mappers := map[string]bool{}
+		for _, mapper := range provider.Mappers {
+			if mapper.Name == "" || mapper.Type == "" {
+				return fmt.Errorf("spec.identityProviders: mappers of provider %s must have a name and a type", provider.Alias)
+			}
+			if mappers[mapper.Name] {
+				return fmt.Errorf("spec.identityProviders: provider %s has duplicate mapper %s", provider.Alias, mapper.Name)
+			}
+			mappers[mapper.Name] = true
+		}
+	}
+
+	return nil
+}
+
+// ValidateBranding ensures that the branding has a valid, non reserved theme
+// name, exactly one source and known theme types
+func ValidateBranding(branding *Branding) error {
+	if branding == nil {
+		return nil
+	}
+
+	if !themeNameRegexp.MatchString(branding.ThemeName) {
+		return fmt.Errorf("spec.branding: invalid theme name %q, expected lower case alphanumeric characters or '-'", branding.ThemeName)
+	}
+	if contains(reservedThemeNames, branding.ThemeName) {
+		return fmt.Errorf("spec.branding: theme name %s is reserved", branding.ThemeName)
+	}
+
+	if (branding.ConfigMapRef == "") == (branding.ArchiveURL == "") {
+		return errors.New("spec.branding: exactly one of configMapRef and archiveURL is required")
+	}
+	if branding.ArchiveURL != "" {
+		archiveURL, err := url.Parse(branding.ArchiveURL)
+		if err != nil || archiveURL.Scheme != "https" || archiveURL.Host == "" {
+			return fmt.Errorf("spec.branding: archiveURL %s must be an https URL", branding.ArchiveURL)
+		}
+	}
+
+	for _, themeType := range branding.ThemeTypes {
+		if !contains(BrandingThemeTypes, themeType) {
+			return fmt.Errorf("spec.branding: unknown theme type %s, expected one of %s", themeType, strings.Join(BrandingThemeTypes, ", "))
+		}
+	}
+
+	return nil
+}
+
+// ValidateCloudResources ensures that the strategy overrides are values the
+// cloud provider accepts, and that the CIDR block and Postgres storage size
+// are not changed in ways the cloud provider can't apply to existing
+// resources. old is nil on create. The CIDR block and storage sizes are also
+// checked against the strategies they're written to when they're reconciled
+func ValidateCloudResources(cloudResources, old *CloudResources) error {
+	if cloudResources == nil {
+		return nil
+	}
+
+	for name, postgres := range cloudResources.Postgres {
+		field := fmt.Sprintf("spec.cloudResources.postgres[%s]", name)
+		if err := validateCloudResourceStrategy(field, postgres, postgresInstanceClassPrefix); err != nil {
+			return err
+		}
+		if postgres.StorageSize == nil {
+			continue
+		}
+		if *postgres.StorageSize < minPostgresStorageSize || *postgres.StorageSize > maxPostgresStorageSize {
+			return fmt.Errorf("%s: storageSize must be between %d and %d GiB", field, minPostgresStorageSize, maxPostgresStorageSize)
+		}
+		if old != nil {
+			if oldPostgres, ok := old.Postgres[name]; ok && oldPostgres.StorageSize != nil && *postgres.StorageSize < *oldPostgres.StorageSize {
+				return fmt.Errorf("%s: storageSize can't be decreased from %d GiB", field, *oldPostgres.StorageSize)
+			}
+		}
+	}
+	for name, redis := range cloudResources.Redis {
+		field := fmt.Sprintf("spec.cloudResources.redis[%s]", name)
+		if err := validateCloudResourceStrategy(field, redis, redisInstanceClassPrefix); err != nil {
+			return err
+		}
+		if redis.StorageSize != nil {
+			return fmt.Errorf("%s: storageSize is not supported for Redis", field)
+		}
+	}
+
+	if cloudResources.CIDR != "" {
+		_, cidr, err := net.ParseCIDR(cloudResources.CIDR)
+		if err != nil || cidr.IP.To4() == nil {
+			return fmt.Errorf("spec.cloudResources: invalid cidr %s, expected an IPv4 CIDR block", cloudResources.CIDR)
+		}
+		if prefixLength, _ := cidr.Mask.Size(); prefixLength < minCIDRPrefixLength || prefixLength > maxCIDRPrefixLength {
+			return fmt.Errorf("spec.cloudResources: cidr %s must have a prefix length between /%d and /%d", cloudResources.CIDR, minCIDRPrefixLength, maxCIDRPrefixLength)
+		}
+	}
+	if old != nil && old.CIDR != "" && cloudResources.CIDR != old.CIDR {
+		return fmt.Errorf("spec.cloudResources: cidr can't be changed once set to %s", old.CIDR)
+	}
+
+	if autoResize := cloudResources.StorageAutoResize; autoResize != nil {
+		if autoResize.ThresholdDays < 1 || autoResize.ThresholdDays > maxAutoResizeThresholdDays {
+			return fmt.Errorf("spec.cloudResources.storageAutoResize: thresholdDays must be between 1 and %d", maxAutoResizeThresholdDays)
+		}
+		if autoResize.IncreasePercent != 0 && (autoResize.IncreasePercent < minAutoResizeIncreasePercent || autoResize.IncreasePercent > maxAutoResizeIncreasePercent) {
+			return fmt.Errorf("spec.cloudResources.storageAutoResize: increasePercent must be between %d and %d", minAutoResizeIncreasePercent, maxAutoResizeIncreasePercent)
+		}
+	}
+
+	return nil
+}
+
+func validateCloudResourceStrategy(field string, strategy CloudResourceStrategy, instanceClassPrefix string) error {
+	if strategy.InstanceClass != "" {
+		if !strings.HasPrefix(strategy.InstanceClass, instanceClassPrefix) || !instanceClassRegexp.MatchString(strings.TrimPrefix(strategy.InstanceClass, instanceClassPrefix)) {
+			return fmt.Errorf("%s: invalid instanceClass %s, expected %s<family>.<size>", field, strategy.InstanceClass, instanceClassPrefix)
+		}
+	}
+	if strategy.EngineVersion != "" && !engineVersionRegexp.MatchString(strategy.EngineVersion) {
+		return fmt.Errorf("%s: invalid engineVersion %s", field, strategy.EngineVersion)
+	}
+	if strategy.SnapshotRetention != nil && (*strategy.SnapshotRetention < 0 || *strategy.SnapshotRetention > maxSnapshotRetention) {
+		return fmt.Errorf("%s: snapshotRetention must be between 0 and %d days", field, maxSnapshotRetention)
+	}
+
+	return nil
+}
+
+// InInstance returns true if the provider is set up in the
+// given instance
+func (p IdentityProvider) InInstance(instance string) bool {
+	return len(p.Instances) == 0 || contains(p.Instances, instance)
+}
+
 // timeBlockOverlaps checks if two time ranges overlap and returns true
 // if they do
 func timeBlockOverlaps(startA, endA, startB, endB time.Time) bool {
@@ -0,0 +1,266 @@
+package v1alpha1
+
+import (
+	"testing"
+)
+
+func TestValidateUserRoles(t *testing.T) {
+	tests := []struct {
+		name        string
+		mappings    []UserRoleMapping
+		expectError bool
+	}{
+		{
+			name: "test valid mappings",
+			mappings: []UserRoleMapping{
+				{Name: "admins", Groups: []string{"api-admins"}, ThreeScale: &ThreeScaleUserRole{Role: ThreeScaleRoleAdmin}},
+				{Name: "finance", Claims: map[string]string{"department": "finance"}, ThreeScale: &ThreeScaleUserRole{Role: ThreeScaleRoleMember, AllowedSections: []string{"finance", "monitoring"}}},
+				{Name: "viewers", Groups: []string{"viewers"}, RHSSO: &RHSSOUserRole{RealmRoles: []string{"viewer"}}},
+			},
+		},
+		{
+			name:        "test mapping without name is invalid",
+			mappings:    []UserRoleMapping{{Groups: []string{"api-admins"}}},
+			expectError: true,
+		},
+		{
+			name: "test duplicate mapping names are invalid",
+			mappings: []UserRoleMapping{
+				{Name: "admins", Groups: []string{"api-admins"}},
+				{Name: "admins", Groups: []string{"other-admins"}},
+			},
+			expectError: true,
+		},
+		{
+			name:        "test mapping without groups or claims is invalid",
+			mappings:    []UserRoleMapping{{Name: "admins"}},
+			expectError: true,
+		},
+		{
+			name:        "test unknown 3scale role is invalid",
+			mappings:    []UserRoleMapping{{Name: "admins", Groups: []string{"api-admins"}, ThreeScale: &ThreeScaleUserRole{Role: "owner"}}},
+			expectError: true,
+		},
+		{
+			name:        "test admin with sections is invalid",
+			mappings:    []UserRoleMapping{{Name: "admins", Groups: []string{"api-admins"}, ThreeScale: &ThreeScaleUserRole{Role: ThreeScaleRoleAdmin, AllowedSections: []string{"portal"}}}},
+			expectError: true,
+		},
+		{
+			name:        "test unknown 3scale section is invalid",
+			mappings:    []UserRoleMapping{{Name: "members", Groups: []string{"members"}, ThreeScale: &ThreeScaleUserRole{Role: ThreeScaleRoleMember, AllowedSections: []string{"billing"}}}},
+			expectError: true,
+		},
+	}
+	for _, c := range tests {
+		t.Run(c.name, func(t *testing.T) {
+			err := ValidateUserRoles(c.mappings)
+			if (err != nil) != c.expectError {
+				t.Errorf("unexpected validation result - got %v; expecting error %v", err, c.expectError)
+			}
+		})
+	}
+}
+
+func TestValidateIdentityProviders(t *testing.T) {
+	tests := []struct {
+		name        string
+		providers   []IdentityProvider
+		expectError bool
+	}{
+		{
+			name: "test valid providers",
+			providers: []IdentityProvider{
+				{Alias: "corporate-saml", Type: IdentityProviderTypeSAML, SecretRef: "saml", Instances: []string{IdentityProviderInstanceRHSSOUser}},
+				{Alias: "corporate-ldap", Type: IdentityProviderTypeLDAP, SecretRef: "ldap", Mappers: []IdentityProviderMapper{{Name: "email", Type: "user-attribute-ldap-mapper"}}},
+			},
+		},
+		{
+			name:        "test reserved alias is invalid",
+			providers:   []IdentityProvider{{Alias: "openshift-v4", Type: IdentityProviderTypeOIDC, SecretRef: "oidc"}},
+			expectError: true,
+		},
+		{
+			name: "test duplicate alias is invalid",
+			providers: []IdentityProvider{
+				{Alias: "corporate", Type: IdentityProviderTypeOIDC, SecretRef: "oidc"},
+				{Alias: "corporate", Type: IdentityProviderTypeSAML, SecretRef: "saml"},
+			},
+			expectError: true,
+		},
+		{
+			name:        "test unknown type is invalid",
+			providers:   []IdentityProvider{{Alias: "corporate", Type: "kerberos", SecretRef: "kerberos"}},
+			expectError: true,
+		},
+		{
+			name:        "test unknown instance is invalid",
+			providers:   []IdentityProvider{{Alias: "corporate", Type: IdentityProviderTypeOIDC, SecretRef: "oidc", Instances: []string{"3scale"}}},
+			expectError: true,
+		},
+		{
+			name:        "test provider without secret is invalid",
+			providers:   []IdentityProvider{{Alias: "corporate", Type: IdentityProviderTypeOIDC}},
+			expectError: true,
+		},
+		{
+			name:        "test mapper without type is invalid",
+			providers:   []IdentityProvider{{Alias: "corporate", Type: IdentityProviderTypeOIDC, SecretRef: "oidc", Mappers: []IdentityProviderMapper{{Name: "email"}}}},
+			expectError: true,
+		},
+	}
+	for _, c := range tests {
+		t.Run(c.name, func(t *testing.T) {
+			err := ValidateIdentityProviders(c.providers)
+			if (err != nil) != c.expectError {
+				t.Errorf("unexpected validation result - got %v; expecting error %v", err, c.expectError)
+			}
+		})
+	}
+}
+
+func TestValidateBranding(t *testing.T) {
+	tests := []struct {
+		name        string
+		branding    *Branding
+		expectError bool
+	}{
+		{
+			name: "test no branding is valid",
+		},
+		{
+			name:     "test config map branding is valid",
+			branding: &Branding{ThemeName: "acme", ConfigMapRef: "acme-theme", ThemeTypes: []string{BrandingThemeTypeLogin}},
+		},
+		{
+			name:     "test archive branding is valid",
+			branding: &Branding{ThemeName: "acme", ArchiveURL: "https://example.com/acme-theme.jar"},
+		},
+		{
+			name:        "test reserved theme name is invalid",
+			branding:    &Branding{ThemeName: "rh-sso", ConfigMapRef: "acme-theme"},
+			expectError: true,
+		},
+		{
+			name:        "test invalid theme name is invalid",
+			branding:    &Branding{ThemeName: "../acme", ConfigMapRef: "acme-theme"},
+			expectError: true,
+		},
+		{
+			name:        "test both sources are invalid",
+			branding:    &Branding{ThemeName: "acme", ConfigMapRef: "acme-theme", ArchiveURL: "https://example.com/acme-theme.jar"},
+			expectError: true,
+		},
+		{
+			name:        "test non https archive is invalid",
+			branding:    &Branding{ThemeName: "acme", ArchiveURL: "http://example.com/acme-theme.jar"},
+			expectError: true,
+		},
+		{
+			name:        "test unknown theme type is invalid",
+			branding:    &Branding{ThemeName: "acme", ConfigMapRef: "acme-theme", ThemeTypes: []string{"admin"}},
+			expectError: true,
+		},
+	}
+	for _, c := range tests {
+		t.Run(c.name, func(t *testing.T) {
+			err := ValidateBranding(c.branding)
+			if (err != nil) != c.expectError {
+				t.Errorf("unexpected validation result - got %v; expecting error %v", err, c.expectError)
+			}
+		})
+	}
+}
+
+func TestValidateCloudResources(t *testing.T) {
+	int64Ptr := func(i int64) *int64 { return &i }
+	multiAZ := true
+
+	tests := []struct {
+		name           string
+		cloudResources *CloudResources
+		old            *CloudResources
+		expectError    bool
+	}{
+		{
+			name: "test no cloud resources is valid",
+		},
+		{
+			name: "test postgres and redis strategies are valid",
+			cloudResources: &CloudResources{
+				Postgres: map[string]CloudResourceStrategy{"threescale-postgres-rhmi": {InstanceClass: "db.m5.xlarge", StorageSize: int64Ptr(100), MultiAZ: &multiAZ, EngineVersion: "10.15", SnapshotRetention: int64Ptr(30)}},
+				Redis:    map[string]CloudResourceStrategy{"threescale-backend-redis-rhmi": {InstanceClass: "cache.m5.large", EngineVersion: "5.0.6", SnapshotRetention: int64Ptr(7)}},
+				CIDR:     "10.1.0.0/26",
+			},
+		},
+		{
+			name:           "test redis instance class for postgres is invalid",
+			cloudResources: &CloudResources{Postgres: map[string]CloudResourceStrategy{"threescale-postgres-rhmi": {InstanceClass: "cache.m5.large"}}},
+			expectError:    true,
+		},
+		{
+			name:           "test invalid engine version is invalid",
+			cloudResources: &CloudResources{Redis: map[string]CloudResourceStrategy{"threescale-backend-redis-rhmi": {EngineVersion: "latest"}}},
+			expectError:    true,
+		},
+		{
+			name:           "test redis storage size is invalid",
+			cloudResources: &CloudResources{Redis: map[string]CloudResourceStrategy{"threescale-backend-redis-rhmi": {StorageSize: int64Ptr(100)}}},
+			expectError:    true,
+		},
+		{
+			name:           "test snapshot retention over the limit is invalid",
+			cloudResources: &CloudResources{Postgres: map[string]CloudResourceStrategy{"threescale-postgres-rhmi": {SnapshotRetention: int64Ptr(36)}}},
+			expectError:    true,
+		},
+		{
+			name:           "test decreasing postgres storage size is invalid",
+			cloudResources: &CloudResources{Postgres: map[string]CloudResourceStrategy{"threescale-postgres-rhmi": {StorageSize: int64Ptr(50)}}},
+			old:            &CloudResources{Postgres: map[string]CloudResourceStrategy{"threescale-postgres-rhmi": {StorageSize: int64Ptr(100)}}},
+			expectError:    true,
+		},
+		{
+			name:           "test postgres storage size of another resource is not compared",
+			cloudResources: &CloudResources{Postgres: map[string]CloudResourceStrategy{"rhsso-postgres-rhmi": {StorageSize: int64Ptr(50)}}},
+			old:            &CloudResources{Postgres: map[string]CloudResourceStrategy{"threescale-postgres-rhmi": {StorageSize: int64Ptr(100)}}},
+		},
+		{
+			name:           "test increasing postgres storage size is valid",
+			cloudResources: &CloudResources{Postgres: map[string]CloudResourceStrategy{"threescale-postgres-rhmi": {StorageSize: int64Ptr(200)}}},
+			old:            &CloudResources{Postgres: map[string]CloudResourceStrategy{"threescale-postgres-rhmi": {StorageSize: int64Ptr(100)}}},
+		},
+		{
+			name:           "test invalid cidr is invalid",
+			cloudResources: &CloudResources{CIDR: "10.1.0.0/8"},
+			expectError:    true,
+		},
+		{
+			name:           "test changing cidr is invalid",
+			cloudResources: &CloudResources{CIDR: "10.2.0.0/26"},
+			old:            &CloudResources{CIDR: "10.1.0.0/26"},
+			expectError:    true,
+		},
+		{
+			name:           "test storage auto resize with default increase is valid",
+			cloudResources: &CloudResources{StorageAutoResize: &StorageAutoResize{ThresholdDays: 7}},
+		},
+		{
+			name:           "test storage auto resize without threshold is invalid",
+			cloudResources: &CloudResources{StorageAutoResize: &StorageAutoResize{IncreasePercent: 25}},
+			expectError:    true,
+		},
+		{
+			name:           "test storage auto resize increase over 100 percent is invalid",
+			cloudResources: &CloudResources{StorageAutoResize: &StorageAutoResize{ThresholdDays: 7, IncreasePercent: 200}},
+			expectError:    true,
+		},
+	}
+	for _, c := range tests {
+		t.Run(c.name, func(t *testing.T) {
+			err := ValidateCloudResources(c.cloudResources, c.old)
+			if (err != nil) != c.expectError {
+				t.Errorf("unexpected validation result - got %v; expecting error %v", err, c.expectError)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SelfManagedGatewaySpec defines the desired state of SelfManagedGateway
+type SelfManagedGatewaySpec struct {
+	// Replicas is the number of APIcast pods to run. Defaults to 1
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Image overrides the APIcast image. When not set, the image used
+	// by the managed apicast-production gateway is used, so the
+	// gateway always matches the installed 3scale version
+	Image string `json:"image,omitempty"`
+
+	// DeploymentEnvironment is the 3scale environment the gateway
+	// loads its configuration from, either "staging" or "production".
+	// Defaults to "production"
+	// +kubebuilder:validation:Enum=staging;production
+	DeploymentEnvironment string `json:"deploymentEnvironment,omitempty"`
+
+	// ConfigurationLoadMode is either "boot" or "lazy". Defaults to "boot"
+	// +kubebuilder:validation:Enum=boot;lazy
+	ConfigurationLoadMode string `json:"configurationLoadMode,omitempty"`
+
+	// ExposedHost, when set, creates a Route for the gateway with this host
+	ExposedHost string `json:"exposedHost,omitempty"`
+
+	// CustomPolicies are APIcast policies loaded from ConfigMaps in the
+	// namespace of the SelfManagedGateway
+	CustomPolicies []SelfManagedGatewayCustomPolicy `json:"customPolicies,omitempty"`
+
+	// RateLimiting attaches an envoy sidecar to the gateway that applies
+	// the same marin3r rate limits as the managed APIcast gateways
+	RateLimiting bool `json:"rateLimiting,omitempty"`
+
+	// Resources sets the compute resources of the APIcast container
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// SelfManagedGatewayCustomPolicy references a ConfigMap holding the
+// source of a custom APIcast policy. The ConfigMap is expected to contain
+// the init.lua, apicast-policy.json and policy lua files
+type SelfManagedGatewayCustomPolicy struct {
+	Name         string                      `json:"name"`
+	Version      string                      `json:"version"`
+	ConfigMapRef corev1.LocalObjectReference `json:"configMapRef"`
+}
+
+// SelfManagedGatewayStatus defines the observed state of SelfManagedGateway
+type SelfManagedGatewayStatus struct {
+	Phase               StatusPhase `json:"phase,omitempty"`
+	LastError           string      `json:"lastError,omitempty"`
+	AdminPortalEndpoint string      `json:"adminPortalEndpoint,omitempty"`
+	Host                string      `json:"host,omitempty"`
+	Image               string      `json:"image,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+//+kubebuilder:printcolumn:name="Host",type=string,JSONPath=`.status.host`
+
+// SelfManagedGateway is the Schema for the selfmanagedgateways API
+type SelfManagedGateway struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SelfManagedGatewaySpec   `json:"spec,omitempty"`
+	Status SelfManagedGatewayStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// SelfManagedGatewayList contains a list of SelfManagedGateway
+type SelfManagedGatewayList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SelfManagedGateway `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SelfManagedGateway{}, &SelfManagedGatewayList{})
+}
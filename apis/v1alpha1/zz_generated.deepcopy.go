@@ -22,6 +22,8 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -55,6 +57,161 @@ func (in *Backup) DeepCopy() *Backup {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Branding) DeepCopyInto(out *Branding) {
+	*out = *in
+	if in.ThemeTypes != nil {
+		in, out := &in.ThemeTypes, &out.ThemeTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Branding.
+func (in *Branding) DeepCopy() *Branding {
+	if in == nil {
+		return nil
+	}
+	out := new(Branding)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapacityForecast) DeepCopyInto(out *CapacityForecast) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityForecast.
+func (in *CapacityForecast) DeepCopy() *CapacityForecast {
+	if in == nil {
+		return nil
+	}
+	out := new(CapacityForecast)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudResourceStrategy) DeepCopyInto(out *CloudResourceStrategy) {
+	*out = *in
+	if in.StorageSize != nil {
+		in, out := &in.StorageSize, &out.StorageSize
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MultiAZ != nil {
+		in, out := &in.MultiAZ, &out.MultiAZ
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SnapshotRetention != nil {
+		in, out := &in.SnapshotRetention, &out.SnapshotRetention
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudResourceStrategy.
+func (in *CloudResourceStrategy) DeepCopy() *CloudResourceStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudResourceStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudResources) DeepCopyInto(out *CloudResources) {
+	*out = *in
+	if in.Postgres != nil {
+		in, out := &in.Postgres, &out.Postgres
+		*out = make(map[string]CloudResourceStrategy, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Redis != nil {
+		in, out := &in.Redis, &out.Redis
+		*out = make(map[string]CloudResourceStrategy, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.StorageAutoResize != nil {
+		in, out := &in.StorageAutoResize, &out.StorageAutoResize
+		*out = new(StorageAutoResize)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudResources.
+func (in *CloudResources) DeepCopy() *CloudResources {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudResources)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IdentityProvider) DeepCopyInto(out *IdentityProvider) {
+	*out = *in
+	if in.Instances != nil {
+		in, out := &in.Instances, &out.Instances
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Mappers != nil {
+		in, out := &in.Mappers, &out.Mappers
+		*out = make([]IdentityProviderMapper, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IdentityProvider.
+func (in *IdentityProvider) DeepCopy() *IdentityProvider {
+	if in == nil {
+		return nil
+	}
+	out := new(IdentityProvider)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IdentityProviderMapper) DeepCopyInto(out *IdentityProviderMapper) {
+	*out = *in
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IdentityProviderMapper.
+func (in *IdentityProviderMapper) DeepCopy() *IdentityProviderMapper {
+	if in == nil {
+		return nil
+	}
+	out := new(IdentityProviderMapper)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Maintenance) DeepCopyInto(out *Maintenance) {
 	*out = *in
@@ -70,6 +227,21 @@ func (in *Maintenance) DeepCopy() *Maintenance {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreflightCheckResult) DeepCopyInto(out *PreflightCheckResult) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreflightCheckResult.
+func (in *PreflightCheckResult) DeepCopy() *PreflightCheckResult {
+	if in == nil {
+		return nil
+	}
+	out := new(PreflightCheckResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PullSecretSpec) DeepCopyInto(out *PullSecretSpec) {
 	*out = *in
@@ -85,12 +257,39 @@ func (in *PullSecretSpec) DeepCopy() *PullSecretSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuotaChangeStatus) DeepCopyInto(out *QuotaChangeStatus) {
+	*out = *in
+	if in.RequestsDelta != nil {
+		in, out := &in.RequestsDelta, &out.RequestsDelta
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.RolledOut != nil {
+		in, out := &in.RolledOut, &out.RolledOut
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaChangeStatus.
+func (in *QuotaChangeStatus) DeepCopy() *QuotaChangeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(QuotaChangeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RHMI) DeepCopyInto(out *RHMI) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -177,6 +376,30 @@ func (in *RHMIConfigSpec) DeepCopyInto(out *RHMIConfigSpec) {
 	in.Upgrade.DeepCopyInto(&out.Upgrade)
 	out.Maintenance = in.Maintenance
 	out.Backup = in.Backup
+	if in.UserRoles != nil {
+		in, out := &in.UserRoles, &out.UserRoles
+		*out = make([]UserRoleMapping, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.IdentityProviders != nil {
+		in, out := &in.IdentityProviders, &out.IdentityProviders
+		*out = make([]IdentityProvider, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Branding != nil {
+		in, out := &in.Branding, &out.Branding
+		*out = new(Branding)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CloudResources != nil {
+		in, out := &in.CloudResources, &out.CloudResources
+		*out = new(CloudResources)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RHMIConfigSpec.
@@ -199,6 +422,18 @@ func (in *RHMIConfigStatus) DeepCopyInto(out *RHMIConfigStatus) {
 		*out = new(UpgradeAvailable)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Branding != nil {
+		in, out := &in.Branding, &out.Branding
+		*out = new(RHMIConfigStatusBranding)
+		**out = **in
+	}
+	if in.UpgradeNotifications != nil {
+		in, out := &in.UpgradeNotifications, &out.UpgradeNotifications
+		*out = make([]UpgradeNotification, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RHMIConfigStatus.
@@ -211,6 +446,21 @@ func (in *RHMIConfigStatus) DeepCopy() *RHMIConfigStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RHMIConfigStatusBranding) DeepCopyInto(out *RHMIConfigStatusBranding) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RHMIConfigStatusBranding.
+func (in *RHMIConfigStatusBranding) DeepCopy() *RHMIConfigStatusBranding {
+	if in == nil {
+		return nil
+	}
+	out := new(RHMIConfigStatusBranding)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RHMIConfigStatusMaintenance) DeepCopyInto(out *RHMIConfigStatusMaintenance) {
 	*out = *in
@@ -281,6 +531,10 @@ func (in *RHMIList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RHMIProductStatus) DeepCopyInto(out *RHMIProductStatus) {
 	*out = *in
+	if in.PhaseTransitionTime != nil {
+		in, out := &in.PhaseTransitionTime, &out.PhaseTransitionTime
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RHMIProductStatus.
@@ -298,6 +552,16 @@ func (in *RHMISpec) DeepCopyInto(out *RHMISpec) {
 	*out = *in
 	out.PullSecret = in.PullSecret
 	out.AlertingEmailAddresses = in.AlertingEmailAddresses
+	if in.SMTPFailoverSecrets != nil {
+		in, out := &in.SMTPFailoverSecrets, &out.SMTPFailoverSecrets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecretRotation != nil {
+		in, out := &in.SecretRotation, &out.SecretRotation
+		*out = make([]SecretRotationPolicy, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RHMISpec.
@@ -317,7 +581,7 @@ func (in *RHMIStageStatus) DeepCopyInto(out *RHMIStageStatus) {
 		in, out := &in.Products, &out.Products
 		*out = make(map[ProductName]RHMIProductStatus, len(*in))
 		for key, val := range *in {
-			(*out)[key] = val
+			(*out)[key] = *val.DeepCopy()
 		}
 	}
 }
@@ -342,6 +606,28 @@ func (in *RHMIStatus) DeepCopyInto(out *RHMIStatus) {
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.PreflightChecks != nil {
+		in, out := &in.PreflightChecks, &out.PreflightChecks
+		*out = make([]PreflightCheckResult, len(*in))
+		copy(*out, *in)
+	}
+	if in.QuotaChange != nil {
+		in, out := &in.QuotaChange, &out.QuotaChange
+		*out = new(QuotaChangeStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CapacityForecasts != nil {
+		in, out := &in.CapacityForecasts, &out.CapacityForecasts
+		*out = make([]CapacityForecast, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RHMIStatus.
@@ -354,6 +640,46 @@ func (in *RHMIStatus) DeepCopy() *RHMIStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RHSSOUserRole) DeepCopyInto(out *RHSSOUserRole) {
+	*out = *in
+	if in.RealmRoles != nil {
+		in, out := &in.RealmRoles, &out.RealmRoles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ClientRoles != nil {
+		in, out := &in.ClientRoles, &out.ClientRoles
+		*out = make(map[string][]string, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RHSSOUserRole.
+func (in *RHSSOUserRole) DeepCopy() *RHSSOUserRole {
+	if in == nil {
+		return nil
+	}
+	out := new(RHSSOUserRole)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RhoamTenant) DeepCopyInto(out *RhoamTenant) {
 	*out = *in
@@ -443,6 +769,176 @@ func (in *RhoamTenantStatus) DeepCopy() *RhoamTenantStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretRotationPolicy) DeepCopyInto(out *SecretRotationPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretRotationPolicy.
+func (in *SecretRotationPolicy) DeepCopy() *SecretRotationPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretRotationPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelfManagedGateway) DeepCopyInto(out *SelfManagedGateway) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelfManagedGateway.
+func (in *SelfManagedGateway) DeepCopy() *SelfManagedGateway {
+	if in == nil {
+		return nil
+	}
+	out := new(SelfManagedGateway)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SelfManagedGateway) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelfManagedGatewayCustomPolicy) DeepCopyInto(out *SelfManagedGatewayCustomPolicy) {
+	*out = *in
+	out.ConfigMapRef = in.ConfigMapRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelfManagedGatewayCustomPolicy.
+func (in *SelfManagedGatewayCustomPolicy) DeepCopy() *SelfManagedGatewayCustomPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(SelfManagedGatewayCustomPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelfManagedGatewayList) DeepCopyInto(out *SelfManagedGatewayList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SelfManagedGateway, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelfManagedGatewayList.
+func (in *SelfManagedGatewayList) DeepCopy() *SelfManagedGatewayList {
+	if in == nil {
+		return nil
+	}
+	out := new(SelfManagedGatewayList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SelfManagedGatewayList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelfManagedGatewaySpec) DeepCopyInto(out *SelfManagedGatewaySpec) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.CustomPolicies != nil {
+		in, out := &in.CustomPolicies, &out.CustomPolicies
+		*out = make([]SelfManagedGatewayCustomPolicy, len(*in))
+		copy(*out, *in)
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelfManagedGatewaySpec.
+func (in *SelfManagedGatewaySpec) DeepCopy() *SelfManagedGatewaySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SelfManagedGatewaySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelfManagedGatewayStatus) DeepCopyInto(out *SelfManagedGatewayStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelfManagedGatewayStatus.
+func (in *SelfManagedGatewayStatus) DeepCopy() *SelfManagedGatewayStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SelfManagedGatewayStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageAutoResize) DeepCopyInto(out *StorageAutoResize) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageAutoResize.
+func (in *StorageAutoResize) DeepCopy() *StorageAutoResize {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageAutoResize)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ThreeScaleUserRole) DeepCopyInto(out *ThreeScaleUserRole) {
+	*out = *in
+	if in.AllowedSections != nil {
+		in, out := &in.AllowedSections, &out.AllowedSections
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThreeScaleUserRole.
+func (in *ThreeScaleUserRole) DeepCopy() *ThreeScaleUserRole {
+	if in == nil {
+		return nil
+	}
+	out := new(ThreeScaleUserRole)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Upgrade) DeepCopyInto(out *Upgrade) {
 	*out = *in
@@ -489,6 +985,22 @@ func (in *UpgradeAvailable) DeepCopy() *UpgradeAvailable {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpgradeNotification) DeepCopyInto(out *UpgradeNotification) {
+	*out = *in
+	in.SentAt.DeepCopyInto(&out.SentAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradeNotification.
+func (in *UpgradeNotification) DeepCopy() *UpgradeNotification {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradeNotification)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UpgradeSchedule) DeepCopyInto(out *UpgradeSchedule) {
 	*out = *in
@@ -503,3 +1015,40 @@ func (in *UpgradeSchedule) DeepCopy() *UpgradeSchedule {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserRoleMapping) DeepCopyInto(out *UserRoleMapping) {
+	*out = *in
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Claims != nil {
+		in, out := &in.Claims, &out.Claims
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ThreeScale != nil {
+		in, out := &in.ThreeScale, &out.ThreeScale
+		*out = new(ThreeScaleUserRole)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RHSSO != nil {
+		in, out := &in.RHSSO, &out.RHSSO
+		*out = new(RHSSOUserRole)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserRoleMapping.
+func (in *UserRoleMapping) DeepCopy() *UserRoleMapping {
+	if in == nil {
+		return nil
+	}
+	out := new(UserRoleMapping)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/integr8ly/integreatly-operator/pkg/resources/events"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/marketplace"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/owner"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/secretrotation"
 
 	rhmiv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
 	oauthv1 "github.com/openshift/api/oauth/v1"
@@ -188,7 +189,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, installation *integreatlyv1a
 	r.deleteObsoleteService(ctx, serverClient)
 
 	if integreatlyv1alpha1.IsRHOAM(rhmiv1alpha1.InstallationType(installation.Spec.Type)) {
-		if err = r.processQuota(installation, request.Namespace, installationQuota, serverClient); err != nil {
+		if err = r.processQuota(ctx, installation, request.Namespace, installationQuota, serverClient); err != nil {
 			events.HandleError(r.recorder, installation, integreatlyv1alpha1.PhaseFailed, "Error while processing the Quota", err)
 			installation.Status.LastError = err.Error()
 			return integreatlyv1alpha1.PhaseFailed, err
@@ -215,7 +216,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, installation *integreatlyv1a
 }
 
 func (r *Reconciler) removePrometheusRules(ctx context.Context, serverClient k8sclient.Client, nsPrefix string) (integreatlyv1alpha1.StatusPhase, error) {
-	rhoamProductNamespaces, err := getRHOAMNamespaces(ctx, serverClient, nsPrefix)
+	rhoamProductNamespaces, err := resources.GetRHOAMNamespaces(ctx, serverClient, nsPrefix)
 	if err != nil {
 		return integreatlyv1alpha1.PhaseFailed, err
 	}
@@ -244,23 +245,6 @@ func (r *Reconciler) removePrometheusRules(ctx context.Context, serverClient k8s
 	return integreatlyv1alpha1.PhaseCompleted, nil
 }
 
-func getRHOAMNamespaces(ctx context.Context, serverClient k8sclient.Client, nsPrefix string) ([]string, error) {
-	var namespaces []string
-	namespaceList := &corev1.NamespaceList{}
-	err := serverClient.List(ctx, namespaceList)
-	if err != nil {
-		return nil, err
-	}
-	// Only return namespaces that have the integreatly label and nsPrefix, but also return rhoam operator ns (it does not have the integreatly label on)
-	for _, namespace := range namespaceList.Items {
-		if !strings.Contains(namespace.Name, "observability") && strings.Contains(namespace.Name, nsPrefix) || namespace.Name == fmt.Sprintf("%soperator", nsPrefix) {
-			namespaces = append(namespaces, namespace.Name)
-		}
-	}
-
-	return namespaces, nil
-}
-
 // temp code for rhmi 2.8 to 2.9.0 upgrades, remove this when all clusters upgraded to 2.9.0
 func (r *Reconciler) deleteObsoleteService(ctx context.Context, serverClient k8sclient.Client) {
 	if r.installation.Spec.Type == string(integreatlyv1alpha1.InstallationTypeManaged) {
@@ -561,6 +545,11 @@ func (r *Reconciler) reconcileOauthSecrets(ctx context.Context, serverClient k8s
 		}
 	}
 
+	// the product reconcilers roll out the rotated secrets to the OAuth
+	// clients and the identity providers using them
+	policy := secretrotation.PolicyFor(r.installation, oauthClientSecrets.Name)
+	secretrotation.Rotate(oauthClientSecrets, policy, func() string { return generateSecret(32) }, time.Now())
+
 	oauthClientSecrets.ObjectMeta.ResourceVersion = ""
 	err = resources.CreateOrUpdate(ctx, serverClient, oauthClientSecrets)
 	if err != nil {
@@ -721,7 +710,7 @@ func generateSecret(length int) string {
 	return string(buf)
 }
 
-func (r *Reconciler) processQuota(installation *rhmiv1alpha1.RHMI, namespace string,
+func (r *Reconciler) processQuota(ctx context.Context, installation *rhmiv1alpha1.RHMI, namespace string,
 	installationQuota *quota.Quota, serverClient k8sclient.Client) error {
 	isQuotaUpdated := false
 
@@ -732,7 +721,7 @@ func (r *Reconciler) processQuota(installation *rhmiv1alpha1.RHMI, namespace str
 
 	// get the quota config map from the cluster
 	configMap := &corev1.ConfigMap{}
-	err = serverClient.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: quota.ConfigMapName}, configMap)
+	err = serverClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: quota.ConfigMapName}, configMap)
 	if err != nil {
 		return fmt.Errorf("error getting quota config map %w", err)
 	}
@@ -743,6 +732,13 @@ func (r *Reconciler) processQuota(installation *rhmiv1alpha1.RHMI, namespace str
 		return err
 	}
 
+	// a change of the quota of an installation already using a quota is
+	// validated and rolled out one workload at a time
+	if installation.Status.Quota != "" && installationQuota.GetName() != installation.Status.Quota {
+		return r.processQuotaChange(ctx, installation, configMap, installationQuota, serverClient)
+	}
+	installation.Status.QuotaChange = nil
+
 	// if both are toQuota and Quota are empty this indicates that it's either
 	// the first reconcile of an installation or it's the first reconcile of an upgrade to 1.6.0
 	// if the secretname is not the same as status.Quota this indicates there has been a quota change
@@ -758,6 +754,101 @@ func (r *Reconciler) processQuota(installation *rhmiv1alpha1.RHMI, namespace str
 	return nil
 }
 
+// processQuotaChange rolls out the change of installation from its current
+// quota to installationQuota. The change is blocked while the cluster doesn't
+// have the capacity for it, otherwise the resource configs of installationQuota
+// are applied one at a time, each once the workload of the previous one is
+// updated and ready. installationQuota is set to the resources to apply
+func (r *Reconciler) processQuotaChange(ctx context.Context, installation *rhmiv1alpha1.RHMI, configMap *corev1.ConfigMap,
+	installationQuota *quota.Quota, serverClient k8sclient.Client) error {
+	from := &quota.Quota{}
+	if err := quota.GetQuotaByName(installation.Status.Quota, configMap, from); err != nil {
+		// the current quota is no longer in the quota config, there is
+		// nothing to plan the change from
+		log.Warningf("Current quota not found, applying the new quota", l.Fields{"quota": installation.Status.Quota, "error": err})
+		installation.Status.QuotaChange = nil
+		installation.Status.ToQuota = installationQuota.GetName()
+		installationQuota.SetIsUpdated(true)
+		return nil
+	}
+	to := &quota.Quota{}
+	if err := quota.GetQuotaByName(installationQuota.GetName(), configMap, to); err != nil {
+		return err
+	}
+	plan := quota.NewPlan(from, to)
+
+	change := installation.Status.QuotaChange
+	if change == nil || change.From != from.GetName() || change.To != to.GetName() {
+		change = &rhmiv1alpha1.QuotaChangeStatus{
+			From:          from.GetName(),
+			To:            to.GetName(),
+			RequestsDelta: plan.Delta(),
+		}
+		installation.Status.QuotaChange = change
+	}
+	installation.Status.ToQuota = to.GetName()
+
+	namespaces := map[rhmiv1alpha1.ProductName]string{}
+	for _, step := range plan.Steps() {
+		productConfig, err := r.ConfigManager.ReadProduct(step.Product)
+		if err != nil {
+			return fmt.Errorf("error reading the config of %s: %w", step.Product, err)
+		}
+		namespaces[step.Product] = productConfig.GetNamespace()
+	}
+
+	// the change is validated before its rollout starts only, the rollout
+	// itself takes capacity
+	if change.Phase != rhmiv1alpha1.QuotaChangeInProgress {
+		if err := plan.Validate(ctx, serverClient, namespaces); err != nil {
+			change.Phase = rhmiv1alpha1.QuotaChangeBlocked
+			change.Message = err.Error()
+			log.Warningf("Quota change blocked", l.Fields{"from": change.From, "to": change.To, "error": err})
+			if err := quota.GetQuotaByName(installation.Status.Quota, configMap, installationQuota); err != nil {
+				return err
+			}
+			installationQuota.SetIsUpdated(false)
+			return nil
+		}
+		change.Phase = rhmiv1alpha1.QuotaChangeInProgress
+		change.RolledOut = nil
+		log.Infof("Quota change in progress", l.Fields{"from": change.From, "to": change.To})
+	}
+
+	// the steps rolled out are those whose workloads are updated and ready,
+	// up to the first that isn't
+	rolledOut := []string{}
+	var current *quota.Step
+	for _, step := range plan.Steps() {
+		ready, err := plan.Ready(ctx, serverClient, step, namespaces[step.Product])
+		if err != nil {
+			return err
+		}
+		if !ready {
+			step := step
+			current = &step
+			break
+		}
+		rolledOut = append(rolledOut, step.Name)
+	}
+	change.RolledOut = rolledOut
+
+	if current == nil {
+		log.Infof("Quota change rolled out", l.Fields{"from": change.From, "to": change.To})
+		// the workloads run the new quota, the next reconciles must not plan
+		// a change from the previous one whatever the stages complete
+		installation.Status.QuotaChange = nil
+		installation.Status.Quota = to.GetName()
+		installation.Status.ToQuota = ""
+		installationQuota.SetIsUpdated(true)
+		return nil
+	}
+
+	change.Message = fmt.Sprintf("rolling out %s", current.Name)
+	plan.Apply(append(rolledOut, current.Name), installationQuota)
+	return nil
+}
+
 func getSecretQuotaParam(installation *rhmiv1alpha1.RHMI, serverClient k8sclient.Client, namespace string) (string, error) {
 	// Check for normal addon quota parameter
 	quotaParam, found, err := addon.GetStringParameterByInstallType(context.TODO(), serverClient, rhmiv1alpha1.InstallationTypeManagedApi, namespace, addon.QuotaParamName)
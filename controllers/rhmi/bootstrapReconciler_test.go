@@ -5,14 +5,19 @@ import (
 	"errors"
 	"fmt"
 	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/addon"
 	moqclient "github.com/integr8ly/integreatly-operator/pkg/client"
 	"github.com/integr8ly/integreatly-operator/pkg/config"
+	"github.com/integr8ly/integreatly-operator/pkg/resources"
 	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/marketplace"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/quota"
 	prometheusv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -265,7 +270,7 @@ func assertRoleBindingNotFound(client k8sclient.Client) error {
 }
 
 func assertAllExpectedNamespacesAreReturned(client k8sclient.Client) error {
-	existingNamespaces, err := getRHOAMNamespaces(context.TODO(), client, "redhat-rhoam-")
+	existingNamespaces, err := resources.GetRHOAMNamespaces(context.TODO(), client, "redhat-rhoam-")
 	if err != nil {
 		return err
 	} else if existingNamespaces == nil {
@@ -350,7 +355,7 @@ func assertAllExpectedNamespacesAreReturned(client k8sclient.Client) error {
 
 func assertPrometheusRulesDeletion(client k8sclient.Client) error {
 	var allExistingRules []prometheusv1.PrometheusRule
-	rhoamProductNamespaces, err := getRHOAMNamespaces(context.TODO(), client, "redhat-rhoam-")
+	rhoamProductNamespaces, err := resources.GetRHOAMNamespaces(context.TODO(), client, "redhat-rhoam-")
 	if err != nil {
 		return err
 	}
@@ -618,3 +623,104 @@ func getNamespaces() *corev1.NamespaceList {
 		},
 	}
 }
+
+func TestReconciler_processQuotaChange(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = appsv1.AddToScheme(scheme)
+
+	// the 1 Million and 5 Million quotas differ by their keycloak replicas
+	keycloakConfig := func(replicas int) string {
+		return fmt.Sprintf(`{"%s": {"replicas": %d, "resources": {"requests": {"cpu": "650m", "memory": "2G"}}}}`, quota.KeycloakName, replicas)
+	}
+	keycloak := func(replicas int32) *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{
+			ObjectMeta: v1.ObjectMeta{Name: "keycloak", Namespace: userSsoNs},
+			Spec: appsv1.StatefulSetSpec{
+				Replicas: &replicas,
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{
+							Name: "keycloak",
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("650m"),
+									corev1.ResourceMemory: resource.MustParse("2G"),
+								},
+							},
+						}},
+					},
+				},
+			},
+			Status: appsv1.StatefulSetStatus{UpdatedReplicas: replicas, ReadyReplicas: replicas},
+		}
+	}
+	client := fakeclient.NewFakeClientWithScheme(scheme,
+		&corev1.ConfigMap{
+			ObjectMeta: v1.ObjectMeta{Name: quota.ConfigMapName, Namespace: rhoamOperatorNs},
+			Data: map[string]string{
+				quota.ConfigMapData: fmt.Sprintf(`[{"name": "1M", "param": "10", "resources": %s}, {"name": "5M", "param": "50", "resources": %s}]`, keycloakConfig(2), keycloakConfig(3)),
+			},
+		},
+		&corev1.Secret{
+			ObjectMeta: v1.ObjectMeta{Name: addon.GetParametersSecretName(integreatlyv1alpha1.InstallationTypeManagedApi), Namespace: rhoamOperatorNs},
+			Data:       map[string][]byte{addon.QuotaParamName: []byte("50")},
+		},
+		&corev1.Node{
+			ObjectMeta: v1.ObjectMeta{Name: "worker", Labels: map[string]string{resources.WorkerNodeLabel: ""}},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("1"),
+					corev1.ResourceMemory: resource.MustParse("4G"),
+				},
+			},
+		},
+		keycloak(2),
+	)
+	configManager := &config.ConfigReadWriterMock{
+		ReadProductFunc: func(product integreatlyv1alpha1.ProductName) (config.ConfigReadable, error) {
+			return config.NewRHSSOUser(config.ProductConfig{"NAMESPACE": userSsoNs}), nil
+		},
+	}
+	installation := &integreatlyv1alpha1.RHMI{
+		ObjectMeta: v1.ObjectMeta{Name: "rhoam", Namespace: rhoamOperatorNs},
+		Spec:       integreatlyv1alpha1.RHMISpec{Type: string(integreatlyv1alpha1.InstallationTypeManagedApi)},
+		Status:     integreatlyv1alpha1.RHMIStatus{Quota: "1M"},
+	}
+	reconciler, err := NewBootstrapReconciler(configManager, installation, &marketplace.MarketplaceInterfaceMock{}, record.NewFakeRecorder(50), l.NewLogger())
+	if err != nil {
+		t.Fatalf("Error creating bootstrap reconciler: %s", err)
+	}
+	processQuota := func() *quota.Quota {
+		installationQuota := &quota.Quota{}
+		if err := reconciler.processQuota(context.TODO(), installation, rhoamOperatorNs, installationQuota, client); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return installationQuota
+	}
+
+	// the keycloak replicas of the new quota are applied
+	installationQuota := processQuota()
+	if installation.Status.QuotaChange == nil || installation.Status.QuotaChange.Phase != integreatlyv1alpha1.QuotaChangeInProgress {
+		t.Fatalf("expected the quota change to be in progress, got %v", installation.Status.QuotaChange)
+	}
+	if installation.Status.Quota != "1M" || installationQuota.GetName() != "5M" || !installationQuota.IsUpdated() {
+		t.Fatalf("expected the 5M quota to be applied to the 1M installation, got %s applied to %s", installationQuota.GetName(), installation.Status.Quota)
+	}
+
+	// the rollout completes once keycloak is scaled, before the stages of the
+	// installation complete
+	if err := client.Update(context.TODO(), keycloak(3)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	processQuota()
+	if installation.Status.QuotaChange != nil || installation.Status.Quota != "5M" || installation.Status.ToQuota != "" {
+		t.Fatalf("expected the quota change to complete to 5M, got quota %s, change %v", installation.Status.Quota, installation.Status.QuotaChange)
+	}
+
+	// the capacity taken by the new quota doesn't block the next reconciles
+	installationQuota = processQuota()
+	if installation.Status.QuotaChange != nil || installationQuota.GetName() != "5M" || installationQuota.IsUpdated() {
+		t.Fatalf("expected no quota change after the rollout, got %v", installation.Status.QuotaChange)
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"net/http"
 	"os"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -43,6 +44,7 @@ import (
 	"github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
 	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
@@ -70,8 +72,16 @@ import (
 	"github.com/integr8ly/integreatly-operator/pkg/products"
 	marin3rconfig "github.com/integr8ly/integreatly-operator/pkg/products/marin3r/config"
 	"github.com/integr8ly/integreatly-operator/pkg/resources"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/capacity"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/leader"
 	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/marketplace"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/mustgather"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/networkpolicy"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/preflight"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/secretprovider"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/smtphealth"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/tracing"
 	"github.com/integr8ly/integreatly-operator/version"
 )
 
@@ -88,6 +98,12 @@ const (
 	priorityClassNameEnvName         = "PRIORITY_CLASS_NAME"
 	managedServicePriorityClassName  = "rhoam-pod-priority"
 	routeRequestUrl                  = "/apis/route.openshift.io/v1"
+
+	networkIsolationConditionType = "NetworkIsolation"
+	networkPolicyAuditInterval    = 10 * time.Minute
+	// maxReportedFlows is the number of blocked flows listed in the
+	// NetworkIsolation condition
+	maxReportedFlows = 10
 )
 
 var (
@@ -108,6 +124,15 @@ type RHMIReconciler struct {
 	restConfig      *rest.Config
 	customInformers map[string]map[string]*cache.Informer
 
+	smtpHealthMonitor *smtphealth.HealthMonitor
+
+	// inFlight lets the reconcile in flight finish before the leader election
+	// lease is released on shutdown
+	inFlight *leader.InFlight
+
+	networkPolicyAudited time.Time
+	blockedFlows         []networkpolicy.Flow
+
 	productsInstallationLoader marketplace.ProductsInstallationLoader
 }
 
@@ -121,6 +146,7 @@ func New(mgr ctrl.Manager) *RHMIReconciler {
 		mgr:             mgr,
 		restConfig:      restconfig,
 		customInformers: make(map[string]map[string]*cache.Informer),
+		inFlight:        leader.NewInFlight(),
 
 		productsInstallationLoader: marketplace.NewFSProductInstallationLoader(
 			marketplace.GetProductsInstallationPath(),
@@ -134,8 +160,8 @@ func New(mgr ctrl.Manager) *RHMIReconciler {
 // +kubebuilder:rbac:groups=integreatly.org,resources=rhmis,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=integreatly.org,resources=rhmis/status,verbs=get;update;patch
 
-// We need to create consolelinks which are cluster level objects
-// +kubebuilder:rbac:groups=console.openshift.io,resources=consolelinks,verbs=get;create;update;delete
+// We need to create consolelinks, and consolenotifications for the upgrade banner, which are cluster level objects
+// +kubebuilder:rbac:groups=console.openshift.io,resources=consolelinks;consolenotifications,verbs=get;create;update;delete
 
 // We are using ProjectRequests API to create namespaces where we automatically become admins
 // +kubebuilder:rbac:groups="";project.openshift.io,resources=projectrequests,verbs=create
@@ -158,6 +184,10 @@ func New(mgr ctrl.Manager) *RHMIReconciler {
 // We need to read this Secret from openshift-monitoring namespace in order to setup our monitoring stack
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get,resourceNames=grafana-datasources
 
+// The preflight checks read the cloud credential operator config and the credentials request of the cloud resources operator
+// +kubebuilder:rbac:groups=operator.openshift.io,resources=cloudcredentials,verbs=get,resourceNames=cluster
+// +kubebuilder:rbac:groups=cloudcredential.openshift.io,resources=credentialsrequests,verbs=get
+
 // OAuthClients are used for login into products with OpenShift User identity
 // +kubebuilder:rbac:groups=oauth.openshift.io,resources=oauthclients,verbs=create;get;update;delete
 
@@ -183,8 +213,10 @@ func New(mgr ctrl.Manager) *RHMIReconciler {
 // Permission to manage ValidatingWebhookConfiguration CRs pointing to the webhook server
 // +kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=validatingwebhookconfigurations;mutatingwebhookconfigurations,verbs=get;watch;list;create;update;delete
 
-// Permission to get the ConfigMap that embeds the CSV for an InstallPlan
-// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get
+// Permission to get the ConfigMap that embeds the CSV for an InstallPlan, and
+// to list the ConfigMaps holding OpenAPI documents imported into 3scale and
+// customer Grafana dashboards
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list
 
 // Permission for marin3r resources
 // +kubebuilder:rbac:groups=marin3r.3scale.net,resources=envoyconfigs,verbs=get;list;watch;create;update;delete
@@ -204,6 +236,11 @@ func New(mgr ctrl.Manager) *RHMIReconciler {
 // Observability
 // +kubebuilder:rbac:groups=observability.redhat.com,resources=observabilities,verbs=*
 
+// Isolating the RHOAM namespaces, and auditing the flows into them from pods
+// in any namespace
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;create;update;delete
+// +kubebuilder:rbac:groups="",resources=pods,verbs=list
+
 // Role permissions
 
 // +kubebuilder:rbac:groups="",resources=pods;events;configmaps;secrets,verbs=list;get;watch;create;update;patch,namespace=integreatly-operator
@@ -227,12 +264,27 @@ func New(mgr ctrl.Manager) *RHMIReconciler {
 // +kubebuilder:rbac:groups=operators.coreos.com,resources=clusterserviceversions,verbs=get;delete;list;update
 
 func (r *RHMIReconciler) Reconcile(request ctrl.Request) (ctrl.Result, error) {
-	_ = context.Background()
+	// the reconcile ID correlates the logs of the reconcile, of the products
+	// it reconciles and of the requests they make
+	ctx := l.ContextWithFields(context.Background(), l.Fields{
+		l.ReconcileIDLogContext:  l.NewReconcileID(),
+		l.InstallationLogContext: request.Name,
+	})
+	log := log.ForContext(ctx)
+	ctx, span := tracing.Start(ctx, "Reconcile", tracing.InstallationKey.String(request.Name))
+	defer span.End()
+
+	// the stages aren't started once the operator is shutting down, the next
+	// leader reconciles them
+	if !r.inFlight.Begin() {
+		return ctrl.Result{Requeue: true}, nil
+	}
+	defer r.inFlight.Done()
 
 	// your logic here
 	installInProgress := false
 	installation := &rhmiv1alpha1.RHMI{}
-	err := r.Get(context.TODO(), request.NamespacedName, installation)
+	err := r.Get(ctx, request.NamespacedName, installation)
 	if err != nil {
 		if k8serr.IsNotFound(err) {
 			return ctrl.Result{}, nil
@@ -240,6 +292,8 @@ func (r *RHMIReconciler) Reconcile(request ctrl.Request) (ctrl.Result, error) {
 		return ctrl.Result{}, err
 	}
 
+	r.reconcileMustGather(ctx, installation)
+
 	originalInstallation := installation.DeepCopy()
 
 	retryRequeue := ctrl.Result{
@@ -256,7 +310,7 @@ func (r *RHMIReconciler) Reconcile(request ctrl.Request) (ctrl.Result, error) {
 	if installation.Spec.AlertingEmailAddresses.CSSRE == "" && cssreAlertingEmailAddress != "" {
 		log.Info("Adding CS-SRE alerting email address to RHMI CR")
 		installation.Spec.AlertingEmailAddresses.CSSRE = cssreAlertingEmailAddress
-		err = r.Update(context.TODO(), installation)
+		err = r.Update(ctx, installation)
 		if err != nil {
 			log.Error("Error while copying alerting email addresses to RHMI CR", err)
 		}
@@ -266,14 +320,14 @@ func (r *RHMIReconciler) Reconcile(request ctrl.Request) (ctrl.Result, error) {
 	if installation.Spec.AlertingEmailAddresses.BusinessUnit == "" && buAlertingEmailAddress != "" {
 		log.Info("Adding BU alerting email address to RHMI CR")
 		installation.Spec.AlertingEmailAddresses.BusinessUnit = buAlertingEmailAddress
-		err = r.Update(context.TODO(), installation)
+		err = r.Update(ctx, installation)
 		if err != nil {
 			log.Error("Error while copying alerting email addresses to RHMI CR", err)
 		}
 	}
 
 	customerAlertingEmailAddress, ok, err := addon.GetStringParameterByInstallType(
-		context.TODO(),
+		ctx,
 		r.Client,
 		rhmiv1alpha1.InstallationType(installation.Spec.Type),
 		installation.Namespace,
@@ -284,7 +338,7 @@ func (r *RHMIReconciler) Reconcile(request ctrl.Request) (ctrl.Result, error) {
 	} else if ok && installation.Spec.AlertingEmailAddress != customerAlertingEmailAddress {
 		log.Info("Updating customer email address from parameter")
 		installation.Spec.AlertingEmailAddress = customerAlertingEmailAddress
-		if err := r.Update(context.TODO(), installation); err != nil {
+		if err := r.Update(ctx, installation); err != nil {
 			log.Error("Error while updating customer email address to RHMI CR", err)
 		}
 	}
@@ -304,20 +358,20 @@ func (r *RHMIReconciler) Reconcile(request ctrl.Request) (ctrl.Result, error) {
 	}
 	metrics.SetRHMIStatus(installation)
 
-	configManager, err := config.NewManager(context.TODO(), r.Client, request.NamespacedName.Namespace, installationCfgMap, installation)
+	configManager, err := config.NewManager(ctx, r.Client, request.NamespacedName.Namespace, installationCfgMap, installation)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
 
 	// Reconcile the webhooks
-	if err := webhooks.Config.Reconcile(context.TODO(), r.Client, installation); err != nil {
+	if err := webhooks.Config.Reconcile(ctx, r.Client, installation); err != nil {
 		return ctrl.Result{}, err
 	}
 
 	if !resources.Contains(installation.GetFinalizers(), deletionFinalizer) && installation.GetDeletionTimestamp() == nil {
 		if resources.Contains(installation.GetFinalizers(), previousDeletionFinalizer) {
 			installation.SetFinalizers(resources.Replace(installation.GetFinalizers(), previousDeletionFinalizer, deletionFinalizer))
-			if err = r.Update(context.TODO(), installation); err != nil {
+			if err = r.Update(ctx, installation); err != nil {
 				return ctrl.Result{}, err
 			}
 		} else {
@@ -337,14 +391,14 @@ func (r *RHMIReconciler) Reconcile(request ctrl.Request) (ctrl.Result, error) {
 
 	// If the CR is being deleted, handle uninstall and return
 	if installation.DeletionTimestamp != nil {
-		return r.handleUninstall(installation, installType, request)
+		return r.handleUninstall(ctx, installation, installType, request)
 	}
 
 	// If no current or target version is set this is the first installation of rhmi.
 	if upgradeFirstReconcile(installation) || firstInstallFirstReconcile(installation) {
 		installation.Status.ToVersion = version.GetVersionByType(installation.Spec.Type)
 		log.Infof("Setting installation.Status.ToVersion on initial install", l.Fields{"version": version.GetVersionByType(installation.Spec.Type)})
-		if err := r.Status().Update(context.TODO(), installation); err != nil {
+		if err := r.Status().Update(ctx, installation); err != nil {
 			return ctrl.Result{}, err
 		}
 		metrics.SetRhmiVersions(string(installation.Status.Stage), installation.Status.Version, installation.Status.ToVersion, installation.CreationTimestamp.Unix())
@@ -364,22 +418,32 @@ func (r *RHMIReconciler) Reconcile(request ctrl.Request) (ctrl.Result, error) {
 		return ctrl.Result{}, fmt.Errorf("error creating client for alerts: %v", err)
 	}
 	// reconciles rhmi installation alerts
-	_, err = r.newAlertsReconciler(installation).ReconcileAlerts(context.TODO(), alertsClient)
+	_, err = r.newAlertsReconciler(installation).ReconcileAlerts(ctx, alertsClient)
 	if err != nil {
 		log.Error("Error reconciling alerts for the rhmi installation", err)
 	}
 
+	// selects the SMTP provider the products send email with, before the
+	// stages reconcile the SMTP credentials
+	r.reconcileSMTPHealth(installation)
+
+	metrics.SetInstallationDuration(installation)
 	installationQuota := &quota.Quota{}
 	for _, stage := range installType.GetInstallStages() {
 		var err error
 		var stagePhase rhmiv1alpha1.StatusPhase
-		var stageLog = l.NewLoggerWithContext(l.Fields{l.StageLogContext: stage.Name})
+		stageCtx := l.ContextWithFields(ctx, l.Fields{l.StageLogContext: stage.Name})
+		stageCtx, stageSpan := tracing.Start(stageCtx, "Stage", tracing.StageKey.String(string(stage.Name)))
+		var stageLog = log.WithFields(l.Fields{l.StageLogContext: stage.Name})
+		stageStart := time.Now()
 
 		if stage.Name == rhmiv1alpha1.BootstrapStage {
-			stagePhase, err = r.bootstrapStage(installation, configManager, stageLog, installationQuota, request)
+			stagePhase, err = r.bootstrapStage(stageCtx, installation, configManager, stageLog, installationQuota, request)
 		} else {
-			stagePhase, err = r.processStage(installation, &stage, configManager, installationQuota, stageLog)
+			stagePhase, err = r.processStage(stageCtx, installation, &stage, configManager, installationQuota, stageLog)
 		}
+		tracing.End(stageSpan, stagePhase, err)
+		metrics.ObserveStageReconcile(stage.Name, time.Since(stageStart))
 
 		if installation.Status.Stages == nil {
 			installation.Status.Stages = make(map[rhmiv1alpha1.StageName]rhmiv1alpha1.RHMIStageStatus)
@@ -406,10 +470,11 @@ func (r *RHMIReconciler) Reconcile(request ctrl.Request) (ctrl.Result, error) {
 
 	// Entered on first reconcile where all stages reported complete after an upgrade / install
 	if installation.Status.ToVersion == version.GetVersionByType(installation.Spec.Type) && !installInProgress && !productVersionMismatchFound {
+		metrics.SetInstallationDuration(installation)
 		installation.Status.Version = version.GetVersionByType(installation.Spec.Type)
 		installation.Status.ToVersion = ""
 		metrics.SetRhmiVersions(string(installation.Status.Stage), installation.Status.Version, installation.Status.ToVersion, installation.CreationTimestamp.Unix())
-		if rhmiv1alpha1.IsRHOAM(rhmiv1alpha1.InstallationType(installation.Spec.Type)) {
+		if rhmiv1alpha1.IsRHOAM(rhmiv1alpha1.InstallationType(installation.Spec.Type)) && installation.Status.QuotaChange == nil {
 			installation.Status.Quota = installationQuota.GetName()
 			installation.Status.ToQuota = ""
 		}
@@ -426,20 +491,28 @@ func (r *RHMIReconciler) Reconcile(request ctrl.Request) (ctrl.Result, error) {
 		}
 
 		if rhmiv1alpha1.IsRHOAM(rhmiv1alpha1.InstallationType(installation.Spec.Type)) {
-			if installationQuota.IsUpdated() {
+			// the quota changes once its rollout completes
+			if installationQuota.IsUpdated() && installation.Status.QuotaChange == nil {
 				installation.Status.Quota = installationQuota.GetName()
 				installation.Status.ToQuota = ""
 				metrics.SetQuota(installation.Status.Quota, installation.Status.ToQuota)
 			}
+			// check the workloads of a quota change in progress sooner
+			if installation.Status.QuotaChange != nil && installation.Status.QuotaChange.Phase == rhmiv1alpha1.QuotaChangeInProgress {
+				retryRequeue.RequeueAfter = 30 * time.Second
+			}
+			r.reconcileCapacityForecasts(installation, configManager)
+			r.reconcileNetworkPolicies(installation, installType, configManager)
 		}
 	}
 	metrics.SetRHMIStatus(installation)
+	span.SetAttributes(tracing.StageKey.String(string(installation.Status.Stage)))
 
 	err = r.updateStatusAndObject(originalInstallation, installation)
 	return retryRequeue, err
 }
 
-func (r *RHMIReconciler) createSilence(installation *rhmiv1alpha1.RHMI, rc *rest.Config, configManager *config.Manager) error {
+func (r *RHMIReconciler) createSilence(ctx context.Context, installation *rhmiv1alpha1.RHMI, rc *rest.Config, configManager *config.Manager) error {
 
 	alertingNamespaces, err := r.getAlertingNamespace(installation, configManager)
 	if err != nil {
@@ -448,12 +521,12 @@ func (r *RHMIReconciler) createSilence(installation *rhmiv1alpha1.RHMI, rc *rest
 
 	for namespace, route := range alertingNamespaces {
 		for _, alert := range alertsToSilence {
-			exists, err := r.silenceExists(namespace, route, rc, alert)
+			exists, err := r.silenceExists(ctx, namespace, route, rc, alert)
 			if err != nil {
 				log.Error("Error checking for silence : %w", err)
 			}
 			if !exists {
-				err := r.silenceAlert(namespace, route, rc, alert)
+				err := r.silenceAlert(ctx, namespace, route, rc, alert)
 				if err != nil {
 					log.Error("error silencing alert : %w", err)
 				}
@@ -489,7 +562,7 @@ func (r *RHMIReconciler) getAlertingNamespace(installation *rhmiv1alpha1.RHMI, c
 	return alertingNamespaces, nil
 }
 
-func (r *RHMIReconciler) silenceAlert(namespace string, route string, rc *rest.Config, alertName string) error {
+func (r *RHMIReconciler) silenceAlert(ctx context.Context, namespace string, route string, rc *rest.Config, alertName string) error {
 
 	endpoint := "/api/v1/silences"
 	startsAt := strfmt.DateTime(time.Now())
@@ -528,7 +601,7 @@ func (r *RHMIReconciler) silenceAlert(namespace string, route string, rc *rest.C
 		return fmt.Errorf("error encoding silence : %w", err)
 	}
 
-	req, err := http.NewRequest("POST", url, reqBodyBytes)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, reqBodyBytes)
 	if err != nil {
 		return fmt.Errorf("error on request : %w", err)
 	}
@@ -536,7 +609,7 @@ func (r *RHMIReconciler) silenceAlert(namespace string, route string, rc *rest.C
 	req.Header.Add("Authorization", bearer)
 	req.Header.Add("Content-Type", "application/json")
 
-	client := &http.Client{}
+	client := &http.Client{Transport: l.NewTransport(ctx, tracing.NewTransport(ctx, nil))}
 	client.Timeout = time.Second * 10
 
 	resp, err := client.Do(req)
@@ -552,7 +625,7 @@ func (r *RHMIReconciler) silenceAlert(namespace string, route string, rc *rest.C
 	return nil
 }
 
-func (r *RHMIReconciler) silenceExists(namespace string, route string, rc *rest.Config, alert string) (bool, error) {
+func (r *RHMIReconciler) silenceExists(ctx context.Context, namespace string, route string, rc *rest.Config, alert string) (bool, error) {
 	silenceExists := false
 	endpoint := "/api/v2/silences"
 
@@ -564,14 +637,14 @@ func (r *RHMIReconciler) silenceExists(namespace string, route string, rc *rest.
 
 	var bearer = "Bearer " + r.restConfig.BearerToken
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return false, fmt.Errorf("error on request : %w", err)
 	}
 
 	req.Header.Add("Authorization", bearer)
 
-	client := &http.Client{}
+	client := &http.Client{Transport: l.NewTransport(ctx, tracing.NewTransport(ctx, nil))}
 	client.Timeout = time.Second * 10
 
 	resp, err := client.Do(req)
@@ -630,6 +703,163 @@ func (r *RHMIReconciler) getURLFromRoute(routeName string, namespace string, rc
 	return host, nil
 }
 
+// reconcileCapacityForecasts reads the capacity forecasts of the cloud
+// resources from the observability Prometheus and publishes them in the
+// installation status. The previous forecasts are kept if they can't be read
+func (r *RHMIReconciler) reconcileCapacityForecasts(installation *rhmiv1alpha1.RHMI, configManager *config.Manager) {
+	observabilityConfig, err := configManager.ReadObservability()
+	if err != nil {
+		log.Error("Error reading observability config for capacity forecasts", err)
+		return
+	}
+
+	url, err := r.getURLFromRoute(observabilityConfig.GetPrometheusRouteName(), observabilityConfig.GetNamespace(), r.restConfig)
+	if err != nil {
+		log.Error("Error getting prometheus route for capacity forecasts", err)
+		return
+	}
+
+	forecaster, err := capacity.NewPrometheusForecaster(url, r.restConfig.BearerToken)
+	if err != nil {
+		log.Error("Error creating capacity forecaster", err)
+		return
+	}
+	forecasts, err := forecaster.Forecast(context.TODO())
+	if err != nil {
+		log.Error("Error reading capacity forecasts", err)
+		return
+	}
+	installation.Status.CapacityForecasts = forecasts
+}
+
+// reconcileSMTPHealth sets the SMTP provider email is sent with from the last
+// check of the health monitor
+func (r *RHMIReconciler) reconcileSMTPHealth(installation *rhmiv1alpha1.RHMI) {
+	if r.smtpHealthMonitor == nil {
+		return
+	}
+	previous := installation.Status.SMTPActiveSecret
+	smtphealth.SetStatus(installation, r.smtpHealthMonitor.Results())
+	if previous != "" && previous != installation.Status.SMTPActiveSecret {
+		log.Warningf("SMTP provider failover", l.Fields{"from": previous, "to": installation.Status.SMTPActiveSecret})
+	}
+}
+
+// reconcileNetworkPolicies isolates the namespaces of the installation in
+// enforce mode, and removes the policies otherwise. The flows the policies
+// block, or would block in audit mode, are audited every
+// networkPolicyAuditInterval
+func (r *RHMIReconciler) reconcileNetworkPolicies(installation *rhmiv1alpha1.RHMI, installType *Type, configManager *config.Manager) {
+	mode := installation.Spec.NetworkPolicyMode
+	if mode == "" {
+		mode = rhmiv1alpha1.NetworkPolicyModeAudit
+	}
+
+	rules, err := networkPolicyRules(installation, installType, configManager)
+	if err != nil {
+		log.Error("Error getting the namespaces to isolate with network policies", err)
+		return
+	}
+
+	ctx := context.TODO()
+	if mode == rhmiv1alpha1.NetworkPolicyModeEnforce {
+		if err := networkpolicy.LabelNamespace(ctx, r.Client, installation.Namespace); err != nil {
+			log.Error("Error labelling the operator namespace for network policies", err)
+			return
+		}
+	}
+	for _, namespace := range sortedNamespaces(rules) {
+		if mode == rhmiv1alpha1.NetworkPolicyModeEnforce {
+			err = networkpolicy.Reconcile(ctx, r.Client, namespace, rules[namespace])
+		} else {
+			err = networkpolicy.Remove(ctx, r.Client, namespace)
+		}
+		if err != nil {
+			log.Error("Error reconciling network policies", err)
+		}
+	}
+
+	if mode == rhmiv1alpha1.NetworkPolicyModeDisabled {
+		r.blockedFlows = nil
+		metrics.ResetNetworkPolicyBlockedFlows()
+		meta.RemoveStatusCondition(&installation.Status.Conditions, networkIsolationConditionType)
+		return
+	}
+
+	if time.Since(r.networkPolicyAudited) >= networkPolicyAuditInterval {
+		blocked, err := networkpolicy.Audit(ctx, r.mgr.GetAPIReader(), rules)
+		if err != nil {
+			log.Error("Error auditing the flows blocked by network policies", err)
+		} else {
+			r.networkPolicyAudited = time.Now()
+			r.blockedFlows = blocked
+			metrics.ResetNetworkPolicyBlockedFlows()
+			for _, flow := range blocked {
+				metrics.IncNetworkPolicyBlockedFlows(flow.SourceNamespace, flow.Namespace)
+				log.Warningf("Flow blocked by network policies", l.Fields{"flow": flow.String(), "mode": mode})
+			}
+		}
+	}
+
+	condition := metav1.Condition{
+		Type:    networkIsolationConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Enforced",
+		Message: "The namespaces are isolated by network policies",
+	}
+	if mode == rhmiv1alpha1.NetworkPolicyModeAudit {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "Audit"
+		condition.Message = "The network policies are audited without being enforced"
+	}
+	if len(r.blockedFlows) > 0 {
+		flows := []string{}
+		for i, flow := range r.blockedFlows {
+			if i == maxReportedFlows {
+				flows = append(flows, fmt.Sprintf("and %d more", len(r.blockedFlows)-maxReportedFlows))
+				break
+			}
+			flows = append(flows, flow.String())
+		}
+		condition.Message = fmt.Sprintf("%s. %d flows are blocked: %s", condition.Message, len(r.blockedFlows), strings.Join(flows, ", "))
+	}
+	meta.SetStatusCondition(&installation.Status.Conditions, condition)
+}
+
+// networkPolicyRules returns the rules of the namespaces of the products of
+// the installation
+func networkPolicyRules(installation *rhmiv1alpha1.RHMI, installType *Type, configManager *config.Manager) (map[string][]networkpolicy.Rule, error) {
+	products := map[rhmiv1alpha1.ProductName]networkpolicy.ProductNamespaces{}
+	for _, stage := range installType.GetInstallStages() {
+		for product := range stage.Products {
+			productConfig, err := configManager.ReadProduct(product)
+			if err != nil {
+				return nil, err
+			}
+			namespaces := networkpolicy.ProductNamespaces{Namespace: productConfig.GetNamespace()}
+			if operatorConfig, ok := productConfig.(interface{ GetOperatorNamespace() string }); ok {
+				namespaces.OperatorNamespace = operatorConfig.GetOperatorNamespace()
+			}
+			products[product] = namespaces
+		}
+	}
+
+	observabilityConfig, err := configManager.ReadObservability()
+	if err != nil {
+		return nil, err
+	}
+	return networkpolicy.Rules(products, installation.Namespace, observabilityConfig.GetNamespace()), nil
+}
+
+func sortedNamespaces(rules map[string][]networkpolicy.Rule) []string {
+	namespaces := make([]string, 0, len(rules))
+	for namespace := range rules {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
+
 func (r *RHMIReconciler) reconcilePodDistribution(installation *rhmiv1alpha1.RHMI) {
 
 	serverClient, err := k8sclient.New(r.restConfig, k8sclient.Options{})
@@ -645,6 +875,40 @@ func (r *RHMIReconciler) reconcilePodDistribution(installation *rhmiv1alpha1.RHM
 	}
 }
 
+// reconcileMustGather gathers the state of the installation when requested by
+// the must-gather annotation and writes its summary to a config map. The
+// annotation is removed so the gather runs once per request
+func (r *RHMIReconciler) reconcileMustGather(ctx context.Context, installation *rhmiv1alpha1.RHMI) {
+	if _, ok := installation.Annotations[mustgather.Annotation]; !ok {
+		return
+	}
+
+	// the product namespaces aren't in the cache of the manager
+	serverClient, err := k8sclient.New(r.restConfig, k8sclient.Options{
+		Scheme: r.mgr.GetScheme(),
+	})
+	if err != nil {
+		log.Error("Error getting server client for must-gather", err)
+		return
+	}
+	bundle, err := mustgather.NewGatherer(serverClient, r.mgr.GetScheme(), installation.Namespace, mustgather.Options{}).Gather(ctx)
+	if err != nil {
+		log.Error("Error gathering the installation", err)
+		return
+	}
+	if err := mustgather.WriteSummary(ctx, serverClient, installation.Namespace, bundle); err != nil {
+		log.Error("Error writing the must-gather summary", err)
+		return
+	}
+	log.Infof("Gathered the installation", l.Fields{"configMap": mustgather.SummaryConfigMapName, "files": len(bundle.Files), "errors": len(bundle.Errors)})
+
+	patch := k8sclient.MergeFrom(installation.DeepCopy())
+	delete(installation.Annotations, mustgather.Annotation)
+	if err := r.Patch(ctx, installation, patch); err != nil {
+		log.Error("Error removing the must-gather annotation", err)
+	}
+}
+
 func (r *RHMIReconciler) updateStatusAndObject(original, installation *rhmiv1alpha1.RHMI) error {
 	if !reflect.DeepEqual(original.Status, installation.Status) {
 		log.Info("updating status")
@@ -664,7 +928,7 @@ func (r *RHMIReconciler) updateStatusAndObject(original, installation *rhmiv1alp
 	return nil
 }
 
-func (r *RHMIReconciler) handleUninstall(installation *rhmiv1alpha1.RHMI, installationType *Type, request ctrl.Request) (ctrl.Result, error) {
+func (r *RHMIReconciler) handleUninstall(ctx context.Context, installation *rhmiv1alpha1.RHMI, installationType *Type, request ctrl.Request) (ctrl.Result, error) {
 	retryRequeue := ctrl.Result{
 		Requeue:      true,
 		RequeueAfter: 10 * time.Second,
@@ -673,7 +937,7 @@ func (r *RHMIReconciler) handleUninstall(installation *rhmiv1alpha1.RHMI, instal
 	if installationCfgMap == "" {
 		installationCfgMap = installation.Spec.NamespacePrefix + DefaultInstallationConfigMapName
 	}
-	configManager, err := config.NewManager(context.TODO(), r.Client, installation.Namespace, installationCfgMap, installation)
+	configManager, err := config.NewManager(ctx, r.Client, installation.Namespace, installationCfgMap, installation)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
@@ -693,20 +957,20 @@ func (r *RHMIReconciler) handleUninstall(installation *rhmiv1alpha1.RHMI, instal
 		Version: "v1",
 	})
 	ls, _ := labels.Parse("integreatly=yes")
-	if err := r.Client.List(context.TODO(), alerts, &k8sclient.ListOptions{
+	if err := r.Client.List(ctx, alerts, &k8sclient.ListOptions{
 		LabelSelector: ls,
 	}); err != nil {
 		return ctrl.Result{}, err
 	}
 
 	for _, alert := range alerts.Items {
-		if err := r.Client.Delete(context.TODO(), &alert); err != nil {
+		if err := r.Client.Delete(ctx, &alert); err != nil {
 			return ctrl.Result{}, err
 		}
 	}
 
 	log.Info("Creating Silence for alerts")
-	err = r.createSilence(installation, r.restConfig, configManager)
+	err = r.createSilence(ctx, installation, r.restConfig, configManager)
 
 	if err != nil {
 		log.Error("Error creating silence", err)
@@ -728,10 +992,10 @@ func (r *RHMIReconciler) handleUninstall(installation *rhmiv1alpha1.RHMI, instal
 	for _, stage := range installationType.UninstallStages {
 		pendingUninstalls := false
 		if stage.Name == rhmiv1alpha1.BootstrapStage {
-			pendingUninstalls = r.handleUninstallBootstrap(installation, finalizers, stage, configManager, merr, request)
+			pendingUninstalls = r.handleUninstallBootstrap(ctx, installation, finalizers, stage, configManager, merr, request)
 		} else {
 			for product := range stage.Products {
-				productPending := r.handleUninstallProduct(installation, product, stage, finalizers, configManager, merr)
+				productPending := r.handleUninstallProduct(ctx, installation, product, stage, finalizers, configManager, merr)
 				if productPending && !pendingUninstalls {
 					pendingUninstalls = true
 				}
@@ -742,9 +1006,9 @@ func (r *RHMIReconciler) handleUninstall(installation *rhmiv1alpha1.RHMI, instal
 		if pendingUninstalls {
 			if len(merr.Errors) > 0 {
 				installation.Status.LastError = merr.Error()
-				r.Client.Status().Update(context.TODO(), installation)
+				r.Client.Status().Update(ctx, installation)
 			}
-			err = r.Client.Update(context.TODO(), installation)
+			err = r.Client.Update(ctx, installation)
 			if err != nil {
 				merr.Add(err)
 			}
@@ -756,10 +1020,10 @@ func (r *RHMIReconciler) handleUninstall(installation *rhmiv1alpha1.RHMI, instal
 	if len(installation.Finalizers) == 1 && installation.Finalizers[0] == deletionFinalizer {
 		log.Infof("Finalizers: ", l.Fields{"length": len(installation.Finalizers)})
 		// delete ConfigMap after all product finalizers finished
-		if err := r.Client.Delete(context.TODO(), &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: installationCfgMap, Namespace: installation.Namespace}}); err != nil && !k8serr.IsNotFound(err) {
+		if err := r.Client.Delete(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: installationCfgMap, Namespace: installation.Namespace}}); err != nil && !k8serr.IsNotFound(err) {
 			merr.Add(fmt.Errorf("failed to remove installation ConfigMap: %w", err))
 			installation.Status.LastError = merr.Error()
-			err = r.Client.Update(context.TODO(), installation)
+			err = r.Client.Update(ctx, installation)
 			if err != nil {
 				merr.Add(err)
 			}
@@ -769,7 +1033,7 @@ func (r *RHMIReconciler) handleUninstall(installation *rhmiv1alpha1.RHMI, instal
 		if err = r.handleCROConfigDeletion(*installation); err != nil && !k8serr.IsNotFound(err) {
 			merr.Add(fmt.Errorf("failed to remove Cloud Resource ConfigMap: %w", err))
 			installation.Status.LastError = merr.Error()
-			err = r.Update(context.TODO(), installation)
+			err = r.Update(ctx, installation)
 			if err != nil {
 				merr.Add(err)
 			}
@@ -778,13 +1042,13 @@ func (r *RHMIReconciler) handleUninstall(installation *rhmiv1alpha1.RHMI, instal
 
 		installation.SetFinalizers(resources.Remove(installation.GetFinalizers(), deletionFinalizer))
 
-		err = r.Update(context.TODO(), installation)
+		err = r.Update(ctx, installation)
 		if err != nil {
 			merr.Add(err)
 			return retryRequeue, merr
 		}
 
-		if err := addon.UninstallOperator(context.TODO(), r.Client, installation); err != nil {
+		if err := addon.UninstallOperator(ctx, r.Client, installation); err != nil {
 			merr.Add(err)
 			return retryRequeue, merr
 		}
@@ -795,11 +1059,11 @@ func (r *RHMIReconciler) handleUninstall(installation *rhmiv1alpha1.RHMI, instal
 
 	log.Info("updating uninstallation object")
 	// no finalizers left, update object
-	err = r.Update(context.TODO(), installation)
+	err = r.Update(ctx, installation)
 	return retryRequeue, err
 }
 
-func (r *RHMIReconciler) handleUninstallProduct(installation *rhmiv1alpha1.RHMI, product rhmiv1alpha1.ProductName, stage Stage, finalizers []string, configManager *config.Manager, merr *resources.MultiErr) bool {
+func (r *RHMIReconciler) handleUninstallProduct(ctx context.Context, installation *rhmiv1alpha1.RHMI, product rhmiv1alpha1.ProductName, stage Stage, finalizers []string, configManager *config.Manager, merr *resources.MultiErr) bool {
 	productName := string(product)
 	log.Infof("Uninstalling ", l.Fields{"product": productName, "stage": stage.Name})
 	productStatus := installation.GetProductStatusObject(product)
@@ -808,7 +1072,7 @@ func (r *RHMIReconciler) handleUninstallProduct(installation *rhmiv1alpha1.RHMI,
 		if !strings.Contains(productFinalizer, productName) {
 			continue
 		}
-		reconciler, err := products.NewReconciler(product, r.restConfig, configManager, installation, r.mgr, log, r.productsInstallationLoader)
+		reconciler, err := products.NewReconciler(ctx, product, r.restConfig, configManager, installation, r.mgr, log, r.productsInstallationLoader)
 		if err != nil {
 			merr.Add(fmt.Errorf("Failed to build reconciler for product %s: %w", productName, err))
 		}
@@ -822,7 +1086,7 @@ func (r *RHMIReconciler) handleUninstallProduct(installation *rhmiv1alpha1.RHMI,
 		if productStatus.Uninstall || installation.DeletionTimestamp != nil {
 			uninstall = true
 		}
-		phase, err := reconciler.Reconcile(context.TODO(), installation, productStatus, serverClient, quota.QuotaProductConfig{}, uninstall)
+		phase, err := reconciler.Reconcile(ctx, installation, productStatus, serverClient, quota.QuotaProductConfig{}, uninstall)
 		if err != nil {
 			merr.Add(fmt.Errorf("Failed to reconcile product %s: %w", productName, err))
 		}
@@ -834,7 +1098,7 @@ func (r *RHMIReconciler) handleUninstallProduct(installation *rhmiv1alpha1.RHMI,
 	return false
 }
 
-func (r *RHMIReconciler) handleUninstallBootstrap(installation *rhmiv1alpha1.RHMI, finalizers []string, stage Stage, configManager *config.Manager, merr *resources.MultiErr, request ctrl.Request) bool {
+func (r *RHMIReconciler) handleUninstallBootstrap(ctx context.Context, installation *rhmiv1alpha1.RHMI, finalizers []string, stage Stage, configManager *config.Manager, merr *resources.MultiErr, request ctrl.Request) bool {
 	for _, productFinalizer := range finalizers {
 		if !strings.Contains(productFinalizer, "observability") {
 			continue
@@ -854,7 +1118,7 @@ func (r *RHMIReconciler) handleUninstallBootstrap(installation *rhmiv1alpha1.RHM
 			merr.Add(fmt.Errorf("could not create server client: %w", err))
 		}
 
-		phase, err := reconciler.Reconcile(context.TODO(), installation, serverClient, &quota.Quota{}, request)
+		phase, err := reconciler.Reconcile(ctx, installation, serverClient, &quota.Quota{}, request)
 		if err != nil {
 			merr.Add(fmt.Errorf("Failed to reconcile bootstrap: %w", err))
 		}
@@ -915,17 +1179,19 @@ func (r *RHMIReconciler) preflightChecks(installation *rhmiv1alpha1.RHMI, instal
 	if rhmiv1alpha1.IsManaged(rhmiv1alpha1.InstallationType(installation.Spec.Type)) {
 		requiredSecrets := []string{installation.Spec.PagerDutySecret, installation.Spec.DeadMansSnitchSecret}
 
+		secretProvider, err := secretprovider.New(r.Client, installation.Namespace)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+
 		for _, secretName := range requiredSecrets {
-			secret := &corev1.Secret{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      secretName,
-					Namespace: installation.Namespace,
-				},
-			}
-			if exists, err := resources.Exists(context.TODO(), r.Client, secret); err != nil {
+			if _, err := secretProvider.GetSecret(context.TODO(), secretName); err != nil && !secretprovider.IsNotFound(err) {
 				return ctrl.Result{}, err
-			} else if !exists {
-				preflightMessage := fmt.Sprintf("Could not find %s secret in %s namespace", secret.Name, installation.Namespace)
+			} else if err != nil {
+				preflightMessage := fmt.Sprintf("Could not find %s secret in the %s secret provider", secretName, secretprovider.Backend())
+				if secretprovider.Backend() == secretprovider.BackendKubernetes {
+					preflightMessage = fmt.Sprintf("Could not find %s secret in %s namespace", secretName, installation.Namespace)
+				}
 				log.Info(preflightMessage)
 				eventRecorder.Event(installation, "Warning", rhmiv1alpha1.EventProcessingError, preflightMessage)
 
@@ -933,7 +1199,7 @@ func (r *RHMIReconciler) preflightChecks(installation *rhmiv1alpha1.RHMI, instal
 				installation.Status.PreflightMessage = preflightMessage
 				_ = r.Status().Update(context.TODO(), installation)
 
-				return ctrl.Result{}, err
+				return ctrl.Result{}, nil
 			}
 			log.Infof("found required secret", l.Fields{"secret": secretName})
 			eventRecorder.Eventf(installation, "Normal", rhmiv1alpha1.EventPreflightCheckPassed,
@@ -997,42 +1263,105 @@ func (r *RHMIReconciler) preflightChecks(installation *rhmiv1alpha1.RHMI, instal
 		}
 	}
 
+	// new client to avoid caching issues
+	serverClient, err := k8sclient.New(r.restConfig, k8sclient.Options{
+		Scheme: r.mgr.GetScheme(),
+	})
+	if err != nil {
+		return result, err
+	}
+
+	var installationQuota *quota.Quota
+	if rhmiv1alpha1.IsRHOAM(rhmiv1alpha1.InstallationType(installation.Spec.Type)) {
+		installationQuota, err = getPreflightQuota(installation, serverClient)
+		if err != nil {
+			log.Warningf("quota not found for the node capacity preflight check", l.Fields{"error": err.Error()})
+		}
+	}
+
+	// the namespace of the cloud resources operator is set once it is
+	// installed
+	cloudResourcesNamespace := ""
+	if cloudResourcesConfig, err := configManager.ReadCloudResources(); err == nil {
+		cloudResourcesNamespace = cloudResourcesConfig.GetOperatorNamespace()
+	}
+
+	checks := append([]preflight.Check{
+		preflight.NewCheck("conflicting-products", rhmiv1alpha1.PreflightSeverityBlocking, func(ctx context.Context, installation *rhmiv1alpha1.RHMI) error {
+			return r.checkConflictingProducts(ctx, serverClient, installation, installationType, configManager)
+		}),
+	}, preflight.DefaultChecks(serverClient, installationQuota, cloudResourcesNamespace)...)
+	installation.Status.PreflightChecks = preflight.Run(context.TODO(), installation, checks)
+
+	if warnings := preflight.Failed(installation.Status.PreflightChecks, rhmiv1alpha1.PreflightSeverityWarning); len(warnings) > 0 {
+		eventRecorder.Event(installation, "Warning", rhmiv1alpha1.EventProcessingError, "preflight check warnings: "+preflight.Message(warnings))
+	}
+
+	if failed := preflight.Failed(installation.Status.PreflightChecks, rhmiv1alpha1.PreflightSeverityBlocking); len(failed) > 0 {
+		preflightMessage := "preflight checks failed: " + preflight.Message(failed)
+		log.Warning(preflightMessage)
+		eventRecorder.Event(installation, "Warning", rhmiv1alpha1.EventProcessingError, preflightMessage)
+
+		installation.Status.PreflightStatus = rhmiv1alpha1.PreflightFail
+		installation.Status.PreflightMessage = preflightMessage
+		_ = r.Status().Update(context.TODO(), installation)
+		return result, nil
+	}
+
+	installation.Status.PreflightStatus = rhmiv1alpha1.PreflightSuccess
+	installation.Status.PreflightMessage = "preflight checks passed"
+	err = r.Status().Update(context.TODO(), installation)
+	if err != nil {
+		log.Infof("error updating status", l.Fields{"error": err.Error()})
+	}
+	return result, nil
+}
+
+// checkConflictingProducts fails when existing installs of the products are
+// found in the namespaces of the cluster
+func (r *RHMIReconciler) checkConflictingProducts(ctx context.Context, serverClient k8sclient.Client, installation *rhmiv1alpha1.RHMI, installationType *Type, configManager *config.Manager) error {
 	log.Info("getting namespaces")
 	namespaces := &corev1.NamespaceList{}
-	err := r.List(context.TODO(), namespaces)
+	err := serverClient.List(ctx, namespaces)
 	if err != nil {
-		// could not list namespaces, keep trying
-		log.Warningf("error listing namespaces", l.Fields{"error": err.Error()})
-		return result, err
+		return fmt.Errorf("error listing namespaces: %w", err)
 	}
 
 	for _, ns := range namespaces.Items {
-		products, err := r.checkNamespaceForProducts(ns, installation, installationType, configManager)
+		products, err := r.checkNamespaceForProducts(ctx, ns, installation, installationType, configManager)
 		if err != nil {
-			// error searching for existing products, keep trying
-			log.Info("error looking for existing deployments, will retry")
-			return result, err
+			return fmt.Errorf("error looking for existing deployments: %w", err)
 		}
 		if len(products) != 0 {
 			//found one or more conflicting products
-			installation.Status.PreflightStatus = rhmiv1alpha1.PreflightFail
-			installation.Status.PreflightMessage = "found conflicting packages: " + strings.Join(products, ", ") + ", in namespace: " + ns.GetName()
 			log.Info("found conflicting packages: " + strings.Join(products, ", ") + ", in namespace: " + ns.GetName())
-			_ = r.Status().Update(context.TODO(), installation)
-			return result, err
+			return fmt.Errorf("found conflicting packages: %s, in namespace: %s", strings.Join(products, ", "), ns.GetName())
 		}
 	}
+	return nil
+}
 
-	installation.Status.PreflightStatus = rhmiv1alpha1.PreflightSuccess
-	installation.Status.PreflightMessage = "preflight checks passed"
-	err = r.Status().Update(context.TODO(), installation)
+// getPreflightQuota returns the quota the installation is created with, from
+// the add-on parameter or the env var
+func getPreflightQuota(installation *rhmiv1alpha1.RHMI, serverClient k8sclient.Client) (*quota.Quota, error) {
+	quotaParam, err := getSecretQuotaParam(installation, serverClient, installation.Namespace)
 	if err != nil {
-		log.Infof("error updating status", l.Fields{"error": err.Error()})
+		return nil, err
 	}
-	return result, nil
+
+	// the quota config map is created by the bootstrap stage, after the
+	// preflight checks
+	quotaConfig := &corev1.ConfigMap{
+		Data: map[string]string{quota.ConfigMapData: addon.GetQuotaConfig(installation.Spec.Type)},
+	}
+	installationQuota := &quota.Quota{}
+	if err := quota.GetQuota(quotaParam, quotaConfig, installationQuota); err != nil {
+		return nil, err
+	}
+	return installationQuota, nil
 }
 
-func (r *RHMIReconciler) checkNamespaceForProducts(ns corev1.Namespace, installation *rhmiv1alpha1.RHMI, installationType *Type, configManager *config.Manager) ([]string, error) {
+func (r *RHMIReconciler) checkNamespaceForProducts(ctx context.Context, ns corev1.Namespace, installation *rhmiv1alpha1.RHMI, installationType *Type, configManager *config.Manager) ([]string, error) {
 	foundProducts := []string{}
 	if strings.HasPrefix(ns.Name, "openshift-") {
 		return foundProducts, nil
@@ -1046,7 +1375,7 @@ func (r *RHMIReconciler) checkNamespaceForProducts(ns corev1.Namespace, installa
 	})
 	for _, stage := range installationType.InstallStages {
 		for _, product := range stage.Products {
-			reconciler, err := products.NewReconciler(product.Name, r.restConfig, configManager, installation, r.mgr, log, r.productsInstallationLoader)
+			reconciler, err := products.NewReconciler(ctx, product.Name, r.restConfig, configManager, installation, r.mgr, log, r.productsInstallationLoader)
 			if err != nil {
 				return foundProducts, err
 			}
@@ -1054,7 +1383,7 @@ func (r *RHMIReconciler) checkNamespaceForProducts(ns corev1.Namespace, installa
 			if search == nil {
 				continue
 			}
-			exists, err := resources.Exists(context.TODO(), serverClient, search)
+			exists, err := resources.Exists(ctx, serverClient, search)
 			if err != nil {
 				return foundProducts, err
 			} else if exists {
@@ -1066,7 +1395,7 @@ func (r *RHMIReconciler) checkNamespaceForProducts(ns corev1.Namespace, installa
 	return foundProducts, nil
 }
 
-func (r *RHMIReconciler) bootstrapStage(installation *rhmiv1alpha1.RHMI, configManager config.ConfigReadWriter, log l.Logger, quota *quota.Quota, request ctrl.Request) (rhmiv1alpha1.StatusPhase, error) {
+func (r *RHMIReconciler) bootstrapStage(ctx context.Context, installation *rhmiv1alpha1.RHMI, configManager config.ConfigReadWriter, log l.Logger, quota *quota.Quota, request ctrl.Request) (rhmiv1alpha1.StatusPhase, error) {
 	installation.Status.Stage = rhmiv1alpha1.BootstrapStage
 	mpm := marketplace.NewManager()
 
@@ -1081,7 +1410,7 @@ func (r *RHMIReconciler) bootstrapStage(installation *rhmiv1alpha1.RHMI, configM
 		return rhmiv1alpha1.PhaseFailed, fmt.Errorf("could not create server client: %w", err)
 	}
 
-	phase, err := reconciler.Reconcile(context.TODO(), installation, serverClient, quota, request)
+	phase, err := reconciler.Reconcile(ctx, installation, serverClient, quota, request)
 	if err != nil || phase == rhmiv1alpha1.PhaseFailed {
 		return rhmiv1alpha1.PhaseFailed, fmt.Errorf("Bootstrap stage reconcile failed: %w", err)
 	}
@@ -1089,7 +1418,7 @@ func (r *RHMIReconciler) bootstrapStage(installation *rhmiv1alpha1.RHMI, configM
 	return phase, nil
 }
 
-func (r *RHMIReconciler) processStage(installation *rhmiv1alpha1.RHMI, stage *Stage,
+func (r *RHMIReconciler) processStage(ctx context.Context, installation *rhmiv1alpha1.RHMI, stage *Stage,
 	configManager config.ConfigReadWriter, quotaconfig *quota.Quota, _ l.Logger) (rhmiv1alpha1.StatusPhase, error) {
 	incompleteStage := false
 	productVersionMismatchFound = false
@@ -1099,12 +1428,18 @@ func (r *RHMIReconciler) processStage(installation *rhmiv1alpha1.RHMI, stage *St
 	installation.Status.Stage = stage.Name
 
 	for productName, productStatus := range stage.Products {
-		productLog := l.NewLoggerWithContext(l.Fields{l.ProductLogContext: productStatus.Name})
+		productCtx := l.ContextWithFields(ctx, l.Fields{l.ProductLogContext: productStatus.Name})
+		productLog := l.NewLogger().ForContext(productCtx)
+		// the span covers the build of the reconciler, that logs in to the
+		// product APIs
+		productCtx, productSpan := tracing.Start(productCtx, "Product", tracing.ProductKey.String(string(productStatus.Name)))
 
-		reconciler, err := products.NewReconciler(productStatus.Name, r.restConfig, configManager, installation, r.mgr, productLog, r.productsInstallationLoader)
+		reconciler, err := products.NewReconciler(productCtx, productStatus.Name, r.restConfig, configManager, installation, r.mgr, productLog, r.productsInstallationLoader)
 
 		if err != nil {
-			return rhmiv1alpha1.PhaseFailed, fmt.Errorf("failed to build a reconciler for %s: %w", productStatus.Name, err)
+			err = fmt.Errorf("failed to build a reconciler for %s: %w", productStatus.Name, err)
+			tracing.End(productSpan, rhmiv1alpha1.PhaseFailed, err)
+			return rhmiv1alpha1.PhaseFailed, err
 		}
 
 		if !reconciler.VerifyVersion(installation) {
@@ -1115,14 +1450,26 @@ func (r *RHMIReconciler) processStage(installation *rhmiv1alpha1.RHMI, stage *St
 			Scheme: r.mgr.GetScheme(),
 		})
 		if err != nil {
-			return rhmiv1alpha1.PhaseFailed, fmt.Errorf("could not create server client: %w", err)
+			err = fmt.Errorf("could not create server client: %w", err)
+			tracing.End(productSpan, rhmiv1alpha1.PhaseFailed, err)
+			return rhmiv1alpha1.PhaseFailed, err
 		}
 
 		uninstall := false
 		if productStatus.Uninstall || installation.DeletionTimestamp != nil {
 			uninstall = true
 		}
-		productStatus.Phase, err = reconciler.Reconcile(context.TODO(), installation, &productStatus, serverClient, quotaconfig.GetProduct(productName), uninstall)
+		previousPhase := productStatus.Phase
+		productStart := time.Now()
+		productStatus.Phase, err = reconciler.Reconcile(productCtx, installation, &productStatus, serverClient, quotaconfig.GetProduct(productName), uninstall)
+		tracing.End(productSpan, productStatus.Phase, err)
+		// the transition time is kept in the status, so that the time in the
+		// phase isn't reset when the operator restarts
+		if productStatus.PhaseTransitionTime == nil || productStatus.Phase != previousPhase {
+			now := metav1.Now()
+			productStatus.PhaseTransitionTime = &now
+		}
+		metrics.ObserveProductReconcile(stage.Name, productStatus.Name, previousPhase, productStatus.Phase, productStatus.PhaseTransitionTime.Time, time.Since(productStart), err)
 
 		if err != nil {
 			if mErr == nil {
@@ -1251,13 +1598,43 @@ func (r *RHMIReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	// Instead of calling .Complete(r), we call .Build(r), which
 	// does the same but returns the controller instance, to be
 	// stored in the reconciler
-	controller, err := ctrl.NewControllerManagedBy(mgr).
+	controllerBuilder := ctrl.NewControllerManagedBy(mgr).
 		For(&rhmiv1alpha1.RHMI{}).
 		Watches(&source.Kind{Type: &usersv1.User{}}, enqueueAllInstallations).
 		Watches(&source.Kind{Type: &corev1.Secret{}}, enqueueAllInstallations).
 		Watches(&source.Kind{Type: &usersv1.Group{}}, enqueueAllInstallations).
-		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, enqueueAllInstallations, builder.WithPredicates(newObjectPredicate(isName(marin3rconfig.RateLimitConfigMapName)))).
-		Build(r)
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, enqueueAllInstallations, builder.WithPredicates(newObjectPredicate(isName(marin3rconfig.RateLimitConfigMapName))))
+
+	namespace, err := resources.GetWatchNamespace()
+	if err != nil {
+		return err
+	}
+	secretProvider, err := secretprovider.New(mgr.GetClient(), namespace)
+	if err != nil {
+		return err
+	}
+
+	// Secrets are already watched above, the other secret backends are
+	// polled to detect rotations
+	if secretprovider.Backend() != secretprovider.BackendKubernetes {
+		rotationWatcher := secretprovider.NewRotationWatcher(secretProvider, rotatedSecretNames(mgr.GetClient(), namespace), secretprovider.DefaultRotationInterval)
+		if err := mgr.Add(rotationWatcher); err != nil {
+			return err
+		}
+		controllerBuilder = controllerBuilder.Watches(&source.Channel{Source: rotationWatcher.Events()}, enqueueAllInstallations)
+	}
+
+	r.smtpHealthMonitor = smtphealth.NewHealthMonitor(secretProvider, smtphealth.NewChecker(smtphealth.DefaultCheckTimeout), smtpSecretNames(mgr.GetClient(), namespace), smtphealth.DefaultCheckInterval)
+	if err := mgr.Add(r.smtpHealthMonitor); err != nil {
+		return err
+	}
+	controllerBuilder = controllerBuilder.Watches(&source.Channel{Source: r.smtpHealthMonitor.Events()}, enqueueAllInstallations)
+
+	if err := mgr.Add(r.inFlight); err != nil {
+		return err
+	}
+
+	controller, err := controllerBuilder.Build(r)
 
 	if err != nil {
 		return err
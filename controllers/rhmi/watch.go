@@ -4,6 +4,11 @@ import (
 	"context"
 
 	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/addon"
+	"github.com/integr8ly/integreatly-operator/pkg/config"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/rhmi"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/secretprovider"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/smtphealth"
 
 	"k8s.io/apimachinery/pkg/types"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -80,3 +85,33 @@ func isName(name string) func(handler.MapObject) bool {
 		return mo.Meta.GetName() == name
 	}
 }
+
+// rotatedSecretNames returns the names of the secrets the installation in
+// namespace depends on, for the rotation watcher of the secret provider
+func rotatedSecretNames(client k8sclient.Client, namespace string) secretprovider.SecretNamesFunc {
+	return func(ctx context.Context) ([]string, error) {
+		installation, err := rhmi.GetRhmiCr(client, ctx, namespace, log)
+		if err != nil || installation == nil {
+			return nil, err
+		}
+
+		return append(smtphealth.Secrets(installation),
+			installation.Spec.PagerDutySecret,
+			installation.Spec.DeadMansSnitchSecret,
+			config.GHOauthClientsSecretName,
+			addon.GetParametersSecretName(integreatlyv1alpha1.InstallationType(installation.Spec.Type)),
+		), nil
+	}
+}
+
+// smtpSecretNames returns the names of the SMTP secrets of the installation in
+// namespace, in failover order, for the SMTP health monitor
+func smtpSecretNames(client k8sclient.Client, namespace string) secretprovider.SecretNamesFunc {
+	return func(ctx context.Context) ([]string, error) {
+		installation, err := rhmi.GetRhmiCr(client, ctx, namespace, log)
+		if err != nil || installation == nil {
+			return nil, err
+		}
+		return smtphealth.Secrets(installation), nil
+	}
+}
@@ -0,0 +1,341 @@
+package helpers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/elasticache"
+	"github.com/aws/aws-sdk-go/service/rds"
+	croUtil "github.com/integr8ly/cloud-resource-operator/pkg/client"
+	croProviders "github.com/integr8ly/cloud-resource-operator/pkg/providers"
+	croAWS "github.com/integr8ly/cloud-resource-operator/pkg/providers/aws"
+	rhmiconfigv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/resources"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/capacity"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// ReconcileCloudResourceOverrides writes the cloud resource overrides of the
+// RHMIConfig to the AWS strategies ConfigMap. The overrides of each resource
+// are written to a tier of its own, on top of the production strategy of its
+// type. The CIDR block and the Postgres storage sizes are checked against the
+// strategies, as they can't be changed once the resources are provisioned
+func ReconcileCloudResourceOverrides(ctx context.Context, client k8sclient.Client, cloudResources *rhmiconfigv1alpha1.CloudResources, namespace string) error {
+	if cloudResources == nil {
+		return nil
+	}
+
+	cfgMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      croAWS.DefaultConfigMapName,
+			Namespace: namespace,
+		},
+	}
+	if _, err := controllerutil.CreateOrUpdate(ctx, client, cfgMap, func() error {
+		if cfgMap.Data == nil {
+			cfgMap.Data = map[string]string{}
+		}
+
+		if err := overrideResourceStrategies(cfgMap, croProviders.PostgresResourceType, resourceNames(cloudResources.Postgres), func() interface{} {
+			return &rds.CreateDBInstanceInput{}
+		}, func(name string, createStrategy, current interface{}) error {
+			strategy := cloudResources.Postgres[name]
+			rdsCreateStrategy := createStrategy.(*rds.CreateDBInstanceInput)
+			overridePostgresStrategy(rdsCreateStrategy, strategy)
+
+			// the storage of an instance can't be decreased, the size of
+			// its tier is kept when the production one is lower
+			currentStorage := current.(*rds.CreateDBInstanceInput).AllocatedStorage
+			if currentStorage == nil || (rdsCreateStrategy.AllocatedStorage != nil && *rdsCreateStrategy.AllocatedStorage >= *currentStorage) {
+				return nil
+			}
+			if strategy.StorageSize != nil {
+				return fmt.Errorf("storage size of postgres %s can't be decreased from %d GiB", name, *currentStorage)
+			}
+			rdsCreateStrategy.AllocatedStorage = aws.Int64(*currentStorage)
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		if err := overrideResourceStrategies(cfgMap, croProviders.RedisResourceType, resourceNames(cloudResources.Redis), func() interface{} {
+			return &elasticache.CreateReplicationGroupInput{}
+		}, func(name string, createStrategy, current interface{}) error {
+			overrideRedisStrategy(createStrategy.(*elasticache.CreateReplicationGroupInput), cloudResources.Redis[name])
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		if cloudResources.CIDR != "" {
+			if err := overrideStrategy(cfgMap, croProviders.NetworkResourceType, &ec2.CreateVpcInput{}, func(createStrategy interface{}) error {
+				vpcCreateStrategy := createStrategy.(*ec2.CreateVpcInput)
+				if cidr := aws.StringValue(vpcCreateStrategy.CidrBlock); cidr != "" && cidr != cloudResources.CIDR {
+					return fmt.Errorf("cidr can't be changed once set to %s", cidr)
+				}
+				vpcCreateStrategy.CidrBlock = aws.String(cloudResources.CIDR)
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to update aws strategy config map with cloud resource overrides : %v", err)
+	}
+
+	return nil
+}
+
+func resourceNames(strategies map[string]rhmiconfigv1alpha1.CloudResourceStrategy) []string {
+	names := []string{}
+	for name := range strategies {
+		names = append(names, name)
+	}
+	return names
+}
+
+// overrideStrategy unmarshals the production create strategy of the resource
+// type into createStrategy, applies override to it and marshals it back
+func overrideStrategy(cfgMap *corev1.ConfigMap, resourceType croProviders.ResourceType, createStrategy interface{}, override func(interface{}) error) error {
+	rawStrategy, err := readStrategy(cfgMap, resourceType)
+	if err != nil {
+		return err
+	}
+
+	tierStrategy := rawStrategy[croUtil.TierProduction]
+	if err := unmarshalCreateStrategy(tierStrategy, resourceType, createStrategy); err != nil {
+		return err
+	}
+	if err := override(createStrategy); err != nil {
+		return err
+	}
+	if err := marshalCreateStrategy(tierStrategy, resourceType, createStrategy); err != nil {
+		return err
+	}
+
+	return writeStrategy(cfgMap, resourceType, rawStrategy)
+}
+
+// overrideResourceStrategies writes the tier of each of the named resources
+// of the resource type. The tier is a copy of the production tier, with the
+// create strategy unmarshalled from newCreateStrategy and overridden by
+// override. current is the create strategy of the tier before it's written.
+// The tiers of resources whose overrides were removed are written again, so
+// that they follow the production tier
+func overrideResourceStrategies(cfgMap *corev1.ConfigMap, resourceType croProviders.ResourceType, names []string, newCreateStrategy func() interface{}, override func(name string, createStrategy, current interface{}) error) error {
+	rawStrategy, err := readStrategy(cfgMap, resourceType)
+	if err != nil {
+		return err
+	}
+
+	tierPrefix := resources.StrategyTier("")
+	for tier := range rawStrategy {
+		if strings.HasPrefix(tier, tierPrefix) && !containsString(names, strings.TrimPrefix(tier, tierPrefix)) {
+			names = append(names, strings.TrimPrefix(tier, tierPrefix))
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	sort.Strings(names)
+
+	production := rawStrategy[croUtil.TierProduction]
+	for _, name := range names {
+		tier := resources.StrategyTier(name)
+		current := newCreateStrategy()
+		if tierStrategy, ok := rawStrategy[tier]; ok && tierStrategy != nil {
+			if err := unmarshalCreateStrategy(tierStrategy, resourceType, current); err != nil {
+				return err
+			}
+		}
+
+		tierStrategy := *production
+		createStrategy := newCreateStrategy()
+		if err := unmarshalCreateStrategy(&tierStrategy, resourceType, createStrategy); err != nil {
+			return err
+		}
+		if err := override(name, createStrategy, current); err != nil {
+			return err
+		}
+		if err := marshalCreateStrategy(&tierStrategy, resourceType, createStrategy); err != nil {
+			return err
+		}
+		rawStrategy[tier] = &tierStrategy
+	}
+
+	return writeStrategy(cfgMap, resourceType, rawStrategy)
+}
+
+// readStrategy unmarshals the strategy mapping of the resource type, adding
+// an empty production tier if it's missing
+func readStrategy(cfgMap *corev1.ConfigMap, resourceType croProviders.ResourceType) (map[string]*croAWS.StrategyConfig, error) {
+	rawStrategy := map[string]*croAWS.StrategyConfig{}
+	if data, ok := cfgMap.Data[string(resourceType)]; ok {
+		if err := json.Unmarshal([]byte(data), &rawStrategy); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal strategy mapping for resource type %s : %v", resourceType, err)
+		}
+	}
+
+	if tierStrategy, ok := rawStrategy[croUtil.TierProduction]; !ok || tierStrategy == nil {
+		rawStrategy[croUtil.TierProduction] = &croAWS.StrategyConfig{}
+	}
+	return rawStrategy, nil
+}
+
+func writeStrategy(cfgMap *corev1.ConfigMap, resourceType croProviders.ResourceType, rawStrategy map[string]*croAWS.StrategyConfig) error {
+	strategyJSON, err := json.Marshal(rawStrategy)
+	if err != nil {
+		return fmt.Errorf("failed to marshal strategy mapping for resource type %s : %v", resourceType, err)
+	}
+	cfgMap.Data[string(resourceType)] = string(strategyJSON)
+	return nil
+}
+
+func unmarshalCreateStrategy(tierStrategy *croAWS.StrategyConfig, resourceType croProviders.ResourceType, createStrategy interface{}) error {
+	if len(tierStrategy.CreateStrategy) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(tierStrategy.CreateStrategy, createStrategy); err != nil {
+		return fmt.Errorf("failed to unmarshal create strategy for resource type %s : %v", resourceType, err)
+	}
+	return nil
+}
+
+func marshalCreateStrategy(tierStrategy *croAWS.StrategyConfig, resourceType croProviders.ResourceType, createStrategy interface{}) error {
+	createStrategyJSON, err := json.Marshal(createStrategy)
+	if err != nil {
+		return fmt.Errorf("failed to marshal create strategy for resource type %s : %v", resourceType, err)
+	}
+	tierStrategy.CreateStrategy = createStrategyJSON
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func overridePostgresStrategy(createStrategy *rds.CreateDBInstanceInput, strategy rhmiconfigv1alpha1.CloudResourceStrategy) {
+	if strategy.InstanceClass != "" {
+		createStrategy.DBInstanceClass = aws.String(strategy.InstanceClass)
+	}
+	if strategy.StorageSize != nil {
+		createStrategy.AllocatedStorage = aws.Int64(*strategy.StorageSize)
+	}
+	if strategy.MultiAZ != nil {
+		createStrategy.MultiAZ = aws.Bool(*strategy.MultiAZ)
+	}
+	if strategy.EngineVersion != "" {
+		createStrategy.EngineVersion = aws.String(strategy.EngineVersion)
+	}
+	if strategy.SnapshotRetention != nil {
+		createStrategy.BackupRetentionPeriod = aws.Int64(*strategy.SnapshotRetention)
+	}
+}
+
+func overrideRedisStrategy(createStrategy *elasticache.CreateReplicationGroupInput, strategy rhmiconfigv1alpha1.CloudResourceStrategy) {
+	if strategy.InstanceClass != "" {
+		createStrategy.CacheNodeType = aws.String(strategy.InstanceClass)
+	}
+	// multi-AZ replication groups require automatic failover
+	if strategy.MultiAZ != nil {
+		createStrategy.MultiAZEnabled = aws.Bool(*strategy.MultiAZ)
+		if *strategy.MultiAZ {
+			createStrategy.AutomaticFailoverEnabled = aws.Bool(true)
+		}
+	}
+	if strategy.EngineVersion != "" {
+		createStrategy.EngineVersion = aws.String(strategy.EngineVersion)
+	}
+	if strategy.SnapshotRetention != nil {
+		createStrategy.SnapshotRetentionLimit = aws.Int64(*strategy.SnapshotRetention)
+	}
+}
+
+const (
+	// maximum storage the RDS instances scale up to when it's not set in
+	// the strategy, as defaulted by the cloud resource operator
+	defaultMaxAllocatedStorage = 100
+	// largest storage of an RDS instance in GiB
+	maxAllocatedStorageLimit = 65536
+)
+
+// ReconcileStorageAutoResize raises the maximum storage of the Postgres
+// instances in the production tier of the AWS strategies ConfigMap when one of
+// them is forecast to run out of storage within the threshold. The cloud
+// resource operator doesn't resize existing instances, it updates the maximum
+// storage their storage autoscaling can reach. The maximum is raised above
+// the allocated storage of the instance by the increase percent, and is never
+// lowered. The tiers of the instances with overrides are copied from the
+// production tier when the overrides are reconciled. Returns the new maximum
+// storage, or 0 if it wasn't raised
+func ReconcileStorageAutoResize(ctx context.Context, client k8sclient.Client, autoResize *rhmiconfigv1alpha1.StorageAutoResize, forecasts []rhmiconfigv1alpha1.CapacityForecast, namespace string) (int64, error) {
+	if autoResize == nil {
+		return 0, nil
+	}
+
+	increasePercent := int64(autoResize.IncreasePercent)
+	if increasePercent == 0 {
+		increasePercent = rhmiconfigv1alpha1.DefaultAutoResizeIncreasePercent
+	}
+	requiredStorage := int64(0)
+	for _, forecast := range forecasts {
+		if forecast.Type != capacity.PostgresType || forecast.DaysUntilFull > autoResize.ThresholdDays || forecast.AllocatedStorage == 0 {
+			continue
+		}
+		// rounded up to the next GiB
+		storage := (forecast.AllocatedStorage*(100+increasePercent) + 99) / 100
+		if storage > requiredStorage {
+			requiredStorage = storage
+		}
+	}
+	if requiredStorage == 0 {
+		return 0, nil
+	}
+	if requiredStorage > maxAllocatedStorageLimit {
+		requiredStorage = maxAllocatedStorageLimit
+	}
+
+	raisedStorage := int64(0)
+	cfgMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      croAWS.DefaultConfigMapName,
+			Namespace: namespace,
+		},
+	}
+	if _, err := controllerutil.CreateOrUpdate(ctx, client, cfgMap, func() error {
+		if cfgMap.Data == nil {
+			cfgMap.Data = map[string]string{}
+		}
+		return overrideStrategy(cfgMap, croProviders.PostgresResourceType, &rds.CreateDBInstanceInput{}, func(createStrategy interface{}) error {
+			rdsCreateStrategy := createStrategy.(*rds.CreateDBInstanceInput)
+			maxAllocatedStorage := int64(defaultMaxAllocatedStorage)
+			if rdsCreateStrategy.MaxAllocatedStorage != nil {
+				maxAllocatedStorage = *rdsCreateStrategy.MaxAllocatedStorage
+			}
+			if requiredStorage > maxAllocatedStorage {
+				rdsCreateStrategy.MaxAllocatedStorage = aws.Int64(requiredStorage)
+				raisedStorage = requiredStorage
+			}
+			return nil
+		})
+	}); err != nil {
+		return 0, fmt.Errorf("failed to update aws strategy config map with storage auto resize : %v", err)
+	}
+
+	return raisedStorage, nil
+}
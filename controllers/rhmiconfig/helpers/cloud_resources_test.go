@@ -0,0 +1,274 @@
+package helpers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/elasticache"
+	"github.com/aws/aws-sdk-go/service/rds"
+	croAWS "github.com/integr8ly/cloud-resource-operator/pkg/providers/aws"
+	rhmiconfigv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func tierCreateStrategy(t *testing.T, cfgMap *corev1.ConfigMap, key, tier string, createStrategy interface{}) {
+	rawStrategy := map[string]*croAWS.StrategyConfig{}
+	if err := json.Unmarshal([]byte(cfgMap.Data[key]), &rawStrategy); err != nil {
+		t.Fatalf("failed to unmarshal %s strategy: %v", key, err)
+	}
+	if rawStrategy[tier] == nil {
+		t.Fatalf("expected %s strategy to have tier %s, got %s", key, tier, cfgMap.Data[key])
+	}
+	if err := json.Unmarshal(rawStrategy[tier].CreateStrategy, createStrategy); err != nil {
+		t.Fatalf("failed to unmarshal %s create strategy: %v", key, err)
+	}
+}
+
+func int64Ptr(value int64) *int64 {
+	return &value
+}
+
+func TestReconcileCloudResourceOverrides(t *testing.T) {
+	namespace := "testing-namespaces-operator"
+	storageSize := int64(100)
+	snapshotRetention := int64(30)
+	postgresTier := "production-threescale-postgres-rhmi"
+	redisTier := "production-threescale-backend-redis-rhmi"
+
+	strategies := func(postgresTiers, network string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      croAWS.DefaultConfigMapName,
+				Namespace: namespace,
+			},
+			Data: map[string]string{
+				"postgres": `{"development": {"region": "", "createStrategy": {}, "deleteStrategy": {}}, "production": {"region": "", "createStrategy": {"PreferredBackupWindow": "03:01-04:01"}, "deleteStrategy": {}}` + postgresTiers + `}`,
+				"redis":    `{"development": {"region": "", "createStrategy": {}, "deleteStrategy": {}}, "production": {"region": "", "createStrategy": {"SnapshotWindow": "03:01-04:01"}, "deleteStrategy": {}}}`,
+				"_network": `{"production": {"region": "", "createStrategy": {` + network + `}, "deleteStrategy": {}}}`,
+			},
+		}
+	}
+
+	scenarios := []struct {
+		Name           string
+		Strategies     *corev1.ConfigMap
+		CloudResources *rhmiconfigv1alpha1.CloudResources
+		ExpectErr      bool
+		Validate       func(*testing.T, *corev1.ConfigMap)
+	}{
+		{
+			Name:       "test no overrides leaves the strategies unchanged",
+			Strategies: strategies("", ""),
+			Validate: func(t *testing.T, cfgMap *corev1.ConfigMap) {
+				if cfgMap.Data["postgres"] != strategies("", "").Data["postgres"] {
+					t.Errorf("expected postgres strategy to be unchanged, got %s", cfgMap.Data["postgres"])
+				}
+			},
+		},
+		{
+			Name:       "test postgres and redis overrides are written to the tiers of the resources",
+			Strategies: strategies("", ""),
+			CloudResources: &rhmiconfigv1alpha1.CloudResources{
+				Postgres: map[string]rhmiconfigv1alpha1.CloudResourceStrategy{
+					"threescale-postgres-rhmi": {
+						InstanceClass:     "db.m5.xlarge",
+						StorageSize:       &storageSize,
+						MultiAZ:           boolPtr(true),
+						EngineVersion:     "10.15",
+						SnapshotRetention: &snapshotRetention,
+					},
+				},
+				Redis: map[string]rhmiconfigv1alpha1.CloudResourceStrategy{
+					"threescale-backend-redis-rhmi": {
+						InstanceClass: "cache.m5.large",
+						MultiAZ:       boolPtr(true),
+					},
+				},
+				CIDR: "10.1.0.0/26",
+			},
+			Validate: func(t *testing.T, cfgMap *corev1.ConfigMap) {
+				postgres := &rds.CreateDBInstanceInput{}
+				tierCreateStrategy(t, cfgMap, "postgres", postgresTier, postgres)
+				if *postgres.DBInstanceClass != "db.m5.xlarge" || *postgres.AllocatedStorage != 100 || !*postgres.MultiAZ || *postgres.EngineVersion != "10.15" || *postgres.BackupRetentionPeriod != 30 {
+					t.Errorf("unexpected postgres create strategy %s", postgres.String())
+				}
+				if *postgres.PreferredBackupWindow != "03:01-04:01" {
+					t.Errorf("expected backup window to be copied from the production tier, got %v", postgres.PreferredBackupWindow)
+				}
+				production := &rds.CreateDBInstanceInput{}
+				tierCreateStrategy(t, cfgMap, "postgres", "production", production)
+				if production.DBInstanceClass != nil {
+					t.Errorf("expected production tier to be unchanged, got %s", production.String())
+				}
+
+				redis := &elasticache.CreateReplicationGroupInput{}
+				tierCreateStrategy(t, cfgMap, "redis", redisTier, redis)
+				if *redis.CacheNodeType != "cache.m5.large" || !*redis.MultiAZEnabled || !*redis.AutomaticFailoverEnabled {
+					t.Errorf("unexpected redis create strategy %s", redis.String())
+				}
+				if redis.EngineVersion != nil || redis.SnapshotRetentionLimit != nil {
+					t.Errorf("expected unset redis overrides not to be written, got %s", redis.String())
+				}
+
+				network := &ec2.CreateVpcInput{}
+				tierCreateStrategy(t, cfgMap, "_network", "production", network)
+				if *network.CidrBlock != "10.1.0.0/26" {
+					t.Errorf("expected cidr 10.1.0.0/26, got %v", network.CidrBlock)
+				}
+			},
+		},
+		{
+			Name:           "test removed overrides revert to the production tier and keep the storage size",
+			Strategies:     strategies(`, "`+postgresTier+`": {"region": "", "createStrategy": {"DBInstanceClass": "db.m5.xlarge", "AllocatedStorage": 200}, "deleteStrategy": {}}`, ""),
+			CloudResources: &rhmiconfigv1alpha1.CloudResources{},
+			Validate: func(t *testing.T, cfgMap *corev1.ConfigMap) {
+				postgres := &rds.CreateDBInstanceInput{}
+				tierCreateStrategy(t, cfgMap, "postgres", postgresTier, postgres)
+				if postgres.DBInstanceClass != nil || *postgres.AllocatedStorage != 200 || *postgres.PreferredBackupWindow != "03:01-04:01" {
+					t.Errorf("unexpected postgres create strategy %s", postgres.String())
+				}
+			},
+		},
+		{
+			Name:       "test decreasing the storage size of the tier of the resource fails",
+			Strategies: strategies(`, "`+postgresTier+`": {"region": "", "createStrategy": {"AllocatedStorage": 200}, "deleteStrategy": {}}`, ""),
+			CloudResources: &rhmiconfigv1alpha1.CloudResources{
+				Postgres: map[string]rhmiconfigv1alpha1.CloudResourceStrategy{
+					"threescale-postgres-rhmi": {StorageSize: &storageSize},
+				},
+			},
+			ExpectErr: true,
+		},
+		{
+			Name:           "test changing the cidr set in the network strategy fails",
+			Strategies:     strategies("", `"CidrBlock": "10.2.0.0/26"`),
+			CloudResources: &rhmiconfigv1alpha1.CloudResources{CIDR: "10.1.0.0/26"},
+			ExpectErr:      true,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.Name, func(t *testing.T) {
+			scheme := buildScheme()
+			if err := corev1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to build scheme: %v", err)
+			}
+			client := fake.NewFakeClientWithScheme(scheme, scenario.Strategies)
+
+			err := ReconcileCloudResourceOverrides(context.TODO(), client, scenario.CloudResources, namespace)
+			if scenario.ExpectErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			cfgMap := &corev1.ConfigMap{}
+			if err := client.Get(context.TODO(), k8sclient.ObjectKey{Name: croAWS.DefaultConfigMapName, Namespace: namespace}, cfgMap); err != nil {
+				t.Fatalf("failed to get strategies config map: %v", err)
+			}
+			scenario.Validate(t, cfgMap)
+		})
+	}
+}
+
+func TestReconcileStorageAutoResize(t *testing.T) {
+	namespace := "testing-namespaces-operator"
+
+	strategies := func(maxAllocatedStorage string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      croAWS.DefaultConfigMapName,
+				Namespace: namespace,
+			},
+			Data: map[string]string{
+				"postgres": `{"production": {"region": "", "createStrategy": {` + maxAllocatedStorage + `}, "deleteStrategy": {}}}`,
+			},
+		}
+	}
+	forecast := func(resourceType string, daysUntilFull int32, allocatedStorage int64) rhmiconfigv1alpha1.CapacityForecast {
+		return rhmiconfigv1alpha1.CapacityForecast{
+			Type:             resourceType,
+			Name:             "threescale-postgres-rhmi",
+			Namespace:        namespace,
+			DaysUntilFull:    daysUntilFull,
+			AllocatedStorage: allocatedStorage,
+		}
+	}
+
+	scenarios := []struct {
+		Name                        string
+		AutoResize                  *rhmiconfigv1alpha1.StorageAutoResize
+		Forecasts                   []rhmiconfigv1alpha1.CapacityForecast
+		MaxAllocatedStorage         string
+		ExpectedRaisedStorage       int64
+		ExpectedMaxAllocatedStorage *int64
+	}{
+		{
+			Name:       "test no auto resize leaves the strategy unchanged",
+			AutoResize: nil,
+			Forecasts:  []rhmiconfigv1alpha1.CapacityForecast{forecast("postgres", 1, 100)},
+		},
+		{
+			Name:       "test forecast over the threshold leaves the strategy unchanged",
+			AutoResize: &rhmiconfigv1alpha1.StorageAutoResize{ThresholdDays: 7},
+			Forecasts:  []rhmiconfigv1alpha1.CapacityForecast{forecast("postgres", 10, 100), forecast("redis", 1, 0)},
+		},
+		{
+			Name:                        "test forecast within the threshold raises the default maximum storage",
+			AutoResize:                  &rhmiconfigv1alpha1.StorageAutoResize{ThresholdDays: 7},
+			Forecasts:                   []rhmiconfigv1alpha1.CapacityForecast{forecast("postgres", 3, 90)},
+			ExpectedRaisedStorage:       113,
+			ExpectedMaxAllocatedStorage: int64Ptr(113),
+		},
+		{
+			Name:                        "test maximum storage over the required storage is not lowered",
+			AutoResize:                  &rhmiconfigv1alpha1.StorageAutoResize{ThresholdDays: 7, IncreasePercent: 50},
+			Forecasts:                   []rhmiconfigv1alpha1.CapacityForecast{forecast("postgres", 3, 100)},
+			MaxAllocatedStorage:         `"MaxAllocatedStorage": 500`,
+			ExpectedMaxAllocatedStorage: int64Ptr(500),
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.Name, func(t *testing.T) {
+			scheme := buildScheme()
+			if err := corev1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to build scheme: %v", err)
+			}
+			client := fake.NewFakeClientWithScheme(scheme, strategies(scenario.MaxAllocatedStorage))
+
+			raisedStorage, err := ReconcileStorageAutoResize(context.TODO(), client, scenario.AutoResize, scenario.Forecasts, namespace)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if raisedStorage != scenario.ExpectedRaisedStorage {
+				t.Errorf("expected raised storage %d, got %d", scenario.ExpectedRaisedStorage, raisedStorage)
+			}
+
+			cfgMap := &corev1.ConfigMap{}
+			if err := client.Get(context.TODO(), k8sclient.ObjectKey{Name: croAWS.DefaultConfigMapName, Namespace: namespace}, cfgMap); err != nil {
+				t.Fatalf("failed to get strategies config map: %v", err)
+			}
+			postgres := &rds.CreateDBInstanceInput{}
+			tierCreateStrategy(t, cfgMap, "postgres", "production", postgres)
+			if scenario.ExpectedMaxAllocatedStorage == nil {
+				if postgres.MaxAllocatedStorage != nil {
+					t.Errorf("expected maximum storage not to be set, got %d", *postgres.MaxAllocatedStorage)
+				}
+				return
+			}
+			if postgres.MaxAllocatedStorage == nil || *postgres.MaxAllocatedStorage != *scenario.ExpectedMaxAllocatedStorage {
+				t.Errorf("expected maximum storage %d, got %v", *scenario.ExpectedMaxAllocatedStorage, postgres.MaxAllocatedStorage)
+			}
+		})
+	}
+}
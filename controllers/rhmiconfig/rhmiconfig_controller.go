@@ -25,6 +25,7 @@ import (
 
 	croUtil "github.com/integr8ly/cloud-resource-operator/pkg/client"
 	"github.com/integr8ly/integreatly-operator/controllers/rhmiconfig/helpers"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/rhmi"
 	k8sErr "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -46,12 +47,13 @@ type RHMIConfigReconciler struct {
 // +kubebuilder:rbac:groups=integreatly.org,resources=rhmiconfigs/status,verbs=get;update;patch
 
 func (r *RHMIConfigReconciler) Reconcile(request ctrl.Request) (ctrl.Result, error) {
-	_ = context.Background()
+	ctx := l.ContextWithFields(context.Background(), l.Fields{l.ReconcileIDLogContext: l.NewReconcileID()})
+	log := log.ForContext(ctx)
 
 	log.Info("reconciling RHMIConfig")
 	// Fetch the RHMIConfig instance
 	rhmiConfig := &rhmiconfigv1alpha1.RHMIConfig{}
-	err := r.Get(context.TODO(), request.NamespacedName, rhmiConfig)
+	err := r.Get(ctx, request.NamespacedName, rhmiConfig)
 	if err != nil {
 		if k8sErr.IsNotFound(err) {
 			// Request object not found, could have been deleted after reconcile request.
@@ -65,18 +67,18 @@ func (r *RHMIConfigReconciler) Reconcile(request ctrl.Request) (ctrl.Result, err
 
 	//	Checks if there is an upgrade available, if there is upgrade available upgrades the status of RHMIConfig
 	if rhmiConfig.Status.UpgradeAvailable != nil {
-		err = helpers.UpdateStatus(context.TODO(), r.Client, rhmiConfig)
+		err = helpers.UpdateStatus(ctx, r.Client, rhmiConfig)
 		if err != nil {
 			return ctrl.Result{}, err
 		}
 
-		err = r.Update(context.TODO(), rhmiConfig)
+		err = r.Update(ctx, rhmiConfig)
 		if err != nil {
 			return ctrl.Result{}, err
 		}
 	} else {
 		rhmiConfig.Status.Upgrade = rhmiconfigv1alpha1.RHMIConfigStatusUpgrade{}
-		err = r.Status().Update(context.TODO(), rhmiConfig)
+		err = r.Status().Update(ctx, rhmiConfig)
 		if err != nil {
 			return ctrl.Result{}, err
 		}
@@ -104,6 +106,7 @@ func (r *RHMIConfigReconciler) Reconcile(request ctrl.Request) (ctrl.Result, err
 }
 
 // reconciles cloud resource strategies, setting backup and maintenance values for postgres and redis instances
+// and the cloud resource overrides
 func (r *RHMIConfigReconciler) ReconcileCloudResourceStrategies(config *rhmiconfigv1alpha1.RHMIConfig) error {
 	log.Info("reconciling cloud resource maintenance strategies")
 
@@ -129,6 +132,33 @@ func (r *RHMIConfigReconciler) ReconcileCloudResourceStrategies(config *rhmiconf
 		return fmt.Errorf("failure to reconcile aws strategy map : %v", err)
 	}
 
+	// the overrides are validated by the web-hook, as for the backup and maintenance values we validate them
+	// again before they make it to CRO
+	if err := rhmiconfigv1alpha1.ValidateCloudResources(config.Spec.CloudResources, nil); err != nil {
+		return fmt.Errorf("failure validating cloud resource overrides : %v", err)
+	}
+	if err := helpers.ReconcileCloudResourceOverrides(context.TODO(), r.Client, config.Spec.CloudResources, config.Namespace); err != nil {
+		return fmt.Errorf("failure to reconcile cloud resource overrides : %v", err)
+	}
+
+	// raise the maximum postgres storage when an instance is forecast to run out of storage, the forecasts are
+	// published in the rhmi status by the installation controller
+	if config.Spec.CloudResources != nil && config.Spec.CloudResources.StorageAutoResize != nil {
+		installation, err := rhmi.GetRhmiCr(r.Client, context.TODO(), config.Namespace, log)
+		if err != nil {
+			return fmt.Errorf("failure getting rhmi cr for storage auto resize : %v", err)
+		}
+		if installation != nil {
+			raisedStorage, err := helpers.ReconcileStorageAutoResize(context.TODO(), r.Client, config.Spec.CloudResources.StorageAutoResize, installation.Status.CapacityForecasts, config.Namespace)
+			if err != nil {
+				return fmt.Errorf("failure to reconcile storage auto resize : %v", err)
+			}
+			if raisedStorage != 0 {
+				log.Infof("Raised postgres maximum storage", l.Fields{"maxAllocatedStorage": raisedStorage})
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -0,0 +1,631 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	marin3rv1alpha1 "github.com/3scale-ops/marin3r/apis/marin3r/v1alpha1"
+	marin3roperator "github.com/3scale-ops/marin3r/apis/operator.marin3r/v1alpha1"
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/config"
+	"github.com/integr8ly/integreatly-operator/pkg/products/observability"
+	"github.com/integr8ly/integreatly-operator/pkg/products/threescale"
+	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/ratelimit"
+	appsv1 "github.com/openshift/api/apps/v1"
+	routev1 "github.com/openshift/api/route/v1"
+	prometheus "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	k8sappsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	apicastContainerName   = "apicast"
+	apicastManagementPort  = 8090
+	apicastMetricsPort     = 9421
+	adminPortalURLKey      = "AdminPortalURL"
+	defaultDeploymentEnv   = "production"
+	defaultConfigLoadMode  = "boot"
+	configurationCacheBoot = "300"
+	customPolicyMountPath  = "/opt/app-root/src/policies"
+	gatewayPortName        = "gateway"
+	gatewayLabelKey        = "integreatly.org/selfmanaged-gateway"
+	discoveryServiceName   = "instance"
+)
+
+// gatewayReconciler holds the state needed to reconcile a single
+// SelfManagedGateway CR
+type gatewayReconciler struct {
+	client        k8sclient.Client
+	scheme        *runtime.Scheme
+	installation  *integreatlyv1alpha1.RHMI
+	configManager config.ConfigReadWriter
+	gateway       *integreatlyv1alpha1.SelfManagedGateway
+	log           l.Logger
+}
+
+func newGatewayReconciler(client k8sclient.Client, scheme *runtime.Scheme, installation *integreatlyv1alpha1.RHMI, configManager config.ConfigReadWriter, gateway *integreatlyv1alpha1.SelfManagedGateway, logger l.Logger) *gatewayReconciler {
+	return &gatewayReconciler{
+		client:        client,
+		scheme:        scheme,
+		installation:  installation,
+		configManager: configManager,
+		gateway:       gateway,
+		log:           logger,
+	}
+}
+
+func (r *gatewayReconciler) reconcile(ctx context.Context) (integreatlyv1alpha1.StatusPhase, error) {
+	threescaleConfig, err := r.configManager.ReadThreeScale()
+	if err != nil {
+		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("could not read 3scale config: %w", err)
+	}
+	// The admin portal host is only written once 3scale has been deployed
+	if threescaleConfig.GetHost() == "" {
+		r.log.Info("Waiting for 3scale to be installed")
+		return integreatlyv1alpha1.PhaseAwaitingComponents, nil
+	}
+
+	phase, err := r.reconcileAdminPortalSecret(ctx, threescaleConfig)
+	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+		return phase, err
+	}
+
+	image, err := r.getImage(ctx, threescaleConfig)
+	if err != nil {
+		return integreatlyv1alpha1.PhaseFailed, err
+	}
+	if image == "" {
+		r.log.Info("Waiting for the apicast-production image to be known")
+		return integreatlyv1alpha1.PhaseAwaitingComponents, nil
+	}
+	r.gateway.Status.Image = image
+
+	phase, err = r.reconcileCustomPolicies(ctx)
+	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+		return phase, err
+	}
+
+	if r.gateway.Spec.RateLimiting {
+		phase, err = r.reconcileRateLimiting(ctx)
+		if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+			return phase, err
+		}
+	} else if err := r.removeRateLimiting(ctx); err != nil {
+		return integreatlyv1alpha1.PhaseFailed, err
+	}
+
+	deployment, err := r.reconcileDeployment(ctx, image)
+	if err != nil {
+		return integreatlyv1alpha1.PhaseFailed, err
+	}
+
+	if err := r.reconcileService(ctx); err != nil {
+		return integreatlyv1alpha1.PhaseFailed, err
+	}
+
+	if err := r.reconcileRoute(ctx); err != nil {
+		return integreatlyv1alpha1.PhaseFailed, err
+	}
+
+	phase, err = r.reconcileMonitoring(ctx)
+	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+		return phase, err
+	}
+
+	if deployment.Status.AvailableReplicas < 1 {
+		r.log.Info("Waiting for the self-managed gateway deployment to be available")
+		return integreatlyv1alpha1.PhaseInProgress, nil
+	}
+
+	return integreatlyv1alpha1.PhaseCompleted, nil
+}
+
+// reconcileAdminPortalSecret creates the secret holding the admin portal
+// endpoint, including the access token of the 3scale tenant, that APIcast
+// uses to load its configuration
+func (r *gatewayReconciler) reconcileAdminPortalSecret(ctx context.Context, threescaleConfig *config.ThreeScale) (integreatlyv1alpha1.StatusPhase, error) {
+	accessToken, err := threescale.GetAdminAccessToken(ctx, r.client, threescaleConfig.GetNamespace())
+	if err != nil {
+		if k8serr.IsNotFound(err) {
+			return integreatlyv1alpha1.PhaseAwaitingComponents, nil
+		}
+		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to get 3scale admin access token: %w", err)
+	}
+
+	adminPortalHost := strings.TrimPrefix(threescaleConfig.GetHost(), "https://")
+	r.gateway.Status.AdminPortalEndpoint = threescaleConfig.GetHost()
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      adminPortalSecretName(r.gateway),
+			Namespace: r.gateway.Namespace,
+		},
+	}
+	_, err = controllerutil.CreateOrUpdate(ctx, r.client, secret, func() error {
+		secret.Labels = gatewayLabels(r.gateway)
+		secret.Data = map[string][]byte{
+			adminPortalURLKey: []byte(fmt.Sprintf("https://%s@%s", *accessToken, adminPortalHost)),
+		}
+		return controllerutil.SetControllerReference(r.gateway, secret, r.scheme)
+	})
+	if err != nil {
+		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to reconcile admin portal secret: %w", err)
+	}
+
+	return integreatlyv1alpha1.PhaseCompleted, nil
+}
+
+// getImage returns the image set in the CR, defaulting to the image used by
+// the managed apicast-production gateway
+func (r *gatewayReconciler) getImage(ctx context.Context, threescaleConfig *config.ThreeScale) (string, error) {
+	if r.gateway.Spec.Image != "" {
+		return r.gateway.Spec.Image, nil
+	}
+
+	dc := &appsv1.DeploymentConfig{}
+	if err := r.client.Get(ctx, k8sclient.ObjectKey{Name: threescale.ApicastProductionDCName, Namespace: threescaleConfig.GetNamespace()}, dc); err != nil {
+		if k8serr.IsNotFound(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get %s deploymentconfig: %w", threescale.ApicastProductionDCName, err)
+	}
+
+	for _, container := range dc.Spec.Template.Spec.Containers {
+		if container.Name == threescale.ApicastProductionDCName {
+			return container.Image, nil
+		}
+	}
+	return "", nil
+}
+
+// reconcileCustomPolicies verifies that the ConfigMaps referenced by the
+// custom policies exist before they are mounted into the deployment
+func (r *gatewayReconciler) reconcileCustomPolicies(ctx context.Context) (integreatlyv1alpha1.StatusPhase, error) {
+	for _, policy := range r.gateway.Spec.CustomPolicies {
+		configMap := &corev1.ConfigMap{}
+		if err := r.client.Get(ctx, k8sclient.ObjectKey{Name: policy.ConfigMapRef.Name, Namespace: r.gateway.Namespace}, configMap); err != nil {
+			if k8serr.IsNotFound(err) {
+				return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("configmap %s for custom policy %s not found", policy.ConfigMapRef.Name, policy.Name)
+			}
+			return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to get configmap %s for custom policy %s: %w", policy.ConfigMapRef.Name, policy.Name, err)
+		}
+	}
+
+	return integreatlyv1alpha1.PhaseCompleted, nil
+}
+
+func (r *gatewayReconciler) reconcileDeployment(ctx context.Context, image string) (*k8sappsv1.Deployment, error) {
+	deployment := &k8sappsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      gatewayName(r.gateway),
+			Namespace: r.gateway.Namespace,
+		},
+	}
+
+	_, err := controllerutil.CreateOrUpdate(ctx, r.client, deployment, func() error {
+		labels := gatewayLabels(r.gateway)
+		deployment.Labels = labels
+		deployment.Spec.Replicas = r.gateway.Spec.Replicas
+		if deployment.Spec.Replicas == nil {
+			replicas := int32(1)
+			deployment.Spec.Replicas = &replicas
+		}
+		deployment.Spec.Selector = &metav1.LabelSelector{MatchLabels: labels}
+		deployment.Spec.Template.Labels = labels
+		deployment.Spec.Template.Annotations = map[string]string{}
+		if r.gateway.Spec.RateLimiting {
+			addEnvoySidecarMetadata(&deployment.Spec.Template, envoyNodeID(r.gateway))
+		}
+		deployment.Spec.Template.Spec.Volumes = getCustomPolicyVolumes(r.gateway)
+		deployment.Spec.Template.Spec.Containers = []corev1.Container{getApicastContainer(r.gateway, image)}
+
+		return controllerutil.SetControllerReference(r.gateway, deployment, r.scheme)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to reconcile self-managed gateway deployment: %w", err)
+	}
+
+	return deployment, nil
+}
+
+func (r *gatewayReconciler) reconcileService(ctx context.Context) error {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      gatewayName(r.gateway),
+			Namespace: r.gateway.Namespace,
+		},
+	}
+
+	// When rate limiting is enabled requests are forwarded to the envoy
+	// sidecar, which forwards them to APIcast once the limit is checked
+	targetPort := threescale.ApicastContainerPort
+	if r.gateway.Spec.RateLimiting {
+		targetPort = threescale.ApicastEnvoyProxyPort
+	}
+
+	_, err := controllerutil.CreateOrUpdate(ctx, r.client, service, func() error {
+		service.Labels = gatewayLabels(r.gateway)
+		service.Spec.Selector = gatewayLabels(r.gateway)
+		service.Spec.Ports = []corev1.ServicePort{
+			{
+				Name:       gatewayPortName,
+				Protocol:   corev1.ProtocolTCP,
+				Port:       threescale.ApicastContainerPort,
+				TargetPort: intstr.FromInt(targetPort),
+			},
+			{
+				Name:       "management",
+				Protocol:   corev1.ProtocolTCP,
+				Port:       apicastManagementPort,
+				TargetPort: intstr.FromInt(apicastManagementPort),
+			},
+			{
+				Name:       "metrics",
+				Protocol:   corev1.ProtocolTCP,
+				Port:       apicastMetricsPort,
+				TargetPort: intstr.FromInt(apicastMetricsPort),
+			},
+		}
+		return controllerutil.SetControllerReference(r.gateway, service, r.scheme)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reconcile self-managed gateway service: %w", err)
+	}
+
+	return nil
+}
+
+func (r *gatewayReconciler) reconcileRoute(ctx context.Context) error {
+	route := &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      gatewayName(r.gateway),
+			Namespace: r.gateway.Namespace,
+		},
+	}
+
+	if r.gateway.Spec.ExposedHost == "" {
+		r.gateway.Status.Host = ""
+		if err := r.client.Delete(ctx, route); err != nil && !k8serr.IsNotFound(err) {
+			return fmt.Errorf("failed to delete self-managed gateway route: %w", err)
+		}
+		return nil
+	}
+
+	_, err := controllerutil.CreateOrUpdate(ctx, r.client, route, func() error {
+		route.Labels = gatewayLabels(r.gateway)
+		route.Spec.Host = r.gateway.Spec.ExposedHost
+		route.Spec.To = routev1.RouteTargetReference{
+			Kind: "Service",
+			Name: gatewayName(r.gateway),
+		}
+		route.Spec.Port = &routev1.RoutePort{TargetPort: intstr.FromString(gatewayPortName)}
+		route.Spec.TLS = &routev1.TLSConfig{
+			Termination:                   routev1.TLSTerminationEdge,
+			InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
+		}
+		return controllerutil.SetControllerReference(r.gateway, route, r.scheme)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reconcile self-managed gateway route: %w", err)
+	}
+	r.gateway.Status.Host = r.gateway.Spec.ExposedHost
+
+	return nil
+}
+
+// reconcileRateLimiting wires the gateway into the marin3r rate limiting
+// service. The marin3r sidecar injection webhook only applies to pods in
+// namespaces with a DiscoveryService, so one is created alongside the
+// gateway, followed by the EnvoyConfig for its sidecar. The DiscoveryService
+// is shared by the gateways of the namespace, each of them is one of its
+// owners so that it's garbage collected with the last of them
+func (r *gatewayReconciler) reconcileRateLimiting(ctx context.Context) (integreatlyv1alpha1.StatusPhase, error) {
+	discoveryService := &marin3roperator.DiscoveryService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      discoveryServiceName,
+			Namespace: r.gateway.Namespace,
+		},
+	}
+	_, err := controllerutil.CreateOrUpdate(ctx, r.client, discoveryService, func() error {
+		// a DiscoveryService that wasn't created for a gateway is left
+		// to its owner
+		if !discoveryService.CreationTimestamp.IsZero() && !ownedByGateway(discoveryService.OwnerReferences) {
+			return nil
+		}
+		image := fmt.Sprintf("quay.io/3scale/marin3r:v%s", integreatlyv1alpha1.VersionMarin3r)
+		discoveryService.Spec.Image = &image
+		return controllerutil.SetOwnerReference(r.gateway, discoveryService, r.scheme)
+	})
+	if err != nil {
+		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to reconcile discovery service: %w", err)
+	}
+
+	marin3rConfig, err := r.configManager.ReadMarin3r()
+	if err != nil {
+		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("could not read marin3r config: %w", err)
+	}
+	ratelimitService := &corev1.Service{}
+	if err := r.client.Get(ctx, k8sclient.ObjectKey{Name: "ratelimit", Namespace: marin3rConfig.GetNamespace()}, ratelimitService); err != nil {
+		if k8serr.IsNotFound(err) {
+			r.log.Info("Waiting for the rate limit service to be created")
+			return integreatlyv1alpha1.PhaseAwaitingComponents, nil
+		}
+		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to get rate limit service: %w", err)
+	}
+
+	clusters, listeners, err := threescale.GetAPIcastEnvoyResources(r.installation, ratelimitService, envoyNodeID(r.gateway))
+	if err != nil {
+		return integreatlyv1alpha1.PhaseFailed, err
+	}
+	envoyConfig := ratelimit.NewEnvoyConfig(envoyNodeID(r.gateway), r.gateway.Namespace, envoyNodeID(r.gateway))
+	if err := envoyConfig.CreateEnvoyConfig(ctx, r.client, clusters, listeners, r.installation); err != nil {
+		return integreatlyv1alpha1.PhaseFailed, err
+	}
+	gatewayEnvoyConfig := &marin3rv1alpha1.EnvoyConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      envoyNodeID(r.gateway),
+			Namespace: r.gateway.Namespace,
+		},
+	}
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.client, gatewayEnvoyConfig, func() error {
+		return controllerutil.SetControllerReference(r.gateway, gatewayEnvoyConfig, r.scheme)
+	}); err != nil {
+		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to set owner of envoy config: %w", err)
+	}
+
+	return integreatlyv1alpha1.PhaseCompleted, nil
+}
+
+// removeRateLimiting deletes the EnvoyConfig of the gateway once rate
+// limiting is disabled, and releases its ownership of the DiscoveryService,
+// which is deleted if no other gateway owns it
+func (r *gatewayReconciler) removeRateLimiting(ctx context.Context) error {
+	envoyConfig := &marin3rv1alpha1.EnvoyConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      envoyNodeID(r.gateway),
+			Namespace: r.gateway.Namespace,
+		},
+	}
+	if err := r.client.Delete(ctx, envoyConfig); err != nil && !k8serr.IsNotFound(err) && !meta.IsNoMatchError(err) {
+		return fmt.Errorf("failed to delete self-managed gateway envoy config: %w", err)
+	}
+
+	discoveryService := &marin3roperator.DiscoveryService{}
+	if err := r.client.Get(ctx, k8sclient.ObjectKey{Name: discoveryServiceName, Namespace: r.gateway.Namespace}, discoveryService); err != nil {
+		if k8serr.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get discovery service: %w", err)
+	}
+
+	owners := []metav1.OwnerReference{}
+	for _, ownerRef := range discoveryService.OwnerReferences {
+		if ownerRef.UID != r.gateway.UID {
+			owners = append(owners, ownerRef)
+		}
+	}
+	if len(owners) == len(discoveryService.OwnerReferences) {
+		return nil
+	}
+	if !ownedByGateway(owners) {
+		if err := r.client.Delete(ctx, discoveryService); err != nil && !k8serr.IsNotFound(err) {
+			return fmt.Errorf("failed to delete discovery service: %w", err)
+		}
+		return nil
+	}
+	discoveryService.OwnerReferences = owners
+	if err := r.client.Update(ctx, discoveryService); err != nil {
+		return fmt.Errorf("failed to update owners of discovery service: %w", err)
+	}
+	return nil
+}
+
+// reconcileMonitoring creates a blackbox probe against the APIcast
+// management API and the alerts for the gateway in the observability
+// namespace
+func (r *gatewayReconciler) reconcileMonitoring(ctx context.Context) (integreatlyv1alpha1.StatusPhase, error) {
+	if !integreatlyv1alpha1.IsRHOAM(integreatlyv1alpha1.InstallationType(r.installation.Spec.Type)) {
+		return integreatlyv1alpha1.PhaseCompleted, nil
+	}
+
+	observabilityConfig, err := r.configManager.ReadObservability()
+	if err != nil {
+		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("could not read observability config: %w", err)
+	}
+
+	phase, err := observability.CreatePrometheusProbe(ctx, r.client, r.installation, observabilityConfig, probeName(r.gateway), "http_2xx", prometheus.ProbeTargetStaticConfig{
+		Targets: []string{fmt.Sprintf("http://%s.%s.svc:%d/status/live", gatewayName(r.gateway), r.gateway.Namespace, apicastManagementPort)},
+		Labels: map[string]string{
+			"service": probeName(r.gateway),
+		},
+	})
+	if err != nil {
+		return phase, fmt.Errorf("error creating self-managed gateway prometheus probe: %w", err)
+	}
+	if phase != integreatlyv1alpha1.PhaseCompleted {
+		return phase, nil
+	}
+
+	return r.newAlertReconciler(observabilityConfig.GetNamespace()).ReconcileAlerts(ctx, r.client)
+}
+
+// cleanup removes the resources created outside the gateway namespace
+func (r *gatewayReconciler) cleanup(ctx context.Context) error {
+	if !integreatlyv1alpha1.IsRHOAM(integreatlyv1alpha1.InstallationType(r.installation.Spec.Type)) {
+		return nil
+	}
+
+	observabilityConfig, err := r.configManager.ReadObservability()
+	if err != nil {
+		return fmt.Errorf("could not read observability config: %w", err)
+	}
+
+	probe := &prometheus.Probe{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      probeName(r.gateway),
+			Namespace: observabilityConfig.GetNamespace(),
+		},
+	}
+	if err := r.client.Delete(ctx, probe); err != nil && !k8serr.IsNotFound(err) {
+		return fmt.Errorf("failed to delete self-managed gateway probe: %w", err)
+	}
+
+	rule := &prometheus.PrometheusRule{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      alertRuleName(r.gateway),
+			Namespace: observabilityConfig.GetNamespace(),
+		},
+	}
+	if err := r.client.Delete(ctx, rule); err != nil && !k8serr.IsNotFound(err) {
+		return fmt.Errorf("failed to delete self-managed gateway alerts: %w", err)
+	}
+
+	return nil
+}
+
+func getApicastContainer(gateway *integreatlyv1alpha1.SelfManagedGateway, image string) corev1.Container {
+	deploymentEnv := gateway.Spec.DeploymentEnvironment
+	if deploymentEnv == "" {
+		deploymentEnv = defaultDeploymentEnv
+	}
+	loadMode := gateway.Spec.ConfigurationLoadMode
+	if loadMode == "" {
+		loadMode = defaultConfigLoadMode
+	}
+
+	env := []corev1.EnvVar{
+		{
+			Name: "THREESCALE_PORTAL_ENDPOINT",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: adminPortalSecretName(gateway)},
+					Key:                  adminPortalURLKey,
+				},
+			},
+		},
+		{Name: "THREESCALE_DEPLOYMENT_ENV", Value: deploymentEnv},
+		{Name: "APICAST_CONFIGURATION_LOADER", Value: loadMode},
+		{Name: "APICAST_MANAGEMENT_API", Value: "status"},
+	}
+	if loadMode == defaultConfigLoadMode {
+		env = append(env, corev1.EnvVar{Name: "APICAST_CONFIGURATION_CACHE", Value: configurationCacheBoot})
+	}
+
+	volumeMounts := []corev1.VolumeMount{}
+	for _, policy := range gateway.Spec.CustomPolicies {
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      customPolicyVolumeName(policy),
+			MountPath: fmt.Sprintf("%s/%s/%s", customPolicyMountPath, policy.Name, policy.Version),
+			ReadOnly:  true,
+		})
+	}
+
+	container := corev1.Container{
+		Name:  apicastContainerName,
+		Image: image,
+		Env:   env,
+		Ports: []corev1.ContainerPort{
+			{Name: "proxy", ContainerPort: int32(threescale.ApicastContainerPort), Protocol: corev1.ProtocolTCP},
+			{Name: "management", ContainerPort: apicastManagementPort, Protocol: corev1.ProtocolTCP},
+			{Name: "metrics", ContainerPort: apicastMetricsPort, Protocol: corev1.ProtocolTCP},
+		},
+		VolumeMounts: volumeMounts,
+		LivenessProbe: &corev1.Probe{
+			Handler: corev1.Handler{
+				HTTPGet: &corev1.HTTPGetAction{Path: "/status/live", Port: intstr.FromInt(apicastManagementPort)},
+			},
+			InitialDelaySeconds: 10,
+			PeriodSeconds:       10,
+		},
+		ReadinessProbe: &corev1.Probe{
+			Handler: corev1.Handler{
+				HTTPGet: &corev1.HTTPGetAction{Path: "/status/ready", Port: intstr.FromInt(apicastManagementPort)},
+			},
+			InitialDelaySeconds: 15,
+			PeriodSeconds:       30,
+		},
+	}
+	if gateway.Spec.Resources != nil {
+		container.Resources = *gateway.Spec.Resources
+	}
+
+	return container
+}
+
+func getCustomPolicyVolumes(gateway *integreatlyv1alpha1.SelfManagedGateway) []corev1.Volume {
+	volumes := []corev1.Volume{}
+	for _, policy := range gateway.Spec.CustomPolicies {
+		volumes = append(volumes, corev1.Volume{
+			Name: customPolicyVolumeName(policy),
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: policy.ConfigMapRef,
+				},
+			},
+		})
+	}
+	return volumes
+}
+
+// addEnvoySidecarMetadata sets the labels and annotations that make the
+// marin3r webhook inject the envoy sidecar into the gateway pods
+func addEnvoySidecarMetadata(template *corev1.PodTemplateSpec, nodeID string) {
+	template.Labels["marin3r.3scale.net/status"] = "enabled"
+	template.Annotations["marin3r.3scale.net/node-id"] = nodeID
+	template.Annotations["marin3r.3scale.net/ports"] = fmt.Sprintf("envoy-https:%s", strconv.Itoa(threescale.ApicastEnvoyProxyPort))
+	template.Annotations["marin3r.3scale.net/envoy-api-version"] = ratelimit.EnvoyAPIVersion
+	template.Annotations["marin3r.3scale.net/envoy-image"] = ratelimit.EnvoyImage
+}
+
+// ownedByGateway returns true if one of the owners is a SelfManagedGateway
+func ownedByGateway(owners []metav1.OwnerReference) bool {
+	for _, ownerRef := range owners {
+		if ownerRef.Kind == "SelfManagedGateway" {
+			return true
+		}
+	}
+	return false
+}
+
+func gatewayLabels(gateway *integreatlyv1alpha1.SelfManagedGateway) map[string]string {
+	return map[string]string{
+		"app":           "apicast",
+		gatewayLabelKey: gateway.Name,
+	}
+}
+
+func gatewayName(gateway *integreatlyv1alpha1.SelfManagedGateway) string {
+	return "apicast-" + gateway.Name
+}
+
+func adminPortalSecretName(gateway *integreatlyv1alpha1.SelfManagedGateway) string {
+	return gateway.Name + "-admin-portal-credentials"
+}
+
+func envoyNodeID(gateway *integreatlyv1alpha1.SelfManagedGateway) string {
+	return gateway.Name + "-ratelimit"
+}
+
+func customPolicyVolumeName(policy integreatlyv1alpha1.SelfManagedGatewayCustomPolicy) string {
+	return fmt.Sprintf("policy-%s-%s", policy.Name, strings.ReplaceAll(policy.Version, ".", "-"))
+}
+
+// probeName and alertRuleName include the namespace as the resources are
+// created in the shared observability namespace
+func probeName(gateway *integreatlyv1alpha1.SelfManagedGateway) string {
+	return fmt.Sprintf("selfmanaged-apicast-%s-%s", gateway.Namespace, gateway.Name)
+}
+
+func alertRuleName(gateway *integreatlyv1alpha1.SelfManagedGateway) string {
+	return probeName(gateway) + "-alerts"
+}
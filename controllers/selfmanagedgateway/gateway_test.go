@@ -0,0 +1,351 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	marin3rv1alpha1 "github.com/3scale-ops/marin3r/apis/marin3r/v1alpha1"
+	marin3roperator "github.com/3scale-ops/marin3r/apis/operator.marin3r/v1alpha1"
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/config"
+	"github.com/integr8ly/integreatly-operator/pkg/products/threescale"
+	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+	appsv1 "github.com/openshift/api/apps/v1"
+	routev1 "github.com/openshift/api/route/v1"
+	k8sappsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	threescaleNs = "redhat-rhoam-3scale"
+	marin3rNs    = "redhat-rhoam-marin3r"
+	gatewayNs    = "customer-gateways"
+	apicastImage = "registry.redhat.io/3scale-amp2/apicast-gateway-rhel8:3scale2.10"
+)
+
+func getBuildScheme() (*runtime.Scheme, error) {
+	scheme := runtime.NewScheme()
+	if err := integreatlyv1alpha1.SchemeBuilder.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	if err := k8sappsv1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	if err := appsv1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	if err := routev1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	if err := marin3rv1alpha1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	err := marin3roperator.AddToScheme(scheme)
+	return scheme, err
+}
+
+func basicConfigMock(threescaleHost string) *config.ConfigReadWriterMock {
+	return &config.ConfigReadWriterMock{
+		ReadThreeScaleFunc: func() (*config.ThreeScale, error) {
+			return config.NewThreeScale(config.ProductConfig{
+				"NAMESPACE": threescaleNs,
+				"HOST":      threescaleHost,
+			}), nil
+		},
+		ReadMarin3rFunc: func() (*config.Marin3r, error) {
+			return config.NewMarin3r(config.ProductConfig{
+				"NAMESPACE": marin3rNs,
+			}), nil
+		},
+	}
+}
+
+func basicGateway() *integreatlyv1alpha1.SelfManagedGateway {
+	return &integreatlyv1alpha1.SelfManagedGateway{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "example",
+			Namespace: gatewayNs,
+		},
+	}
+}
+
+func systemSeedSecret() *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "system-seed",
+			Namespace: threescaleNs,
+		},
+		Data: map[string][]byte{
+			"ADMIN_ACCESS_TOKEN": []byte("token"),
+		},
+	}
+}
+
+func apicastProductionDC() *appsv1.DeploymentConfig {
+	return &appsv1.DeploymentConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      threescale.ApicastProductionDCName,
+			Namespace: threescaleNs,
+		},
+		Spec: appsv1.DeploymentConfigSpec{
+			Template: &corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: threescale.ApicastProductionDCName, Image: apicastImage},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestGatewayReconciler_reconcile(t *testing.T) {
+	scheme, err := getBuildScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	installation := &integreatlyv1alpha1.RHMI{
+		Spec: integreatlyv1alpha1.RHMISpec{
+			Type: string(integreatlyv1alpha1.InstallationTypeManaged),
+		},
+	}
+
+	tests := []struct {
+		Name          string
+		FakeConfig    *config.ConfigReadWriterMock
+		FakeClient    k8sclient.Client
+		Gateway       *integreatlyv1alpha1.SelfManagedGateway
+		ExpectedPhase integreatlyv1alpha1.StatusPhase
+		ExpectErr     bool
+		Assertion     func(k8sclient.Client, *integreatlyv1alpha1.SelfManagedGateway) error
+	}{
+		{
+			Name:          "test awaiting components when 3scale is not installed",
+			FakeConfig:    basicConfigMock(""),
+			FakeClient:    fakeclient.NewFakeClientWithScheme(scheme),
+			Gateway:       basicGateway(),
+			ExpectedPhase: integreatlyv1alpha1.PhaseAwaitingComponents,
+		},
+		{
+			Name:          "test awaiting components when the admin access token is not found",
+			FakeConfig:    basicConfigMock("https://3scale-admin.apps.example.com"),
+			FakeClient:    fakeclient.NewFakeClientWithScheme(scheme),
+			Gateway:       basicGateway(),
+			ExpectedPhase: integreatlyv1alpha1.PhaseAwaitingComponents,
+		},
+		{
+			Name:       "test failure when a custom policy configmap is missing",
+			FakeConfig: basicConfigMock("https://3scale-admin.apps.example.com"),
+			FakeClient: fakeclient.NewFakeClientWithScheme(scheme, systemSeedSecret(), apicastProductionDC()),
+			Gateway: func() *integreatlyv1alpha1.SelfManagedGateway {
+				gateway := basicGateway()
+				gateway.Spec.CustomPolicies = []integreatlyv1alpha1.SelfManagedGatewayCustomPolicy{
+					{Name: "example", Version: "0.1", ConfigMapRef: corev1.LocalObjectReference{Name: "missing"}},
+				}
+				return gateway
+			}(),
+			ExpectedPhase: integreatlyv1alpha1.PhaseFailed,
+			ExpectErr:     true,
+		},
+		{
+			Name:          "test gateway resources are created with the apicast-production image",
+			FakeConfig:    basicConfigMock("https://3scale-admin.apps.example.com"),
+			FakeClient:    fakeclient.NewFakeClientWithScheme(scheme, systemSeedSecret(), apicastProductionDC()),
+			Gateway:       basicGateway(),
+			ExpectedPhase: integreatlyv1alpha1.PhaseInProgress,
+			Assertion: func(client k8sclient.Client, gateway *integreatlyv1alpha1.SelfManagedGateway) error {
+				secret := &corev1.Secret{}
+				if err := client.Get(context.TODO(), k8sclient.ObjectKey{Name: adminPortalSecretName(gateway), Namespace: gatewayNs}, secret); err != nil {
+					return err
+				}
+				if string(secret.Data[adminPortalURLKey]) != "https://token@3scale-admin.apps.example.com" {
+					return fmt.Errorf("unexpected admin portal url %s", secret.Data[adminPortalURLKey])
+				}
+
+				deployment := &k8sappsv1.Deployment{}
+				if err := client.Get(context.TODO(), k8sclient.ObjectKey{Name: gatewayName(gateway), Namespace: gatewayNs}, deployment); err != nil {
+					return err
+				}
+				if image := deployment.Spec.Template.Spec.Containers[0].Image; image != apicastImage {
+					return fmt.Errorf("expected image %s, got %s", apicastImage, image)
+				}
+				if *deployment.Spec.Replicas != 1 {
+					return fmt.Errorf("expected 1 replica, got %d", *deployment.Spec.Replicas)
+				}
+
+				service := &corev1.Service{}
+				if err := client.Get(context.TODO(), k8sclient.ObjectKey{Name: gatewayName(gateway), Namespace: gatewayNs}, service); err != nil {
+					return err
+				}
+				if service.Spec.Ports[0].TargetPort.IntValue() != threescale.ApicastContainerPort {
+					return fmt.Errorf("expected service to target port %d, got %d", threescale.ApicastContainerPort, service.Spec.Ports[0].TargetPort.IntValue())
+				}
+
+				if gateway.Status.Image != apicastImage {
+					return fmt.Errorf("expected status image %s, got %s", apicastImage, gateway.Status.Image)
+				}
+				return nil
+			},
+		},
+		{
+			Name:       "test route is created for the exposed host",
+			FakeConfig: basicConfigMock("https://3scale-admin.apps.example.com"),
+			FakeClient: fakeclient.NewFakeClientWithScheme(scheme, systemSeedSecret(), apicastProductionDC()),
+			Gateway: func() *integreatlyv1alpha1.SelfManagedGateway {
+				gateway := basicGateway()
+				gateway.Spec.ExposedHost = "gateway.apps.example.com"
+				return gateway
+			}(),
+			ExpectedPhase: integreatlyv1alpha1.PhaseInProgress,
+			Assertion: func(client k8sclient.Client, gateway *integreatlyv1alpha1.SelfManagedGateway) error {
+				route := &routev1.Route{}
+				if err := client.Get(context.TODO(), k8sclient.ObjectKey{Name: gatewayName(gateway), Namespace: gatewayNs}, route); err != nil {
+					return err
+				}
+				if route.Spec.Host != gateway.Spec.ExposedHost {
+					return fmt.Errorf("expected route host %s, got %s", gateway.Spec.ExposedHost, route.Spec.Host)
+				}
+				if gateway.Status.Host != gateway.Spec.ExposedHost {
+					return fmt.Errorf("expected status host %s, got %s", gateway.Spec.ExposedHost, gateway.Status.Host)
+				}
+				return nil
+			},
+		},
+		{
+			Name:       "test awaiting components when the rate limit service is not found",
+			FakeConfig: basicConfigMock("https://3scale-admin.apps.example.com"),
+			FakeClient: fakeclient.NewFakeClientWithScheme(scheme, systemSeedSecret(), apicastProductionDC()),
+			Gateway: func() *integreatlyv1alpha1.SelfManagedGateway {
+				gateway := basicGateway()
+				gateway.Spec.RateLimiting = true
+				return gateway
+			}(),
+			ExpectedPhase: integreatlyv1alpha1.PhaseAwaitingComponents,
+			Assertion: func(client k8sclient.Client, gateway *integreatlyv1alpha1.SelfManagedGateway) error {
+				discoveryService := &marin3roperator.DiscoveryService{}
+				if err := client.Get(context.TODO(), k8sclient.ObjectKey{Name: discoveryServiceName, Namespace: gatewayNs}, discoveryService); err != nil {
+					return err
+				}
+				if !ownedByGateway(discoveryService.OwnerReferences) {
+					return fmt.Errorf("expected the discovery service to be owned by the gateway, got %v", discoveryService.OwnerReferences)
+				}
+				return nil
+			},
+		},
+		{
+			Name:       "test envoy config and discovery service are removed when rate limiting is disabled",
+			FakeConfig: basicConfigMock("https://3scale-admin.apps.example.com"),
+			FakeClient: fakeclient.NewFakeClientWithScheme(scheme, systemSeedSecret(), apicastProductionDC(),
+				&marin3rv1alpha1.EnvoyConfig{
+					ObjectMeta: metav1.ObjectMeta{Name: envoyNodeID(basicGateway()), Namespace: gatewayNs},
+				},
+				&marin3roperator.DiscoveryService{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:            discoveryServiceName,
+						Namespace:       gatewayNs,
+						OwnerReferences: []metav1.OwnerReference{{Kind: "SelfManagedGateway", Name: "example", UID: "example-uid"}},
+					},
+				},
+			),
+			Gateway: func() *integreatlyv1alpha1.SelfManagedGateway {
+				gateway := basicGateway()
+				gateway.UID = "example-uid"
+				return gateway
+			}(),
+			ExpectedPhase: integreatlyv1alpha1.PhaseInProgress,
+			Assertion: func(client k8sclient.Client, gateway *integreatlyv1alpha1.SelfManagedGateway) error {
+				envoyConfig := &marin3rv1alpha1.EnvoyConfig{}
+				if err := client.Get(context.TODO(), k8sclient.ObjectKey{Name: envoyNodeID(gateway), Namespace: gatewayNs}, envoyConfig); !k8serr.IsNotFound(err) {
+					return fmt.Errorf("expected the envoy config to be deleted, got %v", err)
+				}
+				discoveryService := &marin3roperator.DiscoveryService{}
+				if err := client.Get(context.TODO(), k8sclient.ObjectKey{Name: discoveryServiceName, Namespace: gatewayNs}, discoveryService); !k8serr.IsNotFound(err) {
+					return fmt.Errorf("expected the discovery service owned only by the gateway to be deleted, got %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name:       "test discovery service owned by another gateway is kept when rate limiting is disabled",
+			FakeConfig: basicConfigMock("https://3scale-admin.apps.example.com"),
+			FakeClient: fakeclient.NewFakeClientWithScheme(scheme, systemSeedSecret(), apicastProductionDC(),
+				&marin3roperator.DiscoveryService{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      discoveryServiceName,
+						Namespace: gatewayNs,
+						OwnerReferences: []metav1.OwnerReference{
+							{Kind: "SelfManagedGateway", Name: "example", UID: "example-uid"},
+							{Kind: "SelfManagedGateway", Name: "other", UID: "other-uid"},
+						},
+					},
+				},
+			),
+			Gateway: func() *integreatlyv1alpha1.SelfManagedGateway {
+				gateway := basicGateway()
+				gateway.UID = "example-uid"
+				return gateway
+			}(),
+			ExpectedPhase: integreatlyv1alpha1.PhaseInProgress,
+			Assertion: func(client k8sclient.Client, gateway *integreatlyv1alpha1.SelfManagedGateway) error {
+				discoveryService := &marin3roperator.DiscoveryService{}
+				if err := client.Get(context.TODO(), k8sclient.ObjectKey{Name: discoveryServiceName, Namespace: gatewayNs}, discoveryService); err != nil {
+					return err
+				}
+				if len(discoveryService.OwnerReferences) != 1 || discoveryService.OwnerReferences[0].Name != "other" {
+					return fmt.Errorf("expected only the other gateway to own the discovery service, got %v", discoveryService.OwnerReferences)
+				}
+				return nil
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			reconciler := newGatewayReconciler(tt.FakeClient, scheme, installation, tt.FakeConfig, tt.Gateway, l.NewLogger())
+
+			phase, err := reconciler.reconcile(context.TODO())
+			if (err != nil) != tt.ExpectErr {
+				t.Fatalf("unexpected error value, expected error: %v, got: %v", tt.ExpectErr, err)
+			}
+			if phase != tt.ExpectedPhase {
+				t.Fatalf("expected phase %s, got %s", tt.ExpectedPhase, phase)
+			}
+			if tt.Assertion != nil {
+				if err := tt.Assertion(tt.FakeClient, tt.Gateway); err != nil {
+					t.Fatalf("assertion failed: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestAddEnvoySidecarMetadata(t *testing.T) {
+	template := &corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:      map[string]string{},
+			Annotations: map[string]string{},
+		},
+	}
+
+	addEnvoySidecarMetadata(template, "example-ratelimit")
+
+	if template.Labels["marin3r.3scale.net/status"] != "enabled" {
+		t.Fatal("expected marin3r status label to be enabled")
+	}
+	if template.Annotations["marin3r.3scale.net/node-id"] != "example-ratelimit" {
+		t.Fatalf("unexpected node id %s", template.Annotations["marin3r.3scale.net/node-id"])
+	}
+	if template.Annotations["marin3r.3scale.net/ports"] != "envoy-https:8443" {
+		t.Fatalf("unexpected ports annotation %s", template.Annotations["marin3r.3scale.net/ports"])
+	}
+}
@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"fmt"
+
+	"github.com/integr8ly/integreatly-operator/pkg/resources"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func (r *gatewayReconciler) newAlertReconciler(namespace string) resources.AlertReconciler {
+	installationName := resources.InstallationNames[r.installation.Spec.Type]
+
+	rules := []monitoringv1.Rule{
+		{
+			Alert: "SelfManagedGatewayDown",
+			Annotations: map[string]string{
+				"sop_url": resources.SopUrlSelfManagedGatewayDown,
+				"message": fmt.Sprintf("Self-managed gateway %s in namespace %s has no available replicas.", r.gateway.Name, r.gateway.Namespace),
+			},
+			Expr:   intstr.FromString(fmt.Sprintf("kube_deployment_status_replicas_available{deployment='%s', namespace='%s'} < 1", gatewayName(r.gateway), r.gateway.Namespace)),
+			For:    "5m",
+			Labels: map[string]string{"severity": "warning", "product": installationName},
+		},
+		{
+			Alert: "SelfManagedGatewayProbeFailing",
+			Annotations: map[string]string{
+				"sop_url": resources.SopUrlSelfManagedGatewayProbeFailing,
+				"message": fmt.Sprintf("The liveness endpoint of self-managed gateway %s in namespace %s is not responding.", r.gateway.Name, r.gateway.Namespace),
+			},
+			Expr:   intstr.FromString(fmt.Sprintf("absent(probe_success{job='blackbox', service='%s'} == 1)", probeName(r.gateway))),
+			For:    "5m",
+			Labels: map[string]string{"severity": "warning", "product": installationName},
+		},
+	}
+
+	if r.gateway.Spec.RateLimiting {
+		rules = append(rules, monitoringv1.Rule{
+			Alert: "SelfManagedGatewayEnvoyContainerDown",
+			Annotations: map[string]string{
+				"sop_url": resources.SopUrlMarin3rEnvoyApicastProductionContainerDown,
+				"message": fmt.Sprintf("Self-managed gateway %s in namespace %s has no ratelimiting sidecar container attached.", r.gateway.Name, r.gateway.Namespace),
+			},
+			Expr:   intstr.FromString(fmt.Sprintf("(1 - absent(kube_pod_container_status_ready{container='envoy-sidecar'} * on (pod,namespace) kube_pod_labels{label_integreatly_org_selfmanaged_gateway='%s',namespace='%s'})) < 1", r.gateway.Name, r.gateway.Namespace)),
+			For:    "5m",
+			Labels: map[string]string{"severity": "warning", "product": installationName},
+		})
+	}
+
+	return &resources.AlertReconcilerImpl{
+		Installation: r.installation,
+		Log:          r.log,
+		ProductName:  "SelfManagedGateway",
+		Alerts: []resources.AlertConfiguration{
+			{
+				AlertName: alertRuleName(r.gateway),
+				GroupName: "selfmanaged-gateway.rules",
+				Namespace: namespace,
+				Rules:     rules,
+			},
+		},
+	}
+}
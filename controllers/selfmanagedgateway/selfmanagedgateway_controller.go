@@ -0,0 +1,210 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"time"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/config"
+	"github.com/integr8ly/integreatly-operator/pkg/resources"
+	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/rhmi"
+	routev1 "github.com/openshift/api/route/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	// gatewayFinalizer is used to clean up the resources created outside
+	// the namespace of the SelfManagedGateway, which can't be garbage
+	// collected through owner references
+	gatewayFinalizer = "selfmanagedgateway.integreatly.org/finalizer"
+
+	defaultInstallationConfigMapName = "installation-config"
+)
+
+var log = l.NewLoggerWithContext(l.Fields{l.ControllerLogContext: "selfmanagedgateway_controller"})
+
+// +kubebuilder:rbac:groups=integreatly.org,resources=selfmanagedgateways,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=integreatly.org,resources=selfmanagedgateways/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups="",resources=services;secrets,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups=route.openshift.io,resources=routes,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=probes;prometheusrules,verbs=get;list;create;update;delete
+// +kubebuilder:rbac:groups=operator.marin3r.3scale.net,resources=discoveryservices,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups=marin3r.3scale.net,resources=envoyconfigs,verbs=get;list;watch;create;update;delete
+
+// New returns a reconciler with an uncached client, as the gateways and the
+// resources they own are in customer namespaces, outside of the namespace the
+// manager cache is restricted to
+func New(mgr manager.Manager) (*SelfManagedGatewayReconciler, error) {
+	client, err := k8sclient.New(mgr.GetConfig(), k8sclient.Options{Scheme: mgr.GetScheme()})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SelfManagedGatewayReconciler{
+		Client: client,
+		Scheme: mgr.GetScheme(),
+		log:    log,
+	}, nil
+}
+
+// SelfManagedGatewayReconciler reconciles a SelfManagedGateway object into
+// an APIcast deployment connected to the 3scale tenant of the installation
+type SelfManagedGatewayReconciler struct {
+	k8sclient.Client
+	Scheme *runtime.Scheme
+	log    l.Logger
+}
+
+func (r *SelfManagedGatewayReconciler) Reconcile(request ctrl.Request) (ctrl.Result, error) {
+	ctx := context.TODO()
+
+	gateway := &integreatlyv1alpha1.SelfManagedGateway{}
+	if err := r.Get(ctx, request.NamespacedName, gateway); err != nil {
+		if k8serr.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	originalGateway := gateway.DeepCopy()
+
+	gatewayLog := l.NewLoggerWithContext(l.Fields{"gateway": gateway.Name, "ns": gateway.Namespace})
+
+	if gateway.DeletionTimestamp != nil {
+		return ctrl.Result{}, r.finalize(ctx, gateway, gatewayLog)
+	}
+
+	installation, configManager, err := r.getInstallation(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if installation == nil {
+		gatewayLog.Info("RHMI CR not found, waiting for the installation before reconciling the gateway")
+		return ctrl.Result{Requeue: true, RequeueAfter: time.Minute}, nil
+	}
+
+	reconciler := newGatewayReconciler(r.Client, r.Scheme, installation, configManager, gateway, gatewayLog)
+
+	if !resources.Contains(gateway.GetFinalizers(), gatewayFinalizer) {
+		gateway.SetFinalizers(append(gateway.GetFinalizers(), gatewayFinalizer))
+		if err := r.Update(ctx, gateway); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	phase, err := reconciler.reconcile(ctx)
+	gateway.Status.Phase = phase
+	if err != nil {
+		gatewayLog.Error("failed to reconcile self-managed gateway", err)
+		gateway.Status.LastError = err.Error()
+	} else {
+		gateway.Status.LastError = ""
+	}
+
+	if !reflect.DeepEqual(originalGateway.Status, gateway.Status) {
+		if updateErr := r.Status().Update(ctx, gateway); updateErr != nil {
+			gatewayLog.Error("error updating status of SelfManagedGateway CR", updateErr)
+			return ctrl.Result{}, updateErr
+		}
+	}
+
+	if phase != integreatlyv1alpha1.PhaseCompleted {
+		return ctrl.Result{Requeue: true, RequeueAfter: 10 * time.Second}, err
+	}
+	return ctrl.Result{Requeue: true, RequeueAfter: 5 * time.Minute}, nil
+}
+
+// finalize deletes the probe and alerts of a deleted gateway and removes its
+// finalizer. The resources it owns are garbage collected, and the probe and
+// alerts are removed along with the installation, so the finalizer is removed
+// without cleanup when there's no installation left
+func (r *SelfManagedGatewayReconciler) finalize(ctx context.Context, gateway *integreatlyv1alpha1.SelfManagedGateway, gatewayLog l.Logger) error {
+	if !resources.Contains(gateway.GetFinalizers(), gatewayFinalizer) {
+		return nil
+	}
+
+	installation, configManager, err := r.getInstallation(ctx)
+	if err != nil {
+		return err
+	}
+	if installation != nil && installation.DeletionTimestamp == nil {
+		if err := newGatewayReconciler(r.Client, r.Scheme, installation, configManager, gateway, gatewayLog).cleanup(ctx); err != nil {
+			return err
+		}
+	} else {
+		gatewayLog.Info("RHMI CR not found or being deleted, removing the gateway finalizer without cleanup")
+	}
+
+	gateway.SetFinalizers(resources.Remove(gateway.GetFinalizers(), gatewayFinalizer))
+	return r.Update(ctx, gateway)
+}
+
+func (r *SelfManagedGatewayReconciler) getInstallation(ctx context.Context) (*integreatlyv1alpha1.RHMI, *config.Manager, error) {
+	namespace, err := resources.GetWatchNamespace()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	installation, err := rhmi.GetRhmiCr(r.Client, ctx, namespace, r.log)
+	if err != nil || installation == nil {
+		return nil, nil, err
+	}
+
+	installationCfgMap := os.Getenv("INSTALLATION_CONFIG_MAP")
+	if installationCfgMap == "" {
+		installationCfgMap = installation.Spec.NamespacePrefix + defaultInstallationConfigMapName
+	}
+	configManager, err := config.NewManager(ctx, r.Client, installation.Namespace, installationCfgMap, installation)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create config manager: %w", err)
+	}
+
+	return installation, configManager, nil
+}
+
+// SetupWithManager watches the gateways and the resources they own through a
+// cluster scoped cache of their own, as the manager cache is restricted to
+// the namespace of the operator
+func (r *SelfManagedGatewayReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	gatewayCache, err := cache.New(mgr.GetConfig(), cache.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+	if err != nil {
+		return fmt.Errorf("failed to create the self-managed gateway cache: %w", err)
+	}
+	// Adding to Manager, which will start it for us with a correct stop channel
+	if err := mgr.Add(gatewayCache); err != nil {
+		return fmt.Errorf("failed to add the self-managed gateway cache to the manager: %w", err)
+	}
+
+	c, err := controller.New("selfmanagedgateway-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	if err := c.Watch(source.NewKindWithCache(&integreatlyv1alpha1.SelfManagedGateway{}, gatewayCache), &handler.EnqueueRequestForObject{}); err != nil {
+		return err
+	}
+	for _, owned := range []runtime.Object{&appsv1.Deployment{}, &corev1.Service{}, &routev1.Route{}} {
+		err := c.Watch(source.NewKindWithCache(owned, gatewayCache), &handler.EnqueueRequestForOwner{
+			OwnerType:    &integreatlyv1alpha1.SelfManagedGateway{},
+			IsController: true,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
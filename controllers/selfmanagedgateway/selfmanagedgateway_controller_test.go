@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSelfManagedGatewayReconciler_Reconcile(t *testing.T) {
+	scheme, err := getBuildScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("WATCH_NAMESPACE", "redhat-rhoam-operator")
+
+	gateway := basicGateway()
+	now := metav1.Now()
+	gateway.DeletionTimestamp = &now
+	gateway.Finalizers = []string{gatewayFinalizer}
+
+	client := fakeclient.NewFakeClientWithScheme(scheme, gateway)
+	reconciler := &SelfManagedGatewayReconciler{Client: client, Scheme: scheme, log: l.NewLogger()}
+
+	result, err := reconciler.Reconcile(ctrl.Request{NamespacedName: k8sclient.ObjectKey{Name: gateway.Name, Namespace: gateway.Namespace}})
+	if err != nil {
+		t.Fatalf("unexpected error deleting gateway without installation: %v", err)
+	}
+	if result.Requeue {
+		t.Fatal("expected deleted gateway not to be requeued")
+	}
+
+	deleted := &integreatlyv1alpha1.SelfManagedGateway{}
+	if err := client.Get(context.TODO(), k8sclient.ObjectKey{Name: gateway.Name, Namespace: gateway.Namespace}, deleted); err != nil {
+		t.Fatal(err)
+	}
+	if len(deleted.Finalizers) != 0 {
+		t.Fatalf("expected the finalizer to be removed without an installation, got %v", deleted.Finalizers)
+	}
+}
@@ -0,0 +1,304 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/resources"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/secretprovider"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/smtphealth"
+	consolev1 "github.com/openshift/api/console/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	ChannelEmail   = "Email"
+	ChannelEvent   = "Event"
+	ChannelConsole = "ConsoleNotification"
+	ChannelWebhook = "Webhook"
+
+	// DefaultSendTimeout is the timeout of sending a notification to a
+	// contact
+	DefaultSendTimeout = 30 * time.Second
+
+	bannerColor           = "#fff"
+	bannerBackgroundColor = "#0088ce"
+)
+
+// SendMailFunc sends an email through the SMTP provider of credentials
+type SendMailFunc func(ctx context.Context, credentials smtphealth.Credentials, from string, to []string, message []byte) error
+
+type emailNotifier struct {
+	secrets  secretprovider.Provider
+	sendMail SendMailFunc
+}
+
+var _ Notifier = &emailNotifier{}
+
+// NewEmailNotifier returns a notifier that emails the email contacts through
+// the active SMTP provider of the installation, read from secrets. The
+// sender is the alert from address of the installation
+func NewEmailNotifier(secrets secretprovider.Provider, sendMail SendMailFunc) Notifier {
+	if sendMail == nil {
+		sendMail = SendMail
+	}
+	return &emailNotifier{secrets: secrets, sendMail: sendMail}
+}
+
+func (n *emailNotifier) Channel() string {
+	return ChannelEmail
+}
+
+func (n *emailNotifier) Notify(ctx context.Context, notification Notification) ([]string, error) {
+	emails, _ := Contacts(notification.Config)
+	emails = notification.undelivered(emails)
+	if len(emails) == 0 {
+		return nil, nil
+	}
+
+	from := notification.Installation.Spec.AlertFromAddress
+	if from == "" {
+		from = os.Getenv(integreatlyv1alpha1.EnvKeyAlertSMTPFrom)
+	}
+	if from == "" {
+		return nil, fmt.Errorf("no from address is configured to email the upgrade notification with")
+	}
+
+	secretName := smtphealth.ActiveSecret(notification.Installation)
+	if secretName == "" {
+		return nil, fmt.Errorf("no smtp secret is configured to email the upgrade notification with")
+	}
+	data, err := n.secrets.GetSecret(ctx, secretName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get smtp secret %s: %w", secretName, err)
+	}
+	credentials, err := smtphealth.CredentialsFromSecret(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid smtp secret %s: %w", secretName, err)
+	}
+
+	message := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n",
+		from, strings.Join(emails, ", "), notification.Subject(), notification.Message())
+	if err := n.sendMail(ctx, credentials, from, emails, []byte(message)); err != nil {
+		return nil, err
+	}
+	return emails, nil
+}
+
+func (n *emailNotifier) Clear(_ context.Context, _ *integreatlyv1alpha1.RHMI) error {
+	return nil
+}
+
+// SendMail sends an email through the SMTP provider of credentials
+func SendMail(ctx context.Context, credentials smtphealth.Credentials, from string, to []string, message []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, DefaultSendTimeout)
+	defer cancel()
+
+	client, err := smtphealth.Dial(ctx, credentials)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if err := client.Mail(from); err != nil {
+		return fmt.Errorf("failed to set sender %s: %w", from, err)
+	}
+	for _, recipient := range to {
+		if err := client.Rcpt(recipient); err != nil {
+			return fmt.Errorf("failed to add recipient %s: %w", recipient, err)
+		}
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(message); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+type eventNotifier struct {
+	recorder record.EventRecorder
+}
+
+var _ Notifier = &eventNotifier{}
+
+// NewEventNotifier returns a notifier that records an event on the RHMIConfig
+func NewEventNotifier(recorder record.EventRecorder) Notifier {
+	return &eventNotifier{recorder: recorder}
+}
+
+func (n *eventNotifier) Channel() string {
+	return ChannelEvent
+}
+
+func (n *eventNotifier) Notify(_ context.Context, notification Notification) ([]string, error) {
+	n.recorder.Event(notification.Config, "Normal", "Upgrade"+string(notification.Stage), notification.Message())
+	return nil, nil
+}
+
+func (n *eventNotifier) Clear(_ context.Context, _ *integreatlyv1alpha1.RHMI) error {
+	return nil
+}
+
+type consoleNotifier struct {
+	client k8sclient.Client
+}
+
+var _ Notifier = &consoleNotifier{}
+
+// NewConsoleNotifier returns a notifier that shows the latest notification in
+// a banner at the top of the OpenShift console, until the upgrade is applied
+func NewConsoleNotifier(client k8sclient.Client) Notifier {
+	return &consoleNotifier{client: client}
+}
+
+// ConsoleNotificationName returns the name of the upgrade banner of the
+// installation
+func ConsoleNotificationName(installation *integreatlyv1alpha1.RHMI) string {
+	return resources.InstallationNames[installation.Spec.Type] + "-upgrade"
+}
+
+func (n *consoleNotifier) Channel() string {
+	return ChannelConsole
+}
+
+func (n *consoleNotifier) Notify(ctx context.Context, notification Notification) ([]string, error) {
+	banner := &consolev1.ConsoleNotification{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: ConsoleNotificationName(notification.Installation),
+		},
+	}
+	_, err := controllerutil.CreateOrUpdate(ctx, n.client, banner, func() error {
+		banner.Spec = consolev1.ConsoleNotificationSpec{
+			Text:            notification.Message(),
+			Location:        consolev1.BannerTop,
+			Color:           bannerColor,
+			BackgroundColor: bannerBackgroundColor,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create or update console notification %s: %w", banner.Name, err)
+	}
+	return nil, nil
+}
+
+func (n *consoleNotifier) Clear(ctx context.Context, installation *integreatlyv1alpha1.RHMI) error {
+	banner := &consolev1.ConsoleNotification{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: ConsoleNotificationName(installation),
+		},
+	}
+	if err := n.client.Delete(ctx, banner); err != nil && !k8serr.IsNotFound(err) {
+		return fmt.Errorf("failed to delete console notification %s: %w", banner.Name, err)
+	}
+	return nil
+}
+
+// WebhookPayload is the JSON body posted to the webhook contacts
+type WebhookPayload struct {
+	Stage            Stage      `json:"stage"`
+	Product          string     `json:"product"`
+	Version          string     `json:"version"`
+	ServiceAffecting bool       `json:"serviceAffecting"`
+	ScheduledFor     *time.Time `json:"scheduledFor,omitempty"`
+	Subject          string     `json:"subject"`
+	Message          string     `json:"message"`
+}
+
+type webhookNotifier struct {
+	httpClient *http.Client
+}
+
+var _ Notifier = &webhookNotifier{}
+
+// NewWebhookNotifier returns a notifier that posts the notification to the
+// webhook contacts
+func NewWebhookNotifier(httpClient *http.Client) Notifier {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: DefaultSendTimeout}
+	}
+	return &webhookNotifier{httpClient: httpClient}
+}
+
+func (n *webhookNotifier) Channel() string {
+	return ChannelWebhook
+}
+
+func (n *webhookNotifier) Notify(ctx context.Context, notification Notification) ([]string, error) {
+	_, webhooks := Contacts(notification.Config)
+	webhooks = notification.undelivered(webhooks)
+	if len(webhooks) == 0 {
+		return nil, nil
+	}
+
+	body, err := json.Marshal(WebhookPayload{
+		Stage:            notification.Stage,
+		Product:          notification.Product(),
+		Version:          notification.Upgrade.Version,
+		ServiceAffecting: notification.Upgrade.ServiceAffecting,
+		ScheduledFor:     notification.Upgrade.ScheduledFor,
+		Subject:          notification.Subject(),
+		Message:          notification.Message(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the webhooks the notification is posted to are returned even when
+	// others fail, so that they aren't posted to again on the retry
+	delivered := []string{}
+	failed := []string{}
+	for _, webhook := range webhooks {
+		if err := n.post(ctx, webhook, body); err != nil {
+			failed = append(failed, err.Error())
+			continue
+		}
+		delivered = append(delivered, webhook)
+	}
+	if len(failed) > 0 {
+		return delivered, fmt.Errorf("failed to post to webhooks: %s", strings.Join(failed, "; "))
+	}
+	return delivered, nil
+}
+
+func (n *webhookNotifier) post(ctx context.Context, webhook string, body []byte) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := n.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, response.Body)
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("%s responded with status %d", webhook, response.StatusCode)
+	}
+	return nil
+}
+
+func (n *webhookNotifier) Clear(_ context.Context, _ *integreatlyv1alpha1.RHMI) error {
+	return nil
+}
@@ -0,0 +1,196 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/integr8ly/integreatly-operator/pkg/resources/secretprovider"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/smtphealth"
+	consolev1 "github.com/openshift/api/console/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type secretsMock map[string]map[string][]byte
+
+func (s secretsMock) GetSecret(_ context.Context, name string) (map[string][]byte, error) {
+	data, ok := s[name]
+	if !ok {
+		return nil, secretprovider.ErrNotFound
+	}
+	return data, nil
+}
+
+func getNotification(stage Stage) Notification {
+	scheduledFor := time.Date(2021, 5, 11, 2, 0, 0, 0, time.UTC)
+	return Notification{
+		Stage:        stage,
+		Upgrade:      Upgrade{Version: "1.2.0", ServiceAffecting: true, ScheduledFor: &scheduledFor},
+		Installation: getInstallation(),
+		Config:       getRHMIConfig(),
+	}
+}
+
+func TestContacts(t *testing.T) {
+	config := getRHMIConfig()
+	config.Spec.Upgrade.Contacts = "user1@example.com, http://insecure.example.com,https://hooks.example.com/a, not a contact,Ops <ops@example.com>"
+
+	emails, webhooks := Contacts(config)
+	if strings.Join(emails, ",") != "user1@example.com,ops@example.com" {
+		t.Errorf("unexpected emails %v", emails)
+	}
+	if strings.Join(webhooks, ",") != "https://hooks.example.com/a" {
+		t.Errorf("unexpected webhooks %v", webhooks)
+	}
+}
+
+func TestEmailNotifier(t *testing.T) {
+	tests := []struct {
+		Name         string
+		Secrets      secretsMock
+		Contacts     string
+		ExpectErr    bool
+		ExpectedSent bool
+	}{
+		{
+			Name: "test notification is emailed through the active smtp provider",
+			Secrets: secretsMock{"redhat-rhoam-smtp": {
+				"host": []byte("smtp.example.com"), "port": []byte("587"), "username": []byte("user"), "password": []byte("pass"),
+			}},
+			Contacts:     "user1@example.com",
+			ExpectedSent: true,
+		},
+		{
+			Name:     "test nothing is sent without email contacts",
+			Secrets:  secretsMock{},
+			Contacts: "https://hooks.example.com/upgrade",
+		},
+		{
+			Name:      "test missing smtp secret",
+			Secrets:   secretsMock{},
+			Contacts:  "user1@example.com",
+			ExpectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			sent := false
+			notifier := NewEmailNotifier(tt.Secrets, func(ctx context.Context, credentials smtphealth.Credentials, from string, to []string, message []byte) error {
+				sent = true
+				if credentials.Host != "smtp.example.com" || from != "noreply@example.com" || strings.Join(to, ",") != "user1@example.com" {
+					t.Errorf("unexpected email from %s to %v through %v", from, to, credentials)
+				}
+				if !strings.Contains(string(message), "Subject: RHOAM upgrade to version 1.2.0 is scheduled") {
+					t.Errorf("unexpected message %s", message)
+				}
+				return nil
+			})
+			notification := getNotification(StageScheduled)
+			notification.Config.Spec.Upgrade.Contacts = tt.Contacts
+
+			delivered, err := notifier.Notify(context.TODO(), notification)
+			if tt.ExpectErr != (err != nil) {
+				t.Fatalf("expected error %v, got %v", tt.ExpectErr, err)
+			}
+			if sent != tt.ExpectedSent {
+				t.Errorf("expected email sent %v, got %v", tt.ExpectedSent, sent)
+			}
+			if sent != (len(delivered) == 1) {
+				t.Errorf("expected the email contacts to be returned once emailed, got %v", delivered)
+			}
+		})
+	}
+}
+
+func TestEventNotifier(t *testing.T) {
+	recorder := record.NewFakeRecorder(1)
+	if _, err := NewEventNotifier(recorder).Notify(context.TODO(), getNotification(StageReminder)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	event := <-recorder.Events
+	if !strings.HasPrefix(event, "Normal UpgradeReminder The upgrade of RHOAM to version 1.2.0 is scheduled for 11 May 2021 02:00 UTC") {
+		t.Errorf("unexpected event %s", event)
+	}
+}
+
+func TestConsoleNotifier(t *testing.T) {
+	scheme, err := getBuildScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := fakeclient.NewFakeClientWithScheme(scheme)
+	notifier := NewConsoleNotifier(client)
+	key := k8sclient.ObjectKey{Name: "rhoam-upgrade"}
+
+	for _, stage := range []Stage{StageAvailable, StageScheduled} {
+		if _, err := notifier.Notify(context.TODO(), getNotification(stage)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	banner := &consolev1.ConsoleNotification{}
+	if err := client.Get(context.TODO(), key, banner); err != nil {
+		t.Fatal(err)
+	}
+	if banner.Spec.Location != consolev1.BannerTop || !strings.Contains(banner.Spec.Text, "is scheduled for") {
+		t.Errorf("expected a banner with the latest notification, got %v", banner.Spec)
+	}
+
+	if err := notifier.Clear(context.TODO(), getInstallation()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := client.Get(context.TODO(), key, banner); !k8serr.IsNotFound(err) {
+		t.Errorf("expected the banner to be deleted, got %v", err)
+	}
+	if err := notifier.Clear(context.TODO(), getInstallation()); err != nil {
+		t.Errorf("expected clearing a deleted banner to succeed, got %v", err)
+	}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	payloads := []WebhookPayload{}
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload := WebhookPayload{}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("invalid payload: %v", err)
+		}
+		payloads = append(payloads, payload)
+		if r.URL.Path == "/failing" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier(server.Client())
+	notification := getNotification(StageAvailable)
+	notification.Config.Spec.Upgrade.Contacts = "user1@example.com," + server.URL + "/upgrade"
+	if _, err := notifier.Notify(context.TODO(), notification); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(payloads) != 1 || payloads[0].Stage != StageAvailable || payloads[0].Version != "1.2.0" || !payloads[0].ServiceAffecting {
+		t.Errorf("unexpected payloads %v", payloads)
+	}
+
+	// the webhooks are delivered to one by one, the ones delivered to aren't
+	// posted to again
+	payloads = []WebhookPayload{}
+	notification.Config.Spec.Upgrade.Contacts = server.URL + "/upgrade," + server.URL + "/failing," + server.URL + "/delivered"
+	notification.Delivered = map[string]bool{server.URL + "/delivered": true}
+	delivered, err := notifier.Notify(context.TODO(), notification)
+	if err == nil {
+		t.Errorf("expected an error for a failing webhook")
+	}
+	if len(delivered) != 1 || delivered[0] != server.URL+"/upgrade" {
+		t.Errorf("expected only the webhook posted to to be delivered, got %v", delivered)
+	}
+	if len(payloads) != 2 {
+		t.Errorf("expected the delivered webhook not to be posted to again, got %d posts", len(payloads))
+	}
+}
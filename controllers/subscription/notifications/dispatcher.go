@@ -0,0 +1,142 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var log = l.NewLoggerWithContext(l.Fields{l.ControllerLogContext: "upgrade_notifications"})
+
+// Dispatcher sends the notifications of an upgrade through every channel,
+// once per stage and channel. The sent notifications, and the contacts they
+// are delivered to, are recorded in the status of the RHMIConfig, so a channel
+// that fails is retried on the next call without notifying twice through the
+// others, or the contacts it was delivered to
+type Dispatcher struct {
+	client    k8sclient.Client
+	notifiers []Notifier
+	now       func() time.Time
+}
+
+func NewDispatcher(client k8sclient.Client, notifiers ...Notifier) *Dispatcher {
+	return &Dispatcher{
+		client:    client,
+		notifiers: notifiers,
+		now:       time.Now,
+	}
+}
+
+// Notify sends the notification of the latest stage the upgrade reached if it
+// wasn't sent yet
+func (d *Dispatcher) Notify(ctx context.Context, installation *integreatlyv1alpha1.RHMI, config *integreatlyv1alpha1.RHMIConfig, upgrade Upgrade) error {
+	now := d.now()
+
+	// notifications of previous upgrades are forgotten
+	sent := []integreatlyv1alpha1.UpgradeNotification{}
+	for _, notification := range config.Status.UpgradeNotifications {
+		if notification.Version == upgrade.Version {
+			sent = append(sent, notification)
+		}
+	}
+	updated := len(sent) != len(config.Status.UpgradeNotifications)
+
+	failed := []string{}
+	stage := DueStage(upgrade, now)
+	for _, notifier := range d.notifiers {
+		channel := notifier.Channel()
+		if isSent(sent, stage, channel) {
+			continue
+		}
+		notification := Notification{
+			Stage:        stage,
+			Upgrade:      upgrade,
+			Installation: installation,
+			Config:       config,
+			Delivered:    delivered(sent, stage, channel),
+		}
+		contacts, err := notifier.Notify(ctx, notification)
+		for _, contact := range contacts {
+			sent = append(sent, integreatlyv1alpha1.UpgradeNotification{
+				Version: upgrade.Version,
+				Stage:   string(stage),
+				Channel: channel,
+				Contact: contact,
+				SentAt:  metav1.NewTime(now),
+			})
+			updated = true
+		}
+		if err != nil {
+			log.Error(fmt.Sprintf("Failed to send %s upgrade notification through %s", stage, channel), err)
+			failed = append(failed, fmt.Sprintf("%s: %v", channel, err))
+			continue
+		}
+		log.Infof("Sent upgrade notification", l.Fields{"stage": stage, "channel": channel, "version": upgrade.Version})
+		sent = append(sent, integreatlyv1alpha1.UpgradeNotification{
+			Version: upgrade.Version,
+			Stage:   string(stage),
+			Channel: channel,
+			SentAt:  metav1.NewTime(now),
+		})
+		updated = true
+	}
+
+	if updated {
+		config.Status.UpgradeNotifications = sent
+		if err := d.client.Status().Update(ctx, config); err != nil {
+			return fmt.Errorf("failed to record upgrade notifications in rhmi config status: %w", err)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to send upgrade notifications: %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// Clear removes the notifications of every channel once no upgrade is
+// available, and forgets the sent notifications
+func (d *Dispatcher) Clear(ctx context.Context, installation *integreatlyv1alpha1.RHMI, config *integreatlyv1alpha1.RHMIConfig) error {
+	for _, notifier := range d.notifiers {
+		if err := notifier.Clear(ctx, installation); err != nil {
+			return fmt.Errorf("failed to clear upgrade notifications of %s: %w", notifier.Channel(), err)
+		}
+	}
+
+	if config == nil || len(config.Status.UpgradeNotifications) == 0 {
+		return nil
+	}
+	config.Status.UpgradeNotifications = nil
+	if err := d.client.Status().Update(ctx, config); err != nil {
+		return fmt.Errorf("failed to clear upgrade notifications of rhmi config status: %w", err)
+	}
+	return nil
+}
+
+// isSent returns whether the notification of stage was sent through channel,
+// to every contact of the channel
+func isSent(sent []integreatlyv1alpha1.UpgradeNotification, stage Stage, channel string) bool {
+	for _, notification := range sent {
+		if notification.Stage == string(stage) && notification.Channel == channel && notification.Contact == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// delivered returns the contacts the notification of stage was delivered to
+// through channel
+func delivered(sent []integreatlyv1alpha1.UpgradeNotification, stage Stage, channel string) map[string]bool {
+	contacts := map[string]bool{}
+	for _, notification := range sent {
+		if notification.Stage == string(stage) && notification.Channel == channel && notification.Contact != "" {
+			contacts[notification.Contact] = true
+		}
+	}
+	return contacts
+}
@@ -0,0 +1,251 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	consolev1 "github.com/openshift/api/console/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func getBuildScheme() (*runtime.Scheme, error) {
+	scheme := runtime.NewScheme()
+	if err := integreatlyv1alpha1.SchemeBuilder.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	return scheme, consolev1.AddToScheme(scheme)
+}
+
+func getInstallation() *integreatlyv1alpha1.RHMI {
+	return &integreatlyv1alpha1.RHMI{
+		ObjectMeta: metav1.ObjectMeta{Name: "rhoam", Namespace: "redhat-rhoam-operator"},
+		Spec: integreatlyv1alpha1.RHMISpec{
+			Type:             string(integreatlyv1alpha1.InstallationTypeManagedApi),
+			SMTPSecret:       "redhat-rhoam-smtp",
+			AlertFromAddress: "noreply@example.com",
+		},
+	}
+}
+
+func getRHMIConfig(notifications ...integreatlyv1alpha1.UpgradeNotification) *integreatlyv1alpha1.RHMIConfig {
+	return &integreatlyv1alpha1.RHMIConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "rhmi-config", Namespace: "redhat-rhoam-operator"},
+		Spec: integreatlyv1alpha1.RHMIConfigSpec{
+			Upgrade: integreatlyv1alpha1.Upgrade{Contacts: "user1@example.com, https://hooks.example.com/upgrade"},
+		},
+		Status: integreatlyv1alpha1.RHMIConfigStatus{UpgradeNotifications: notifications},
+	}
+}
+
+// getNotifier returns a notifier of channel that delivers to the contacts
+// that aren't failing
+func getNotifier(channel string, contacts []string, failing string, sent *[]string) *NotifierMock {
+	return &NotifierMock{
+		ChannelFunc: func() string {
+			return channel
+		},
+		NotifyFunc: func(ctx context.Context, notification Notification) ([]string, error) {
+			delivered := []string{}
+			for _, contact := range notification.undelivered(contacts) {
+				if contact == failing {
+					continue
+				}
+				*sent = append(*sent, fmt.Sprintf("%s/%s/%s", notification.Stage, channel, contact))
+				delivered = append(delivered, contact)
+			}
+			if failing != "" {
+				return delivered, fmt.Errorf("failed to deliver to %s", failing)
+			}
+			if len(contacts) == 0 {
+				*sent = append(*sent, fmt.Sprintf("%s/%s", notification.Stage, channel))
+			}
+			return delivered, nil
+		},
+		ClearFunc: func(ctx context.Context, installation *integreatlyv1alpha1.RHMI) error {
+			return nil
+		},
+	}
+}
+
+func TestDueStage(t *testing.T) {
+	now := time.Date(2021, 5, 10, 12, 0, 0, 0, time.UTC)
+	inTwoDays := now.Add(48 * time.Hour)
+	inTwelveHours := now.Add(12 * time.Hour)
+	twoHoursAgo := now.Add(-2 * time.Hour)
+
+	tests := []struct {
+		Name          string
+		Upgrade       Upgrade
+		ExpectedStage Stage
+	}{
+		{
+			Name:          "test upgrade not scheduled",
+			Upgrade:       Upgrade{Version: "1.2.0"},
+			ExpectedStage: StageAvailable,
+		},
+		{
+			Name:          "test upgrade scheduled in more than 24 hours",
+			Upgrade:       Upgrade{Version: "1.2.0", ScheduledFor: &inTwoDays},
+			ExpectedStage: StageScheduled,
+		},
+		{
+			Name:          "test upgrade scheduled within 24 hours",
+			Upgrade:       Upgrade{Version: "1.2.0", ScheduledFor: &inTwelveHours},
+			ExpectedStage: StageReminder,
+		},
+		{
+			Name:          "test upgrade window passed",
+			Upgrade:       Upgrade{Version: "1.2.0", ScheduledFor: &twoHoursAgo},
+			ExpectedStage: StageScheduled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			if stage := DueStage(tt.Upgrade, now); stage != tt.ExpectedStage {
+				t.Errorf("expected stage %s, got %s", tt.ExpectedStage, stage)
+			}
+		})
+	}
+}
+
+func TestDispatcher_Notify(t *testing.T) {
+	scheme, err := getBuildScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2021, 5, 10, 12, 0, 0, 0, time.UTC)
+	inTwelveHours := now.Add(12 * time.Hour)
+	webhooks := []string{"https://a.example.com", "https://b.example.com"}
+
+	tests := []struct {
+		Name                  string
+		RHMIConfig            *integreatlyv1alpha1.RHMIConfig
+		Upgrade               Upgrade
+		FailingContact        string
+		ExpectErr             bool
+		ExpectedSent          []string
+		ExpectedNotifications int
+	}{
+		{
+			Name:                  "test available upgrade is notified through every channel",
+			RHMIConfig:            getRHMIConfig(),
+			Upgrade:               Upgrade{Version: "1.2.0"},
+			ExpectedSent:          []string{"Available/Event", "Available/Webhook/https://a.example.com", "Available/Webhook/https://b.example.com"},
+			ExpectedNotifications: 4,
+		},
+		{
+			Name: "test only the latest due stage is notified",
+			RHMIConfig: getRHMIConfig(
+				integreatlyv1alpha1.UpgradeNotification{Version: "1.2.0", Stage: "Available", Channel: "Event"},
+				integreatlyv1alpha1.UpgradeNotification{Version: "1.2.0", Stage: "Available", Channel: "Webhook"},
+			),
+			Upgrade:               Upgrade{Version: "1.2.0", ScheduledFor: &inTwelveHours},
+			ExpectedSent:          []string{"Reminder/Event", "Reminder/Webhook/https://a.example.com", "Reminder/Webhook/https://b.example.com"},
+			ExpectedNotifications: 6,
+		},
+		{
+			Name: "test sent notifications are not sent again",
+			RHMIConfig: getRHMIConfig(
+				integreatlyv1alpha1.UpgradeNotification{Version: "1.2.0", Stage: "Available", Channel: "Event"},
+				integreatlyv1alpha1.UpgradeNotification{Version: "1.2.0", Stage: "Available", Channel: "Webhook"},
+			),
+			Upgrade:               Upgrade{Version: "1.2.0"},
+			ExpectedSent:          []string{},
+			ExpectedNotifications: 2,
+		},
+		{
+			Name: "test notifications of a previous upgrade are forgotten",
+			RHMIConfig: getRHMIConfig(
+				integreatlyv1alpha1.UpgradeNotification{Version: "1.1.0", Stage: "Available", Channel: "Event"},
+			),
+			Upgrade:               Upgrade{Version: "1.2.0"},
+			ExpectedSent:          []string{"Available/Event", "Available/Webhook/https://a.example.com", "Available/Webhook/https://b.example.com"},
+			ExpectedNotifications: 4,
+		},
+		{
+			Name:                  "test the contacts delivered to are recorded when a contact fails",
+			RHMIConfig:            getRHMIConfig(),
+			Upgrade:               Upgrade{Version: "1.2.0"},
+			FailingContact:        "https://b.example.com",
+			ExpectErr:             true,
+			ExpectedSent:          []string{"Available/Event", "Available/Webhook/https://a.example.com"},
+			ExpectedNotifications: 2,
+		},
+		{
+			Name: "test the contacts delivered to are not notified again",
+			RHMIConfig: getRHMIConfig(
+				integreatlyv1alpha1.UpgradeNotification{Version: "1.2.0", Stage: "Available", Channel: "Event"},
+				integreatlyv1alpha1.UpgradeNotification{Version: "1.2.0", Stage: "Available", Channel: "Webhook", Contact: "https://a.example.com"},
+			),
+			Upgrade:               Upgrade{Version: "1.2.0"},
+			ExpectedSent:          []string{"Available/Webhook/https://b.example.com"},
+			ExpectedNotifications: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			client := fakeclient.NewFakeClientWithScheme(scheme, tt.RHMIConfig)
+			sent := []string{}
+			dispatcher := NewDispatcher(client,
+				getNotifier("Event", nil, "", &sent),
+				getNotifier("Webhook", webhooks, tt.FailingContact, &sent),
+			)
+			dispatcher.now = func() time.Time { return now }
+
+			err := dispatcher.Notify(context.TODO(), getInstallation(), tt.RHMIConfig, tt.Upgrade)
+			if tt.ExpectErr != (err != nil) {
+				t.Fatalf("expected error %v, got %v", tt.ExpectErr, err)
+			}
+			if fmt.Sprint(sent) != fmt.Sprint(tt.ExpectedSent) {
+				t.Errorf("expected notifications %v to be sent, got %v", tt.ExpectedSent, sent)
+			}
+
+			config := &integreatlyv1alpha1.RHMIConfig{}
+			if err := client.Get(context.TODO(), k8sclient.ObjectKey{Name: "rhmi-config", Namespace: "redhat-rhoam-operator"}, config); err != nil {
+				t.Fatal(err)
+			}
+			if len(config.Status.UpgradeNotifications) != tt.ExpectedNotifications {
+				t.Errorf("expected %d notifications recorded, got %v", tt.ExpectedNotifications, config.Status.UpgradeNotifications)
+			}
+			for _, notification := range config.Status.UpgradeNotifications {
+				if notification.Version != tt.Upgrade.Version {
+					t.Errorf("expected only notifications of version %s, got %v", tt.Upgrade.Version, notification)
+				}
+			}
+		})
+	}
+}
+
+func TestDispatcher_Clear(t *testing.T) {
+	scheme, err := getBuildScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rhmiConfig := getRHMIConfig(integreatlyv1alpha1.UpgradeNotification{Version: "1.2.0", Stage: "Available", Channel: "Email"})
+	client := fakeclient.NewFakeClientWithScheme(scheme, rhmiConfig)
+	sent := []string{}
+	notifier := getNotifier("Email", nil, "", &sent)
+
+	if err := NewDispatcher(client, notifier).Clear(context.TODO(), getInstallation(), rhmiConfig); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(notifier.ClearCalls()) != 1 {
+		t.Errorf("expected the notifications of the channel to be cleared")
+	}
+
+	config := &integreatlyv1alpha1.RHMIConfig{}
+	if err := client.Get(context.TODO(), k8sclient.ObjectKey{Name: "rhmi-config", Namespace: "redhat-rhoam-operator"}, config); err != nil {
+		t.Fatal(err)
+	}
+	if len(config.Status.UpgradeNotifications) != 0 {
+		t.Errorf("expected the recorded notifications to be cleared, got %v", config.Status.UpgradeNotifications)
+	}
+}
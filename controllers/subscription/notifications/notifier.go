@@ -0,0 +1,145 @@
+// Package notifications notifies the customer of an upgrade of the operator
+// when it becomes available, when it is scheduled and 24 hours before it is
+// applied. The notifications are sent through channels: emails to the email
+// contacts of the RHMIConfig upgrade, JSON posts to its webhook contacts,
+// Kubernetes events on the RHMIConfig and a banner in the OpenShift console
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/resources"
+)
+
+// Stage of an upgrade a notification is sent for
+type Stage string
+
+const (
+	StageAvailable Stage = "Available"
+	StageScheduled Stage = "Scheduled"
+	StageReminder  Stage = "Reminder"
+
+	// ReminderBefore is how long before the scheduled upgrade the reminder is
+	// sent
+	ReminderBefore = 24 * time.Hour
+)
+
+// Upgrade is an available upgrade of the operator
+type Upgrade struct {
+	Version          string
+	ServiceAffecting bool
+	// ScheduledFor is the time the upgrade is applied at, nil until the
+	// upgrade is scheduled
+	ScheduledFor *time.Time
+}
+
+// DueStage returns the latest stage the upgrade reached at now. The earlier
+// stages aren't notified once a later one is due, an upgrade first seen
+// within 24 hours of its schedule is only notified by the reminder
+func DueStage(upgrade Upgrade, now time.Time) Stage {
+	if upgrade.ScheduledFor == nil {
+		return StageAvailable
+	}
+	if now.Before(*upgrade.ScheduledFor) && !now.Before(upgrade.ScheduledFor.Add(-ReminderBefore)) {
+		return StageReminder
+	}
+	return StageScheduled
+}
+
+// Notification of a stage of an upgrade
+type Notification struct {
+	Stage   Stage
+	Upgrade Upgrade
+
+	Installation *integreatlyv1alpha1.RHMI
+	Config       *integreatlyv1alpha1.RHMIConfig
+
+	// Delivered are the contacts the notification was already delivered
+	// to, that aren't notified again
+	Delivered map[string]bool
+}
+
+// Product returns the name of the product the notification is about
+func (n Notification) Product() string {
+	return strings.ToUpper(resources.InstallationNames[n.Installation.Spec.Type])
+}
+
+// Subject returns the one line summary of the notification
+func (n Notification) Subject() string {
+	switch n.Stage {
+	case StageScheduled:
+		return fmt.Sprintf("%s upgrade to version %s is scheduled", n.Product(), n.Upgrade.Version)
+	case StageReminder:
+		return fmt.Sprintf("%s upgrade to version %s starts within 24 hours", n.Product(), n.Upgrade.Version)
+	default:
+		return fmt.Sprintf("%s upgrade to version %s is available", n.Product(), n.Upgrade.Version)
+	}
+}
+
+// Message returns the text of the notification
+func (n Notification) Message() string {
+	switch n.Stage {
+	case StageScheduled, StageReminder:
+		return fmt.Sprintf("The upgrade of %s to version %s is scheduled for %s UTC.",
+			n.Product(), n.Upgrade.Version, n.Upgrade.ScheduledFor.UTC().Format(integreatlyv1alpha1.DateFormat))
+	}
+	if n.Upgrade.ServiceAffecting {
+		return fmt.Sprintf("Version %s of %s is available. The upgrade is service affecting and is applied in the upgrade window of the %s RHMIConfig.",
+			n.Upgrade.Version, n.Product(), n.Config.Name)
+	}
+	return fmt.Sprintf("Version %s of %s is available. The upgrade is not service affecting and is applied immediately.",
+		n.Upgrade.Version, n.Product())
+}
+
+//go:generate moq -out notifier_moq.go . Notifier
+
+// Notifier sends notifications through a channel
+type Notifier interface {
+	// Channel returns the name of the channel, recorded in the RHMIConfig
+	// status for the notifications sent through it
+	Channel() string
+	// Notify sends the notification to the contacts of the channel it
+	// wasn't delivered to. It returns the contacts it's delivered to, also
+	// when it fails for others. Channels without contacts return none
+	Notify(ctx context.Context, notification Notification) ([]string, error)
+	// Clear removes the notifications of the channel that stay visible after
+	// the upgrade
+	Clear(ctx context.Context, installation *integreatlyv1alpha1.RHMI) error
+}
+
+// undelivered returns the contacts the notification wasn't delivered to
+func (n Notification) undelivered(contacts []string) []string {
+	remaining := []string{}
+	for _, contact := range contacts {
+		if !n.Delivered[contact] {
+			remaining = append(remaining, contact)
+		}
+	}
+	return remaining
+}
+
+// Contacts returns the email addresses and the webhook URLs of the comma
+// separated contacts of the RHMIConfig upgrade. Webhooks must use https,
+// other contacts are ignored
+func Contacts(config *integreatlyv1alpha1.RHMIConfig) (emails []string, webhooks []string) {
+	for _, contact := range strings.Split(config.Spec.Upgrade.Contacts, ",") {
+		contact = strings.TrimSpace(contact)
+		if contact == "" {
+			continue
+		}
+		if webhook, err := url.Parse(contact); err == nil && webhook.Scheme == "https" && webhook.Host != "" {
+			webhooks = append(webhooks, contact)
+			continue
+		}
+		if address, err := mail.ParseAddress(contact); err == nil {
+			emails = append(emails, address.Address)
+		}
+	}
+	return emails, webhooks
+}
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"time"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/integr8ly/integreatly-operator/pkg/resources"
 
 	"github.com/integr8ly/integreatly-operator/controllers/subscription/csvlocator"
+	"github.com/integr8ly/integreatly-operator/controllers/subscription/notifications"
 	"github.com/integr8ly/integreatly-operator/controllers/subscription/rhmiConfigs"
 	"github.com/integr8ly/integreatly-operator/controllers/subscription/webapp"
 
@@ -19,6 +21,7 @@ import (
 
 	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
 	catalogsourceClient "github.com/integr8ly/integreatly-operator/pkg/resources/catalogsource"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/secretprovider"
 
 	operatorsv1alpha1 "github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators/v1alpha1"
 
@@ -83,6 +86,17 @@ func New(mgr manager.Manager) (*SubscriptionReconciler, error) {
 		})
 	})
 
+	secretProvider, err := secretprovider.New(client, operatorNs)
+	if err != nil {
+		return nil, err
+	}
+	upgradeNotifications := notifications.NewDispatcher(client,
+		notifications.NewEmailNotifier(secretProvider, nil),
+		notifications.NewEventNotifier(mgr.GetEventRecorderFor("RHMI Upgrade")),
+		notifications.NewConsoleNotifier(client),
+		notifications.NewWebhookNotifier(nil),
+	)
+
 	csvLocator := csvlocator.NewCachedCSVLocator(csvlocator.NewConditionalCSVLocator(
 		csvlocator.SwitchLocators(
 			csvlocator.ForReference,
@@ -91,13 +105,14 @@ func New(mgr manager.Manager) (*SubscriptionReconciler, error) {
 	))
 
 	return &SubscriptionReconciler{
-		mgr:                 mgr,
-		Client:              client,
-		Scheme:              mgr.GetScheme(),
-		operatorNamespace:   operatorNs,
-		catalogSourceClient: catalogSourceClient,
-		webbappNotifier:     webappNotifierClient,
-		csvLocator:          csvLocator,
+		mgr:                  mgr,
+		Client:               client,
+		Scheme:               mgr.GetScheme(),
+		operatorNamespace:    operatorNs,
+		catalogSourceClient:  catalogSourceClient,
+		webbappNotifier:      webappNotifierClient,
+		upgradeNotifications: upgradeNotifications,
+		csvLocator:           csvLocator,
 	}, nil
 }
 
@@ -109,7 +124,9 @@ type SubscriptionReconciler struct {
 	mgr                 manager.Manager
 	catalogSourceClient catalogsourceClient.CatalogSourceClientInterface
 	webbappNotifier     webapp.UpgradeNotifier
-	csvLocator          csvlocator.CSVLocator
+	// upgradeNotifications notifies the upgrade contacts of the RHMIConfig
+	upgradeNotifications *notifications.Dispatcher
+	csvLocator           csvlocator.CSVLocator
 }
 
 // +kubebuilder:rbac:groups=operators.coreos.com,resources=subscriptions;subscriptions/status,verbs=get;list;watch;update;patch;delete,namespace=integreatly-operator
@@ -121,7 +138,8 @@ type SubscriptionReconciler struct {
 // Reconcile will ensure that that Subscription object(s) have Manual approval for the upgrades
 // In a namespaced installation of integreatly operator it will only reconcile Subscription of the integreatly operator itself
 func (r *SubscriptionReconciler) Reconcile(request ctrl.Request) (ctrl.Result, error) {
-	_ = context.Background()
+	ctx := l.ContextWithFields(context.Background(), l.Fields{l.ReconcileIDLogContext: l.NewReconcileID()})
+	log := log.ForContext(ctx)
 
 	// skip any Subscriptions that are not integreatly operator
 	if !r.shouldReconcileSubscription(request) {
@@ -130,7 +148,7 @@ func (r *SubscriptionReconciler) Reconcile(request ctrl.Request) (ctrl.Result, e
 	}
 
 	subscription := &operatorsv1alpha1.Subscription{}
-	err := r.Get(context.TODO(), request.NamespacedName, subscription)
+	err := r.Get(ctx, request.NamespacedName, subscription)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			// Request object not found, could have been deleted after reconcile request. Return and don't requeue
@@ -141,13 +159,13 @@ func (r *SubscriptionReconciler) Reconcile(request ctrl.Request) (ctrl.Result, e
 
 	if subscription.Spec.InstallPlanApproval != operatorsv1alpha1.ApprovalManual {
 		subscription.Spec.InstallPlanApproval = operatorsv1alpha1.ApprovalManual
-		err = r.Update(context.TODO(), subscription)
+		err = r.Update(ctx, subscription)
 		if err != nil {
 			return ctrl.Result{}, err
 		}
 	}
 
-	rhmiCr, err := resources.GetRhmiCr(r.Client, context.TODO(), request.NamespacedName.Namespace, log)
+	rhmiCr, err := resources.GetRhmiCr(r.Client, ctx, request.NamespacedName.Namespace, log)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
@@ -156,7 +174,7 @@ func (r *SubscriptionReconciler) Reconcile(request ctrl.Request) (ctrl.Result, e
 		return ctrl.Result{}, nil
 	}
 
-	return r.HandleUpgrades(context.TODO(), subscription, rhmiCr)
+	return r.HandleUpgrades(ctx, subscription, rhmiCr)
 }
 
 func (r *SubscriptionReconciler) shouldReconcileSubscription(request ctrl.Request) bool {
@@ -182,6 +200,13 @@ func (r *SubscriptionReconciler) HandleUpgrades(ctx context.Context, rhmiSubscri
 		if err := r.webbappNotifier.ClearNotification(namespacePrefix); err != nil {
 			return ctrl.Result{}, err
 		}
+		rhmiConfig, err := r.getRHMIConfig(ctx)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.upgradeNotifications.Clear(ctx, installation, rhmiConfig); err != nil {
+			return ctrl.Result{}, err
+		}
 
 		return ctrl.Result{}, nil
 	}
@@ -215,6 +240,12 @@ func (r *SubscriptionReconciler) HandleUpgrades(ctx context.Context, rhmiSubscri
 
 	isServiceAffecting := rhmiConfigs.IsUpgradeServiceAffecting(latestRHMICSV)
 
+	// a failed notification is retried on the next reconcile, it doesn't hold
+	// back the upgrade
+	if err := r.notifyUpgrade(ctx, installation, latestRHMICSV.Spec.Version.String(), isServiceAffecting); err != nil {
+		log.Error("Failed to send upgrade notifications", err)
+	}
+
 	if !isServiceAffecting && !latestRHMIInstallPlan.Spec.Approved {
 		eventRecorder := r.mgr.GetEventRecorderFor("RHMI Upgrade")
 		err = rhmiConfigs.ApproveUpgrade(ctx, r.Client, installation, latestRHMIInstallPlan, eventRecorder)
@@ -236,6 +267,46 @@ func (r *SubscriptionReconciler) HandleUpgrades(ctx context.Context, rhmiSubscri
 	}, nil
 }
 
+// notifyUpgrade sends the notifications of the stages the upgrade reached,
+// scheduled at the time calculated in the RHMIConfig status
+func (r *SubscriptionReconciler) notifyUpgrade(ctx context.Context, installation *integreatlyv1alpha1.RHMI, version string, isServiceAffecting bool) error {
+	rhmiConfig, err := r.getRHMIConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if rhmiConfig == nil {
+		log.Info("No rhmi config to notify the upgrade contacts of")
+		return nil
+	}
+
+	upgrade := notifications.Upgrade{
+		Version:          version,
+		ServiceAffecting: isServiceAffecting,
+	}
+	if scheduled := rhmiConfig.Status.Upgrade.Scheduled; scheduled != nil && scheduled.For != "" {
+		scheduledFor, err := time.Parse(integreatlyv1alpha1.DateFormat, scheduled.For)
+		if err != nil {
+			return fmt.Errorf("invalid scheduled upgrade time %q: %w", scheduled.For, err)
+		}
+		upgrade.ScheduledFor = &scheduledFor
+	}
+
+	return r.upgradeNotifications.Notify(ctx, installation, rhmiConfig, upgrade)
+}
+
+// getRHMIConfig returns the RHMIConfig of the installation, or nil if it
+// doesn't exist
+func (r *SubscriptionReconciler) getRHMIConfig(ctx context.Context) (*integreatlyv1alpha1.RHMIConfig, error) {
+	rhmiConfig := &integreatlyv1alpha1.RHMIConfig{}
+	if err := r.Client.Get(ctx, k8sclient.ObjectKey{Name: "rhmi-config", Namespace: r.operatorNamespace}, rhmiConfig); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get rhmi config: %w", err)
+	}
+	return rhmiConfig, nil
+}
+
 func (r *SubscriptionReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&operatorsv1alpha1.Subscription{}).
@@ -10,6 +10,7 @@ import (
 
 	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
 	"github.com/integr8ly/integreatly-operator/controllers/subscription/csvlocator"
+	"github.com/integr8ly/integreatly-operator/controllers/subscription/notifications"
 	"github.com/integr8ly/integreatly-operator/controllers/subscription/webapp"
 
 	catalogsourceClient "github.com/integr8ly/integreatly-operator/pkg/resources/catalogsource"
@@ -261,12 +262,13 @@ func TestSubscriptionReconciler(t *testing.T) {
 			APIObject := scenario.APISubscription
 			client := fakeclient.NewFakeClientWithScheme(scheme, APIObject, installPlan, rhmiConfig, rhmiCR)
 			reconciler := SubscriptionReconciler{
-				Client:              client,
-				Scheme:              scheme,
-				catalogSourceClient: scenario.catalogsourceClient,
-				operatorNamespace:   operatorNamespace,
-				webbappNotifier:     &webapp.NoOp{},
-				csvLocator:          &csvlocator.EmbeddedCSVLocator{},
+				Client:               client,
+				Scheme:               scheme,
+				catalogSourceClient:  scenario.catalogsourceClient,
+				operatorNamespace:    operatorNamespace,
+				webbappNotifier:      &webapp.NoOp{},
+				upgradeNotifications: notifications.NewDispatcher(client),
+				csvLocator:           &csvlocator.EmbeddedCSVLocator{},
 			}
 			res, err := reconciler.Reconcile(scenario.Request)
 			scenario.Verify(client, res, err, t)
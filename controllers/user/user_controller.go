@@ -2,87 +2,402 @@ package controllers
 
 import (
 	"context"
+	"sync"
 	"time"
 
-	rhmiv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/metrics"
+	"github.com/integr8ly/integreatly-operator/pkg/resources"
 	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/usersync"
 
 	userHelper "github.com/integr8ly/integreatly-operator/pkg/resources/user"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	usersv1 "github.com/openshift/api/user/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime"
-	controllerruntime "sigs.k8s.io/controller-runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	rhmiDevelopersGroupName = "rhmi-developers"
+	userSyncConsumer        = "user_controller"
+
+	// stateFlushInterval is the maximum time the in memory sync state is kept
+	// without being persisted while there are users waiting to be synced
+	stateFlushInterval = 30 * time.Second
 )
 
 var log = l.NewLoggerWithContext(l.Fields{l.ControllerLogContext: "user_controller"})
 
-// UserReconciler reconciles a User object
+// UserReconciler syncs OpenShift users one at a time, as their User, Group
+// membership or Identities change. Every user is added to the rhmi-developers
+// group unless it belongs to an exclusion group, and the hash of the synced
+// user is recorded in the persisted sync state
 type UserReconciler struct {
+	// client reads the users, groups and identities from the manager cache
+	client k8sclient.Client
+	// writer is an uncached client used for the rhmi-developers group and the
+	// sync state, as they're also updated by other reconcilers
+	writer    k8sclient.Client
+	namespace string
+
+	pending *pendingUsers
+
+	stateLock   sync.Mutex
+	state       *usersync.State
+	stateDirty  bool
+	lastFlushed time.Time
+}
+
+func New(mgr ctrl.Manager) (*UserReconciler, error) {
+	writer, err := k8sclient.New(mgr.GetConfig(), k8sclient.Options{Scheme: mgr.GetScheme()})
+	if err != nil {
+		return nil, err
+	}
+
+	namespace, err := resources.GetWatchNamespace()
+	if err != nil {
+		return nil, err
+	}
+
+	return &UserReconciler{
+		client:    mgr.GetClient(),
+		writer:    writer,
+		namespace: namespace,
+		pending:   newPendingUsers(),
+	}, nil
 }
 
 // +kubebuilder:rbac:groups=user.openshift.io,resources=groups,verbs=get;list;watch;create
 // +kubebuilder:rbac:groups=user.openshift.io,resources=groups,resourceNames=rhmi-developers,verbs=update;delete
 // +kubebuilder:rbac:groups=user.openshift.io,resources=users,verbs=watch;get;list
+// +kubebuilder:rbac:groups=user.openshift.io,resources=identities,verbs=watch;get;list
 
 func (r *UserReconciler) Reconcile(request ctrl.Request) (ctrl.Result, error) {
-	_ = context.Background()
-	log.Info("Reconciling User")
+	ctx := context.TODO()
+	userName := request.Name
 
-	// new client to avoid caching issues
-	restConfig := controllerruntime.GetConfigOrDie()
-	restConfig.Timeout = time.Second * 10
-	scheme := runtime.NewScheme()
-	err := rhmiv1alpha1.AddToSchemes.AddToScheme(scheme)
+	changedAt, isPending := r.pending.take(userName)
+	defer func() {
+		metrics.SetUserSyncPending(r.pending.len())
+	}()
 
-	c, _ := k8sclient.New(restConfig, k8sclient.Options{Scheme: scheme})
-	ctx := context.TODO()
+	if err := r.syncUser(ctx, userName); err != nil {
+		log.Errorf("Failed to sync user", l.Fields{"user": userName}, err)
+		metrics.IncUserSyncFailures(userSyncConsumer)
+		if isPending {
+			r.pending.addAt(userName, changedAt)
+		}
+		return ctrl.Result{}, err
+	}
 
-	rhmiGroup := &usersv1.Group{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: "rhmi-developers",
-		},
+	if isPending {
+		metrics.ObserveUserSyncLag(time.Since(changedAt))
 	}
 
-	or, err := controllerutil.CreateOrUpdate(ctx, c, rhmiGroup, func() error {
-		users := &usersv1.UserList{}
-		err := c.List(ctx, users)
-		if err != nil {
+	if err := r.flushState(ctx); err != nil {
+		log.Error("Failed to persist user sync state", err)
+		metrics.IncUserSyncFailures(userSyncConsumer)
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// syncUser updates the rhmi-developers group membership of the user and
+// records its hash in the in memory sync state
+func (r *UserReconciler) syncUser(ctx context.Context, userName string) error {
+	state, err := r.getState(ctx)
+	if err != nil {
+		return err
+	}
+
+	user := &usersv1.User{}
+	err = r.client.Get(ctx, k8sclient.ObjectKey{Name: userName}, user)
+	if k8serr.IsNotFound(err) {
+		if err := r.setDeveloperMembership(ctx, userName, false); err != nil {
 			return err
 		}
+		r.updateState(func() bool { return state.RemoveUser(userName, time.Now()) })
+		return nil
+	}
+	if err != nil {
+		return err
+	}
 
-		groups := &usersv1.GroupList{}
-		err = c.List(ctx, groups)
+	groups := &usersv1.GroupList{}
+	if err := r.client.List(ctx, groups); err != nil {
+		return err
+	}
+
+	identities := []usersv1.Identity{}
+	for _, identityName := range user.Identities {
+		identity := &usersv1.Identity{}
+		err := r.client.Get(ctx, k8sclient.ObjectKey{Name: identityName}, identity)
+		if k8serr.IsNotFound(err) {
+			continue
+		}
 		if err != nil {
 			return err
 		}
+		identities = append(identities, *identity)
+	}
 
-		rhmiGroup.Users = mapUserNames(users, groups)
+	// Certain users such as sre do not need to be added
+	isDeveloper := !userHelper.UserInExclusionGroup(*user, groups)
+	if err := r.setDeveloperMembership(ctx, userName, isDeveloper); err != nil {
+		return err
+	}
+
+	hash := usersync.UserHash(user, identities, userGroupNames(userName, groups))
+	r.updateState(func() bool { return state.SetUser(userName, hash, time.Now()) })
 
+	return nil
+}
+
+// setDeveloperMembership adds or removes a single user from the
+// rhmi-developers group, creating the group if it doesn't exist
+func (r *UserReconciler) setDeveloperMembership(ctx context.Context, userName string, isMember bool) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		rhmiGroup := &usersv1.Group{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: rhmiDevelopersGroupName,
+			},
+		}
+
+		or, err := controllerutil.CreateOrUpdate(ctx, r.writer, rhmiGroup, func() error {
+			users := usersv1.OptionalNames{}
+			for _, groupUser := range rhmiGroup.Users {
+				if groupUser != userName {
+					users = append(users, groupUser)
+				}
+			}
+			if isMember {
+				users = append(users, userName)
+			}
+			rhmiGroup.Users = users
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if or != controllerutil.OperationResultNone {
+			log.Infof("Operation Result", l.Fields{"groupName": rhmiGroup.Name, "user": userName, "result": string(or)})
+		}
 		return nil
 	})
-	log.Infof("Operation Result", l.Fields{"groupName": rhmiGroup.Name, "result": string(or)})
+}
 
-	return ctrl.Result{}, err
+func (r *UserReconciler) getState(ctx context.Context) (*usersync.State, error) {
+	r.stateLock.Lock()
+	defer r.stateLock.Unlock()
+
+	if r.state == nil {
+		state, err := usersync.GetState(ctx, r.writer, r.namespace)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.pruneDeletedUsers(ctx, state); err != nil {
+			return nil, err
+		}
+		r.state = state
+		r.lastFlushed = time.Now()
+	}
+	return r.state, nil
 }
 
-func mapUserNames(users *usersv1.UserList, groups *usersv1.GroupList) []string {
-	var result = []string{}
+// pruneDeletedUsers removes the users deleted while the operator wasn't
+// running, as no event is received for them
+func (r *UserReconciler) pruneDeletedUsers(ctx context.Context, state *usersync.State) error {
+	users := &usersv1.UserList{}
+	if err := r.client.List(ctx, users); err != nil {
+		return err
+	}
+	existing := map[string]bool{}
 	for _, user := range users.Items {
-		// Certain users such as sre do not need to be added
-		if !userHelper.UserInExclusionGroup(user, groups) {
-			result = append(result, user.Name)
+		existing[user.Name] = true
+	}
+
+	rhmiGroup := &usersv1.Group{}
+	err := r.writer.Get(ctx, k8sclient.ObjectKey{Name: rhmiDevelopersGroupName}, rhmiGroup)
+	if err != nil && !k8serr.IsNotFound(err) {
+		return err
+	}
+	for _, userName := range rhmiGroup.Users {
+		if !existing[userName] {
+			if err := r.setDeveloperMembership(ctx, userName, false); err != nil {
+				return err
+			}
+		}
+	}
+
+	for userName := range state.Users {
+		if !existing[userName] && state.RemoveUser(userName, time.Now()) {
+			r.stateDirty = true
 		}
 	}
+	return nil
+}
+
+func (r *UserReconciler) updateState(update func() bool) {
+	r.stateLock.Lock()
+	defer r.stateLock.Unlock()
+
+	if update() {
+		r.stateDirty = true
+	}
+}
+
+// flushState persists the users in the in memory sync state. To avoid an
+// update of the state for every user when many users change at once, the
+// state is only persisted once no user is waiting to be synced, or after
+// stateFlushInterval
+func (r *UserReconciler) flushState(ctx context.Context) error {
+	r.stateLock.Lock()
+	defer r.stateLock.Unlock()
+
+	if !r.stateDirty {
+		return nil
+	}
+	if r.pending.len() > 0 && time.Since(r.lastFlushed) < stateFlushInterval {
+		return nil
+	}
+
+	// The consumers are owned by the product reconcilers, only the shards
+	// of the changed users and the revision are updated
+	if err := usersync.SaveUsers(ctx, r.writer, r.namespace, r.state); err != nil {
+		return err
+	}
 
-	return result
+	r.stateDirty = false
+	r.lastFlushed = time.Now()
+	return nil
+}
+
+func userGroupNames(userName string, groups *usersv1.GroupList) []string {
+	names := []string{}
+	for _, group := range groups.Items {
+		if group.Name == rhmiDevelopersGroupName {
+			continue
+		}
+		for _, groupUser := range group.Users {
+			if groupUser == userName {
+				names = append(names, group.Name)
+				break
+			}
+		}
+	}
+	return names
 }
 
 func (r *UserReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&usersv1.User{}).
+		For(&usersv1.User{}, builder.WithPredicates(r.trackUserEvents())).
+		Watches(&source.Kind{Type: &usersv1.Group{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.mapGroupToUsers),
+		}).
+		Watches(&source.Kind{Type: &usersv1.Identity{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.mapIdentityToUser),
+		}).
 		Complete(r)
 }
+
+// trackUserEvents records the time of the User events, to measure the sync lag
+func (r *UserReconciler) trackUserEvents() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			r.pending.add(e.Meta.GetName())
+			return true
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			r.pending.add(e.MetaNew.GetName())
+			return true
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			r.pending.add(e.Meta.GetName())
+			return true
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			r.pending.add(e.Meta.GetName())
+			return true
+		},
+	}
+}
+
+// mapGroupToUsers enqueues the members of a group. Changes to the
+// rhmi-developers group are ignored as it's managed by this controller
+func (r *UserReconciler) mapGroupToUsers(mo handler.MapObject) []reconcile.Request {
+	group, ok := mo.Object.(*usersv1.Group)
+	if !ok || group.Name == rhmiDevelopersGroupName {
+		return []reconcile.Request{}
+	}
+
+	requests := []reconcile.Request{}
+	for _, userName := range group.Users {
+		r.pending.add(userName)
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: userName}})
+	}
+	return requests
+}
+
+func (r *UserReconciler) mapIdentityToUser(mo handler.MapObject) []reconcile.Request {
+	identity, ok := mo.Object.(*usersv1.Identity)
+	if !ok || identity.User.Name == "" {
+		return []reconcile.Request{}
+	}
+
+	r.pending.add(identity.User.Name)
+	return []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: identity.User.Name}},
+	}
+}
+
+// pendingUsers keeps the time of the first unsynced change of every user
+type pendingUsers struct {
+	lock  sync.Mutex
+	since map[string]time.Time
+}
+
+func newPendingUsers() *pendingUsers {
+	return &pendingUsers{since: map[string]time.Time{}}
+}
+
+func (p *pendingUsers) add(userName string) {
+	p.addAt(userName, time.Now())
+}
+
+func (p *pendingUsers) addAt(userName string, changedAt time.Time) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if existing, ok := p.since[userName]; ok && existing.Before(changedAt) {
+		return
+	}
+	p.since[userName] = changedAt
+}
+
+func (p *pendingUsers) take(userName string) (time.Time, bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	changedAt, ok := p.since[userName]
+	delete(p.since, userName)
+	return changedAt, ok
+}
+
+func (p *pendingUsers) len() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return len(p.since)
+}
@@ -0,0 +1,201 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/integr8ly/integreatly-operator/pkg/resources/usersync"
+	usersv1 "github.com/openshift/api/user/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+const testNamespace = "redhat-rhoam-operator"
+
+func getBuildScheme() (*runtime.Scheme, error) {
+	scheme := runtime.NewScheme()
+	if err := usersv1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	err := corev1.AddToScheme(scheme)
+	return scheme, err
+}
+
+func newTestReconciler(client k8sclient.Client) *UserReconciler {
+	return &UserReconciler{
+		client:    client,
+		writer:    client,
+		namespace: testNamespace,
+		pending:   newPendingUsers(),
+	}
+}
+
+func developers(client k8sclient.Client) ([]string, error) {
+	group := &usersv1.Group{}
+	if err := client.Get(context.TODO(), k8sclient.ObjectKey{Name: rhmiDevelopersGroupName}, group); err != nil {
+		return nil, err
+	}
+	return group.Users, nil
+}
+
+// withSyncedUsers persists the given users in the sync state
+func withSyncedUsers(t *testing.T, client k8sclient.Client, userNames ...string) k8sclient.Client {
+	state := usersync.NewState()
+	for _, userName := range userNames {
+		state.SetUser(userName, "hash", time.Now())
+	}
+	if err := usersync.SaveUsers(context.TODO(), client, testNamespace, state); err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestUserReconciler_Reconcile(t *testing.T) {
+	scheme, err := getBuildScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	developer := &usersv1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "developer"},
+		Identities: []string{"devsandbox:developer"},
+	}
+	identity := &usersv1.Identity{
+		ObjectMeta:       metav1.ObjectMeta{Name: "devsandbox:developer"},
+		ProviderName:     "devsandbox",
+		ProviderUserName: "developer",
+	}
+	sre := &usersv1.User{ObjectMeta: metav1.ObjectMeta{Name: "sre"}}
+	sreGroup := &usersv1.Group{
+		ObjectMeta: metav1.ObjectMeta{Name: "osd-sre-admins"},
+		Users:      []string{"sre"},
+	}
+
+	tests := []struct {
+		Name       string
+		FakeClient k8sclient.Client
+		UserName   string
+		Assertion  func(k8sclient.Client) error
+	}{
+		{
+			Name:       "test user is added to the developers group and its hash persisted",
+			FakeClient: fakeclient.NewFakeClientWithScheme(scheme, developer, identity),
+			UserName:   "developer",
+			Assertion: func(client k8sclient.Client) error {
+				users, err := developers(client)
+				if err != nil {
+					return err
+				}
+				if len(users) != 1 || users[0] != "developer" {
+					return fmt.Errorf("expected developer in the developers group, got %v", users)
+				}
+
+				state, err := usersync.GetState(context.TODO(), client, testNamespace)
+				if err != nil {
+					return err
+				}
+				if _, ok := state.Users["developer"]; !ok {
+					return fmt.Errorf("expected developer in the sync state, got %v", state.Users)
+				}
+				if state.Revision != 1 {
+					return fmt.Errorf("expected revision 1, got %d", state.Revision)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "test excluded user is removed from the developers group",
+			FakeClient: fakeclient.NewFakeClientWithScheme(scheme, sre, sreGroup, &usersv1.Group{
+				ObjectMeta: metav1.ObjectMeta{Name: rhmiDevelopersGroupName},
+				Users:      []string{"sre"},
+			}),
+			UserName: "sre",
+			Assertion: func(client k8sclient.Client) error {
+				users, err := developers(client)
+				if err != nil {
+					return err
+				}
+				if len(users) != 0 {
+					return fmt.Errorf("expected empty developers group, got %v", users)
+				}
+				return nil
+			},
+		},
+		{
+			Name: "test deleted user is removed from the developers group and the sync state",
+			FakeClient: withSyncedUsers(t, fakeclient.NewFakeClientWithScheme(scheme, developer, &usersv1.Group{
+				ObjectMeta: metav1.ObjectMeta{Name: rhmiDevelopersGroupName},
+				Users:      []string{"developer", "deleted"},
+			}), "deleted"),
+			UserName: "deleted",
+			Assertion: func(client k8sclient.Client) error {
+				users, err := developers(client)
+				if err != nil {
+					return err
+				}
+				if len(users) != 1 || users[0] != "developer" {
+					return fmt.Errorf("expected only developer in the developers group, got %v", users)
+				}
+
+				state, err := usersync.GetState(context.TODO(), client, testNamespace)
+				if err != nil {
+					return err
+				}
+				if !state.Users["deleted"].Removed {
+					return fmt.Errorf("expected deleted user to be removed from the sync state")
+				}
+				if state.Revision != 2 {
+					return fmt.Errorf("expected revision 2, got %d", state.Revision)
+				}
+				return nil
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			reconciler := newTestReconciler(tt.FakeClient)
+			reconciler.pending.add(tt.UserName)
+
+			if _, err := reconciler.Reconcile(ctrl.Request{NamespacedName: k8sclient.ObjectKey{Name: tt.UserName}}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if reconciler.pending.len() != 0 {
+				t.Fatalf("expected no pending users, got %d", reconciler.pending.len())
+			}
+			if err := tt.Assertion(tt.FakeClient); err != nil {
+				t.Fatalf("assertion failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestUserReconciler_mapGroupToUsers(t *testing.T) {
+	reconciler := newTestReconciler(nil)
+
+	requests := reconciler.mapGroupToUsers(handler.MapObject{Object: &usersv1.Group{
+		ObjectMeta: metav1.ObjectMeta{Name: "dedicated-admins"},
+		Users:      []string{"admin1", "admin2"},
+	}})
+	if len(requests) != 2 {
+		t.Fatalf("expected a request per group member, got %v", requests)
+	}
+	if reconciler.pending.len() != 2 {
+		t.Fatalf("expected 2 pending users, got %d", reconciler.pending.len())
+	}
+
+	requests = reconciler.mapGroupToUsers(handler.MapObject{Object: &usersv1.Group{
+		ObjectMeta: metav1.ObjectMeta{Name: rhmiDevelopersGroupName},
+		Users:      []string{"developer"},
+	}})
+	if len(requests) != 0 {
+		t.Fatalf("expected changes to the developers group to be ignored, got %v", requests)
+	}
+}
@@ -17,10 +17,13 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
 	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
 	"os"
 	"strings"
+	"time"
 
 	integreatlymetrics "github.com/integr8ly/integreatly-operator/pkg/metrics"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -28,6 +31,7 @@ import (
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	ctrl "sigs.k8s.io/controller-runtime"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	customMetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 
@@ -35,13 +39,18 @@ import (
 	namespacecontroller "github.com/integr8ly/integreatly-operator/controllers/namespacelabel"
 	rhmicontroller "github.com/integr8ly/integreatly-operator/controllers/rhmi"
 	rhmiconfigcontroller "github.com/integr8ly/integreatly-operator/controllers/rhmiconfig"
+	selfmanagedgatewaycontroller "github.com/integr8ly/integreatly-operator/controllers/selfmanagedgateway"
 	subscriptioncontroller "github.com/integr8ly/integreatly-operator/controllers/subscription"
 	tenantcontroller "github.com/integr8ly/integreatly-operator/controllers/tenant"
 	usercontroller "github.com/integr8ly/integreatly-operator/controllers/user"
 	"github.com/integr8ly/integreatly-operator/pkg/addon"
 	"github.com/integr8ly/integreatly-operator/pkg/resources"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/leader"
+	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/mustgather"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/tracing"
 	"github.com/integr8ly/integreatly-operator/pkg/webhooks"
-	"github.com/sirupsen/logrus"
+	"github.com/prometheus/client_golang/prometheus"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 	// +kubebuilder:scaffold:imports
 )
@@ -51,21 +60,9 @@ var (
 	setupLog = ctrl.Log.WithName("setup")
 )
 
-func init() {
-	// Register custom metrics with the global prometheus registry
-	customMetrics.Registry.MustRegister(integreatlymetrics.OperatorVersion)
-	customMetrics.Registry.MustRegister(integreatlymetrics.RHMIStatusAvailable)
-	customMetrics.Registry.MustRegister(integreatlymetrics.RHMIInfo)
-	customMetrics.Registry.MustRegister(integreatlymetrics.RHMIVersion)
-	customMetrics.Registry.MustRegister(integreatlymetrics.RHMIStatus)
-	customMetrics.Registry.MustRegister(integreatlymetrics.RHOAMVersion)
-	customMetrics.Registry.MustRegister(integreatlymetrics.RHOAMStatus)
-	customMetrics.Registry.MustRegister(integreatlymetrics.ThreeScaleUserAction)
-	customMetrics.Registry.MustRegister(integreatlymetrics.Quota)
-	customMetrics.Registry.MustRegister(integreatlymetrics.NumTenants)
-	customMetrics.Registry.MustRegister(integreatlymetrics.NoActivated3ScaleTenantAccount)
-	customMetrics.Registry.MustRegister(integreatlymetrics.NoTenantRealm)
+const leaderElectionID = "28185cee.integreatly.org"
 
+func init() {
 	integreatlymetrics.OperatorVersion.Add(1)
 
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
@@ -79,20 +76,55 @@ func init() {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "must-gather" {
+		ctrl.SetLogger(zap.New(zap.UseDevMode(true)))
+		if err := runMustGather(os.Args[2:]); err != nil {
+			setupLog.Error(err, "unable to gather the installation")
+			os.Exit(1)
+		}
+		return
+	}
+
 	var metricsAddr string
 	var enableLeaderElection bool
+	var gracefulShutdownTimeout time.Duration
+	var logFormat string
+	var tracingConfig tracing.Config
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8383", "The address the metric endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.DurationVar(&gracefulShutdownTimeout, "graceful-shutdown-timeout", 2*time.Minute,
+		"The time given to the reconciles in flight to finish on shutdown, before the leader election lease is released.")
+	flag.StringVar(&logFormat, "log-format", l.FormatText, "The format of the logs, text or json.")
+	flag.StringVar(&tracingConfig.Endpoint, "tracing-endpoint", "",
+		"The host and port of the OTLP HTTP collector the reconcile traces are exported to. Tracing is disabled when empty.")
+	flag.BoolVar(&tracingConfig.Insecure, "tracing-insecure", false, "Export the traces over HTTP instead of HTTPS.")
+	flag.Float64Var(&tracingConfig.SampleRatio, "tracing-sample-ratio", 1, "The ratio of the reconciles traced, between 0 and 1.")
 	flag.Parse()
 
-	ctrl.SetLogger(zap.New(zap.UseDevMode(true)))
-	logrus.SetFormatter(&logrus.TextFormatter{
-		ForceColors:      true,
-		FullTimestamp:    true,
-		QuoteEmptyFields: false,
-	})
+	ctrl.SetLogger(zap.New(zap.UseDevMode(logFormat == l.FormatText)))
+	if err := l.SetFormat(logFormat); err != nil {
+		setupLog.Error(err, "unable to set log format")
+		os.Exit(1)
+	}
+	if level, ok := os.LookupEnv("LOG_LEVEL"); ok {
+		if err := l.SetLevel(level); err != nil {
+			setupLog.Error(err, "unable to set log level")
+			os.Exit(1)
+		}
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracingConfig)
+	if err != nil {
+		setupLog.Error(err, "unable to set up tracing")
+		os.Exit(1)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			setupLog.Error(err, "unable to export the remaining traces")
+		}
+	}()
 
 	watchNamespace, err := resources.GetWatchNamespace()
 	if err != nil {
@@ -101,18 +133,31 @@ func main() {
 	}
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
-		Scheme:             scheme,
-		MetricsBindAddress: metricsAddr,
-		Port:               9443,
-		LeaderElection:     enableLeaderElection,
-		LeaderElectionID:   "28185cee.integreatly.org",
-		Namespace:          watchNamespace,
+		Scheme:                  scheme,
+		MetricsBindAddress:      metricsAddr,
+		Port:                    9443,
+		LeaderElection:          enableLeaderElection,
+		LeaderElectionID:        leaderElectionID,
+		LeaderElectionNamespace: watchNamespace,
+		GracefulShutdownTimeout: &gracefulShutdownTimeout,
+		Namespace:               watchNamespace,
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
+	// Only the leader reconciles, so only the leader reports the metrics set
+	// by the reconciles
+	registerMetrics(mgr.Elected())
+
+	if watchNamespace != "" {
+		if err := mgr.Add(l.NewLevelWatcher(mgr.GetAPIReader(), watchNamespace, l.DefaultLevelWatchInterval)); err != nil {
+			setupLog.Error(err, "unable to watch log level")
+			os.Exit(1)
+		}
+	}
+
 	if err = rhmicontroller.New(mgr).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "RHMI")
 		os.Exit(1)
@@ -129,10 +174,24 @@ func main() {
 			setupLog.Error(err, "unable to create controller", "controller", "Namespace")
 			os.Exit(1)
 		}
-		if err = (&usercontroller.UserReconciler{}).SetupWithManager(mgr); err != nil {
+		userCtrl, err := usercontroller.New(mgr)
+		if err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "User")
+			os.Exit(1)
+		}
+		if err = userCtrl.SetupWithManager(mgr); err != nil {
 			setupLog.Error(err, "unable to create controller", "controller", "User")
 			os.Exit(1)
 		}
+		gatewayCtrl, err := selfmanagedgatewaycontroller.New(mgr)
+		if err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "SelfManagedGateway")
+			os.Exit(1)
+		}
+		if err = gatewayCtrl.SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "SelfManagedGateway")
+			os.Exit(1)
+		}
 	}
 
 	if strings.Contains(watchNamespace, "sandbox") {
@@ -167,6 +226,109 @@ func main() {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
+
+	// The reconciles in flight are drained, hand over to the next replica
+	if enableLeaderElection && watchNamespace != "" {
+		if err := releaseLease(mgr, watchNamespace); err != nil {
+			setupLog.Error(err, "unable to release leader election lease")
+		}
+	}
+}
+
+// runMustGather gathers the state of the installation in the cluster of the
+// current kube config into an archive, to attach to bug reports
+func runMustGather(args []string) error {
+	var options mustgather.Options
+	var namespace, output string
+	flags := flag.NewFlagSet("must-gather", flag.ExitOnError)
+	flags.StringVar(&namespace, "namespace", "", "The namespace of the operator, defaults to the watch namespace.")
+	flags.StringVar(&output, "output", fmt.Sprintf("must-gather-%s.tar.gz", time.Now().UTC().Format("20060102-150405")),
+		"The path of the archive written.")
+	flags.BoolVar(&options.Secrets, "secrets", true, "Gather the secrets of the RHOAM namespaces, with their values redacted.")
+	flags.DurationVar(&options.EventsSince, "events-since", mustgather.DefaultEventsSince, "How far back the events are gathered.")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if namespace == "" {
+		watchNamespace, err := resources.GetWatchNamespace()
+		if err != nil {
+			return fmt.Errorf("no --namespace given and unable to get the watch namespace: %w", err)
+		}
+		namespace = watchNamespace
+	}
+
+	client, err := k8sclient.New(ctrl.GetConfigOrDie(), k8sclient.Options{Scheme: scheme})
+	if err != nil {
+		return err
+	}
+	bundle, err := mustgather.NewGatherer(client, scheme, namespace, options).Gather(context.Background())
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	if err := bundle.WriteArchive(file); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	setupLog.Info("gathered the installation", "output", output, "files", len(bundle.Files), "errors", len(bundle.Errors))
+	return nil
+}
+
+func registerMetrics(elected <-chan struct{}) {
+	collectors := []prometheus.Collector{
+		integreatlymetrics.OperatorVersion,
+		integreatlymetrics.RHMIStatusAvailable,
+		integreatlymetrics.RHMIInfo,
+		integreatlymetrics.RHMIVersion,
+		integreatlymetrics.RHMIStatus,
+		integreatlymetrics.RHOAMVersion,
+		integreatlymetrics.RHOAMStatus,
+		integreatlymetrics.ThreeScaleUserAction,
+		integreatlymetrics.ThreeScaleRequestDuration,
+		integreatlymetrics.ThreeScaleRequestRetries,
+		integreatlymetrics.UserSyncLag,
+		integreatlymetrics.UserSyncPending,
+		integreatlymetrics.UserSyncFailures,
+		integreatlymetrics.KeycloakRealmDrift,
+		integreatlymetrics.SecretLastRotation,
+		integreatlymetrics.SecretRotations,
+		integreatlymetrics.SMTPProviderHealthy,
+		integreatlymetrics.NetworkPolicyBlockedFlows,
+		integreatlymetrics.Quota,
+		integreatlymetrics.NumTenants,
+		integreatlymetrics.NoActivated3ScaleTenantAccount,
+		integreatlymetrics.NoTenantRealm,
+		integreatlymetrics.StageReconcileDuration,
+		integreatlymetrics.ProductReconcileDuration,
+		integreatlymetrics.ProductReconcileErrors,
+		integreatlymetrics.ProductPhaseTransitions,
+		integreatlymetrics.ProductPhaseDuration,
+		integreatlymetrics.InstallationDuration,
+		integreatlymetrics.UpgradeDuration,
+	}
+	for _, collector := range collectors {
+		customMetrics.Registry.MustRegister(integreatlymetrics.LeaderOnly(collector, elected))
+	}
+	customMetrics.Registry.MustRegister(integreatlymetrics.NewOperatorLeader(elected))
+}
+
+func releaseLease(mgr ctrl.Manager, namespace string) error {
+	client, err := k8sclient.New(mgr.GetConfig(), k8sclient.Options{Scheme: mgr.GetScheme()})
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return leader.ReleaseLease(ctx, client, namespace, leaderElectionID)
 }
 
 func setupWebhooks(mgr ctrl.Manager) error {
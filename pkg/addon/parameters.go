@@ -7,8 +7,7 @@ import (
 
 	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
 	rhmiresources "github.com/integr8ly/integreatly-operator/pkg/resources/rhmi"
-	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/errors"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/secretprovider"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -46,25 +45,30 @@ func GetParameterByInstallation(ctx context.Context, client k8sclient.Client, in
 // GetParameterByInstallType retrieves the value for an addon parameter by
 // selecting the addon name for installationType
 func GetParameterByInstallType(ctx context.Context, client k8sclient.Client, installationType integreatlyv1alpha1.InstallationType, namespace, parameter string) ([]byte, bool, error) {
-	addonName := GetName(installationType)
-	secretName := fmt.Sprintf("addon-%s-parameters", addonName)
-
-	secret := &corev1.Secret{}
-	if err := client.Get(ctx, k8sclient.ObjectKey{
-		Name:      secretName,
-		Namespace: namespace,
-	}, secret); err != nil {
-		if errors.IsNotFound(err) {
+	secretProvider, err := secretprovider.New(client, namespace)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get secret provider: %v", err)
+	}
+
+	secret, err := secretProvider.GetSecret(ctx, GetParametersSecretName(installationType))
+	if err != nil {
+		if secretprovider.IsNotFound(err) {
 			return nil, false, nil
 		}
 
 		return nil, false, fmt.Errorf("failed to retrieve parameters secret: %v", err)
 	}
 
-	value, ok := secret.Data[parameter]
+	value, ok := secret[parameter]
 	return value, ok, nil
 }
 
+// GetParametersSecretName returns the name of the secret holding the addon
+// parameters for installationType
+func GetParametersSecretName(installationType integreatlyv1alpha1.InstallationType) string {
+	return fmt.Sprintf("addon-%s-parameters", GetName(installationType))
+}
+
 // GetStringParameterByInstallType retrieves the string value for an addon
 // parameter given the installation type
 func GetStringParameterByInstallType(ctx context.Context, client k8sclient.Client, installationType integreatlyv1alpha1.InstallationType, namespace, parameter string) (string, bool, error) {
@@ -17,6 +17,10 @@ import (
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// GHOauthClientsSecretName is the name of the secret holding the GitHub OAuth
+// client credentials
+const GHOauthClientsSecretName = "github-oauth-secret"
+
 type ProductConfig map[string]string
 
 func NewManager(ctx context.Context, client k8sclient.Client, namespace string, configMapName string, installation *integreatlyv1alpha1.RHMI) (*Manager, error) {
@@ -163,7 +167,7 @@ func (m *Manager) GetBackupsSecretName() string {
 }
 
 func (m *Manager) GetGHOauthClientsSecretName() string {
-	return "github-oauth-secret"
+	return GHOauthClientsSecretName
 }
 
 func (m *Manager) ReadAMQStreams() (*AMQStreams, error) {
@@ -6,6 +6,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 
 	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	dashboards "github.com/integr8ly/integreatly-operator/pkg/products/monitoringcommon/dashboards"
 )
 
 const (
@@ -23,30 +24,6 @@ type Monitoring struct {
 	Config ProductConfig
 }
 
-var rhmiTemplateList = []string{
-	"endpointsdetailed",
-	"endpointsreport",
-	"endpointssummary",
-	"resources-by-namespace",
-	"resources-by-pod",
-	"cluster-resources",
-	"critical-slo-rhmi-alerts",
-	"cro-resources",
-	"rhoam-rhsso-availability-slo",
-}
-
-var managedAPITemplateList = []string{
-	"endpointsdetailed",
-	"endpointsreport",
-	"endpointssummary",
-	"resources-by-namespace",
-	"resources-by-pod",
-	"cluster-resources",
-	"critical-slo-managed-api-alerts",
-	"cro-resources",
-	"rhoam-rhsso-availability-slo",
-}
-
 func NewMonitoring(config ProductConfig) *Monitoring {
 	return &Monitoring{Config: config}
 }
@@ -159,16 +136,7 @@ func (m *Monitoring) GetPrometheusStorageRequest() string {
 }
 
 func (m *Monitoring) GetDashboards(installType integreatlyv1alpha1.InstallationType) []string {
-	switch installType {
-	case integreatlyv1alpha1.InstallationTypeManaged, integreatlyv1alpha1.InstallationTypeSelfManaged, integreatlyv1alpha1.InstallationTypeWorkshop:
-		return rhmiTemplateList
-	case integreatlyv1alpha1.InstallationTypeManagedApi:
-		return managedAPITemplateList
-	case integreatlyv1alpha1.InstallationTypeMultitenantManagedApi:
-		return managedAPITemplateList
-	default:
-		return rhmiTemplateList
-	}
+	return dashboardNames(installType)
 }
 
 func (m *Monitoring) GetJobTemplates() []string {
@@ -205,3 +173,13 @@ func (m *Monitoring) GetExtraParamWithDefault(key string, v string) string {
 func (m *Monitoring) GetAlertManagerRouteName() string {
 	return "alertmanager-route"
 }
+
+// dashboardNames returns the names of the Grafana dashboards installed for the
+// installation type
+func dashboardNames(installType integreatlyv1alpha1.InstallationType) []string {
+	names := []string{}
+	for _, dashboard := range dashboards.ForInstallationType(installType) {
+		names = append(names, dashboard.Name)
+	}
+	return names
+}
@@ -99,14 +99,7 @@ func (m *Observability) GetWatchableCRDs() []runtime.Object {
 }
 
 func (m *Observability) GetDashboards(installType integreatlyv1alpha1.InstallationType) []string {
-	switch installType {
-	case integreatlyv1alpha1.InstallationTypeManaged, integreatlyv1alpha1.InstallationTypeSelfManaged, integreatlyv1alpha1.InstallationTypeWorkshop:
-		return rhmiTemplateList
-	case integreatlyv1alpha1.InstallationTypeManagedApi, integreatlyv1alpha1.InstallationTypeMultitenantManagedApi:
-		return managedAPITemplateList
-	default:
-		return rhmiTemplateList
-	}
+	return dashboardNames(installType)
 }
 
 func (m *Observability) GetAlertManagerVersion() string {
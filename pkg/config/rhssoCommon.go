@@ -2,6 +2,8 @@ package config
 
 import (
 	"errors"
+	"strconv"
+	"strings"
 
 	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
 	keycloak "github.com/keycloak/keycloak-operator/pkg/apis/keycloak/v1alpha1"
@@ -92,6 +94,32 @@ func (r *RHSSOCommon) SetHost(newHost string) {
 	r.Config["HOST"] = newHost
 }
 
+// GetIdentityProviders returns the external identity providers set up by the
+// operator, as "type:alias" entries
+func (r *RHSSOCommon) GetIdentityProviders() []string {
+	if r.Config["IDENTITY_PROVIDERS"] == "" {
+		return []string{}
+	}
+	return strings.Split(r.Config["IDENTITY_PROVIDERS"], ",")
+}
+
+func (r *RHSSOCommon) SetIdentityProviders(providers []string) {
+	r.Config["IDENTITY_PROVIDERS"] = strings.Join(providers, ",")
+}
+
+// GetRealmDriftRemediation returns whether drift in the managed realm
+// settings is reverted, rather than only reported
+func (r *RHSSOCommon) GetRealmDriftRemediation() (bool, error) {
+	if r.Config["REALM_DRIFT_REMEDIATION"] == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(r.Config["REALM_DRIFT_REMEDIATION"])
+}
+
+func (r *RHSSOCommon) SetRealmDriftRemediation(remediate bool) {
+	r.Config["REALM_DRIFT_REMEDIATION"] = strconv.FormatBool(remediate)
+}
+
 func (r *RHSSOCommon) Read() ProductConfig {
 	return r.Config
 }
@@ -3,6 +3,7 @@ package config
 import (
 	"errors"
 	"strconv"
+	"time"
 
 	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
 	testResources "github.com/integr8ly/integreatly-operator/test/resources"
@@ -27,6 +28,45 @@ func (r *RHSSOUser) GetDevelopersGroupConfigured() (bool, error) {
 	return strconv.ParseBool(r.Config["DEVELOPERS_GROUP_CONFIGURED"])
 }
 
+// GetBrandingApplied returns the hash of the branding theme set on the
+// master realm
+func (r *RHSSOUser) GetBrandingApplied() string {
+	return r.Config["BRANDING_APPLIED"]
+}
+
+func (r *RHSSOUser) SetBrandingApplied(hash string) {
+	r.Config["BRANDING_APPLIED"] = hash
+}
+
+// GetBrandingPending returns the hash of the branding theme waiting to be
+// loaded by Keycloak, and when it was first mounted
+func (r *RHSSOUser) GetBrandingPending() (string, time.Time) {
+	since, err := strconv.ParseInt(r.Config["BRANDING_PENDING_SINCE"], 10, 64)
+	if err != nil {
+		return r.Config["BRANDING_PENDING"], time.Time{}
+	}
+	return r.Config["BRANDING_PENDING"], time.Unix(since, 0)
+}
+
+func (r *RHSSOUser) SetBrandingPending(hash string, since time.Time) {
+	r.Config["BRANDING_PENDING"] = hash
+	if hash == "" {
+		r.Config["BRANDING_PENDING_SINCE"] = ""
+		return
+	}
+	r.Config["BRANDING_PENDING_SINCE"] = strconv.FormatInt(since.Unix(), 10)
+}
+
+// GetBrandingFailed returns the hash of the last branding theme that failed
+// to load, which isn't mounted again until the branding changes
+func (r *RHSSOUser) GetBrandingFailed() string {
+	return r.Config["BRANDING_FAILED"]
+}
+
+func (r *RHSSOUser) SetBrandingFailed(hash string) {
+	r.Config["BRANDING_FAILED"] = hash
+}
+
 func (r *RHSSOUser) GetBlackboxTargetPath() string {
 	return r.Config["BLACKBOX_TARGET_PATH"]
 }
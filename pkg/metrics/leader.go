@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// leaderCollector reports the metrics of a collector only once the replica is
+// the leader. The metrics of the operator are set by the reconciles, that only
+// run in the leader, so the other replicas would report stale or unset values
+type leaderCollector struct {
+	prometheus.Collector
+	elected <-chan struct{}
+}
+
+// LeaderOnly returns a collector that reports the metrics of collector once
+// elected is closed, when the replica is elected leader
+func LeaderOnly(collector prometheus.Collector, elected <-chan struct{}) prometheus.Collector {
+	return &leaderCollector{Collector: collector, elected: elected}
+}
+
+func (c *leaderCollector) Collect(metrics chan<- prometheus.Metric) {
+	if isElected(c.elected) {
+		c.Collector.Collect(metrics)
+	}
+}
+
+// NewOperatorLeader returns the gauge reporting whether the replica is the
+// leader, reported by every replica
+func NewOperatorLeader(elected <-chan struct{}) prometheus.GaugeFunc {
+	return prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Name: "integreatly_operator_leader",
+			Help: "Whether the operator replica is the leader that reconciles the installation",
+		},
+		func() float64 {
+			if isElected(elected) {
+				return 1
+			}
+			return 0
+		},
+	)
+}
+
+func isElected(elected <-chan struct{}) bool {
+	select {
+	case <-elected:
+		return true
+	default:
+		return false
+	}
+}
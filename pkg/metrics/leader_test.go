@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestLeaderOnly(t *testing.T) {
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "test_gauge", Help: "test"})
+	gauge.Set(1)
+	elected := make(chan struct{})
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(LeaderOnly(gauge, elected), NewOperatorLeader(elected))
+
+	gather := func() map[string]float64 {
+		families, err := registry.Gather()
+		if err != nil {
+			t.Fatal(err)
+		}
+		values := map[string]float64{}
+		for _, family := range families {
+			values[family.GetName()] = family.GetMetric()[0].GetGauge().GetValue()
+		}
+		return values
+	}
+
+	values := gather()
+	if _, ok := values["test_gauge"]; ok {
+		t.Errorf("expected the metrics not to be reported before the replica is elected")
+	}
+	if values["integreatly_operator_leader"] != 0 {
+		t.Errorf("expected the replica not to be reported leader")
+	}
+
+	close(elected)
+	values = gather()
+	if values["test_gauge"] != 1 || values["integreatly_operator_leader"] != 1 {
+		t.Errorf("expected the metrics of the leader to be reported, got %v", values)
+	}
+}
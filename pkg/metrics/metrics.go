@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"github.com/integr8ly/integreatly-operator/pkg/resources"
 	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+	"strconv"
+	"time"
 
 	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
 	"github.com/integr8ly/integreatly-operator/version"
@@ -146,6 +148,110 @@ var (
 			"username",
 		},
 	)
+
+	ThreeScaleRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "threescale_api_request_duration_seconds",
+			Help:    "Duration of requests made by the operator to the 3scale API",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{
+			"method",
+			"endpoint",
+			"code",
+		},
+	)
+
+	ThreeScaleRequestRetries = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "threescale_api_request_retries_total",
+			Help: "Number of requests to the 3scale API retried after a failure",
+		},
+		[]string{
+			"method",
+			"endpoint",
+		},
+	)
+
+	UserSyncLag = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "rhoam_user_sync_lag_seconds",
+			Help:    "Time between a change to an OpenShift user, group or identity and the user being synced",
+			Buckets: []float64{0.1, 0.5, 1, 5, 10, 30, 60, 300, 900},
+		},
+	)
+
+	UserSyncPending = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "rhoam_user_sync_pending",
+			Help: "Number of users with changes waiting to be synced",
+		},
+	)
+
+	KeycloakRealmDrift = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rhoam_keycloak_realm_drift",
+			Help: "Number of managed Keycloak realm settings that differ from the desired state",
+		},
+		[]string{
+			"realm",
+			"kind",
+		},
+	)
+
+	UserSyncFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rhoam_user_sync_failures_total",
+			Help: "Number of failed user syncs",
+		},
+		[]string{
+			"consumer",
+		},
+	)
+
+	SecretLastRotation = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rhoam_secret_last_rotation_timestamp_seconds",
+			Help: "Time the value of an operator generated secret key was last rotated",
+		},
+		[]string{
+			"secret",
+			"key",
+		},
+	)
+
+	SecretRotations = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rhoam_secret_rotations_total",
+			Help: "Number of rotations of operator generated secret keys",
+		},
+		[]string{
+			"secret",
+			"key",
+		},
+	)
+
+	NetworkPolicyBlockedFlows = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rhoam_network_policy_blocked_flows",
+			Help: "Number of flows from the pods of a namespace to the services of a RHOAM namespace that its NetworkPolicies block, or would block in audit mode",
+		},
+		[]string{
+			"source_namespace",
+			"namespace",
+		},
+	)
+
+	SMTPProviderHealthy = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rhoam_smtp_provider_healthy",
+			Help: "1 if the SMTP provider accepted an authenticated handshake, 0 otherwise. The priority is the position of the provider in the failover order, 0 being the primary SMTP secret",
+		},
+		[]string{
+			"secret",
+			"priority",
+		},
+	)
 )
 
 // SetRHMIInfo exposes rhmi info metrics with labels from the installation CR
@@ -191,6 +297,62 @@ func ResetThreeScaleUserAction() {
 	ThreeScaleUserAction.Reset()
 }
 
+// ObserveThreeScaleRequest records the duration of a request to the 3scale
+// API. code is the response status code, or "error" if no response was
+// received
+func ObserveThreeScaleRequest(method, endpoint, code string, duration time.Duration) {
+	ThreeScaleRequestDuration.WithLabelValues(method, endpoint, code).Observe(duration.Seconds())
+}
+
+func IncThreeScaleRequestRetry(method, endpoint string) {
+	ThreeScaleRequestRetries.WithLabelValues(method, endpoint).Inc()
+}
+
+func ObserveUserSyncLag(lag time.Duration) {
+	UserSyncLag.Observe(lag.Seconds())
+}
+
+func SetUserSyncPending(pending int) {
+	UserSyncPending.Set(float64(pending))
+}
+
+func IncUserSyncFailures(consumer string) {
+	UserSyncFailures.WithLabelValues(consumer).Inc()
+}
+
+func SetSecretLastRotation(secret, key string, rotated time.Time) {
+	SecretLastRotation.WithLabelValues(secret, key).Set(float64(rotated.Unix()))
+}
+
+func IncSecretRotations(secret, key string) {
+	SecretRotations.WithLabelValues(secret, key).Inc()
+}
+
+func ResetNetworkPolicyBlockedFlows() {
+	NetworkPolicyBlockedFlows.Reset()
+}
+
+func IncNetworkPolicyBlockedFlows(sourceNamespace, namespace string) {
+	NetworkPolicyBlockedFlows.WithLabelValues(sourceNamespace, namespace).Inc()
+}
+
+// SetSMTPProvidersHealthy exposes the health of the SMTP providers, in
+// failover order
+func SetSMTPProvidersHealthy(secrets []string, healthy []bool) {
+	SMTPProviderHealthy.Reset()
+	for priority, secret := range secrets {
+		value := 0.0
+		if healthy[priority] {
+			value = 1
+		}
+		SMTPProviderHealthy.WithLabelValues(secret, strconv.Itoa(priority)).Set(value)
+	}
+}
+
+func SetKeycloakRealmDrift(realm, kind string, drifts int) {
+	KeycloakRealmDrift.WithLabelValues(realm, kind).Set(float64(drifts))
+}
+
 func SetNumTenants(numTenants string) {
 	NumTenants.Reset()
 	NumTenants.WithLabelValues(numTenants).Set(float64(1))
@@ -0,0 +1,156 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// reconcileDurationBuckets range from a reconcile of a few milliseconds to
+// the ten minutes of a product waiting for OLM
+var reconcileDurationBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600}
+
+var (
+	StageReconcileDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "integreatly_operator_stage_reconcile_duration_seconds",
+			Help:    "Duration of the reconciles of the installation stages",
+			Buckets: reconcileDurationBuckets,
+		},
+		[]string{
+			"stage",
+		},
+	)
+
+	ProductReconcileDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "integreatly_operator_product_reconcile_duration_seconds",
+			Help:    "Duration of the reconciles of the products",
+			Buckets: reconcileDurationBuckets,
+		},
+		[]string{
+			"stage",
+			"product",
+		},
+	)
+
+	ProductReconcileErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "integreatly_operator_product_reconcile_errors_total",
+			Help: "Number of reconciles of the products that returned an error",
+		},
+		[]string{
+			"product",
+		},
+	)
+
+	ProductPhaseTransitions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "integreatly_operator_product_phase_transitions_total",
+			Help: "Number of changes of the phase of the products",
+		},
+		[]string{
+			"product",
+			"from",
+			"to",
+		},
+	)
+
+	ProductPhaseDuration = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "integreatly_operator_product_phase_duration_seconds",
+			Help: "Time the product has been in its current phase",
+		},
+		[]string{
+			"product",
+			"phase",
+		},
+	)
+
+	InstallationDuration = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "integreatly_operator_installation_duration_seconds",
+			Help: "Time from the creation of the installation until all its stages completed, or until now while installing",
+		},
+	)
+
+	UpgradeDuration = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "integreatly_operator_upgrade_duration_seconds",
+			Help: "Time from the start of the upgrade to the version until all the stages completed, or until now while upgrading. The time is counted from the start of the operator for an upgrade already in progress",
+		},
+		[]string{
+			"to_version",
+		},
+	)
+)
+
+// phaseTracker keeps the phase of the products the duration gauge is set for,
+// and the start of the upgrade in progress. It's kept in memory, the
+// reconciles only run in the leader
+type phaseTracker struct {
+	mu           sync.Mutex
+	phases       map[string]integreatlyv1alpha1.StatusPhase
+	upgrade      string
+	upgradeStart time.Time
+}
+
+var tracker = &phaseTracker{phases: map[string]integreatlyv1alpha1.StatusPhase{}}
+
+// ObserveStageReconcile records the duration of the reconcile of a stage
+func ObserveStageReconcile(stage integreatlyv1alpha1.StageName, duration time.Duration) {
+	StageReconcileDuration.WithLabelValues(string(stage)).Observe(duration.Seconds())
+}
+
+// ObserveProductReconcile records the duration and error of the reconcile of
+// a product, the change of its phase from previousPhase to phase, and the time
+// in phase since the product entered it
+func ObserveProductReconcile(stage integreatlyv1alpha1.StageName, product integreatlyv1alpha1.ProductName, previousPhase, phase integreatlyv1alpha1.StatusPhase, since time.Time, duration time.Duration, err error) {
+	ProductReconcileDuration.WithLabelValues(string(stage), string(product)).Observe(duration.Seconds())
+	if err != nil {
+		ProductReconcileErrors.WithLabelValues(string(product)).Inc()
+	}
+	if previousPhase != phase {
+		ProductPhaseTransitions.WithLabelValues(string(product), string(previousPhase), string(phase)).Inc()
+	}
+	tracker.setPhase(string(product), phase, since, time.Now())
+}
+
+func (t *phaseTracker) setPhase(product string, phase integreatlyv1alpha1.StatusPhase, since, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if current, ok := t.phases[product]; ok && current != phase {
+		ProductPhaseDuration.DeleteLabelValues(product, string(current))
+	}
+	t.phases[product] = phase
+	ProductPhaseDuration.WithLabelValues(product, string(phase)).Set(now.Sub(since).Seconds())
+}
+
+// SetInstallationDuration sets the duration of the installation while it's
+// installing, or of the upgrade in progress. Once complete the gauges keep the
+// last duration set, so it's called again before the installation is marked
+// complete
+func SetInstallationDuration(installation *integreatlyv1alpha1.RHMI) {
+	tracker.setInstallationDuration(installation, time.Now())
+}
+
+func (t *phaseTracker) setInstallationDuration(installation *integreatlyv1alpha1.RHMI, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch {
+	case installation.Status.Version == "":
+		InstallationDuration.Set(now.Sub(installation.CreationTimestamp.Time).Seconds())
+	case installation.Status.ToVersion != "":
+		if t.upgrade != installation.Status.ToVersion {
+			t.upgrade = installation.Status.ToVersion
+			t.upgradeStart = now
+		}
+		UpgradeDuration.WithLabelValues(t.upgrade).Set(now.Sub(t.upgradeStart).Seconds())
+	default:
+		t.upgrade = ""
+	}
+}
@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func getGaugeValue(t *testing.T, gauge prometheus.Gauge) float64 {
+	metric := &dto.Metric{}
+	if err := gauge.Write(metric); err != nil {
+		t.Fatal(err)
+	}
+	return metric.GetGauge().GetValue()
+}
+
+func TestPhaseTracker_SetPhase(t *testing.T) {
+	tracker := &phaseTracker{phases: map[string]integreatlyv1alpha1.StatusPhase{}}
+	start := time.Now()
+
+	tracker.setPhase("3scale", integreatlyv1alpha1.PhaseInProgress, start, start)
+	tracker.setPhase("3scale", integreatlyv1alpha1.PhaseInProgress, start, start.Add(time.Hour))
+	if value := getGaugeValue(t, ProductPhaseDuration.WithLabelValues("3scale", "in progress")); value != 3600 {
+		t.Errorf("expected an hour in progress, got %v", value)
+	}
+
+	tracker.setPhase("3scale", integreatlyv1alpha1.PhaseCompleted, start.Add(2*time.Hour), start.Add(2*time.Hour))
+	if value := getGaugeValue(t, ProductPhaseDuration.WithLabelValues("3scale", "completed")); value != 0 {
+		t.Errorf("expected the time in the new phase to start, got %v", value)
+	}
+	if ProductPhaseDuration.DeleteLabelValues("3scale", "in progress") {
+		t.Errorf("expected the time in the previous phase to be removed")
+	}
+
+	// the time in phase is counted from the transition time of the status
+	// after a restart of the operator
+	restarted := &phaseTracker{phases: map[string]integreatlyv1alpha1.StatusPhase{}}
+	restarted.setPhase("rhsso", integreatlyv1alpha1.PhaseFailed, start, start.Add(2*time.Hour))
+	if value := getGaugeValue(t, ProductPhaseDuration.WithLabelValues("rhsso", "failed")); value != 7200 {
+		t.Errorf("expected two hours failed, got %v", value)
+	}
+}
+
+func TestPhaseTracker_SetInstallationDuration(t *testing.T) {
+	tracker := &phaseTracker{phases: map[string]integreatlyv1alpha1.StatusPhase{}}
+	created := time.Now()
+	installation := &integreatlyv1alpha1.RHMI{
+		ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(created)},
+		Status:     integreatlyv1alpha1.RHMIStatus{ToVersion: "1.14.0"},
+	}
+
+	tracker.setInstallationDuration(installation, created.Add(40*time.Minute))
+	if value := getGaugeValue(t, InstallationDuration); value != 2400 {
+		t.Errorf("expected an installation duration of 40 minutes, got %v", value)
+	}
+
+	installation.Status.Version = "1.14.0"
+	installation.Status.ToVersion = "1.15.0"
+	upgradeStart := created.Add(24 * time.Hour)
+	tracker.setInstallationDuration(installation, upgradeStart)
+	tracker.setInstallationDuration(installation, upgradeStart.Add(30*time.Minute))
+	if value := getGaugeValue(t, UpgradeDuration.WithLabelValues("1.15.0")); value != 1800 {
+		t.Errorf("expected an upgrade duration of 30 minutes, got %v", value)
+	}
+	if value := getGaugeValue(t, InstallationDuration); value != 2400 {
+		t.Errorf("expected the installation duration to be kept, got %v", value)
+	}
+}
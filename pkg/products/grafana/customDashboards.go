@@ -0,0 +1,380 @@
+package grafana
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	grafanav1alpha1 "github.com/integr8ly/grafana-operator/v3/pkg/apis/integreatly/v1alpha1"
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/owner"
+	corev1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// Customers add dashboards to the customer Grafana with ConfigMaps labelled
+// with customDashboardLabel in their namespaces. Every key of the ConfigMap
+// ending with .json holds a dashboard. The dashboards are validated and
+// reconciled as GrafanaDashboards in the customer dashboards folder, or in the
+// folder of the tenant owning the namespace in multitenant installations
+const (
+	customDashboardLabel = "monitoring.integreatly.org/customer-dashboard"
+	// customDashboardSourceAnnotation records the ConfigMap key a
+	// GrafanaDashboard was built from
+	customDashboardSourceAnnotation = "monitoring.integreatly.org/customer-dashboard-source"
+
+	customDashboardFolder     = "Customer Dashboards"
+	customDashboardNamePrefix = "customer-"
+	customDashboardKeySuffix  = ".json"
+
+	// customerDatasourceName and customerDatasourceUID are the name and uid
+	// of the only datasource of the customer Grafana, reconciled in
+	// reconcileComponents
+	customerDatasourceName = "Prometheus"
+	customerDatasourceUID  = "customer-prometheus"
+)
+
+// forbiddenPanelTypes list information from outside of the dashboard, like
+// the other dashboards, alerts and plugins of the Grafana instance, which are
+// meant for its administrators
+var forbiddenPanelTypes = map[string]bool{
+	"dashlist":       true,
+	"alertlist":      true,
+	"annolist":       true,
+	"pluginlist":     true,
+	"gettingstarted": true,
+	"welcome":        true,
+	"news":           true,
+}
+
+var invalidNameCharacters = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// reconcileCustomDashboards reconciles the dashboards of the customer
+// ConfigMaps, and deletes the dashboards whose ConfigMap or key was removed.
+// Invalid dashboards are reported as events on their ConfigMap so that one
+// invalid dashboard doesn't block the installation
+func (r *Reconciler) reconcileCustomDashboards(ctx context.Context, serverClient k8sclient.Client) (integreatlyv1alpha1.StatusPhase, error) {
+	configMaps := &corev1.ConfigMapList{}
+	if err := serverClient.List(ctx, configMaps, k8sclient.MatchingLabels{customDashboardLabel: "true"}); err != nil {
+		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to list customer dashboard configmaps: %w", err)
+	}
+
+	tenantFolders, err := r.getTenantFolders(ctx, serverClient)
+	if err != nil {
+		return integreatlyv1alpha1.PhaseFailed, err
+	}
+
+	desired := map[string]bool{}
+	for i := range configMaps.Items {
+		configMap := &configMaps.Items[i]
+		if configMap.DeletionTimestamp != nil {
+			continue
+		}
+
+		folder := customDashboardFolder
+		if tenantFolder, ok := tenantFolders[configMap.Namespace]; ok {
+			folder = tenantFolder
+		}
+
+		for _, key := range sortedDashboardKeys(configMap) {
+			// the dashboard of an invalid key is kept as it is until the key
+			// is fixed or removed
+			desired[customDashboardName(configMap, key)] = true
+
+			dashboard, err := buildCustomDashboard(configMap, key, folder, r.Config.GetOperatorNamespace())
+			if err != nil {
+				r.log.Warningf("Invalid customer dashboard", l.Fields{"ns": configMap.Namespace, "name": configMap.Name, "key": key, "error": err})
+				r.recorder.Eventf(configMap, corev1.EventTypeWarning, "InvalidDashboard", "Dashboard %s is not valid, the previous version is kept: %v", key, err)
+				continue
+			}
+
+			spec := dashboard.Spec
+			annotations := dashboard.Annotations
+			opRes, err := controllerutil.CreateOrUpdate(ctx, serverClient, dashboard, func() error {
+				owner.AddIntegreatlyOwnerAnnotations(dashboard, r.installation)
+				dashboard.Labels = map[string]string{
+					"monitoring-key":     "customer",
+					customDashboardLabel: "true",
+				}
+				if dashboard.Annotations == nil {
+					dashboard.Annotations = map[string]string{}
+				}
+				for key, value := range annotations {
+					dashboard.Annotations[key] = value
+				}
+				dashboard.Spec = spec
+				return nil
+			})
+			if err != nil {
+				return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to reconcile customer dashboard %s: %w", dashboard.Name, err)
+			}
+			if opRes != controllerutil.OperationResultNone {
+				r.log.Infof("Operation result customer dashboard", l.Fields{"grafanaDashboard": dashboard.Name, "result": opRes})
+			}
+		}
+	}
+
+	dashboards := &grafanav1alpha1.GrafanaDashboardList{}
+	if err := serverClient.List(ctx, dashboards, k8sclient.InNamespace(r.Config.GetOperatorNamespace()), k8sclient.MatchingLabels{customDashboardLabel: "true"}); err != nil {
+		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to list customer dashboards: %w", err)
+	}
+	for i := range dashboards.Items {
+		dashboard := &dashboards.Items[i]
+		if desired[dashboard.Name] {
+			continue
+		}
+		r.log.Infof("Deleting customer dashboard of removed configmap", l.Fields{"grafanaDashboard": dashboard.Name})
+		if err := serverClient.Delete(ctx, dashboard); err != nil && !k8serr.IsNotFound(err) {
+			return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to delete customer dashboard %s: %w", dashboard.Name, err)
+		}
+	}
+
+	return integreatlyv1alpha1.PhaseCompleted, nil
+}
+
+// getTenantFolders returns the folder of the dashboards of each tenant
+// namespace in multitenant installations, keyed by namespace
+func (r *Reconciler) getTenantFolders(ctx context.Context, serverClient k8sclient.Client) (map[string]string, error) {
+	folders := map[string]string{}
+	if !integreatlyv1alpha1.IsRHOAMMultitenant(integreatlyv1alpha1.InstallationType(r.installation.Spec.Type)) {
+		return folders, nil
+	}
+
+	tenants := &integreatlyv1alpha1.RhoamTenantList{}
+	if err := serverClient.List(ctx, tenants); err != nil {
+		return nil, fmt.Errorf("failed to list tenants: %w", err)
+	}
+	for _, tenant := range tenants.Items {
+		for _, namespace := range []string{tenant.Name + "-dev", tenant.Name + "-stage"} {
+			folders[namespace] = fmt.Sprintf("%s Dashboards", tenant.Name)
+		}
+	}
+	return folders, nil
+}
+
+// buildCustomDashboard validates the dashboard of the ConfigMap key and
+// returns the GrafanaDashboard of it, in namespace
+func buildCustomDashboard(configMap *corev1.ConfigMap, key, folder, namespace string) (*grafanav1alpha1.GrafanaDashboard, error) {
+	dashboard := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(configMap.Data[key]), &dashboard); err != nil {
+		return nil, fmt.Errorf("invalid json: %w", err)
+	}
+
+	datasources, err := validateCustomDashboard(dashboard)
+	if err != nil {
+		return nil, err
+	}
+
+	source, hash := customDashboardSource(configMap, key)
+	// the ids and uids of the customer dashboards are set by the operator,
+	// so that they can't replace the dashboards of other customers
+	dashboard["id"] = nil
+	dashboard["uid"] = "customer-" + hex.EncodeToString(hash)[:16]
+
+	dashboardJSON, err := json.Marshal(dashboard)
+	if err != nil {
+		return nil, err
+	}
+
+	return &grafanav1alpha1.GrafanaDashboard{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        customDashboardName(configMap, key),
+			Namespace:   namespace,
+			Annotations: map[string]string{customDashboardSourceAnnotation: source},
+		},
+		Spec: grafanav1alpha1.GrafanaDashboardSpec{
+			Json:             string(dashboardJSON),
+			Datasources:      datasources,
+			CustomFolderName: folder,
+		},
+	}, nil
+}
+
+// validateCustomDashboard checks that the dashboard only queries the
+// datasource of the customer Grafana and has no panels meant for the
+// administrators. It returns the mapping of the datasource inputs of the
+// dashboard to the customer datasource
+func validateCustomDashboard(dashboard map[string]interface{}) ([]grafanav1alpha1.GrafanaDashboardDatasource, error) {
+	if title, _ := dashboard["title"].(string); title == "" {
+		return nil, fmt.Errorf("title is undefined")
+	}
+
+	datasources := []grafanav1alpha1.GrafanaDashboardDatasource{}
+	inputs, _ := dashboard["__inputs"].([]interface{})
+	inputNames := map[string]bool{}
+	for _, input := range inputs {
+		input, _ := input.(map[string]interface{})
+		if input["type"] != "datasource" {
+			continue
+		}
+		name, _ := input["name"].(string)
+		if input["pluginId"] != "prometheus" {
+			return nil, fmt.Errorf("datasource input %s is not a prometheus datasource", name)
+		}
+		inputNames[name] = true
+		datasources = append(datasources, grafanav1alpha1.GrafanaDashboardDatasource{
+			InputName:      name,
+			DatasourceName: customerDatasourceName,
+		})
+	}
+
+	if err := validatePanels(dashboard["panels"]); err != nil {
+		return nil, err
+	}
+	rows, _ := dashboard["rows"].([]interface{})
+	for _, row := range rows {
+		row, _ := row.(map[string]interface{})
+		if err := validatePanels(row["panels"]); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validateDatasources(dashboard, inputNames); err != nil {
+		return nil, err
+	}
+	return datasources, nil
+}
+
+// validatePanels checks the types of the panels, and of the panels of the
+// collapsed rows
+func validatePanels(value interface{}) error {
+	panels, _ := value.([]interface{})
+	for _, panel := range panels {
+		panel, _ := panel.(map[string]interface{})
+		panelType, _ := panel["type"].(string)
+		title, _ := panel["title"].(string)
+		if forbiddenPanelTypes[panelType] {
+			return fmt.Errorf("panel %q of type %s is not allowed", title, panelType)
+		}
+		if panelType == "text" && (panel["mode"] == "html" || nestedValue(panel, "options", "mode") == "html") {
+			return fmt.Errorf("panel %q renders html, which is not allowed", title)
+		}
+		if err := validatePanels(panel["panels"]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateDatasources checks every datasource reference of the dashboard
+func validateDatasources(value interface{}, inputNames map[string]bool) error {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, field := range value {
+			if key == "datasource" {
+				if err := validateDatasource(field, inputNames); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := validateDatasources(field, inputNames); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, item := range value {
+			if err := validateDatasources(item, inputNames); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func validateDatasource(datasource interface{}, inputNames map[string]bool) error {
+	switch datasource := datasource.(type) {
+	case nil:
+		return nil
+	case string:
+		return validateDatasourceReference(datasource, inputNames)
+	case map[string]interface{}:
+		// datasource references of Grafana 8 dashboards, by uid, or by name
+		// in the dashboards migrated from earlier versions
+		reference, _ := datasource["uid"].(string)
+		if reference == "" {
+			reference, _ = datasource["name"].(string)
+		}
+		switch datasource["type"] {
+		case "datasource", "grafana":
+			// the mixed, dashboard and grafana built-in datasources
+			return nil
+		case "prometheus", nil:
+			return validateDatasourceReference(reference, inputNames)
+		}
+		return fmt.Errorf("datasource of type %v does not exist, the only datasource is %s", datasource["type"], customerDatasourceName)
+	}
+	return fmt.Errorf("invalid datasource reference %v", datasource)
+}
+
+// validateDatasourceReference checks a datasource name or uid refers to the
+// customer datasource, directly or through an input or a variable
+func validateDatasourceReference(reference string, inputNames map[string]bool) error {
+	switch {
+	case reference == "", reference == customerDatasourceName, reference == customerDatasourceUID,
+		reference == "-- Grafana --", reference == "-- Dashboard --", reference == "-- Mixed --":
+		return nil
+	case strings.HasPrefix(reference, "${") && strings.HasSuffix(reference, "}"):
+		name := strings.TrimSuffix(strings.TrimPrefix(reference, "${"), "}")
+		if inputNames[name] {
+			return nil
+		}
+	case strings.HasPrefix(reference, "$"):
+		// dashboard variables are checked through the datasource of their
+		// query
+		return nil
+	}
+	return fmt.Errorf("datasource %s does not exist, the only datasource is %s", reference, customerDatasourceName)
+}
+
+func nestedValue(object map[string]interface{}, keys ...string) interface{} {
+	var value interface{} = object
+	for _, key := range keys {
+		nested, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = nested[key]
+	}
+	return value
+}
+
+// customDashboardName returns a unique name for the dashboard of the
+// ConfigMap key, readable when short enough
+// customDashboardSource returns the ConfigMap key a dashboard is built from,
+// and its hash
+func customDashboardSource(configMap *corev1.ConfigMap, key string) (string, []byte) {
+	source := fmt.Sprintf("%s/%s/%s", configMap.Namespace, configMap.Name, key)
+	hash := sha1.Sum([]byte(source))
+	return source, hash[:]
+}
+
+func customDashboardName(configMap *corev1.ConfigMap, key string) string {
+	_, hash := customDashboardSource(configMap, key)
+	name := strings.ToLower(fmt.Sprintf("%s%s-%s-%s", customDashboardNamePrefix, configMap.Namespace, configMap.Name, strings.TrimSuffix(key, customDashboardKeySuffix)))
+	name = strings.Trim(invalidNameCharacters.ReplaceAllString(name, "-"), "-")
+	suffix := "-" + hex.EncodeToString(hash)[:8]
+	if len(name)+len(suffix) > 253 {
+		name = name[:253-len(suffix)]
+	}
+	return name + suffix
+}
+
+func sortedDashboardKeys(configMap *corev1.ConfigMap) []string {
+	keys := []string{}
+	for key := range configMap.Data {
+		if strings.HasSuffix(key, customDashboardKeySuffix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
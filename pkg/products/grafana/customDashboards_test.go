@@ -0,0 +1,296 @@
+package grafana
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	grafanav1alpha1 "github.com/integr8ly/grafana-operator/v3/pkg/apis/integreatly/v1alpha1"
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/config"
+	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	testOperatorNamespace = "redhat-rhoam-customer-monitoring-operator"
+
+	requestsDashboard = `{
+  "id": 12,
+  "uid": "requests",
+  "title": "Requests",
+  "__inputs": [{"name": "DS_PROMETHEUS", "type": "datasource", "pluginId": "prometheus"}],
+  "panels": [
+    {"type": "graph", "title": "Requests", "datasource": "${DS_PROMETHEUS}"},
+    {"type": "row", "title": "Details", "panels": [
+      {"type": "stat", "title": "Errors", "datasource": {"type": "prometheus", "uid": "customer-prometheus"}}
+    ]}
+  ],
+  "templating": {"list": [{"name": "namespace", "datasource": "Prometheus"}]}
+}`
+)
+
+func getBuildScheme() (*runtime.Scheme, error) {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	if err := integreatlyv1alpha1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	if err := grafanav1alpha1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	return scheme, nil
+}
+
+func getLogger() l.Logger {
+	return l.NewLoggerWithContext(l.Fields{l.ProductLogContext: integreatlyv1alpha1.ProductGrafana})
+}
+
+func dashboardConfigMap(namespace, name string, data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    map[string]string{customDashboardLabel: "true"},
+		},
+		Data: data,
+	}
+}
+
+func TestValidateCustomDashboard(t *testing.T) {
+	tests := []struct {
+		Name                string
+		Dashboard           string
+		ExpectErr           string
+		ExpectedDatasources int
+	}{
+		{
+			Name:                "test valid dashboard",
+			Dashboard:           requestsDashboard,
+			ExpectedDatasources: 1,
+		},
+		{
+			Name:      "test dashboard without title",
+			Dashboard: `{"panels": []}`,
+			ExpectErr: "title",
+		},
+		{
+			Name:      "test unknown datasource",
+			Dashboard: `{"title": "a", "panels": [{"type": "graph", "datasource": "Loki"}]}`,
+			ExpectErr: "datasource Loki does not exist",
+		},
+		{
+			Name:      "test undeclared datasource input",
+			Dashboard: `{"title": "a", "panels": [{"type": "graph", "datasource": "${DS_OTHER}"}]}`,
+			ExpectErr: "does not exist",
+		},
+		{
+			Name:      "test non prometheus datasource input",
+			Dashboard: `{"title": "a", "__inputs": [{"name": "DS", "type": "datasource", "pluginId": "elasticsearch"}]}`,
+			ExpectErr: "not a prometheus datasource",
+		},
+		{
+			Name:      "test non prometheus datasource reference",
+			Dashboard: `{"title": "a", "panels": [{"type": "graph", "datasource": {"type": "loki"}}]}`,
+			ExpectErr: "of type loki does not exist",
+		},
+		{
+			Name:      "test unknown datasource uid",
+			Dashboard: `{"title": "a", "panels": [{"type": "graph", "datasource": {"type": "prometheus", "uid": "abc"}}]}`,
+			ExpectErr: "datasource abc does not exist",
+		},
+		{
+			Name:      "test unknown datasource name",
+			Dashboard: `{"title": "a", "panels": [{"type": "graph", "datasource": {"name": "Thanos"}}]}`,
+			ExpectErr: "datasource Thanos does not exist",
+		},
+		{
+			Name:      "test built-in datasource reference",
+			Dashboard: `{"title": "a", "annotations": {"list": [{"datasource": {"type": "grafana", "uid": "grafana"}}]}}`,
+		},
+		{
+			Name:      "test admin only panel",
+			Dashboard: `{"title": "a", "panels": [{"type": "dashlist", "title": "All dashboards"}]}`,
+			ExpectErr: "type dashlist is not allowed",
+		},
+		{
+			Name:      "test admin only panel in a collapsed row",
+			Dashboard: `{"title": "a", "panels": [{"type": "row", "panels": [{"type": "pluginlist"}]}]}`,
+			ExpectErr: "type pluginlist is not allowed",
+		},
+		{
+			Name:      "test html text panel",
+			Dashboard: `{"title": "a", "panels": [{"type": "text", "options": {"mode": "html"}}]}`,
+			ExpectErr: "html",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			dashboard := map[string]interface{}{}
+			if err := json.Unmarshal([]byte(tt.Dashboard), &dashboard); err != nil {
+				t.Fatal(err)
+			}
+			datasources, err := validateCustomDashboard(dashboard)
+			if tt.ExpectErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.ExpectErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.ExpectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(datasources) != tt.ExpectedDatasources {
+				t.Fatalf("expected %d datasources, got %v", tt.ExpectedDatasources, datasources)
+			}
+		})
+	}
+}
+
+func TestReconcileCustomDashboards(t *testing.T) {
+	scheme, err := getBuildScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stale := &grafanav1alpha1.GrafanaDashboard{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "customer-removed",
+			Namespace: testOperatorNamespace,
+			Labels:    map[string]string{customDashboardLabel: "true"},
+		},
+	}
+	invalidConfigMap := dashboardConfigMap("team-a-dev", "dashboards", map[string]string{
+		"requests.json": requestsDashboard,
+		"invalid.json":  `{"title": "invalid", "panels": [{"type": "news"}]}`,
+		"README.md":     "not a dashboard",
+	})
+	// the dashboard of the invalid key, from when it was valid
+	previous := &grafanav1alpha1.GrafanaDashboard{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      customDashboardName(invalidConfigMap, "invalid.json"),
+			Namespace: testOperatorNamespace,
+			Labels:    map[string]string{customDashboardLabel: "true"},
+		},
+	}
+	rateLimit := &grafanav1alpha1.GrafanaDashboard{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      rateLimitDashBoardName,
+			Namespace: testOperatorNamespace,
+		},
+	}
+
+	tests := []struct {
+		Name             string
+		InstallationType integreatlyv1alpha1.InstallationType
+		ExpectedFolders  map[string]string
+	}{
+		{
+			Name:             "test dashboards are reconciled in the customer folder",
+			InstallationType: integreatlyv1alpha1.InstallationTypeManagedApi,
+			ExpectedFolders: map[string]string{
+				"team-a-dev": customDashboardFolder,
+				"shared":     customDashboardFolder,
+			},
+		},
+		{
+			Name:             "test tenant dashboards are reconciled in the tenant folder",
+			InstallationType: integreatlyv1alpha1.InstallationTypeMultitenantManagedApi,
+			ExpectedFolders: map[string]string{
+				"team-a-dev": "team-a Dashboards",
+				"shared":     customDashboardFolder,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			serverClient := fakeclient.NewFakeClientWithScheme(scheme,
+				stale.DeepCopy(),
+				rateLimit.DeepCopy(),
+				&integreatlyv1alpha1.RhoamTenant{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}},
+				previous.DeepCopy(),
+				invalidConfigMap.DeepCopy(),
+				dashboardConfigMap("shared", "dashboards", map[string]string{
+					"requests.json": requestsDashboard,
+				}),
+			)
+			recorder := record.NewFakeRecorder(50)
+			r := &Reconciler{
+				Config: config.NewGrafana(config.ProductConfig{
+					"OPERATOR_NAMESPACE": testOperatorNamespace,
+				}),
+				installation: &integreatlyv1alpha1.RHMI{
+					ObjectMeta: metav1.ObjectMeta{Name: "rhoam", Namespace: "redhat-rhoam-operator"},
+					Spec:       integreatlyv1alpha1.RHMISpec{Type: string(tt.InstallationType)},
+				},
+				recorder: recorder,
+				log:      getLogger(),
+			}
+
+			phase, err := r.reconcileCustomDashboards(context.TODO(), serverClient)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if phase != integreatlyv1alpha1.PhaseCompleted {
+				t.Fatalf("expected phase %s, got %s", integreatlyv1alpha1.PhaseCompleted, phase)
+			}
+
+			dashboards := &grafanav1alpha1.GrafanaDashboardList{}
+			if err := serverClient.List(context.TODO(), dashboards, k8sclient.MatchingLabels{customDashboardLabel: "true"}); err != nil {
+				t.Fatal(err)
+			}
+			if len(dashboards.Items) != len(tt.ExpectedFolders)+1 {
+				t.Fatalf("expected %d customer dashboards, got %d", len(tt.ExpectedFolders)+1, len(dashboards.Items))
+			}
+			uids := map[string]bool{}
+			for _, dashboard := range dashboards.Items {
+				if dashboard.Name == previous.Name {
+					// kept as it was while its key is invalid
+					continue
+				}
+				namespace := strings.Split(dashboard.Annotations[customDashboardSourceAnnotation], "/")[0]
+				if dashboard.Spec.CustomFolderName != tt.ExpectedFolders[namespace] {
+					t.Errorf("expected dashboard of %s in folder %q, got %q", namespace, tt.ExpectedFolders[namespace], dashboard.Spec.CustomFolderName)
+				}
+				if len(dashboard.Spec.Datasources) != 1 || dashboard.Spec.Datasources[0].DatasourceName != customerDatasourceName {
+					t.Errorf("expected the dashboard input to be mapped to the customer datasource, got %v", dashboard.Spec.Datasources)
+				}
+				model := map[string]interface{}{}
+				if err := json.Unmarshal([]byte(dashboard.Spec.Json), &model); err != nil {
+					t.Fatal(err)
+				}
+				if model["id"] != nil {
+					t.Errorf("expected the dashboard id to be cleared, got %v", model["id"])
+				}
+				uid, _ := model["uid"].(string)
+				if uids[uid] {
+					t.Errorf("expected unique dashboard uids, got duplicate %s", uid)
+				}
+				uids[uid] = true
+			}
+
+			if err := serverClient.Get(context.TODO(), k8sclient.ObjectKey{Name: rateLimitDashBoardName, Namespace: testOperatorNamespace}, &grafanav1alpha1.GrafanaDashboard{}); err != nil {
+				t.Errorf("expected the operator dashboard to be kept: %v", err)
+			}
+
+			select {
+			case event := <-recorder.Events:
+				if !strings.Contains(event, "InvalidDashboard") {
+					t.Errorf("expected an invalid dashboard event, got %s", event)
+				}
+			default:
+				t.Errorf("expected an invalid dashboard event")
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/integr8ly/integreatly-operator/pkg/products/monitoring"
 	"github.com/integr8ly/integreatly-operator/pkg/products/observability"
 	prometheus "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"time"
 
 	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/quota"
@@ -24,6 +25,7 @@ import (
 	"github.com/integr8ly/integreatly-operator/pkg/resources/events"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/marketplace"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/owner"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/secretrotation"
 	"github.com/integr8ly/integreatly-operator/version"
 	routev1 "github.com/openshift/api/route/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -56,6 +58,10 @@ type Reconciler struct {
 	log           l.Logger
 	extraParams   map[string]string
 	recorder      record.EventRecorder
+
+	// sessionSecretRotation is the rotation record of the session secret of
+	// the proxy
+	sessionSecretRotation string
 }
 
 func (r *Reconciler) GetPreflightObject(ns string) runtime.Object {
@@ -171,6 +177,12 @@ func (r *Reconciler) Reconcile(ctx context.Context, installation *integreatlyv1a
 		return phase, err
 	}
 
+	phase, err = r.reconcileCustomDashboards(ctx, client)
+	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+		events.HandleError(r.recorder, installation, phase, "Failed to reconcile customer grafana dashboards", err)
+		return phase, err
+	}
+
 	if string(r.Config.GetProductVersion()) != string(integreatlyv1alpha1.VersionGrafana) {
 		r.Config.SetProductVersion(string(integreatlyv1alpha1.VersionGrafana))
 		if err := r.ConfigManager.WriteConfig(r.Config); err != nil {
@@ -219,7 +231,12 @@ func (r *Reconciler) reconcileSecrets(ctx context.Context, client k8sclient.Clie
 		if secret.Data == nil {
 			secret.Data = map[string][]byte{}
 		}
-		secret.Data["session_secret"] = []byte(r.populateSessionProxySecret())
+		if len(secret.Data["session_secret"]) == 0 {
+			secret.Data["session_secret"] = []byte(r.populateSessionProxySecret())
+		}
+		policy := secretrotation.PolicyFor(installation, secret.Name)
+		secretrotation.Rotate(secret, policy, r.populateSessionProxySecret, time.Now())
+		r.sessionSecretRotation = secret.Annotations[secretrotation.LastRotatedAnnotation]
 		return nil
 	})
 
@@ -332,6 +349,11 @@ func (r *Reconciler) reconcileComponents(ctx context.Context, client k8sclient.C
 			},
 			Deployment: &grafanav1alpha1.GrafanaDeployment{
 				PriorityClassName: r.installation.Spec.PriorityClassName,
+				// restarts the proxy when the session secret is rotated, it's
+				// only read on start up
+				Annotations: map[string]string{
+					secretrotation.LastRotatedAnnotation: r.sessionSecretRotation,
+				},
 			},
 			Secrets: []string{"grafana-k8s-tls", "grafana-k8s-proxy"},
 			Service: &grafanav1alpha1.GrafanaService{
@@ -412,7 +434,8 @@ func (r *Reconciler) reconcileComponents(ctx context.Context, client k8sclient.C
 		spec := grafanav1alpha1.GrafanaDataSourceSpec{
 			Datasources: []grafanav1alpha1.GrafanaDataSourceFields{
 				{
-					Name:      "Prometheus",
+					Name:      customerDatasourceName,
+					Uid:       customerDatasourceUID,
 					Access:    "proxy",
 					Editable:  true,
 					IsDefault: true,
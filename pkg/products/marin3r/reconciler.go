@@ -9,9 +9,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/integr8ly/cloud-resource-operator/apis/integreatly/v1alpha1/types"
-	croUtil "github.com/integr8ly/cloud-resource-operator/pkg/client"
 	"github.com/integr8ly/integreatly-operator/pkg/products/grafana"
-	"github.com/integr8ly/integreatly-operator/pkg/resources/owner"
 	prometheus "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -23,6 +21,7 @@ import (
 	marin3rconfig "github.com/integr8ly/integreatly-operator/pkg/products/marin3r/config"
 	"github.com/integr8ly/integreatly-operator/pkg/resources"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/backup"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/clusterstorage"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/constants"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/events"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/marketplace"
@@ -293,10 +292,7 @@ func (r *Reconciler) reconcileRedis(ctx context.Context, client k8sclient.Client
 	ns := r.installation.Namespace
 
 	redisName := fmt.Sprintf("%s%s", constants.RateLimitRedisPrefix, r.installation.Name)
-	rateLimitRedis, err := croUtil.ReconcileRedis(ctx, client, defaultInstallationNamespace, r.installation.Spec.Type, croUtil.TierProduction, redisName, ns, redisName, ns, false, func(cr metav1.Object) error {
-		owner.AddIntegreatlyOwnerAnnotations(cr, r.installation)
-		return nil
-	})
+	rateLimitRedis, err := resources.ReconcileRedis(ctx, client, r.installation, defaultInstallationNamespace, redisName, ns)
 	if err != nil {
 		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to reconcile backend redis request: %w", err)
 	}
@@ -425,6 +421,12 @@ func (r *Reconciler) reconcileSubscription(ctx context.Context, serverClient k8s
 }
 
 func (r *Reconciler) preUpgradeBackupExecutor() backup.BackupExecutor {
+	if resources.UsesClusterStorage(r.installation) {
+		return clusterstorage.NewBackupExecutor(
+			fmt.Sprintf("%s%s", constants.RateLimitRedisPrefix, r.installation.Name),
+			r.installation.Namespace,
+		)
+	}
 	if r.installation.Spec.UseClusterStorage != "false" {
 		return backup.NewNoopBackupExecutor()
 	}
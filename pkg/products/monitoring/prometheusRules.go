@@ -2,6 +2,7 @@ package monitoring
 
 import (
 	"fmt"
+	"github.com/integr8ly/integreatly-operator/pkg/products/monitoringcommon"
 	"github.com/integr8ly/integreatly-operator/pkg/resources"
 	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
@@ -300,6 +301,12 @@ func (r *Reconciler) newAlertsReconciler(logger l.Logger, installType string) re
 					},
 				},
 			},
+			{
+				AlertName: fmt.Sprintf("%s-reconcile-performance-alerts", installationName),
+				Namespace: r.Config.GetOperatorNamespace(),
+				GroupName: fmt.Sprintf("%s-reconcile-performance.rules", installationName),
+				Rules:     monitoringcommon.ReconcileAlertRules(installationName),
+			},
 			{
 				AlertName: "test-alerts",
 				Namespace: r.Config.GetOperatorNamespace(),
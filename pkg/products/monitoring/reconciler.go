@@ -10,14 +10,13 @@ import (
 	"github.com/integr8ly/integreatly-operator/pkg/resources/quota"
 
 	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/secretprovider"
 	"strings"
 
 	"github.com/operator-framework/operator-registry/pkg/lib/bundle"
 	prometheus "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	rbac "k8s.io/api/rbac/v1"
 
-	"k8s.io/apimachinery/pkg/types"
-
 	"github.com/integr8ly/integreatly-operator/pkg/resources/backup"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/constants"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/events"
@@ -780,18 +779,20 @@ func (r *Reconciler) getPagerDutySecret(ctx context.Context, serverClient k8scli
 
 	var secret string
 
-	pagerdutySecret := &corev1.Secret{}
-	err := serverClient.Get(ctx, types.NamespacedName{Name: r.installation.Spec.PagerDutySecret,
-		Namespace: r.installation.Namespace}, pagerdutySecret)
+	secretProvider, err := secretprovider.New(serverClient, r.installation.Namespace)
+	if err != nil {
+		return "", fmt.Errorf("failed to get secret provider: %w", err)
+	}
 
+	pagerdutySecret, err := secretProvider.GetSecret(ctx, r.installation.Spec.PagerDutySecret)
 	if err != nil {
 		return "", fmt.Errorf("could not obtain pagerduty credentials secret: %w", err)
 	}
 
-	if len(pagerdutySecret.Data["PAGERDUTY_KEY"]) != 0 {
-		secret = string(pagerdutySecret.Data["PAGERDUTY_KEY"])
-	} else if len(pagerdutySecret.Data["serviceKey"]) != 0 {
-		secret = string(pagerdutySecret.Data["serviceKey"])
+	if len(pagerdutySecret["PAGERDUTY_KEY"]) != 0 {
+		secret = string(pagerdutySecret["PAGERDUTY_KEY"])
+	} else if len(pagerdutySecret["serviceKey"]) != 0 {
+		secret = string(pagerdutySecret["serviceKey"])
 	}
 
 	if secret == "" {
@@ -805,18 +806,20 @@ func (r *Reconciler) getDMSSecret(ctx context.Context, serverClient k8sclient.Cl
 
 	var secret string
 
-	dmsSecret := &corev1.Secret{}
-	err := serverClient.Get(ctx, types.NamespacedName{Name: r.installation.Spec.DeadMansSnitchSecret,
-		Namespace: r.installation.Namespace}, dmsSecret)
+	secretProvider, err := secretprovider.New(serverClient, r.installation.Namespace)
+	if err != nil {
+		return "", fmt.Errorf("failed to get secret provider: %w", err)
+	}
 
+	dmsSecret, err := secretProvider.GetSecret(ctx, r.installation.Spec.DeadMansSnitchSecret)
 	if err != nil {
 		return "", fmt.Errorf("could not obtain dead mans snitch credentials secret: %w", err)
 	}
 
-	if len(dmsSecret.Data["SNITCH_URL"]) != 0 {
-		secret = string(dmsSecret.Data["SNITCH_URL"])
-	} else if len(dmsSecret.Data["url"]) != 0 {
-		secret = string(dmsSecret.Data["url"])
+	if len(dmsSecret["SNITCH_URL"]) != 0 {
+		secret = string(dmsSecret["SNITCH_URL"])
+	} else if len(dmsSecret["url"]) != 0 {
+		secret = string(dmsSecret["url"])
 	} else {
 		return "", fmt.Errorf("url is undefined in dead mans snitch secret")
 	}
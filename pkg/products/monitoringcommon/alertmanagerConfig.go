@@ -8,6 +8,8 @@ import (
 	"github.com/integr8ly/integreatly-operator/pkg/resources"
 	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/owner"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/secretprovider"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/smtphealth"
 	configv1 "github.com/openshift/api/config/v1"
 	routev1 "github.com/openshift/api/route/v1"
 	v1 "github.com/openshift/api/route/v1"
@@ -84,20 +86,25 @@ func ReconcileAlertManagerSecrets(ctx context.Context, serverClient k8sclient.Cl
 		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("could not obtain alert manager route: %w", err)
 	}
 
+	secretProvider, err := secretprovider.New(serverClient, integreatlyOperatorNs)
+	if err != nil {
+		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to get secret provider: %w", err)
+	}
+
 	// handle smtp credentials
 	smtpSecret := &corev1.Secret{}
-	if err := serverClient.Get(ctx, types.NamespacedName{Name: installation.Spec.SMTPSecret, Namespace: integreatlyOperatorNs}, smtpSecret); err != nil {
+	if smtpSecret.Data, err = secretProvider.GetSecret(ctx, smtphealth.ActiveSecret(installation)); err != nil {
 		log.Warningf("Could not obtain smtp credentials secret", l.Fields{"error": err.Error()})
 	}
 
 	//Get pagerduty credentials
-	pagerDutySecret, err := getPagerDutySecret(ctx, serverClient, *installation)
+	pagerDutySecret, err := getPagerDutySecret(ctx, secretProvider, *installation)
 	if err != nil {
 		return integreatlyv1alpha1.PhaseFailed, err
 	}
 
 	//Get dms credentials
-	dmsSecret, err := getDMSSecret(ctx, serverClient, *installation)
+	dmsSecret, err := getDMSSecret(ctx, secretProvider, *installation)
 	if err != nil {
 		return integreatlyv1alpha1.PhaseFailed, err
 	}
@@ -227,22 +234,19 @@ func ReconcileAlertManagerSecrets(ctx context.Context, serverClient k8sclient.Cl
 	return integreatlyv1alpha1.PhaseCompleted, nil
 }
 
-func getPagerDutySecret(ctx context.Context, serverClient k8sclient.Client, installation integreatlyv1alpha1.RHMI) (string, error) {
+func getPagerDutySecret(ctx context.Context, secretProvider secretprovider.Provider, installation integreatlyv1alpha1.RHMI) (string, error) {
 
 	var secret string
 
-	pagerdutySecret := &corev1.Secret{}
-	err := serverClient.Get(ctx, types.NamespacedName{Name: installation.Spec.PagerDutySecret,
-		Namespace: installation.Namespace}, pagerdutySecret)
-
+	pagerdutySecret, err := secretProvider.GetSecret(ctx, installation.Spec.PagerDutySecret)
 	if err != nil {
 		return "", fmt.Errorf("could not obtain pagerduty credentials secret: %w", err)
 	}
 
-	if len(pagerdutySecret.Data["PAGERDUTY_KEY"]) != 0 {
-		secret = string(pagerdutySecret.Data["PAGERDUTY_KEY"])
-	} else if len(pagerdutySecret.Data["serviceKey"]) != 0 {
-		secret = string(pagerdutySecret.Data["serviceKey"])
+	if len(pagerdutySecret["PAGERDUTY_KEY"]) != 0 {
+		secret = string(pagerdutySecret["PAGERDUTY_KEY"])
+	} else if len(pagerdutySecret["serviceKey"]) != 0 {
+		secret = string(pagerdutySecret["serviceKey"])
 	}
 
 	if secret == "" {
@@ -252,22 +256,19 @@ func getPagerDutySecret(ctx context.Context, serverClient k8sclient.Client, inst
 	return secret, nil
 }
 
-func getDMSSecret(ctx context.Context, serverClient k8sclient.Client, installation integreatlyv1alpha1.RHMI) (string, error) {
+func getDMSSecret(ctx context.Context, secretProvider secretprovider.Provider, installation integreatlyv1alpha1.RHMI) (string, error) {
 
 	var secret string
 
-	dmsSecret := &corev1.Secret{}
-	err := serverClient.Get(ctx, types.NamespacedName{Name: installation.Spec.DeadMansSnitchSecret,
-		Namespace: installation.Namespace}, dmsSecret)
-
+	dmsSecret, err := secretProvider.GetSecret(ctx, installation.Spec.DeadMansSnitchSecret)
 	if err != nil {
 		return "", fmt.Errorf("could not obtain dead mans snitch credentials secret: %w", err)
 	}
 
-	if len(dmsSecret.Data["SNITCH_URL"]) != 0 {
-		secret = string(dmsSecret.Data["SNITCH_URL"])
-	} else if len(dmsSecret.Data["url"]) != 0 {
-		secret = string(dmsSecret.Data["url"])
+	if len(dmsSecret["SNITCH_URL"]) != 0 {
+		secret = string(dmsSecret["SNITCH_URL"])
+	} else if len(dmsSecret["url"]) != 0 {
+		secret = string(dmsSecret["url"])
 	} else {
 		return "", fmt.Errorf("url is undefined in dead mans snitch secret")
 	}
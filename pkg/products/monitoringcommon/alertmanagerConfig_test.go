@@ -7,6 +7,7 @@ import (
 	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
 	moqclient "github.com/integr8ly/integreatly-operator/pkg/client"
 	"github.com/integr8ly/integreatly-operator/pkg/config"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/secretprovider"
 	configv1 "github.com/openshift/api/config/v1"
 	projectv1 "github.com/openshift/api/project/v1"
 	routev1 "github.com/openshift/api/route/v1"
@@ -520,7 +521,7 @@ func TestReconciler_getPagerDutySecret(t *testing.T) {
 
 			serverClient := tt.serverClient()
 
-			got, err := getPagerDutySecret(context.TODO(), serverClient, *installation)
+			got, err := getPagerDutySecret(context.TODO(), secretprovider.NewKubernetesProvider(serverClient, installation.Namespace), *installation)
 			if tt.wantErr != "" && err.Error() != tt.wantErr {
 				t.Errorf("getPagerDutySecret() error = %v, wantErr %v", err.Error(), tt.wantErr)
 				return
@@ -608,7 +609,7 @@ func TestReconciler_getDMSSecret(t *testing.T) {
 
 			serverClient := tt.serverClient()
 
-			got, err := getDMSSecret(context.TODO(), serverClient, *installation)
+			got, err := getDMSSecret(context.TODO(), secretprovider.NewKubernetesProvider(serverClient, installation.Namespace), *installation)
 			if tt.wantErr != "" && err.Error() != tt.wantErr {
 				t.Errorf("getDMSSecret() error = %v, wantErr %v", err.Error(), tt.wantErr)
 				return
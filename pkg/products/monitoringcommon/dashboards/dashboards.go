@@ -0,0 +1,131 @@
+package monitoringcommon
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"strings"
+	"text/template"
+
+	grafanav1alpha1 "github.com/integr8ly/grafana-operator/v3/pkg/apis/integreatly/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+)
+
+// The dashboards are Grafana JSON models in templates/, rendered with
+// text/template. The templates use [[ and ]] as delimiters, as the Grafana
+// legend formats use {{ and }}, for example:
+//
+//	"expr": "sum([[ .ContainerCPUMetric ]]{namespace=~'[[ .NamespacePrefix ]].*'})"
+//	"annotations": {"list": [...[[ if .ManagedAPI ]], {"name": "Quota"}[[ end ]]]}
+//
+//go:embed templates/*.json.tmpl
+var templateFiles embed.FS
+
+const (
+	leftDelimiter  = "[["
+	rightDelimiter = "]]"
+)
+
+// Params configure the dashboard templates for an installation
+type Params struct {
+	// InstallationType is the type of the RHMI CR, which selects the variant of
+	// the dashboards
+	InstallationType v1alpha1.InstallationType
+	// InstallationName is the name of the installation type, rhmi or rhoam,
+	// which prefixes the metrics of the operator
+	InstallationName string
+	// NamespacePrefix of the product namespaces
+	NamespacePrefix string
+	// ContainerCPUMetric is the container CPU usage metric of the cluster
+	// version
+	ContainerCPUMetric string
+}
+
+// ManagedAPI returns whether the dashboards are rendered for a RHOAM
+// installation
+func (p Params) ManagedAPI() bool {
+	return v1alpha1.IsRHOAM(p.InstallationType)
+}
+
+// Dashboard is a dashboard of the monitoring Grafana
+type Dashboard struct {
+	// Name of the GrafanaDashboard CR
+	Name string
+	// FileName of the dashboard in Grafana
+	FileName string
+	// Plugins needed by the panels of the dashboard
+	Plugins grafanav1alpha1.PluginList
+
+	// installedFor returns whether the dashboard is installed for the
+	// installation type, nil when installed for every type
+	installedFor func(installType v1alpha1.InstallationType) bool
+}
+
+var dashboardList = []Dashboard{
+	{
+		Name:     "endpointsdetailed",
+		FileName: "endpointsdetailed.json",
+		Plugins: grafanav1alpha1.PluginList{
+			{Name: "natel-discrete-panel", Version: "0.0.9"},
+		},
+	},
+	{Name: "endpointsreport", FileName: "endpointsreport.json"},
+	{Name: "endpointssummary", FileName: "endpointssummary.json"},
+	{Name: "resources-by-namespace", FileName: "resources-by-namespace.json"},
+	{Name: "resources-by-pod", FileName: "resources-by-pod.json"},
+	{Name: "cluster-resources", FileName: "cluster-resources-new.json"},
+	{Name: "critical-slo-rhmi-alerts", FileName: "critical-slo-alerts.json", installedFor: isNotRHOAM},
+	{Name: "critical-slo-managed-api-alerts", FileName: "critical-slo-alerts.json", installedFor: v1alpha1.IsRHOAM},
+	{Name: "cro-resources", FileName: "cro-resources.json"},
+	{Name: "rhoam-rhsso-availability-slo", FileName: "rhoam-rhsso-availability-slo.json"},
+	{Name: "operator-reconcile", FileName: "operator-reconcile.json"},
+}
+
+// Dashboards returns all the dashboards of the monitoring Grafana
+func Dashboards() []Dashboard {
+	return append([]Dashboard{}, dashboardList...)
+}
+
+// ForInstallationType returns the dashboards installed for the installation
+// type
+func ForInstallationType(installType v1alpha1.InstallationType) []Dashboard {
+	dashboards := []Dashboard{}
+	for _, dashboard := range dashboardList {
+		if dashboard.installedFor == nil || dashboard.installedFor(installType) {
+			dashboards = append(dashboards, dashboard)
+		}
+	}
+	return dashboards
+}
+
+// GetDashboard returns the dashboard with the given name
+func GetDashboard(name string) (Dashboard, error) {
+	for _, dashboard := range dashboardList {
+		if dashboard.Name == name {
+			return dashboard, nil
+		}
+	}
+	return Dashboard{}, fmt.Errorf("Invalid/Unsupported Grafana Dashboard %s", name)
+}
+
+// Render returns the JSON model of the dashboard for params
+func (d Dashboard) Render(params Params) (string, error) {
+	templateName := d.Name + ".json.tmpl"
+	tmpl, err := template.New(templateName).
+		Delims(leftDelimiter, rightDelimiter).
+		Option("missingkey=error").
+		ParseFS(templateFiles, "templates/"+templateName)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse dashboard template %s: %w", d.Name, err)
+	}
+
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, params); err != nil {
+		return "", fmt.Errorf("failed to render dashboard %s: %w", d.Name, err)
+	}
+	return strings.TrimSpace(buffer.String()), nil
+}
+
+func isNotRHOAM(installType v1alpha1.InstallationType) bool {
+	return !v1alpha1.IsRHOAM(installType)
+}
@@ -0,0 +1,269 @@
+package monitoringcommon_test
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/metrics"
+	dashboards "github.com/integr8ly/integreatly-operator/pkg/products/monitoringcommon/dashboards"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/capacity"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var installationTypes = map[v1alpha1.InstallationType]string{
+	v1alpha1.InstallationTypeManaged:               "rhmi",
+	v1alpha1.InstallationTypeSelfManaged:           "rhmi",
+	v1alpha1.InstallationTypeWorkshop:              "rhmi",
+	v1alpha1.InstallationTypeManagedApi:            "rhoam",
+	v1alpha1.InstallationTypeMultitenantManagedApi: "rhoam",
+}
+
+// containerCPUMetrics are the container CPU usage metrics of the supported
+// cluster versions
+var containerCPUMetrics = []string{
+	"node_namespace_pod_container:container_cpu_usage_seconds_total:sum_rate",
+	"node_namespace_pod_container:container_cpu_usage_seconds_total:sum_irate",
+}
+
+// exporterMetrics are the metrics the dashboards can query that are produced
+// outside of the operator, by exporter
+var exporterMetrics = map[string][]string{
+	"prometheus": {
+		"ALERTS",
+	},
+	"kube-state-metrics": {
+		"kube_namespace_labels",
+		"kube_node_role",
+		"kube_node_status_allocatable",
+		"kube_node_status_capacity",
+		"kube_pod_container_resource_limits",
+		"kube_pod_container_resource_requests",
+		"kube_pod_info",
+	},
+	"cadvisor": {
+		"container_memory_rss",
+		"container_memory_working_set_bytes",
+	},
+	"cluster monitoring recording rules": append([]string{
+		"instance:node_cpu_utilisation:rate1m",
+		"instance:node_memory_utilisation:ratio",
+	}, containerCPUMetrics...),
+	"blackbox exporter": {
+		"probe_dns_lookup_time_seconds",
+		"probe_duration_seconds",
+		"probe_http_ssl",
+		"probe_http_status_code",
+		"probe_ssl_earliest_cert_expiry",
+		"probe_success",
+	},
+	"openshift router": {
+		"haproxy_backend_http_responses_total",
+	},
+	"cloud resource operator": {
+		"cro_postgres_available",
+		"cro_postgres_cpu_utilization_average",
+		"cro_postgres_current_allocated_storage",
+		"cro_postgres_free_storage_average",
+		"cro_postgres_freeable_memory_average",
+		"cro_postgres_max_memory",
+		"cro_redis_available",
+		"cro_redis_cpu_utilization_average",
+		"cro_redis_freeable_memory_average",
+		"cro_redis_memory_usage_percentage_average",
+	},
+}
+
+var descriptorName = regexp.MustCompile(`fqName: "([^"]+)"`)
+
+// knownMetrics returns the metrics produced by the exporters, the operator
+// and the recording rules of the operator
+func knownMetrics(t *testing.T) map[string]bool {
+	known := map[string]bool{}
+	for _, exported := range exporterMetrics {
+		for _, metric := range exported {
+			known[metric] = true
+		}
+	}
+	for _, rule := range capacity.RecordingRules() {
+		known[rule.Record] = true
+	}
+
+	collectors := []prometheus.Collector{
+		metrics.OperatorVersion, metrics.RHMIStatusAvailable, metrics.RHMIInfo, metrics.RHMIVersion,
+		metrics.RHMIStatus, metrics.RHOAMVersion, metrics.RHOAMStatus, metrics.ThreeScaleUserAction,
+		metrics.Quota, metrics.NumTenants, metrics.NoTenantRealm, metrics.NoActivated3ScaleTenantAccount,
+		metrics.ThreeScaleRequestDuration, metrics.ThreeScaleRequestRetries, metrics.UserSyncLag,
+		metrics.UserSyncPending, metrics.KeycloakRealmDrift, metrics.UserSyncFailures,
+		metrics.SecretLastRotation, metrics.SecretRotations, metrics.NetworkPolicyBlockedFlows,
+		metrics.SMTPProviderHealthy, metrics.StageReconcileDuration, metrics.ProductReconcileDuration,
+		metrics.ProductReconcileErrors, metrics.ProductPhaseTransitions, metrics.ProductPhaseDuration,
+		metrics.InstallationDuration, metrics.UpgradeDuration,
+	}
+	for _, collector := range collectors {
+		descriptors := make(chan *prometheus.Desc, 1)
+		go func() {
+			collector.Describe(descriptors)
+			close(descriptors)
+		}()
+		for descriptor := range descriptors {
+			match := descriptorName.FindStringSubmatch(descriptor.String())
+			if match == nil {
+				t.Fatalf("failed to read the name of metric %s", descriptor.String())
+			}
+			for _, suffix := range []string{"", "_bucket", "_sum", "_count"} {
+				known[match[1]+suffix] = true
+			}
+		}
+	}
+	return known
+}
+
+func TestDashboards(t *testing.T) {
+	known := knownMetrics(t)
+
+	for installType, installationName := range installationTypes {
+		for _, containerCPUMetric := range containerCPUMetrics {
+			params := dashboards.Params{
+				InstallationType:   installType,
+				InstallationName:   installationName,
+				NamespacePrefix:    "redhat-" + installationName + "-",
+				ContainerCPUMetric: containerCPUMetric,
+			}
+
+			for _, dashboard := range dashboards.ForInstallationType(installType) {
+				t.Run(string(installType)+"/"+dashboard.Name, func(t *testing.T) {
+					dashboardJSON, err := dashboard.Render(params)
+					if err != nil {
+						t.Fatalf("failed to render dashboard: %v", err)
+					}
+					if strings.Contains(dashboardJSON, "[[") {
+						t.Errorf("expected the template to be fully rendered")
+					}
+
+					queried, err := dashboards.Validate(dashboardJSON)
+					if err != nil {
+						t.Fatalf("invalid dashboard: %v", err)
+					}
+					for _, metric := range queried {
+						if !known[metric] {
+							t.Errorf("metric %s is not produced by a rule or exporter", metric)
+						}
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestDashboards_Variants(t *testing.T) {
+	for installType := range installationTypes {
+		names := map[string]bool{}
+		for _, dashboard := range dashboards.ForInstallationType(installType) {
+			names[dashboard.Name] = true
+		}
+
+		if len(names) != 10 {
+			t.Errorf("expected 10 dashboards for %s, got %v", installType, names)
+		}
+		managedAPI := v1alpha1.IsRHOAM(installType)
+		if names["critical-slo-managed-api-alerts"] != managedAPI || names["critical-slo-rhmi-alerts"] == managedAPI {
+			t.Errorf("expected the critical SLO dashboard variant of %s, got %v", installType, names)
+		}
+
+		dashboard, err := dashboards.GetDashboard("cluster-resources")
+		if err != nil {
+			t.Fatal(err)
+		}
+		dashboardJSON, err := dashboard.Render(dashboards.Params{InstallationType: installType, InstallationName: installationTypes[installType]})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(dashboardJSON, "rhoam_quota") != managedAPI {
+			t.Errorf("expected the quota annotations only for RHOAM installations, got them for %s: %v", installType, !managedAPI)
+		}
+	}
+}
+
+func TestGetDashboard(t *testing.T) {
+	if _, err := dashboards.GetDashboard("unknown"); err == nil {
+		t.Errorf("expected an error for an unknown dashboard")
+	}
+
+	dashboard, err := dashboards.GetDashboard("endpointsdetailed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dashboard.FileName != "endpointsdetailed.json" || len(dashboard.Plugins) != 1 {
+		t.Errorf("unexpected dashboard %v", dashboard)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		Name            string
+		Dashboard       interface{}
+		ExpectErr       string
+		ExpectedMetrics []string
+	}{
+		{
+			Name: "test queries of panels, annotations and variables are read",
+			Dashboard: map[string]interface{}{
+				"annotations": map[string]interface{}{"list": []interface{}{
+					map[string]interface{}{"datasource": "-- Grafana --"},
+					map[string]interface{}{"datasource": "Prometheus", "expr": "rhoam_version"},
+				}},
+				"panels": []interface{}{
+					map[string]interface{}{"title": "CPU", "datasource": "Prometheus", "targets": []interface{}{
+						map[string]interface{}{"expr": "sum(rate(cpu_seconds_total{namespace=~'$namespace'}[$__interval])) by (pod)"},
+					}},
+				},
+				"templating": map[string]interface{}{"list": []interface{}{
+					map[string]interface{}{"name": "pod", "type": "query", "query": "label_values(kube_pod_info{namespace=~'$namespace'}, pod)"},
+					map[string]interface{}{"name": "interval", "type": "interval", "query": "1m,10m"},
+				}},
+			},
+			ExpectedMetrics: []string{"cpu_seconds_total", "kube_pod_info", "rhoam_version"},
+		},
+		{
+			Name: "test unknown datasource",
+			Dashboard: map[string]interface{}{
+				"panels": []interface{}{map[string]interface{}{"title": "Logs", "datasource": "Loki"}},
+			},
+			ExpectErr: "/Logs: unknown datasource Loki",
+		},
+		{
+			Name: "test invalid query",
+			Dashboard: map[string]interface{}{
+				"panels": []interface{}{map[string]interface{}{"title": "CPU", "targets": []interface{}{
+					map[string]interface{}{"expr": "sum(rate(cpu_seconds_total[5m]) by (pod)"},
+				}}},
+			},
+			ExpectErr: "/CPU: invalid query",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			dashboardJSON, err := json.Marshal(tt.Dashboard)
+			if err != nil {
+				t.Fatal(err)
+			}
+			queried, err := dashboards.Validate(string(dashboardJSON))
+			if tt.ExpectErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.ExpectErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.ExpectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if strings.Join(queried, ",") != strings.Join(tt.ExpectedMetrics, ",") {
+				t.Errorf("expected metrics %v, got %v", tt.ExpectedMetrics, queried)
+			}
+		})
+	}
+}
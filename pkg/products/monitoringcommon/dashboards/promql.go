@@ -0,0 +1,399 @@
+package monitoringcommon
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// The PromQL parser checks the syntax of the dashboard queries and returns
+// the metrics they select. It covers the PromQL used by dashboards, with
+// Grafana variables ($var, ${var:format}) accepted wherever a number or a
+// duration is
+
+var promqlAggregations = map[string]bool{
+	"sum": true, "min": true, "max": true, "avg": true, "group": true, "stddev": true, "stdvar": true,
+	"count": true, "count_values": true, "bottomk": true, "topk": true, "quantile": true,
+}
+
+var promqlFunctions = map[string]bool{
+	"abs": true, "absent": true, "absent_over_time": true, "ceil": true, "changes": true, "clamp": true,
+	"clamp_max": true, "clamp_min": true, "day_of_month": true, "day_of_week": true, "days_in_month": true,
+	"delta": true, "deriv": true, "exp": true, "floor": true, "histogram_quantile": true, "holt_winters": true,
+	"hour": true, "idelta": true, "increase": true, "irate": true, "label_join": true, "label_replace": true,
+	"ln": true, "log2": true, "log10": true, "minute": true, "month": true, "predict_linear": true, "rate": true,
+	"resets": true, "round": true, "scalar": true, "sgn": true, "sort": true, "sort_desc": true, "sqrt": true,
+	"time": true, "timestamp": true, "vector": true, "year": true, "avg_over_time": true, "min_over_time": true,
+	"max_over_time": true, "sum_over_time": true, "count_over_time": true, "quantile_over_time": true,
+	"stddev_over_time": true, "stdvar_over_time": true, "last_over_time": true,
+}
+
+var promqlBinaryOperators = map[string]bool{
+	"+": true, "-": true, "*": true, "/": true, "%": true, "^": true,
+	"==": true, "!=": true, "<=": true, ">=": true, "<": true, ">": true,
+	"and": true, "or": true, "unless": true,
+}
+
+var promqlMatchOperators = map[string]bool{"=": true, "!=": true, "=~": true, "!~": true}
+
+type promqlTokenType int
+
+const (
+	tokenEOF promqlTokenType = iota
+	tokenIdentifier
+	tokenNumber
+	tokenDuration
+	tokenString
+	tokenVariable
+	tokenOperator
+)
+
+type promqlToken struct {
+	typ   promqlTokenType
+	value string
+	pos   int
+}
+
+// ParsePromQL checks the syntax of expr and returns the names of the metrics
+// it selects, sorted
+func ParsePromQL(expr string) ([]string, error) {
+	tokens, err := lexPromQL(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &promqlParser{tokens: tokens, metrics: map[string]bool{}}
+	if p.peek().typ == tokenEOF {
+		return nil, fmt.Errorf("empty expression")
+	}
+	if err := p.parseExpr(); err != nil {
+		return nil, err
+	}
+	if token := p.peek(); token.typ != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", token.value, token.pos)
+	}
+
+	metrics := []string{}
+	for metric := range p.metrics {
+		metrics = append(metrics, metric)
+	}
+	sort.Strings(metrics)
+	return metrics, nil
+}
+
+func lexPromQL(expr string) ([]promqlToken, error) {
+	tokens := []promqlToken{}
+	runes := []rune(expr)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case r == '"' || r == '\'':
+			i++
+			for i < len(runes) && runes[i] != r {
+				if runes[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated string at position %d", start)
+			}
+			i++
+			tokens = append(tokens, promqlToken{typ: tokenString, value: string(runes[start:i]), pos: start})
+		case r == '$':
+			i++
+			if i < len(runes) && runes[i] == '{' {
+				for i < len(runes) && runes[i] != '}' {
+					i++
+				}
+				if i >= len(runes) {
+					return nil, fmt.Errorf("unterminated variable at position %d", start)
+				}
+				i++
+			} else {
+				for i < len(runes) && (runes[i] == '_' || unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i])) {
+					i++
+				}
+			}
+			if i == start+1 {
+				return nil, fmt.Errorf("invalid variable at position %d", start)
+			}
+			tokens = append(tokens, promqlToken{typ: tokenVariable, value: string(runes[start:i]), pos: start})
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.' ||
+				((runes[i] == 'e' || runes[i] == 'E') && i+1 < len(runes) && (unicode.IsDigit(runes[i+1]) || runes[i+1] == '-' || runes[i+1] == '+'))) {
+				if runes[i] == 'e' || runes[i] == 'E' {
+					i++
+				}
+				i++
+			}
+			typ := tokenNumber
+			// durations like 5m, 1h30m or 500ms
+			for i < len(runes) && strings.ContainsRune("smhdwy", runes[i]) {
+				typ = tokenDuration
+				i++
+				for i < len(runes) && unicode.IsDigit(runes[i]) {
+					i++
+				}
+			}
+			if i < len(runes) && (runes[i] == '_' || unicode.IsLetter(runes[i])) {
+				return nil, fmt.Errorf("invalid number or duration at position %d", start)
+			}
+			tokens = append(tokens, promqlToken{typ: typ, value: string(runes[start:i]), pos: start})
+		// a leading colon is the step of a subquery
+		case r == '_' || unicode.IsLetter(r):
+			for i < len(runes) && (isIdentifierRune(runes[i]) || unicode.IsDigit(runes[i])) {
+				i++
+			}
+			tokens = append(tokens, promqlToken{typ: tokenIdentifier, value: string(runes[start:i]), pos: start})
+		default:
+			operator := string(r)
+			if i+1 < len(runes) {
+				if two := string(runes[i : i+2]); two == "==" || two == "!=" || two == "<=" || two == ">=" || two == "=~" || two == "!~" {
+					operator = two
+				}
+			}
+			if !strings.Contains("+-*/%^=!<>(){}[],:", operator[:1]) || operator == "!" {
+				return nil, fmt.Errorf("unexpected character %q at position %d", r, start)
+			}
+			i += len(operator)
+			tokens = append(tokens, promqlToken{typ: tokenOperator, value: operator, pos: start})
+		}
+	}
+	return append(tokens, promqlToken{typ: tokenEOF, pos: len(runes)}), nil
+}
+
+func isIdentifierRune(r rune) bool {
+	return r == '_' || r == ':' || unicode.IsLetter(r)
+}
+
+type promqlParser struct {
+	tokens  []promqlToken
+	pos     int
+	metrics map[string]bool
+}
+
+func (p *promqlParser) peek() promqlToken {
+	return p.tokens[p.pos]
+}
+
+func (p *promqlParser) next() promqlToken {
+	token := p.tokens[p.pos]
+	if token.typ != tokenEOF {
+		p.pos++
+	}
+	return token
+}
+
+func (p *promqlParser) accept(value string) bool {
+	if token := p.peek(); (token.typ == tokenOperator || token.typ == tokenIdentifier) && token.value == value {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *promqlParser) expect(value string) error {
+	if !p.accept(value) {
+		token := p.peek()
+		if token.typ == tokenEOF {
+			return fmt.Errorf("expected %q, got end of expression", value)
+		}
+		return fmt.Errorf("expected %q, got %q at position %d", value, token.value, token.pos)
+	}
+	return nil
+}
+
+// parseExpr parses operands separated by binary operators. The precedence of
+// the operators doesn't matter to check the syntax
+func (p *promqlParser) parseExpr() error {
+	if err := p.parseUnary(); err != nil {
+		return err
+	}
+	for {
+		token := p.peek()
+		if (token.typ != tokenOperator && token.typ != tokenIdentifier) || !promqlBinaryOperators[token.value] {
+			return nil
+		}
+		p.next()
+		p.accept("bool")
+		if p.accept("on") || p.accept("ignoring") {
+			if err := p.parseLabelList(); err != nil {
+				return err
+			}
+		}
+		if p.accept("group_left") || p.accept("group_right") {
+			if p.peek().value == "(" {
+				if err := p.parseLabelList(); err != nil {
+					return err
+				}
+			}
+		}
+		if err := p.parseUnary(); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *promqlParser) parseUnary() error {
+	if p.accept("-") || p.accept("+") {
+		return p.parseUnary()
+	}
+	if err := p.parsePrimary(); err != nil {
+		return err
+	}
+	// range vectors, subqueries and offsets
+	for {
+		switch {
+		case p.accept("["):
+			if err := p.parseDuration(); err != nil {
+				return err
+			}
+			if p.accept(":") && p.peek().value != "]" {
+				if err := p.parseDuration(); err != nil {
+					return err
+				}
+			}
+			if err := p.expect("]"); err != nil {
+				return err
+			}
+		case p.accept("offset"):
+			p.accept("-")
+			if err := p.parseDuration(); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+	}
+}
+
+func (p *promqlParser) parseDuration() error {
+	token := p.next()
+	if token.typ != tokenDuration && token.typ != tokenVariable {
+		return fmt.Errorf("expected a duration, got %q at position %d", token.value, token.pos)
+	}
+	return nil
+}
+
+func (p *promqlParser) parsePrimary() error {
+	token := p.next()
+	switch token.typ {
+	case tokenNumber, tokenString, tokenVariable:
+		return nil
+	case tokenOperator:
+		switch token.value {
+		case "(":
+			if err := p.parseExpr(); err != nil {
+				return err
+			}
+			return p.expect(")")
+		case "{":
+			p.pos--
+			return p.parseMatchers()
+		}
+	case tokenIdentifier:
+		switch {
+		case promqlAggregations[token.value]:
+			return p.parseAggregation()
+		case p.peek().value == "(":
+			if !promqlFunctions[token.value] {
+				return fmt.Errorf("unknown function %s at position %d", token.value, token.pos)
+			}
+			return p.parseArguments()
+		case promqlBinaryOperators[token.value] || token.value == "by" || token.value == "without" ||
+			token.value == "on" || token.value == "ignoring" || token.value == "offset" || token.value == "bool":
+			return fmt.Errorf("unexpected keyword %s at position %d", token.value, token.pos)
+		}
+		p.metrics[token.value] = true
+		if p.peek().value == "{" {
+			return p.parseMatchers()
+		}
+		return nil
+	}
+	if token.typ == tokenEOF {
+		return fmt.Errorf("unexpected end of expression")
+	}
+	return fmt.Errorf("unexpected %q at position %d", token.value, token.pos)
+}
+
+func (p *promqlParser) parseAggregation() error {
+	grouped := false
+	if p.accept("by") || p.accept("without") {
+		grouped = true
+		if err := p.parseLabelList(); err != nil {
+			return err
+		}
+	}
+	if err := p.parseArguments(); err != nil {
+		return err
+	}
+	if !grouped && (p.accept("by") || p.accept("without")) {
+		return p.parseLabelList()
+	}
+	return nil
+}
+
+func (p *promqlParser) parseArguments() error {
+	if err := p.expect("("); err != nil {
+		return err
+	}
+	if p.accept(")") {
+		return nil
+	}
+	for {
+		if err := p.parseExpr(); err != nil {
+			return err
+		}
+		if p.accept(")") {
+			return nil
+		}
+		if err := p.expect(","); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *promqlParser) parseLabelList() error {
+	if err := p.expect("("); err != nil {
+		return err
+	}
+	for !p.accept(")") {
+		if token := p.next(); token.typ != tokenIdentifier {
+			return fmt.Errorf("expected a label name, got %q at position %d", token.value, token.pos)
+		}
+		if p.peek().value != ")" {
+			if err := p.expect(","); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (p *promqlParser) parseMatchers() error {
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	for !p.accept("}") {
+		if token := p.next(); token.typ != tokenIdentifier {
+			return fmt.Errorf("expected a label name, got %q at position %d", token.value, token.pos)
+		}
+		if token := p.next(); token.typ != tokenOperator || !promqlMatchOperators[token.value] {
+			return fmt.Errorf("expected a label matcher, got %q at position %d", token.value, token.pos)
+		}
+		if token := p.next(); token.typ != tokenString {
+			return fmt.Errorf("expected a label value, got %q at position %d", token.value, token.pos)
+		}
+		if p.peek().value != "}" {
+			if err := p.expect(","); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package monitoringcommon_test
+
+import (
+	"strings"
+	"testing"
+
+	dashboards "github.com/integr8ly/integreatly-operator/pkg/products/monitoringcommon/dashboards"
+)
+
+func TestParsePromQL(t *testing.T) {
+	tests := []struct {
+		Name            string
+		Expr            string
+		ExpectErr       bool
+		ExpectedMetrics []string
+	}{
+		{
+			Name:            "test selector",
+			Expr:            `probe_success{service=~"$services"}`,
+			ExpectedMetrics: []string{"probe_success"},
+		},
+		{
+			Name:            "test recording rule",
+			Expr:            "1 - avg(instance:node_cpu_utilisation:rate1m)",
+			ExpectedMetrics: []string{"instance:node_cpu_utilisation:rate1m"},
+		},
+		{
+			Name:            "test aggregation and vector matching",
+			Expr:            `sum by (namespace) (container_memory_rss{container!=''}) / on(node) group_left (instance) kube_node_role{role="worker"} > bool 0`,
+			ExpectedMetrics: []string{"container_memory_rss", "kube_node_role"},
+		},
+		{
+			Name:            "test subquery and grafana variables",
+			Expr:            "$slo_001_ms - sum_over_time((clamp_max(sum(ALERTS{alertstate='firing'}), 1))[28d:10m]) * (10 * 60 * 1e3) or vector(0)",
+			ExpectedMetrics: []string{"ALERTS"},
+		},
+		{
+			Name:            "test range vector with offset",
+			Expr:            "rate(http_requests_total[$__rate_interval] offset 1h30m)",
+			ExpectedMetrics: []string{"http_requests_total"},
+		},
+		{
+			Name:            "test functions and strings",
+			Expr:            `label_replace(up, "node", "$1", "instance", "(.*)") * time()`,
+			ExpectedMetrics: []string{"up"},
+		},
+		{
+			Name:      "test unbalanced parentheses",
+			Expr:      "sum(rate(up[5m])",
+			ExpectErr: true,
+		},
+		{
+			Name:      "test unterminated string",
+			Expr:      `up{job="prometheus}`,
+			ExpectErr: true,
+		},
+		{
+			Name:      "test invalid matcher",
+			Expr:      `up{job}`,
+			ExpectErr: true,
+		},
+		{
+			Name:      "test unknown function",
+			Expr:      "rates(up[5m])",
+			ExpectErr: true,
+		},
+		{
+			Name:      "test invalid range",
+			Expr:      "rate(up[5])",
+			ExpectErr: true,
+		},
+		{
+			Name:      "test missing operand",
+			Expr:      "sum(up) /",
+			ExpectErr: true,
+		},
+		{
+			Name:      "test empty expression",
+			Expr:      " ",
+			ExpectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			metrics, err := dashboards.ParsePromQL(tt.Expr)
+			if tt.ExpectErr {
+				if err == nil {
+					t.Fatalf("expected an error, got metrics %v", metrics)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if strings.Join(metrics, ",") != strings.Join(tt.ExpectedMetrics, ",") {
+				t.Errorf("expected metrics %v, got %v", tt.ExpectedMetrics, metrics)
+			}
+		})
+	}
+}
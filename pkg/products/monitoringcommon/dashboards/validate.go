@@ -0,0 +1,125 @@
+package monitoringcommon
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PrometheusDatasource is the name of the datasource of the monitoring
+// Grafana
+const PrometheusDatasource = "Prometheus"
+
+// builtInDatasources are the datasources provided by Grafana itself, used by
+// the annotations of alerts and dashboard events
+var builtInDatasources = map[string]bool{
+	"-- Grafana --":   true,
+	"-- Dashboard --": true,
+	"-- Mixed --":     true,
+}
+
+// Validate checks the JSON model of a rendered dashboard. Every datasource
+// reference must be the Prometheus datasource or a built in Grafana
+// datasource, and every query of the panels, annotations and variables must be
+// valid PromQL. It returns the metrics queried by the dashboard, sorted
+func Validate(dashboardJSON string) ([]string, error) {
+	model := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(dashboardJSON), &model); err != nil {
+		return nil, fmt.Errorf("invalid json: %w", err)
+	}
+
+	v := &validator{metrics: map[string]bool{}}
+	v.walk("", model)
+
+	if len(v.errors) > 0 {
+		return nil, fmt.Errorf("%s", strings.Join(v.errors, "; "))
+	}
+	metrics := []string{}
+	for metric := range v.metrics {
+		metrics = append(metrics, metric)
+	}
+	sort.Strings(metrics)
+	return metrics, nil
+}
+
+type validator struct {
+	metrics map[string]bool
+	errors  []string
+}
+
+func (v *validator) walk(path string, value interface{}) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		if title, ok := value["title"].(string); ok && title != "" {
+			path = fmt.Sprintf("%s/%s", path, title)
+		} else if name, ok := value["name"].(string); ok && name != "" {
+			path = fmt.Sprintf("%s/%s", path, name)
+		}
+		for key, field := range value {
+			switch key {
+			case "datasource":
+				v.checkDatasource(path, field)
+			case "expr":
+				if expr, ok := field.(string); ok {
+					v.checkQuery(path, expr)
+				}
+			case "query":
+				// the queries of variables
+				if expr, ok := field.(string); ok && value["type"] == "query" {
+					v.checkVariableQuery(path, expr)
+				}
+			default:
+				v.walk(path, field)
+			}
+		}
+	case []interface{}:
+		for _, item := range value {
+			v.walk(path, item)
+		}
+	}
+}
+
+func (v *validator) checkDatasource(path string, datasource interface{}) {
+	switch datasource := datasource.(type) {
+	case nil:
+		return
+	case string:
+		if datasource == PrometheusDatasource || builtInDatasources[datasource] || strings.HasPrefix(datasource, "$") {
+			return
+		}
+	}
+	v.errors = append(v.errors, fmt.Sprintf("%s: unknown datasource %v, expected %s", path, datasource, PrometheusDatasource))
+}
+
+func (v *validator) checkQuery(path, expr string) {
+	metrics, err := ParsePromQL(expr)
+	if err != nil {
+		v.errors = append(v.errors, fmt.Sprintf("%s: invalid query %q: %v", path, expr, err))
+		return
+	}
+	for _, metric := range metrics {
+		v.metrics[metric] = true
+	}
+}
+
+// checkVariableQuery checks the queries of variables, which can use the
+// label_values and query_result functions of the Grafana Prometheus
+// datasource
+func (v *validator) checkVariableQuery(path, query string) {
+	query = strings.TrimSpace(query)
+	switch {
+	case strings.HasPrefix(query, "label_values(") && strings.HasSuffix(query, ")"):
+		args := strings.TrimSuffix(strings.TrimPrefix(query, "label_values("), ")")
+		// label_values(label) or label_values(expr, label)
+		if i := strings.LastIndex(args, ","); i >= 0 {
+			v.checkQuery(path, args[:i])
+		}
+	case strings.HasPrefix(query, "query_result(") && strings.HasSuffix(query, ")"):
+		v.checkQuery(path, strings.TrimSuffix(strings.TrimPrefix(query, "query_result("), ")"))
+	case strings.HasPrefix(query, "label_names(") || strings.HasPrefix(query, "metrics("):
+		return
+	default:
+		v.checkQuery(path, query)
+	}
+}
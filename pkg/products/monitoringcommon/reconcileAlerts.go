@@ -0,0 +1,63 @@
+package monitoringcommon
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/integr8ly/integreatly-operator/pkg/resources"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// productPhaseStuckThreshold is the time a product can stay in a phase
+	// other than completed before it's reported stuck
+	productPhaseStuckThreshold = "3600"
+	// upgradeDurationThreshold is the time an upgrade can take before it's
+	// reported
+	upgradeDurationThreshold = "7200"
+)
+
+// ReconcileAlertRules returns the alerts on the performance of the reconciles
+// of the installation, from the metrics recorded by the installation
+// controller
+func ReconcileAlertRules(installationName string) []monitoringv1.Rule {
+	prefix := strings.ToUpper(installationName)
+	// the product alerts keep the product label of their series, a product
+	// label set by the rule would make the alerts of the products clash
+	productLabels := map[string]string{"severity": "warning"}
+	labels := map[string]string{"severity": "warning", "product": installationName}
+
+	return []monitoringv1.Rule{
+		{
+			Alert: fmt.Sprintf("%sProductReconcileStuck", prefix),
+			Annotations: map[string]string{
+				"sop_url": resources.SopUrlAlertsAndTroubleshooting,
+				"message": "The reconcile of {{ $labels.product }} has been in phase '{{ $labels.phase }}' for more than an hour",
+			},
+			Expr:   intstr.FromString(fmt.Sprintf("integreatly_operator_product_phase_duration_seconds{phase!='completed'} > %s", productPhaseStuckThreshold)),
+			For:    "5m",
+			Labels: productLabels,
+		},
+		{
+			Alert: fmt.Sprintf("%sProductReconcileFailing", prefix),
+			Annotations: map[string]string{
+				"sop_url": resources.SopUrlAlertsAndTroubleshooting,
+				"message": "The reconciles of {{ $labels.product }} have been returning errors for the last 30 minutes",
+			},
+			Expr:   intstr.FromString("rate(integreatly_operator_product_reconcile_errors_total[10m]) > 0"),
+			For:    "30m",
+			Labels: productLabels,
+		},
+		{
+			Alert: fmt.Sprintf("%sUpgradeTakingTooLong", prefix),
+			Annotations: map[string]string{
+				"sop_url": resources.SopUrlAlertsAndTroubleshooting,
+				"message": fmt.Sprintf("The upgrade of %s to {{ $labels.to_version }} has been in progress for more than 2 hours", prefix),
+			},
+			Expr:   intstr.FromString(fmt.Sprintf("integreatly_operator_upgrade_duration_seconds > %s and on(to_version) %s_version{to_version!=''}", upgradeDurationThreshold, installationName)),
+			For:    "5m",
+			Labels: labels,
+		},
+	}
+}
@@ -2,7 +2,9 @@ package observability
 
 import (
 	"fmt"
+	"github.com/integr8ly/integreatly-operator/pkg/products/monitoringcommon"
 	"github.com/integr8ly/integreatly-operator/pkg/resources"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/capacity"
 	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -302,6 +304,43 @@ func (r *Reconciler) newAlertsReconciler(logger l.Logger, installType string) re
 					},
 				},
 			},
+			{
+				AlertName: "smtp-health-alerts",
+				Namespace: namespace,
+				GroupName: "smtp-health.rules",
+				Rules: []monitoringv1.Rule{
+					{
+						Alert: "RHOAMSMTPProvidersUnhealthy",
+						Annotations: map[string]string{
+							"message": "None of the SMTP providers accepted an authenticated handshake for the last 15 minutes. Email can't be sent.",
+						},
+						Expr:   intstr.FromString("max(rhoam_smtp_provider_healthy) == 0"),
+						For:    "15m",
+						Labels: map[string]string{"severity": "critical", "product": installationName},
+					},
+					{
+						Alert: "RHOAMSMTPFailoverActive",
+						Annotations: map[string]string{
+							"message": "The primary SMTP provider {{ $labels.secret }} is unhealthy. Email is sent with a failover provider.",
+						},
+						Expr:   intstr.FromString("rhoam_smtp_provider_healthy{priority='0'} == 0 and on() max(rhoam_smtp_provider_healthy) == 1"),
+						For:    "15m",
+						Labels: map[string]string{"severity": "warning", "product": installationName},
+					},
+				},
+			},
+			{
+				AlertName: "capacity-forecast-rules",
+				Namespace: namespace,
+				GroupName: "capacity-forecast.rules",
+				Rules:     capacity.RecordingRules(),
+			},
+			{
+				AlertName: fmt.Sprintf("%s-reconcile-performance-alerts", installationName),
+				Namespace: namespace,
+				GroupName: fmt.Sprintf("%s-reconcile-performance.rules", installationName),
+				Rules:     monitoringcommon.ReconcileAlertRules(installationName),
+			},
 			{
 				AlertName: "test-alerts",
 				Namespace: namespace,
@@ -33,6 +33,7 @@ import (
 	"github.com/integr8ly/integreatly-operator/pkg/resources"
 	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/marketplace"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/tracing"
 	"net/http"
 
 	"github.com/integr8ly/integreatly-operator/pkg/products/amqonline"
@@ -97,7 +98,7 @@ type Interface interface {
 	VerifyVersion(installation *integreatlyv1alpha1.RHMI) bool
 }
 
-func NewReconciler(product integreatlyv1alpha1.ProductName, rc *rest.Config, configManager config.ConfigReadWriter, installation *integreatlyv1alpha1.RHMI, mgr manager.Manager, log l.Logger, productsInstalllationLoader marketplace.ProductsInstallationLoader) (reconciler Interface, err error) {
+func NewReconciler(ctx context.Context, product integreatlyv1alpha1.ProductName, rc *rest.Config, configManager config.ConfigReadWriter, installation *integreatlyv1alpha1.RHMI, mgr manager.Manager, log l.Logger, productsInstalllationLoader marketplace.ProductsInstallationLoader) (reconciler Interface, err error) {
 	mpm := marketplace.NewManager()
 	oauthHttpClient := &http.Client{
 		Timeout: time.Second * 10,
@@ -186,11 +187,11 @@ func NewReconciler(product integreatlyv1alpha1.ProductName, rc *rest.Config, con
 
 		httpc := &http.Client{
 			Timeout: time.Second * 10,
-			Transport: &http.Transport{
+			Transport: l.NewTransport(ctx, tracing.NewTransport(ctx, &http.Transport{
 				DisableKeepAlives: true,
 				IdleConnTimeout:   time.Second * 10,
 				TLSClientConfig:   &tls.Config{InsecureSkipVerify: installation.Spec.SelfSignedCerts},
-			},
+			})),
 		}
 
 		tsClient := threescale.NewThreeScaleClient(httpc, installation.Spec.RoutingSubdomain)
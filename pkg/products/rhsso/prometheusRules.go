@@ -164,6 +164,22 @@ func (r *Reconciler) newAlertsReconciler(logger l.Logger, installType string) re
 					},
 				},
 			},
+			{
+				AlertName: "rhsso-realm-drift-alerts",
+				Namespace: operatorNamespace,
+				GroupName: "rhsso-realm-drift.rules",
+				Rules: []monitoringv1.Rule{
+					{
+						Alert: "RHMIRhssoRealmSettingsDrift",
+						Annotations: map[string]string{
+							"message": fmt.Sprintf("{{ $value }} managed {{ $labels.kind }} settings of the RHSSO {{ $labels.realm }} realm differ from the desired state. See the {{ $labels.realm }}-realm-export config map in namespace %s for details", r.Config.GetNamespace()),
+						},
+						Expr:   intstr.FromString(fmt.Sprintf(`sum by (realm, kind) (rhoam_keycloak_realm_drift{realm='%s'}) > 0`, keycloakRealmName)),
+						For:    "15m",
+						Labels: map[string]string{"severity": "warning", "product": installationName},
+					},
+				},
+			},
 		},
 	}
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/integr8ly/integreatly-operator/pkg/resources/events"
 	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/quota"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/secretprovider"
 	userHelper "github.com/integr8ly/integreatly-operator/pkg/resources/user"
 	"github.com/integr8ly/integreatly-operator/version"
 	olmv1alpha1 "github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators/v1alpha1"
@@ -406,6 +407,19 @@ func (r *Reconciler) reconcileComponents(ctx context.Context, installation *inte
 		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to sync openshift idp client secret: %w", err)
 	}
 
+	phase, err := r.ReconcileIdentityProviders(ctx, serverClient, kc, r.Config, r.Config.RHSSOCommon, keycloakRealmName, integreatlyv1alpha1.IdentityProviderInstanceRHSSO)
+	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+		return phase, err
+	}
+
+	if err := r.RotateAdminCredentials(ctx, serverClient, kc); err != nil {
+		return integreatlyv1alpha1.PhaseInProgress, fmt.Errorf("failed to rotate keycloak admin credentials: %w", err)
+	}
+
+	if driftErr := r.ReconcileRealmDrift(ctx, serverClient, kc, r.Config.RHSSOCommon, r.Config.GetNamespace(), keycloakRealmName, getDesiredRealmSettings()); driftErr != nil {
+		r.Log.Error("Failed to export realm settings", driftErr)
+	}
+
 	// Get all currently existing keycloak users
 	keycloakUsers, err := GetKeycloakUsers(ctx, serverClient, r.Config.GetNamespace())
 	if err != nil {
@@ -430,8 +444,13 @@ func (r *Reconciler) reconcileComponents(ctx context.Context, installation *inte
 }
 
 func (r *Reconciler) setupGithubIDP(ctx context.Context, kc *keycloak.Keycloak, kcr *keycloak.KeycloakRealm, serverClient k8sclient.Client, installation *integreatlyv1alpha1.RHMI) error {
+	secretProvider, err := secretprovider.New(serverClient, r.ConfigManager.GetOperatorNamespace())
+	if err != nil {
+		return fmt.Errorf("failed to get secret provider: %w", err)
+	}
+
 	githubCreds := &corev1.Secret{}
-	err := serverClient.Get(ctx, k8sclient.ObjectKey{Name: r.ConfigManager.GetGHOauthClientsSecretName(), Namespace: r.ConfigManager.GetOperatorNamespace()}, githubCreds)
+	githubCreds.Data, err = secretProvider.GetSecret(ctx, r.ConfigManager.GetGHOauthClientsSecretName())
 	if err != nil {
 		r.Log.Errorf("Unable to find Github oauth credentials secret", l.Fields{"ns": r.ConfigManager.GetOperatorNamespace()}, err)
 		return err
@@ -716,6 +735,24 @@ func createAuthDelayAuthenticationFlow(authenticated keycloakCommon.KeycloakInte
 	return nil
 }
 
+// getDesiredRealmSettings returns the realm settings set up by
+// createAuthDelayAuthenticationFlow, to be checked for drift
+func getDesiredRealmSettings() *rhssocommon.RealmSettings {
+	return &rhssocommon.RealmSettings{
+		AuthenticationFlows: []rhssocommon.RealmAuthenticationFlow{
+			{
+				Alias: authFlowAlias,
+				Executions: []rhssocommon.RealmExecution{
+					{ProviderID: "delay-authentication", Requirement: string(keycloakCommon.Required)},
+				},
+			},
+		},
+		IdentityProviders: []rhssocommon.RealmIdentityProvider{
+			{Alias: idpAlias, Enabled: true, FirstBrokerLoginFlowAlias: authFlowAlias},
+		},
+	}
+}
+
 func getKeycloakRoles(installationType integreatlyv1alpha1.InstallationType) map[string][]string {
 	var roles map[string][]string
 	if integreatlyv1alpha1.IsRHOAMMultitenant(integreatlyv1alpha1.InstallationType(installationType)) {
@@ -0,0 +1,64 @@
+package rhssocommon
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/integr8ly/integreatly-operator/pkg/resources"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/secretrotation"
+	keycloak "github.com/keycloak/keycloak-operator/pkg/apis/keycloak/v1alpha1"
+	"github.com/keycloak/keycloak-operator/pkg/model"
+	corev1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const adminRealmName = "master"
+
+// RotateAdminCredentials sets a new password on the admin user of the master
+// realm once the password in the credential secret of the Keycloak instance
+// is older than its rotation policy, and records it in the secret
+func (r *Reconciler) RotateAdminCredentials(ctx context.Context, serverClient k8sclient.Client, kc *keycloak.Keycloak) error {
+	// the secret is created by the keycloak operator
+	if kc.Status.CredentialSecret == "" {
+		return nil
+	}
+	adminCreds := &corev1.Secret{}
+	err := serverClient.Get(ctx, k8sclient.ObjectKey{Name: kc.Status.CredentialSecret, Namespace: kc.Namespace}, adminCreds)
+	if k8serr.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get the keycloak admin credentials: %w", err)
+	}
+
+	var adminClient KeycloakAdminInterface
+	policy := secretrotation.PolicyFor(r.Installation, adminCreds.Name)
+	annotation := adminCreds.Annotations[secretrotation.LastRotatedAnnotation]
+	rotated, rotateErr := secretrotation.RotateWith(adminCreds, policy, func(string) ([]byte, error) {
+		// the client logs in with the current password, so it's created
+		// before the password is reset
+		if adminClient == nil {
+			client, err := r.KeycloakAdminClientFactory(ctx, serverClient, kc)
+			if err != nil {
+				return nil, err
+			}
+			adminClient = client
+		}
+		pw := resources.GenerateRandomPassword(20, 2, 2, 2)
+		if err := adminClient.ResetUserPassword(adminRealmName, string(adminCreds.Data[model.AdminUsernameProperty]), pw); err != nil {
+			return nil, err
+		}
+		return []byte(pw), nil
+	}, time.Now())
+
+	// the password already set in keycloak is recorded even if the rotation
+	// failed afterwards
+	if len(rotated) > 0 || adminCreds.Annotations[secretrotation.LastRotatedAnnotation] != annotation {
+		if err := serverClient.Update(ctx, adminCreds); err != nil {
+			return fmt.Errorf("failed to update the keycloak admin credentials: %w", err)
+		}
+	}
+	return rotateErr
+}
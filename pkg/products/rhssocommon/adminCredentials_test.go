@@ -0,0 +1,122 @@
+package rhssocommon
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/secretrotation"
+	keycloak "github.com/keycloak/keycloak-operator/pkg/apis/keycloak/v1alpha1"
+	"github.com/keycloak/keycloak-operator/pkg/model"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconciler_RotateAdminCredentials(t *testing.T) {
+	scheme, err := getBuildScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kc := &keycloak.Keycloak{
+		ObjectMeta: metav1.ObjectMeta{Name: "rhsso", Namespace: "rhsso"},
+		Status:     keycloak.KeycloakStatus{CredentialSecret: secretrotation.RHSSOAdminCredentialsName},
+	}
+	newAdminCreds := func(lastRotated string) *corev1.Secret {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: kc.Status.CredentialSecret, Namespace: kc.Namespace},
+			Data: map[string][]byte{
+				model.AdminUsernameProperty: []byte("admin"),
+				model.AdminPasswordProperty: []byte("old"),
+			},
+		}
+		if lastRotated != "" {
+			secret.Annotations = map[string]string{
+				secretrotation.LastRotatedAnnotation: fmt.Sprintf(`{%q:%q}`, model.AdminPasswordProperty, lastRotated),
+			}
+		}
+		return secret
+	}
+
+	cases := []struct {
+		Name          string
+		AdminCreds    *corev1.Secret
+		ResetErr      error
+		ExpectErr     bool
+		ExpectReset   bool
+		ExpectRecords bool
+	}{
+		{
+			Name:          "test the expired password is reset and recorded",
+			AdminCreds:    newAdminCreds("2021-01-01T00:00:00Z"),
+			ExpectReset:   true,
+			ExpectRecords: true,
+		},
+		{
+			Name:          "test the password without a recorded rotation is recorded but not reset",
+			AdminCreds:    newAdminCreds(""),
+			ExpectRecords: true,
+		},
+		{
+			Name:          "test the password is kept when the reset fails",
+			AdminCreds:    newAdminCreds("2021-01-01T00:00:00Z"),
+			ResetErr:      fmt.Errorf("unauthorized"),
+			ExpectErr:     true,
+			ExpectRecords: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var reset []string
+			adminClient := &KeycloakAdminInterfaceMock{
+				ResetUserPasswordFunc: func(realmName string, username string, password string) error {
+					if tc.ResetErr != nil {
+						return tc.ResetErr
+					}
+					reset = append(reset, realmName, username, password)
+					return nil
+				},
+			}
+			serverClient := fakeclient.NewFakeClientWithScheme(scheme, tc.AdminCreds)
+			r := &Reconciler{
+				Installation: &integreatlyv1alpha1.RHMI{},
+				Log:          getLogger(),
+				KeycloakAdminClientFactory: func(ctx context.Context, serverClient k8sclient.Client, kc *keycloak.Keycloak) (KeycloakAdminInterface, error) {
+					return adminClient, nil
+				},
+			}
+
+			err := r.RotateAdminCredentials(context.TODO(), serverClient, kc)
+			if (err != nil) != tc.ExpectErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			secret := &corev1.Secret{}
+			if err := serverClient.Get(context.TODO(), k8sclient.ObjectKey{Name: kc.Status.CredentialSecret, Namespace: kc.Namespace}, secret); err != nil {
+				t.Fatal(err)
+			}
+			password := string(secret.Data[model.AdminPasswordProperty])
+			if tc.ExpectReset {
+				if len(reset) != 3 || reset[0] != "master" || reset[1] != "admin" || reset[2] != password {
+					t.Fatalf("expected the password of the admin user of the master realm to be reset to %s, got %v", password, reset)
+				}
+			} else if len(reset) != 0 || password != "old" {
+				t.Fatalf("expected the password not to be reset, got %v and %s", reset, password)
+			}
+			if _, ok := secretrotation.LastRotated(secret)[model.AdminPasswordProperty]; ok != tc.ExpectRecords {
+				t.Fatalf("expected the rotation of the password to be recorded: %v, got %v", tc.ExpectRecords, secret.Annotations)
+			}
+		})
+	}
+
+	t.Run("test the rotation is skipped until the keycloak operator creates the credentials", func(t *testing.T) {
+		r := &Reconciler{Installation: &integreatlyv1alpha1.RHMI{}, Log: getLogger()}
+		if err := r.RotateAdminCredentials(context.TODO(), fakeclient.NewFakeClientWithScheme(scheme), kc); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
@@ -0,0 +1,422 @@
+package rhssocommon
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/config"
+	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+	keycloakCommon "github.com/integr8ly/keycloak-client/pkg/common"
+	keycloak "github.com/keycloak/keycloak-operator/pkg/apis/keycloak/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	rhmiConfigName                   = "rhmi-config"
+	defaultFirstBrokerLoginFlowAlias = "first broker login"
+
+	// credentialsHashConfigKey holds the hash of the credentials secret of a
+	// provider. Keycloak masks the secrets it returns, so the hash is used to
+	// find out whether the credentials have to be rotated
+	credentialsHashConfigKey = "credentialsHash"
+)
+
+// KeycloakAdminClientFactory returns a client of the admin API of a Keycloak
+// instance
+type KeycloakAdminClientFactory func(ctx context.Context, serverClient k8sclient.Client, kc *keycloak.Keycloak) (KeycloakAdminInterface, error)
+
+// ReconcileIdentityProviders sets up the external identity providers of the
+// RHMIConfig in the realm of the given instance, and removes the ones that
+// were set up previously but are no longer configured. The providers set up
+// are recorded in the product config
+func (r *Reconciler) ReconcileIdentityProviders(ctx context.Context, serverClient k8sclient.Client, kc *keycloak.Keycloak, productConfig config.ConfigReadable, ssoCommon *config.RHSSOCommon, realmName, instance string) (integreatlyv1alpha1.StatusPhase, error) {
+	providers, err := getIdentityProviders(ctx, serverClient, r.ConfigManager.GetOperatorNamespace(), instance)
+	if err != nil {
+		return integreatlyv1alpha1.PhaseFailed, err
+	}
+
+	managed := ssoCommon.GetIdentityProviders()
+	if len(providers) == 0 && len(managed) == 0 {
+		return integreatlyv1alpha1.PhaseCompleted, nil
+	}
+
+	kcClient, err := r.KeycloakClientFactory.AuthenticatedClient(*kc)
+	if err != nil {
+		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to authenticate client in keycloak api %w", err)
+	}
+	adminClient, err := r.KeycloakAdminClientFactory(ctx, serverClient, kc)
+	if err != nil {
+		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to authenticate admin client in keycloak api %w", err)
+	}
+
+	desired := []string{}
+	for _, provider := range providers {
+		secret := &corev1.Secret{}
+		if err := serverClient.Get(ctx, k8sclient.ObjectKey{Name: provider.SecretRef, Namespace: r.ConfigManager.GetOperatorNamespace()}, secret); err != nil {
+			return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to get credentials of identity provider %s: %w", provider.Alias, err)
+		}
+
+		if provider.Type == integreatlyv1alpha1.IdentityProviderTypeLDAP {
+			err = reconcileLDAPProvider(adminClient, provider, secret, realmName)
+		} else {
+			err = reconcileBrokerProvider(kcClient, adminClient, provider, secret, realmName)
+		}
+		if err != nil {
+			return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to reconcile identity provider %s: %w", provider.Alias, err)
+		}
+		r.Log.Infof("Reconciled identity provider", l.Fields{"alias": provider.Alias, "realm": realmName})
+
+		desired = append(desired, managedIdentityProvider(provider.Type, provider.Alias))
+	}
+
+	for _, entry := range managed {
+		if containsString(desired, entry) {
+			continue
+		}
+		if err := deleteIdentityProvider(kcClient, adminClient, entry, realmName); err != nil {
+			return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to delete identity provider %s: %w", entry, err)
+		}
+		r.Log.Infof("Deleted identity provider", l.Fields{"provider": entry, "realm": realmName})
+	}
+
+	sort.Strings(desired)
+	if !reflect.DeepEqual(desired, managed) {
+		ssoCommon.SetIdentityProviders(desired)
+		if err := r.ConfigManager.WriteConfig(productConfig); err != nil {
+			return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("error writing identity providers to config: %w", err)
+		}
+	}
+
+	return integreatlyv1alpha1.PhaseCompleted, nil
+}
+
+// getIdentityProviders returns the identity providers of the RHMIConfig that
+// are set up in the given instance
+func getIdentityProviders(ctx context.Context, serverClient k8sclient.Client, ns, instance string) ([]integreatlyv1alpha1.IdentityProvider, error) {
+	rhmiConfig := &integreatlyv1alpha1.RHMIConfig{}
+	err := serverClient.Get(ctx, k8sclient.ObjectKey{Name: rhmiConfigName, Namespace: ns}, rhmiConfig)
+	if k8serr.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rhmi config: %w", err)
+	}
+
+	providers := []integreatlyv1alpha1.IdentityProvider{}
+	for _, provider := range rhmiConfig.Spec.IdentityProviders {
+		if provider.InInstance(instance) {
+			providers = append(providers, provider)
+		}
+	}
+	return providers, nil
+}
+
+func reconcileBrokerProvider(kcClient keycloakCommon.KeycloakInterface, adminClient KeycloakAdminInterface, provider integreatlyv1alpha1.IdentityProvider, secret *corev1.Secret, realmName string) error {
+	desired := buildBrokerProvider(provider, secret)
+
+	existing, err := kcClient.GetIdentityProvider(provider.Alias, realmName)
+	if err != nil {
+		return err
+	}
+
+	if existing == nil {
+		if _, err := kcClient.CreateIdentityProvider(desired, realmName); err != nil {
+			return err
+		}
+	} else if existing.ProviderID != desired.ProviderID {
+		// the type of a provider can't be changed, it has to be recreated
+		if err := kcClient.DeleteIdentityProvider(provider.Alias, realmName); err != nil {
+			return err
+		}
+		if _, err := kcClient.CreateIdentityProvider(desired, realmName); err != nil {
+			return err
+		}
+	} else if brokerProviderNeedsUpdate(existing, desired, secret) {
+		desired.InternalID = existing.InternalID
+		if err := kcClient.UpdateIdentityProvider(desired, realmName); err != nil {
+			return err
+		}
+	}
+
+	existingMappers, err := adminClient.ListIdentityProviderMappers(provider.Alias, realmName)
+	if err != nil {
+		return err
+	}
+	for _, mapper := range provider.Mappers {
+		desiredMapper := &IdentityProviderMapper{
+			Name:                   mapper.Name,
+			IdentityProviderAlias:  provider.Alias,
+			IdentityProviderMapper: mapper.Type,
+			Config:                 mapper.Config,
+		}
+
+		existingMapper := findIdentityProviderMapper(existingMappers, mapper.Name)
+		if existingMapper == nil {
+			if err := adminClient.CreateIdentityProviderMapper(desiredMapper, realmName); err != nil {
+				return err
+			}
+			continue
+		}
+		if existingMapper.IdentityProviderMapper != mapper.Type || !configEqual(existingMapper.Config, mapper.Config) {
+			desiredMapper.ID = existingMapper.ID
+			if err := adminClient.UpdateIdentityProviderMapper(desiredMapper, realmName); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func buildBrokerProvider(provider integreatlyv1alpha1.IdentityProvider, secret *corev1.Secret) *keycloak.KeycloakIdentityProvider {
+	providerConfig := map[string]string{}
+	for key, value := range provider.Config {
+		providerConfig[key] = value
+	}
+	for key, value := range secret.Data {
+		providerConfig[key] = string(value)
+	}
+	providerConfig[credentialsHashConfigKey] = credentialsHash(secret)
+
+	firstBrokerLoginFlowAlias := provider.FirstBrokerLoginFlowAlias
+	if firstBrokerLoginFlowAlias == "" {
+		firstBrokerLoginFlowAlias = defaultFirstBrokerLoginFlowAlias
+	}
+
+	return &keycloak.KeycloakIdentityProvider{
+		Alias:                     provider.Alias,
+		DisplayName:               provider.DisplayName,
+		ProviderID:                provider.Type,
+		Enabled:                   true,
+		TrustEmail:                provider.TrustEmail,
+		FirstBrokerLoginFlowAlias: firstBrokerLoginFlowAlias,
+		Config:                    providerConfig,
+	}
+}
+
+// brokerProviderNeedsUpdate compares the existing provider with the desired
+// one. The secret keys are skipped as Keycloak masks them, credential changes
+// are detected through the credentials hash instead
+func brokerProviderNeedsUpdate(existing, desired *keycloak.KeycloakIdentityProvider, secret *corev1.Secret) bool {
+	if existing.DisplayName != desired.DisplayName ||
+		existing.Enabled != desired.Enabled ||
+		existing.TrustEmail != desired.TrustEmail ||
+		existing.FirstBrokerLoginFlowAlias != desired.FirstBrokerLoginFlowAlias {
+		return true
+	}
+
+	for key, value := range desired.Config {
+		if _, ok := secret.Data[key]; ok {
+			continue
+		}
+		if existing.Config[key] != value {
+			return true
+		}
+	}
+	return false
+}
+
+func reconcileLDAPProvider(adminClient KeycloakAdminInterface, provider integreatlyv1alpha1.IdentityProvider, secret *corev1.Secret, realmName string) error {
+	// the realms created by the operator have their name as ID
+	existingProviders, err := adminClient.ListComponents(realmName, realmName, userStorageProviderType)
+	if err != nil {
+		return err
+	}
+
+	desired := buildLDAPComponent(provider, secret, realmName)
+	existing := findComponent(existingProviders, provider.Alias)
+	if existing == nil {
+		if err := adminClient.CreateComponent(desired, realmName); err != nil {
+			return err
+		}
+		// the ID of the created component is needed as the parent of its
+		// mappers
+		if existingProviders, err = adminClient.ListComponents(realmName, realmName, userStorageProviderType); err != nil {
+			return err
+		}
+		if existing = findComponent(existingProviders, provider.Alias); existing == nil {
+			return fmt.Errorf("ldap provider %s not found after creation", provider.Alias)
+		}
+	} else if ldapComponentNeedsUpdate(existing, desired, secret) {
+		desired.ID = existing.ID
+		if err := adminClient.UpdateComponent(desired, realmName); err != nil {
+			return err
+		}
+	}
+
+	if len(provider.Mappers) == 0 {
+		return nil
+	}
+	existingMappers, err := adminClient.ListComponents(realmName, existing.ID, ldapStorageMapperType)
+	if err != nil {
+		return err
+	}
+	for _, mapper := range provider.Mappers {
+		desiredMapper := &Component{
+			Name:         mapper.Name,
+			ProviderID:   mapper.Type,
+			ProviderType: ldapStorageMapperType,
+			ParentID:     existing.ID,
+			Config:       componentConfig(mapper.Config),
+		}
+
+		existingMapper := findComponent(existingMappers, mapper.Name)
+		if existingMapper == nil {
+			if err := adminClient.CreateComponent(desiredMapper, realmName); err != nil {
+				return err
+			}
+			continue
+		}
+		if existingMapper.ProviderID != mapper.Type || !componentConfigContains(existingMapper.Config, desiredMapper.Config, nil) {
+			desiredMapper.ID = existingMapper.ID
+			if err := adminClient.UpdateComponent(desiredMapper, realmName); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func buildLDAPComponent(provider integreatlyv1alpha1.IdentityProvider, secret *corev1.Secret, realmName string) *Component {
+	providerConfig := componentConfig(provider.Config)
+	for key, value := range secret.Data {
+		providerConfig[key] = []string{string(value)}
+	}
+	providerConfig[credentialsHashConfigKey] = []string{credentialsHash(secret)}
+	if _, ok := providerConfig["enabled"]; !ok {
+		providerConfig["enabled"] = []string{"true"}
+	}
+
+	return &Component{
+		Name:         provider.Alias,
+		ProviderID:   integreatlyv1alpha1.IdentityProviderTypeLDAP,
+		ProviderType: userStorageProviderType,
+		ParentID:     realmName,
+		Config:       providerConfig,
+	}
+}
+
+// ldapComponentNeedsUpdate compares the config of the existing LDAP provider
+// with the desired one, skipping the masked secret keys
+func ldapComponentNeedsUpdate(existing, desired *Component, secret *corev1.Secret) bool {
+	return !componentConfigContains(existing.Config, desired.Config, secret.Data)
+}
+
+func deleteIdentityProvider(kcClient keycloakCommon.KeycloakInterface, adminClient KeycloakAdminInterface, entry, realmName string) error {
+	providerType, alias := parseManagedIdentityProvider(entry)
+
+	if providerType != integreatlyv1alpha1.IdentityProviderTypeLDAP {
+		existing, err := kcClient.GetIdentityProvider(alias, realmName)
+		if err != nil || existing == nil {
+			return err
+		}
+		return kcClient.DeleteIdentityProvider(alias, realmName)
+	}
+
+	components, err := adminClient.ListComponents(realmName, realmName, userStorageProviderType)
+	if err != nil {
+		return err
+	}
+	if existing := findComponent(components, alias); existing != nil {
+		return adminClient.DeleteComponent(existing.ID, realmName)
+	}
+	return nil
+}
+
+func managedIdentityProvider(providerType, alias string) string {
+	return providerType + ":" + alias
+}
+
+func parseManagedIdentityProvider(entry string) (string, string) {
+	parts := strings.SplitN(entry, ":", 2)
+	if len(parts) != 2 {
+		return "", entry
+	}
+	return parts[0], parts[1]
+}
+
+// credentialsHash returns a hash of the data of the secret, independent of
+// the order of its keys
+func credentialsHash(secret *corev1.Secret) string {
+	keys := []string{}
+	for key := range secret.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	hash := sha256.New()
+	for _, key := range keys {
+		hash.Write([]byte(key))
+		hash.Write([]byte{0})
+		hash.Write(secret.Data[key])
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func componentConfig(providerConfig map[string]string) map[string][]string {
+	result := map[string][]string{}
+	for key, value := range providerConfig {
+		result[key] = []string{value}
+	}
+	return result
+}
+
+// componentConfigContains returns true if existing has all the values of
+// desired, except for the skipped keys
+func componentConfigContains(existing, desired map[string][]string, skip map[string][]byte) bool {
+	for key, value := range desired {
+		if _, ok := skip[key]; ok {
+			continue
+		}
+		if !reflect.DeepEqual(existing[key], value) {
+			return false
+		}
+	}
+	return true
+}
+
+func configEqual(existing, desired map[string]string) bool {
+	for key, value := range desired {
+		if existing[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+func findIdentityProviderMapper(mappers []*IdentityProviderMapper, name string) *IdentityProviderMapper {
+	for _, mapper := range mappers {
+		if mapper.Name == name {
+			return mapper
+		}
+	}
+	return nil
+}
+
+func findComponent(components []*Component, name string) *Component {
+	for _, component := range components {
+		if component.Name == name {
+			return component
+		}
+	}
+	return nil
+}
+
+func containsString(items []string, find string) bool {
+	for _, item := range items {
+		if item == find {
+			return true
+		}
+	}
+	return false
+}
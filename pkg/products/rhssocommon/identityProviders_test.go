@@ -0,0 +1,258 @@
+package rhssocommon
+
+import (
+	"context"
+	"testing"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/config"
+	keycloakCommon "github.com/integr8ly/keycloak-client/pkg/common"
+	keycloak "github.com/keycloak/keycloak-operator/pkg/apis/keycloak/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// Mock context of the identity provider endpoints of the Keycloak API
+type identityProviderMockContext struct {
+	Providers  map[string]*keycloak.KeycloakIdentityProvider
+	Mappers    map[string]*IdentityProviderMapper
+	Components map[string]*Component
+	Updated    []string
+	Deleted    []string
+}
+
+func newIdentityProviderMocks() (*keycloakCommon.KeycloakInterfaceMock, *KeycloakAdminInterfaceMock, *identityProviderMockContext) {
+	context := &identityProviderMockContext{
+		Providers:  map[string]*keycloak.KeycloakIdentityProvider{},
+		Mappers:    map[string]*IdentityProviderMapper{},
+		Components: map[string]*Component{},
+	}
+
+	kcClient := &keycloakCommon.KeycloakInterfaceMock{
+		GetIdentityProviderFunc: func(alias string, realmName string) (*keycloak.KeycloakIdentityProvider, error) {
+			return context.Providers[alias], nil
+		},
+		CreateIdentityProviderFunc: func(identityProvider *keycloak.KeycloakIdentityProvider, realmName string) (string, error) {
+			context.Providers[identityProvider.Alias] = identityProvider
+			return identityProvider.Alias, nil
+		},
+		UpdateIdentityProviderFunc: func(identityProvider *keycloak.KeycloakIdentityProvider, realmName string) error {
+			context.Providers[identityProvider.Alias] = identityProvider
+			context.Updated = append(context.Updated, identityProvider.Alias)
+			return nil
+		},
+		DeleteIdentityProviderFunc: func(alias string, realmName string) error {
+			delete(context.Providers, alias)
+			context.Deleted = append(context.Deleted, alias)
+			return nil
+		},
+	}
+
+	adminClient := &KeycloakAdminInterfaceMock{
+		ListIdentityProviderMappersFunc: func(alias string, realmName string) ([]*IdentityProviderMapper, error) {
+			mappers := []*IdentityProviderMapper{}
+			for _, mapper := range context.Mappers {
+				if mapper.IdentityProviderAlias == alias {
+					mappers = append(mappers, mapper)
+				}
+			}
+			return mappers, nil
+		},
+		CreateIdentityProviderMapperFunc: func(mapper *IdentityProviderMapper, realmName string) error {
+			mapper.ID = mapper.Name
+			context.Mappers[mapper.Name] = mapper
+			return nil
+		},
+		UpdateIdentityProviderMapperFunc: func(mapper *IdentityProviderMapper, realmName string) error {
+			context.Mappers[mapper.Name] = mapper
+			context.Updated = append(context.Updated, mapper.Name)
+			return nil
+		},
+		ListComponentsFunc: func(realmName string, parentID string, providerType string) ([]*Component, error) {
+			components := []*Component{}
+			for _, component := range context.Components {
+				if component.ParentID == parentID && component.ProviderType == providerType {
+					components = append(components, component)
+				}
+			}
+			return components, nil
+		},
+		CreateComponentFunc: func(component *Component, realmName string) error {
+			component.ID = component.Name + "-id"
+			context.Components[component.ID] = component
+			return nil
+		},
+		UpdateComponentFunc: func(component *Component, realmName string) error {
+			context.Components[component.ID] = component
+			context.Updated = append(context.Updated, component.Name)
+			return nil
+		},
+		DeleteComponentFunc: func(componentID string, realmName string) error {
+			context.Deleted = append(context.Deleted, context.Components[componentID].Name)
+			delete(context.Components, componentID)
+			return nil
+		},
+	}
+
+	return kcClient, adminClient, context
+}
+
+func TestReconciler_ReconcileIdentityProviders(t *testing.T) {
+	scheme, err := getBuildScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	oidcSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "corporate-oidc", Namespace: defaultOperatorNamespace},
+		Data: map[string][]byte{
+			"clientId":     []byte("client"),
+			"clientSecret": []byte("secret"),
+		},
+	}
+	ldapSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "corporate-ldap", Namespace: defaultOperatorNamespace},
+		Data: map[string][]byte{
+			"bindDn":         []byte("cn=admin"),
+			"bindCredential": []byte("password"),
+		},
+	}
+	rhmiConfig := &integreatlyv1alpha1.RHMIConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: rhmiConfigName, Namespace: defaultOperatorNamespace},
+		Spec: integreatlyv1alpha1.RHMIConfigSpec{
+			IdentityProviders: []integreatlyv1alpha1.IdentityProvider{
+				{
+					Alias:     "corporate-oidc",
+					Type:      integreatlyv1alpha1.IdentityProviderTypeOIDC,
+					SecretRef: "corporate-oidc",
+					Config:    map[string]string{"authorizationUrl": "https://idp.example.com/auth"},
+					Mappers: []integreatlyv1alpha1.IdentityProviderMapper{
+						{Name: "department", Type: "oidc-user-attribute-idp-mapper", Config: map[string]string{"claim": "department"}},
+					},
+				},
+				{
+					Alias:     "corporate-ldap",
+					Type:      integreatlyv1alpha1.IdentityProviderTypeLDAP,
+					SecretRef: "corporate-ldap",
+					Config:    map[string]string{"connectionUrl": "ldaps://ldap.example.com"},
+					Mappers: []integreatlyv1alpha1.IdentityProviderMapper{
+						{Name: "email", Type: "user-attribute-ldap-mapper", Config: map[string]string{"ldap.attribute": "mail"}},
+					},
+				},
+				{
+					Alias:     "rhsso-only",
+					Type:      integreatlyv1alpha1.IdentityProviderTypeGitLab,
+					Instances: []string{integreatlyv1alpha1.IdentityProviderInstanceRHSSO},
+					SecretRef: "corporate-oidc",
+				},
+			},
+		},
+	}
+
+	newReconciler := func(serverClient k8sclient.Client, kcClient keycloakCommon.KeycloakInterface, adminClient KeycloakAdminInterface) *Reconciler {
+		return &Reconciler{
+			ConfigManager: basicConfigMock(),
+			Log:           getLogger(),
+			KeycloakClientFactory: &keycloakCommon.KeycloakClientFactoryMock{
+				AuthenticatedClientFunc: func(kc keycloak.Keycloak) (keycloakCommon.KeycloakInterface, error) {
+					return kcClient, nil
+				},
+			},
+			KeycloakAdminClientFactory: func(ctx context.Context, serverClient k8sclient.Client, kc *keycloak.Keycloak) (KeycloakAdminInterface, error) {
+				return adminClient, nil
+			},
+		}
+	}
+
+	t.Run("test providers and mappers are created in the instance realm", func(t *testing.T) {
+		kcClient, adminClient, mockContext := newIdentityProviderMocks()
+		serverClient := fakeclient.NewFakeClientWithScheme(scheme, rhmiConfig, oidcSecret, ldapSecret)
+		ssoConfig := config.NewRHSSOUser(config.ProductConfig{})
+
+		phase, err := newReconciler(serverClient, kcClient, adminClient).ReconcileIdentityProviders(context.TODO(), serverClient, &keycloak.Keycloak{}, ssoConfig, ssoConfig.RHSSOCommon, "master", integreatlyv1alpha1.IdentityProviderInstanceRHSSOUser)
+		if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+			t.Fatalf("unexpected result: %v, %v", phase, err)
+		}
+
+		provider, ok := mockContext.Providers["corporate-oidc"]
+		if !ok {
+			t.Fatalf("expected the oidc provider to be created, got %v", mockContext.Providers)
+		}
+		if provider.Config["clientSecret"] != "secret" || provider.Config["authorizationUrl"] != "https://idp.example.com/auth" {
+			t.Fatalf("expected the provider config to include the config and the credentials, got %v", provider.Config)
+		}
+		if provider.FirstBrokerLoginFlowAlias != defaultFirstBrokerLoginFlowAlias {
+			t.Fatalf("expected the default first broker login flow, got %s", provider.FirstBrokerLoginFlowAlias)
+		}
+		if _, ok := mockContext.Providers["rhsso-only"]; ok {
+			t.Fatal("expected the rhsso only provider not to be created in the user sso realm")
+		}
+		if _, ok := mockContext.Mappers["department"]; !ok {
+			t.Fatalf("expected the oidc mapper to be created, got %v", mockContext.Mappers)
+		}
+
+		ldap, ok := mockContext.Components["corporate-ldap-id"]
+		if !ok || ldap.ParentID != "master" || ldap.Config["bindCredential"][0] != "password" {
+			t.Fatalf("expected the ldap provider to be created in the realm with its credentials, got %v", ldap)
+		}
+		if mapper, ok := mockContext.Components["email-id"]; !ok || mapper.ParentID != "corporate-ldap-id" {
+			t.Fatalf("expected the ldap mapper to be created under the ldap provider, got %v", mockContext.Components)
+		}
+
+		managed := ssoConfig.GetIdentityProviders()
+		if len(managed) != 2 || managed[0] != "ldap:corporate-ldap" || managed[1] != "oidc:corporate-oidc" {
+			t.Fatalf("expected the providers to be recorded in the config, got %v", managed)
+		}
+
+		// a second reconcile with the same credentials doesn't update anything
+		if _, err := newReconciler(serverClient, kcClient, adminClient).ReconcileIdentityProviders(context.TODO(), serverClient, &keycloak.Keycloak{}, ssoConfig, ssoConfig.RHSSOCommon, "master", integreatlyv1alpha1.IdentityProviderInstanceRHSSOUser); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(mockContext.Updated) != 0 {
+			t.Fatalf("expected no updates, got %v", mockContext.Updated)
+		}
+	})
+
+	t.Run("test credentials are rotated when the secret changes", func(t *testing.T) {
+		kcClient, adminClient, mockContext := newIdentityProviderMocks()
+		existing := buildBrokerProvider(rhmiConfig.Spec.IdentityProviders[0], oidcSecret)
+		existing.Config["clientSecret"] = "**********"
+		mockContext.Providers["corporate-oidc"] = existing
+
+		rotated := oidcSecret.DeepCopy()
+		rotated.Data["clientSecret"] = []byte("rotated")
+		serverClient := fakeclient.NewFakeClientWithScheme(scheme, rhmiConfig, rotated, ldapSecret)
+		ssoConfig := config.NewRHSSO(config.ProductConfig{})
+
+		if _, err := newReconciler(serverClient, kcClient, adminClient).ReconcileIdentityProviders(context.TODO(), serverClient, &keycloak.Keycloak{}, ssoConfig, ssoConfig.RHSSOCommon, "openshift", integreatlyv1alpha1.IdentityProviderInstanceRHSSO); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(mockContext.Updated) != 1 || mockContext.Updated[0] != "corporate-oidc" {
+			t.Fatalf("expected the oidc provider to be updated, got %v", mockContext.Updated)
+		}
+		if mockContext.Providers["corporate-oidc"].Config["clientSecret"] != "rotated" {
+			t.Fatal("expected the rotated client secret to be set")
+		}
+	})
+
+	t.Run("test providers removed from the config are deleted", func(t *testing.T) {
+		kcClient, adminClient, mockContext := newIdentityProviderMocks()
+		mockContext.Providers["removed-saml"] = &keycloak.KeycloakIdentityProvider{Alias: "removed-saml", ProviderID: "saml"}
+		mockContext.Components["removed-ldap-id"] = &Component{ID: "removed-ldap-id", Name: "removed-ldap", ParentID: "master", ProviderType: userStorageProviderType}
+
+		serverClient := fakeclient.NewFakeClientWithScheme(scheme)
+		ssoConfig := config.NewRHSSOUser(config.ProductConfig{"IDENTITY_PROVIDERS": "saml:removed-saml,ldap:removed-ldap"})
+
+		if _, err := newReconciler(serverClient, kcClient, adminClient).ReconcileIdentityProviders(context.TODO(), serverClient, &keycloak.Keycloak{}, ssoConfig, ssoConfig.RHSSOCommon, "master", integreatlyv1alpha1.IdentityProviderInstanceRHSSOUser); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(mockContext.Providers) != 0 || len(mockContext.Components) != 0 {
+			t.Fatalf("expected the removed providers to be deleted, got %v", mockContext.Deleted)
+		}
+		if len(ssoConfig.GetIdentityProviders()) != 0 {
+			t.Fatalf("expected no providers recorded in the config, got %v", ssoConfig.GetIdentityProviders())
+		}
+	})
+}
@@ -0,0 +1,269 @@
+package rhssocommon
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+
+	"github.com/integr8ly/integreatly-operator/pkg/resources/tracing"
+	keycloak "github.com/keycloak/keycloak-operator/pkg/apis/keycloak/v1alpha1"
+	"github.com/keycloak/keycloak-operator/pkg/model"
+	corev1 "k8s.io/api/core/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	userStorageProviderType = "org.keycloak.storage.UserStorageProvider"
+	ldapStorageMapperType   = "org.keycloak.storage.ldap.mappers.LDAPStorageMapper"
+)
+
+// IdentityProviderMapper is the representation of an identity provider mapper
+// in the Keycloak admin API
+type IdentityProviderMapper struct {
+	ID                     string            `json:"id,omitempty"`
+	Name                   string            `json:"name"`
+	IdentityProviderAlias  string            `json:"identityProviderAlias"`
+	IdentityProviderMapper string            `json:"identityProviderMapper"`
+	Config                 map[string]string `json:"config,omitempty"`
+}
+
+// Component is the representation of a component, such as a user federation
+// provider or one of its mappers, in the Keycloak admin API
+type Component struct {
+	ID           string              `json:"id,omitempty"`
+	Name         string              `json:"name"`
+	ProviderID   string              `json:"providerId"`
+	ProviderType string              `json:"providerType"`
+	ParentID     string              `json:"parentId"`
+	Config       map[string][]string `json:"config,omitempty"`
+}
+
+// RealmThemes are the themes set on a realm. Empty themes are the Keycloak
+// defaults
+type RealmThemes struct {
+	LoginTheme   string `json:"loginTheme"`
+	AccountTheme string `json:"accountTheme"`
+	EmailTheme   string `json:"emailTheme"`
+}
+
+type user struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+}
+
+type credential struct {
+	Type      string `json:"type"`
+	Value     string `json:"value"`
+	Temporary bool   `json:"temporary"`
+}
+
+type serverInfoTheme struct {
+	Name string `json:"name"`
+}
+
+type serverInfo struct {
+	Themes map[string][]serverInfoTheme `json:"themes"`
+}
+
+//go:generate moq -out keycloakAdminClient_moq.go . KeycloakAdminInterface
+
+// KeycloakAdminInterface covers the endpoints of the Keycloak admin API that
+// aren't part of the keycloak client
+type KeycloakAdminInterface interface {
+	ListIdentityProviderMappers(alias, realmName string) ([]*IdentityProviderMapper, error)
+	CreateIdentityProviderMapper(mapper *IdentityProviderMapper, realmName string) error
+	UpdateIdentityProviderMapper(mapper *IdentityProviderMapper, realmName string) error
+
+	ListComponents(realmName, parentID, providerType string) ([]*Component, error)
+	CreateComponent(component *Component, realmName string) error
+	UpdateComponent(component *Component, realmName string) error
+	DeleteComponent(componentID, realmName string) error
+
+	ListThemes() (map[string][]string, error)
+	GetRealmThemes(realmName string) (*RealmThemes, error)
+	UpdateRealmThemes(themes *RealmThemes, realmName string) error
+
+	ResetUserPassword(realmName, username, password string) error
+}
+
+type keycloakAdminClient struct {
+	url        string
+	token      string
+	httpClient *http.Client
+}
+
+var _ KeycloakAdminInterface = &keycloakAdminClient{}
+
+// NewKeycloakAdminClient returns a client of the admin API of the Keycloak
+// instance, authenticated with its admin credentials
+func NewKeycloakAdminClient(ctx context.Context, serverClient k8sclient.Client, kc *keycloak.Keycloak) (KeycloakAdminInterface, error) {
+	adminCreds := &corev1.Secret{}
+	if err := serverClient.Get(ctx, k8sclient.ObjectKey{Name: kc.Status.CredentialSecret, Namespace: kc.Namespace}, adminCreds); err != nil {
+		return nil, fmt.Errorf("failed to get the keycloak admin credentials: %w", err)
+	}
+
+	client := &keycloakAdminClient{
+		url: kc.Status.ExternalURL,
+		httpClient: &http.Client{
+			Transport: l.NewTransport(ctx, tracing.NewTransport(ctx, &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, // nolint
+			})),
+			Timeout: 10 * time.Second,
+		},
+	}
+	if err := client.login(string(adminCreds.Data[model.AdminUsernameProperty]), string(adminCreds.Data[model.AdminPasswordProperty])); err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
+
+func (c *keycloakAdminClient) ListIdentityProviderMappers(alias, realmName string) ([]*IdentityProviderMapper, error) {
+	mappers := []*IdentityProviderMapper{}
+	err := c.do(http.MethodGet, fmt.Sprintf("realms/%s/identity-provider/instances/%s/mappers", realmName, alias), nil, &mappers)
+	return mappers, err
+}
+
+func (c *keycloakAdminClient) CreateIdentityProviderMapper(mapper *IdentityProviderMapper, realmName string) error {
+	return c.do(http.MethodPost, fmt.Sprintf("realms/%s/identity-provider/instances/%s/mappers", realmName, mapper.IdentityProviderAlias), mapper, nil)
+}
+
+func (c *keycloakAdminClient) UpdateIdentityProviderMapper(mapper *IdentityProviderMapper, realmName string) error {
+	return c.do(http.MethodPut, fmt.Sprintf("realms/%s/identity-provider/instances/%s/mappers/%s", realmName, mapper.IdentityProviderAlias, mapper.ID), mapper, nil)
+}
+
+func (c *keycloakAdminClient) ListComponents(realmName, parentID, providerType string) ([]*Component, error) {
+	query := url.Values{}
+	query.Set("parent", parentID)
+	query.Set("type", providerType)
+
+	components := []*Component{}
+	err := c.do(http.MethodGet, fmt.Sprintf("realms/%s/components?%s", realmName, query.Encode()), nil, &components)
+	return components, err
+}
+
+func (c *keycloakAdminClient) CreateComponent(component *Component, realmName string) error {
+	return c.do(http.MethodPost, fmt.Sprintf("realms/%s/components", realmName), component, nil)
+}
+
+func (c *keycloakAdminClient) UpdateComponent(component *Component, realmName string) error {
+	return c.do(http.MethodPut, fmt.Sprintf("realms/%s/components/%s", realmName, component.ID), component, nil)
+}
+
+func (c *keycloakAdminClient) DeleteComponent(componentID, realmName string) error {
+	return c.do(http.MethodDelete, fmt.Sprintf("realms/%s/components/%s", realmName, componentID), nil, nil)
+}
+
+// ListThemes returns the names of the themes loaded by the server, keyed by
+// theme type
+func (c *keycloakAdminClient) ListThemes() (map[string][]string, error) {
+	info := &serverInfo{}
+	if err := c.do(http.MethodGet, "serverinfo", nil, info); err != nil {
+		return nil, err
+	}
+
+	themes := map[string][]string{}
+	for themeType, typeThemes := range info.Themes {
+		for _, theme := range typeThemes {
+			themes[themeType] = append(themes[themeType], theme.Name)
+		}
+	}
+	return themes, nil
+}
+
+func (c *keycloakAdminClient) GetRealmThemes(realmName string) (*RealmThemes, error) {
+	themes := &RealmThemes{}
+	err := c.do(http.MethodGet, fmt.Sprintf("realms/%s", realmName), nil, themes)
+	return themes, err
+}
+
+// UpdateRealmThemes sets the themes of the realm, leaving the rest of its
+// settings unchanged
+func (c *keycloakAdminClient) UpdateRealmThemes(themes *RealmThemes, realmName string) error {
+	return c.do(http.MethodPut, fmt.Sprintf("realms/%s", realmName), themes, nil)
+}
+
+// ResetUserPassword sets a permanent password on the user of the realm
+func (c *keycloakAdminClient) ResetUserPassword(realmName, username, password string) error {
+	query := url.Values{}
+	query.Set("username", username)
+	query.Set("exact", "true")
+
+	users := []*user{}
+	if err := c.do(http.MethodGet, fmt.Sprintf("realms/%s/users?%s", realmName, query.Encode()), nil, &users); err != nil {
+		return err
+	}
+	for _, u := range users {
+		if u.Username == username {
+			return c.do(http.MethodPut, fmt.Sprintf("realms/%s/users/%s/reset-password", realmName, u.ID), &credential{Type: "password", Value: password}, nil)
+		}
+	}
+	return fmt.Errorf("user %s not found in realm %s", username, realmName)
+}
+
+func (c *keycloakAdminClient) do(method, path string, body interface{}, result interface{}) error {
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequest(method, fmt.Sprintf("%s/auth/admin/%s", c.url, path), bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("error creating %s %s request: %w", method, path, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error performing %s %s request: %w", method, path, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		resBody, _ := ioutil.ReadAll(res.Body)
+		return fmt.Errorf("unexpected status code for %s %s: %d. Body: %s", method, path, res.StatusCode, string(resBody))
+	}
+
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(result)
+}
+
+func (c *keycloakAdminClient) login(user, pass string) error {
+	form := url.Values{}
+	form.Set("username", user)
+	form.Set("password", pass)
+	form.Set("client_id", "admin-cli")
+	form.Set("grant_type", "password")
+
+	res, err := c.httpClient.Post(fmt.Sprintf("%s/auth/realms/master/protocol/openid-connect/token", c.url), "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("error performing token request: %w", err)
+	}
+	defer res.Body.Close()
+
+	tokenRes := &keycloak.TokenResponse{}
+	if err := json.NewDecoder(res.Body).Decode(tokenRes); err != nil {
+		return fmt.Errorf("error parsing token response: %w", err)
+	}
+	if tokenRes.Error != "" {
+		return fmt.Errorf("error logging in to keycloak: %s", tokenRes.ErrorDescription)
+	}
+	c.token = tokenRes.AccessToken
+
+	return nil
+}
@@ -0,0 +1,478 @@
+package rhssocommon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/integr8ly/integreatly-operator/pkg/config"
+	"github.com/integr8ly/integreatly-operator/pkg/metrics"
+	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+	keycloakCommon "github.com/integr8ly/keycloak-client/pkg/common"
+	keycloak "github.com/keycloak/keycloak-operator/pkg/apis/keycloak/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// RealmDriftKindAuthenticationFlow is the kind of drift in the executions
+	// of an authentication flow
+	RealmDriftKindAuthenticationFlow = "authenticationFlow"
+	// RealmDriftKindIdentityProvider is the kind of drift in an identity
+	// provider
+	RealmDriftKindIdentityProvider = "identityProvider"
+	// RealmDriftKindGroup is the kind of drift in a group or its roles
+	RealmDriftKindGroup = "group"
+
+	realmExportInterval           = 5 * time.Minute
+	realmExportRevisionAnnotation = "integreatly.org/realm-export-revision"
+	realmExportedAtAnnotation     = "integreatly.org/realm-exported-at"
+	realmExportKey                = "realm.json"
+	realmExportPreviousKey        = "realm.previous.json"
+	realmDriftKey                 = "drift.json"
+)
+
+var realmDriftKinds = []string{
+	RealmDriftKindAuthenticationFlow,
+	RealmDriftKindIdentityProvider,
+	RealmDriftKindGroup,
+}
+
+// RealmSettings are the realm settings managed by the operator. Only the
+// flows, executions, identity providers and groups listed are compared, so
+// settings that are owned by customers don't count as drift
+type RealmSettings struct {
+	AuthenticationFlows []RealmAuthenticationFlow `json:"authenticationFlows,omitempty"`
+	IdentityProviders   []RealmIdentityProvider   `json:"identityProviders,omitempty"`
+	Groups              []RealmGroup              `json:"groups,omitempty"`
+}
+
+// RealmAuthenticationFlow is an authentication flow and its executions
+type RealmAuthenticationFlow struct {
+	Alias      string           `json:"alias"`
+	Executions []RealmExecution `json:"executions"`
+}
+
+// RealmExecution is an execution of an authentication flow, identified by its
+// provider
+type RealmExecution struct {
+	ProviderID  string `json:"providerId"`
+	Requirement string `json:"requirement"`
+}
+
+// RealmIdentityProvider is the managed part of an identity provider. An empty
+// FirstBrokerLoginFlowAlias isn't compared
+type RealmIdentityProvider struct {
+	Alias                     string `json:"alias"`
+	Enabled                   bool   `json:"enabled"`
+	FirstBrokerLoginFlowAlias string `json:"firstBrokerLoginFlowAlias,omitempty"`
+}
+
+// RealmGroup is a group and the realm roles mapped to it
+type RealmGroup struct {
+	Name       string   `json:"name"`
+	RealmRoles []string `json:"realmRoles,omitempty"`
+}
+
+// RealmDrift is a managed realm setting that differs from the desired state
+type RealmDrift struct {
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+	Field    string `json:"field"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+// ReconcileRealmDrift exports the managed settings of the realm to the
+// "<realm>-realm-export" ConfigMap of the namespace, along with their drift
+// from the desired state, and exposes the drift as a metric. The export is
+// versioned by an annotation that is increased every time the exported
+// settings change. If remediation is enabled in the product config the drift
+// is reverted. The export runs at most once per realmExportInterval
+func (r *Reconciler) ReconcileRealmDrift(ctx context.Context, serverClient k8sclient.Client, kc *keycloak.Keycloak, ssoCommon *config.RHSSOCommon, namespace, realmName string, desired *RealmSettings) error {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-realm-export", realmName),
+			Namespace: namespace,
+		},
+	}
+	err := serverClient.Get(ctx, k8sclient.ObjectKey{Name: cm.Name, Namespace: cm.Namespace}, cm)
+	if err != nil && !k8serr.IsNotFound(err) {
+		return fmt.Errorf("failed to get realm export config map: %w", err)
+	}
+	if exportedAt, err := time.Parse(time.RFC3339, cm.Annotations[realmExportedAtAnnotation]); err == nil && time.Since(exportedAt) < realmExportInterval {
+		return nil
+	}
+
+	kcClient, err := r.KeycloakClientFactory.AuthenticatedClient(*kc)
+	if err != nil {
+		return fmt.Errorf("failed to authenticate client in keycloak api %w", err)
+	}
+
+	actual, err := ExportRealmSettings(kcClient, realmName, desired)
+	if err != nil {
+		return err
+	}
+	drifts := DiffRealmSettings(desired, actual)
+
+	if len(drifts) > 0 {
+		r.Log.Warningf("Realm settings drifted from the desired state", l.Fields{"realm": realmName, "drifts": len(drifts)})
+
+		remediate, err := ssoCommon.GetRealmDriftRemediation()
+		if err != nil {
+			return fmt.Errorf("failed to read realm drift remediation config: %w", err)
+		}
+		if remediate {
+			if err := RemediateRealmSettings(kcClient, realmName, desired, actual); err != nil {
+				return fmt.Errorf("failed to remediate realm drift: %w", err)
+			}
+			r.Log.Infof("Remediated realm drift", l.Fields{"realm": realmName})
+
+			if actual, err = ExportRealmSettings(kcClient, realmName, desired); err != nil {
+				return err
+			}
+			drifts = DiffRealmSettings(desired, actual)
+		}
+	}
+
+	for _, kind := range realmDriftKinds {
+		count := 0
+		for _, drift := range drifts {
+			if drift.Kind == kind {
+				count++
+			}
+		}
+		metrics.SetKeycloakRealmDrift(realmName, kind, count)
+	}
+
+	export, err := json.MarshalIndent(actual, "", "  ")
+	if err != nil {
+		return err
+	}
+	driftExport, err := json.MarshalIndent(drifts, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = controllerutil.CreateOrUpdate(ctx, serverClient, cm, func() error {
+		if cm.Annotations == nil {
+			cm.Annotations = map[string]string{}
+		}
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+
+		if cm.Data[realmExportKey] != string(export) {
+			revision, _ := strconv.Atoi(cm.Annotations[realmExportRevisionAnnotation])
+			cm.Annotations[realmExportRevisionAnnotation] = strconv.Itoa(revision + 1)
+			if cm.Data[realmExportKey] != "" {
+				cm.Data[realmExportPreviousKey] = cm.Data[realmExportKey]
+			}
+			cm.Data[realmExportKey] = string(export)
+		}
+		cm.Data[realmDriftKey] = string(driftExport)
+		cm.Annotations[realmExportedAtAnnotation] = time.Now().UTC().Format(time.RFC3339)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create/update realm export config map: %w", err)
+	}
+
+	return nil
+}
+
+// ExportRealmSettings returns the current state of the realm settings listed
+// in desired. Flows are exported with all their executions, identity
+// providers and groups that don't exist are left out
+func ExportRealmSettings(kcClient keycloakCommon.KeycloakInterface, realmName string, desired *RealmSettings) (*RealmSettings, error) {
+	actual := &RealmSettings{}
+
+	for _, desiredFlow := range desired.AuthenticationFlows {
+		executions, err := kcClient.ListAuthenticationExecutionsForFlow(desiredFlow.Alias, realmName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list executions of authentication flow %s: %w", desiredFlow.Alias, err)
+		}
+
+		flow := RealmAuthenticationFlow{Alias: desiredFlow.Alias, Executions: []RealmExecution{}}
+		for _, execution := range executions {
+			flow.Executions = append(flow.Executions, RealmExecution{
+				ProviderID:  execution.ProviderID,
+				Requirement: strings.ToUpper(execution.Requirement),
+			})
+		}
+		actual.AuthenticationFlows = append(actual.AuthenticationFlows, flow)
+	}
+
+	for _, desiredIDP := range desired.IdentityProviders {
+		idp, err := kcClient.GetIdentityProvider(desiredIDP.Alias, realmName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get identity provider %s: %w", desiredIDP.Alias, err)
+		}
+		if idp == nil {
+			continue
+		}
+		actual.IdentityProviders = append(actual.IdentityProviders, RealmIdentityProvider{
+			Alias:                     idp.Alias,
+			Enabled:                   idp.Enabled,
+			FirstBrokerLoginFlowAlias: idp.FirstBrokerLoginFlowAlias,
+		})
+	}
+
+	for _, desiredGroup := range desired.Groups {
+		group, err := kcClient.FindGroupByName(desiredGroup.Name, realmName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find group %s: %w", desiredGroup.Name, err)
+		}
+		if group == nil {
+			continue
+		}
+		roles, err := kcClient.ListGroupRealmRoles(realmName, group.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list realm roles of group %s: %w", desiredGroup.Name, err)
+		}
+
+		realmGroup := RealmGroup{Name: group.Name}
+		for _, role := range roles {
+			realmGroup.RealmRoles = append(realmGroup.RealmRoles, role.Name)
+		}
+		sort.Strings(realmGroup.RealmRoles)
+		actual.Groups = append(actual.Groups, realmGroup)
+	}
+
+	return actual, nil
+}
+
+// DiffRealmSettings returns the settings in desired that differ in actual.
+// Settings that only exist in actual aren't managed and aren't reported
+func DiffRealmSettings(desired, actual *RealmSettings) []RealmDrift {
+	drifts := []RealmDrift{}
+
+	for _, desiredFlow := range desired.AuthenticationFlows {
+		flow := findRealmFlow(actual, desiredFlow.Alias)
+		for _, desiredExecution := range desiredFlow.Executions {
+			execution := findRealmExecution(flow, desiredExecution.ProviderID)
+			if execution == nil {
+				drifts = append(drifts, missingRealmDrift(RealmDriftKindAuthenticationFlow, desiredFlow.Alias+"/"+desiredExecution.ProviderID))
+				continue
+			}
+			if execution.Requirement != strings.ToUpper(desiredExecution.Requirement) {
+				drifts = append(drifts, RealmDrift{
+					Kind:     RealmDriftKindAuthenticationFlow,
+					Name:     desiredFlow.Alias + "/" + desiredExecution.ProviderID,
+					Field:    "requirement",
+					Expected: strings.ToUpper(desiredExecution.Requirement),
+					Actual:   execution.Requirement,
+				})
+			}
+		}
+	}
+
+	for _, desiredIDP := range desired.IdentityProviders {
+		idp := findRealmIdentityProvider(actual, desiredIDP.Alias)
+		if idp == nil {
+			drifts = append(drifts, missingRealmDrift(RealmDriftKindIdentityProvider, desiredIDP.Alias))
+			continue
+		}
+		if idp.Enabled != desiredIDP.Enabled {
+			drifts = append(drifts, RealmDrift{
+				Kind:     RealmDriftKindIdentityProvider,
+				Name:     desiredIDP.Alias,
+				Field:    "enabled",
+				Expected: strconv.FormatBool(desiredIDP.Enabled),
+				Actual:   strconv.FormatBool(idp.Enabled),
+			})
+		}
+		if desiredIDP.FirstBrokerLoginFlowAlias != "" && idp.FirstBrokerLoginFlowAlias != desiredIDP.FirstBrokerLoginFlowAlias {
+			drifts = append(drifts, RealmDrift{
+				Kind:     RealmDriftKindIdentityProvider,
+				Name:     desiredIDP.Alias,
+				Field:    "firstBrokerLoginFlowAlias",
+				Expected: desiredIDP.FirstBrokerLoginFlowAlias,
+				Actual:   idp.FirstBrokerLoginFlowAlias,
+			})
+		}
+	}
+
+	for _, desiredGroup := range desired.Groups {
+		group := findRealmGroup(actual, desiredGroup.Name)
+		if group == nil {
+			drifts = append(drifts, missingRealmDrift(RealmDriftKindGroup, desiredGroup.Name))
+			continue
+		}
+		for _, role := range desiredGroup.RealmRoles {
+			if !containsString(group.RealmRoles, role) {
+				drifts = append(drifts, RealmDrift{
+					Kind:     RealmDriftKindGroup,
+					Name:     desiredGroup.Name,
+					Field:    "realmRoles",
+					Expected: role,
+				})
+			}
+		}
+	}
+
+	return drifts
+}
+
+// RemediateRealmSettings reverts the settings in actual that differ from
+// desired. Missing authentication flows and identity providers are left to the
+// product reconcilers that create them
+func RemediateRealmSettings(kcClient keycloakCommon.KeycloakInterface, realmName string, desired, actual *RealmSettings) error {
+	for _, desiredFlow := range desired.AuthenticationFlows {
+		flow := findRealmFlow(actual, desiredFlow.Alias)
+		for _, desiredExecution := range desiredFlow.Executions {
+			requirement := strings.ToUpper(desiredExecution.Requirement)
+
+			execution := findRealmExecution(flow, desiredExecution.ProviderID)
+			if execution == nil {
+				if err := kcClient.AddExecutionToAuthenticatonFlow(desiredFlow.Alias, realmName, desiredExecution.ProviderID, keycloakCommon.Requirement(requirement)); err != nil {
+					return fmt.Errorf("failed to add execution %s to authentication flow %s: %w", desiredExecution.ProviderID, desiredFlow.Alias, err)
+				}
+				continue
+			}
+			if execution.Requirement == requirement {
+				continue
+			}
+
+			providerID := desiredExecution.ProviderID
+			info, err := kcClient.FindAuthenticationExecutionForFlow(desiredFlow.Alias, realmName, func(execution *keycloak.AuthenticationExecutionInfo) bool {
+				return execution.ProviderID == providerID
+			})
+			if err != nil {
+				return fmt.Errorf("failed to find execution %s of authentication flow %s: %w", providerID, desiredFlow.Alias, err)
+			}
+			if info == nil {
+				continue
+			}
+			info.Requirement = requirement
+			if err := kcClient.UpdateAuthenticationExecutionForFlow(desiredFlow.Alias, realmName, info); err != nil {
+				return fmt.Errorf("failed to update execution %s of authentication flow %s: %w", providerID, desiredFlow.Alias, err)
+			}
+		}
+	}
+
+	for _, desiredIDP := range desired.IdentityProviders {
+		idp := findRealmIdentityProvider(actual, desiredIDP.Alias)
+		if idp == nil || (idp.Enabled == desiredIDP.Enabled && (desiredIDP.FirstBrokerLoginFlowAlias == "" || idp.FirstBrokerLoginFlowAlias == desiredIDP.FirstBrokerLoginFlowAlias)) {
+			continue
+		}
+
+		kcIDP, err := kcClient.GetIdentityProvider(desiredIDP.Alias, realmName)
+		if err != nil {
+			return fmt.Errorf("failed to get identity provider %s: %w", desiredIDP.Alias, err)
+		}
+		if kcIDP == nil {
+			continue
+		}
+		kcIDP.Enabled = desiredIDP.Enabled
+		if desiredIDP.FirstBrokerLoginFlowAlias != "" {
+			kcIDP.FirstBrokerLoginFlowAlias = desiredIDP.FirstBrokerLoginFlowAlias
+		}
+		if err := kcClient.UpdateIdentityProvider(kcIDP, realmName); err != nil {
+			return fmt.Errorf("failed to update identity provider %s: %w", desiredIDP.Alias, err)
+		}
+	}
+
+	for _, desiredGroup := range desired.Groups {
+		group := findRealmGroup(actual, desiredGroup.Name)
+
+		missingRoles := []string{}
+		for _, role := range desiredGroup.RealmRoles {
+			if group == nil || !containsString(group.RealmRoles, role) {
+				missingRoles = append(missingRoles, role)
+			}
+		}
+		if group != nil && len(missingRoles) == 0 {
+			continue
+		}
+
+		groupID := ""
+		if group == nil {
+			id, err := kcClient.CreateGroup(desiredGroup.Name, realmName)
+			if err != nil {
+				return fmt.Errorf("failed to create group %s: %w", desiredGroup.Name, err)
+			}
+			groupID = id
+		} else {
+			kcGroup, err := kcClient.FindGroupByName(desiredGroup.Name, realmName)
+			if err != nil {
+				return fmt.Errorf("failed to find group %s: %w", desiredGroup.Name, err)
+			}
+			if kcGroup == nil {
+				continue
+			}
+			groupID = kcGroup.ID
+		}
+
+		availableRoles, err := kcClient.ListAvailableGroupRealmRoles(realmName, groupID)
+		if err != nil {
+			return fmt.Errorf("failed to list available realm roles of group %s: %w", desiredGroup.Name, err)
+		}
+		for _, role := range availableRoles {
+			if !containsString(missingRoles, role.Name) {
+				continue
+			}
+			if _, err := kcClient.CreateGroupRealmRole(role, realmName, groupID); err != nil {
+				return fmt.Errorf("failed to map realm role %s to group %s: %w", role.Name, desiredGroup.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func missingRealmDrift(kind, name string) RealmDrift {
+	return RealmDrift{
+		Kind:     kind,
+		Name:     name,
+		Field:    "exists",
+		Expected: "true",
+		Actual:   "false",
+	}
+}
+
+func findRealmFlow(settings *RealmSettings, alias string) *RealmAuthenticationFlow {
+	for i := range settings.AuthenticationFlows {
+		if settings.AuthenticationFlows[i].Alias == alias {
+			return &settings.AuthenticationFlows[i]
+		}
+	}
+	return nil
+}
+
+func findRealmExecution(flow *RealmAuthenticationFlow, providerID string) *RealmExecution {
+	if flow == nil {
+		return nil
+	}
+	for i := range flow.Executions {
+		if flow.Executions[i].ProviderID == providerID {
+			return &flow.Executions[i]
+		}
+	}
+	return nil
+}
+
+func findRealmIdentityProvider(settings *RealmSettings, alias string) *RealmIdentityProvider {
+	for i := range settings.IdentityProviders {
+		if settings.IdentityProviders[i].Alias == alias {
+			return &settings.IdentityProviders[i]
+		}
+	}
+	return nil
+}
+
+func findRealmGroup(settings *RealmSettings, name string) *RealmGroup {
+	for i := range settings.Groups {
+		if settings.Groups[i].Name == name {
+			return &settings.Groups[i]
+		}
+	}
+	return nil
+}
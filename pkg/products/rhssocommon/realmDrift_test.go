@@ -0,0 +1,306 @@
+package rhssocommon
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/integr8ly/integreatly-operator/pkg/config"
+	keycloakCommon "github.com/integr8ly/keycloak-client/pkg/common"
+	keycloak "github.com/keycloak/keycloak-operator/pkg/apis/keycloak/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// Mock context of the realm endpoints of the Keycloak API
+type realmMockContext struct {
+	Executions map[string][]*keycloak.AuthenticationExecutionInfo
+	Providers  map[string]*keycloak.KeycloakIdentityProvider
+	Groups     map[string][]*keycloak.KeycloakUserRole
+	Updated    []string
+}
+
+func newRealmMocks() (*keycloakCommon.KeycloakInterfaceMock, *realmMockContext) {
+	context := &realmMockContext{
+		Executions: map[string][]*keycloak.AuthenticationExecutionInfo{
+			"authdelay": {
+				{ID: "delay", ProviderID: "delay-authentication", Requirement: "REQUIRED"},
+			},
+		},
+		Providers: map[string]*keycloak.KeycloakIdentityProvider{
+			"openshift-v4": {Alias: "openshift-v4", Enabled: true, FirstBrokerLoginFlowAlias: "authdelay"},
+		},
+		Groups: map[string][]*keycloak.KeycloakUserRole{
+			"rhmi-developers": {{Name: "create-realm"}},
+		},
+	}
+
+	kcClient := &keycloakCommon.KeycloakInterfaceMock{
+		ListAuthenticationExecutionsForFlowFunc: func(flowAlias string, realmName string) ([]*keycloak.AuthenticationExecutionInfo, error) {
+			return context.Executions[flowAlias], nil
+		},
+		FindAuthenticationExecutionForFlowFunc: func(flowAlias string, realmName string, predicate func(*keycloak.AuthenticationExecutionInfo) bool) (*keycloak.AuthenticationExecutionInfo, error) {
+			for _, execution := range context.Executions[flowAlias] {
+				if predicate(execution) {
+					copy := *execution
+					return &copy, nil
+				}
+			}
+			return nil, nil
+		},
+		UpdateAuthenticationExecutionForFlowFunc: func(flowAlias string, realmName string, execution *keycloak.AuthenticationExecutionInfo) error {
+			for i, existing := range context.Executions[flowAlias] {
+				if existing.ID == execution.ID {
+					context.Executions[flowAlias][i] = execution
+				}
+			}
+			context.Updated = append(context.Updated, flowAlias+"/"+execution.ProviderID)
+			return nil
+		},
+		GetIdentityProviderFunc: func(alias string, realmName string) (*keycloak.KeycloakIdentityProvider, error) {
+			if idp, ok := context.Providers[alias]; ok {
+				copy := *idp
+				return &copy, nil
+			}
+			return nil, nil
+		},
+		UpdateIdentityProviderFunc: func(identityProvider *keycloak.KeycloakIdentityProvider, realmName string) error {
+			context.Providers[identityProvider.Alias] = identityProvider
+			context.Updated = append(context.Updated, identityProvider.Alias)
+			return nil
+		},
+		FindGroupByNameFunc: func(groupName string, realmName string) (*keycloakCommon.Group, error) {
+			if _, ok := context.Groups[groupName]; ok {
+				return &keycloakCommon.Group{ID: groupName, Name: groupName}, nil
+			}
+			return nil, nil
+		},
+		ListGroupRealmRolesFunc: func(realmName string, groupID string) ([]*keycloak.KeycloakUserRole, error) {
+			return context.Groups[groupID], nil
+		},
+		ListAvailableGroupRealmRolesFunc: func(realmName string, groupID string) ([]*keycloak.KeycloakUserRole, error) {
+			return []*keycloak.KeycloakUserRole{{Name: "create-realm"}, {Name: "admin"}}, nil
+		},
+		CreateGroupRealmRoleFunc: func(role *keycloak.KeycloakUserRole, realmName string, groupID string) (string, error) {
+			context.Groups[groupID] = append(context.Groups[groupID], role)
+			context.Updated = append(context.Updated, groupID+"/"+role.Name)
+			return role.Name, nil
+		},
+	}
+
+	return kcClient, context
+}
+
+func getTestRealmSettings() *RealmSettings {
+	return &RealmSettings{
+		AuthenticationFlows: []RealmAuthenticationFlow{
+			{
+				Alias:      "authdelay",
+				Executions: []RealmExecution{{ProviderID: "delay-authentication", Requirement: "REQUIRED"}},
+			},
+		},
+		IdentityProviders: []RealmIdentityProvider{
+			{Alias: "openshift-v4", Enabled: true, FirstBrokerLoginFlowAlias: "authdelay"},
+		},
+		Groups: []RealmGroup{
+			{Name: "rhmi-developers", RealmRoles: []string{"create-realm"}},
+		},
+	}
+}
+
+func TestDiffRealmSettings(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Actual   func(settings *RealmSettings)
+		Expected []RealmDrift
+	}{
+		{
+			Name:     "no drift",
+			Actual:   func(settings *RealmSettings) {},
+			Expected: []RealmDrift{},
+		},
+		{
+			Name: "unmanaged settings are ignored",
+			Actual: func(settings *RealmSettings) {
+				settings.AuthenticationFlows[0].Executions = append(settings.AuthenticationFlows[0].Executions, RealmExecution{ProviderID: "auth-otp-form", Requirement: "OPTIONAL"})
+				settings.Groups[0].RealmRoles = append(settings.Groups[0].RealmRoles, "admin")
+			},
+			Expected: []RealmDrift{},
+		},
+		{
+			Name: "changed execution requirement and identity provider",
+			Actual: func(settings *RealmSettings) {
+				settings.AuthenticationFlows[0].Executions[0].Requirement = "DISABLED"
+				settings.IdentityProviders[0].FirstBrokerLoginFlowAlias = "first broker login"
+			},
+			Expected: []RealmDrift{
+				{Kind: RealmDriftKindAuthenticationFlow, Name: "authdelay/delay-authentication", Field: "requirement", Expected: "REQUIRED", Actual: "DISABLED"},
+				{Kind: RealmDriftKindIdentityProvider, Name: "openshift-v4", Field: "firstBrokerLoginFlowAlias", Expected: "authdelay", Actual: "first broker login"},
+			},
+		},
+		{
+			Name: "missing settings",
+			Actual: func(settings *RealmSettings) {
+				settings.AuthenticationFlows[0].Executions = nil
+				settings.IdentityProviders = nil
+				settings.Groups[0].RealmRoles = nil
+			},
+			Expected: []RealmDrift{
+				{Kind: RealmDriftKindAuthenticationFlow, Name: "authdelay/delay-authentication", Field: "exists", Expected: "true", Actual: "false"},
+				{Kind: RealmDriftKindIdentityProvider, Name: "openshift-v4", Field: "exists", Expected: "true", Actual: "false"},
+				{Kind: RealmDriftKindGroup, Name: "rhmi-developers", Field: "realmRoles", Expected: "create-realm"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			actual := getTestRealmSettings()
+			tt.Actual(actual)
+
+			drifts := DiffRealmSettings(getTestRealmSettings(), actual)
+			if !reflect.DeepEqual(drifts, tt.Expected) {
+				t.Errorf("unexpected drift. Expected %v, got %v", tt.Expected, drifts)
+			}
+		})
+	}
+}
+
+func TestReconciler_ReconcileRealmDrift(t *testing.T) {
+	scheme, err := getBuildScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kc := &keycloak.Keycloak{ObjectMeta: metav1.ObjectMeta{Name: "rhsso", Namespace: "rhsso"}}
+	cmKey := k8sclient.ObjectKey{Name: "rhsso-realm-export", Namespace: "rhsso"}
+
+	buildReconciler := func(kcClient keycloakCommon.KeycloakInterface) *Reconciler {
+		return &Reconciler{
+			ConfigManager: basicConfigMock(),
+			Log:           getLogger(),
+			KeycloakClientFactory: &keycloakCommon.KeycloakClientFactoryMock{
+				AuthenticatedClientFunc: func(kc keycloak.Keycloak) (keycloakCommon.KeycloakInterface, error) {
+					return kcClient, nil
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		Name             string
+		Remediate        bool
+		InitObjs         []runtime.Object
+		Drift            func(mockContext *realmMockContext)
+		ExpectedDrifts   int
+		ExpectedUpdated  []string
+		ExpectedRevision string
+	}{
+		{
+			Name:             "exports the realm settings without drift",
+			Drift:            func(mockContext *realmMockContext) {},
+			ExpectedDrifts:   0,
+			ExpectedRevision: "1",
+		},
+		{
+			Name: "reports drift without remediating it",
+			Drift: func(mockContext *realmMockContext) {
+				mockContext.Executions["authdelay"][0].Requirement = "DISABLED"
+			},
+			ExpectedDrifts:   1,
+			ExpectedRevision: "1",
+		},
+		{
+			Name:      "remediates drift",
+			Remediate: true,
+			Drift: func(mockContext *realmMockContext) {
+				mockContext.Executions["authdelay"][0].Requirement = "DISABLED"
+				mockContext.Providers["openshift-v4"].Enabled = false
+				mockContext.Groups["rhmi-developers"] = nil
+			},
+			ExpectedDrifts:   0,
+			ExpectedUpdated:  []string{"authdelay/delay-authentication", "openshift-v4", "rhmi-developers/create-realm"},
+			ExpectedRevision: "1",
+		},
+		{
+			Name: "increases the revision when the export changes",
+			InitObjs: []runtime.Object{
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      cmKey.Name,
+						Namespace: cmKey.Namespace,
+						Annotations: map[string]string{
+							realmExportRevisionAnnotation: "3",
+							realmExportedAtAnnotation:     time.Now().Add(-10 * time.Minute).UTC().Format(time.RFC3339),
+						},
+					},
+					Data: map[string]string{realmExportKey: "{}"},
+				},
+			},
+			Drift:            func(mockContext *realmMockContext) {},
+			ExpectedDrifts:   0,
+			ExpectedRevision: "4",
+		},
+		{
+			Name: "skips the export within the export interval",
+			InitObjs: []runtime.Object{
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      cmKey.Name,
+						Namespace: cmKey.Namespace,
+						Annotations: map[string]string{
+							realmExportRevisionAnnotation: "3",
+							realmExportedAtAnnotation:     time.Now().UTC().Format(time.RFC3339),
+						},
+					},
+					Data: map[string]string{realmExportKey: "{}", realmDriftKey: "[]"},
+				},
+			},
+			Drift: func(mockContext *realmMockContext) {
+				mockContext.Executions["authdelay"][0].Requirement = "DISABLED"
+			},
+			ExpectedDrifts:   0,
+			ExpectedRevision: "3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			kcClient, mockContext := newRealmMocks()
+			tt.Drift(mockContext)
+
+			serverClient := fakeclient.NewFakeClientWithScheme(scheme, tt.InitObjs...)
+			ssoCommon := &config.RHSSOCommon{Config: config.ProductConfig{}}
+			ssoCommon.SetRealmDriftRemediation(tt.Remediate)
+
+			err := buildReconciler(kcClient).ReconcileRealmDrift(context.TODO(), serverClient, kc, ssoCommon, "rhsso", "rhsso", getTestRealmSettings())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			cm := &corev1.ConfigMap{}
+			if err := serverClient.Get(context.TODO(), cmKey, cm); err != nil {
+				t.Fatalf("failed to get realm export config map: %v", err)
+			}
+			if cm.Annotations[realmExportRevisionAnnotation] != tt.ExpectedRevision {
+				t.Errorf("expected revision %s, got %s", tt.ExpectedRevision, cm.Annotations[realmExportRevisionAnnotation])
+			}
+
+			drifts := []RealmDrift{}
+			if err := json.Unmarshal([]byte(cm.Data[realmDriftKey]), &drifts); err != nil {
+				t.Fatalf("failed to parse drift export: %v", err)
+			}
+			if len(drifts) != tt.ExpectedDrifts {
+				t.Errorf("expected %d drifts, got %v", tt.ExpectedDrifts, drifts)
+			}
+			if !reflect.DeepEqual(mockContext.Updated, tt.ExpectedUpdated) {
+				t.Errorf("expected updates %v, got %v", tt.ExpectedUpdated, mockContext.Updated)
+			}
+		})
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/integr8ly/integreatly-operator/pkg/products/observability"
 	"github.com/integr8ly/integreatly-operator/pkg/resources"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/backup"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/clusterstorage"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/constants"
 	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/marketplace"
@@ -54,21 +55,23 @@ type Reconciler struct {
 	Oauthv1Client oauthClient.OauthV1Interface
 	APIURL        string
 	*resources.Reconciler
-	Recorder              record.EventRecorder
-	KeycloakClientFactory keycloakCommon.KeycloakClientFactory
+	Recorder                   record.EventRecorder
+	KeycloakClientFactory      keycloakCommon.KeycloakClientFactory
+	KeycloakAdminClientFactory KeycloakAdminClientFactory
 }
 
 func NewReconciler(configManager config.ConfigReadWriter, mpm marketplace.MarketplaceInterface, installation *integreatlyv1alpha1.RHMI, logger l.Logger, oauthv1Client oauthClient.OauthV1Interface, recorder record.EventRecorder, APIURL string, keycloakClientFactory keycloakCommon.KeycloakClientFactory, productDeclaration marketplace.ProductDeclaration) *Reconciler {
 	return &Reconciler{
-		ConfigManager:         configManager,
-		mpm:                   mpm,
-		Installation:          installation,
-		Log:                   logger,
-		Oauthv1Client:         oauthv1Client,
-		APIURL:                APIURL,
-		Reconciler:            resources.NewReconciler(mpm).WithProductDeclaration(productDeclaration),
-		Recorder:              recorder,
-		KeycloakClientFactory: keycloakClientFactory,
+		ConfigManager:              configManager,
+		mpm:                        mpm,
+		Installation:               installation,
+		Log:                        logger,
+		Oauthv1Client:              oauthv1Client,
+		APIURL:                     APIURL,
+		Reconciler:                 resources.NewReconciler(mpm).WithProductDeclaration(productDeclaration),
+		Recorder:                   recorder,
+		KeycloakClientFactory:      keycloakClientFactory,
+		KeycloakAdminClientFactory: NewKeycloakAdminClient,
 	}
 }
 
@@ -333,6 +336,10 @@ func (r *Reconciler) SyncOpenshiftIDPClientSecret(ctx context.Context, serverCli
 		r.Log.Error("Failed to get identity provider via keycloak api", err)
 		return fmt.Errorf("failed to get identity provider via keycloak api %w", err)
 	}
+	if idp == nil {
+		r.Log.Warningf("Identity provider not found, skipping client secret sync", l.Fields{"idpAlias": idpAlias})
+		return nil
+	}
 
 	if idp.Config == nil {
 		idp.Config = map[string]string{}
@@ -433,6 +440,9 @@ func (r *Reconciler) ReconcileCloudResources(dbPRefix string, defaultNamespace s
 }
 
 func (r *Reconciler) PreUpgradeBackupsExecutor(resourceName string) backup.BackupExecutor {
+	if resources.UsesClusterStorage(r.Installation) {
+		return clusterstorage.NewBackupExecutor(resourceName, r.Installation.Namespace)
+	}
 	if r.Installation.Spec.UseClusterStorage != "false" {
 		return backup.NewNoopBackupExecutor()
 	}
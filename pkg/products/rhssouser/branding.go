@@ -0,0 +1,358 @@
+package rhssouser
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/products/rhssocommon"
+	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+	keycloak "github.com/keycloak/keycloak-operator/pkg/apis/keycloak/v1alpha1"
+	k8sappsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	brandingConfigMapName = "user-sso-branding"
+	brandingVolumeName    = "branding-theme"
+	brandingThemesPath    = "/opt/eap/themes"
+
+	// brandingHashEnvVar is set on the Keycloak pods so that they are rolled
+	// out, and the theme cache is cleared, whenever the branding changes
+	brandingHashEnvVar = "BRANDING_THEME_HASH"
+
+	// brandingLoadTimeout is how long Keycloak has to load a mounted theme
+	// before the branding is rolled back
+	brandingLoadTimeout = 15 * time.Minute
+)
+
+var brandingPathSegmentRegexp = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9._-]*$`)
+
+// brandingState is the branding theme to be mounted in Keycloak
+type brandingState struct {
+	Branding *integreatlyv1alpha1.Branding
+	Hash     string
+	Items    []corev1.KeyToPath
+}
+
+// getBranding returns the RHMIConfig and the branding theme to be mounted in
+// Keycloak, or a nil state if there's no branding, it's invalid or it failed
+// to load before. The files of ConfigMap themes are copied to the user SSO
+// namespace so that they can be mounted
+func (r *Reconciler) getBranding(ctx context.Context, serverClient k8sclient.Client) (*integreatlyv1alpha1.RHMIConfig, *brandingState, error) {
+	rhmiConfig := &integreatlyv1alpha1.RHMIConfig{}
+	err := serverClient.Get(ctx, k8sclient.ObjectKey{Name: "rhmi-config", Namespace: r.ConfigManager.GetOperatorNamespace()}, rhmiConfig)
+	if k8serr.IsNotFound(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get rhmi config: %w", err)
+	}
+
+	branding := rhmiConfig.Spec.Branding
+	if branding == nil {
+		return rhmiConfig, nil, nil
+	}
+
+	state := &brandingState{Branding: branding}
+	hashInputs := []string{branding.ThemeName, branding.ArchiveURL, strings.Join(branding.GetThemeTypes(), ",")}
+
+	if branding.ConfigMapRef != "" {
+		source := &corev1.ConfigMap{}
+		err := serverClient.Get(ctx, k8sclient.ObjectKey{Name: branding.ConfigMapRef, Namespace: r.ConfigManager.GetOperatorNamespace()}, source)
+		if k8serr.IsNotFound(err) {
+			return rhmiConfig, nil, r.setBrandingStatus(ctx, serverClient, rhmiConfig, integreatlyv1alpha1.BrandingPhaseFailed, fmt.Sprintf("ConfigMap %s not found", branding.ConfigMapRef))
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get branding config map: %w", err)
+		}
+
+		items, err := brandingItems(source, branding.GetThemeTypes())
+		if err != nil {
+			return rhmiConfig, nil, r.setBrandingStatus(ctx, serverClient, rhmiConfig, integreatlyv1alpha1.BrandingPhaseFailed, err.Error())
+		}
+		state.Items = items
+
+		for _, item := range items {
+			hashInputs = append(hashInputs, item.Path, source.Data[item.Key], string(source.BinaryData[item.Key]))
+		}
+
+		themeConfigMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      brandingConfigMapName,
+				Namespace: r.Config.GetNamespace(),
+			},
+		}
+		_, err = controllerutil.CreateOrUpdate(ctx, serverClient, themeConfigMap, func() error {
+			themeConfigMap.Data = source.Data
+			themeConfigMap.BinaryData = source.BinaryData
+			return nil
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create/update branding config map: %w", err)
+		}
+	}
+
+	hash := sha256.Sum256([]byte(strings.Join(hashInputs, "\n")))
+	state.Hash = hex.EncodeToString(hash[:])
+
+	if state.Hash == r.Config.GetBrandingFailed() {
+		return rhmiConfig, nil, nil
+	}
+
+	return rhmiConfig, state, nil
+}
+
+// brandingItems maps the keys of the branding ConfigMap to the paths of the
+// files in the theme directory, and ensures that the theme has a
+// theme.properties file for each type
+func brandingItems(cm *corev1.ConfigMap, themeTypes []string) ([]corev1.KeyToPath, error) {
+	keys := []string{}
+	for key := range cm.Data {
+		keys = append(keys, key)
+	}
+	for key := range cm.BinaryData {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	items := []corev1.KeyToPath{}
+	for _, key := range keys {
+		segments := strings.Split(key, integreatlyv1alpha1.BrandingPathSeparator)
+		for _, segment := range segments {
+			if !brandingPathSegmentRegexp.MatchString(segment) {
+				return nil, fmt.Errorf("invalid theme file %s in ConfigMap %s", key, cm.Name)
+			}
+		}
+		items = append(items, corev1.KeyToPath{Key: key, Path: path.Join(segments...)})
+	}
+
+	for _, themeType := range themeTypes {
+		if !hasBrandingPath(items, path.Join(themeType, "theme.properties")) {
+			return nil, fmt.Errorf("ConfigMap %s has no %s theme.properties, expected key %s%stheme.properties", cm.Name, themeType, themeType, integreatlyv1alpha1.BrandingPathSeparator)
+		}
+	}
+
+	return items, nil
+}
+
+func hasBrandingPath(items []corev1.KeyToPath, filePath string) bool {
+	for _, item := range items {
+		if item.Path == filePath {
+			return true
+		}
+	}
+	return false
+}
+
+// applyBranding mounts the branding theme in the Keycloak CR, or removes it
+// if state is nil. Archive themes are added to the extensions, which are
+// expected to be reset by the caller
+func applyBranding(kc *keycloak.Keycloak, state *brandingState) {
+	experimental := &kc.Spec.KeycloakDeploymentSpec.Experimental
+
+	var volumes []keycloak.VolumeSpec
+	for _, volume := range experimental.Volumes.Items {
+		if volume.Name != brandingVolumeName {
+			volumes = append(volumes, volume)
+		}
+	}
+	var env []corev1.EnvVar
+	for _, envVar := range experimental.Env {
+		if envVar.Name != brandingHashEnvVar {
+			env = append(env, envVar)
+		}
+	}
+
+	if state != nil {
+		if state.Branding.ArchiveURL != "" {
+			kc.Spec.Extensions = append(kc.Spec.Extensions, state.Branding.ArchiveURL)
+		} else {
+			volumes = append(volumes, keycloak.VolumeSpec{
+				Name:       brandingVolumeName,
+				MountPath:  path.Join(brandingThemesPath, state.Branding.ThemeName),
+				ConfigMaps: []string{brandingConfigMapName},
+				Items:      state.Items,
+			})
+		}
+		env = append(env, corev1.EnvVar{Name: brandingHashEnvVar, Value: state.Hash})
+	}
+
+	experimental.Volumes.Items = volumes
+	experimental.Env = env
+}
+
+// reconcileBrandingTheme sets the branding theme on the master realm once
+// Keycloak has been rolled out with it and has loaded it. If the theme isn't
+// loaded within brandingLoadTimeout the branding is rolled back: it's marked
+// as failed, so it's no longer mounted, and the realm goes back to the
+// default themes
+func (r *Reconciler) reconcileBrandingTheme(ctx context.Context, serverClient k8sclient.Client, kc *keycloak.Keycloak, rhmiConfig *integreatlyv1alpha1.RHMIConfig, state *brandingState) (integreatlyv1alpha1.StatusPhase, error) {
+	if rhmiConfig != nil && rhmiConfig.Spec.Branding == nil && rhmiConfig.Status.Branding != nil {
+		rhmiConfig.Status.Branding = nil
+		if err := serverClient.Status().Update(ctx, rhmiConfig); err != nil {
+			return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to update rhmi config branding status: %w", err)
+		}
+	}
+
+	applied := r.Config.GetBrandingApplied()
+	pending, pendingSince := r.Config.GetBrandingPending()
+
+	if state == nil {
+		if applied == "" && pending == "" {
+			return integreatlyv1alpha1.PhaseCompleted, nil
+		}
+
+		adminClient, err := r.KeycloakAdminClientFactory(ctx, serverClient, kc)
+		if err != nil {
+			return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to authenticate admin client in keycloak api %w", err)
+		}
+		if err := adminClient.UpdateRealmThemes(&rhssocommon.RealmThemes{}, masterRealmName); err != nil {
+			return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to reset the master realm themes: %w", err)
+		}
+		r.Log.Info("Reset the master realm themes to the defaults")
+
+		r.Config.SetBrandingApplied("")
+		r.Config.SetBrandingPending("", time.Time{})
+		if err := r.ConfigManager.WriteConfig(r.Config); err != nil {
+			return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to write user sso config: %w", err)
+		}
+		return integreatlyv1alpha1.PhaseCompleted, nil
+	}
+
+	if state.Hash == applied {
+		return integreatlyv1alpha1.PhaseCompleted, r.setBrandingStatus(ctx, serverClient, rhmiConfig, integreatlyv1alpha1.BrandingPhaseApplied, "")
+	}
+
+	if state.Hash != pending {
+		pendingSince = time.Now()
+		r.Config.SetBrandingPending(state.Hash, pendingSince)
+		if err := r.ConfigManager.WriteConfig(r.Config); err != nil {
+			return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to write user sso config: %w", err)
+		}
+	}
+
+	loaded, err := r.isBrandingLoaded(ctx, serverClient, kc, state)
+	if err != nil {
+		return integreatlyv1alpha1.PhaseFailed, err
+	}
+
+	if !loaded {
+		if time.Since(pendingSince) < brandingLoadTimeout {
+			r.Log.Infof("Waiting for Keycloak to load the branding theme", l.Fields{"theme": state.Branding.ThemeName})
+			return integreatlyv1alpha1.PhaseCompleted, r.setBrandingStatus(ctx, serverClient, rhmiConfig, integreatlyv1alpha1.BrandingPhasePending, "Waiting for Keycloak to load the theme")
+		}
+
+		message := fmt.Sprintf("Keycloak didn't load theme %s within %s, the branding was rolled back", state.Branding.ThemeName, brandingLoadTimeout)
+		r.Log.Warning(message)
+		r.Recorder.Event(rhmiConfig, corev1.EventTypeWarning, "BrandingRolledBack", message)
+
+		r.Config.SetBrandingFailed(state.Hash)
+		r.Config.SetBrandingPending("", time.Time{})
+		if err := r.ConfigManager.WriteConfig(r.Config); err != nil {
+			return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to write user sso config: %w", err)
+		}
+		return integreatlyv1alpha1.PhaseCompleted, r.setBrandingStatus(ctx, serverClient, rhmiConfig, integreatlyv1alpha1.BrandingPhaseFailed, message)
+	}
+
+	adminClient, err := r.KeycloakAdminClientFactory(ctx, serverClient, kc)
+	if err != nil {
+		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to authenticate admin client in keycloak api %w", err)
+	}
+	themes := &rhssocommon.RealmThemes{}
+	for _, themeType := range state.Branding.GetThemeTypes() {
+		switch themeType {
+		case integreatlyv1alpha1.BrandingThemeTypeLogin:
+			themes.LoginTheme = state.Branding.ThemeName
+		case integreatlyv1alpha1.BrandingThemeTypeAccount:
+			themes.AccountTheme = state.Branding.ThemeName
+		case integreatlyv1alpha1.BrandingThemeTypeEmail:
+			themes.EmailTheme = state.Branding.ThemeName
+		}
+	}
+	if err := adminClient.UpdateRealmThemes(themes, masterRealmName); err != nil {
+		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to set the master realm themes: %w", err)
+	}
+	r.Log.Infof("Set the branding theme on the master realm", l.Fields{"theme": state.Branding.ThemeName})
+
+	r.Config.SetBrandingApplied(state.Hash)
+	r.Config.SetBrandingPending("", time.Time{})
+	if err := r.ConfigManager.WriteConfig(r.Config); err != nil {
+		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to write user sso config: %w", err)
+	}
+
+	return integreatlyv1alpha1.PhaseCompleted, r.setBrandingStatus(ctx, serverClient, rhmiConfig, integreatlyv1alpha1.BrandingPhaseApplied, "")
+}
+
+// isBrandingLoaded returns true if every Keycloak pod has been rolled out
+// with the branding and the server lists the theme for each of its types
+func (r *Reconciler) isBrandingLoaded(ctx context.Context, serverClient k8sclient.Client, kc *keycloak.Keycloak, state *brandingState) (bool, error) {
+	statefulSet := &k8sappsv1.StatefulSet{}
+	err := serverClient.Get(ctx, k8sclient.ObjectKey{Name: "keycloak", Namespace: r.Config.GetNamespace()}, statefulSet)
+	if k8serr.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get keycloak stateful set: %w", err)
+	}
+
+	rolledOut := false
+	for _, container := range statefulSet.Spec.Template.Spec.Containers {
+		for _, envVar := range container.Env {
+			if envVar.Name == brandingHashEnvVar && envVar.Value == state.Hash {
+				rolledOut = true
+			}
+		}
+	}
+	replicas := int32(1)
+	if statefulSet.Spec.Replicas != nil {
+		replicas = *statefulSet.Spec.Replicas
+	}
+	if !rolledOut || statefulSet.Status.UpdatedReplicas < replicas || statefulSet.Status.ReadyReplicas < replicas {
+		return false, nil
+	}
+
+	adminClient, err := r.KeycloakAdminClientFactory(ctx, serverClient, kc)
+	if err != nil {
+		return false, fmt.Errorf("failed to authenticate admin client in keycloak api %w", err)
+	}
+	themes, err := adminClient.ListThemes()
+	if err != nil {
+		return false, fmt.Errorf("failed to list keycloak themes: %w", err)
+	}
+	for _, themeType := range state.Branding.GetThemeTypes() {
+		if !contains(themes[themeType], state.Branding.ThemeName) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func (r *Reconciler) setBrandingStatus(ctx context.Context, serverClient k8sclient.Client, rhmiConfig *integreatlyv1alpha1.RHMIConfig, phase integreatlyv1alpha1.BrandingPhase, message string) error {
+	status := &integreatlyv1alpha1.RHMIConfigStatusBranding{
+		ThemeName: rhmiConfig.Spec.Branding.ThemeName,
+		Phase:     phase,
+		Message:   message,
+	}
+	if rhmiConfig.Status.Branding != nil && *rhmiConfig.Status.Branding == *status {
+		return nil
+	}
+
+	rhmiConfig.Status.Branding = status
+	if err := serverClient.Status().Update(ctx, rhmiConfig); err != nil {
+		return fmt.Errorf("failed to update rhmi config branding status: %w", err)
+	}
+	return nil
+}
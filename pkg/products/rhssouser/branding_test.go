@@ -0,0 +1,286 @@
+package rhssouser
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/config"
+	"github.com/integr8ly/integreatly-operator/pkg/products/rhssocommon"
+	keycloak "github.com/keycloak/keycloak-operator/pkg/apis/keycloak/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestBrandingItems(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Data          map[string]string
+		BinaryData    map[string][]byte
+		ThemeTypes    []string
+		ExpectedItems []corev1.KeyToPath
+		ExpectError   bool
+	}{
+		{
+			Name: "maps keys to theme paths",
+			Data: map[string]string{
+				"login__theme.properties":                 "parent=rh-sso",
+				"login__resources__css__custom.css":       "body {}",
+				"login__messages__messages_en.properties": "loginTitle=Welcome",
+			},
+			BinaryData: map[string][]byte{
+				"login__resources__img__logo.png": []byte("png"),
+			},
+			ThemeTypes: []string{integreatlyv1alpha1.BrandingThemeTypeLogin},
+			ExpectedItems: []corev1.KeyToPath{
+				{Key: "login__messages__messages_en.properties", Path: "login/messages/messages_en.properties"},
+				{Key: "login__resources__css__custom.css", Path: "login/resources/css/custom.css"},
+				{Key: "login__resources__img__logo.png", Path: "login/resources/img/logo.png"},
+				{Key: "login__theme.properties", Path: "login/theme.properties"},
+			},
+		},
+		{
+			Name:        "missing theme.properties",
+			Data:        map[string]string{"login__theme.properties": "parent=rh-sso"},
+			ThemeTypes:  integreatlyv1alpha1.BrandingThemeTypes,
+			ExpectError: true,
+		},
+		{
+			Name: "path traversal",
+			Data: map[string]string{
+				"login__theme.properties":   "parent=rh-sso",
+				"login__..__standalone.xml": "",
+			},
+			ThemeTypes:  []string{integreatlyv1alpha1.BrandingThemeTypeLogin},
+			ExpectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			cm := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "branding"},
+				Data:       tt.Data,
+				BinaryData: tt.BinaryData,
+			}
+
+			items, err := brandingItems(cm, tt.ThemeTypes)
+			if tt.ExpectError {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(items, tt.ExpectedItems) {
+				t.Errorf("expected items %v, got %v", tt.ExpectedItems, items)
+			}
+		})
+	}
+}
+
+func TestApplyBranding(t *testing.T) {
+	metricsExtension := "https://example.com/metrics.jar"
+	otherVolume := keycloak.VolumeSpec{Name: "other", MountPath: "/other", ConfigMaps: []string{"other"}}
+
+	state := &brandingState{
+		Branding: &integreatlyv1alpha1.Branding{ThemeName: "acme", ConfigMapRef: "acme-theme"},
+		Hash:     "hash",
+		Items:    []corev1.KeyToPath{{Key: "login__theme.properties", Path: "login/theme.properties"}},
+	}
+
+	kc := &keycloak.Keycloak{}
+	kc.Spec.Extensions = []string{metricsExtension}
+	kc.Spec.KeycloakDeploymentSpec.Experimental.Volumes.Items = []keycloak.VolumeSpec{otherVolume}
+
+	applyBranding(kc, state)
+	applyBranding(kc, state)
+
+	expectedVolumes := []keycloak.VolumeSpec{
+		otherVolume,
+		{
+			Name:       brandingVolumeName,
+			MountPath:  "/opt/eap/themes/acme",
+			ConfigMaps: []string{brandingConfigMapName},
+			Items:      state.Items,
+		},
+	}
+	if !reflect.DeepEqual(kc.Spec.KeycloakDeploymentSpec.Experimental.Volumes.Items, expectedVolumes) {
+		t.Errorf("expected volumes %v, got %v", expectedVolumes, kc.Spec.KeycloakDeploymentSpec.Experimental.Volumes.Items)
+	}
+	expectedEnv := []corev1.EnvVar{{Name: brandingHashEnvVar, Value: "hash"}}
+	if !reflect.DeepEqual(kc.Spec.KeycloakDeploymentSpec.Experimental.Env, expectedEnv) {
+		t.Errorf("expected env %v, got %v", expectedEnv, kc.Spec.KeycloakDeploymentSpec.Experimental.Env)
+	}
+
+	kc.Spec.Extensions = []string{metricsExtension}
+	applyBranding(kc, &brandingState{
+		Branding: &integreatlyv1alpha1.Branding{ThemeName: "acme", ArchiveURL: "https://example.com/acme.jar"},
+		Hash:     "archive",
+	})
+	if !reflect.DeepEqual(kc.Spec.Extensions, []string{metricsExtension, "https://example.com/acme.jar"}) {
+		t.Errorf("expected the theme archive in the extensions, got %v", kc.Spec.Extensions)
+	}
+	if !reflect.DeepEqual(kc.Spec.KeycloakDeploymentSpec.Experimental.Volumes.Items, []keycloak.VolumeSpec{otherVolume}) {
+		t.Errorf("expected the branding volume to be removed, got %v", kc.Spec.KeycloakDeploymentSpec.Experimental.Volumes.Items)
+	}
+
+	kc.Spec.Extensions = []string{metricsExtension}
+	applyBranding(kc, nil)
+	if len(kc.Spec.KeycloakDeploymentSpec.Experimental.Env) != 0 {
+		t.Errorf("expected the branding env var to be removed, got %v", kc.Spec.KeycloakDeploymentSpec.Experimental.Env)
+	}
+}
+
+func TestReconciler_reconcileBrandingTheme(t *testing.T) {
+	scheme, err := getBuildScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	branding := &integreatlyv1alpha1.Branding{
+		ThemeName:  "acme",
+		ArchiveURL: "https://example.com/acme.jar",
+		ThemeTypes: []string{integreatlyv1alpha1.BrandingThemeTypeLogin},
+	}
+	replicas := int32(2)
+	statefulSet := func(hash string, ready int32) *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "keycloak", Namespace: "user-sso"},
+			Spec: appsv1.StatefulSetSpec{
+				Replicas: &replicas,
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{Name: "keycloak", Env: []corev1.EnvVar{{Name: brandingHashEnvVar, Value: hash}}},
+						},
+					},
+				},
+			},
+			Status: appsv1.StatefulSetStatus{UpdatedReplicas: ready, ReadyReplicas: ready},
+		}
+	}
+
+	tests := []struct {
+		Name            string
+		State           *brandingState
+		Config          config.ProductConfig
+		InitObjs        []runtime.Object
+		LoadedThemes    []string
+		ExpectedPhase   integreatlyv1alpha1.BrandingPhase
+		ExpectedThemes  *rhssocommon.RealmThemes
+		ExpectedApplied string
+		ExpectedFailed  string
+	}{
+		{
+			Name:          "waits for the keycloak pods to be rolled out",
+			State:         &brandingState{Branding: branding, Hash: "new"},
+			Config:        config.ProductConfig{},
+			InitObjs:      []runtime.Object{statefulSet("new", 1)},
+			LoadedThemes:  []string{"acme"},
+			ExpectedPhase: integreatlyv1alpha1.BrandingPhasePending,
+		},
+		{
+			Name:            "sets the theme once it's loaded",
+			State:           &brandingState{Branding: branding, Hash: "new"},
+			Config:          config.ProductConfig{},
+			InitObjs:        []runtime.Object{statefulSet("new", 2)},
+			LoadedThemes:    []string{"rh-sso", "acme"},
+			ExpectedPhase:   integreatlyv1alpha1.BrandingPhaseApplied,
+			ExpectedThemes:  &rhssocommon.RealmThemes{LoginTheme: "acme"},
+			ExpectedApplied: "new",
+		},
+		{
+			Name:  "rolls back a theme that isn't loaded in time",
+			State: &brandingState{Branding: branding, Hash: "new"},
+			Config: config.ProductConfig{
+				"BRANDING_APPLIED":       "old",
+				"BRANDING_PENDING":       "new",
+				"BRANDING_PENDING_SINCE": "1",
+			},
+			InitObjs:        []runtime.Object{statefulSet("new", 2)},
+			LoadedThemes:    []string{"rh-sso"},
+			ExpectedPhase:   integreatlyv1alpha1.BrandingPhaseFailed,
+			ExpectedApplied: "old",
+			ExpectedFailed:  "new",
+		},
+		{
+			Name:           "resets the realm themes when the branding is removed",
+			Config:         config.ProductConfig{"BRANDING_APPLIED": "old"},
+			ExpectedThemes: &rhssocommon.RealmThemes{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			rhmiConfig := &integreatlyv1alpha1.RHMIConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "rhmi-config", Namespace: defaultOperatorNamespace},
+			}
+			if tt.State != nil {
+				rhmiConfig.Spec.Branding = tt.State.Branding
+			}
+			serverClient := fakeclient.NewFakeClientWithScheme(scheme, append(tt.InitObjs, rhmiConfig)...)
+
+			var updatedThemes *rhssocommon.RealmThemes
+			adminClient := &rhssocommon.KeycloakAdminInterfaceMock{
+				ListThemesFunc: func() (map[string][]string, error) {
+					return map[string][]string{integreatlyv1alpha1.BrandingThemeTypeLogin: tt.LoadedThemes}, nil
+				},
+				UpdateRealmThemesFunc: func(themes *rhssocommon.RealmThemes, realmName string) error {
+					updatedThemes = themes
+					return nil
+				},
+			}
+
+			tt.Config["NAMESPACE"] = "user-sso"
+			productConfig := config.NewRHSSOUser(tt.Config)
+			r := &Reconciler{
+				Config: productConfig,
+				Log:    getLogger(),
+				Reconciler: &rhssocommon.Reconciler{
+					ConfigManager: basicConfigMock(),
+					Recorder:      setupRecorder(),
+					KeycloakAdminClientFactory: func(ctx context.Context, serverClient k8sclient.Client, kc *keycloak.Keycloak) (rhssocommon.KeycloakAdminInterface, error) {
+						return adminClient, nil
+					},
+				},
+			}
+
+			phase, err := r.reconcileBrandingTheme(context.TODO(), serverClient, &keycloak.Keycloak{}, rhmiConfig, tt.State)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if phase != integreatlyv1alpha1.PhaseCompleted {
+				t.Fatalf("expected phase %s, got %s", integreatlyv1alpha1.PhaseCompleted, phase)
+			}
+
+			if !reflect.DeepEqual(updatedThemes, tt.ExpectedThemes) {
+				t.Errorf("expected realm themes %v, got %v", tt.ExpectedThemes, updatedThemes)
+			}
+			if productConfig.GetBrandingApplied() != tt.ExpectedApplied {
+				t.Errorf("expected applied branding %q, got %q", tt.ExpectedApplied, productConfig.GetBrandingApplied())
+			}
+			if productConfig.GetBrandingFailed() != tt.ExpectedFailed {
+				t.Errorf("expected failed branding %q, got %q", tt.ExpectedFailed, productConfig.GetBrandingFailed())
+			}
+
+			if tt.ExpectedPhase == "" {
+				return
+			}
+			updated := &integreatlyv1alpha1.RHMIConfig{}
+			if err := serverClient.Get(context.TODO(), k8sclient.ObjectKey{Name: "rhmi-config", Namespace: defaultOperatorNamespace}, updated); err != nil {
+				t.Fatal(err)
+			}
+			if updated.Status.Branding == nil || updated.Status.Branding.Phase != tt.ExpectedPhase {
+				t.Errorf("expected branding status phase %s, got %v", tt.ExpectedPhase, updated.Status.Branding)
+			}
+		})
+	}
+}
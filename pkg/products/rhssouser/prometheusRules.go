@@ -165,6 +165,22 @@ func (r *Reconciler) newAlertsReconciler(logger l.Logger, installType string) re
 					},
 				},
 			},
+			{
+				AlertName: "user-sso-realm-drift-alerts",
+				Namespace: operatorNamespace,
+				GroupName: "user-sso-realm-drift.rules",
+				Rules: []monitoringv1.Rule{
+					{
+						Alert: "RHMIUserRhssoRealmSettingsDrift",
+						Annotations: map[string]string{
+							"message": fmt.Sprintf("{{ $value }} managed {{ $labels.kind }} settings of the User SSO {{ $labels.realm }} realm differ from the desired state. See the {{ $labels.realm }}-realm-export config map in namespace %s for details", r.Config.GetNamespace()),
+						},
+						Expr:   intstr.FromString(fmt.Sprintf(`sum by (realm, kind) (rhoam_keycloak_realm_drift{realm='%s'}) > 0`, masterRealmName)),
+						For:    "15m",
+						Labels: map[string]string{"severity": "warning", "product": installationName},
+					},
+				},
+			},
 		},
 	}
 }
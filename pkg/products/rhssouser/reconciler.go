@@ -5,7 +5,9 @@ import (
 	"fmt"
 	olmv1alpha1 "github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators/v1alpha1"
 	"strings"
+	"time"
 
+	"github.com/integr8ly/integreatly-operator/pkg/metrics"
 	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/quota"
 	corev1 "k8s.io/api/core/v1"
@@ -16,6 +18,7 @@ import (
 	"github.com/integr8ly/integreatly-operator/version"
 
 	userHelper "github.com/integr8ly/integreatly-operator/pkg/resources/user"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/usersync"
 
 	"github.com/integr8ly/integreatly-operator/pkg/products/rhsso"
 	keycloakCommon "github.com/integr8ly/keycloak-client/pkg/common"
@@ -338,11 +341,17 @@ func (r *Reconciler) reconcileComponents(ctx context.Context, installation *inte
 		}
 	}
 
+	rhmiConfig, branding, err := r.getBranding(ctx, serverClient)
+	if err != nil {
+		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to get branding: %w", err)
+	}
+
 	or, err := controllerutil.CreateOrUpdate(ctx, serverClient, kc, func() error {
 		owner.AddIntegreatlyOwnerAnnotations(kc, installation)
 		kc.Spec.Extensions = []string{
 			"https://github.com/aerogear/keycloak-metrics-spi/releases/download/2.0.1/keycloak-metrics-spi-2.0.1.jar",
 		}
+		applyBranding(kc, branding)
 		kc.Spec.ExternalDatabase = keycloak.KeycloakExternalDatabase{Enabled: true}
 		kc.Labels = getMasterLabels()
 
@@ -431,7 +440,29 @@ func (r *Reconciler) reconcileComponents(ctx context.Context, installation *inte
 		}
 	}
 
-	phase, err := r.reconcileBrowserAuthFlow(ctx, kc, serverClient)
+	// keep the idp client secret in sync with the oauth client secret, which
+	// is rotated by the bootstrap reconciler
+	err = r.SyncOpenshiftIDPClientSecret(ctx, serverClient, kcClient, r.Config, masterRealmName)
+	if err != nil {
+		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to sync openshift idp client secret: %w", err)
+	}
+
+	phase, err := r.ReconcileIdentityProviders(ctx, serverClient, kc, r.Config, r.Config.RHSSOCommon, masterRealmName, integreatlyv1alpha1.IdentityProviderInstanceRHSSOUser)
+	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+		return phase, err
+	}
+
+	if err := r.RotateAdminCredentials(ctx, serverClient, kc); err != nil {
+		return integreatlyv1alpha1.PhaseInProgress, fmt.Errorf("failed to rotate keycloak admin credentials: %w", err)
+	}
+
+	phase, err = r.reconcileBrandingTheme(ctx, serverClient, kc, rhmiConfig, branding)
+	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+		events.HandleError(r.Recorder, installation, phase, "Failed to reconcile branding theme", err)
+		return phase, err
+	}
+
+	phase, err = r.reconcileBrowserAuthFlow(ctx, kc, serverClient)
 	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
 		events.HandleError(r.Recorder, installation, phase, "Failed to reconcile browser authentication flow", err)
 		return phase, err
@@ -452,9 +483,16 @@ func (r *Reconciler) reconcileComponents(ctx context.Context, installation *inte
 	if err != nil {
 		return integreatlyv1alpha1.PhaseFailed, err
 	}
-	r.reconcileAdminUsers(ctx, serverClient, kcClient, keycloakUsers)
-	if err != nil {
-		return integreatlyv1alpha1.PhaseFailed, err
+
+	// the drift of the realm settings is reported, it doesn't fail the
+	// reconcile
+	if driftErr := r.ReconcileRealmDrift(ctx, serverClient, kc, r.Config.RHSSOCommon, r.Config.GetNamespace(), masterRealmName, getDesiredRealmSettings()); driftErr != nil {
+		r.Log.Error("Failed to export realm settings", driftErr)
+	}
+
+	phase, err = r.reconcileAdminUsers(ctx, serverClient, kcClient, keycloakUsers)
+	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+		return phase, err
 	}
 
 	return integreatlyv1alpha1.PhaseCompleted, nil
@@ -488,18 +526,63 @@ func (r *Reconciler) reconcileGroups(ctx context.Context, serverClient k8sclient
 
 func (r *Reconciler) reconcileAdminUsers(ctx context.Context, serverClient k8sclient.Client, kcClient keycloakCommon.KeycloakInterface, keycloakUsers []keycloak.KeycloakAPIUser) (integreatlyv1alpha1.StatusPhase, error) {
 
+	// Only the users changed since the last sync are created or updated,
+	// unless the role mappings changed
+	syncState, err := usersync.GetState(ctx, serverClient, r.Installation.Namespace)
+	if err != nil {
+		r.Log.Warning("Failed to get user sync state, syncing all users: " + err.Error())
+		syncState = usersync.NewState()
+	}
+	roleMappings, err := userHelper.GetUserRoleMappings(ctx, serverClient, r.Installation.Namespace)
+	if err != nil {
+		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to get user role mappings: %w", err)
+	}
+
+	fingerprint := usersync.Fingerprint(userHelper.UserRoleMappingsInput(roleMappings))
+	plan := syncState.Plan(usersync.ConsumerRHSSOUser, fingerprint, time.Now())
+	if plan.Skip() {
+		r.Log.Info("No user changes since the last sync to the master realm")
+		return integreatlyv1alpha1.PhaseCompleted, nil
+	}
+
+	matchedRoleMappings, err := userHelper.MatchUserRoleMappings(ctx, serverClient, roleMappings)
+	if err != nil {
+		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to match user role mappings: %w", err)
+	}
+
+	phase, err := r.syncAdminUsers(ctx, serverClient, kcClient, keycloakUsers, matchedRoleMappings, plan)
+	if err != nil {
+		metrics.IncUserSyncFailures(usersync.ConsumerRHSSOUser)
+		return phase, err
+	}
+
+	if err := usersync.MarkSynced(ctx, serverClient, r.Installation.Namespace, usersync.ConsumerRHSSOUser, fingerprint, plan.Revision); err != nil {
+		r.Log.Warning("Failed to record the user sync to the master realm: " + err.Error())
+	}
+
+	return phase, nil
+}
+
+func (r *Reconciler) syncAdminUsers(ctx context.Context, serverClient k8sclient.Client, kcClient keycloakCommon.KeycloakInterface, keycloakUsers []keycloak.KeycloakAPIUser, roleMappings map[string][]integreatlyv1alpha1.UserRoleMapping, plan usersync.SyncPlan) (integreatlyv1alpha1.StatusPhase, error) {
+
 	// Sync keycloak with openshift users
-	users, err := syncAdminUsersInMasterRealm(keycloakUsers, ctx, serverClient, r.Config.GetNamespace())
+	users, err := syncAdminUsersInMasterRealm(keycloakUsers, roleMappings, ctx, serverClient, r.Config.GetNamespace())
 	if err != nil {
 		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to synchronize the users: %w", err)
 	}
 
-	// Create / update the synchronized users
+	// Create / update the synchronized users. Users that haven't been
+	// created in keycloak yet are synced along with the changed users
+	plannedUsers := []keycloak.KeycloakAPIUser{}
 	for _, user := range users {
-		if user.UserName == "" {
+		if user.UserName == "" || (!plan.Includes(user.UserName) && user.ID != "") {
 			continue
 		}
+		plannedUsers = append(plannedUsers, user)
+	}
+	users = plannedUsers
 
+	for _, user := range users {
 		// If the ID is not set, check if the user is already on Keycloak,
 		// and set the ID on the CR to avoid the Keycloak operator from trying
 		// to create the user, causing a conflict
@@ -651,7 +734,7 @@ func getMasterLabels() map[string]string {
 	}
 }
 
-func syncAdminUsersInMasterRealm(keycloakUsers []keycloak.KeycloakAPIUser, ctx context.Context, serverClient k8sclient.Client, ns string) ([]keycloak.KeycloakAPIUser, error) {
+func syncAdminUsersInMasterRealm(keycloakUsers []keycloak.KeycloakAPIUser, roleMappings map[string][]integreatlyv1alpha1.UserRoleMapping, ctx context.Context, serverClient k8sclient.Client, ns string) ([]keycloak.KeycloakAPIUser, error) {
 
 	openshiftUsers := &usersv1.UserList{}
 	err := serverClient.List(ctx, openshiftUsers)
@@ -681,9 +764,96 @@ func syncAdminUsersInMasterRealm(keycloakUsers []keycloak.KeycloakAPIUser, ctx c
 	keycloakUsers = promoteKeycloakUsers(keycloakUsers, promoted)
 	keycloakUsers = demoteKeycloakUsers(keycloakUsers, demoted)
 
+	keycloakUsers = addMappedKeycloakUsers(keycloakUsers, openshiftUsers.Items, roleMappings)
+	keycloakUsers = applyRoleMappings(keycloakUsers, openshiftUsers.Items, roleMappings)
+
 	return keycloakUsers, nil
 }
 
+// addMappedKeycloakUsers adds the OpenShift users that are granted RHSSO
+// roles by a mapping but aren't in the master realm yet, with the roles of a
+// non admin user
+func addMappedKeycloakUsers(keycloakUsers []keycloak.KeycloakAPIUser, openshiftUsers []usersv1.User, roleMappings map[string][]integreatlyv1alpha1.UserRoleMapping) []keycloak.KeycloakAPIUser {
+	for _, osUser := range openshiftUsers {
+		if !hasRHSSORoleMapping(roleMappings[strings.ToLower(osUser.Name)]) || getKeyCloakUser(osUser, keycloakUsers) != nil {
+			continue
+		}
+
+		keycloakUsers = append(keycloakUsers, keycloak.KeycloakAPIUser{
+			Enabled:       true,
+			UserName:      osUser.Name,
+			EmailVerified: true,
+			FederatedIdentities: []keycloak.FederatedIdentity{
+				{
+					IdentityProvider: idpAlias,
+					UserID:           string(osUser.UID),
+					UserName:         osUser.Name,
+				},
+			},
+			RealmRoles: []string{"offline_access", "uma_authorization"},
+			ClientRoles: map[string][]string{
+				"account": {
+					"manage-account",
+					"manage-account-links",
+					"view-profile",
+				},
+			},
+		})
+	}
+	return keycloakUsers
+}
+
+// applyRoleMappings adds the realm roles, client roles and groups of the
+// mappings to the keycloak users of the matching OpenShift users. Roles are
+// only ever added, on top of the ones set by the admin sync
+func applyRoleMappings(keycloakUsers []keycloak.KeycloakAPIUser, openshiftUsers []usersv1.User, roleMappings map[string][]integreatlyv1alpha1.UserRoleMapping) []keycloak.KeycloakAPIUser {
+	if len(roleMappings) == 0 {
+		return keycloakUsers
+	}
+
+	for i, kcUser := range keycloakUsers {
+		osUser := getOpenShiftUser(kcUser, openshiftUsers)
+		if osUser == nil {
+			continue
+		}
+
+		for _, mapping := range roleMappings[strings.ToLower(osUser.Name)] {
+			if mapping.RHSSO == nil {
+				continue
+			}
+
+			keycloakUsers[i].RealmRoles = appendMissing(keycloakUsers[i].RealmRoles, mapping.RHSSO.RealmRoles...)
+			keycloakUsers[i].Groups = appendMissing(keycloakUsers[i].Groups, mapping.RHSSO.Groups...)
+			for clientID, roles := range mapping.RHSSO.ClientRoles {
+				if keycloakUsers[i].ClientRoles == nil {
+					keycloakUsers[i].ClientRoles = map[string][]string{}
+				}
+				keycloakUsers[i].ClientRoles[clientID] = appendMissing(keycloakUsers[i].ClientRoles[clientID], roles...)
+			}
+		}
+	}
+
+	return keycloakUsers
+}
+
+func hasRHSSORoleMapping(mappings []integreatlyv1alpha1.UserRoleMapping) bool {
+	for _, mapping := range mappings {
+		if mapping.RHSSO != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func appendMissing(items []string, values ...string) []string {
+	for _, value := range values {
+		if !contains(items, value) {
+			items = append(items, value)
+		}
+	}
+	return items
+}
+
 func addKeycloakUsers(keycloakUsers []keycloak.KeycloakAPIUser, added []usersv1.User) []keycloak.KeycloakAPIUser {
 
 	for _, osUser := range added {
@@ -1191,6 +1361,33 @@ func (r *Reconciler) reconcileFirstLoginAuthFlow(kc *keycloak.Keycloak) (integre
 	return integreatlyv1alpha1.PhaseFailed, err
 }
 
+// getDesiredRealmSettings returns the settings of the master realm set up by
+// the reconciler, to be checked for drift
+func getDesiredRealmSettings() *rhssocommon.RealmSettings {
+	return &rhssocommon.RealmSettings{
+		AuthenticationFlows: []rhssocommon.RealmAuthenticationFlow{
+			{
+				Alias: "browser",
+				Executions: []rhssocommon.RealmExecution{
+					{ProviderID: "identity-provider-redirector", Requirement: string(keycloakCommon.Alternative)},
+				},
+			},
+			{
+				Alias: firstBrokerLoginFlowAlias,
+				Executions: []rhssocommon.RealmExecution{
+					{ProviderID: "idp-review-profile", Requirement: string(keycloakCommon.Disabled)},
+				},
+			},
+		},
+		IdentityProviders: []rhssocommon.RealmIdentityProvider{
+			{Alias: idpAlias, Enabled: true},
+		},
+		Groups: []rhssocommon.RealmGroup{
+			{Name: developersGroupName, RealmRoles: []string{createRealmRoleName}},
+		},
+	}
+}
+
 // Struct to define the desired status of a Keycloak group
 type keycloakGroupSpec struct {
 	Name      string
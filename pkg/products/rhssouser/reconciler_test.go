@@ -1461,3 +1461,39 @@ func createKeycloakInterfaceMock() (keycloakCommon.KeycloakInterface, *mockClien
 func getLogger() l.Logger {
 	return l.NewLoggerWithContext(l.Fields{l.ProductLogContext: integreatlyv1alpha1.ProductRHSSO})
 }
+
+func TestSyncAdminUsersInMasterRealm_roleMappings(t *testing.T) {
+	admin := usersv1.User{ObjectMeta: metav1.ObjectMeta{Name: "admin", UID: "admin-uid"}}
+	developer := usersv1.User{ObjectMeta: metav1.ObjectMeta{Name: "Developer", UID: "developer-uid"}}
+
+	roleMappings := map[string][]integreatlyv1alpha1.UserRoleMapping{
+		"admin": {
+			{Name: "auditors", RHSSO: &integreatlyv1alpha1.RHSSOUserRole{ClientRoles: map[string][]string{"master-realm": {"view-events"}}}},
+		},
+		"developer": {
+			{Name: "viewers", RHSSO: &integreatlyv1alpha1.RHSSOUserRole{RealmRoles: []string{"viewer"}, Groups: []string{"viewers"}}},
+		},
+	}
+
+	keycloakUsers := addKeycloakUsers(nil, []usersv1.User{admin})
+	keycloakUsers = addMappedKeycloakUsers(keycloakUsers, []usersv1.User{admin, developer}, roleMappings)
+	if len(keycloakUsers) != 2 {
+		t.Fatalf("expected the mapped developer to be added, got %d users", len(keycloakUsers))
+	}
+	if hasAdminPrivileges(&keycloakUsers[1]) {
+		t.Fatal("expected the mapped developer not to be granted admin privileges")
+	}
+
+	keycloakUsers = applyRoleMappings(keycloakUsers, []usersv1.User{admin, developer}, roleMappings)
+	if !hasAdminPrivileges(&keycloakUsers[0]) || !contains(keycloakUsers[0].ClientRoles["master-realm"], "view-events") {
+		t.Fatalf("expected the admin to keep its privileges and get the mapped client role, got %v", keycloakUsers[0].ClientRoles)
+	}
+	if !contains(keycloakUsers[1].RealmRoles, "viewer") || !contains(keycloakUsers[1].Groups, "viewers") {
+		t.Fatalf("expected the developer to get the mapped realm role and group, got %v %v", keycloakUsers[1].RealmRoles, keycloakUsers[1].Groups)
+	}
+
+	keycloakUsers = applyRoleMappings(keycloakUsers, []usersv1.User{admin, developer}, roleMappings)
+	if len(keycloakUsers[1].RealmRoles) != 3 {
+		t.Fatalf("expected mapped roles not to be duplicated, got %v", keycloakUsers[1].RealmRoles)
+	}
+}
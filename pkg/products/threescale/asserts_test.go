@@ -79,7 +79,7 @@ func assertInstallationSuccessfull(scenario ThreeScaleTestScenario, configManage
 	if err != nil {
 		return errors.New("Error getting RHSSO config")
 	}
-	authProvider, err := fakeThreeScaleClient.GetAuthenticationProviderByName(rhssoIntegrationName, accessToken)
+	authProvider, err := fakeThreeScaleClient.GetAuthenticationProviderByName(context.TODO(), rhssoIntegrationName, accessToken)
 	if tsIsNotFoundError(err) {
 		return fmt.Errorf("SSO integration was not created")
 	}
@@ -104,11 +104,11 @@ func assertInstallationSuccessfull(scenario ThreeScaleTestScenario, configManage
 	}
 
 	// rhsso users should be users in 3scale. If an rhsso user is also in dedicated-admins group that user should be an admin in 3scale.
-	test1User, _ := fakeThreeScaleClient.GetUser(rhssoTest1.Spec.User.UserName, "accessToken")
+	test1User, _ := fakeThreeScaleClient.GetUser(context.TODO(), rhssoTest1.Spec.User.UserName, "accessToken")
 	if test1User.UserDetails.Role != adminRole {
 		return fmt.Errorf("%s should be an admin user in 3scale", test1User.UserDetails.Username)
 	}
-	test2User, _ := fakeThreeScaleClient.GetUser(rhssoTest2.Spec.User.UserName, "accessToken")
+	test2User, _ := fakeThreeScaleClient.GetUser(context.TODO(), rhssoTest2.Spec.User.UserName, "accessToken")
 	if test2User.UserDetails.Role != memberRole {
 		return fmt.Errorf("%s should be a member user in 3scale", test2User.UserDetails.Username)
 	}
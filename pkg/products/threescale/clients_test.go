@@ -134,7 +134,7 @@ func getThreeScaleClient() *ThreeScaleInterfaceMock {
 		},
 	}
 	return &ThreeScaleInterfaceMock{
-		AddAuthenticationProviderFunc: func(data map[string]string, accessToken string) (response *http.Response, e error) {
+		AddAuthenticationProviderFunc: func(ctx context.Context, data map[string]string, accessToken string) (response *http.Response, e error) {
 			testAuthProviders.AuthProviders = append(testAuthProviders.AuthProviders, &AuthProvider{
 				ProviderDetails: AuthProviderDetails{
 					Kind:                           data["kind"],
@@ -150,10 +150,10 @@ func getThreeScaleClient() *ThreeScaleInterfaceMock {
 				StatusCode: http.StatusCreated,
 			}, nil
 		},
-		GetAuthenticationProvidersFunc: func(accessToken string) (providers *AuthProviders, e error) {
+		GetAuthenticationProvidersFunc: func(ctx context.Context, accessToken string) (providers *AuthProviders, e error) {
 			return testAuthProviders, nil
 		},
-		GetAuthenticationProviderByNameFunc: func(name string, accessToken string) (provider *AuthProvider, e error) {
+		GetAuthenticationProviderByNameFunc: func(ctx context.Context, name string, accessToken string) (provider *AuthProvider, e error) {
 			for _, ap := range testAuthProviders.AuthProviders {
 				if ap.ProviderDetails.Name == name {
 					return ap, nil
@@ -162,10 +162,10 @@ func getThreeScaleClient() *ThreeScaleInterfaceMock {
 
 			return nil, &tsError{message: "Authprovider not found", StatusCode: http.StatusNotFound}
 		},
-		GetUsersFunc: func(accessToken string) (users *Users, e error) {
+		GetUsersFunc: func(ctx context.Context, accessToken string) (users *Users, e error) {
 			return testUsers, nil
 		},
-		GetUserFunc: func(userName string, accessToken string) (user *User, e error) {
+		GetUserFunc: func(ctx context.Context, userName string, accessToken string) (user *User, e error) {
 			for _, user := range testUsers.Users {
 				if user.UserDetails.Username == userName {
 					return user, nil
@@ -174,10 +174,10 @@ func getThreeScaleClient() *ThreeScaleInterfaceMock {
 
 			return nil, fmt.Errorf("user %s not found", userName)
 		},
-		SetFromEmailAddressFunc: func(emailAddress string, accessToken string) (*http.Response, error) {
+		SetFromEmailAddressFunc: func(ctx context.Context, emailAddress string, accessToken string) (*http.Response, error) {
 			return nil, nil
 		},
-		AddUserFunc: func(username string, email string, password string, accessToken string) (response *http.Response, e error) {
+		AddUserFunc: func(ctx context.Context, username string, email string, password string, accessToken string) (response *http.Response, e error) {
 			testUsers.Users = append(testUsers.Users, &User{
 				UserDetails: UserDetails{
 					Role:     memberRole,
@@ -190,7 +190,7 @@ func getThreeScaleClient() *ThreeScaleInterfaceMock {
 				StatusCode: http.StatusCreated,
 			}, nil
 		},
-		SetUserAsAdminFunc: func(userId int, accessToken string) (response *http.Response, e error) {
+		SetUserAsAdminFunc: func(ctx context.Context, userId int, accessToken string) (response *http.Response, e error) {
 			for _, user := range testUsers.Users {
 				if user.UserDetails.Id == userId {
 					user.UserDetails.Role = adminRole
@@ -200,7 +200,7 @@ func getThreeScaleClient() *ThreeScaleInterfaceMock {
 				StatusCode: http.StatusOK,
 			}, nil
 		},
-		SetUserAsMemberFunc: func(userId int, accessToken string) (response *http.Response, e error) {
+		SetUserAsMemberFunc: func(ctx context.Context, userId int, accessToken string) (response *http.Response, e error) {
 			for _, user := range testUsers.Users {
 				if user.UserDetails.Id == userId {
 					user.UserDetails.Role = memberRole
@@ -210,7 +210,7 @@ func getThreeScaleClient() *ThreeScaleInterfaceMock {
 				StatusCode: http.StatusOK,
 			}, nil
 		},
-		CreateTenantFunc: func(accessToken string, account AccountDetail, pw string, email string) (*SignUpAccount, error) {
+		CreateTenantFunc: func(ctx context.Context, accessToken string, account AccountDetail, pw string, email string) (*SignUpAccount, error) {
 			return &SignUpAccount{
 				AccountDetail: AccountDetail{
 					Id:      1,
@@ -219,14 +219,20 @@ func getThreeScaleClient() *ThreeScaleInterfaceMock {
 				},
 			}, nil
 		},
-		ListTenantAccountsFunc: func(accessToken string, page int) ([]AccountDetail, error) {
+		ListTenantAccountsFunc: func(ctx context.Context, accessToken string) ([]AccountDetail, error) {
 			return accounts, nil
 		},
-		DeleteTenantsFunc: func(accessToken string, accounts []AccountDetail) error {
+		DeleteTenantsFunc: func(ctx context.Context, accessToken string, accounts []AccountDetail) error {
 			return nil
 		},
-		DeleteTenantFunc: func(accessToken string, id int) error {
+		DeleteTenantFunc: func(ctx context.Context, accessToken string, id int) error {
 			return nil
 		},
+		ListBackendsFunc: func(ctx context.Context, accessToken string) (*Backends, error) {
+			return &Backends{}, nil
+		},
+		ListServicesFunc: func(ctx context.Context, accessToken string) (*Services, error) {
+			return &Services{}, nil
+		},
 	}
 }
@@ -0,0 +1,415 @@
+package threescale
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+	corev1 "k8s.io/api/core/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// APIs are imported into the 3scale tenant from ConfigMaps labelled with
+// apiImportLabel that hold an OpenAPI document. The annotations below can be
+// used to override the values read from the document
+const (
+	apiImportLabel = "apis.3scale.integreatly.org/import"
+
+	apiImportAnnotationPrefix = "apis.3scale.integreatly.org/"
+	// apiImportNameAnnotation overrides the product and backend name, which
+	// defaults to info.title
+	apiImportNameAnnotation = apiImportAnnotationPrefix + "name"
+	// apiImportPrivateEndpointAnnotation overrides the backend private
+	// endpoint, which defaults to the first entry of servers
+	apiImportPrivateEndpointAnnotation = apiImportAnnotationPrefix + "private-endpoint"
+	// apiImportPathAnnotation is the path the backend is mounted on in the
+	// product. Defaults to "/"
+	apiImportPathAnnotation = apiImportAnnotationPrefix + "path"
+	// apiImportPlansAnnotation is a comma separated list of application plans
+	// to create for the product. Defaults to a single "Basic" plan
+	apiImportPlansAnnotation = apiImportAnnotationPrefix + "plans"
+
+	// managedAPISystemNamePrefix is set on the system name of every backend
+	// and product created from a ConfigMap, so that entries removed from the
+	// cluster can be found and pruned without touching APIs created through
+	// the admin portal
+	managedAPISystemNamePrefix = "rhoam_managed_"
+
+	defaultAPIImportPath = "/"
+	defaultAPIImportPlan = "Basic"
+	hitsMetricSystemName = "hits"
+)
+
+var openAPIOperationMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// openAPIDocument holds the parts of an OpenAPI 3 document used to build the
+// 3scale configuration
+type openAPIDocument struct {
+	Info struct {
+		Title string `json:"title"`
+	} `json:"info"`
+	Servers []struct {
+		URL string `json:"url"`
+	} `json:"servers"`
+	Paths map[string]map[string]interface{} `json:"paths"`
+}
+
+// apiImport is the desired state of a backend and product in 3scale, built
+// from a single ConfigMap
+type apiImport struct {
+	source          *corev1.ConfigMap
+	systemName      string
+	name            string
+	privateEndpoint string
+	path            string
+	plans           []string
+	mappingRules    []apiMappingRule
+}
+
+type apiMappingRule struct {
+	httpMethod string
+	pattern    string
+}
+
+func (m apiMappingRule) key() string {
+	return m.httpMethod + " " + m.pattern
+}
+
+// reconcileDeclarativeAPIs reconciles the backends, products, mapping rules
+// and application plans described by OpenAPI ConfigMaps into the 3scale
+// tenant. Changes made to managed entries through the admin portal are
+// reverted, and entries whose ConfigMap was removed are deleted. Errors for a
+// single ConfigMap are reported as events on it so that one invalid document
+// doesn't block the installation
+func (r *Reconciler) reconcileDeclarativeAPIs(ctx context.Context, serverClient k8sclient.Client) (integreatlyv1alpha1.StatusPhase, error) {
+	configMaps := &corev1.ConfigMapList{}
+	if err := serverClient.List(ctx, configMaps, k8sclient.MatchingLabels{apiImportLabel: "true"}); err != nil {
+		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to list api import configmaps: %w", err)
+	}
+
+	accessToken, err := r.GetAdminToken(ctx, serverClient)
+	if err != nil {
+		return integreatlyv1alpha1.PhaseFailed, err
+	}
+
+	backends, err := r.tsClient.ListBackends(ctx, *accessToken)
+	if err != nil {
+		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to list 3scale backends: %w", err)
+	}
+	services, err := r.tsClient.ListServices(ctx, *accessToken)
+	if err != nil {
+		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to list 3scale products: %w", err)
+	}
+
+	desired := map[string]bool{}
+	for i := range configMaps.Items {
+		configMap := &configMaps.Items[i]
+		if configMap.DeletionTimestamp != nil {
+			continue
+		}
+
+		// the entries of an invalid ConfigMap are kept as they are until it
+		// is fixed or removed
+		desired[apiImportSystemName(configMap)] = true
+
+		api, err := parseAPIImport(configMap)
+		if err != nil {
+			r.log.Warningf("Invalid api import configmap", l.Fields{"ns": configMap.Namespace, "name": configMap.Name, "error": err})
+			r.recorder.Eventf(configMap, corev1.EventTypeWarning, "InvalidAPIImport", "Failed to parse OpenAPI document: %v", err)
+			continue
+		}
+
+		if err := r.reconcileAPIImport(ctx, *accessToken, api, backends, services); err != nil {
+			r.log.Warningf("Failed to reconcile api import", l.Fields{"ns": configMap.Namespace, "name": configMap.Name, "error": err})
+			r.recorder.Eventf(configMap, corev1.EventTypeWarning, "APIImportFailed", "Failed to reconcile API into 3scale: %v", err)
+		}
+	}
+
+	// Products are pruned before backends as 3scale refuses to delete a
+	// backend that is still used by a product
+	for _, service := range services.Services {
+		systemName := service.ServiceDetails.SystemName
+		if !strings.HasPrefix(systemName, managedAPISystemNamePrefix) || desired[systemName] {
+			continue
+		}
+		r.log.Infof("Deleting product of removed api import", l.Fields{"systemName": systemName})
+		if err := r.tsClient.DeleteService(ctx, *accessToken, strconv.Itoa(service.ServiceDetails.Id)); err != nil {
+			return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to delete product %s: %w", systemName, err)
+		}
+	}
+	for _, backend := range backends.Backends {
+		systemName := backend.BackendDetails.SystemName
+		if !strings.HasPrefix(systemName, managedAPISystemNamePrefix) || desired[systemName] {
+			continue
+		}
+		r.log.Infof("Deleting backend of removed api import", l.Fields{"systemName": systemName})
+		if err := r.tsClient.DeleteBackend(ctx, *accessToken, backend.BackendDetails.Id); err != nil {
+			return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to delete backend %s: %w", systemName, err)
+		}
+	}
+
+	return integreatlyv1alpha1.PhaseCompleted, nil
+}
+
+func (r *Reconciler) reconcileAPIImport(ctx context.Context, accessToken string, api *apiImport, backends *Backends, services *Services) error {
+	changed := false
+	record := func(format string, args ...interface{}) {
+		changed = true
+		r.recorder.Eventf(api.source, corev1.EventTypeNormal, "APIImportUpdated", format, args...)
+	}
+
+	backendID := 0
+	for _, backend := range backends.Backends {
+		if backend.BackendDetails.SystemName != api.systemName {
+			continue
+		}
+		backendID = backend.BackendDetails.Id
+		if backend.BackendDetails.PrivateEndpoint != api.privateEndpoint {
+			if err := r.tsClient.UpdateBackend(ctx, accessToken, backendID, api.privateEndpoint); err != nil {
+				return fmt.Errorf("failed to update backend private endpoint: %w", err)
+			}
+			record("Updated private endpoint of backend %s to %s", api.name, api.privateEndpoint)
+		}
+	}
+	if backendID == 0 {
+		id, err := r.tsClient.CreateBackend(ctx, accessToken, api.name, api.systemName, api.privateEndpoint)
+		if err != nil {
+			return fmt.Errorf("failed to create backend: %w", err)
+		}
+		backendID = id
+		record("Created backend %s", api.name)
+	}
+
+	if err := r.reconcileAPIMappingRules(ctx, accessToken, api, backendID, record); err != nil {
+		return err
+	}
+
+	serviceID := ""
+	for _, service := range services.Services {
+		if service.ServiceDetails.SystemName == api.systemName {
+			serviceID = strconv.Itoa(service.ServiceDetails.Id)
+		}
+	}
+	if serviceID == "" {
+		id, err := r.tsClient.CreateService(ctx, accessToken, api.name, api.systemName)
+		if err != nil {
+			return fmt.Errorf("failed to create product: %w", err)
+		}
+		serviceID = id
+		record("Created product %s", api.name)
+	}
+
+	backendUsages, err := r.tsClient.ListBackendUsages(ctx, accessToken, serviceID)
+	if err != nil {
+		return fmt.Errorf("failed to list backend usages: %w", err)
+	}
+	backendUsed := false
+	for _, usage := range backendUsages {
+		if usage.BackendUsageDetails.BackendId != backendID {
+			continue
+		}
+		backendUsed = true
+		if usage.BackendUsageDetails.Path != api.path {
+			if err := r.tsClient.UpdateBackendUsage(ctx, accessToken, serviceID, usage.BackendUsageDetails.Id, api.path); err != nil {
+				return fmt.Errorf("failed to update backend path in product: %w", err)
+			}
+			record("Updated path of backend %s in product to %s", api.name, api.path)
+		}
+	}
+	if !backendUsed {
+		if err := r.tsClient.CreateBackendUsage(ctx, accessToken, serviceID, backendID, api.path); err != nil {
+			return fmt.Errorf("failed to add backend to product: %w", err)
+		}
+		record("Added backend %s to product on path %s", api.name, api.path)
+	}
+
+	if err := r.reconcileAPIApplicationPlans(ctx, accessToken, api, serviceID, record); err != nil {
+		return err
+	}
+
+	if !changed {
+		return nil
+	}
+
+	if err := r.tsClient.DeployProxy(ctx, accessToken, serviceID); err != nil {
+		return fmt.Errorf("failed to deploy product to staging: %w", err)
+	}
+	if _, err := r.tsClient.PromoteProxy(ctx, accessToken, serviceID, "sandbox", "production"); err != nil {
+		return fmt.Errorf("failed to promote product to production: %w", err)
+	}
+	r.recorder.Eventf(api.source, corev1.EventTypeNormal, "APIImportPromoted", "Promoted product %s to production", api.name)
+
+	return nil
+}
+
+// reconcileAPIMappingRules makes the mapping rules of the backend match the
+// operations of the OpenAPI document. Every operation increments the hits
+// metric of the backend
+func (r *Reconciler) reconcileAPIMappingRules(ctx context.Context, accessToken string, api *apiImport, backendID int, record func(string, ...interface{})) error {
+	metrics, err := r.tsClient.ListBackendMetrics(ctx, accessToken, backendID)
+	if err != nil {
+		return fmt.Errorf("failed to list backend metrics: %w", err)
+	}
+	hitsMetricID := 0
+	for _, metric := range metrics.Metrics {
+		// backend metric system names are suffixed with the backend ID
+		systemName := metric.MetricDetails.SystemName
+		if systemName == hitsMetricSystemName || strings.HasPrefix(systemName, hitsMetricSystemName+".") {
+			hitsMetricID = metric.MetricDetails.Id
+		}
+	}
+	if hitsMetricID == 0 {
+		return fmt.Errorf("hits metric not found for backend %d", backendID)
+	}
+
+	mappingRules, err := r.tsClient.ListBackendMappingRules(ctx, accessToken, backendID)
+	if err != nil {
+		return fmt.Errorf("failed to list backend mapping rules: %w", err)
+	}
+
+	desired := map[string]bool{}
+	for _, rule := range api.mappingRules {
+		desired[rule.key()] = true
+	}
+	existing := map[string]bool{}
+	for _, rule := range mappingRules.MappingRules {
+		current := apiMappingRule{httpMethod: rule.MappingRuleDetails.HTTPMethod, pattern: rule.MappingRuleDetails.Pattern}
+		if desired[current.key()] && rule.MappingRuleDetails.MetricId == hitsMetricID && !existing[current.key()] {
+			existing[current.key()] = true
+			continue
+		}
+		if err := r.tsClient.DeleteBackendMappingRule(ctx, accessToken, backendID, rule.MappingRuleDetails.Id); err != nil {
+			return fmt.Errorf("failed to delete mapping rule %s: %w", current.key(), err)
+		}
+		record("Deleted mapping rule %s", current.key())
+	}
+
+	for _, rule := range api.mappingRules {
+		if existing[rule.key()] {
+			continue
+		}
+		if err := r.tsClient.CreateBackendMappingRule(ctx, accessToken, backendID, hitsMetricID, rule.httpMethod, rule.pattern, 1); err != nil {
+			return fmt.Errorf("failed to create mapping rule %s: %w", rule.key(), err)
+		}
+		record("Created mapping rule %s", rule.key())
+	}
+
+	return nil
+}
+
+func (r *Reconciler) reconcileAPIApplicationPlans(ctx context.Context, accessToken string, api *apiImport, serviceID string, record func(string, ...interface{})) error {
+	plans, err := r.tsClient.ListApplicationPlans(ctx, accessToken, serviceID)
+	if err != nil {
+		return fmt.Errorf("failed to list application plans: %w", err)
+	}
+
+	desired := map[string]bool{}
+	for _, plan := range api.plans {
+		desired[plan] = true
+	}
+	existing := map[string]bool{}
+	for _, plan := range plans.Plans {
+		name := plan.ApplicationPlanDetails.Name
+		if desired[name] {
+			existing[name] = true
+			continue
+		}
+		if err := r.tsClient.DeleteApplicationPlan(ctx, accessToken, serviceID, plan.ApplicationPlanDetails.Id); err != nil {
+			return fmt.Errorf("failed to delete application plan %s: %w", name, err)
+		}
+		record("Deleted application plan %s", name)
+	}
+
+	for _, plan := range api.plans {
+		if existing[plan] {
+			continue
+		}
+		if _, err := r.tsClient.CreateApplicationPlan(ctx, accessToken, serviceID, plan); err != nil {
+			return fmt.Errorf("failed to create application plan %s: %w", plan, err)
+		}
+		record("Created application plan %s", plan)
+	}
+
+	return nil
+}
+
+// parseAPIImport builds the desired 3scale configuration from the OpenAPI
+// document in the ConfigMap, which must hold a single key
+func parseAPIImport(configMap *corev1.ConfigMap) (*apiImport, error) {
+	if len(configMap.Data) != 1 {
+		return nil, fmt.Errorf("expected a single OpenAPI document, found %d keys", len(configMap.Data))
+	}
+
+	document := &openAPIDocument{}
+	for _, data := range configMap.Data {
+		if err := yaml.Unmarshal([]byte(data), document); err != nil {
+			return nil, err
+		}
+	}
+
+	api := &apiImport{
+		source:          configMap,
+		systemName:      apiImportSystemName(configMap),
+		name:            document.Info.Title,
+		privateEndpoint: configMap.Annotations[apiImportPrivateEndpointAnnotation],
+		path:            configMap.Annotations[apiImportPathAnnotation],
+		plans:           []string{defaultAPIImportPlan},
+	}
+	if name, ok := configMap.Annotations[apiImportNameAnnotation]; ok {
+		api.name = name
+	}
+	if api.name == "" {
+		return nil, fmt.Errorf("info.title or the %s annotation must be set", apiImportNameAnnotation)
+	}
+	if api.privateEndpoint == "" && len(document.Servers) > 0 {
+		api.privateEndpoint = document.Servers[0].URL
+	}
+	if api.privateEndpoint == "" {
+		return nil, fmt.Errorf("servers or the %s annotation must be set", apiImportPrivateEndpointAnnotation)
+	}
+	if api.path == "" {
+		api.path = defaultAPIImportPath
+	}
+	if plans, ok := configMap.Annotations[apiImportPlansAnnotation]; ok {
+		api.plans = []string{}
+		for _, plan := range strings.Split(plans, ",") {
+			if plan = strings.TrimSpace(plan); plan != "" {
+				api.plans = append(api.plans, plan)
+			}
+		}
+	}
+
+	for path, operations := range document.Paths {
+		for _, method := range openAPIOperationMethods {
+			if _, ok := operations[method]; !ok {
+				continue
+			}
+			api.mappingRules = append(api.mappingRules, apiMappingRule{
+				httpMethod: strings.ToUpper(method),
+				// paths in the document are exact, so the pattern is anchored
+				// to avoid matching longer paths
+				pattern: path + "$",
+			})
+		}
+	}
+	if len(api.mappingRules) == 0 {
+		return nil, fmt.Errorf("no operations found in paths")
+	}
+	sort.Slice(api.mappingRules, func(i, j int) bool {
+		return api.mappingRules[i].key() < api.mappingRules[j].key()
+	})
+
+	return api, nil
+}
+
+// apiImportSystemName returns the system name used for both the backend and
+// product of the ConfigMap. 3scale system names only allow alphanumeric
+// characters, underscores and dashes
+func apiImportSystemName(configMap *corev1.ConfigMap) string {
+	return managedAPISystemNamePrefix + strings.ReplaceAll(fmt.Sprintf("%s_%s", configMap.Namespace, configMap.Name), ".", "_")
+}
@@ -0,0 +1,416 @@
+package threescale
+
+import (
+	"context"
+	"testing"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const petstoreOpenAPI = `
+openapi: 3.0.0
+info:
+  title: Petstore
+servers:
+  - url: http://petstore.petstore.svc:8080
+paths:
+  /pets:
+    get:
+      operationId: listPets
+    post:
+      operationId: createPet
+  /pets/{petId}:
+    parameters:
+      - name: petId
+        in: path
+    get:
+      operationId: showPetById
+`
+
+func petstoreConfigMap(annotations map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "petstore",
+			Namespace:   "petstore",
+			Labels:      map[string]string{apiImportLabel: "true"},
+			Annotations: annotations,
+		},
+		Data: map[string]string{
+			"openapi.yaml": petstoreOpenAPI,
+		},
+	}
+}
+
+func TestParseAPIImport(t *testing.T) {
+	tests := []struct {
+		Name                    string
+		ConfigMap               *corev1.ConfigMap
+		ExpectErr               bool
+		ExpectedName            string
+		ExpectedPrivateEndpoint string
+		ExpectedPlans           []string
+		ExpectedMappingRules    []apiMappingRule
+	}{
+		{
+			Name:                    "test values are read from the document",
+			ConfigMap:               petstoreConfigMap(nil),
+			ExpectedName:            "Petstore",
+			ExpectedPrivateEndpoint: "http://petstore.petstore.svc:8080",
+			ExpectedPlans:           []string{defaultAPIImportPlan},
+			ExpectedMappingRules: []apiMappingRule{
+				{httpMethod: "GET", pattern: "/pets$"},
+				{httpMethod: "GET", pattern: "/pets/{petId}$"},
+				{httpMethod: "POST", pattern: "/pets$"},
+			},
+		},
+		{
+			Name: "test annotations override the document",
+			ConfigMap: petstoreConfigMap(map[string]string{
+				apiImportNameAnnotation:            "Pets",
+				apiImportPrivateEndpointAnnotation: "https://pets.example.com",
+				apiImportPlansAnnotation:           "Gold, Silver,",
+			}),
+			ExpectedName:            "Pets",
+			ExpectedPrivateEndpoint: "https://pets.example.com",
+			ExpectedPlans:           []string{"Gold", "Silver"},
+			ExpectedMappingRules: []apiMappingRule{
+				{httpMethod: "GET", pattern: "/pets$"},
+				{httpMethod: "GET", pattern: "/pets/{petId}$"},
+				{httpMethod: "POST", pattern: "/pets$"},
+			},
+		},
+		{
+			Name: "test error when the configmap has more than one document",
+			ConfigMap: func() *corev1.ConfigMap {
+				configMap := petstoreConfigMap(nil)
+				configMap.Data["other.yaml"] = petstoreOpenAPI
+				return configMap
+			}(),
+			ExpectErr: true,
+		},
+		{
+			Name: "test error when the private endpoint can't be found",
+			ConfigMap: func() *corev1.ConfigMap {
+				configMap := petstoreConfigMap(nil)
+				configMap.Data["openapi.yaml"] = "info:\n  title: Petstore\npaths:\n  /pets:\n    get: {}\n"
+				return configMap
+			}(),
+			ExpectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			api, err := parseAPIImport(tt.ConfigMap)
+			if (err != nil) != tt.ExpectErr {
+				t.Fatalf("unexpected error value, expected error: %v, got: %v", tt.ExpectErr, err)
+			}
+			if tt.ExpectErr {
+				return
+			}
+			if api.name != tt.ExpectedName {
+				t.Fatalf("expected name %s, got %s", tt.ExpectedName, api.name)
+			}
+			if api.privateEndpoint != tt.ExpectedPrivateEndpoint {
+				t.Fatalf("expected private endpoint %s, got %s", tt.ExpectedPrivateEndpoint, api.privateEndpoint)
+			}
+			if api.systemName != "rhoam_managed_petstore_petstore" {
+				t.Fatalf("unexpected system name %s", api.systemName)
+			}
+			if len(api.plans) != len(tt.ExpectedPlans) {
+				t.Fatalf("expected plans %v, got %v", tt.ExpectedPlans, api.plans)
+			}
+			for i := range tt.ExpectedPlans {
+				if api.plans[i] != tt.ExpectedPlans[i] {
+					t.Fatalf("expected plans %v, got %v", tt.ExpectedPlans, api.plans)
+				}
+			}
+			if len(api.mappingRules) != len(tt.ExpectedMappingRules) {
+				t.Fatalf("expected mapping rules %v, got %v", tt.ExpectedMappingRules, api.mappingRules)
+			}
+			for i := range tt.ExpectedMappingRules {
+				if api.mappingRules[i] != tt.ExpectedMappingRules[i] {
+					t.Fatalf("expected mapping rules %v, got %v", tt.ExpectedMappingRules, api.mappingRules)
+				}
+			}
+		})
+	}
+}
+
+// fakeTenantAPIs is an in-memory 3scale tenant for the declarative API tests
+type fakeTenantAPIs struct {
+	backends     *Backends
+	services     *Services
+	mappingRules *MappingRules
+	plans        *ApplicationPlans
+	usages       []*BackendUsage
+
+	createdMappingRules []string
+	deletedMappingRules []int
+	deletedServices     []string
+	deletedBackends     []int
+	updatedUsagePaths   []string
+	createdPlans        []string
+	promoted            bool
+}
+
+func (f *fakeTenantAPIs) client() *ThreeScaleInterfaceMock {
+	return &ThreeScaleInterfaceMock{
+		ListBackendsFunc: func(ctx context.Context, accessToken string) (*Backends, error) {
+			return f.backends, nil
+		},
+		ListServicesFunc: func(ctx context.Context, accessToken string) (*Services, error) {
+			return f.services, nil
+		},
+		CreateBackendFunc: func(ctx context.Context, accessToken, name, systemName, privateEndpoint string) (int, error) {
+			return 10, nil
+		},
+		UpdateBackendFunc: func(ctx context.Context, accessToken string, backendID int, privateEndpoint string) error {
+			return nil
+		},
+		ListBackendMetricsFunc: func(ctx context.Context, accessToken string, backendID int) (*Metrics, error) {
+			return &Metrics{Metrics: []*Metric{{MetricDetails: MetricDetails{Id: 100, SystemName: "hits.10"}}}}, nil
+		},
+		ListBackendMappingRulesFunc: func(ctx context.Context, accessToken string, backendID int) (*MappingRules, error) {
+			return f.mappingRules, nil
+		},
+		CreateBackendMappingRuleFunc: func(ctx context.Context, accessToken string, backendID, metricID int, httpMethod, pattern string, delta int) error {
+			f.createdMappingRules = append(f.createdMappingRules, httpMethod+" "+pattern)
+			return nil
+		},
+		DeleteBackendMappingRuleFunc: func(ctx context.Context, accessToken string, backendID, mappingRuleID int) error {
+			f.deletedMappingRules = append(f.deletedMappingRules, mappingRuleID)
+			return nil
+		},
+		CreateServiceFunc: func(ctx context.Context, accessToken, name, systemName string) (string, error) {
+			return "20", nil
+		},
+		ListBackendUsagesFunc: func(ctx context.Context, accessToken, serviceID string) ([]*BackendUsage, error) {
+			return f.usages, nil
+		},
+		CreateBackendUsageFunc: func(ctx context.Context, accessToken, serviceID string, backendID int, path string) error {
+			return nil
+		},
+		UpdateBackendUsageFunc: func(ctx context.Context, accessToken, serviceID string, backendUsageID int, path string) error {
+			f.updatedUsagePaths = append(f.updatedUsagePaths, path)
+			return nil
+		},
+		ListApplicationPlansFunc: func(ctx context.Context, accessToken, serviceID string) (*ApplicationPlans, error) {
+			return f.plans, nil
+		},
+		CreateApplicationPlanFunc: func(ctx context.Context, accessToken, serviceID, name string) (string, error) {
+			f.createdPlans = append(f.createdPlans, name)
+			return "30", nil
+		},
+		DeleteApplicationPlanFunc: func(ctx context.Context, accessToken, serviceID string, planID int) error {
+			return nil
+		},
+		DeployProxyFunc: func(ctx context.Context, accessToken, serviceID string) error {
+			return nil
+		},
+		PromoteProxyFunc: func(ctx context.Context, accessToken, serviceID, env, to string) (string, error) {
+			f.promoted = true
+			return "", nil
+		},
+		DeleteServiceFunc: func(ctx context.Context, accessToken, serviceID string) error {
+			f.deletedServices = append(f.deletedServices, serviceID)
+			return nil
+		},
+		DeleteBackendFunc: func(ctx context.Context, accessToken string, backendID int) error {
+			f.deletedBackends = append(f.deletedBackends, backendID)
+			return nil
+		},
+	}
+}
+
+func syncedPetstoreTenant() *fakeTenantAPIs {
+	return &fakeTenantAPIs{
+		backends: &Backends{Backends: []*Backend{
+			{BackendDetails: BackendDetails{Id: 10, SystemName: "rhoam_managed_petstore_petstore", PrivateEndpoint: "http://petstore.petstore.svc:8080"}},
+		}},
+		services: &Services{Services: []*Service{
+			{ServiceDetails: ServiceDetails{Id: 20, SystemName: "rhoam_managed_petstore_petstore"}},
+		}},
+		mappingRules: &MappingRules{MappingRules: []*MappingRule{
+			{MappingRuleDetails: MappingRuleDetails{Id: 1, MetricId: 100, HTTPMethod: "GET", Pattern: "/pets$"}},
+			{MappingRuleDetails: MappingRuleDetails{Id: 2, MetricId: 100, HTTPMethod: "GET", Pattern: "/pets/{petId}$"}},
+			{MappingRuleDetails: MappingRuleDetails{Id: 3, MetricId: 100, HTTPMethod: "POST", Pattern: "/pets$"}},
+		}},
+		plans: &ApplicationPlans{Plans: []*ApplicationPlan{
+			{ApplicationPlanDetails: ApplicationPlanDetails{Id: 30, Name: defaultAPIImportPlan}},
+		}},
+		usages: []*BackendUsage{
+			{BackendUsageDetails: BackendUsageDetails{Id: 40, Path: "/", ServiceId: 20, BackendId: 10}},
+		},
+	}
+}
+
+func TestReconciler_reconcileDeclarativeAPIs(t *testing.T) {
+	scheme, err := getBuildScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seed := threeScaleAdminDetailsSecret.DeepCopy()
+	seed.Namespace = defaultInstallationNamespace
+	seed.Data["ADMIN_ACCESS_TOKEN"] = []byte("token")
+
+	tests := []struct {
+		Name          string
+		Tenant        *fakeTenantAPIs
+		ConfigMaps    []*corev1.ConfigMap
+		ExpectedPhase integreatlyv1alpha1.StatusPhase
+		Assertion     func(*testing.T, *fakeTenantAPIs)
+	}{
+		{
+			Name:          "test api is created in an empty tenant",
+			Tenant:        &fakeTenantAPIs{backends: &Backends{}, services: &Services{}, mappingRules: &MappingRules{}, plans: &ApplicationPlans{}},
+			ConfigMaps:    []*corev1.ConfigMap{petstoreConfigMap(nil)},
+			ExpectedPhase: integreatlyv1alpha1.PhaseCompleted,
+			Assertion: func(t *testing.T, tenant *fakeTenantAPIs) {
+				if len(tenant.createdMappingRules) != 3 {
+					t.Fatalf("expected 3 mapping rules to be created, got %v", tenant.createdMappingRules)
+				}
+				if len(tenant.createdPlans) != 1 || tenant.createdPlans[0] != defaultAPIImportPlan {
+					t.Fatalf("expected the default plan to be created, got %v", tenant.createdPlans)
+				}
+				if !tenant.promoted {
+					t.Fatal("expected the product to be promoted")
+				}
+			},
+		},
+		{
+			Name:          "test nothing is promoted when the tenant is in sync",
+			Tenant:        syncedPetstoreTenant(),
+			ConfigMaps:    []*corev1.ConfigMap{petstoreConfigMap(nil)},
+			ExpectedPhase: integreatlyv1alpha1.PhaseCompleted,
+			Assertion: func(t *testing.T, tenant *fakeTenantAPIs) {
+				if len(tenant.createdMappingRules) != 0 || len(tenant.deletedMappingRules) != 0 {
+					t.Fatalf("expected no mapping rule changes, created %v, deleted %v", tenant.createdMappingRules, tenant.deletedMappingRules)
+				}
+				if len(tenant.updatedUsagePaths) != 0 {
+					t.Fatalf("expected no backend path changes, got %v", tenant.updatedUsagePaths)
+				}
+				if tenant.promoted {
+					t.Fatal("expected the product not to be promoted")
+				}
+			},
+		},
+		{
+			Name:          "test change of the path annotation updates the backend usage",
+			Tenant:        syncedPetstoreTenant(),
+			ConfigMaps:    []*corev1.ConfigMap{petstoreConfigMap(map[string]string{apiImportPathAnnotation: "/petstore"})},
+			ExpectedPhase: integreatlyv1alpha1.PhaseCompleted,
+			Assertion: func(t *testing.T, tenant *fakeTenantAPIs) {
+				if len(tenant.updatedUsagePaths) != 1 || tenant.updatedUsagePaths[0] != "/petstore" {
+					t.Fatalf("expected the backend path to be updated to /petstore, got %v", tenant.updatedUsagePaths)
+				}
+				if !tenant.promoted {
+					t.Fatal("expected the product to be promoted")
+				}
+			},
+		},
+		{
+			Name: "test drift in mapping rules is reverted",
+			Tenant: func() *fakeTenantAPIs {
+				tenant := syncedPetstoreTenant()
+				tenant.mappingRules.MappingRules = append(tenant.mappingRules.MappingRules[1:], &MappingRule{
+					MappingRuleDetails: MappingRuleDetails{Id: 4, MetricId: 100, HTTPMethod: "DELETE", Pattern: "/pets$"},
+				})
+				return tenant
+			}(),
+			ConfigMaps:    []*corev1.ConfigMap{petstoreConfigMap(nil)},
+			ExpectedPhase: integreatlyv1alpha1.PhaseCompleted,
+			Assertion: func(t *testing.T, tenant *fakeTenantAPIs) {
+				if len(tenant.deletedMappingRules) != 1 || tenant.deletedMappingRules[0] != 4 {
+					t.Fatalf("expected mapping rule 4 to be deleted, got %v", tenant.deletedMappingRules)
+				}
+				if len(tenant.createdMappingRules) != 1 || tenant.createdMappingRules[0] != "GET /pets$" {
+					t.Fatalf("expected GET /pets$ to be created, got %v", tenant.createdMappingRules)
+				}
+				if !tenant.promoted {
+					t.Fatal("expected the product to be promoted")
+				}
+			},
+		},
+		{
+			Name: "test apis of removed configmaps are pruned",
+			Tenant: func() *fakeTenantAPIs {
+				tenant := syncedPetstoreTenant()
+				tenant.services.Services = append(tenant.services.Services, &Service{ServiceDetails: ServiceDetails{Id: 21, SystemName: "api"}})
+				tenant.backends.Backends = append(tenant.backends.Backends, &Backend{BackendDetails: BackendDetails{Id: 11, SystemName: "api"}})
+				return tenant
+			}(),
+			ExpectedPhase: integreatlyv1alpha1.PhaseCompleted,
+			Assertion: func(t *testing.T, tenant *fakeTenantAPIs) {
+				if len(tenant.deletedServices) != 1 || tenant.deletedServices[0] != "20" {
+					t.Fatalf("expected only the managed product to be deleted, got %v", tenant.deletedServices)
+				}
+				if len(tenant.deletedBackends) != 1 || tenant.deletedBackends[0] != 10 {
+					t.Fatalf("expected only the managed backend to be deleted, got %v", tenant.deletedBackends)
+				}
+			},
+		},
+		{
+			Name:   "test invalid configmaps don't fail the reconcile",
+			Tenant: &fakeTenantAPIs{backends: &Backends{}, services: &Services{}},
+			ConfigMaps: func() []*corev1.ConfigMap {
+				configMap := petstoreConfigMap(nil)
+				configMap.Data["openapi.yaml"] = "paths: {}"
+				return []*corev1.ConfigMap{configMap}
+			}(),
+			ExpectedPhase: integreatlyv1alpha1.PhaseCompleted,
+		},
+		{
+			Name:   "test apis of invalid configmaps are not pruned",
+			Tenant: syncedPetstoreTenant(),
+			ConfigMaps: func() []*corev1.ConfigMap {
+				configMap := petstoreConfigMap(nil)
+				configMap.Data["openapi.yaml"] = "paths: {}"
+				return []*corev1.ConfigMap{configMap}
+			}(),
+			ExpectedPhase: integreatlyv1alpha1.PhaseCompleted,
+			Assertion: func(t *testing.T, tenant *fakeTenantAPIs) {
+				if len(tenant.deletedServices) != 0 || len(tenant.deletedBackends) != 0 {
+					t.Fatalf("expected the product and backend to be kept, deleted %v and %v", tenant.deletedServices, tenant.deletedBackends)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			serverClient := fakeclient.NewFakeClientWithScheme(scheme, seed)
+			for _, configMap := range tt.ConfigMaps {
+				if err := serverClient.Create(context.TODO(), configMap); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			r := &Reconciler{
+				Config: config.NewThreeScale(config.ProductConfig{
+					"NAMESPACE": defaultInstallationNamespace,
+				}),
+				tsClient: tt.Tenant.client(),
+				recorder: record.NewFakeRecorder(50),
+				log:      getLogger(),
+			}
+
+			phase, err := r.reconcileDeclarativeAPIs(context.TODO(), serverClient)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if phase != tt.ExpectedPhase {
+				t.Fatalf("expected phase %s, got %s", tt.ExpectedPhase, phase)
+			}
+			if tt.Assertion != nil {
+				tt.Assertion(t, tt.Tenant)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	envoyapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	envoycore "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
 	listener "github.com/envoyproxy/go-control-plane/envoy/api/v2/listener"
 	route "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
 	v2route "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
@@ -15,6 +16,7 @@ import (
 	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/ratelimit"
 	structpb "google.golang.org/protobuf/types/known/structpb"
+	corev1 "k8s.io/api/core/v1"
 )
 
 const (
@@ -170,6 +172,63 @@ var tsHTTPRateLimitFilter = hcm.HttpFilter{
 	},
 }
 
+// NewRateLimitClusterResource returns the envoy cluster pointing at the
+// marin3r rate limit service
+func NewRateLimitClusterResource(ratelimitService *corev1.Service) *envoyapi.Cluster {
+	ratelimitClusterResource := ratelimit.CreateClusterResource(
+		ratelimitService.Spec.ClusterIP,
+		ratelimit.RateLimitClusterName,
+		getRatelimitServicePort(ratelimitService),
+	)
+	ratelimitClusterResource.Http2ProtocolOptions = &envoycore.Http2ProtocolOptions{}
+
+	return ratelimitClusterResource
+}
+
+// GetAPIcastEnvoyResources returns the envoy clusters and listener that
+// front an APIcast container with the marin3r rate limiting service. It
+// is shared by the managed APIcast gateways and self-managed gateways
+func GetAPIcastEnvoyResources(installation *integreatlyv1alpha1.RHMI, ratelimitService *corev1.Service, clusterName string) ([]*envoyapi.Cluster, []*envoyapi.Listener, error) {
+	// apicast cluster
+	apiCastClusterResource := ratelimit.CreateClusterResource(
+		ApicastContainerAddress,
+		clusterName,
+		ApicastContainerPort,
+	)
+
+	var apicastHTTPFilters []*hcm.HttpFilter
+	var err error
+	// apicast filters based on installation type
+	if !integreatlyv1alpha1.IsRHOAMMultitenant(integreatlyv1alpha1.InstallationType(installation.Spec.Type)) {
+		apicastHTTPFilters, err = getAPICastHTTPFilters()
+	} else {
+		apicastHTTPFilters, err = getMultitenantAPICastHTTPFilters()
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create envoyconfig filters for %s: %w", clusterName, err)
+	}
+
+	// apicast listener
+	apiCastFilters, err := getListenerResourceFilters(
+		getAPICastVirtualHosts(installation, clusterName),
+		apicastHTTPFilters,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	apiCastListenerResource := ratelimit.CreateListenerResource(
+		ApicastListenerName,
+		ApicastEnvoyProxyAddress,
+		ApicastEnvoyProxyPort,
+		apiCastFilters,
+	)
+
+	return []*envoyapi.Cluster{apiCastClusterResource, NewRateLimitClusterResource(ratelimitService)},
+		[]*envoyapi.Listener{apiCastListenerResource},
+		nil
+}
+
 /**
  httpFilters:
 	- &tsHTTPRateLimitFilter
@@ -7,20 +7,23 @@ import (
 	"github.com/integr8ly/integreatly-operator/pkg/products/observability"
 	prometheus "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	"os"
+	"sort"
 
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
-	hcm "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/http_connection_manager/v2"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/quota"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/user"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/usersync"
 
 	"github.com/integr8ly/integreatly-operator/pkg/metrics"
 
 	envoyapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
-	envoycore "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
 	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/secretprovider"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/smtphealth"
 	consolev1 "github.com/openshift/api/console/v1"
 	oauthv1 "github.com/openshift/api/oauth/v1"
 
@@ -29,6 +32,7 @@ import (
 
 	"github.com/integr8ly/integreatly-operator/pkg/products/monitoring"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/backup"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/clusterstorage"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/owner"
 	"github.com/integr8ly/integreatly-operator/version"
 	rbacv1 "k8s.io/api/rbac/v1"
@@ -49,6 +53,7 @@ import (
 	"github.com/integr8ly/integreatly-operator/pkg/products/rhsso"
 	"github.com/integr8ly/integreatly-operator/pkg/resources"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/marketplace"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/secretrotation"
 
 	"github.com/integr8ly/integreatly-operator/pkg/resources/constants"
 	appsv1 "github.com/openshift/api/apps/v1"
@@ -79,6 +84,7 @@ const (
 	externalPostgresSecretName       = "system-database"
 	apicastStagingDCName             = "apicast-staging"
 	apicastProductionDCName          = "apicast-production"
+	ApicastProductionDCName          = apicastProductionDCName
 	backendListenerDCName            = "backend-listener"
 	systemSeedSecretName             = "system-seed"
 	systemMasterApiCastSecretName    = "system-master-apicast"
@@ -432,6 +438,13 @@ func (r *Reconciler) Reconcile(ctx context.Context, installation *integreatlyv1a
 		return phase, err
 	}
 
+	phase, err = r.reconcileDeclarativeAPIs(ctx, serverClient)
+	r.log.Infof("reconcileDeclarativeAPIs", l.Fields{"phase": phase})
+	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+		events.HandleError(r.recorder, installation, phase, "Failed to reconcile declarative APIs", err)
+		return phase, err
+	}
+
 	productStatus.Host = r.Config.GetHost()
 	productStatus.Version = r.Config.GetProductVersion()
 	productStatus.OperatorVersion = r.Config.GetOperatorVersion()
@@ -489,9 +502,14 @@ func (r *Reconciler) getOauthClientSecret(ctx context.Context, serverClient k8sc
 func (r *Reconciler) reconcileSMTPCredentials(ctx context.Context, serverClient k8sclient.Client) (integreatlyv1alpha1.StatusPhase, error) {
 	r.log.Info("Reconciling smtp credentials")
 
+	secretProvider, err := secretprovider.New(serverClient, r.installation.Namespace)
+	if err != nil {
+		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to get secret provider: %w", err)
+	}
+
 	// // get the secret containing smtp credentials
 	credSec := &corev1.Secret{}
-	err := serverClient.Get(ctx, k8sclient.ObjectKey{Name: r.installation.Spec.SMTPSecret, Namespace: r.installation.Namespace}, credSec)
+	credSec.Data, err = secretProvider.GetSecret(ctx, smtphealth.ActiveSecret(r.installation))
 	if err != nil {
 		r.log.Warningf("could not obtain smtp credentials secret", l.Fields{"error": err})
 	}
@@ -920,10 +938,7 @@ func (r *Reconciler) reconcileExternalDatasources(ctx context.Context, serverCli
 	// this will be used by the cloud resources operator to provision a redis instance
 	r.log.Info("Creating backend redis instance")
 	backendRedisName := fmt.Sprintf("%s%s", constants.ThreeScaleBackendRedisPrefix, r.installation.Name)
-	backendRedis, err := croUtil.ReconcileRedis(ctx, serverClient, defaultInstallationNamespace, r.installation.Spec.Type, croUtil.TierProduction, backendRedisName, ns, backendRedisName, ns, false, func(cr metav1.Object) error {
-		owner.AddIntegreatlyOwnerAnnotations(cr, r.installation)
-		return nil
-	})
+	backendRedis, err := resources.ReconcileRedis(ctx, serverClient, r.installation, defaultInstallationNamespace, backendRedisName, ns)
 	if err != nil {
 		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to reconcile backend redis request: %w", err)
 	}
@@ -932,10 +947,7 @@ func (r *Reconciler) reconcileExternalDatasources(ctx context.Context, serverCli
 	// this will be used by the cloud resources operator to provision a redis instance
 	r.log.Info("Creating system redis instance")
 	systemRedisName := fmt.Sprintf("%s%s", constants.ThreeScaleSystemRedisPrefix, r.installation.Name)
-	systemRedis, err := croUtil.ReconcileRedis(ctx, serverClient, defaultInstallationNamespace, r.installation.Spec.Type, croUtil.TierProduction, systemRedisName, ns, systemRedisName, ns, false, func(cr metav1.Object) error {
-		owner.AddIntegreatlyOwnerAnnotations(cr, r.installation)
-		return nil
-	})
+	systemRedis, err := resources.ReconcileRedis(ctx, serverClient, r.installation, defaultInstallationNamespace, systemRedisName, ns)
 	if err != nil {
 		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to reconcile system redis request: %w", err)
 	}
@@ -944,10 +956,7 @@ func (r *Reconciler) reconcileExternalDatasources(ctx context.Context, serverCli
 	// this will be used by the cloud resources operator to provision a postgres instance
 	r.log.Info("Creating postgres instance")
 	postgresName := fmt.Sprintf("%s%s", constants.ThreeScalePostgresPrefix, r.installation.Name)
-	postgres, err := croUtil.ReconcilePostgres(ctx, serverClient, defaultInstallationNamespace, r.installation.Spec.Type, croUtil.TierProduction, postgresName, ns, postgresName, ns, constants.PostgresApplyImmediately, func(cr metav1.Object) error {
-		owner.AddIntegreatlyOwnerAnnotations(cr, r.installation)
-		return nil
-	})
+	postgres, err := resources.ReconcilePostgres(ctx, serverClient, r.installation, defaultInstallationNamespace, postgresName, ns)
 	if err != nil {
 		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to reconcile postgres request: %w", err)
 	}
@@ -1105,7 +1114,7 @@ func (r *Reconciler) reconcileOutgoingEmailAddress(ctx context.Context, serverCl
 		r.log.Info("Failed to get admin token in reconcileOutgoingEmailAddresss: " + err.Error())
 		return integreatlyv1alpha1.PhaseInProgress, err
 	}
-	_, err = r.tsClient.SetFromEmailAddress(existingSMTPFromAddress, *accessToken)
+	_, err = r.tsClient.SetFromEmailAddress(ctx, existingSMTPFromAddress, *accessToken)
 	if err != nil {
 		r.log.Error("Failed to set email from address:", err)
 		return integreatlyv1alpha1.PhaseFailed, err
@@ -1162,14 +1171,28 @@ func (r *Reconciler) reconcileRHSSOIntegration(ctx context.Context, serverClient
 		r.log.Info("Failed to get admin token: " + err.Error())
 		return integreatlyv1alpha1.PhaseInProgress, err
 	}
-	_, err = r.tsClient.GetAuthenticationProviderByName(rhssoIntegrationName, *accessToken)
+	authProvider, err := r.tsClient.GetAuthenticationProviderByName(ctx, rhssoIntegrationName, *accessToken)
 	if err != nil && !tsIsNotFoundError(err) {
 		r.log.Info("Failed to get authentication provider:" + err.Error())
 		return integreatlyv1alpha1.PhaseInProgress, err
 	}
+	// roll out rotations of the oauth client secret to the integration
+	if err == nil && authProvider.ProviderDetails.ClientSecret != clientSecret {
+		res, err := r.tsClient.UpdateAuthenticationProvider(ctx, authProvider.ProviderDetails.Id, map[string]string{
+			"client_secret": clientSecret,
+		}, *accessToken)
+		if err != nil {
+			r.log.Info("Failed to update authentication provider:" + err.Error())
+			return integreatlyv1alpha1.PhaseInProgress, err
+		}
+		if res.StatusCode != http.StatusOK {
+			return integreatlyv1alpha1.PhaseInProgress, fmt.Errorf("failed to update authentication provider %s, status code %d", rhssoIntegrationName, res.StatusCode)
+		}
+		r.log.Infof("Updated authentication provider client secret", l.Fields{"authProvider": rhssoIntegrationName})
+	}
 	if tsIsNotFoundError(err) {
 		site := rhssoConfig.GetHost() + "/auth/realms/" + rhssoRealm
-		res, err := r.tsClient.AddAuthenticationProvider(map[string]string{
+		res, err := r.tsClient.AddAuthenticationProvider(ctx, map[string]string{
 			"kind":                              "keycloak",
 			"name":                              rhssoIntegrationName,
 			"client_id":                         clientID,
@@ -1217,13 +1240,54 @@ func (r *Reconciler) reconcileOpenshiftUsers(ctx context.Context, installation *
 		return integreatlyv1alpha1.PhaseFailed, err
 	}
 
-	tsUsers, err := r.tsClient.GetUsers(*accessToken)
+	openshiftAdminGroup := &usersv1.Group{}
+	err = serverClient.Get(ctx, k8sclient.ObjectKey{Name: "dedicated-admins"}, openshiftAdminGroup)
+	if err != nil && !k8serr.IsNotFound(err) {
+		r.log.Info("Failed to retrieve dedicated admins: " + err.Error())
+		return integreatlyv1alpha1.PhaseInProgress, err
+	}
+
+	// only the users changed since the last sync to 3scale are diffed against
+	// the 3scale users, unless the dedicated admins or the role mappings changed
+	syncState, err := usersync.GetState(ctx, serverClient, installation.Namespace)
+	if err != nil {
+		r.log.Warning("Failed to get user sync state, syncing all users: " + err.Error())
+		syncState = usersync.NewState()
+	}
+	roleMappings, err := userHelper.GetUserRoleMappings(ctx, serverClient, installation.Namespace)
+	if err != nil {
+		r.log.Warning("Failed to get user role mappings: " + err.Error())
+		return integreatlyv1alpha1.PhaseInProgress, err
+	}
+	matchedRoleMappings, err := userHelper.MatchUserRoleMappings(ctx, serverClient, roleMappings)
+	if err != nil {
+		r.log.Warning("Failed to match user role mappings: " + err.Error())
+		return integreatlyv1alpha1.PhaseInProgress, err
+	}
+
+	fingerprint := usersync.Fingerprint(append(userSyncInputs(openshiftAdminGroup), userHelper.UserRoleMappingsInput(roleMappings))...)
+	plan := syncState.Plan(usersync.ConsumerThreeScale, fingerprint, time.Now())
+	kcu, removalPending := plannedKeycloakUsers(kcu, plan)
+	if !plan.Full && len(kcu) == 0 && len(plan.Removed) == 0 {
+		r.log.Info("No user changes since the last sync to 3scale")
+		if !plan.Skip() {
+			if err := usersync.MarkSynced(ctx, serverClient, installation.Namespace, usersync.ConsumerThreeScale, fingerprint, plan.Revision); err != nil {
+				r.log.Warning("Failed to record the user sync to 3scale: " + err.Error())
+			}
+		}
+		return integreatlyv1alpha1.PhaseCompleted, nil
+	}
+	// the keycloak users of the removed users are deleted by the rhsso
+	// reconciler, the sync is retried until they're gone
+	syncFailed := removalPending
+
+	tsUsers, err := r.tsClient.GetUsers(ctx, *accessToken)
 	if err != nil {
 		r.log.Info("Failed to get users:" + err.Error())
 		return integreatlyv1alpha1.PhaseInProgress, err
 	}
 
-	added, deleted, updated := r.getUserDiff(ctx, serverClient, kcu, tsUsers.Users)
+	added, deleted, updated := r.getUserDiff(ctx, serverClient, kcu, plannedThreeScaleUsers(tsUsers.Users, kcu, plan))
 	// reset the user action metric before we re-reconcile
 	// in order to get up to date metrics on user creation
 	metrics.ResetThreeScaleUserAction()
@@ -1237,7 +1301,7 @@ func (r *Reconciler) reconcileOpenshiftUsers(ctx context.Context, installation *
 		if tsUser.UserDetails.Username != *systemAdminUsername {
 			statusCode := http.StatusServiceUnavailable
 
-			res, err := r.tsClient.DeleteUser(tsUser.UserDetails.Id, *accessToken)
+			res, err := r.tsClient.DeleteUser(ctx, tsUser.UserDetails.Id, *accessToken)
 			if err != nil {
 				r.log.Error(fmt.Sprintf("Failed to delete keycloak user %d from 3scale", tsUser.UserDetails.Id), err)
 			} else {
@@ -1248,6 +1312,7 @@ func (r *Reconciler) reconcileOpenshiftUsers(ctx context.Context, installation *
 
 			if statusCode != http.StatusOK {
 				r.log.Error(fmt.Sprintf("Failed to delete keycloak user %d from 3scale with status code %d", tsUser.UserDetails.Id, statusCode), errors.New("error on http request"))
+				syncFailed = true
 			}
 		}
 	}
@@ -1258,23 +1323,25 @@ func (r *Reconciler) reconcileOpenshiftUsers(ctx context.Context, installation *
 
 			if err != nil {
 				r.log.Warning("Failed to get generate keycloak user: " + err.Error())
+				syncFailed = true
 				continue
 			}
 
-			_, err = r.tsClient.UpdateUser(tsUser.UserDetails.Id, strings.ToLower(genKcUser.Spec.User.UserName), tsUser.UserDetails.Email, *accessToken)
+			_, err = r.tsClient.UpdateUser(ctx, tsUser.UserDetails.Id, strings.ToLower(genKcUser.Spec.User.UserName), tsUser.UserDetails.Email, *accessToken)
 			if err != nil {
 				r.log.Warning("Failed to updating 3scale user details: " + err.Error())
+				syncFailed = true
 			}
 		}
 	}
 
 	for _, kcUser := range added {
-		user, _ := r.tsClient.GetUser(strings.ToLower(kcUser.UserName), *accessToken)
+		user, _ := r.tsClient.GetUser(ctx, strings.ToLower(kcUser.UserName), *accessToken)
 		// recheck the user is new.
 		// 3scale user may being update during the update phase
 		if user == nil {
 			statusCode := http.StatusServiceUnavailable
-			res, err := r.tsClient.AddUser(strings.ToLower(kcUser.UserName), strings.ToLower(kcUser.Email), "", *accessToken)
+			res, err := r.tsClient.AddUser(ctx, strings.ToLower(kcUser.UserName), strings.ToLower(kcUser.Email), "", *accessToken)
 
 			if err != nil {
 				r.log.Error(fmt.Sprintf("Failed to add keycloak user %s to 3scale", kcUser.UserName), err)
@@ -1291,6 +1358,7 @@ func (r *Reconciler) reconcileOpenshiftUsers(ctx context.Context, installation *
 
 			if statusCode != http.StatusCreated {
 				r.log.Error(fmt.Sprintf("Failed to add keycloak user %s to 3scale with status code %d", kcUser.UserName, statusCode), errors.New("error on http request"))
+				syncFailed = true
 			}
 		}
 	}
@@ -1301,13 +1369,7 @@ func (r *Reconciler) reconcileOpenshiftUsers(ctx context.Context, installation *
 		return phase, err
 	}
 
-	openshiftAdminGroup := &usersv1.Group{}
-	err = serverClient.Get(ctx, k8sclient.ObjectKey{Name: "dedicated-admins"}, openshiftAdminGroup)
-	if err != nil && !k8serr.IsNotFound(err) {
-		r.log.Info("Failed to retrieve dedicated admins: " + err.Error())
-		return integreatlyv1alpha1.PhaseInProgress, err
-	}
-	newTsUsers, err := r.tsClient.GetUsers(*accessToken)
+	newTsUsers, err := r.tsClient.GetUsers(ctx, *accessToken)
 	if err != nil {
 		r.log.Info("Failed to get users: " + err.Error())
 		return integreatlyv1alpha1.PhaseInProgress, err
@@ -1315,15 +1377,79 @@ func (r *Reconciler) reconcileOpenshiftUsers(ctx context.Context, installation *
 
 	isWorkshop := installation.Spec.Type == string(integreatlyv1alpha1.InstallationTypeWorkshop)
 
-	err = syncOpenshiftAdminMembership(openshiftAdminGroup, newTsUsers, *systemAdminUsername, isWorkshop, r.tsClient, *accessToken)
+	newTsUsers.Users = plannedThreeScaleUsers(newTsUsers.Users, kcu, plan)
+	err = syncOpenshiftAdminMembership(ctx, openshiftAdminGroup, newTsUsers, *systemAdminUsername, isWorkshop, matchedRoleMappings, r.tsClient, *accessToken)
 	if err != nil {
 		r.log.Info("Failed to sync openshift admin membership: " + err.Error())
 		return integreatlyv1alpha1.PhaseInProgress, err
 	}
 
+	// users that failed to sync are retried on the next reconcile
+	if syncFailed {
+		metrics.IncUserSyncFailures(usersync.ConsumerThreeScale)
+		return integreatlyv1alpha1.PhaseCompleted, nil
+	}
+	if err := usersync.MarkSynced(ctx, serverClient, installation.Namespace, usersync.ConsumerThreeScale, fingerprint, plan.Revision); err != nil {
+		r.log.Warning("Failed to record the user sync to 3scale: " + err.Error())
+	}
+
 	return integreatlyv1alpha1.PhaseCompleted, nil
 }
 
+// userSyncInputs returns the dedicated admins synced to 3scale, for use in
+// the user sync fingerprint
+func userSyncInputs(openshiftAdminGroup *usersv1.Group) []string {
+	inputs := []string{}
+	for _, admin := range openshiftAdminGroup.Users {
+		inputs = append(inputs, "admin:"+admin)
+	}
+	return inputs
+}
+
+// plannedKeycloakUsers returns the keycloak users to sync to 3scale: all of
+// them on a full sync, otherwise the changed users and the users without a
+// 3scale user id, as they were created by the rhsso reconciler after the last
+// sync or failed to be added to 3scale. removalPending is true if a removed
+// user still has a keycloak user
+func plannedKeycloakUsers(kcUsers []keycloak.KeycloakAPIUser, plan usersync.SyncPlan) (planned []keycloak.KeycloakAPIUser, removalPending bool) {
+	if plan.Full {
+		return kcUsers, false
+	}
+
+	for _, kcUser := range kcUsers {
+		userName := strings.ToLower(kcUser.UserName)
+		if plan.Removed[userName] {
+			removalPending = true
+			continue
+		}
+		if plan.Changed[userName] || len(kcUser.Attributes[user3ScaleID]) == 0 {
+			planned = append(planned, kcUser)
+		}
+	}
+	return planned, removalPending
+}
+
+// plannedThreeScaleUsers returns the 3scale users of the planned keycloak
+// users and of the users removed since the last sync
+func plannedThreeScaleUsers(tsUsers []*User, plannedKcUsers []keycloak.KeycloakAPIUser, plan usersync.SyncPlan) []*User {
+	if plan.Full {
+		return tsUsers
+	}
+
+	userNames := map[string]bool{}
+	for _, kcUser := range plannedKcUsers {
+		userNames[strings.ToLower(kcUser.UserName)] = true
+	}
+
+	planned := []*User{}
+	for _, tsUser := range tsUsers {
+		if plan.Includes(tsUser.UserDetails.Username) || userNames[strings.ToLower(tsUser.UserDetails.Username)] {
+			planned = append(planned, tsUser)
+		}
+	}
+	return planned
+}
+
 func (r *Reconciler) updateKeycloakUsersAttributeWith3ScaleUserId(ctx context.Context, serverClient k8sclient.Client, kcu []keycloak.KeycloakAPIUser, accessToken *string) (integreatlyv1alpha1.StatusPhase, error) {
 	rhssoConfig, err := r.ConfigManager.ReadRHSSO()
 	if err != nil {
@@ -1332,7 +1458,7 @@ func (r *Reconciler) updateKeycloakUsersAttributeWith3ScaleUserId(ctx context.Co
 
 	userCreated3ScaleName := "3scale_user_created"
 	for _, user := range kcu {
-		tsUser, err := r.tsClient.GetUser(strings.ToLower(user.UserName), *accessToken)
+		tsUser, err := r.tsClient.GetUser(ctx, strings.ToLower(user.UserName), *accessToken)
 		if err != nil {
 			// Continue installation to not block for when users could not be created in 3scale (i.e. too many characters in username)
 			continue
@@ -1383,33 +1509,15 @@ func (r *Reconciler) reconcile3scaleMultiTenancy(ctx context.Context, serverClie
 		return integreatlyv1alpha1.PhaseFailed, err
 	}
 
-	defaultPageSize := 500
-	totalPages := 1
-	if totalIdentities > defaultPageSize {
-		totalPages = totalIdentities / defaultPageSize
-	}
-
-	// adding an extra page in case of accounts set to be deleted
-	totalPages++
-
-	allAccounts := []AccountDetail{}
-	for page := 1; page <= totalPages; page++ {
-		// list 3scale tenant accounts
-
-		r.log.Infof("Retrieving list of MT accounts available ",
-			l.Fields{"Page": page},
-		)
-		accounts, err := r.tsClient.ListTenantAccounts(*accessToken, page)
-		if err != nil {
-			r.log.Error("Failed to get accounts from 3scale API:", err)
-			return integreatlyv1alpha1.PhaseFailed, err
-		}
-		allAccounts = append(allAccounts, accounts...)
+	// list 3scale tenant accounts
+	allAccounts, err := r.tsClient.ListTenantAccounts(ctx, *accessToken)
+	if err != nil {
+		r.log.Error("Failed to get accounts from 3scale API:", err)
+		return integreatlyv1alpha1.PhaseFailed, err
 	}
 
 	r.log.Infof("Total of accounts available",
 		l.Fields{
-			"totalPages":                totalPages,
 			"totalOpenshiftUsers":       totalIdentities,
 			"total3scaleTenantAccounts": len(allAccounts),
 		},
@@ -1446,7 +1554,7 @@ func (r *Reconciler) reconcile3scaleMultiTenancy(ctx context.Context, serverClie
 						},
 					)
 
-					err = r.tsClient.ActivateUser(*accessToken, account.Id, user.Id)
+					err = r.tsClient.ActivateUser(ctx, *accessToken, account.Id, user.Id)
 					if err != nil {
 						r.log.Errorf("Error activating user access to new tenant account",
 							l.Fields{
@@ -1527,7 +1635,7 @@ func (r *Reconciler) reconcile3scaleMultiTenancy(ctx context.Context, serverClie
 				},
 			)
 
-			err = r.tsClient.DeleteTenant(*accessToken, account.Id)
+			err = r.tsClient.DeleteTenant(ctx, *accessToken, account.Id)
 			if err != nil {
 				r.log.Errorf("Error deleting broken account",
 					l.Fields{
@@ -1565,7 +1673,7 @@ func (r *Reconciler) reconcile3scaleMultiTenancy(ctx context.Context, serverClie
 		}
 
 		// create account
-		newSignupAccount, err := r.tsClient.CreateTenant(*accessToken, account, pw, emailAddrs[idx])
+		newSignupAccount, err := r.tsClient.CreateTenant(ctx, *accessToken, account, pw, emailAddrs[idx])
 		if err != nil {
 			r.log.Errorf("Error creating tenant account",
 				l.Fields{"tenantAccountName": account.OrgName},
@@ -1600,7 +1708,7 @@ func (r *Reconciler) reconcile3scaleMultiTenancy(ctx context.Context, serverClie
 			"totalAccounts":       len(accountsToBeDeleted),
 		},
 	)
-	r.tsClient.DeleteTenants(*accessToken, accountsToBeDeleted)
+	r.tsClient.DeleteTenants(ctx, *accessToken, accountsToBeDeleted)
 	if err != nil {
 		r.log.Error("Error deleting tenant accounts:", err)
 		return integreatlyv1alpha1.PhaseFailed, err
@@ -1624,6 +1732,10 @@ func (r *Reconciler) reconcile3scaleMultiTenancy(ctx context.Context, serverClie
 		}
 	}
 
+	if err := r.rotateTenantAccountPasswords(ctx, serverClient, *accessToken, allAccounts); err != nil {
+		return integreatlyv1alpha1.PhaseInProgress, err
+	}
+
 	if len(accountsToBeCreated) > 0 {
 		r.log.Infof("Returning in progess as there were accounts created and users need to be activated",
 			l.Fields{"totalAccountsCreated": len(accountsToBeCreated)},
@@ -1694,7 +1806,7 @@ func (r *Reconciler) removeTenantAccountPassword(ctx context.Context, serverClie
 	tenantAccountSecret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: r.Config.GetNamespace(),
-			Name:      "tenant-account-passwords",
+			Name:      secretrotation.TenantAccountPasswordsName,
 		},
 	}
 
@@ -1721,12 +1833,69 @@ func (r *Reconciler) removeTenantAccountPassword(ctx context.Context, serverClie
 	return nil
 }
 
+// rotateTenantAccountPasswords sets new passwords on the admin users of the
+// tenant accounts whose password is older than the rotation policy, and
+// records them in the tenant account passwords secret
+func (r *Reconciler) rotateTenantAccountPasswords(ctx context.Context, serverClient k8sclient.Client, accessToken string, accounts []AccountDetail) error {
+	tenantAccountSecret := &corev1.Secret{}
+	err := serverClient.Get(ctx, k8sclient.ObjectKey{Name: secretrotation.TenantAccountPasswordsName, Namespace: r.Config.GetNamespace()}, tenantAccountSecret)
+	if k8serr.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get tenant account passwords secret: %w", err)
+	}
+	if tenantAccountSecret.Data == nil {
+		tenantAccountSecret.Data = map[string][]byte{}
+	}
+
+	policy := secretrotation.PolicyFor(r.installation, tenantAccountSecret.Name)
+	annotation := tenantAccountSecret.Annotations[secretrotation.LastRotatedAnnotation]
+	rotated, rotateErr := secretrotation.RotateWith(tenantAccountSecret, policy, func(accountName string) ([]byte, error) {
+		account, user, ok := getTenantAdminUser(accounts, accountName)
+		if !ok {
+			// the passwords of deleted accounts are removed with them
+			return nil, nil
+		}
+		pw := resources.GenerateRandomPassword(20, 2, 2, 2)
+		if err := r.tsClient.UpdateTenantUserPassword(ctx, accessToken, account.Id, user.Id, pw); err != nil {
+			return nil, err
+		}
+		return []byte(pw), nil
+	}, time.Now())
+
+	// the passwords already set in 3scale are recorded even when others
+	// failed to rotate
+	if len(rotated) > 0 || tenantAccountSecret.Annotations[secretrotation.LastRotatedAnnotation] != annotation {
+		if err := serverClient.Update(ctx, tenantAccountSecret); err != nil {
+			return fmt.Errorf("failed to update tenant account passwords secret: %w", err)
+		}
+	}
+	return rotateErr
+}
+
+// getTenantAdminUser returns the approved tenant account named accountName
+// and its admin user, which has the name of the account
+func getTenantAdminUser(accounts []AccountDetail, accountName string) (AccountDetail, XMLUserDetails, bool) {
+	for _, account := range accounts {
+		if account.Name != accountName || account.State != "approved" {
+			continue
+		}
+		for _, user := range account.Users.User {
+			if user.Username == accountName {
+				return account, user, true
+			}
+		}
+	}
+	return AccountDetail{}, XMLUserDetails{}, false
+}
+
 func (r *Reconciler) getTenantAccountPassword(ctx context.Context, serverClient k8sclient.Client, account AccountDetail) (string, error) {
 	var pw = ""
 	tenantAccountSecret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: r.Config.GetNamespace(),
-			Name:      "tenant-account-passwords",
+			Name:      secretrotation.TenantAccountPasswordsName,
 		},
 	}
 
@@ -1791,7 +1960,7 @@ func (r *Reconciler) AddAuthProviderToMTAccount(ctx context.Context, serverClien
 	clientID := fmt.Sprintf("%s-%s", multitenantID, tenantID)
 	integration := fmt.Sprintf("%s-%s", rhssoIntegrationName, clientID)
 
-	isAdded, err := r.tsClient.IsAuthProviderAdded(account.AccountAccessToken.Value,
+	isAdded, err := r.tsClient.IsAuthProviderAdded(ctx, account.AccountAccessToken.Value,
 		integration, account.AccountDetail)
 	if err != nil {
 		return err
@@ -1863,7 +2032,7 @@ func (r *Reconciler) AddAuthProviderToMTAccount(ctx context.Context, serverClien
 	}
 	r.log.Infof("auth provider", l.Fields{"authProviderDetails": authProviderDetails})
 
-	err = r.tsClient.AddAuthProviderToAccount(account.AccountAccessToken.Value,
+	err = r.tsClient.AddAuthProviderToAccount(ctx, account.AccountAccessToken.Value,
 		account.AccountDetail, authProviderDetails,
 	)
 	if err != nil {
@@ -1917,6 +2086,13 @@ func getMTAccountsToBeDeleted(usersIdentity []userHelper.MultiTenantUser, accoun
 }
 
 func (r *Reconciler) preUpgradeBackupExecutor() backup.BackupExecutor {
+	if resources.UsesClusterStorage(r.installation) {
+		return backup.NewConcurrentBackupExecutor(
+			clusterstorage.NewBackupExecutor("threescale-postgres-rhmi", r.installation.Namespace),
+			clusterstorage.NewBackupExecutor("threescale-backend-redis-rhmi", r.installation.Namespace),
+			clusterstorage.NewBackupExecutor("threescale-redis-rhmi", r.installation.Namespace),
+		)
+	}
 	if r.installation.Spec.UseClusterStorage != "false" {
 		return backup.NewNoopBackupExecutor()
 	}
@@ -1940,25 +2116,82 @@ func (r *Reconciler) preUpgradeBackupExecutor() backup.BackupExecutor {
 	)
 }
 
-func syncOpenshiftAdminMembership(openshiftAdminGroup *usersv1.Group, newTsUsers *Users, systemAdminUsername string, isWorkshop bool, tsClient ThreeScaleInterface, accessToken string) error {
+func syncOpenshiftAdminMembership(ctx context.Context, openshiftAdminGroup *usersv1.Group, newTsUsers *Users, systemAdminUsername string, isWorkshop bool, roleMappings map[string][]integreatlyv1alpha1.UserRoleMapping, tsClient ThreeScaleInterface, accessToken string) error {
 	for _, tsUser := range newTsUsers.Users {
 		// skip if ts user is the system user admin
 		if tsUser.UserDetails.Username == systemAdminUsername {
 			continue
 		}
 
+		mappedAdmin, allowedSections, mapped := mappedThreeScaleRole(tsUser, roleMappings)
+
 		// In workshop mode, developer users also get admin permissions in 3scale
-		if (userIsOpenshiftAdmin(tsUser, openshiftAdminGroup) || isWorkshop) && tsUser.UserDetails.Role != adminRole {
-			res, err := tsClient.SetUserAsAdmin(tsUser.UserDetails.Id, accessToken)
-			if err != nil || res.StatusCode != http.StatusOK {
+		if userIsOpenshiftAdmin(tsUser, openshiftAdminGroup) || isWorkshop || mappedAdmin {
+			if tsUser.UserDetails.Role != adminRole {
+				res, err := tsClient.SetUserAsAdmin(ctx, tsUser.UserDetails.Id, accessToken)
+				if err != nil {
+					return err
+				}
+				if err := assertStatusCode(http.StatusOK, res); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		// users are never demoted from admin, so the sections of a mapped
+		// member only apply to users that aren't admins already
+		if !mapped || tsUser.UserDetails.Role == adminRole {
+			continue
+		}
+		if tsUser.UserDetails.Role != memberRole {
+			res, err := tsClient.SetUserAsMember(ctx, tsUser.UserDetails.Id, accessToken)
+			if err != nil {
+				return err
+			}
+			if err := assertStatusCode(http.StatusOK, res); err != nil {
 				return err
 			}
 		}
+		res, err := tsClient.SetUserPermissions(ctx, tsUser.UserDetails.Id, allowedSections, accessToken)
+		if err != nil {
+			return err
+		}
+		if err := assertStatusCode(http.StatusOK, res); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
+// mappedThreeScaleRole returns whether the role mappings of the user make it
+// an admin and, otherwise, the union of the sections it is allowed to access.
+// mapped is false when no mapping sets a 3scale role for the user
+func mappedThreeScaleRole(tsUser *User, roleMappings map[string][]integreatlyv1alpha1.UserRoleMapping) (admin bool, allowedSections []string, mapped bool) {
+	sections := map[string]bool{}
+	for _, mapping := range roleMappings[strings.ToLower(tsUser.UserDetails.Username)] {
+		if mapping.ThreeScale == nil {
+			continue
+		}
+		mapped = true
+		if mapping.ThreeScale.Role == integreatlyv1alpha1.ThreeScaleRoleAdmin {
+			admin = true
+		}
+		for _, section := range mapping.ThreeScale.AllowedSections {
+			sections[section] = true
+		}
+	}
+
+	allowedSections = []string{}
+	for section := range sections {
+		allowedSections = append(allowedSections, section)
+	}
+	sort.Strings(allowedSections)
+
+	return admin, allowedSections, mapped
+}
+
 func (r *Reconciler) reconcileServiceDiscovery(ctx context.Context, serverClient k8sclient.Client) (integreatlyv1alpha1.StatusPhase, error) {
 
 	if string(r.Config.GetProductVersion()) != string(integreatlyv1alpha1.Version3Scale) {
@@ -2211,7 +2444,13 @@ func (r *Reconciler) SetAdminDetailsOnSecret(ctx context.Context, serverClient k
 }
 
 func (r *Reconciler) GetAdminToken(ctx context.Context, serverClient k8sclient.Client) (*string, error) {
-	return getToken(ctx, serverClient, r.Config.GetNamespace(), "ADMIN_ACCESS_TOKEN")
+	return GetAdminAccessToken(ctx, serverClient, r.Config.GetNamespace())
+}
+
+// GetAdminAccessToken returns the admin access token of the default 3scale
+// tenant installed in namespace
+func GetAdminAccessToken(ctx context.Context, serverClient k8sclient.Client, namespace string) (*string, error) {
+	return getToken(ctx, serverClient, namespace, "ADMIN_ACCESS_TOKEN")
 }
 
 func (r *Reconciler) GetMasterToken(ctx context.Context, serverClient k8sclient.Client) (*string, error) {
@@ -2847,52 +3086,17 @@ func (r *Reconciler) reconcileRatelimitingTo3scaleComponents(ctx context.Context
 	}
 
 	// rate limit cluster
-	ratelimitClusterResource := ratelimit.CreateClusterResource(
-		ratelimitServiceCR.Spec.ClusterIP,
-		ratelimit.RateLimitClusterName,
-		getRatelimitServicePort(ratelimitServiceCR),
-	)
-	ratelimitClusterResource.Http2ProtocolOptions = &envoycore.Http2ProtocolOptions{}
-
-	// apicast cluster
-	apiCastClusterResource := ratelimit.CreateClusterResource(
-		ApicastContainerAddress,
-		ApicastClusterName,
-		ApicastContainerPort,
-	)
+	ratelimitClusterResource := NewRateLimitClusterResource(ratelimitServiceCR)
 
-	var apicastHTTPFilters []*hcm.HttpFilter
-	// apicast filters based on installation type
-	if !integreatlyv1alpha1.IsRHOAMMultitenant(integreatlyv1alpha1.InstallationType(r.installation.Spec.Type)) {
-		apicastHTTPFilters, err = getAPICastHTTPFilters()
-		if err != nil {
-			r.log.Errorf("Failed to create envoyconfig filters for multitenant RHOAM", l.Fields{"APICast": ApicastClusterName}, err)
-			return integreatlyv1alpha1.PhaseFailed, err
-		}
-	} else {
-		apicastHTTPFilters, err = getMultitenantAPICastHTTPFilters()
-		if err != nil {
-			r.log.Errorf("Failed to create envoyconfig filters for multitenant RHOAM", l.Fields{"APICast": ApicastClusterName}, err)
-			return integreatlyv1alpha1.PhaseFailed, err
-		}
+	apiCastClusterResources, apiCastListenerResources, err := GetAPIcastEnvoyResources(installation, ratelimitServiceCR, ApicastClusterName)
+	if err != nil {
+		r.log.Errorf("Failed to create envoyconfig resources for apicast", l.Fields{"APICast": ApicastClusterName}, err)
+		return integreatlyv1alpha1.PhaseFailed, err
 	}
 
-	// apicast listener
-	apiCastFilters, _ := getListenerResourceFilters(
-		getAPICastVirtualHosts(installation, ApicastClusterName),
-		apicastHTTPFilters,
-	)
-
-	apiCastListenerResource := ratelimit.CreateListenerResource(
-		ApicastListenerName,
-		ApicastEnvoyProxyAddress,
-		ApicastEnvoyProxyPort,
-		apiCastFilters,
-	)
-
 	// create envoy config for apicast
 	apiCastProxyConfig := ratelimit.NewEnvoyConfig(ApicastClusterName, r.Config.GetNamespace(), ApicastNodeID)
-	err = apiCastProxyConfig.CreateEnvoyConfig(ctx, serverClient, []*envoyapi.Cluster{apiCastClusterResource, ratelimitClusterResource}, []*envoyapi.Listener{apiCastListenerResource}, installation)
+	err = apiCastProxyConfig.CreateEnvoyConfig(ctx, serverClient, apiCastClusterResources, apiCastListenerResources, installation)
 	if err != nil {
 		r.log.Errorf("Failed to create envoyconfig for apicast", l.Fields{"APICast": ApicastClusterName}, err)
 		return integreatlyv1alpha1.PhaseFailed, err
@@ -3,8 +3,10 @@ package threescale
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/integr8ly/integreatly-operator/pkg/resources/quota"
@@ -27,6 +29,8 @@ import (
 	"github.com/integr8ly/integreatly-operator/pkg/config"
 	"github.com/integr8ly/integreatly-operator/pkg/resources"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/marketplace"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/secretrotation"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/usersync"
 	keycloak "github.com/keycloak/keycloak-operator/pkg/apis/keycloak/v1alpha1"
 	rbacv1 "k8s.io/api/rbac/v1"
 
@@ -377,7 +381,7 @@ func TestReconciler_syncOpenshiftAdmimMembership(t *testing.T) {
 	calledSetUserAsAdmin := false
 
 	tsClientMock := ThreeScaleInterfaceMock{
-		SetUserAsAdminFunc: func(userID int, accessToken string) (*http.Response, error) {
+		SetUserAsAdminFunc: func(ctx context.Context, userID int, accessToken string) (*http.Response, error) {
 			if userID != 1 {
 				t.Fatalf("Unexpected user promoted to admin. Expected User with ID 1, got user with ID %d", userID)
 			} else {
@@ -388,7 +392,7 @@ func TestReconciler_syncOpenshiftAdmimMembership(t *testing.T) {
 				StatusCode: 200,
 			}, nil
 		},
-		SetUserAsMemberFunc: func(userID int, accessToken string) (*http.Response, error) {
+		SetUserAsMemberFunc: func(ctx context.Context, userID int, accessToken string) (*http.Response, error) {
 			t.Fatalf("Unexpected call to `SetUserAsMember`. Called with userID %d", userID)
 
 			return &http.Response{
@@ -435,7 +439,7 @@ func TestReconciler_syncOpenshiftAdmimMembership(t *testing.T) {
 		},
 	}
 
-	err := syncOpenshiftAdminMembership(openshiftAdminGroup, newTsUsers, "", false, &tsClientMock, "")
+	err := syncOpenshiftAdminMembership(context.TODO(), openshiftAdminGroup, newTsUsers, "", false, nil, &tsClientMock, "")
 
 	if err != nil {
 		t.Fatalf("Unexpected error when reconcilling openshift admin membership: %s", err)
@@ -446,6 +450,60 @@ func TestReconciler_syncOpenshiftAdmimMembership(t *testing.T) {
 	}
 }
 
+func TestReconciler_syncOpenshiftAdminMembershipWithRoleMappings(t *testing.T) {
+	promoted := map[int]bool{}
+	permissions := map[int][]string{}
+
+	tsClientMock := ThreeScaleInterfaceMock{
+		SetUserAsAdminFunc: func(ctx context.Context, userID int, accessToken string) (*http.Response, error) {
+			promoted[userID] = true
+			return &http.Response{StatusCode: http.StatusOK}, nil
+		},
+		SetUserPermissionsFunc: func(ctx context.Context, userID int, allowedSections []string, accessToken string) (*http.Response, error) {
+			permissions[userID] = allowedSections
+			return &http.Response{StatusCode: http.StatusOK}, nil
+		},
+	}
+
+	roleMappings := map[string][]integreatlyv1alpha1.UserRoleMapping{
+		"user1": {
+			{Name: "api-admins", ThreeScale: &integreatlyv1alpha1.ThreeScaleUserRole{Role: integreatlyv1alpha1.ThreeScaleRoleAdmin}},
+		},
+		"user2": {
+			{Name: "portal", ThreeScale: &integreatlyv1alpha1.ThreeScaleUserRole{Role: integreatlyv1alpha1.ThreeScaleRoleMember, AllowedSections: []string{"portal"}}},
+			{Name: "finance", ThreeScale: &integreatlyv1alpha1.ThreeScaleUserRole{Role: integreatlyv1alpha1.ThreeScaleRoleMember, AllowedSections: []string{"finance", "portal"}}},
+		},
+		"user3": {
+			{Name: "portal", ThreeScale: &integreatlyv1alpha1.ThreeScaleUserRole{Role: integreatlyv1alpha1.ThreeScaleRoleMember, AllowedSections: []string{"portal"}}},
+		},
+	}
+
+	newTsUsers := &Users{
+		Users: []*User{
+			// Mapped to the admin role. Should be promoted
+			{UserDetails: UserDetails{Id: 1, Role: memberRole, Username: "User1"}},
+			// Mapped to two member roles. Should get the union of the sections
+			{UserDetails: UserDetails{Id: 2, Role: memberRole, Username: "User2"}},
+			// Mapped to a member role but already admin. Should NOT be demoted
+			{UserDetails: UserDetails{Id: 3, Role: adminRole, Username: "User3"}},
+			// Not mapped. Should be ignored
+			{UserDetails: UserDetails{Id: 4, Role: memberRole, Username: "User4"}},
+		},
+	}
+
+	err := syncOpenshiftAdminMembership(context.TODO(), &usersv1.Group{}, newTsUsers, "", false, roleMappings, &tsClientMock, "")
+	if err != nil {
+		t.Fatalf("Unexpected error when reconcilling openshift admin membership: %s", err)
+	}
+
+	if len(promoted) != 1 || !promoted[1] {
+		t.Fatalf("Expected only user with ID 1 to be promoted as admin, got %v", promoted)
+	}
+	if len(permissions) != 1 || !reflect.DeepEqual(permissions[2], []string{"finance", "portal"}) {
+		t.Fatalf("Expected only user with ID 2 to get the finance and portal sections, got %v", permissions)
+	}
+}
+
 func getLogger() l.Logger {
 	return l.NewLoggerWithContext(l.Fields{l.ProductLogContext: integreatlyv1alpha1.Product3Scale})
 }
@@ -762,7 +820,7 @@ func TestReconciler_reconcileOpenshiftUsers(t *testing.T) {
 				}),
 				log: getLogger(),
 				tsClient: &ThreeScaleInterfaceMock{
-					GetUsersFunc: func(accessToken string) (*Users, error) {
+					GetUsersFunc: func(ctx context.Context, accessToken string) (*Users, error) {
 						return nil, fmt.Errorf("get error")
 					},
 				},
@@ -776,6 +834,7 @@ func TestReconciler_reconcileOpenshiftUsers(t *testing.T) {
 						return nil
 					},
 				},
+				installation: &integreatlyv1alpha1.RHMI{},
 			},
 			want:    integreatlyv1alpha1.PhaseInProgress,
 			wantErr: true,
@@ -793,7 +852,7 @@ func TestReconciler_reconcileOpenshiftUsers(t *testing.T) {
 				}),
 				log: getLogger(),
 				tsClient: &ThreeScaleInterfaceMock{
-					GetUsersFunc: func(accessToken string) (*Users, error) {
+					GetUsersFunc: func(ctx context.Context, accessToken string) (*Users, error) {
 						return &Users{
 							Users: []*User{
 								{
@@ -810,22 +869,22 @@ func TestReconciler_reconcileOpenshiftUsers(t *testing.T) {
 							},
 						}, nil
 					},
-					AddUserFunc: func(username string, email string, password string, accessToken string) (*http.Response, error) {
+					AddUserFunc: func(ctx context.Context, username string, email string, password string, accessToken string) (*http.Response, error) {
 						return &http.Response{
 							StatusCode: http.StatusOK,
 						}, nil
 					},
-					DeleteUserFunc: func(userID int, accessToken string) (*http.Response, error) {
+					DeleteUserFunc: func(ctx context.Context, userID int, accessToken string) (*http.Response, error) {
 						return &http.Response{
 							StatusCode: http.StatusOK,
 						}, nil
 					},
-					UpdateUserFunc: func(userID int, username string, email string, accessToken string) (*http.Response, error) {
+					UpdateUserFunc: func(ctx context.Context, userID int, username string, email string, accessToken string) (*http.Response, error) {
 						return &http.Response{
 							StatusCode: http.StatusOK,
 						}, nil
 					},
-					GetUserFunc: func(username string, accessToken string) (*User, error) {
+					GetUserFunc: func(ctx context.Context, username string, accessToken string) (*User, error) {
 						return &User{
 							UserDetails: UserDetails{
 								Username: defaultInstallationNamespace,
@@ -892,6 +951,66 @@ func TestReconciler_reconcileOpenshiftUsers(t *testing.T) {
 	}
 }
 
+func TestReconciler_reconcileOpenshiftUsersAdminMembershipFailure(t *testing.T) {
+	scheme, err := getBuildScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kcUser := rhssoTest1.DeepCopy()
+	kcUser.Spec.User.Attributes = map[string][]string{user3ScaleID: {"1"}}
+	tsUser := &User{UserDetails: UserDetails{Id: 1, Username: "test1", Email: "test1@example.com", Role: memberRole}}
+	installation := &integreatlyv1alpha1.RHMI{ObjectMeta: metav1.ObjectMeta{Namespace: integreatlyOperatorNamespace}}
+	seed := threeScaleAdminDetailsSecret.DeepCopy()
+	seed.Namespace = defaultInstallationNamespace
+	serverClient := fake.NewFakeClientWithScheme(scheme, seed, testDedicatedAdminsGroup, kcUser)
+
+	r := &Reconciler{
+		ConfigManager: &config.ConfigReadWriterMock{ReadRHSSOFunc: func() (*config.RHSSO, error) {
+			return config.NewRHSSO(config.ProductConfig{
+				"NAMESPACE": testRhssoNamespace,
+			}), nil
+		}},
+		Config: config.NewThreeScale(config.ProductConfig{
+			"NAMESPACE": defaultInstallationNamespace,
+		}),
+		installation: installation,
+		tsClient: &ThreeScaleInterfaceMock{
+			GetUsersFunc: func(ctx context.Context, accessToken string) (*Users, error) {
+				return &Users{Users: []*User{tsUser}}, nil
+			},
+			GetUserFunc: func(ctx context.Context, username string, accessToken string) (*User, error) {
+				return tsUser, nil
+			},
+			// the dedicated admin can't be promoted, so the sync must not
+			// be recorded
+			SetUserAsAdminFunc: func(ctx context.Context, userID int, accessToken string) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusInternalServerError,
+					Body:       ioutil.NopCloser(strings.NewReader("internal server error")),
+				}, nil
+			},
+		},
+		log: getLogger(),
+	}
+
+	phase, err := r.reconcileOpenshiftUsers(context.TODO(), installation, serverClient)
+	if err == nil {
+		t.Fatal("expected an error when the user can't be promoted to admin")
+	}
+	if phase != integreatlyv1alpha1.PhaseInProgress {
+		t.Fatalf("expected phase %s, got %s", integreatlyv1alpha1.PhaseInProgress, phase)
+	}
+
+	state, err := usersync.GetState(context.TODO(), serverClient, installation.Namespace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := state.Consumers[usersync.ConsumerThreeScale]; ok {
+		t.Fatal("expected the user sync to 3scale not to be recorded")
+	}
+}
+
 func TestReconciler_updateKeycloakUsersAttributeWith3ScaleUserId(t *testing.T) {
 	accessToken := "accessToken"
 
@@ -956,7 +1075,7 @@ func TestReconciler_updateKeycloakUsersAttributeWith3ScaleUserId(t *testing.T) {
 				}),
 				log: getLogger(),
 				tsClient: &ThreeScaleInterfaceMock{
-					GetUserFunc: func(username string, accessToken string) (*User, error) {
+					GetUserFunc: func(ctx context.Context, username string, accessToken string) (*User, error) {
 						return nil, fmt.Errorf("get error")
 					},
 				},
@@ -984,7 +1103,7 @@ func TestReconciler_updateKeycloakUsersAttributeWith3ScaleUserId(t *testing.T) {
 				}),
 				log: getLogger(),
 				tsClient: &ThreeScaleInterfaceMock{
-					GetUserFunc: func(username string, accessToken string) (*User, error) {
+					GetUserFunc: func(ctx context.Context, username string, accessToken string) (*User, error) {
 						return &User{UserDetails: UserDetails{Id: 1}}, nil
 					},
 				},
@@ -1018,7 +1137,7 @@ func TestReconciler_updateKeycloakUsersAttributeWith3ScaleUserId(t *testing.T) {
 				}),
 				log: getLogger(),
 				tsClient: &ThreeScaleInterfaceMock{
-					GetUserFunc: func(username string, accessToken string) (*User, error) {
+					GetUserFunc: func(ctx context.Context, username string, accessToken string) (*User, error) {
 						return &User{UserDetails: UserDetails{Id: 1}}, nil
 					},
 				},
@@ -1376,3 +1495,114 @@ func TestReconciler_getUserDiff(t *testing.T) {
 		})
 	}
 }
+
+func TestPlannedUsers(t *testing.T) {
+	kcUsers := []keycloak.KeycloakAPIUser{
+		{UserName: "synced", Attributes: map[string][]string{user3ScaleID: {"1"}}},
+		{UserName: "Changed", Attributes: map[string][]string{user3ScaleID: {"2"}}},
+		{UserName: "new"},
+	}
+	tsUsers := []*User{
+		{UserDetails: UserDetails{Id: 1, Username: "synced"}},
+		{UserDetails: UserDetails{Id: 2, Username: "changed"}},
+		{UserDetails: UserDetails{Id: 3, Username: "removed"}},
+	}
+
+	plan := usersync.SyncPlan{
+		Changed: map[string]bool{"changed": true},
+		Removed: map[string]bool{"removed": true},
+	}
+	planned, removalPending := plannedKeycloakUsers(kcUsers, plan)
+	if removalPending {
+		t.Fatal("expected no removal pending")
+	}
+	if len(planned) != 2 || planned[0].UserName != "Changed" || planned[1].UserName != "new" {
+		t.Fatalf("expected the changed and new keycloak users to be planned, got %v", planned)
+	}
+	plannedTsUsers := plannedThreeScaleUsers(tsUsers, planned, plan)
+	if len(plannedTsUsers) != 2 || plannedTsUsers[0].UserDetails.Id != 2 || plannedTsUsers[1].UserDetails.Id != 3 {
+		t.Fatalf("expected the changed and removed 3scale users to be planned, got %v", plannedTsUsers)
+	}
+
+	plan.Removed = map[string]bool{"synced": true}
+	if _, removalPending := plannedKeycloakUsers(kcUsers, plan); !removalPending {
+		t.Fatal("expected removal pending for a removed user with a keycloak user")
+	}
+
+	full := usersync.SyncPlan{Full: true}
+	if planned, _ := plannedKeycloakUsers(kcUsers, full); len(planned) != len(kcUsers) {
+		t.Fatalf("expected all the keycloak users to be planned on a full sync, got %v", planned)
+	}
+	if planned := plannedThreeScaleUsers(tsUsers, nil, full); len(planned) != len(tsUsers) {
+		t.Fatalf("expected all the 3scale users to be planned on a full sync, got %v", planned)
+	}
+}
+
+func TestReconciler_rotateTenantAccountPasswords(t *testing.T) {
+	scheme, err := getBuildScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tenantAccountSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secretrotation.TenantAccountPasswordsName,
+			Namespace: defaultInstallationNamespace,
+			Annotations: map[string]string{
+				secretrotation.LastRotatedAnnotation: `{"failing":"2021-01-01T00:00:00Z","tenant1":"2021-01-01T00:00:00Z"}`,
+			},
+		},
+		Data: map[string][]byte{
+			"tenant1": []byte("old"),
+			"failing": []byte("old"),
+			"tenant2": []byte("unrecorded"),
+		},
+	}
+	accounts := []AccountDetail{
+		{Id: 1, Name: "tenant1", State: "approved", Users: XMLUsers{User: []XMLUserDetails{{Id: 11, Username: "tenant1"}}}},
+		{Id: 2, Name: "failing", State: "approved", Users: XMLUsers{User: []XMLUserDetails{{Id: 21, Username: "failing"}}}},
+		{Id: 3, Name: "tenant2", State: "approved", Users: XMLUsers{User: []XMLUserDetails{{Id: 31, Username: "tenant2"}}}},
+	}
+
+	passwords := map[int]string{}
+	tsClientMock := &ThreeScaleInterfaceMock{
+		UpdateTenantUserPasswordFunc: func(ctx context.Context, accessToken string, accountId, userId int, password string) error {
+			if accountId == 2 {
+				return fmt.Errorf("service unavailable")
+			}
+			passwords[userId] = password
+			return nil
+		},
+	}
+	serverClient := fake.NewFakeClientWithScheme(scheme, tenantAccountSecret)
+	r := &Reconciler{
+		Config: config.NewThreeScale(config.ProductConfig{
+			"NAMESPACE": defaultInstallationNamespace,
+		}),
+		installation: getTestInstallation(),
+		tsClient:     tsClientMock,
+		log:          getLogger(),
+	}
+
+	if err := r.rotateTenantAccountPasswords(context.TODO(), serverClient, "accessToken", accounts); err == nil {
+		t.Fatalf("expected an error for the tenant whose password failed to rotate")
+	}
+
+	secret := &corev1.Secret{}
+	if err := serverClient.Get(context.TODO(), k8sclient.ObjectKey{Name: tenantAccountSecret.Name, Namespace: defaultInstallationNamespace}, secret); err != nil {
+		t.Fatal(err)
+	}
+	if len(passwords) != 1 || string(secret.Data["tenant1"]) != passwords[11] || passwords[11] == "old" {
+		t.Errorf("expected the password set on the admin user of tenant1 to be recorded, got %v and %s", passwords, secret.Data["tenant1"])
+	}
+	if string(secret.Data["failing"]) != "old" {
+		t.Errorf("expected the password that failed to rotate to be kept, got %s", secret.Data["failing"])
+	}
+	if string(secret.Data["tenant2"]) != "unrecorded" {
+		t.Errorf("expected the password without a recorded rotation not to be rotated, got %s", secret.Data["tenant2"])
+	}
+	lastRotated := secretrotation.LastRotated(secret)
+	if _, ok := lastRotated["tenant2"]; !ok {
+		t.Errorf("expected the rotation time of tenant2 to be recorded, got %v", lastRotated)
+	}
+}
@@ -1,69 +1,90 @@
 package threescale
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"encoding/xml"
-	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"time"
 
 	"github.com/antchfx/xmlquery"
-	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
 )
 
 //go:generate moq -out three_scale_moq.go . ThreeScaleInterface
 type ThreeScaleInterface interface {
 	SetNamespace(ns string)
-	AddAuthenticationProvider(data map[string]string, accessToken string) (*http.Response, error)
-	GetAuthenticationProviders(accessToken string) (*AuthProviders, error)
-	GetAuthenticationProviderByName(name string, accessToken string) (*AuthProvider, error)
-	GetUser(username, accessToken string) (*User, error)
-	GetUsers(accessToken string) (*Users, error)
-	AddUser(username string, email string, password string, accessToken string) (*http.Response, error)
-	DeleteUser(userID int, accessToken string) (*http.Response, error)
-	SetUserAsAdmin(userID int, accessToken string) (*http.Response, error)
-	SetUserAsMember(userID int, accessToken string) (*http.Response, error)
-	SetFromEmailAddress(emailAddress string, accessToken string) (*http.Response, error)
-	UpdateUser(userID int, username string, email string, accessToken string) (*http.Response, error)
-
-	CreateAccount(accessToken, orgName, username string) (string, error)
-	CreateBackend(accessToken, name, privateEndpoint string) (int, error)
-	CreateMetric(accessToken string, backendID int, friendlyName, unit string) (int, error)
-	CreateBackendMappingRule(accessToken string, backendID, metricID int, httpMethod, pattern string, delta int) error
-	CreateService(accessToken, name, systemName string) (string, error)
-	CreateBackendUsage(accessToken, serviceID string, backendID int, path string) error
-	CreateApplicationPlan(accessToken, serviceID, name string) (string, error)
-	CreateApplication(accessToken, accountID, planID, name, description string) (string, error)
-	DeployProxy(accessToken, serviceID string) error
-	PromoteProxy(accessToken, serviceID, env, to string) (string, error)
-
-	DeleteService(accessToken, serviceID string) error
-	DeleteBackend(accessToken string, backendID int) error
-	DeleteAccount(accessToken, accountID string) error
-
-	CreateTenant(accessToken string, account AccountDetail, password string, email string) (*SignUpAccount, error)
-	ListTenantAccounts(accessToken string, page int) ([]AccountDetail, error)
-	GetTenantAccount(accessToken string, id int) (*SignUpAccount, error)
-	DeleteTenant(accessToken string, id int) error
-	DeleteTenants(accessToken string, accounts []AccountDetail) error
-
-	ActivateUser(accessToken string, accountId, userId int) error
-	AddAuthProviderToAccount(accessToken string, account AccountDetail, authProviderDetail AuthProviderDetails) error
-	IsAuthProviderAdded(accessToken string, authProviderName string, account AccountDetail) (bool, error)
+	AddAuthenticationProvider(ctx context.Context, data map[string]string, accessToken string) (*http.Response, error)
+	UpdateAuthenticationProvider(ctx context.Context, id int, data map[string]string, accessToken string) (*http.Response, error)
+	GetAuthenticationProviders(ctx context.Context, accessToken string) (*AuthProviders, error)
+	GetAuthenticationProviderByName(ctx context.Context, name string, accessToken string) (*AuthProvider, error)
+	GetUser(ctx context.Context, username, accessToken string) (*User, error)
+	GetUsers(ctx context.Context, accessToken string) (*Users, error)
+	AddUser(ctx context.Context, username string, email string, password string, accessToken string) (*http.Response, error)
+	DeleteUser(ctx context.Context, userID int, accessToken string) (*http.Response, error)
+	SetUserAsAdmin(ctx context.Context, userID int, accessToken string) (*http.Response, error)
+	SetUserAsMember(ctx context.Context, userID int, accessToken string) (*http.Response, error)
+	SetUserPermissions(ctx context.Context, userID int, allowedSections []string, accessToken string) (*http.Response, error)
+	SetFromEmailAddress(ctx context.Context, emailAddress string, accessToken string) (*http.Response, error)
+	UpdateUser(ctx context.Context, userID int, username string, email string, accessToken string) (*http.Response, error)
+
+	CreateAccount(ctx context.Context, accessToken, orgName, username string) (string, error)
+	CreateBackend(ctx context.Context, accessToken, name, systemName, privateEndpoint string) (int, error)
+	UpdateBackend(ctx context.Context, accessToken string, backendID int, privateEndpoint string) error
+	ListBackends(ctx context.Context, accessToken string) (*Backends, error)
+	ListBackendMetrics(ctx context.Context, accessToken string, backendID int) (*Metrics, error)
+	ListBackendMappingRules(ctx context.Context, accessToken string, backendID int) (*MappingRules, error)
+	CreateMetric(ctx context.Context, accessToken string, backendID int, friendlyName, unit string) (int, error)
+	CreateBackendMappingRule(ctx context.Context, accessToken string, backendID, metricID int, httpMethod, pattern string, delta int) error
+	CreateService(ctx context.Context, accessToken, name, systemName string) (string, error)
+	ListServices(ctx context.Context, accessToken string) (*Services, error)
+	CreateBackendUsage(ctx context.Context, accessToken, serviceID string, backendID int, path string) error
+	UpdateBackendUsage(ctx context.Context, accessToken, serviceID string, backendUsageID int, path string) error
+	ListBackendUsages(ctx context.Context, accessToken, serviceID string) ([]*BackendUsage, error)
+	CreateApplicationPlan(ctx context.Context, accessToken, serviceID, name string) (string, error)
+	ListApplicationPlans(ctx context.Context, accessToken, serviceID string) (*ApplicationPlans, error)
+	CreateApplication(ctx context.Context, accessToken, accountID, planID, name, description string) (string, error)
+	DeployProxy(ctx context.Context, accessToken, serviceID string) error
+	PromoteProxy(ctx context.Context, accessToken, serviceID, env, to string) (string, error)
+
+	DeleteService(ctx context.Context, accessToken, serviceID string) error
+	DeleteBackend(ctx context.Context, accessToken string, backendID int) error
+	DeleteBackendMappingRule(ctx context.Context, accessToken string, backendID, mappingRuleID int) error
+	DeleteApplicationPlan(ctx context.Context, accessToken, serviceID string, planID int) error
+	DeleteAccount(ctx context.Context, accessToken, accountID string) error
+
+	CreateTenant(ctx context.Context, accessToken string, account AccountDetail, password string, email string) (*SignUpAccount, error)
+	ListTenantAccounts(ctx context.Context, accessToken string) ([]AccountDetail, error)
+	GetTenantAccount(ctx context.Context, accessToken string, id int) (*SignUpAccount, error)
+	DeleteTenant(ctx context.Context, accessToken string, id int) error
+	DeleteTenants(ctx context.Context, accessToken string, accounts []AccountDetail) error
+
+	ActivateUser(ctx context.Context, accessToken string, accountId, userId int) error
+	UpdateTenantUserPassword(ctx context.Context, accessToken string, accountId, userId int, password string) error
+	AddAuthProviderToAccount(ctx context.Context, accessToken string, account AccountDetail, authProviderDetail AuthProviderDetails) error
+	IsAuthProviderAdded(ctx context.Context, accessToken string, authProviderName string, account AccountDetail) (bool, error)
 }
 
 const (
 	adminRole  = "admin"
 	memberRole = "member"
+
+	// listPageSize is the maximum page size accepted by the 3scale account
+	// management API
+	listPageSize = 500
 )
 
 type threeScaleClient struct {
 	httpc          *http.Client
 	wildCardDomain string
 	ns             string
+
+	limiter        *rate.Limiter
+	maxRetries     int
+	retryBaseDelay time.Duration
+	retryMaxDelay  time.Duration
 }
 
 var _ ThreeScaleInterface = &threeScaleClient{}
@@ -73,6 +94,10 @@ func NewThreeScaleClient(httpc *http.Client, wildCardDomain string) *threeScaleC
 	return &threeScaleClient{
 		httpc:          httpc,
 		wildCardDomain: wildCardDomain,
+		limiter:        newDefaultRateLimiter(),
+		maxRetries:     defaultMaxRetries,
+		retryBaseDelay: defaultRetryBaseDelay,
+		retryMaxDelay:  defaultRetryMaxDelay,
 	}
 }
 
@@ -80,44 +105,43 @@ func (tsc *threeScaleClient) SetNamespace(ns string) {
 	tsc.ns = ns
 }
 
-func (tsc *threeScaleClient) AddAuthenticationProvider(data map[string]string, accessToken string) (*http.Response, error) {
-	data["access_token"] = accessToken
-	reqData, err := json.Marshal(data)
-	if err != nil {
-		return nil, err
+func (tsc *threeScaleClient) AddAuthenticationProvider(ctx context.Context, data map[string]string, accessToken string) (*http.Response, error) {
+	parameters := onlyAccessToken(accessToken)
+	for key, value := range data {
+		parameters[key] = value
 	}
-	tsc.httpc.Timeout = time.Second * 10
-	res, err := tsc.httpc.Post(
-		fmt.Sprintf("https://3scale-admin.%s/admin/api/account/authentication_providers.json", tsc.wildCardDomain),
-		"application/json",
-		bytes.NewBuffer(reqData),
-	)
-	if err != nil {
-		return nil, err
+
+	return tsc.makeRequest(ctx, http.MethodPost, "account/authentication_providers.json", parameters)
+}
+
+func (tsc *threeScaleClient) UpdateAuthenticationProvider(ctx context.Context, id int, data map[string]string, accessToken string) (*http.Response, error) {
+	parameters := onlyAccessToken(accessToken)
+	for key, value := range data {
+		parameters[key] = value
 	}
 
-	return res, nil
+	return tsc.makeRequest(ctx, http.MethodPut, fmt.Sprintf("account/authentication_providers/%d.json", id), parameters)
 }
 
-func (tsc *threeScaleClient) GetAuthenticationProviders(accessToken string) (*AuthProviders, error) {
-	res, err := tsc.httpc.Get(
-		fmt.Sprintf("https://3scale-admin.%s/admin/api/account/authentication_providers.json?access_token=%s", tsc.wildCardDomain, accessToken),
-	)
+func (tsc *threeScaleClient) GetAuthenticationProviders(ctx context.Context, accessToken string) (*AuthProviders, error) {
+	res, err := tsc.makeRequest(ctx, http.MethodGet, "account/authentication_providers.json", onlyAccessToken(accessToken))
 	if err != nil {
 		return nil, err
 	}
+	if err := assertStatusCode(http.StatusOK, res); err != nil {
+		return nil, err
+	}
 
 	authProviders := &AuthProviders{}
-	err = json.NewDecoder(res.Body).Decode(authProviders)
-	if err != nil {
+	if err := jsonFromResponse(res, authProviders); err != nil {
 		return nil, err
 	}
 
 	return authProviders, nil
 }
 
-func (tsc *threeScaleClient) GetAuthenticationProviderByName(name string, accessToken string) (*AuthProvider, error) {
-	authProviders, err := tsc.GetAuthenticationProviders(accessToken)
+func (tsc *threeScaleClient) GetAuthenticationProviderByName(ctx context.Context, name string, accessToken string) (*AuthProvider, error) {
+	authProviders, err := tsc.GetAuthenticationProviders(ctx, accessToken)
 	if err != nil {
 		return nil, err
 	}
@@ -131,8 +155,8 @@ func (tsc *threeScaleClient) GetAuthenticationProviderByName(name string, access
 	return nil, &tsError{message: "Authprovider not found", StatusCode: http.StatusNotFound}
 }
 
-func (tsc *threeScaleClient) GetUser(username, accessToken string) (*User, error) {
-	users, err := tsc.GetUsers(accessToken)
+func (tsc *threeScaleClient) GetUser(ctx context.Context, username, accessToken string) (*User, error) {
+	users, err := tsc.GetUsers(ctx, accessToken)
 	if err != nil {
 		return nil, err
 	}
@@ -146,16 +170,37 @@ func (tsc *threeScaleClient) GetUser(username, accessToken string) (*User, error
 	return nil, &tsError{message: "User not found", StatusCode: http.StatusNotFound}
 }
 
-func (tsc *threeScaleClient) GetUsers(accessToken string) (*Users, error) {
-	res, err := tsc.httpc.Get(
-		fmt.Sprintf("https://3scale-admin.%s/admin/api/users.json?access_token=%s", tsc.wildCardDomain, accessToken),
-	)
-	if err != nil {
-		return nil, err
-	}
-
+func (tsc *threeScaleClient) GetUsers(ctx context.Context, accessToken string) (*Users, error) {
 	users := &Users{}
-	err = json.NewDecoder(res.Body).Decode(users)
+	seen := map[int]bool{}
+	err := listPages(func(page int) (int, int, error) {
+		res, err := tsc.makeRequest(ctx, http.MethodGet, "users.json", withAccessToken(accessToken, map[string]interface{}{
+			"page":     page,
+			"per_page": listPageSize,
+		}))
+		if err != nil {
+			return 0, 0, err
+		}
+		if err := assertStatusCode(http.StatusOK, res); err != nil {
+			return 0, 0, err
+		}
+
+		pageUsers := &Users{}
+		if err := jsonFromResponse(res, pageUsers); err != nil {
+			return 0, 0, err
+		}
+
+		added := 0
+		for _, user := range pageUsers.Users {
+			if seen[user.UserDetails.Id] {
+				continue
+			}
+			seen[user.UserDetails.Id] = true
+			users.Users = append(users.Users, user)
+			added++
+		}
+		return len(pageUsers.Users), added, nil
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -163,150 +208,63 @@ func (tsc *threeScaleClient) GetUsers(accessToken string) (*Users, error) {
 	return users, nil
 }
 
-func (tsc *threeScaleClient) SetFromEmailAddress(emailAddress string, accessToken string) (*http.Response, error) {
+func (tsc *threeScaleClient) SetFromEmailAddress(ctx context.Context, emailAddress string, accessToken string) (*http.Response, error) {
 
 	//curl -v --header "Content-Type: application/json" -X PUT "https://3scale-admin.apps.bg.o7rx.s1.devshift.org/admin/api/provider.xml"
 	// --data '{"access_token":"05807975eb3cbec201d16fc54a327546960fc61bb278169e28eafdb99913bbbe","from_email":"test@test.com"}'
 
-	data, err := json.Marshal(map[string]string{
-		"access_token": accessToken,
-		"from_email":   emailAddress,
-	})
-	url := fmt.Sprintf("https://3scale-admin.%s/admin/api/provider.xml", tsc.wildCardDomain)
-	req, err := http.NewRequest(
-		"PUT",
-		url,
-		bytes.NewBuffer(data),
-	)
+	res, err := tsc.makeRequest(ctx, http.MethodPut, "provider.xml", withAccessToken(accessToken, map[string]interface{}{
+		"from_email": emailAddress,
+	}))
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	tsc.httpc.Timeout = time.Second * 10
-
-	res, err := tsc.httpc.Do(req)
-
-	if err == nil && res.StatusCode != 200 {
-
-		err = errors.New(fmt.Sprintf("StatusCode %v calling SetFromEmailAddress", res.StatusCode))
-	}
-
-	return res, err
+	return res, assertStatusCode(http.StatusOK, res)
 }
 
-func (tsc *threeScaleClient) AddUser(username string, email string, password string, accessToken string) (*http.Response, error) {
-	data := make(map[string]string)
-	data["access_token"] = accessToken
-	data["username"] = username
-	data["email"] = email
-	data["password"] = password
-	reqData, err := json.Marshal(data)
-
-	res, err := tsc.httpc.Post(
-		fmt.Sprintf("https://3scale-admin.%s/admin/api/users.json", tsc.wildCardDomain),
-		"application/json",
-		bytes.NewBuffer(reqData),
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	return res, nil
+func (tsc *threeScaleClient) AddUser(ctx context.Context, username string, email string, password string, accessToken string) (*http.Response, error) {
+	return tsc.makeRequest(ctx, http.MethodPost, "users.json", withAccessToken(accessToken, map[string]interface{}{
+		"username": username,
+		"email":    email,
+		"password": password,
+	}))
 }
 
-func (tsc *threeScaleClient) DeleteUser(userID int, accessToken string) (*http.Response, error) {
-	data := make(map[string]string)
-	data["access_token"] = accessToken
-	reqData, err := json.Marshal(data)
-
-	req, err := http.NewRequest(
-		http.MethodDelete,
-		fmt.Sprintf("https://3scale-admin.%s/admin/api/users/%d.json", tsc.wildCardDomain, userID),
-		bytes.NewBuffer(reqData))
-	req.Header.Add("Content-type", "application/json")
-	tsc.httpc.Timeout = time.Second * 10
-	res, err := tsc.httpc.Do(req)
-	if err != nil {
-		return nil, err
-	}
-
-	return res, nil
+func (tsc *threeScaleClient) DeleteUser(ctx context.Context, userID int, accessToken string) (*http.Response, error) {
+	return tsc.makeRequest(ctx, http.MethodDelete, fmt.Sprintf("users/%d.json", userID), onlyAccessToken(accessToken))
 }
 
-func (tsc *threeScaleClient) SetUserAsAdmin(userID int, accessToken string) (*http.Response, error) {
-	data, err := json.Marshal(map[string]string{
-		"access_token": accessToken,
-	})
-	url := fmt.Sprintf("https://3scale-admin.%s/admin/api/users/%d/admin.json", tsc.wildCardDomain, userID)
-	req, err := http.NewRequest(
-		"PUT",
-		url,
-		bytes.NewBuffer(data),
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	tsc.httpc.Timeout = time.Second * 10
-
-	res, err := tsc.httpc.Do(req)
-
-	return res, err
+func (tsc *threeScaleClient) SetUserAsAdmin(ctx context.Context, userID int, accessToken string) (*http.Response, error) {
+	return tsc.makeRequest(ctx, http.MethodPut, fmt.Sprintf("users/%d/admin.json", userID), onlyAccessToken(accessToken))
 }
 
-func (tsc *threeScaleClient) SetUserAsMember(userID int, accessToken string) (*http.Response, error) {
-	data, err := json.Marshal(map[string]string{
-		"access_token": accessToken,
-	})
-	url := fmt.Sprintf("https://3scale-admin.%s/admin/api/users/%d/member.json", tsc.wildCardDomain, userID)
-	req, err := http.NewRequest(
-		"PUT",
-		url,
-		bytes.NewBuffer(data),
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	tsc.httpc.Timeout = time.Second * 10
-	res, err := tsc.httpc.Do(req)
-
-	return res, err
+func (tsc *threeScaleClient) SetUserAsMember(ctx context.Context, userID int, accessToken string) (*http.Response, error) {
+	return tsc.makeRequest(ctx, http.MethodPut, fmt.Sprintf("users/%d/member.json", userID), onlyAccessToken(accessToken))
 }
 
-func (tsc *threeScaleClient) UpdateUser(userID int, username string, email string, accessToken string) (*http.Response, error) {
-	data, err := json.Marshal(map[string]string{
-		"access_token": accessToken,
-		"username":     username,
-		"email":        email,
-	})
-	url := fmt.Sprintf("https://3scale-admin.%s/admin/api/users/%d.json", tsc.wildCardDomain, userID)
-	req, err := http.NewRequest(
-		"PUT",
-		url,
-		bytes.NewBuffer(data),
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	tsc.httpc.Timeout = time.Second * 10
-	res, err := tsc.httpc.Do(req)
+// SetUserPermissions restricts the admin portal sections a member user has
+// access to
+func (tsc *threeScaleClient) SetUserPermissions(ctx context.Context, userID int, allowedSections []string, accessToken string) (*http.Response, error) {
+	return tsc.makeRequest(ctx, http.MethodPut, fmt.Sprintf("users/%d/permissions.json", userID), withAccessToken(accessToken, map[string]interface{}{
+		"allowed_sections": allowedSections,
+	}))
+}
 
-	return res, err
+func (tsc *threeScaleClient) UpdateUser(ctx context.Context, userID int, username string, email string, accessToken string) (*http.Response, error) {
+	return tsc.makeRequest(ctx, http.MethodPut, fmt.Sprintf("users/%d.json", userID), withAccessToken(accessToken, map[string]interface{}{
+		"username": username,
+		"email":    email,
+	}))
 }
 
-func (tsc *threeScaleClient) CreateAccount(accessToken, orgName, username string) (string, error) {
+func (tsc *threeScaleClient) CreateAccount(ctx context.Context, accessToken, orgName, username string) (string, error) {
 	data := map[string]interface{}{
 		"org_name": orgName,
 		"username": username,
 	}
 
-	res, err := tsc.makeRequest("POST", "signup.xml", withAccessToken(accessToken, data))
+	res, err := tsc.makeRequest(ctx, "POST", "signup.xml", withAccessToken(accessToken, data))
 	if err != nil {
 		return "", err
 	}
@@ -318,13 +276,14 @@ func (tsc *threeScaleClient) CreateAccount(accessToken, orgName, username string
 	return xmlFromResponse(res, "//account/id/text()")
 }
 
-func (tsc *threeScaleClient) CreateBackend(accessToken, name, privateEndpoint string) (int, error) {
+func (tsc *threeScaleClient) CreateBackend(ctx context.Context, accessToken, name, systemName, privateEndpoint string) (int, error) {
 	data := map[string]interface{}{
 		"name":             name,
+		"system_name":      systemName,
 		"private_endpoint": privateEndpoint,
 	}
 
-	res, err := tsc.makeRequest("POST", "backend_apis.json", withAccessToken(accessToken, data))
+	res, err := tsc.makeRequest(ctx, "POST", "backend_apis.json", withAccessToken(accessToken, data))
 	if err != nil {
 		return 0, err
 	}
@@ -345,8 +304,106 @@ func (tsc *threeScaleClient) CreateBackend(accessToken, name, privateEndpoint st
 	return responseBody.BackendAPI.ID, nil
 }
 
-func (tsc *threeScaleClient) CreateMetric(accessToken string, backendID int, friendlyName, unit string) (int, error) {
+func (tsc *threeScaleClient) UpdateBackend(ctx context.Context, accessToken string, backendID int, privateEndpoint string) error {
+	res, err := tsc.makeRequest(
+		ctx,
+		"PUT",
+		fmt.Sprintf("backend_apis/%d.json", backendID),
+		withAccessToken(accessToken, map[string]interface{}{
+			"private_endpoint": privateEndpoint,
+		}),
+	)
+	if err != nil {
+		return err
+	}
+
+	return assertStatusCode(http.StatusOK, res)
+}
+
+func (tsc *threeScaleClient) ListBackends(ctx context.Context, accessToken string) (*Backends, error) {
+	backends := &Backends{}
+	seen := map[int]bool{}
+	err := listPages(func(page int) (int, int, error) {
+		pageBackends := &Backends{}
+		if err := tsc.getPage(ctx, "backend_apis.json", accessToken, page, pageBackends); err != nil {
+			return 0, 0, err
+		}
+
+		added := 0
+		for _, backend := range pageBackends.Backends {
+			if seen[backend.BackendDetails.Id] {
+				continue
+			}
+			seen[backend.BackendDetails.Id] = true
+			backends.Backends = append(backends.Backends, backend)
+			added++
+		}
+		return len(pageBackends.Backends), added, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return backends, nil
+}
+
+func (tsc *threeScaleClient) ListBackendMetrics(ctx context.Context, accessToken string, backendID int) (*Metrics, error) {
+	metrics := &Metrics{}
+	seen := map[int]bool{}
+	err := listPages(func(page int) (int, int, error) {
+		pageMetrics := &Metrics{}
+		if err := tsc.getPage(ctx, fmt.Sprintf("backend_apis/%d/metrics.json", backendID), accessToken, page, pageMetrics); err != nil {
+			return 0, 0, err
+		}
+
+		added := 0
+		for _, metric := range pageMetrics.Metrics {
+			if seen[metric.MetricDetails.Id] {
+				continue
+			}
+			seen[metric.MetricDetails.Id] = true
+			metrics.Metrics = append(metrics.Metrics, metric)
+			added++
+		}
+		return len(pageMetrics.Metrics), added, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return metrics, nil
+}
+
+func (tsc *threeScaleClient) ListBackendMappingRules(ctx context.Context, accessToken string, backendID int) (*MappingRules, error) {
+	mappingRules := &MappingRules{}
+	seen := map[int]bool{}
+	err := listPages(func(page int) (int, int, error) {
+		pageMappingRules := &MappingRules{}
+		if err := tsc.getPage(ctx, fmt.Sprintf("backend_apis/%d/mapping_rules.json", backendID), accessToken, page, pageMappingRules); err != nil {
+			return 0, 0, err
+		}
+
+		added := 0
+		for _, mappingRule := range pageMappingRules.MappingRules {
+			if seen[mappingRule.MappingRuleDetails.Id] {
+				continue
+			}
+			seen[mappingRule.MappingRuleDetails.Id] = true
+			mappingRules.MappingRules = append(mappingRules.MappingRules, mappingRule)
+			added++
+		}
+		return len(pageMappingRules.MappingRules), added, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return mappingRules, nil
+}
+
+func (tsc *threeScaleClient) CreateMetric(ctx context.Context, accessToken string, backendID int, friendlyName, unit string) (int, error) {
 	res, err := tsc.makeRequest(
+		ctx,
 		"POST",
 		fmt.Sprintf("backend_apis/%d/metrics.json", backendID),
 		withAccessToken(accessToken, map[string]interface{}{
@@ -373,8 +430,9 @@ func (tsc *threeScaleClient) CreateMetric(accessToken string, backendID int, fri
 	return responseBody.Metric.ID, nil
 }
 
-func (tsc *threeScaleClient) CreateBackendMappingRule(accessToken string, backendID, metricID int, httpMethod, pattern string, delta int) error {
+func (tsc *threeScaleClient) CreateBackendMappingRule(ctx context.Context, accessToken string, backendID, metricID int, httpMethod, pattern string, delta int) error {
 	res, err := tsc.makeRequest(
+		ctx,
 		"POST",
 		fmt.Sprintf("backend_apis/%d/mapping_rules.json", backendID),
 		withAccessToken(accessToken, map[string]interface{}{
@@ -391,13 +449,14 @@ func (tsc *threeScaleClient) CreateBackendMappingRule(accessToken string, backen
 	return assertStatusCode(http.StatusCreated, res)
 }
 
-func (tsc *threeScaleClient) CreateService(accessToken, name, systemName string) (string, error) {
+func (tsc *threeScaleClient) CreateService(ctx context.Context, accessToken, name, systemName string) (string, error) {
 	res, err := tsc.makeRequest(
+		ctx,
 		"POST",
 		"services.xml",
 		withAccessToken(accessToken, map[string]interface{}{
 			"name":        name,
-			"system_name": name,
+			"system_name": systemName,
 		}),
 	)
 	if err != nil {
@@ -410,8 +469,36 @@ func (tsc *threeScaleClient) CreateService(accessToken, name, systemName string)
 	return xmlFromResponse(res, "//service/id/text()")
 }
 
-func (tsc *threeScaleClient) CreateBackendUsage(accessToken, serviceID string, backendID int, path string) error {
+func (tsc *threeScaleClient) ListServices(ctx context.Context, accessToken string) (*Services, error) {
+	services := &Services{}
+	seen := map[int]bool{}
+	err := listPages(func(page int) (int, int, error) {
+		pageServices := &Services{}
+		if err := tsc.getPage(ctx, "services.json", accessToken, page, pageServices); err != nil {
+			return 0, 0, err
+		}
+
+		added := 0
+		for _, service := range pageServices.Services {
+			if seen[service.ServiceDetails.Id] {
+				continue
+			}
+			seen[service.ServiceDetails.Id] = true
+			services.Services = append(services.Services, service)
+			added++
+		}
+		return len(pageServices.Services), added, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return services, nil
+}
+
+func (tsc *threeScaleClient) CreateBackendUsage(ctx context.Context, accessToken, serviceID string, backendID int, path string) error {
 	res, err := tsc.makeRequest(
+		ctx,
 		"POST",
 		fmt.Sprintf("services/%s/backend_usages.json", serviceID),
 		withAccessToken(accessToken, map[string]interface{}{
@@ -426,8 +513,52 @@ func (tsc *threeScaleClient) CreateBackendUsage(accessToken, serviceID string, b
 	return assertStatusCode(http.StatusCreated, res)
 }
 
-func (tsc *threeScaleClient) CreateApplicationPlan(accessToken, serviceID, name string) (string, error) {
+func (tsc *threeScaleClient) UpdateBackendUsage(ctx context.Context, accessToken, serviceID string, backendUsageID int, path string) error {
 	res, err := tsc.makeRequest(
+		ctx,
+		"PUT",
+		fmt.Sprintf("services/%s/backend_usages/%d.json", serviceID, backendUsageID),
+		withAccessToken(accessToken, map[string]interface{}{
+			"path": path,
+		}),
+	)
+
+	if err != nil {
+		return err
+	}
+	return assertStatusCode(http.StatusOK, res)
+}
+
+func (tsc *threeScaleClient) ListBackendUsages(ctx context.Context, accessToken, serviceID string) ([]*BackendUsage, error) {
+	backendUsages := []*BackendUsage{}
+	seen := map[int]bool{}
+	err := listPages(func(page int) (int, int, error) {
+		pageBackendUsages := []*BackendUsage{}
+		if err := tsc.getPage(ctx, fmt.Sprintf("services/%s/backend_usages.json", serviceID), accessToken, page, &pageBackendUsages); err != nil {
+			return 0, 0, err
+		}
+
+		added := 0
+		for _, backendUsage := range pageBackendUsages {
+			if seen[backendUsage.BackendUsageDetails.Id] {
+				continue
+			}
+			seen[backendUsage.BackendUsageDetails.Id] = true
+			backendUsages = append(backendUsages, backendUsage)
+			added++
+		}
+		return len(pageBackendUsages), added, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return backendUsages, nil
+}
+
+func (tsc *threeScaleClient) CreateApplicationPlan(ctx context.Context, accessToken, serviceID, name string) (string, error) {
+	res, err := tsc.makeRequest(
+		ctx,
 		"POST",
 		fmt.Sprintf("services/%s/application_plans.xml", serviceID),
 		withAccessToken(accessToken, map[string]interface{}{
@@ -445,8 +576,36 @@ func (tsc *threeScaleClient) CreateApplicationPlan(accessToken, serviceID, name
 	return xmlFromResponse(res, "//plan/id/text()")
 }
 
-func (tsc *threeScaleClient) CreateApplication(accessToken, accountID, planID, name, description string) (string, error) {
+func (tsc *threeScaleClient) ListApplicationPlans(ctx context.Context, accessToken, serviceID string) (*ApplicationPlans, error) {
+	plans := &ApplicationPlans{}
+	seen := map[int]bool{}
+	err := listPages(func(page int) (int, int, error) {
+		pagePlans := &ApplicationPlans{}
+		if err := tsc.getPage(ctx, fmt.Sprintf("services/%s/application_plans.json", serviceID), accessToken, page, pagePlans); err != nil {
+			return 0, 0, err
+		}
+
+		added := 0
+		for _, plan := range pagePlans.Plans {
+			if seen[plan.ApplicationPlanDetails.Id] {
+				continue
+			}
+			seen[plan.ApplicationPlanDetails.Id] = true
+			plans.Plans = append(plans.Plans, plan)
+			added++
+		}
+		return len(pagePlans.Plans), added, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return plans, nil
+}
+
+func (tsc *threeScaleClient) CreateApplication(ctx context.Context, accessToken, accountID, planID, name, description string) (string, error) {
 	res, err := tsc.makeRequest(
+		ctx,
 		"POST",
 		fmt.Sprintf("accounts/%s/applications.xml", accountID),
 		withAccessToken(accessToken, map[string]interface{}{
@@ -466,8 +625,9 @@ func (tsc *threeScaleClient) CreateApplication(accessToken, accountID, planID, n
 	return xmlFromResponse(res, "//application/user_key/text()")
 }
 
-func (tsc *threeScaleClient) DeployProxy(accessToken, serviceID string) error {
+func (tsc *threeScaleClient) DeployProxy(ctx context.Context, accessToken, serviceID string) error {
 	res, err := tsc.makeRequest(
+		ctx,
 		"POST",
 		fmt.Sprintf("services/%s/proxy/deploy.xml", serviceID),
 		onlyAccessToken(accessToken),
@@ -480,8 +640,13 @@ func (tsc *threeScaleClient) DeployProxy(accessToken, serviceID string) error {
 	return assertStatusCode(http.StatusCreated, res)
 }
 
-func (tsc *threeScaleClient) PromoteProxy(accessToken, serviceID, env, to string) (string, error) {
-	res, err := tsc.httpc.Get(fmt.Sprintf("https://3scale-admin.%s/admin/api/services/%s/proxy/configs/%s/latest.json?access_token=%s", tsc.wildCardDomain, serviceID, env, accessToken))
+func (tsc *threeScaleClient) PromoteProxy(ctx context.Context, accessToken, serviceID, env, to string) (string, error) {
+	res, err := tsc.makeRequest(
+		ctx,
+		"GET",
+		fmt.Sprintf("services/%s/proxy/configs/%s/latest.json", serviceID, env),
+		onlyAccessToken(accessToken),
+	)
 	if err != nil {
 		return "", err
 	}
@@ -505,6 +670,7 @@ func (tsc *threeScaleClient) PromoteProxy(accessToken, serviceID, env, to string
 	}
 
 	res, err = tsc.makeRequest(
+		ctx,
 		"POST",
 		fmt.Sprintf("services/%s/proxy/configs/%s/%d/promote.json", serviceID, env, proxyConfigResponse.ProxyConfig.Version),
 		withAccessToken(accessToken, map[string]interface{}{
@@ -525,8 +691,9 @@ func (tsc *threeScaleClient) PromoteProxy(accessToken, serviceID, env, to string
 	return proxyConfigResponse.ProxyConfig.Content.Proxy.Endpoint, nil
 }
 
-func (tsc *threeScaleClient) DeleteService(accessToken, serviceID string) error {
+func (tsc *threeScaleClient) DeleteService(ctx context.Context, accessToken, serviceID string) error {
 	res, err := tsc.makeRequest(
+		ctx,
 		"DELETE",
 		fmt.Sprintf("services/%s.xml", serviceID),
 		onlyAccessToken(accessToken),
@@ -538,8 +705,9 @@ func (tsc *threeScaleClient) DeleteService(accessToken, serviceID string) error
 	return assertStatusCode(http.StatusOK, res)
 }
 
-func (tsc *threeScaleClient) DeleteBackend(accessToken string, backendID int) error {
+func (tsc *threeScaleClient) DeleteBackend(ctx context.Context, accessToken string, backendID int) error {
 	res, err := tsc.makeRequest(
+		ctx,
 		"DELETE",
 		fmt.Sprintf("backend_apis/%d.json", backendID),
 		onlyAccessToken(accessToken),
@@ -551,10 +719,11 @@ func (tsc *threeScaleClient) DeleteBackend(accessToken string, backendID int) er
 	return assertStatusCode(http.StatusOK, res)
 }
 
-func (tsc *threeScaleClient) DeleteAccount(accessToken, accountID string) error {
+func (tsc *threeScaleClient) DeleteBackendMappingRule(ctx context.Context, accessToken string, backendID, mappingRuleID int) error {
 	res, err := tsc.makeRequest(
+		ctx,
 		"DELETE",
-		fmt.Sprintf("accounts/%s.xml", accountID),
+		fmt.Sprintf("backend_apis/%d/mapping_rules/%d.json", backendID, mappingRuleID),
 		onlyAccessToken(accessToken),
 	)
 	if err != nil {
@@ -564,41 +733,87 @@ func (tsc *threeScaleClient) DeleteAccount(accessToken, accountID string) error
 	return assertStatusCode(http.StatusOK, res)
 }
 
-func (tsc *threeScaleClient) ListTenantAccounts(accessToken string, page int) ([]AccountDetail, error) {
-	// curl -v  -X GET "https://master.apps.jmonteir.edy6.s1.devshift.org/admin/api/accounts.json?access_token=AIjluIOs"
-	res, err := tsc.makeRequestToMaster(
-		"GET",
-		"admin/api/accounts.xml",
-		withAccessToken(accessToken, map[string]interface{}{
-			"page": page,
-		}),
+func (tsc *threeScaleClient) DeleteApplicationPlan(ctx context.Context, accessToken, serviceID string, planID int) error {
+	res, err := tsc.makeRequest(
+		ctx,
+		"DELETE",
+		fmt.Sprintf("services/%s/application_plans/%d.json", serviceID, planID),
+		onlyAccessToken(accessToken),
 	)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	if err := assertStatusCode(http.StatusOK, res); err != nil {
-		return nil, err
-	}
+	return assertStatusCode(http.StatusOK, res)
+}
 
-	accountList := XMLAccountList{}
-	if err := responseFromXML(res, &accountList); err != nil {
-		return nil, err
+func (tsc *threeScaleClient) DeleteAccount(ctx context.Context, accessToken, accountID string) error {
+	res, err := tsc.makeRequest(
+		ctx,
+		"DELETE",
+		fmt.Sprintf("accounts/%s.xml", accountID),
+		onlyAccessToken(accessToken),
+	)
+	if err != nil {
+		return err
 	}
 
+	return assertStatusCode(http.StatusOK, res)
+}
+
+// ListTenantAccounts returns the tenant accounts of the 3scale master,
+// excluding the master and default tenant accounts
+func (tsc *threeScaleClient) ListTenantAccounts(ctx context.Context, accessToken string) ([]AccountDetail, error) {
+	// curl -v  -X GET "https://master.apps.jmonteir.edy6.s1.devshift.org/admin/api/accounts.json?access_token=AIjluIOs"
 	accounts := []AccountDetail{}
-	// removes pre created 3scale accounts
-	for _, account := range accountList.Accounts {
-		if account.Id != 1 && account.Id != 2 {
-			accounts = append(accounts, account)
+	seen := map[int]bool{}
+	err := listPages(func(page int) (int, int, error) {
+		res, err := tsc.makeRequestToMaster(
+			ctx,
+			"GET",
+			"admin/api/accounts.xml",
+			withAccessToken(accessToken, map[string]interface{}{
+				"page":     page,
+				"per_page": listPageSize,
+			}),
+		)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		if err := assertStatusCode(http.StatusOK, res); err != nil {
+			return 0, 0, err
+		}
+
+		accountList := XMLAccountList{}
+		if err := responseFromXML(res, &accountList); err != nil {
+			return 0, 0, err
+		}
+
+		added := 0
+		for _, account := range accountList.Accounts {
+			if seen[account.Id] {
+				continue
+			}
+			seen[account.Id] = true
+			added++
+			// removes pre created 3scale accounts
+			if account.Id != 1 && account.Id != 2 {
+				accounts = append(accounts, account)
+			}
 		}
+		return len(accountList.Accounts), added, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return accounts, nil
 }
 
-func (tsc *threeScaleClient) CreateTenant(accessToken string, account AccountDetail, password string, email string) (*SignUpAccount, error) {
+func (tsc *threeScaleClient) CreateTenant(ctx context.Context, accessToken string, account AccountDetail, password string, email string) (*SignUpAccount, error) {
 	res, err := tsc.makeRequestToMaster(
+		ctx,
 		"POST",
 		"master/api/providers.xml",
 		withAccessToken(accessToken, map[string]interface{}{
@@ -624,10 +839,11 @@ func (tsc *threeScaleClient) CreateTenant(accessToken string, account AccountDet
 	return signupAccount, nil
 }
 
-func (tsc *threeScaleClient) GetTenantAccount(accessToken string, id int) (*SignUpAccount, error) {
+func (tsc *threeScaleClient) GetTenantAccount(ctx context.Context, accessToken string, id int) (*SignUpAccount, error) {
 	res, err := tsc.makeRequestToMaster(
+		ctx,
 		"GET",
-		fmt.Sprintf("master/api/providers/{%v}.xml", id),
+		fmt.Sprintf("master/api/providers/%d.xml", id),
 		onlyAccessToken(accessToken),
 	)
 	if err != nil {
@@ -646,8 +862,9 @@ func (tsc *threeScaleClient) GetTenantAccount(accessToken string, id int) (*Sign
 	return &signupAccount, nil
 }
 
-func (tsc *threeScaleClient) ActivateUser(accessToken string, accountId, userId int) error {
+func (tsc *threeScaleClient) ActivateUser(ctx context.Context, accessToken string, accountId, userId int) error {
 	res, err := tsc.makeRequestToMaster(
+		ctx,
 		"PUT",
 		fmt.Sprintf("admin/api/accounts/%d/users/%d/activate.xml", accountId, userId),
 		onlyAccessToken(accessToken),
@@ -663,11 +880,27 @@ func (tsc *threeScaleClient) ActivateUser(accessToken string, accountId, userId
 	return nil
 }
 
-func (tsc *threeScaleClient) AddAuthProviderToAccount(accessToken string, account AccountDetail, authProviderDetail AuthProviderDetails) error {
+func (tsc *threeScaleClient) UpdateTenantUserPassword(ctx context.Context, accessToken string, accountId, userId int, password string) error {
+	res, err := tsc.makeRequestToMaster(
+		ctx,
+		http.MethodPut,
+		fmt.Sprintf("admin/api/accounts/%d/users/%d.json", accountId, userId),
+		withAccessToken(accessToken, map[string]interface{}{
+			"password": password,
+		}),
+	)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	return assertStatusCode(http.StatusOK, res)
+}
+
+func (tsc *threeScaleClient) AddAuthProviderToAccount(ctx context.Context, accessToken string, account AccountDetail, authProviderDetail AuthProviderDetails) error {
 
 	url := fmt.Sprintf("%s/%s", account.AdminBaseURL, "admin/api/account/authentication_providers.json")
-	res, err := makeRequest(url,
-		"POST",
+	res, err := tsc.do(ctx, "POST", url,
 		withAccessToken(accessToken, map[string]interface{}{
 			"kind":                              authProviderDetail.Kind,
 			"name":                              authProviderDetail.Name,
@@ -678,7 +911,6 @@ func (tsc *threeScaleClient) AddAuthProviderToAccount(accessToken string, accoun
 			"published":                         authProviderDetail.Published,
 			"system_name":                       authProviderDetail.SystemName,
 		}),
-		tsc,
 	)
 	if err != nil {
 		return fmt.Errorf("Error creating new authentication provider for %s tenant account: , %w", account.OrgName, err)
@@ -691,14 +923,10 @@ func (tsc *threeScaleClient) AddAuthProviderToAccount(accessToken string, accoun
 	return nil
 }
 
-func (tsc *threeScaleClient) IsAuthProviderAdded(accessToken string, authProviderName string, account AccountDetail) (bool, error) {
+func (tsc *threeScaleClient) IsAuthProviderAdded(ctx context.Context, accessToken string, authProviderName string, account AccountDetail) (bool, error) {
 	isAuthProviderAdded := false
 	url := fmt.Sprintf("%s/%s", account.AdminBaseURL, "admin/api/account/authentication_providers.json")
-	res, err := makeRequest(url,
-		"GET",
-		onlyAccessToken(accessToken),
-		tsc,
-	)
+	res, err := tsc.do(ctx, "GET", url, onlyAccessToken(accessToken))
 	if err != nil {
 		return isAuthProviderAdded, err
 	}
@@ -722,18 +950,18 @@ func (tsc *threeScaleClient) IsAuthProviderAdded(accessToken string, authProvide
 	return isAuthProviderAdded, nil
 }
 
-func (tsc *threeScaleClient) DeleteTenants(accessToken string, accounts []AccountDetail) error {
+func (tsc *threeScaleClient) DeleteTenants(ctx context.Context, accessToken string, accounts []AccountDetail) error {
 	for _, account := range accounts {
-		err := tsc.DeleteTenant(accessToken, account.Id)
-		if err == nil {
-			return fmt.Errorf("Error deleting tenant: %s", account.Name)
+		if err := tsc.DeleteTenant(ctx, accessToken, account.Id); err != nil {
+			return fmt.Errorf("Error deleting tenant %s: %w", account.Name, err)
 		}
 	}
 	return nil
 }
 
-func (tsc *threeScaleClient) DeleteTenant(accessToken string, accountId int) error {
+func (tsc *threeScaleClient) DeleteTenant(ctx context.Context, accessToken string, accountId int) error {
 	res, err := tsc.makeRequestToMaster(
+		ctx,
 		"DELETE",
 		fmt.Sprintf("master/api/providers/%d.xml", accountId),
 		onlyAccessToken(accessToken),
@@ -749,36 +977,34 @@ func (tsc *threeScaleClient) DeleteTenant(accessToken string, accountId int) err
 	return nil
 }
 
-func makeRequest(url, method string, parameters map[string]interface{}, tsc *threeScaleClient) (*http.Response, error) {
-	dataJSON, err := json.Marshal(parameters)
-	if err != nil {
-		return nil, err
-	}
-	req, err := http.NewRequest(
-		method,
-		url,
-		bytes.NewBuffer(dataJSON),
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-
-	return tsc.httpc.Do(req)
-}
-
-func (tsc *threeScaleClient) makeRequest(method, path string, parameters map[string]interface{}) (*http.Response, error) {
+func (tsc *threeScaleClient) makeRequest(ctx context.Context, method, path string, parameters map[string]interface{}) (*http.Response, error) {
 	url := fmt.Sprintf("https://3scale-admin.%s/admin/api/%s", tsc.wildCardDomain, path)
-	return makeRequest(url, method, parameters, tsc)
+	return tsc.do(ctx, method, url, parameters)
 }
 
-func (tsc *threeScaleClient) makeRequestToMaster(method, path string, parameters map[string]interface{}) (*http.Response, error) {
+func (tsc *threeScaleClient) makeRequestToMaster(ctx context.Context, method, path string, parameters map[string]interface{}) (*http.Response, error) {
 	url := fmt.Sprintf("https://master.%s/%s", tsc.wildCardDomain, path)
-	return makeRequest(url, method, parameters, tsc)
+	return tsc.do(ctx, method, url, parameters)
+}
+
+// getPage decodes a page of a JSON list endpoint of the admin API into target
+func (tsc *threeScaleClient) getPage(ctx context.Context, path, accessToken string, page int, target interface{}) error {
+	res, err := tsc.makeRequest(ctx, http.MethodGet, path, withAccessToken(accessToken, map[string]interface{}{
+		"page":     page,
+		"per_page": listPageSize,
+	}))
+	if err != nil {
+		return err
+	}
+	if err := assertStatusCode(http.StatusOK, res); err != nil {
+		return err
+	}
+
+	return jsonFromResponse(res, target)
 }
 
 func xmlFromResponse(res *http.Response, xpath string) (string, error) {
+	defer res.Body.Close()
 	doc, err := xmlquery.Parse(res.Body)
 	if err != nil {
 		return "", err
@@ -793,11 +1019,12 @@ func xmlFromResponse(res *http.Response, xpath string) (string, error) {
 }
 
 func jsonFromResponse(res *http.Response, target interface{}) error {
-	logrus.Infof("body %v", res.Body)
+	defer res.Body.Close()
 	return json.NewDecoder(res.Body).Decode(target)
 }
 
 func responseFromXML(res *http.Response, target interface{}) error {
+	defer res.Body.Close()
 	return xml.NewDecoder(res.Body).Decode(target)
 }
 
@@ -812,11 +1039,17 @@ func withAccessToken(accessToken string, data map[string]interface{}) map[string
 	return data
 }
 
+// assertStatusCode returns a *tsError holding the status code and body of the
+// response when the status code isn't the expected one
 func assertStatusCode(expected int, res *http.Response) error {
 	if res.StatusCode == expected {
 		return nil
 	}
 
+	defer res.Body.Close()
 	body, _ := ioutil.ReadAll(res.Body)
-	return fmt.Errorf("unexpected status code: %d. Body: %s", res.StatusCode, string(body))
+	return &tsError{
+		message:    fmt.Sprintf("unexpected status code: %d. Body: %s", res.StatusCode, string(body)),
+		StatusCode: res.StatusCode,
+	}
 }
@@ -4,6 +4,7 @@
 package threescale
 
 import (
+	"context"
 	"net/http"
 	"sync"
 )
@@ -18,103 +19,142 @@ var _ ThreeScaleInterface = &ThreeScaleInterfaceMock{}
 //
 // 		// make and configure a mocked ThreeScaleInterface
 // 		mockedThreeScaleInterface := &ThreeScaleInterfaceMock{
-// 			ActivateUserFunc: func(accessToken string, accountId int, userId int) error {
+// 			ActivateUserFunc: func(ctx context.Context, accessToken string, accountId int, userId int) error {
 // 				panic("mock out the ActivateUser method")
 // 			},
-// 			AddAuthProviderToAccountFunc: func(accessToken string, account AccountDetail, authProviderDetail AuthProviderDetails) error {
+// 			AddAuthProviderToAccountFunc: func(ctx context.Context, accessToken string, account AccountDetail, authProviderDetail AuthProviderDetails) error {
 // 				panic("mock out the AddAuthProviderToAccount method")
 // 			},
-// 			AddAuthenticationProviderFunc: func(data map[string]string, accessToken string) (*http.Response, error) {
+// 			AddAuthenticationProviderFunc: func(ctx context.Context, data map[string]string, accessToken string) (*http.Response, error) {
 // 				panic("mock out the AddAuthenticationProvider method")
 // 			},
-// 			AddUserFunc: func(username string, email string, password string, accessToken string) (*http.Response, error) {
+// 			AddUserFunc: func(ctx context.Context, username string, email string, password string, accessToken string) (*http.Response, error) {
 // 				panic("mock out the AddUser method")
 // 			},
-// 			CreateAccountFunc: func(accessToken string, orgName string, username string) (string, error) {
+// 			CreateAccountFunc: func(ctx context.Context, accessToken string, orgName string, username string) (string, error) {
 // 				panic("mock out the CreateAccount method")
 // 			},
-// 			CreateApplicationFunc: func(accessToken string, accountID string, planID string, name string, description string) (string, error) {
+// 			CreateApplicationFunc: func(ctx context.Context, accessToken string, accountID string, planID string, name string, description string) (string, error) {
 // 				panic("mock out the CreateApplication method")
 // 			},
-// 			CreateApplicationPlanFunc: func(accessToken string, serviceID string, name string) (string, error) {
+// 			CreateApplicationPlanFunc: func(ctx context.Context, accessToken string, serviceID string, name string) (string, error) {
 // 				panic("mock out the CreateApplicationPlan method")
 // 			},
-// 			CreateBackendFunc: func(accessToken string, name string, privateEndpoint string) (int, error) {
+// 			CreateBackendFunc: func(ctx context.Context, accessToken string, name string, systemName string, privateEndpoint string) (int, error) {
 // 				panic("mock out the CreateBackend method")
 // 			},
-// 			CreateBackendMappingRuleFunc: func(accessToken string, backendID int, metricID int, httpMethod string, pattern string, delta int) error {
+// 			CreateBackendMappingRuleFunc: func(ctx context.Context, accessToken string, backendID int, metricID int, httpMethod string, pattern string, delta int) error {
 // 				panic("mock out the CreateBackendMappingRule method")
 // 			},
-// 			CreateBackendUsageFunc: func(accessToken string, serviceID string, backendID int, path string) error {
+// 			CreateBackendUsageFunc: func(ctx context.Context, accessToken string, serviceID string, backendID int, path string) error {
 // 				panic("mock out the CreateBackendUsage method")
 // 			},
-// 			CreateMetricFunc: func(accessToken string, backendID int, friendlyName string, unit string) (int, error) {
+// 			CreateMetricFunc: func(ctx context.Context, accessToken string, backendID int, friendlyName string, unit string) (int, error) {
 // 				panic("mock out the CreateMetric method")
 // 			},
-// 			CreateServiceFunc: func(accessToken string, name string, systemName string) (string, error) {
+// 			CreateServiceFunc: func(ctx context.Context, accessToken string, name string, systemName string) (string, error) {
 // 				panic("mock out the CreateService method")
 // 			},
-// 			CreateTenantFunc: func(accessToken string, account AccountDetail, password string, email string) (*SignUpAccount, error) {
+// 			CreateTenantFunc: func(ctx context.Context, accessToken string, account AccountDetail, password string, email string) (*SignUpAccount, error) {
 // 				panic("mock out the CreateTenant method")
 // 			},
-// 			DeleteAccountFunc: func(accessToken string, accountID string) error {
+// 			DeleteAccountFunc: func(ctx context.Context, accessToken string, accountID string) error {
 // 				panic("mock out the DeleteAccount method")
 // 			},
-// 			DeleteBackendFunc: func(accessToken string, backendID int) error {
+// 			DeleteApplicationPlanFunc: func(ctx context.Context, accessToken string, serviceID string, planID int) error {
+// 				panic("mock out the DeleteApplicationPlan method")
+// 			},
+// 			DeleteBackendFunc: func(ctx context.Context, accessToken string, backendID int) error {
 // 				panic("mock out the DeleteBackend method")
 // 			},
-// 			DeleteServiceFunc: func(accessToken string, serviceID string) error {
+// 			DeleteBackendMappingRuleFunc: func(ctx context.Context, accessToken string, backendID int, mappingRuleID int) error {
+// 				panic("mock out the DeleteBackendMappingRule method")
+// 			},
+// 			DeleteServiceFunc: func(ctx context.Context, accessToken string, serviceID string) error {
 // 				panic("mock out the DeleteService method")
 // 			},
-// 			DeleteTenantFunc: func(accessToken string, id int) error {
+// 			DeleteTenantFunc: func(ctx context.Context, accessToken string, id int) error {
 // 				panic("mock out the DeleteTenant method")
 // 			},
-// 			DeleteTenantsFunc: func(accessToken string, accounts []AccountDetail) error {
+// 			DeleteTenantsFunc: func(ctx context.Context, accessToken string, accounts []AccountDetail) error {
 // 				panic("mock out the DeleteTenants method")
 // 			},
-// 			DeleteUserFunc: func(userID int, accessToken string) (*http.Response, error) {
+// 			DeleteUserFunc: func(ctx context.Context, userID int, accessToken string) (*http.Response, error) {
 // 				panic("mock out the DeleteUser method")
 // 			},
-// 			DeployProxyFunc: func(accessToken string, serviceID string) error {
+// 			DeployProxyFunc: func(ctx context.Context, accessToken string, serviceID string) error {
 // 				panic("mock out the DeployProxy method")
 // 			},
-// 			GetAuthenticationProviderByNameFunc: func(name string, accessToken string) (*AuthProvider, error) {
+// 			GetAuthenticationProviderByNameFunc: func(ctx context.Context, name string, accessToken string) (*AuthProvider, error) {
 // 				panic("mock out the GetAuthenticationProviderByName method")
 // 			},
-// 			GetAuthenticationProvidersFunc: func(accessToken string) (*AuthProviders, error) {
+// 			GetAuthenticationProvidersFunc: func(ctx context.Context, accessToken string) (*AuthProviders, error) {
 // 				panic("mock out the GetAuthenticationProviders method")
 // 			},
-// 			GetTenantAccountFunc: func(accessToken string, id int) (*SignUpAccount, error) {
+// 			GetTenantAccountFunc: func(ctx context.Context, accessToken string, id int) (*SignUpAccount, error) {
 // 				panic("mock out the GetTenantAccount method")
 // 			},
-// 			GetUserFunc: func(username string, accessToken string) (*User, error) {
+// 			GetUserFunc: func(ctx context.Context, username string, accessToken string) (*User, error) {
 // 				panic("mock out the GetUser method")
 // 			},
-// 			GetUsersFunc: func(accessToken string) (*Users, error) {
+// 			GetUsersFunc: func(ctx context.Context, accessToken string) (*Users, error) {
 // 				panic("mock out the GetUsers method")
 // 			},
-// 			IsAuthProviderAddedFunc: func(accessToken string, authProviderName string, account AccountDetail) (bool, error) {
+// 			IsAuthProviderAddedFunc: func(ctx context.Context, accessToken string, authProviderName string, account AccountDetail) (bool, error) {
 // 				panic("mock out the IsAuthProviderAdded method")
 // 			},
-// 			ListTenantAccountsFunc: func(accessToken string, page int) ([]AccountDetail, error) {
+// 			ListApplicationPlansFunc: func(ctx context.Context, accessToken string, serviceID string) (*ApplicationPlans, error) {
+// 				panic("mock out the ListApplicationPlans method")
+// 			},
+// 			ListBackendMappingRulesFunc: func(ctx context.Context, accessToken string, backendID int) (*MappingRules, error) {
+// 				panic("mock out the ListBackendMappingRules method")
+// 			},
+// 			ListBackendMetricsFunc: func(ctx context.Context, accessToken string, backendID int) (*Metrics, error) {
+// 				panic("mock out the ListBackendMetrics method")
+// 			},
+// 			ListBackendUsagesFunc: func(ctx context.Context, accessToken string, serviceID string) ([]*BackendUsage, error) {
+// 				panic("mock out the ListBackendUsages method")
+// 			},
+// 			ListBackendsFunc: func(ctx context.Context, accessToken string) (*Backends, error) {
+// 				panic("mock out the ListBackends method")
+// 			},
+// 			ListServicesFunc: func(ctx context.Context, accessToken string) (*Services, error) {
+// 				panic("mock out the ListServices method")
+// 			},
+// 			ListTenantAccountsFunc: func(ctx context.Context, accessToken string) ([]AccountDetail, error) {
 // 				panic("mock out the ListTenantAccounts method")
 // 			},
-// 			PromoteProxyFunc: func(accessToken string, serviceID string, env string, to string) (string, error) {
+// 			PromoteProxyFunc: func(ctx context.Context, accessToken string, serviceID string, env string, to string) (string, error) {
 // 				panic("mock out the PromoteProxy method")
 // 			},
-// 			SetFromEmailAddressFunc: func(emailAddress string, accessToken string) (*http.Response, error) {
+// 			SetFromEmailAddressFunc: func(ctx context.Context, emailAddress string, accessToken string) (*http.Response, error) {
 // 				panic("mock out the SetFromEmailAddress method")
 // 			},
 // 			SetNamespaceFunc: func(ns string)  {
 // 				panic("mock out the SetNamespace method")
 // 			},
-// 			SetUserAsAdminFunc: func(userID int, accessToken string) (*http.Response, error) {
+// 			SetUserAsAdminFunc: func(ctx context.Context, userID int, accessToken string) (*http.Response, error) {
 // 				panic("mock out the SetUserAsAdmin method")
 // 			},
-// 			SetUserAsMemberFunc: func(userID int, accessToken string) (*http.Response, error) {
+// 			SetUserAsMemberFunc: func(ctx context.Context, userID int, accessToken string) (*http.Response, error) {
 // 				panic("mock out the SetUserAsMember method")
 // 			},
-// 			UpdateUserFunc: func(userID int, username string, email string, accessToken string) (*http.Response, error) {
+// 			SetUserPermissionsFunc: func(ctx context.Context, userID int, allowedSections []string, accessToken string) (*http.Response, error) {
+// 				panic("mock out the SetUserPermissions method")
+// 			},
+// 			UpdateAuthenticationProviderFunc: func(ctx context.Context, id int, data map[string]string, accessToken string) (*http.Response, error) {
+// 				panic("mock out the UpdateAuthenticationProvider method")
+// 			},
+// 			UpdateBackendFunc: func(ctx context.Context, accessToken string, backendID int, privateEndpoint string) error {
+// 				panic("mock out the UpdateBackend method")
+// 			},
+// 			UpdateBackendUsageFunc: func(ctx context.Context, accessToken string, serviceID string, backendUsageID int, path string) error {
+// 				panic("mock out the UpdateBackendUsage method")
+// 			},
+// 			UpdateTenantUserPasswordFunc: func(ctx context.Context, accessToken string, accountId int, userId int, password string) error {
+// 				panic("mock out the UpdateTenantUserPassword method")
+// 			},
+// 			UpdateUserFunc: func(ctx context.Context, userID int, username string, email string, accessToken string) (*http.Response, error) {
 // 				panic("mock out the UpdateUser method")
 // 			},
 // 		}
@@ -125,108 +165,149 @@ var _ ThreeScaleInterface = &ThreeScaleInterfaceMock{}
 // 	}
 type ThreeScaleInterfaceMock struct {
 	// ActivateUserFunc mocks the ActivateUser method.
-	ActivateUserFunc func(accessToken string, accountId int, userId int) error
+	ActivateUserFunc func(ctx context.Context, accessToken string, accountId int, userId int) error
 
 	// AddAuthProviderToAccountFunc mocks the AddAuthProviderToAccount method.
-	AddAuthProviderToAccountFunc func(accessToken string, account AccountDetail, authProviderDetail AuthProviderDetails) error
+	AddAuthProviderToAccountFunc func(ctx context.Context, accessToken string, account AccountDetail, authProviderDetail AuthProviderDetails) error
 
 	// AddAuthenticationProviderFunc mocks the AddAuthenticationProvider method.
-	AddAuthenticationProviderFunc func(data map[string]string, accessToken string) (*http.Response, error)
+	AddAuthenticationProviderFunc func(ctx context.Context, data map[string]string, accessToken string) (*http.Response, error)
 
 	// AddUserFunc mocks the AddUser method.
-	AddUserFunc func(username string, email string, password string, accessToken string) (*http.Response, error)
+	AddUserFunc func(ctx context.Context, username string, email string, password string, accessToken string) (*http.Response, error)
 
 	// CreateAccountFunc mocks the CreateAccount method.
-	CreateAccountFunc func(accessToken string, orgName string, username string) (string, error)
+	CreateAccountFunc func(ctx context.Context, accessToken string, orgName string, username string) (string, error)
 
 	// CreateApplicationFunc mocks the CreateApplication method.
-	CreateApplicationFunc func(accessToken string, accountID string, planID string, name string, description string) (string, error)
+	CreateApplicationFunc func(ctx context.Context, accessToken string, accountID string, planID string, name string, description string) (string, error)
 
 	// CreateApplicationPlanFunc mocks the CreateApplicationPlan method.
-	CreateApplicationPlanFunc func(accessToken string, serviceID string, name string) (string, error)
+	CreateApplicationPlanFunc func(ctx context.Context, accessToken string, serviceID string, name string) (string, error)
 
 	// CreateBackendFunc mocks the CreateBackend method.
-	CreateBackendFunc func(accessToken string, name string, privateEndpoint string) (int, error)
+	CreateBackendFunc func(ctx context.Context, accessToken string, name string, systemName string, privateEndpoint string) (int, error)
 
 	// CreateBackendMappingRuleFunc mocks the CreateBackendMappingRule method.
-	CreateBackendMappingRuleFunc func(accessToken string, backendID int, metricID int, httpMethod string, pattern string, delta int) error
+	CreateBackendMappingRuleFunc func(ctx context.Context, accessToken string, backendID int, metricID int, httpMethod string, pattern string, delta int) error
 
 	// CreateBackendUsageFunc mocks the CreateBackendUsage method.
-	CreateBackendUsageFunc func(accessToken string, serviceID string, backendID int, path string) error
+	CreateBackendUsageFunc func(ctx context.Context, accessToken string, serviceID string, backendID int, path string) error
 
 	// CreateMetricFunc mocks the CreateMetric method.
-	CreateMetricFunc func(accessToken string, backendID int, friendlyName string, unit string) (int, error)
+	CreateMetricFunc func(ctx context.Context, accessToken string, backendID int, friendlyName string, unit string) (int, error)
 
 	// CreateServiceFunc mocks the CreateService method.
-	CreateServiceFunc func(accessToken string, name string, systemName string) (string, error)
+	CreateServiceFunc func(ctx context.Context, accessToken string, name string, systemName string) (string, error)
 
 	// CreateTenantFunc mocks the CreateTenant method.
-	CreateTenantFunc func(accessToken string, account AccountDetail, password string, email string) (*SignUpAccount, error)
+	CreateTenantFunc func(ctx context.Context, accessToken string, account AccountDetail, password string, email string) (*SignUpAccount, error)
 
 	// DeleteAccountFunc mocks the DeleteAccount method.
-	DeleteAccountFunc func(accessToken string, accountID string) error
+	DeleteAccountFunc func(ctx context.Context, accessToken string, accountID string) error
+
+	// DeleteApplicationPlanFunc mocks the DeleteApplicationPlan method.
+	DeleteApplicationPlanFunc func(ctx context.Context, accessToken string, serviceID string, planID int) error
 
 	// DeleteBackendFunc mocks the DeleteBackend method.
-	DeleteBackendFunc func(accessToken string, backendID int) error
+	DeleteBackendFunc func(ctx context.Context, accessToken string, backendID int) error
+
+	// DeleteBackendMappingRuleFunc mocks the DeleteBackendMappingRule method.
+	DeleteBackendMappingRuleFunc func(ctx context.Context, accessToken string, backendID int, mappingRuleID int) error
 
 	// DeleteServiceFunc mocks the DeleteService method.
-	DeleteServiceFunc func(accessToken string, serviceID string) error
+	DeleteServiceFunc func(ctx context.Context, accessToken string, serviceID string) error
 
 	// DeleteTenantFunc mocks the DeleteTenant method.
-	DeleteTenantFunc func(accessToken string, id int) error
+	DeleteTenantFunc func(ctx context.Context, accessToken string, id int) error
 
 	// DeleteTenantsFunc mocks the DeleteTenants method.
-	DeleteTenantsFunc func(accessToken string, accounts []AccountDetail) error
+	DeleteTenantsFunc func(ctx context.Context, accessToken string, accounts []AccountDetail) error
 
 	// DeleteUserFunc mocks the DeleteUser method.
-	DeleteUserFunc func(userID int, accessToken string) (*http.Response, error)
+	DeleteUserFunc func(ctx context.Context, userID int, accessToken string) (*http.Response, error)
 
 	// DeployProxyFunc mocks the DeployProxy method.
-	DeployProxyFunc func(accessToken string, serviceID string) error
+	DeployProxyFunc func(ctx context.Context, accessToken string, serviceID string) error
 
 	// GetAuthenticationProviderByNameFunc mocks the GetAuthenticationProviderByName method.
-	GetAuthenticationProviderByNameFunc func(name string, accessToken string) (*AuthProvider, error)
+	GetAuthenticationProviderByNameFunc func(ctx context.Context, name string, accessToken string) (*AuthProvider, error)
 
 	// GetAuthenticationProvidersFunc mocks the GetAuthenticationProviders method.
-	GetAuthenticationProvidersFunc func(accessToken string) (*AuthProviders, error)
+	GetAuthenticationProvidersFunc func(ctx context.Context, accessToken string) (*AuthProviders, error)
 
 	// GetTenantAccountFunc mocks the GetTenantAccount method.
-	GetTenantAccountFunc func(accessToken string, id int) (*SignUpAccount, error)
+	GetTenantAccountFunc func(ctx context.Context, accessToken string, id int) (*SignUpAccount, error)
 
 	// GetUserFunc mocks the GetUser method.
-	GetUserFunc func(username string, accessToken string) (*User, error)
+	GetUserFunc func(ctx context.Context, username string, accessToken string) (*User, error)
 
 	// GetUsersFunc mocks the GetUsers method.
-	GetUsersFunc func(accessToken string) (*Users, error)
+	GetUsersFunc func(ctx context.Context, accessToken string) (*Users, error)
 
 	// IsAuthProviderAddedFunc mocks the IsAuthProviderAdded method.
-	IsAuthProviderAddedFunc func(accessToken string, authProviderName string, account AccountDetail) (bool, error)
+	IsAuthProviderAddedFunc func(ctx context.Context, accessToken string, authProviderName string, account AccountDetail) (bool, error)
+
+	// ListApplicationPlansFunc mocks the ListApplicationPlans method.
+	ListApplicationPlansFunc func(ctx context.Context, accessToken string, serviceID string) (*ApplicationPlans, error)
+
+	// ListBackendMappingRulesFunc mocks the ListBackendMappingRules method.
+	ListBackendMappingRulesFunc func(ctx context.Context, accessToken string, backendID int) (*MappingRules, error)
+
+	// ListBackendMetricsFunc mocks the ListBackendMetrics method.
+	ListBackendMetricsFunc func(ctx context.Context, accessToken string, backendID int) (*Metrics, error)
+
+	// ListBackendUsagesFunc mocks the ListBackendUsages method.
+	ListBackendUsagesFunc func(ctx context.Context, accessToken string, serviceID string) ([]*BackendUsage, error)
+
+	// ListBackendsFunc mocks the ListBackends method.
+	ListBackendsFunc func(ctx context.Context, accessToken string) (*Backends, error)
+
+	// ListServicesFunc mocks the ListServices method.
+	ListServicesFunc func(ctx context.Context, accessToken string) (*Services, error)
 
 	// ListTenantAccountsFunc mocks the ListTenantAccounts method.
-	ListTenantAccountsFunc func(accessToken string, page int) ([]AccountDetail, error)
+	ListTenantAccountsFunc func(ctx context.Context, accessToken string) ([]AccountDetail, error)
 
 	// PromoteProxyFunc mocks the PromoteProxy method.
-	PromoteProxyFunc func(accessToken string, serviceID string, env string, to string) (string, error)
+	PromoteProxyFunc func(ctx context.Context, accessToken string, serviceID string, env string, to string) (string, error)
 
 	// SetFromEmailAddressFunc mocks the SetFromEmailAddress method.
-	SetFromEmailAddressFunc func(emailAddress string, accessToken string) (*http.Response, error)
+	SetFromEmailAddressFunc func(ctx context.Context, emailAddress string, accessToken string) (*http.Response, error)
 
 	// SetNamespaceFunc mocks the SetNamespace method.
 	SetNamespaceFunc func(ns string)
 
 	// SetUserAsAdminFunc mocks the SetUserAsAdmin method.
-	SetUserAsAdminFunc func(userID int, accessToken string) (*http.Response, error)
+	SetUserAsAdminFunc func(ctx context.Context, userID int, accessToken string) (*http.Response, error)
 
 	// SetUserAsMemberFunc mocks the SetUserAsMember method.
-	SetUserAsMemberFunc func(userID int, accessToken string) (*http.Response, error)
+	SetUserAsMemberFunc func(ctx context.Context, userID int, accessToken string) (*http.Response, error)
+
+	// SetUserPermissionsFunc mocks the SetUserPermissions method.
+	SetUserPermissionsFunc func(ctx context.Context, userID int, allowedSections []string, accessToken string) (*http.Response, error)
+
+	// UpdateAuthenticationProviderFunc mocks the UpdateAuthenticationProvider method.
+	UpdateAuthenticationProviderFunc func(ctx context.Context, id int, data map[string]string, accessToken string) (*http.Response, error)
+
+	// UpdateBackendFunc mocks the UpdateBackend method.
+	UpdateBackendFunc func(ctx context.Context, accessToken string, backendID int, privateEndpoint string) error
+
+	// UpdateBackendUsageFunc mocks the UpdateBackendUsage method.
+	UpdateBackendUsageFunc func(ctx context.Context, accessToken string, serviceID string, backendUsageID int, path string) error
+
+	// UpdateTenantUserPasswordFunc mocks the UpdateTenantUserPassword method.
+	UpdateTenantUserPasswordFunc func(ctx context.Context, accessToken string, accountId int, userId int, password string) error
 
 	// UpdateUserFunc mocks the UpdateUser method.
-	UpdateUserFunc func(userID int, username string, email string, accessToken string) (*http.Response, error)
+	UpdateUserFunc func(ctx context.Context, userID int, username string, email string, accessToken string) (*http.Response, error)
 
 	// calls tracks calls to the methods.
 	calls struct {
 		// ActivateUser holds details about calls to the ActivateUser method.
 		ActivateUser []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// AccessToken is the accessToken argument value.
 			AccessToken string
 			// AccountId is the accountId argument value.
@@ -236,6 +317,8 @@ type ThreeScaleInterfaceMock struct {
 		}
 		// AddAuthProviderToAccount holds details about calls to the AddAuthProviderToAccount method.
 		AddAuthProviderToAccount []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// AccessToken is the accessToken argument value.
 			AccessToken string
 			// Account is the account argument value.
@@ -245,6 +328,8 @@ type ThreeScaleInterfaceMock struct {
 		}
 		// AddAuthenticationProvider holds details about calls to the AddAuthenticationProvider method.
 		AddAuthenticationProvider []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// Data is the data argument value.
 			Data map[string]string
 			// AccessToken is the accessToken argument value.
@@ -252,6 +337,8 @@ type ThreeScaleInterfaceMock struct {
 		}
 		// AddUser holds details about calls to the AddUser method.
 		AddUser []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// Username is the username argument value.
 			Username string
 			// Email is the email argument value.
@@ -263,6 +350,8 @@ type ThreeScaleInterfaceMock struct {
 		}
 		// CreateAccount holds details about calls to the CreateAccount method.
 		CreateAccount []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// AccessToken is the accessToken argument value.
 			AccessToken string
 			// OrgName is the orgName argument value.
@@ -272,6 +361,8 @@ type ThreeScaleInterfaceMock struct {
 		}
 		// CreateApplication holds details about calls to the CreateApplication method.
 		CreateApplication []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// AccessToken is the accessToken argument value.
 			AccessToken string
 			// AccountID is the accountID argument value.
@@ -285,6 +376,8 @@ type ThreeScaleInterfaceMock struct {
 		}
 		// CreateApplicationPlan holds details about calls to the CreateApplicationPlan method.
 		CreateApplicationPlan []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// AccessToken is the accessToken argument value.
 			AccessToken string
 			// ServiceID is the serviceID argument value.
@@ -294,15 +387,21 @@ type ThreeScaleInterfaceMock struct {
 		}
 		// CreateBackend holds details about calls to the CreateBackend method.
 		CreateBackend []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// AccessToken is the accessToken argument value.
 			AccessToken string
 			// Name is the name argument value.
 			Name string
+			// SystemName is the systemName argument value.
+			SystemName string
 			// PrivateEndpoint is the privateEndpoint argument value.
 			PrivateEndpoint string
 		}
 		// CreateBackendMappingRule holds details about calls to the CreateBackendMappingRule method.
 		CreateBackendMappingRule []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// AccessToken is the accessToken argument value.
 			AccessToken string
 			// BackendID is the backendID argument value.
@@ -318,6 +417,8 @@ type ThreeScaleInterfaceMock struct {
 		}
 		// CreateBackendUsage holds details about calls to the CreateBackendUsage method.
 		CreateBackendUsage []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// AccessToken is the accessToken argument value.
 			AccessToken string
 			// ServiceID is the serviceID argument value.
@@ -329,6 +430,8 @@ type ThreeScaleInterfaceMock struct {
 		}
 		// CreateMetric holds details about calls to the CreateMetric method.
 		CreateMetric []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// AccessToken is the accessToken argument value.
 			AccessToken string
 			// BackendID is the backendID argument value.
@@ -340,6 +443,8 @@ type ThreeScaleInterfaceMock struct {
 		}
 		// CreateService holds details about calls to the CreateService method.
 		CreateService []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// AccessToken is the accessToken argument value.
 			AccessToken string
 			// Name is the name argument value.
@@ -349,6 +454,8 @@ type ThreeScaleInterfaceMock struct {
 		}
 		// CreateTenant holds details about calls to the CreateTenant method.
 		CreateTenant []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// AccessToken is the accessToken argument value.
 			AccessToken string
 			// Account is the account argument value.
@@ -360,20 +467,48 @@ type ThreeScaleInterfaceMock struct {
 		}
 		// DeleteAccount holds details about calls to the DeleteAccount method.
 		DeleteAccount []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// AccessToken is the accessToken argument value.
 			AccessToken string
 			// AccountID is the accountID argument value.
 			AccountID string
 		}
+		// DeleteApplicationPlan holds details about calls to the DeleteApplicationPlan method.
+		DeleteApplicationPlan []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// AccessToken is the accessToken argument value.
+			AccessToken string
+			// ServiceID is the serviceID argument value.
+			ServiceID string
+			// PlanID is the planID argument value.
+			PlanID int
+		}
 		// DeleteBackend holds details about calls to the DeleteBackend method.
 		DeleteBackend []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// AccessToken is the accessToken argument value.
 			AccessToken string
 			// BackendID is the backendID argument value.
 			BackendID int
 		}
+		// DeleteBackendMappingRule holds details about calls to the DeleteBackendMappingRule method.
+		DeleteBackendMappingRule []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// AccessToken is the accessToken argument value.
+			AccessToken string
+			// BackendID is the backendID argument value.
+			BackendID int
+			// MappingRuleID is the mappingRuleID argument value.
+			MappingRuleID int
+		}
 		// DeleteService holds details about calls to the DeleteService method.
 		DeleteService []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// AccessToken is the accessToken argument value.
 			AccessToken string
 			// ServiceID is the serviceID argument value.
@@ -381,6 +516,8 @@ type ThreeScaleInterfaceMock struct {
 		}
 		// DeleteTenant holds details about calls to the DeleteTenant method.
 		DeleteTenant []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// AccessToken is the accessToken argument value.
 			AccessToken string
 			// ID is the id argument value.
@@ -388,6 +525,8 @@ type ThreeScaleInterfaceMock struct {
 		}
 		// DeleteTenants holds details about calls to the DeleteTenants method.
 		DeleteTenants []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// AccessToken is the accessToken argument value.
 			AccessToken string
 			// Accounts is the accounts argument value.
@@ -395,6 +534,8 @@ type ThreeScaleInterfaceMock struct {
 		}
 		// DeleteUser holds details about calls to the DeleteUser method.
 		DeleteUser []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// UserID is the userID argument value.
 			UserID int
 			// AccessToken is the accessToken argument value.
@@ -402,6 +543,8 @@ type ThreeScaleInterfaceMock struct {
 		}
 		// DeployProxy holds details about calls to the DeployProxy method.
 		DeployProxy []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// AccessToken is the accessToken argument value.
 			AccessToken string
 			// ServiceID is the serviceID argument value.
@@ -409,6 +552,8 @@ type ThreeScaleInterfaceMock struct {
 		}
 		// GetAuthenticationProviderByName holds details about calls to the GetAuthenticationProviderByName method.
 		GetAuthenticationProviderByName []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// Name is the name argument value.
 			Name string
 			// AccessToken is the accessToken argument value.
@@ -416,11 +561,15 @@ type ThreeScaleInterfaceMock struct {
 		}
 		// GetAuthenticationProviders holds details about calls to the GetAuthenticationProviders method.
 		GetAuthenticationProviders []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// AccessToken is the accessToken argument value.
 			AccessToken string
 		}
 		// GetTenantAccount holds details about calls to the GetTenantAccount method.
 		GetTenantAccount []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// AccessToken is the accessToken argument value.
 			AccessToken string
 			// ID is the id argument value.
@@ -428,6 +577,8 @@ type ThreeScaleInterfaceMock struct {
 		}
 		// GetUser holds details about calls to the GetUser method.
 		GetUser []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// Username is the username argument value.
 			Username string
 			// AccessToken is the accessToken argument value.
@@ -435,11 +586,15 @@ type ThreeScaleInterfaceMock struct {
 		}
 		// GetUsers holds details about calls to the GetUsers method.
 		GetUsers []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// AccessToken is the accessToken argument value.
 			AccessToken string
 		}
 		// IsAuthProviderAdded holds details about calls to the IsAuthProviderAdded method.
 		IsAuthProviderAdded []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// AccessToken is the accessToken argument value.
 			AccessToken string
 			// AuthProviderName is the authProviderName argument value.
@@ -447,15 +602,67 @@ type ThreeScaleInterfaceMock struct {
 			// Account is the account argument value.
 			Account AccountDetail
 		}
+		// ListApplicationPlans holds details about calls to the ListApplicationPlans method.
+		ListApplicationPlans []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// AccessToken is the accessToken argument value.
+			AccessToken string
+			// ServiceID is the serviceID argument value.
+			ServiceID string
+		}
+		// ListBackendMappingRules holds details about calls to the ListBackendMappingRules method.
+		ListBackendMappingRules []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// AccessToken is the accessToken argument value.
+			AccessToken string
+			// BackendID is the backendID argument value.
+			BackendID int
+		}
+		// ListBackendMetrics holds details about calls to the ListBackendMetrics method.
+		ListBackendMetrics []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// AccessToken is the accessToken argument value.
+			AccessToken string
+			// BackendID is the backendID argument value.
+			BackendID int
+		}
+		// ListBackendUsages holds details about calls to the ListBackendUsages method.
+		ListBackendUsages []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// AccessToken is the accessToken argument value.
+			AccessToken string
+			// ServiceID is the serviceID argument value.
+			ServiceID string
+		}
+		// ListBackends holds details about calls to the ListBackends method.
+		ListBackends []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// AccessToken is the accessToken argument value.
+			AccessToken string
+		}
+		// ListServices holds details about calls to the ListServices method.
+		ListServices []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// AccessToken is the accessToken argument value.
+			AccessToken string
+		}
 		// ListTenantAccounts holds details about calls to the ListTenantAccounts method.
 		ListTenantAccounts []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// AccessToken is the accessToken argument value.
 			AccessToken string
-			// Page is the page argument value.
-			Page int
 		}
 		// PromoteProxy holds details about calls to the PromoteProxy method.
 		PromoteProxy []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// AccessToken is the accessToken argument value.
 			AccessToken string
 			// ServiceID is the serviceID argument value.
@@ -467,6 +674,8 @@ type ThreeScaleInterfaceMock struct {
 		}
 		// SetFromEmailAddress holds details about calls to the SetFromEmailAddress method.
 		SetFromEmailAddress []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// EmailAddress is the emailAddress argument value.
 			EmailAddress string
 			// AccessToken is the accessToken argument value.
@@ -479,6 +688,8 @@ type ThreeScaleInterfaceMock struct {
 		}
 		// SetUserAsAdmin holds details about calls to the SetUserAsAdmin method.
 		SetUserAsAdmin []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// UserID is the userID argument value.
 			UserID int
 			// AccessToken is the accessToken argument value.
@@ -486,13 +697,76 @@ type ThreeScaleInterfaceMock struct {
 		}
 		// SetUserAsMember holds details about calls to the SetUserAsMember method.
 		SetUserAsMember []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// UserID is the userID argument value.
+			UserID int
+			// AccessToken is the accessToken argument value.
+			AccessToken string
+		}
+		// SetUserPermissions holds details about calls to the SetUserPermissions method.
+		SetUserPermissions []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// UserID is the userID argument value.
 			UserID int
+			// AllowedSections is the allowedSections argument value.
+			AllowedSections []string
+			// AccessToken is the accessToken argument value.
+			AccessToken string
+		}
+		// UpdateAuthenticationProvider holds details about calls to the UpdateAuthenticationProvider method.
+		UpdateAuthenticationProvider []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID int
+			// Data is the data argument value.
+			Data map[string]string
+			// AccessToken is the accessToken argument value.
+			AccessToken string
+		}
+		// UpdateBackend holds details about calls to the UpdateBackend method.
+		UpdateBackend []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// AccessToken is the accessToken argument value.
+			AccessToken string
+			// BackendID is the backendID argument value.
+			BackendID int
+			// PrivateEndpoint is the privateEndpoint argument value.
+			PrivateEndpoint string
+		}
+		// UpdateBackendUsage holds details about calls to the UpdateBackendUsage method.
+		UpdateBackendUsage []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// AccessToken is the accessToken argument value.
+			AccessToken string
+			// ServiceID is the serviceID argument value.
+			ServiceID string
+			// BackendUsageID is the backendUsageID argument value.
+			BackendUsageID int
+			// Path is the path argument value.
+			Path string
+		}
+		// UpdateTenantUserPassword holds details about calls to the UpdateTenantUserPassword method.
+		UpdateTenantUserPassword []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// AccessToken is the accessToken argument value.
 			AccessToken string
+			// AccountId is the accountId argument value.
+			AccountId int
+			// UserId is the userId argument value.
+			UserId int
+			// Password is the password argument value.
+			Password string
 		}
 		// UpdateUser holds details about calls to the UpdateUser method.
 		UpdateUser []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// UserID is the userID argument value.
 			UserID int
 			// Username is the username argument value.
@@ -517,7 +791,9 @@ type ThreeScaleInterfaceMock struct {
 	lockCreateService                   sync.RWMutex
 	lockCreateTenant                    sync.RWMutex
 	lockDeleteAccount                   sync.RWMutex
+	lockDeleteApplicationPlan           sync.RWMutex
 	lockDeleteBackend                   sync.RWMutex
+	lockDeleteBackendMappingRule        sync.RWMutex
 	lockDeleteService                   sync.RWMutex
 	lockDeleteTenant                    sync.RWMutex
 	lockDeleteTenants                   sync.RWMutex
@@ -529,25 +805,38 @@ type ThreeScaleInterfaceMock struct {
 	lockGetUser                         sync.RWMutex
 	lockGetUsers                        sync.RWMutex
 	lockIsAuthProviderAdded             sync.RWMutex
+	lockListApplicationPlans            sync.RWMutex
+	lockListBackendMappingRules         sync.RWMutex
+	lockListBackendMetrics              sync.RWMutex
+	lockListBackendUsages               sync.RWMutex
+	lockListBackends                    sync.RWMutex
+	lockListServices                    sync.RWMutex
 	lockListTenantAccounts              sync.RWMutex
 	lockPromoteProxy                    sync.RWMutex
 	lockSetFromEmailAddress             sync.RWMutex
 	lockSetNamespace                    sync.RWMutex
 	lockSetUserAsAdmin                  sync.RWMutex
 	lockSetUserAsMember                 sync.RWMutex
+	lockSetUserPermissions              sync.RWMutex
+	lockUpdateAuthenticationProvider    sync.RWMutex
+	lockUpdateBackend                   sync.RWMutex
+	lockUpdateBackendUsage              sync.RWMutex
+	lockUpdateTenantUserPassword        sync.RWMutex
 	lockUpdateUser                      sync.RWMutex
 }
 
 // ActivateUser calls ActivateUserFunc.
-func (mock *ThreeScaleInterfaceMock) ActivateUser(accessToken string, accountId int, userId int) error {
+func (mock *ThreeScaleInterfaceMock) ActivateUser(ctx context.Context, accessToken string, accountId int, userId int) error {
 	if mock.ActivateUserFunc == nil {
 		panic("ThreeScaleInterfaceMock.ActivateUserFunc: method is nil but ThreeScaleInterface.ActivateUser was just called")
 	}
 	callInfo := struct {
+		Ctx         context.Context
 		AccessToken string
 		AccountId   int
 		UserId      int
 	}{
+		Ctx:         ctx,
 		AccessToken: accessToken,
 		AccountId:   accountId,
 		UserId:      userId,
@@ -555,18 +844,20 @@ func (mock *ThreeScaleInterfaceMock) ActivateUser(accessToken string, accountId
 	mock.lockActivateUser.Lock()
 	mock.calls.ActivateUser = append(mock.calls.ActivateUser, callInfo)
 	mock.lockActivateUser.Unlock()
-	return mock.ActivateUserFunc(accessToken, accountId, userId)
+	return mock.ActivateUserFunc(ctx, accessToken, accountId, userId)
 }
 
 // ActivateUserCalls gets all the calls that were made to ActivateUser.
 // Check the length with:
 //     len(mockedThreeScaleInterface.ActivateUserCalls())
 func (mock *ThreeScaleInterfaceMock) ActivateUserCalls() []struct {
+	Ctx         context.Context
 	AccessToken string
 	AccountId   int
 	UserId      int
 } {
 	var calls []struct {
+		Ctx         context.Context
 		AccessToken string
 		AccountId   int
 		UserId      int
@@ -578,15 +869,17 @@ func (mock *ThreeScaleInterfaceMock) ActivateUserCalls() []struct {
 }
 
 // AddAuthProviderToAccount calls AddAuthProviderToAccountFunc.
-func (mock *ThreeScaleInterfaceMock) AddAuthProviderToAccount(accessToken string, account AccountDetail, authProviderDetail AuthProviderDetails) error {
+func (mock *ThreeScaleInterfaceMock) AddAuthProviderToAccount(ctx context.Context, accessToken string, account AccountDetail, authProviderDetail AuthProviderDetails) error {
 	if mock.AddAuthProviderToAccountFunc == nil {
 		panic("ThreeScaleInterfaceMock.AddAuthProviderToAccountFunc: method is nil but ThreeScaleInterface.AddAuthProviderToAccount was just called")
 	}
 	callInfo := struct {
+		Ctx                context.Context
 		AccessToken        string
 		Account            AccountDetail
 		AuthProviderDetail AuthProviderDetails
 	}{
+		Ctx:                ctx,
 		AccessToken:        accessToken,
 		Account:            account,
 		AuthProviderDetail: authProviderDetail,
@@ -594,18 +887,20 @@ func (mock *ThreeScaleInterfaceMock) AddAuthProviderToAccount(accessToken string
 	mock.lockAddAuthProviderToAccount.Lock()
 	mock.calls.AddAuthProviderToAccount = append(mock.calls.AddAuthProviderToAccount, callInfo)
 	mock.lockAddAuthProviderToAccount.Unlock()
-	return mock.AddAuthProviderToAccountFunc(accessToken, account, authProviderDetail)
+	return mock.AddAuthProviderToAccountFunc(ctx, accessToken, account, authProviderDetail)
 }
 
 // AddAuthProviderToAccountCalls gets all the calls that were made to AddAuthProviderToAccount.
 // Check the length with:
 //     len(mockedThreeScaleInterface.AddAuthProviderToAccountCalls())
 func (mock *ThreeScaleInterfaceMock) AddAuthProviderToAccountCalls() []struct {
+	Ctx                context.Context
 	AccessToken        string
 	Account            AccountDetail
 	AuthProviderDetail AuthProviderDetails
 } {
 	var calls []struct {
+		Ctx                context.Context
 		AccessToken        string
 		Account            AccountDetail
 		AuthProviderDetail AuthProviderDetails
@@ -617,31 +912,35 @@ func (mock *ThreeScaleInterfaceMock) AddAuthProviderToAccountCalls() []struct {
 }
 
 // AddAuthenticationProvider calls AddAuthenticationProviderFunc.
-func (mock *ThreeScaleInterfaceMock) AddAuthenticationProvider(data map[string]string, accessToken string) (*http.Response, error) {
+func (mock *ThreeScaleInterfaceMock) AddAuthenticationProvider(ctx context.Context, data map[string]string, accessToken string) (*http.Response, error) {
 	if mock.AddAuthenticationProviderFunc == nil {
 		panic("ThreeScaleInterfaceMock.AddAuthenticationProviderFunc: method is nil but ThreeScaleInterface.AddAuthenticationProvider was just called")
 	}
 	callInfo := struct {
+		Ctx         context.Context
 		Data        map[string]string
 		AccessToken string
 	}{
+		Ctx:         ctx,
 		Data:        data,
 		AccessToken: accessToken,
 	}
 	mock.lockAddAuthenticationProvider.Lock()
 	mock.calls.AddAuthenticationProvider = append(mock.calls.AddAuthenticationProvider, callInfo)
 	mock.lockAddAuthenticationProvider.Unlock()
-	return mock.AddAuthenticationProviderFunc(data, accessToken)
+	return mock.AddAuthenticationProviderFunc(ctx, data, accessToken)
 }
 
 // AddAuthenticationProviderCalls gets all the calls that were made to AddAuthenticationProvider.
 // Check the length with:
 //     len(mockedThreeScaleInterface.AddAuthenticationProviderCalls())
 func (mock *ThreeScaleInterfaceMock) AddAuthenticationProviderCalls() []struct {
+	Ctx         context.Context
 	Data        map[string]string
 	AccessToken string
 } {
 	var calls []struct {
+		Ctx         context.Context
 		Data        map[string]string
 		AccessToken string
 	}
@@ -652,16 +951,18 @@ func (mock *ThreeScaleInterfaceMock) AddAuthenticationProviderCalls() []struct {
 }
 
 // AddUser calls AddUserFunc.
-func (mock *ThreeScaleInterfaceMock) AddUser(username string, email string, password string, accessToken string) (*http.Response, error) {
+func (mock *ThreeScaleInterfaceMock) AddUser(ctx context.Context, username string, email string, password string, accessToken string) (*http.Response, error) {
 	if mock.AddUserFunc == nil {
 		panic("ThreeScaleInterfaceMock.AddUserFunc: method is nil but ThreeScaleInterface.AddUser was just called")
 	}
 	callInfo := struct {
+		Ctx         context.Context
 		Username    string
 		Email       string
 		Password    string
 		AccessToken string
 	}{
+		Ctx:         ctx,
 		Username:    username,
 		Email:       email,
 		Password:    password,
@@ -670,19 +971,21 @@ func (mock *ThreeScaleInterfaceMock) AddUser(username string, email string, pass
 	mock.lockAddUser.Lock()
 	mock.calls.AddUser = append(mock.calls.AddUser, callInfo)
 	mock.lockAddUser.Unlock()
-	return mock.AddUserFunc(username, email, password, accessToken)
+	return mock.AddUserFunc(ctx, username, email, password, accessToken)
 }
 
 // AddUserCalls gets all the calls that were made to AddUser.
 // Check the length with:
 //     len(mockedThreeScaleInterface.AddUserCalls())
 func (mock *ThreeScaleInterfaceMock) AddUserCalls() []struct {
+	Ctx         context.Context
 	Username    string
 	Email       string
 	Password    string
 	AccessToken string
 } {
 	var calls []struct {
+		Ctx         context.Context
 		Username    string
 		Email       string
 		Password    string
@@ -695,15 +998,17 @@ func (mock *ThreeScaleInterfaceMock) AddUserCalls() []struct {
 }
 
 // CreateAccount calls CreateAccountFunc.
-func (mock *ThreeScaleInterfaceMock) CreateAccount(accessToken string, orgName string, username string) (string, error) {
+func (mock *ThreeScaleInterfaceMock) CreateAccount(ctx context.Context, accessToken string, orgName string, username string) (string, error) {
 	if mock.CreateAccountFunc == nil {
 		panic("ThreeScaleInterfaceMock.CreateAccountFunc: method is nil but ThreeScaleInterface.CreateAccount was just called")
 	}
 	callInfo := struct {
+		Ctx         context.Context
 		AccessToken string
 		OrgName     string
 		Username    string
 	}{
+		Ctx:         ctx,
 		AccessToken: accessToken,
 		OrgName:     orgName,
 		Username:    username,
@@ -711,18 +1016,20 @@ func (mock *ThreeScaleInterfaceMock) CreateAccount(accessToken string, orgName s
 	mock.lockCreateAccount.Lock()
 	mock.calls.CreateAccount = append(mock.calls.CreateAccount, callInfo)
 	mock.lockCreateAccount.Unlock()
-	return mock.CreateAccountFunc(accessToken, orgName, username)
+	return mock.CreateAccountFunc(ctx, accessToken, orgName, username)
 }
 
 // CreateAccountCalls gets all the calls that were made to CreateAccount.
 // Check the length with:
 //     len(mockedThreeScaleInterface.CreateAccountCalls())
 func (mock *ThreeScaleInterfaceMock) CreateAccountCalls() []struct {
+	Ctx         context.Context
 	AccessToken string
 	OrgName     string
 	Username    string
 } {
 	var calls []struct {
+		Ctx         context.Context
 		AccessToken string
 		OrgName     string
 		Username    string
@@ -734,17 +1041,19 @@ func (mock *ThreeScaleInterfaceMock) CreateAccountCalls() []struct {
 }
 
 // CreateApplication calls CreateApplicationFunc.
-func (mock *ThreeScaleInterfaceMock) CreateApplication(accessToken string, accountID string, planID string, name string, description string) (string, error) {
+func (mock *ThreeScaleInterfaceMock) CreateApplication(ctx context.Context, accessToken string, accountID string, planID string, name string, description string) (string, error) {
 	if mock.CreateApplicationFunc == nil {
 		panic("ThreeScaleInterfaceMock.CreateApplicationFunc: method is nil but ThreeScaleInterface.CreateApplication was just called")
 	}
 	callInfo := struct {
+		Ctx         context.Context
 		AccessToken string
 		AccountID   string
 		PlanID      string
 		Name        string
 		Description string
 	}{
+		Ctx:         ctx,
 		AccessToken: accessToken,
 		AccountID:   accountID,
 		PlanID:      planID,
@@ -754,13 +1063,14 @@ func (mock *ThreeScaleInterfaceMock) CreateApplication(accessToken string, accou
 	mock.lockCreateApplication.Lock()
 	mock.calls.CreateApplication = append(mock.calls.CreateApplication, callInfo)
 	mock.lockCreateApplication.Unlock()
-	return mock.CreateApplicationFunc(accessToken, accountID, planID, name, description)
+	return mock.CreateApplicationFunc(ctx, accessToken, accountID, planID, name, description)
 }
 
 // CreateApplicationCalls gets all the calls that were made to CreateApplication.
 // Check the length with:
 //     len(mockedThreeScaleInterface.CreateApplicationCalls())
 func (mock *ThreeScaleInterfaceMock) CreateApplicationCalls() []struct {
+	Ctx         context.Context
 	AccessToken string
 	AccountID   string
 	PlanID      string
@@ -768,6 +1078,7 @@ func (mock *ThreeScaleInterfaceMock) CreateApplicationCalls() []struct {
 	Description string
 } {
 	var calls []struct {
+		Ctx         context.Context
 		AccessToken string
 		AccountID   string
 		PlanID      string
@@ -781,15 +1092,17 @@ func (mock *ThreeScaleInterfaceMock) CreateApplicationCalls() []struct {
 }
 
 // CreateApplicationPlan calls CreateApplicationPlanFunc.
-func (mock *ThreeScaleInterfaceMock) CreateApplicationPlan(accessToken string, serviceID string, name string) (string, error) {
+func (mock *ThreeScaleInterfaceMock) CreateApplicationPlan(ctx context.Context, accessToken string, serviceID string, name string) (string, error) {
 	if mock.CreateApplicationPlanFunc == nil {
 		panic("ThreeScaleInterfaceMock.CreateApplicationPlanFunc: method is nil but ThreeScaleInterface.CreateApplicationPlan was just called")
 	}
 	callInfo := struct {
+		Ctx         context.Context
 		AccessToken string
 		ServiceID   string
 		Name        string
 	}{
+		Ctx:         ctx,
 		AccessToken: accessToken,
 		ServiceID:   serviceID,
 		Name:        name,
@@ -797,18 +1110,20 @@ func (mock *ThreeScaleInterfaceMock) CreateApplicationPlan(accessToken string, s
 	mock.lockCreateApplicationPlan.Lock()
 	mock.calls.CreateApplicationPlan = append(mock.calls.CreateApplicationPlan, callInfo)
 	mock.lockCreateApplicationPlan.Unlock()
-	return mock.CreateApplicationPlanFunc(accessToken, serviceID, name)
+	return mock.CreateApplicationPlanFunc(ctx, accessToken, serviceID, name)
 }
 
 // CreateApplicationPlanCalls gets all the calls that were made to CreateApplicationPlan.
 // Check the length with:
 //     len(mockedThreeScaleInterface.CreateApplicationPlanCalls())
 func (mock *ThreeScaleInterfaceMock) CreateApplicationPlanCalls() []struct {
+	Ctx         context.Context
 	AccessToken string
 	ServiceID   string
 	Name        string
 } {
 	var calls []struct {
+		Ctx         context.Context
 		AccessToken string
 		ServiceID   string
 		Name        string
@@ -820,36 +1135,44 @@ func (mock *ThreeScaleInterfaceMock) CreateApplicationPlanCalls() []struct {
 }
 
 // CreateBackend calls CreateBackendFunc.
-func (mock *ThreeScaleInterfaceMock) CreateBackend(accessToken string, name string, privateEndpoint string) (int, error) {
+func (mock *ThreeScaleInterfaceMock) CreateBackend(ctx context.Context, accessToken string, name string, systemName string, privateEndpoint string) (int, error) {
 	if mock.CreateBackendFunc == nil {
 		panic("ThreeScaleInterfaceMock.CreateBackendFunc: method is nil but ThreeScaleInterface.CreateBackend was just called")
 	}
 	callInfo := struct {
+		Ctx             context.Context
 		AccessToken     string
 		Name            string
+		SystemName      string
 		PrivateEndpoint string
 	}{
+		Ctx:             ctx,
 		AccessToken:     accessToken,
 		Name:            name,
+		SystemName:      systemName,
 		PrivateEndpoint: privateEndpoint,
 	}
 	mock.lockCreateBackend.Lock()
 	mock.calls.CreateBackend = append(mock.calls.CreateBackend, callInfo)
 	mock.lockCreateBackend.Unlock()
-	return mock.CreateBackendFunc(accessToken, name, privateEndpoint)
+	return mock.CreateBackendFunc(ctx, accessToken, name, systemName, privateEndpoint)
 }
 
 // CreateBackendCalls gets all the calls that were made to CreateBackend.
 // Check the length with:
 //     len(mockedThreeScaleInterface.CreateBackendCalls())
 func (mock *ThreeScaleInterfaceMock) CreateBackendCalls() []struct {
+	Ctx             context.Context
 	AccessToken     string
 	Name            string
+	SystemName      string
 	PrivateEndpoint string
 } {
 	var calls []struct {
+		Ctx             context.Context
 		AccessToken     string
 		Name            string
+		SystemName      string
 		PrivateEndpoint string
 	}
 	mock.lockCreateBackend.RLock()
@@ -859,11 +1182,12 @@ func (mock *ThreeScaleInterfaceMock) CreateBackendCalls() []struct {
 }
 
 // CreateBackendMappingRule calls CreateBackendMappingRuleFunc.
-func (mock *ThreeScaleInterfaceMock) CreateBackendMappingRule(accessToken string, backendID int, metricID int, httpMethod string, pattern string, delta int) error {
+func (mock *ThreeScaleInterfaceMock) CreateBackendMappingRule(ctx context.Context, accessToken string, backendID int, metricID int, httpMethod string, pattern string, delta int) error {
 	if mock.CreateBackendMappingRuleFunc == nil {
 		panic("ThreeScaleInterfaceMock.CreateBackendMappingRuleFunc: method is nil but ThreeScaleInterface.CreateBackendMappingRule was just called")
 	}
 	callInfo := struct {
+		Ctx         context.Context
 		AccessToken string
 		BackendID   int
 		MetricID    int
@@ -871,6 +1195,7 @@ func (mock *ThreeScaleInterfaceMock) CreateBackendMappingRule(accessToken string
 		Pattern     string
 		Delta       int
 	}{
+		Ctx:         ctx,
 		AccessToken: accessToken,
 		BackendID:   backendID,
 		MetricID:    metricID,
@@ -881,13 +1206,14 @@ func (mock *ThreeScaleInterfaceMock) CreateBackendMappingRule(accessToken string
 	mock.lockCreateBackendMappingRule.Lock()
 	mock.calls.CreateBackendMappingRule = append(mock.calls.CreateBackendMappingRule, callInfo)
 	mock.lockCreateBackendMappingRule.Unlock()
-	return mock.CreateBackendMappingRuleFunc(accessToken, backendID, metricID, httpMethod, pattern, delta)
+	return mock.CreateBackendMappingRuleFunc(ctx, accessToken, backendID, metricID, httpMethod, pattern, delta)
 }
 
 // CreateBackendMappingRuleCalls gets all the calls that were made to CreateBackendMappingRule.
 // Check the length with:
 //     len(mockedThreeScaleInterface.CreateBackendMappingRuleCalls())
 func (mock *ThreeScaleInterfaceMock) CreateBackendMappingRuleCalls() []struct {
+	Ctx         context.Context
 	AccessToken string
 	BackendID   int
 	MetricID    int
@@ -896,6 +1222,7 @@ func (mock *ThreeScaleInterfaceMock) CreateBackendMappingRuleCalls() []struct {
 	Delta       int
 } {
 	var calls []struct {
+		Ctx         context.Context
 		AccessToken string
 		BackendID   int
 		MetricID    int
@@ -910,16 +1237,18 @@ func (mock *ThreeScaleInterfaceMock) CreateBackendMappingRuleCalls() []struct {
 }
 
 // CreateBackendUsage calls CreateBackendUsageFunc.
-func (mock *ThreeScaleInterfaceMock) CreateBackendUsage(accessToken string, serviceID string, backendID int, path string) error {
+func (mock *ThreeScaleInterfaceMock) CreateBackendUsage(ctx context.Context, accessToken string, serviceID string, backendID int, path string) error {
 	if mock.CreateBackendUsageFunc == nil {
 		panic("ThreeScaleInterfaceMock.CreateBackendUsageFunc: method is nil but ThreeScaleInterface.CreateBackendUsage was just called")
 	}
 	callInfo := struct {
+		Ctx         context.Context
 		AccessToken string
 		ServiceID   string
 		BackendID   int
 		Path        string
 	}{
+		Ctx:         ctx,
 		AccessToken: accessToken,
 		ServiceID:   serviceID,
 		BackendID:   backendID,
@@ -928,19 +1257,21 @@ func (mock *ThreeScaleInterfaceMock) CreateBackendUsage(accessToken string, serv
 	mock.lockCreateBackendUsage.Lock()
 	mock.calls.CreateBackendUsage = append(mock.calls.CreateBackendUsage, callInfo)
 	mock.lockCreateBackendUsage.Unlock()
-	return mock.CreateBackendUsageFunc(accessToken, serviceID, backendID, path)
+	return mock.CreateBackendUsageFunc(ctx, accessToken, serviceID, backendID, path)
 }
 
 // CreateBackendUsageCalls gets all the calls that were made to CreateBackendUsage.
 // Check the length with:
 //     len(mockedThreeScaleInterface.CreateBackendUsageCalls())
 func (mock *ThreeScaleInterfaceMock) CreateBackendUsageCalls() []struct {
+	Ctx         context.Context
 	AccessToken string
 	ServiceID   string
 	BackendID   int
 	Path        string
 } {
 	var calls []struct {
+		Ctx         context.Context
 		AccessToken string
 		ServiceID   string
 		BackendID   int
@@ -953,16 +1284,18 @@ func (mock *ThreeScaleInterfaceMock) CreateBackendUsageCalls() []struct {
 }
 
 // CreateMetric calls CreateMetricFunc.
-func (mock *ThreeScaleInterfaceMock) CreateMetric(accessToken string, backendID int, friendlyName string, unit string) (int, error) {
+func (mock *ThreeScaleInterfaceMock) CreateMetric(ctx context.Context, accessToken string, backendID int, friendlyName string, unit string) (int, error) {
 	if mock.CreateMetricFunc == nil {
 		panic("ThreeScaleInterfaceMock.CreateMetricFunc: method is nil but ThreeScaleInterface.CreateMetric was just called")
 	}
 	callInfo := struct {
+		Ctx          context.Context
 		AccessToken  string
 		BackendID    int
 		FriendlyName string
 		Unit         string
 	}{
+		Ctx:          ctx,
 		AccessToken:  accessToken,
 		BackendID:    backendID,
 		FriendlyName: friendlyName,
@@ -971,19 +1304,21 @@ func (mock *ThreeScaleInterfaceMock) CreateMetric(accessToken string, backendID
 	mock.lockCreateMetric.Lock()
 	mock.calls.CreateMetric = append(mock.calls.CreateMetric, callInfo)
 	mock.lockCreateMetric.Unlock()
-	return mock.CreateMetricFunc(accessToken, backendID, friendlyName, unit)
+	return mock.CreateMetricFunc(ctx, accessToken, backendID, friendlyName, unit)
 }
 
 // CreateMetricCalls gets all the calls that were made to CreateMetric.
 // Check the length with:
 //     len(mockedThreeScaleInterface.CreateMetricCalls())
 func (mock *ThreeScaleInterfaceMock) CreateMetricCalls() []struct {
+	Ctx          context.Context
 	AccessToken  string
 	BackendID    int
 	FriendlyName string
 	Unit         string
 } {
 	var calls []struct {
+		Ctx          context.Context
 		AccessToken  string
 		BackendID    int
 		FriendlyName string
@@ -996,15 +1331,17 @@ func (mock *ThreeScaleInterfaceMock) CreateMetricCalls() []struct {
 }
 
 // CreateService calls CreateServiceFunc.
-func (mock *ThreeScaleInterfaceMock) CreateService(accessToken string, name string, systemName string) (string, error) {
+func (mock *ThreeScaleInterfaceMock) CreateService(ctx context.Context, accessToken string, name string, systemName string) (string, error) {
 	if mock.CreateServiceFunc == nil {
 		panic("ThreeScaleInterfaceMock.CreateServiceFunc: method is nil but ThreeScaleInterface.CreateService was just called")
 	}
 	callInfo := struct {
+		Ctx         context.Context
 		AccessToken string
 		Name        string
 		SystemName  string
 	}{
+		Ctx:         ctx,
 		AccessToken: accessToken,
 		Name:        name,
 		SystemName:  systemName,
@@ -1012,18 +1349,20 @@ func (mock *ThreeScaleInterfaceMock) CreateService(accessToken string, name stri
 	mock.lockCreateService.Lock()
 	mock.calls.CreateService = append(mock.calls.CreateService, callInfo)
 	mock.lockCreateService.Unlock()
-	return mock.CreateServiceFunc(accessToken, name, systemName)
+	return mock.CreateServiceFunc(ctx, accessToken, name, systemName)
 }
 
 // CreateServiceCalls gets all the calls that were made to CreateService.
 // Check the length with:
 //     len(mockedThreeScaleInterface.CreateServiceCalls())
 func (mock *ThreeScaleInterfaceMock) CreateServiceCalls() []struct {
+	Ctx         context.Context
 	AccessToken string
 	Name        string
 	SystemName  string
 } {
 	var calls []struct {
+		Ctx         context.Context
 		AccessToken string
 		Name        string
 		SystemName  string
@@ -1035,16 +1374,18 @@ func (mock *ThreeScaleInterfaceMock) CreateServiceCalls() []struct {
 }
 
 // CreateTenant calls CreateTenantFunc.
-func (mock *ThreeScaleInterfaceMock) CreateTenant(accessToken string, account AccountDetail, password string, email string) (*SignUpAccount, error) {
+func (mock *ThreeScaleInterfaceMock) CreateTenant(ctx context.Context, accessToken string, account AccountDetail, password string, email string) (*SignUpAccount, error) {
 	if mock.CreateTenantFunc == nil {
 		panic("ThreeScaleInterfaceMock.CreateTenantFunc: method is nil but ThreeScaleInterface.CreateTenant was just called")
 	}
 	callInfo := struct {
+		Ctx         context.Context
 		AccessToken string
 		Account     AccountDetail
 		Password    string
 		Email       string
 	}{
+		Ctx:         ctx,
 		AccessToken: accessToken,
 		Account:     account,
 		Password:    password,
@@ -1053,19 +1394,21 @@ func (mock *ThreeScaleInterfaceMock) CreateTenant(accessToken string, account Ac
 	mock.lockCreateTenant.Lock()
 	mock.calls.CreateTenant = append(mock.calls.CreateTenant, callInfo)
 	mock.lockCreateTenant.Unlock()
-	return mock.CreateTenantFunc(accessToken, account, password, email)
+	return mock.CreateTenantFunc(ctx, accessToken, account, password, email)
 }
 
 // CreateTenantCalls gets all the calls that were made to CreateTenant.
 // Check the length with:
 //     len(mockedThreeScaleInterface.CreateTenantCalls())
 func (mock *ThreeScaleInterfaceMock) CreateTenantCalls() []struct {
+	Ctx         context.Context
 	AccessToken string
 	Account     AccountDetail
 	Password    string
 	Email       string
 } {
 	var calls []struct {
+		Ctx         context.Context
 		AccessToken string
 		Account     AccountDetail
 		Password    string
@@ -1078,31 +1421,35 @@ func (mock *ThreeScaleInterfaceMock) CreateTenantCalls() []struct {
 }
 
 // DeleteAccount calls DeleteAccountFunc.
-func (mock *ThreeScaleInterfaceMock) DeleteAccount(accessToken string, accountID string) error {
+func (mock *ThreeScaleInterfaceMock) DeleteAccount(ctx context.Context, accessToken string, accountID string) error {
 	if mock.DeleteAccountFunc == nil {
 		panic("ThreeScaleInterfaceMock.DeleteAccountFunc: method is nil but ThreeScaleInterface.DeleteAccount was just called")
 	}
 	callInfo := struct {
+		Ctx         context.Context
 		AccessToken string
 		AccountID   string
 	}{
+		Ctx:         ctx,
 		AccessToken: accessToken,
 		AccountID:   accountID,
 	}
 	mock.lockDeleteAccount.Lock()
 	mock.calls.DeleteAccount = append(mock.calls.DeleteAccount, callInfo)
 	mock.lockDeleteAccount.Unlock()
-	return mock.DeleteAccountFunc(accessToken, accountID)
+	return mock.DeleteAccountFunc(ctx, accessToken, accountID)
 }
 
 // DeleteAccountCalls gets all the calls that were made to DeleteAccount.
 // Check the length with:
 //     len(mockedThreeScaleInterface.DeleteAccountCalls())
 func (mock *ThreeScaleInterfaceMock) DeleteAccountCalls() []struct {
+	Ctx         context.Context
 	AccessToken string
 	AccountID   string
 } {
 	var calls []struct {
+		Ctx         context.Context
 		AccessToken string
 		AccountID   string
 	}
@@ -1112,32 +1459,79 @@ func (mock *ThreeScaleInterfaceMock) DeleteAccountCalls() []struct {
 	return calls
 }
 
+// DeleteApplicationPlan calls DeleteApplicationPlanFunc.
+func (mock *ThreeScaleInterfaceMock) DeleteApplicationPlan(ctx context.Context, accessToken string, serviceID string, planID int) error {
+	if mock.DeleteApplicationPlanFunc == nil {
+		panic("ThreeScaleInterfaceMock.DeleteApplicationPlanFunc: method is nil but ThreeScaleInterface.DeleteApplicationPlan was just called")
+	}
+	callInfo := struct {
+		Ctx         context.Context
+		AccessToken string
+		ServiceID   string
+		PlanID      int
+	}{
+		Ctx:         ctx,
+		AccessToken: accessToken,
+		ServiceID:   serviceID,
+		PlanID:      planID,
+	}
+	mock.lockDeleteApplicationPlan.Lock()
+	mock.calls.DeleteApplicationPlan = append(mock.calls.DeleteApplicationPlan, callInfo)
+	mock.lockDeleteApplicationPlan.Unlock()
+	return mock.DeleteApplicationPlanFunc(ctx, accessToken, serviceID, planID)
+}
+
+// DeleteApplicationPlanCalls gets all the calls that were made to DeleteApplicationPlan.
+// Check the length with:
+//     len(mockedThreeScaleInterface.DeleteApplicationPlanCalls())
+func (mock *ThreeScaleInterfaceMock) DeleteApplicationPlanCalls() []struct {
+	Ctx         context.Context
+	AccessToken string
+	ServiceID   string
+	PlanID      int
+} {
+	var calls []struct {
+		Ctx         context.Context
+		AccessToken string
+		ServiceID   string
+		PlanID      int
+	}
+	mock.lockDeleteApplicationPlan.RLock()
+	calls = mock.calls.DeleteApplicationPlan
+	mock.lockDeleteApplicationPlan.RUnlock()
+	return calls
+}
+
 // DeleteBackend calls DeleteBackendFunc.
-func (mock *ThreeScaleInterfaceMock) DeleteBackend(accessToken string, backendID int) error {
+func (mock *ThreeScaleInterfaceMock) DeleteBackend(ctx context.Context, accessToken string, backendID int) error {
 	if mock.DeleteBackendFunc == nil {
 		panic("ThreeScaleInterfaceMock.DeleteBackendFunc: method is nil but ThreeScaleInterface.DeleteBackend was just called")
 	}
 	callInfo := struct {
+		Ctx         context.Context
 		AccessToken string
 		BackendID   int
 	}{
+		Ctx:         ctx,
 		AccessToken: accessToken,
 		BackendID:   backendID,
 	}
 	mock.lockDeleteBackend.Lock()
 	mock.calls.DeleteBackend = append(mock.calls.DeleteBackend, callInfo)
 	mock.lockDeleteBackend.Unlock()
-	return mock.DeleteBackendFunc(accessToken, backendID)
+	return mock.DeleteBackendFunc(ctx, accessToken, backendID)
 }
 
 // DeleteBackendCalls gets all the calls that were made to DeleteBackend.
 // Check the length with:
 //     len(mockedThreeScaleInterface.DeleteBackendCalls())
 func (mock *ThreeScaleInterfaceMock) DeleteBackendCalls() []struct {
+	Ctx         context.Context
 	AccessToken string
 	BackendID   int
 } {
 	var calls []struct {
+		Ctx         context.Context
 		AccessToken string
 		BackendID   int
 	}
@@ -1147,32 +1541,79 @@ func (mock *ThreeScaleInterfaceMock) DeleteBackendCalls() []struct {
 	return calls
 }
 
+// DeleteBackendMappingRule calls DeleteBackendMappingRuleFunc.
+func (mock *ThreeScaleInterfaceMock) DeleteBackendMappingRule(ctx context.Context, accessToken string, backendID int, mappingRuleID int) error {
+	if mock.DeleteBackendMappingRuleFunc == nil {
+		panic("ThreeScaleInterfaceMock.DeleteBackendMappingRuleFunc: method is nil but ThreeScaleInterface.DeleteBackendMappingRule was just called")
+	}
+	callInfo := struct {
+		Ctx           context.Context
+		AccessToken   string
+		BackendID     int
+		MappingRuleID int
+	}{
+		Ctx:           ctx,
+		AccessToken:   accessToken,
+		BackendID:     backendID,
+		MappingRuleID: mappingRuleID,
+	}
+	mock.lockDeleteBackendMappingRule.Lock()
+	mock.calls.DeleteBackendMappingRule = append(mock.calls.DeleteBackendMappingRule, callInfo)
+	mock.lockDeleteBackendMappingRule.Unlock()
+	return mock.DeleteBackendMappingRuleFunc(ctx, accessToken, backendID, mappingRuleID)
+}
+
+// DeleteBackendMappingRuleCalls gets all the calls that were made to DeleteBackendMappingRule.
+// Check the length with:
+//     len(mockedThreeScaleInterface.DeleteBackendMappingRuleCalls())
+func (mock *ThreeScaleInterfaceMock) DeleteBackendMappingRuleCalls() []struct {
+	Ctx           context.Context
+	AccessToken   string
+	BackendID     int
+	MappingRuleID int
+} {
+	var calls []struct {
+		Ctx           context.Context
+		AccessToken   string
+		BackendID     int
+		MappingRuleID int
+	}
+	mock.lockDeleteBackendMappingRule.RLock()
+	calls = mock.calls.DeleteBackendMappingRule
+	mock.lockDeleteBackendMappingRule.RUnlock()
+	return calls
+}
+
 // DeleteService calls DeleteServiceFunc.
-func (mock *ThreeScaleInterfaceMock) DeleteService(accessToken string, serviceID string) error {
+func (mock *ThreeScaleInterfaceMock) DeleteService(ctx context.Context, accessToken string, serviceID string) error {
 	if mock.DeleteServiceFunc == nil {
 		panic("ThreeScaleInterfaceMock.DeleteServiceFunc: method is nil but ThreeScaleInterface.DeleteService was just called")
 	}
 	callInfo := struct {
+		Ctx         context.Context
 		AccessToken string
 		ServiceID   string
 	}{
+		Ctx:         ctx,
 		AccessToken: accessToken,
 		ServiceID:   serviceID,
 	}
 	mock.lockDeleteService.Lock()
 	mock.calls.DeleteService = append(mock.calls.DeleteService, callInfo)
 	mock.lockDeleteService.Unlock()
-	return mock.DeleteServiceFunc(accessToken, serviceID)
+	return mock.DeleteServiceFunc(ctx, accessToken, serviceID)
 }
 
 // DeleteServiceCalls gets all the calls that were made to DeleteService.
 // Check the length with:
 //     len(mockedThreeScaleInterface.DeleteServiceCalls())
 func (mock *ThreeScaleInterfaceMock) DeleteServiceCalls() []struct {
+	Ctx         context.Context
 	AccessToken string
 	ServiceID   string
 } {
 	var calls []struct {
+		Ctx         context.Context
 		AccessToken string
 		ServiceID   string
 	}
@@ -1183,31 +1624,35 @@ func (mock *ThreeScaleInterfaceMock) DeleteServiceCalls() []struct {
 }
 
 // DeleteTenant calls DeleteTenantFunc.
-func (mock *ThreeScaleInterfaceMock) DeleteTenant(accessToken string, id int) error {
+func (mock *ThreeScaleInterfaceMock) DeleteTenant(ctx context.Context, accessToken string, id int) error {
 	if mock.DeleteTenantFunc == nil {
 		panic("ThreeScaleInterfaceMock.DeleteTenantFunc: method is nil but ThreeScaleInterface.DeleteTenant was just called")
 	}
 	callInfo := struct {
+		Ctx         context.Context
 		AccessToken string
 		ID          int
 	}{
+		Ctx:         ctx,
 		AccessToken: accessToken,
 		ID:          id,
 	}
 	mock.lockDeleteTenant.Lock()
 	mock.calls.DeleteTenant = append(mock.calls.DeleteTenant, callInfo)
 	mock.lockDeleteTenant.Unlock()
-	return mock.DeleteTenantFunc(accessToken, id)
+	return mock.DeleteTenantFunc(ctx, accessToken, id)
 }
 
 // DeleteTenantCalls gets all the calls that were made to DeleteTenant.
 // Check the length with:
 //     len(mockedThreeScaleInterface.DeleteTenantCalls())
 func (mock *ThreeScaleInterfaceMock) DeleteTenantCalls() []struct {
+	Ctx         context.Context
 	AccessToken string
 	ID          int
 } {
 	var calls []struct {
+		Ctx         context.Context
 		AccessToken string
 		ID          int
 	}
@@ -1218,31 +1663,35 @@ func (mock *ThreeScaleInterfaceMock) DeleteTenantCalls() []struct {
 }
 
 // DeleteTenants calls DeleteTenantsFunc.
-func (mock *ThreeScaleInterfaceMock) DeleteTenants(accessToken string, accounts []AccountDetail) error {
+func (mock *ThreeScaleInterfaceMock) DeleteTenants(ctx context.Context, accessToken string, accounts []AccountDetail) error {
 	if mock.DeleteTenantsFunc == nil {
 		panic("ThreeScaleInterfaceMock.DeleteTenantsFunc: method is nil but ThreeScaleInterface.DeleteTenants was just called")
 	}
 	callInfo := struct {
+		Ctx         context.Context
 		AccessToken string
 		Accounts    []AccountDetail
 	}{
+		Ctx:         ctx,
 		AccessToken: accessToken,
 		Accounts:    accounts,
 	}
 	mock.lockDeleteTenants.Lock()
 	mock.calls.DeleteTenants = append(mock.calls.DeleteTenants, callInfo)
 	mock.lockDeleteTenants.Unlock()
-	return mock.DeleteTenantsFunc(accessToken, accounts)
+	return mock.DeleteTenantsFunc(ctx, accessToken, accounts)
 }
 
 // DeleteTenantsCalls gets all the calls that were made to DeleteTenants.
 // Check the length with:
 //     len(mockedThreeScaleInterface.DeleteTenantsCalls())
 func (mock *ThreeScaleInterfaceMock) DeleteTenantsCalls() []struct {
+	Ctx         context.Context
 	AccessToken string
 	Accounts    []AccountDetail
 } {
 	var calls []struct {
+		Ctx         context.Context
 		AccessToken string
 		Accounts    []AccountDetail
 	}
@@ -1253,31 +1702,35 @@ func (mock *ThreeScaleInterfaceMock) DeleteTenantsCalls() []struct {
 }
 
 // DeleteUser calls DeleteUserFunc.
-func (mock *ThreeScaleInterfaceMock) DeleteUser(userID int, accessToken string) (*http.Response, error) {
+func (mock *ThreeScaleInterfaceMock) DeleteUser(ctx context.Context, userID int, accessToken string) (*http.Response, error) {
 	if mock.DeleteUserFunc == nil {
 		panic("ThreeScaleInterfaceMock.DeleteUserFunc: method is nil but ThreeScaleInterface.DeleteUser was just called")
 	}
 	callInfo := struct {
+		Ctx         context.Context
 		UserID      int
 		AccessToken string
 	}{
+		Ctx:         ctx,
 		UserID:      userID,
 		AccessToken: accessToken,
 	}
 	mock.lockDeleteUser.Lock()
 	mock.calls.DeleteUser = append(mock.calls.DeleteUser, callInfo)
 	mock.lockDeleteUser.Unlock()
-	return mock.DeleteUserFunc(userID, accessToken)
+	return mock.DeleteUserFunc(ctx, userID, accessToken)
 }
 
 // DeleteUserCalls gets all the calls that were made to DeleteUser.
 // Check the length with:
 //     len(mockedThreeScaleInterface.DeleteUserCalls())
 func (mock *ThreeScaleInterfaceMock) DeleteUserCalls() []struct {
+	Ctx         context.Context
 	UserID      int
 	AccessToken string
 } {
 	var calls []struct {
+		Ctx         context.Context
 		UserID      int
 		AccessToken string
 	}
@@ -1288,31 +1741,35 @@ func (mock *ThreeScaleInterfaceMock) DeleteUserCalls() []struct {
 }
 
 // DeployProxy calls DeployProxyFunc.
-func (mock *ThreeScaleInterfaceMock) DeployProxy(accessToken string, serviceID string) error {
+func (mock *ThreeScaleInterfaceMock) DeployProxy(ctx context.Context, accessToken string, serviceID string) error {
 	if mock.DeployProxyFunc == nil {
 		panic("ThreeScaleInterfaceMock.DeployProxyFunc: method is nil but ThreeScaleInterface.DeployProxy was just called")
 	}
 	callInfo := struct {
+		Ctx         context.Context
 		AccessToken string
 		ServiceID   string
 	}{
+		Ctx:         ctx,
 		AccessToken: accessToken,
 		ServiceID:   serviceID,
 	}
 	mock.lockDeployProxy.Lock()
 	mock.calls.DeployProxy = append(mock.calls.DeployProxy, callInfo)
 	mock.lockDeployProxy.Unlock()
-	return mock.DeployProxyFunc(accessToken, serviceID)
+	return mock.DeployProxyFunc(ctx, accessToken, serviceID)
 }
 
 // DeployProxyCalls gets all the calls that were made to DeployProxy.
 // Check the length with:
 //     len(mockedThreeScaleInterface.DeployProxyCalls())
 func (mock *ThreeScaleInterfaceMock) DeployProxyCalls() []struct {
+	Ctx         context.Context
 	AccessToken string
 	ServiceID   string
 } {
 	var calls []struct {
+		Ctx         context.Context
 		AccessToken string
 		ServiceID   string
 	}
@@ -1323,31 +1780,35 @@ func (mock *ThreeScaleInterfaceMock) DeployProxyCalls() []struct {
 }
 
 // GetAuthenticationProviderByName calls GetAuthenticationProviderByNameFunc.
-func (mock *ThreeScaleInterfaceMock) GetAuthenticationProviderByName(name string, accessToken string) (*AuthProvider, error) {
+func (mock *ThreeScaleInterfaceMock) GetAuthenticationProviderByName(ctx context.Context, name string, accessToken string) (*AuthProvider, error) {
 	if mock.GetAuthenticationProviderByNameFunc == nil {
 		panic("ThreeScaleInterfaceMock.GetAuthenticationProviderByNameFunc: method is nil but ThreeScaleInterface.GetAuthenticationProviderByName was just called")
 	}
 	callInfo := struct {
+		Ctx         context.Context
 		Name        string
 		AccessToken string
 	}{
+		Ctx:         ctx,
 		Name:        name,
 		AccessToken: accessToken,
 	}
 	mock.lockGetAuthenticationProviderByName.Lock()
 	mock.calls.GetAuthenticationProviderByName = append(mock.calls.GetAuthenticationProviderByName, callInfo)
 	mock.lockGetAuthenticationProviderByName.Unlock()
-	return mock.GetAuthenticationProviderByNameFunc(name, accessToken)
+	return mock.GetAuthenticationProviderByNameFunc(ctx, name, accessToken)
 }
 
 // GetAuthenticationProviderByNameCalls gets all the calls that were made to GetAuthenticationProviderByName.
 // Check the length with:
 //     len(mockedThreeScaleInterface.GetAuthenticationProviderByNameCalls())
 func (mock *ThreeScaleInterfaceMock) GetAuthenticationProviderByNameCalls() []struct {
+	Ctx         context.Context
 	Name        string
 	AccessToken string
 } {
 	var calls []struct {
+		Ctx         context.Context
 		Name        string
 		AccessToken string
 	}
@@ -1358,28 +1819,32 @@ func (mock *ThreeScaleInterfaceMock) GetAuthenticationProviderByNameCalls() []st
 }
 
 // GetAuthenticationProviders calls GetAuthenticationProvidersFunc.
-func (mock *ThreeScaleInterfaceMock) GetAuthenticationProviders(accessToken string) (*AuthProviders, error) {
+func (mock *ThreeScaleInterfaceMock) GetAuthenticationProviders(ctx context.Context, accessToken string) (*AuthProviders, error) {
 	if mock.GetAuthenticationProvidersFunc == nil {
 		panic("ThreeScaleInterfaceMock.GetAuthenticationProvidersFunc: method is nil but ThreeScaleInterface.GetAuthenticationProviders was just called")
 	}
 	callInfo := struct {
+		Ctx         context.Context
 		AccessToken string
 	}{
+		Ctx:         ctx,
 		AccessToken: accessToken,
 	}
 	mock.lockGetAuthenticationProviders.Lock()
 	mock.calls.GetAuthenticationProviders = append(mock.calls.GetAuthenticationProviders, callInfo)
 	mock.lockGetAuthenticationProviders.Unlock()
-	return mock.GetAuthenticationProvidersFunc(accessToken)
+	return mock.GetAuthenticationProvidersFunc(ctx, accessToken)
 }
 
 // GetAuthenticationProvidersCalls gets all the calls that were made to GetAuthenticationProviders.
 // Check the length with:
 //     len(mockedThreeScaleInterface.GetAuthenticationProvidersCalls())
 func (mock *ThreeScaleInterfaceMock) GetAuthenticationProvidersCalls() []struct {
+	Ctx         context.Context
 	AccessToken string
 } {
 	var calls []struct {
+		Ctx         context.Context
 		AccessToken string
 	}
 	mock.lockGetAuthenticationProviders.RLock()
@@ -1389,31 +1854,35 @@ func (mock *ThreeScaleInterfaceMock) GetAuthenticationProvidersCalls() []struct
 }
 
 // GetTenantAccount calls GetTenantAccountFunc.
-func (mock *ThreeScaleInterfaceMock) GetTenantAccount(accessToken string, id int) (*SignUpAccount, error) {
+func (mock *ThreeScaleInterfaceMock) GetTenantAccount(ctx context.Context, accessToken string, id int) (*SignUpAccount, error) {
 	if mock.GetTenantAccountFunc == nil {
 		panic("ThreeScaleInterfaceMock.GetTenantAccountFunc: method is nil but ThreeScaleInterface.GetTenantAccount was just called")
 	}
 	callInfo := struct {
+		Ctx         context.Context
 		AccessToken string
 		ID          int
 	}{
+		Ctx:         ctx,
 		AccessToken: accessToken,
 		ID:          id,
 	}
 	mock.lockGetTenantAccount.Lock()
 	mock.calls.GetTenantAccount = append(mock.calls.GetTenantAccount, callInfo)
 	mock.lockGetTenantAccount.Unlock()
-	return mock.GetTenantAccountFunc(accessToken, id)
+	return mock.GetTenantAccountFunc(ctx, accessToken, id)
 }
 
 // GetTenantAccountCalls gets all the calls that were made to GetTenantAccount.
 // Check the length with:
 //     len(mockedThreeScaleInterface.GetTenantAccountCalls())
 func (mock *ThreeScaleInterfaceMock) GetTenantAccountCalls() []struct {
+	Ctx         context.Context
 	AccessToken string
 	ID          int
 } {
 	var calls []struct {
+		Ctx         context.Context
 		AccessToken string
 		ID          int
 	}
@@ -1424,31 +1893,35 @@ func (mock *ThreeScaleInterfaceMock) GetTenantAccountCalls() []struct {
 }
 
 // GetUser calls GetUserFunc.
-func (mock *ThreeScaleInterfaceMock) GetUser(username string, accessToken string) (*User, error) {
+func (mock *ThreeScaleInterfaceMock) GetUser(ctx context.Context, username string, accessToken string) (*User, error) {
 	if mock.GetUserFunc == nil {
 		panic("ThreeScaleInterfaceMock.GetUserFunc: method is nil but ThreeScaleInterface.GetUser was just called")
 	}
 	callInfo := struct {
+		Ctx         context.Context
 		Username    string
 		AccessToken string
 	}{
+		Ctx:         ctx,
 		Username:    username,
 		AccessToken: accessToken,
 	}
 	mock.lockGetUser.Lock()
 	mock.calls.GetUser = append(mock.calls.GetUser, callInfo)
 	mock.lockGetUser.Unlock()
-	return mock.GetUserFunc(username, accessToken)
+	return mock.GetUserFunc(ctx, username, accessToken)
 }
 
 // GetUserCalls gets all the calls that were made to GetUser.
 // Check the length with:
 //     len(mockedThreeScaleInterface.GetUserCalls())
 func (mock *ThreeScaleInterfaceMock) GetUserCalls() []struct {
+	Ctx         context.Context
 	Username    string
 	AccessToken string
 } {
 	var calls []struct {
+		Ctx         context.Context
 		Username    string
 		AccessToken string
 	}
@@ -1459,28 +1932,32 @@ func (mock *ThreeScaleInterfaceMock) GetUserCalls() []struct {
 }
 
 // GetUsers calls GetUsersFunc.
-func (mock *ThreeScaleInterfaceMock) GetUsers(accessToken string) (*Users, error) {
+func (mock *ThreeScaleInterfaceMock) GetUsers(ctx context.Context, accessToken string) (*Users, error) {
 	if mock.GetUsersFunc == nil {
 		panic("ThreeScaleInterfaceMock.GetUsersFunc: method is nil but ThreeScaleInterface.GetUsers was just called")
 	}
 	callInfo := struct {
+		Ctx         context.Context
 		AccessToken string
 	}{
+		Ctx:         ctx,
 		AccessToken: accessToken,
 	}
 	mock.lockGetUsers.Lock()
 	mock.calls.GetUsers = append(mock.calls.GetUsers, callInfo)
 	mock.lockGetUsers.Unlock()
-	return mock.GetUsersFunc(accessToken)
+	return mock.GetUsersFunc(ctx, accessToken)
 }
 
 // GetUsersCalls gets all the calls that were made to GetUsers.
 // Check the length with:
 //     len(mockedThreeScaleInterface.GetUsersCalls())
 func (mock *ThreeScaleInterfaceMock) GetUsersCalls() []struct {
+	Ctx         context.Context
 	AccessToken string
 } {
 	var calls []struct {
+		Ctx         context.Context
 		AccessToken string
 	}
 	mock.lockGetUsers.RLock()
@@ -1490,15 +1967,17 @@ func (mock *ThreeScaleInterfaceMock) GetUsersCalls() []struct {
 }
 
 // IsAuthProviderAdded calls IsAuthProviderAddedFunc.
-func (mock *ThreeScaleInterfaceMock) IsAuthProviderAdded(accessToken string, authProviderName string, account AccountDetail) (bool, error) {
+func (mock *ThreeScaleInterfaceMock) IsAuthProviderAdded(ctx context.Context, accessToken string, authProviderName string, account AccountDetail) (bool, error) {
 	if mock.IsAuthProviderAddedFunc == nil {
 		panic("ThreeScaleInterfaceMock.IsAuthProviderAddedFunc: method is nil but ThreeScaleInterface.IsAuthProviderAdded was just called")
 	}
 	callInfo := struct {
+		Ctx              context.Context
 		AccessToken      string
 		AuthProviderName string
 		Account          AccountDetail
 	}{
+		Ctx:              ctx,
 		AccessToken:      accessToken,
 		AuthProviderName: authProviderName,
 		Account:          account,
@@ -1506,18 +1985,20 @@ func (mock *ThreeScaleInterfaceMock) IsAuthProviderAdded(accessToken string, aut
 	mock.lockIsAuthProviderAdded.Lock()
 	mock.calls.IsAuthProviderAdded = append(mock.calls.IsAuthProviderAdded, callInfo)
 	mock.lockIsAuthProviderAdded.Unlock()
-	return mock.IsAuthProviderAddedFunc(accessToken, authProviderName, account)
+	return mock.IsAuthProviderAddedFunc(ctx, accessToken, authProviderName, account)
 }
 
 // IsAuthProviderAddedCalls gets all the calls that were made to IsAuthProviderAdded.
 // Check the length with:
 //     len(mockedThreeScaleInterface.IsAuthProviderAddedCalls())
 func (mock *ThreeScaleInterfaceMock) IsAuthProviderAddedCalls() []struct {
+	Ctx              context.Context
 	AccessToken      string
 	AuthProviderName string
 	Account          AccountDetail
 } {
 	var calls []struct {
+		Ctx              context.Context
 		AccessToken      string
 		AuthProviderName string
 		Account          AccountDetail
@@ -1528,34 +2009,260 @@ func (mock *ThreeScaleInterfaceMock) IsAuthProviderAddedCalls() []struct {
 	return calls
 }
 
+// ListApplicationPlans calls ListApplicationPlansFunc.
+func (mock *ThreeScaleInterfaceMock) ListApplicationPlans(ctx context.Context, accessToken string, serviceID string) (*ApplicationPlans, error) {
+	if mock.ListApplicationPlansFunc == nil {
+		panic("ThreeScaleInterfaceMock.ListApplicationPlansFunc: method is nil but ThreeScaleInterface.ListApplicationPlans was just called")
+	}
+	callInfo := struct {
+		Ctx         context.Context
+		AccessToken string
+		ServiceID   string
+	}{
+		Ctx:         ctx,
+		AccessToken: accessToken,
+		ServiceID:   serviceID,
+	}
+	mock.lockListApplicationPlans.Lock()
+	mock.calls.ListApplicationPlans = append(mock.calls.ListApplicationPlans, callInfo)
+	mock.lockListApplicationPlans.Unlock()
+	return mock.ListApplicationPlansFunc(ctx, accessToken, serviceID)
+}
+
+// ListApplicationPlansCalls gets all the calls that were made to ListApplicationPlans.
+// Check the length with:
+//     len(mockedThreeScaleInterface.ListApplicationPlansCalls())
+func (mock *ThreeScaleInterfaceMock) ListApplicationPlansCalls() []struct {
+	Ctx         context.Context
+	AccessToken string
+	ServiceID   string
+} {
+	var calls []struct {
+		Ctx         context.Context
+		AccessToken string
+		ServiceID   string
+	}
+	mock.lockListApplicationPlans.RLock()
+	calls = mock.calls.ListApplicationPlans
+	mock.lockListApplicationPlans.RUnlock()
+	return calls
+}
+
+// ListBackendMappingRules calls ListBackendMappingRulesFunc.
+func (mock *ThreeScaleInterfaceMock) ListBackendMappingRules(ctx context.Context, accessToken string, backendID int) (*MappingRules, error) {
+	if mock.ListBackendMappingRulesFunc == nil {
+		panic("ThreeScaleInterfaceMock.ListBackendMappingRulesFunc: method is nil but ThreeScaleInterface.ListBackendMappingRules was just called")
+	}
+	callInfo := struct {
+		Ctx         context.Context
+		AccessToken string
+		BackendID   int
+	}{
+		Ctx:         ctx,
+		AccessToken: accessToken,
+		BackendID:   backendID,
+	}
+	mock.lockListBackendMappingRules.Lock()
+	mock.calls.ListBackendMappingRules = append(mock.calls.ListBackendMappingRules, callInfo)
+	mock.lockListBackendMappingRules.Unlock()
+	return mock.ListBackendMappingRulesFunc(ctx, accessToken, backendID)
+}
+
+// ListBackendMappingRulesCalls gets all the calls that were made to ListBackendMappingRules.
+// Check the length with:
+//     len(mockedThreeScaleInterface.ListBackendMappingRulesCalls())
+func (mock *ThreeScaleInterfaceMock) ListBackendMappingRulesCalls() []struct {
+	Ctx         context.Context
+	AccessToken string
+	BackendID   int
+} {
+	var calls []struct {
+		Ctx         context.Context
+		AccessToken string
+		BackendID   int
+	}
+	mock.lockListBackendMappingRules.RLock()
+	calls = mock.calls.ListBackendMappingRules
+	mock.lockListBackendMappingRules.RUnlock()
+	return calls
+}
+
+// ListBackendMetrics calls ListBackendMetricsFunc.
+func (mock *ThreeScaleInterfaceMock) ListBackendMetrics(ctx context.Context, accessToken string, backendID int) (*Metrics, error) {
+	if mock.ListBackendMetricsFunc == nil {
+		panic("ThreeScaleInterfaceMock.ListBackendMetricsFunc: method is nil but ThreeScaleInterface.ListBackendMetrics was just called")
+	}
+	callInfo := struct {
+		Ctx         context.Context
+		AccessToken string
+		BackendID   int
+	}{
+		Ctx:         ctx,
+		AccessToken: accessToken,
+		BackendID:   backendID,
+	}
+	mock.lockListBackendMetrics.Lock()
+	mock.calls.ListBackendMetrics = append(mock.calls.ListBackendMetrics, callInfo)
+	mock.lockListBackendMetrics.Unlock()
+	return mock.ListBackendMetricsFunc(ctx, accessToken, backendID)
+}
+
+// ListBackendMetricsCalls gets all the calls that were made to ListBackendMetrics.
+// Check the length with:
+//     len(mockedThreeScaleInterface.ListBackendMetricsCalls())
+func (mock *ThreeScaleInterfaceMock) ListBackendMetricsCalls() []struct {
+	Ctx         context.Context
+	AccessToken string
+	BackendID   int
+} {
+	var calls []struct {
+		Ctx         context.Context
+		AccessToken string
+		BackendID   int
+	}
+	mock.lockListBackendMetrics.RLock()
+	calls = mock.calls.ListBackendMetrics
+	mock.lockListBackendMetrics.RUnlock()
+	return calls
+}
+
+// ListBackendUsages calls ListBackendUsagesFunc.
+func (mock *ThreeScaleInterfaceMock) ListBackendUsages(ctx context.Context, accessToken string, serviceID string) ([]*BackendUsage, error) {
+	if mock.ListBackendUsagesFunc == nil {
+		panic("ThreeScaleInterfaceMock.ListBackendUsagesFunc: method is nil but ThreeScaleInterface.ListBackendUsages was just called")
+	}
+	callInfo := struct {
+		Ctx         context.Context
+		AccessToken string
+		ServiceID   string
+	}{
+		Ctx:         ctx,
+		AccessToken: accessToken,
+		ServiceID:   serviceID,
+	}
+	mock.lockListBackendUsages.Lock()
+	mock.calls.ListBackendUsages = append(mock.calls.ListBackendUsages, callInfo)
+	mock.lockListBackendUsages.Unlock()
+	return mock.ListBackendUsagesFunc(ctx, accessToken, serviceID)
+}
+
+// ListBackendUsagesCalls gets all the calls that were made to ListBackendUsages.
+// Check the length with:
+//     len(mockedThreeScaleInterface.ListBackendUsagesCalls())
+func (mock *ThreeScaleInterfaceMock) ListBackendUsagesCalls() []struct {
+	Ctx         context.Context
+	AccessToken string
+	ServiceID   string
+} {
+	var calls []struct {
+		Ctx         context.Context
+		AccessToken string
+		ServiceID   string
+	}
+	mock.lockListBackendUsages.RLock()
+	calls = mock.calls.ListBackendUsages
+	mock.lockListBackendUsages.RUnlock()
+	return calls
+}
+
+// ListBackends calls ListBackendsFunc.
+func (mock *ThreeScaleInterfaceMock) ListBackends(ctx context.Context, accessToken string) (*Backends, error) {
+	if mock.ListBackendsFunc == nil {
+		panic("ThreeScaleInterfaceMock.ListBackendsFunc: method is nil but ThreeScaleInterface.ListBackends was just called")
+	}
+	callInfo := struct {
+		Ctx         context.Context
+		AccessToken string
+	}{
+		Ctx:         ctx,
+		AccessToken: accessToken,
+	}
+	mock.lockListBackends.Lock()
+	mock.calls.ListBackends = append(mock.calls.ListBackends, callInfo)
+	mock.lockListBackends.Unlock()
+	return mock.ListBackendsFunc(ctx, accessToken)
+}
+
+// ListBackendsCalls gets all the calls that were made to ListBackends.
+// Check the length with:
+//     len(mockedThreeScaleInterface.ListBackendsCalls())
+func (mock *ThreeScaleInterfaceMock) ListBackendsCalls() []struct {
+	Ctx         context.Context
+	AccessToken string
+} {
+	var calls []struct {
+		Ctx         context.Context
+		AccessToken string
+	}
+	mock.lockListBackends.RLock()
+	calls = mock.calls.ListBackends
+	mock.lockListBackends.RUnlock()
+	return calls
+}
+
+// ListServices calls ListServicesFunc.
+func (mock *ThreeScaleInterfaceMock) ListServices(ctx context.Context, accessToken string) (*Services, error) {
+	if mock.ListServicesFunc == nil {
+		panic("ThreeScaleInterfaceMock.ListServicesFunc: method is nil but ThreeScaleInterface.ListServices was just called")
+	}
+	callInfo := struct {
+		Ctx         context.Context
+		AccessToken string
+	}{
+		Ctx:         ctx,
+		AccessToken: accessToken,
+	}
+	mock.lockListServices.Lock()
+	mock.calls.ListServices = append(mock.calls.ListServices, callInfo)
+	mock.lockListServices.Unlock()
+	return mock.ListServicesFunc(ctx, accessToken)
+}
+
+// ListServicesCalls gets all the calls that were made to ListServices.
+// Check the length with:
+//     len(mockedThreeScaleInterface.ListServicesCalls())
+func (mock *ThreeScaleInterfaceMock) ListServicesCalls() []struct {
+	Ctx         context.Context
+	AccessToken string
+} {
+	var calls []struct {
+		Ctx         context.Context
+		AccessToken string
+	}
+	mock.lockListServices.RLock()
+	calls = mock.calls.ListServices
+	mock.lockListServices.RUnlock()
+	return calls
+}
+
 // ListTenantAccounts calls ListTenantAccountsFunc.
-func (mock *ThreeScaleInterfaceMock) ListTenantAccounts(accessToken string, page int) ([]AccountDetail, error) {
+func (mock *ThreeScaleInterfaceMock) ListTenantAccounts(ctx context.Context, accessToken string) ([]AccountDetail, error) {
 	if mock.ListTenantAccountsFunc == nil {
 		panic("ThreeScaleInterfaceMock.ListTenantAccountsFunc: method is nil but ThreeScaleInterface.ListTenantAccounts was just called")
 	}
 	callInfo := struct {
+		Ctx         context.Context
 		AccessToken string
-		Page        int
 	}{
+		Ctx:         ctx,
 		AccessToken: accessToken,
-		Page:        page,
 	}
 	mock.lockListTenantAccounts.Lock()
 	mock.calls.ListTenantAccounts = append(mock.calls.ListTenantAccounts, callInfo)
 	mock.lockListTenantAccounts.Unlock()
-	return mock.ListTenantAccountsFunc(accessToken, page)
+	return mock.ListTenantAccountsFunc(ctx, accessToken)
 }
 
 // ListTenantAccountsCalls gets all the calls that were made to ListTenantAccounts.
 // Check the length with:
 //     len(mockedThreeScaleInterface.ListTenantAccountsCalls())
 func (mock *ThreeScaleInterfaceMock) ListTenantAccountsCalls() []struct {
+	Ctx         context.Context
 	AccessToken string
-	Page        int
 } {
 	var calls []struct {
+		Ctx         context.Context
 		AccessToken string
-		Page        int
 	}
 	mock.lockListTenantAccounts.RLock()
 	calls = mock.calls.ListTenantAccounts
@@ -1564,16 +2271,18 @@ func (mock *ThreeScaleInterfaceMock) ListTenantAccountsCalls() []struct {
 }
 
 // PromoteProxy calls PromoteProxyFunc.
-func (mock *ThreeScaleInterfaceMock) PromoteProxy(accessToken string, serviceID string, env string, to string) (string, error) {
+func (mock *ThreeScaleInterfaceMock) PromoteProxy(ctx context.Context, accessToken string, serviceID string, env string, to string) (string, error) {
 	if mock.PromoteProxyFunc == nil {
 		panic("ThreeScaleInterfaceMock.PromoteProxyFunc: method is nil but ThreeScaleInterface.PromoteProxy was just called")
 	}
 	callInfo := struct {
+		Ctx         context.Context
 		AccessToken string
 		ServiceID   string
 		Env         string
 		To          string
 	}{
+		Ctx:         ctx,
 		AccessToken: accessToken,
 		ServiceID:   serviceID,
 		Env:         env,
@@ -1582,19 +2291,21 @@ func (mock *ThreeScaleInterfaceMock) PromoteProxy(accessToken string, serviceID
 	mock.lockPromoteProxy.Lock()
 	mock.calls.PromoteProxy = append(mock.calls.PromoteProxy, callInfo)
 	mock.lockPromoteProxy.Unlock()
-	return mock.PromoteProxyFunc(accessToken, serviceID, env, to)
+	return mock.PromoteProxyFunc(ctx, accessToken, serviceID, env, to)
 }
 
 // PromoteProxyCalls gets all the calls that were made to PromoteProxy.
 // Check the length with:
 //     len(mockedThreeScaleInterface.PromoteProxyCalls())
 func (mock *ThreeScaleInterfaceMock) PromoteProxyCalls() []struct {
+	Ctx         context.Context
 	AccessToken string
 	ServiceID   string
 	Env         string
 	To          string
 } {
 	var calls []struct {
+		Ctx         context.Context
 		AccessToken string
 		ServiceID   string
 		Env         string
@@ -1607,31 +2318,35 @@ func (mock *ThreeScaleInterfaceMock) PromoteProxyCalls() []struct {
 }
 
 // SetFromEmailAddress calls SetFromEmailAddressFunc.
-func (mock *ThreeScaleInterfaceMock) SetFromEmailAddress(emailAddress string, accessToken string) (*http.Response, error) {
+func (mock *ThreeScaleInterfaceMock) SetFromEmailAddress(ctx context.Context, emailAddress string, accessToken string) (*http.Response, error) {
 	if mock.SetFromEmailAddressFunc == nil {
 		panic("ThreeScaleInterfaceMock.SetFromEmailAddressFunc: method is nil but ThreeScaleInterface.SetFromEmailAddress was just called")
 	}
 	callInfo := struct {
+		Ctx          context.Context
 		EmailAddress string
 		AccessToken  string
 	}{
+		Ctx:          ctx,
 		EmailAddress: emailAddress,
 		AccessToken:  accessToken,
 	}
 	mock.lockSetFromEmailAddress.Lock()
 	mock.calls.SetFromEmailAddress = append(mock.calls.SetFromEmailAddress, callInfo)
 	mock.lockSetFromEmailAddress.Unlock()
-	return mock.SetFromEmailAddressFunc(emailAddress, accessToken)
+	return mock.SetFromEmailAddressFunc(ctx, emailAddress, accessToken)
 }
 
 // SetFromEmailAddressCalls gets all the calls that were made to SetFromEmailAddress.
 // Check the length with:
 //     len(mockedThreeScaleInterface.SetFromEmailAddressCalls())
 func (mock *ThreeScaleInterfaceMock) SetFromEmailAddressCalls() []struct {
+	Ctx          context.Context
 	EmailAddress string
 	AccessToken  string
 } {
 	var calls []struct {
+		Ctx          context.Context
 		EmailAddress string
 		AccessToken  string
 	}
@@ -1673,31 +2388,35 @@ func (mock *ThreeScaleInterfaceMock) SetNamespaceCalls() []struct {
 }
 
 // SetUserAsAdmin calls SetUserAsAdminFunc.
-func (mock *ThreeScaleInterfaceMock) SetUserAsAdmin(userID int, accessToken string) (*http.Response, error) {
+func (mock *ThreeScaleInterfaceMock) SetUserAsAdmin(ctx context.Context, userID int, accessToken string) (*http.Response, error) {
 	if mock.SetUserAsAdminFunc == nil {
 		panic("ThreeScaleInterfaceMock.SetUserAsAdminFunc: method is nil but ThreeScaleInterface.SetUserAsAdmin was just called")
 	}
 	callInfo := struct {
+		Ctx         context.Context
 		UserID      int
 		AccessToken string
 	}{
+		Ctx:         ctx,
 		UserID:      userID,
 		AccessToken: accessToken,
 	}
 	mock.lockSetUserAsAdmin.Lock()
 	mock.calls.SetUserAsAdmin = append(mock.calls.SetUserAsAdmin, callInfo)
 	mock.lockSetUserAsAdmin.Unlock()
-	return mock.SetUserAsAdminFunc(userID, accessToken)
+	return mock.SetUserAsAdminFunc(ctx, userID, accessToken)
 }
 
 // SetUserAsAdminCalls gets all the calls that were made to SetUserAsAdmin.
 // Check the length with:
 //     len(mockedThreeScaleInterface.SetUserAsAdminCalls())
 func (mock *ThreeScaleInterfaceMock) SetUserAsAdminCalls() []struct {
+	Ctx         context.Context
 	UserID      int
 	AccessToken string
 } {
 	var calls []struct {
+		Ctx         context.Context
 		UserID      int
 		AccessToken string
 	}
@@ -1708,31 +2427,35 @@ func (mock *ThreeScaleInterfaceMock) SetUserAsAdminCalls() []struct {
 }
 
 // SetUserAsMember calls SetUserAsMemberFunc.
-func (mock *ThreeScaleInterfaceMock) SetUserAsMember(userID int, accessToken string) (*http.Response, error) {
+func (mock *ThreeScaleInterfaceMock) SetUserAsMember(ctx context.Context, userID int, accessToken string) (*http.Response, error) {
 	if mock.SetUserAsMemberFunc == nil {
 		panic("ThreeScaleInterfaceMock.SetUserAsMemberFunc: method is nil but ThreeScaleInterface.SetUserAsMember was just called")
 	}
 	callInfo := struct {
+		Ctx         context.Context
 		UserID      int
 		AccessToken string
 	}{
+		Ctx:         ctx,
 		UserID:      userID,
 		AccessToken: accessToken,
 	}
 	mock.lockSetUserAsMember.Lock()
 	mock.calls.SetUserAsMember = append(mock.calls.SetUserAsMember, callInfo)
 	mock.lockSetUserAsMember.Unlock()
-	return mock.SetUserAsMemberFunc(userID, accessToken)
+	return mock.SetUserAsMemberFunc(ctx, userID, accessToken)
 }
 
 // SetUserAsMemberCalls gets all the calls that were made to SetUserAsMember.
 // Check the length with:
 //     len(mockedThreeScaleInterface.SetUserAsMemberCalls())
 func (mock *ThreeScaleInterfaceMock) SetUserAsMemberCalls() []struct {
+	Ctx         context.Context
 	UserID      int
 	AccessToken string
 } {
 	var calls []struct {
+		Ctx         context.Context
 		UserID      int
 		AccessToken string
 	}
@@ -1742,17 +2465,242 @@ func (mock *ThreeScaleInterfaceMock) SetUserAsMemberCalls() []struct {
 	return calls
 }
 
+// SetUserPermissions calls SetUserPermissionsFunc.
+func (mock *ThreeScaleInterfaceMock) SetUserPermissions(ctx context.Context, userID int, allowedSections []string, accessToken string) (*http.Response, error) {
+	if mock.SetUserPermissionsFunc == nil {
+		panic("ThreeScaleInterfaceMock.SetUserPermissionsFunc: method is nil but ThreeScaleInterface.SetUserPermissions was just called")
+	}
+	callInfo := struct {
+		Ctx             context.Context
+		UserID          int
+		AllowedSections []string
+		AccessToken     string
+	}{
+		Ctx:             ctx,
+		UserID:          userID,
+		AllowedSections: allowedSections,
+		AccessToken:     accessToken,
+	}
+	mock.lockSetUserPermissions.Lock()
+	mock.calls.SetUserPermissions = append(mock.calls.SetUserPermissions, callInfo)
+	mock.lockSetUserPermissions.Unlock()
+	return mock.SetUserPermissionsFunc(ctx, userID, allowedSections, accessToken)
+}
+
+// SetUserPermissionsCalls gets all the calls that were made to SetUserPermissions.
+// Check the length with:
+//     len(mockedThreeScaleInterface.SetUserPermissionsCalls())
+func (mock *ThreeScaleInterfaceMock) SetUserPermissionsCalls() []struct {
+	Ctx             context.Context
+	UserID          int
+	AllowedSections []string
+	AccessToken     string
+} {
+	var calls []struct {
+		Ctx             context.Context
+		UserID          int
+		AllowedSections []string
+		AccessToken     string
+	}
+	mock.lockSetUserPermissions.RLock()
+	calls = mock.calls.SetUserPermissions
+	mock.lockSetUserPermissions.RUnlock()
+	return calls
+}
+
+// UpdateAuthenticationProvider calls UpdateAuthenticationProviderFunc.
+func (mock *ThreeScaleInterfaceMock) UpdateAuthenticationProvider(ctx context.Context, id int, data map[string]string, accessToken string) (*http.Response, error) {
+	if mock.UpdateAuthenticationProviderFunc == nil {
+		panic("ThreeScaleInterfaceMock.UpdateAuthenticationProviderFunc: method is nil but ThreeScaleInterface.UpdateAuthenticationProvider was just called")
+	}
+	callInfo := struct {
+		Ctx         context.Context
+		ID          int
+		Data        map[string]string
+		AccessToken string
+	}{
+		Ctx:         ctx,
+		ID:          id,
+		Data:        data,
+		AccessToken: accessToken,
+	}
+	mock.lockUpdateAuthenticationProvider.Lock()
+	mock.calls.UpdateAuthenticationProvider = append(mock.calls.UpdateAuthenticationProvider, callInfo)
+	mock.lockUpdateAuthenticationProvider.Unlock()
+	return mock.UpdateAuthenticationProviderFunc(ctx, id, data, accessToken)
+}
+
+// UpdateAuthenticationProviderCalls gets all the calls that were made to UpdateAuthenticationProvider.
+// Check the length with:
+//     len(mockedThreeScaleInterface.UpdateAuthenticationProviderCalls())
+func (mock *ThreeScaleInterfaceMock) UpdateAuthenticationProviderCalls() []struct {
+	Ctx         context.Context
+	ID          int
+	Data        map[string]string
+	AccessToken string
+} {
+	var calls []struct {
+		Ctx         context.Context
+		ID          int
+		Data        map[string]string
+		AccessToken string
+	}
+	mock.lockUpdateAuthenticationProvider.RLock()
+	calls = mock.calls.UpdateAuthenticationProvider
+	mock.lockUpdateAuthenticationProvider.RUnlock()
+	return calls
+}
+
+// UpdateBackend calls UpdateBackendFunc.
+func (mock *ThreeScaleInterfaceMock) UpdateBackend(ctx context.Context, accessToken string, backendID int, privateEndpoint string) error {
+	if mock.UpdateBackendFunc == nil {
+		panic("ThreeScaleInterfaceMock.UpdateBackendFunc: method is nil but ThreeScaleInterface.UpdateBackend was just called")
+	}
+	callInfo := struct {
+		Ctx             context.Context
+		AccessToken     string
+		BackendID       int
+		PrivateEndpoint string
+	}{
+		Ctx:             ctx,
+		AccessToken:     accessToken,
+		BackendID:       backendID,
+		PrivateEndpoint: privateEndpoint,
+	}
+	mock.lockUpdateBackend.Lock()
+	mock.calls.UpdateBackend = append(mock.calls.UpdateBackend, callInfo)
+	mock.lockUpdateBackend.Unlock()
+	return mock.UpdateBackendFunc(ctx, accessToken, backendID, privateEndpoint)
+}
+
+// UpdateBackendCalls gets all the calls that were made to UpdateBackend.
+// Check the length with:
+//     len(mockedThreeScaleInterface.UpdateBackendCalls())
+func (mock *ThreeScaleInterfaceMock) UpdateBackendCalls() []struct {
+	Ctx             context.Context
+	AccessToken     string
+	BackendID       int
+	PrivateEndpoint string
+} {
+	var calls []struct {
+		Ctx             context.Context
+		AccessToken     string
+		BackendID       int
+		PrivateEndpoint string
+	}
+	mock.lockUpdateBackend.RLock()
+	calls = mock.calls.UpdateBackend
+	mock.lockUpdateBackend.RUnlock()
+	return calls
+}
+
+// UpdateBackendUsage calls UpdateBackendUsageFunc.
+func (mock *ThreeScaleInterfaceMock) UpdateBackendUsage(ctx context.Context, accessToken string, serviceID string, backendUsageID int, path string) error {
+	if mock.UpdateBackendUsageFunc == nil {
+		panic("ThreeScaleInterfaceMock.UpdateBackendUsageFunc: method is nil but ThreeScaleInterface.UpdateBackendUsage was just called")
+	}
+	callInfo := struct {
+		Ctx            context.Context
+		AccessToken    string
+		ServiceID      string
+		BackendUsageID int
+		Path           string
+	}{
+		Ctx:            ctx,
+		AccessToken:    accessToken,
+		ServiceID:      serviceID,
+		BackendUsageID: backendUsageID,
+		Path:           path,
+	}
+	mock.lockUpdateBackendUsage.Lock()
+	mock.calls.UpdateBackendUsage = append(mock.calls.UpdateBackendUsage, callInfo)
+	mock.lockUpdateBackendUsage.Unlock()
+	return mock.UpdateBackendUsageFunc(ctx, accessToken, serviceID, backendUsageID, path)
+}
+
+// UpdateBackendUsageCalls gets all the calls that were made to UpdateBackendUsage.
+// Check the length with:
+//     len(mockedThreeScaleInterface.UpdateBackendUsageCalls())
+func (mock *ThreeScaleInterfaceMock) UpdateBackendUsageCalls() []struct {
+	Ctx            context.Context
+	AccessToken    string
+	ServiceID      string
+	BackendUsageID int
+	Path           string
+} {
+	var calls []struct {
+		Ctx            context.Context
+		AccessToken    string
+		ServiceID      string
+		BackendUsageID int
+		Path           string
+	}
+	mock.lockUpdateBackendUsage.RLock()
+	calls = mock.calls.UpdateBackendUsage
+	mock.lockUpdateBackendUsage.RUnlock()
+	return calls
+}
+
+// UpdateTenantUserPassword calls UpdateTenantUserPasswordFunc.
+func (mock *ThreeScaleInterfaceMock) UpdateTenantUserPassword(ctx context.Context, accessToken string, accountId int, userId int, password string) error {
+	if mock.UpdateTenantUserPasswordFunc == nil {
+		panic("ThreeScaleInterfaceMock.UpdateTenantUserPasswordFunc: method is nil but ThreeScaleInterface.UpdateTenantUserPassword was just called")
+	}
+	callInfo := struct {
+		Ctx         context.Context
+		AccessToken string
+		AccountId   int
+		UserId      int
+		Password    string
+	}{
+		Ctx:         ctx,
+		AccessToken: accessToken,
+		AccountId:   accountId,
+		UserId:      userId,
+		Password:    password,
+	}
+	mock.lockUpdateTenantUserPassword.Lock()
+	mock.calls.UpdateTenantUserPassword = append(mock.calls.UpdateTenantUserPassword, callInfo)
+	mock.lockUpdateTenantUserPassword.Unlock()
+	return mock.UpdateTenantUserPasswordFunc(ctx, accessToken, accountId, userId, password)
+}
+
+// UpdateTenantUserPasswordCalls gets all the calls that were made to UpdateTenantUserPassword.
+// Check the length with:
+//     len(mockedThreeScaleInterface.UpdateTenantUserPasswordCalls())
+func (mock *ThreeScaleInterfaceMock) UpdateTenantUserPasswordCalls() []struct {
+	Ctx         context.Context
+	AccessToken string
+	AccountId   int
+	UserId      int
+	Password    string
+} {
+	var calls []struct {
+		Ctx         context.Context
+		AccessToken string
+		AccountId   int
+		UserId      int
+		Password    string
+	}
+	mock.lockUpdateTenantUserPassword.RLock()
+	calls = mock.calls.UpdateTenantUserPassword
+	mock.lockUpdateTenantUserPassword.RUnlock()
+	return calls
+}
+
 // UpdateUser calls UpdateUserFunc.
-func (mock *ThreeScaleInterfaceMock) UpdateUser(userID int, username string, email string, accessToken string) (*http.Response, error) {
+func (mock *ThreeScaleInterfaceMock) UpdateUser(ctx context.Context, userID int, username string, email string, accessToken string) (*http.Response, error) {
 	if mock.UpdateUserFunc == nil {
 		panic("ThreeScaleInterfaceMock.UpdateUserFunc: method is nil but ThreeScaleInterface.UpdateUser was just called")
 	}
 	callInfo := struct {
+		Ctx         context.Context
 		UserID      int
 		Username    string
 		Email       string
 		AccessToken string
 	}{
+		Ctx:         ctx,
 		UserID:      userID,
 		Username:    username,
 		Email:       email,
@@ -1761,19 +2709,21 @@ func (mock *ThreeScaleInterfaceMock) UpdateUser(userID int, username string, ema
 	mock.lockUpdateUser.Lock()
 	mock.calls.UpdateUser = append(mock.calls.UpdateUser, callInfo)
 	mock.lockUpdateUser.Unlock()
-	return mock.UpdateUserFunc(userID, username, email, accessToken)
+	return mock.UpdateUserFunc(ctx, userID, username, email, accessToken)
 }
 
 // UpdateUserCalls gets all the calls that were made to UpdateUser.
 // Check the length with:
 //     len(mockedThreeScaleInterface.UpdateUserCalls())
 func (mock *ThreeScaleInterfaceMock) UpdateUserCalls() []struct {
+	Ctx         context.Context
 	UserID      int
 	Username    string
 	Email       string
 	AccessToken string
 } {
 	var calls []struct {
+		Ctx         context.Context
 		UserID      int
 		Username    string
 		Email       string
@@ -0,0 +1,197 @@
+package threescale
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/integr8ly/integreatly-operator/pkg/metrics"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// defaultRequestsPerSecond and defaultRequestBurst limit the load the
+	// operator puts on the 3scale admin API, which is shared with the
+	// customer traffic of the admin portal
+	defaultRequestsPerSecond = 10
+	defaultRequestBurst      = 20
+
+	defaultMaxRetries     = 4
+	defaultRetryBaseDelay = 500 * time.Millisecond
+	defaultRetryMaxDelay  = 10 * time.Second
+
+	// maxListPages guards against list endpoints that ignore the page
+	// parameter and keep returning the same full page
+	maxListPages = 100
+)
+
+// idPathSegment matches numeric path segments, which are replaced in the
+// endpoint label of the request metrics to keep their cardinality low
+var idPathSegment = regexp.MustCompile(`/[0-9]+(/|\.|$)`)
+
+// do sends a request to the 3scale API, waiting for the client rate limiter
+// first. Idempotent requests failing with a network error, a 429 or a 5xx
+// status code are retried with exponential backoff and full jitter. Other
+// requests may have been applied when they fail, they are only retried on a
+// 429. The response of the last attempt is returned once the retries are
+// exhausted so that callers can report its status code
+func (tsc *threeScaleClient) do(ctx context.Context, method, rawURL string, parameters map[string]interface{}) (*http.Response, error) {
+	var body []byte
+	if method == http.MethodGet {
+		query := url.Values{}
+		for key, value := range parameters {
+			query.Set(key, fmt.Sprintf("%v", value))
+		}
+		if len(query) > 0 {
+			rawURL = rawURL + "?" + query.Encode()
+		}
+	} else {
+		data, err := json.Marshal(parameters)
+		if err != nil {
+			return nil, err
+		}
+		body = data
+	}
+
+	endpoint := requestEndpoint(rawURL)
+	for attempt := 0; ; attempt++ {
+		if err := tsc.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, method, rawURL, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		start := time.Now()
+		res, err := tsc.httpc.Do(req)
+		code := "error"
+		if err == nil {
+			code = strconv.Itoa(res.StatusCode)
+		}
+		metrics.ObserveThreeScaleRequest(method, endpoint, code, time.Since(start))
+
+		if err != nil {
+			if ctx.Err() != nil || !isIdempotent(method) || attempt >= tsc.maxRetries {
+				return nil, fmt.Errorf("%s %s failed after %d attempts: %w", method, endpoint, attempt+1, err)
+			}
+		} else if !isRetryableStatus(method, res.StatusCode) || attempt >= tsc.maxRetries {
+			return res, nil
+		}
+
+		delay := tsc.retryDelay(attempt, res)
+		if res != nil {
+			// drain the body so the connection can be reused
+			_, _ = io.Copy(ioutil.Discard, res.Body)
+			res.Body.Close()
+		}
+		metrics.IncThreeScaleRequestRetry(method, endpoint)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// retryDelay returns the delay before the next attempt. The Retry-After
+// header of rate limited responses is honoured when set, up to the maximum
+// retry delay so that a reconcile isn't blocked by a long Retry-After
+func (tsc *threeScaleClient) retryDelay(attempt int, res *http.Response) time.Duration {
+	if res != nil && res.StatusCode == http.StatusTooManyRequests {
+		if delay, ok := retryAfter(res.Header.Get("Retry-After"), time.Now()); ok {
+			if delay > tsc.retryMaxDelay {
+				delay = tsc.retryMaxDelay
+			}
+			return delay
+		}
+	}
+
+	backoff := tsc.retryBaseDelay << uint(attempt)
+	if backoff <= 0 || backoff > tsc.retryMaxDelay {
+		backoff = tsc.retryMaxDelay
+	}
+	return time.Duration(rand.Int63n(int64(backoff) + 1))
+}
+
+// retryAfter parses a Retry-After header, in seconds or as an HTTP date
+func retryAfter(header string, now time.Time) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return time.Duration(seconds) * time.Second, seconds > 0
+	}
+	if date, err := http.ParseTime(header); err == nil && date.After(now) {
+		return date.Sub(now), true
+	}
+	return 0, false
+}
+
+// isIdempotent returns whether sending a request of method more than once
+// has the same effect as sending it once
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// isRetryableStatus returns whether a request of method that got a response
+// with statusCode is retried. A 429 means the request was rejected before it
+// was processed, the requests of any method are retried on it
+func isRetryableStatus(method string, statusCode int) bool {
+	if statusCode == http.StatusTooManyRequests {
+		return true
+	}
+	return isIdempotent(method) && statusCode >= http.StatusInternalServerError
+}
+
+// requestEndpoint returns the path of the request with IDs removed, for use
+// as a metric label
+func requestEndpoint(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "unknown"
+	}
+	endpoint := parsed.Path
+	for idPathSegment.MatchString(endpoint) {
+		endpoint = idPathSegment.ReplaceAllString(endpoint, "/:id$1")
+	}
+	return endpoint
+}
+
+// listPages calls fetchPage for every page of a list endpoint until a page
+// doesn't add any new item or is shorter than listPageSize. fetchPage returns
+// the number of items in the page and how many of them weren't seen before
+func listPages(fetchPage func(page int) (int, int, error)) error {
+	for page := 1; page <= maxListPages; page++ {
+		items, added, err := fetchPage(page)
+		if err != nil {
+			return err
+		}
+		if items < listPageSize || added == 0 {
+			return nil
+		}
+	}
+	return fmt.Errorf("list exceeded %d pages", maxListPages)
+}
+
+func newDefaultRateLimiter() *rate.Limiter {
+	return rate.NewLimiter(rate.Limit(defaultRequestsPerSecond), defaultRequestBurst)
+}
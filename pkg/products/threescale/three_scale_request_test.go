@@ -0,0 +1,326 @@
+package threescale
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func newResponse(statusCode int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: statusCode,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func newTestThreeScaleClient(roundTrip roundTripFunc) *threeScaleClient {
+	tsc := NewThreeScaleClient(&http.Client{Transport: roundTrip}, "apps.example.com")
+	tsc.limiter = rate.NewLimiter(rate.Inf, 1)
+	tsc.retryBaseDelay = time.Millisecond
+	tsc.retryMaxDelay = time.Millisecond
+	return tsc
+}
+
+func TestThreeScaleClient_do(t *testing.T) {
+	tests := []struct {
+		Name             string
+		Method           string
+		Responses        []int
+		ExpectedAttempts int
+		ExpectedStatus   int
+		ExpectErr        bool
+	}{
+		{
+			Name:             "test successful request is not retried",
+			Responses:        []int{http.StatusOK},
+			ExpectedAttempts: 1,
+			ExpectedStatus:   http.StatusOK,
+		},
+		{
+			Name:             "test client errors are not retried",
+			Responses:        []int{http.StatusNotFound},
+			ExpectedAttempts: 1,
+			ExpectedStatus:   http.StatusNotFound,
+		},
+		{
+			Name:             "test server errors and rate limited requests are retried",
+			Responses:        []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK},
+			ExpectedAttempts: 3,
+			ExpectedStatus:   http.StatusOK,
+		},
+		{
+			Name:             "test last response is returned when retries are exhausted",
+			Responses:        []int{500, 500, 500, 500, 502},
+			ExpectedAttempts: defaultMaxRetries + 1,
+			ExpectedStatus:   http.StatusBadGateway,
+		},
+		{
+			Name:             "test network errors are retried",
+			Responses:        []int{0, http.StatusOK},
+			ExpectedAttempts: 2,
+			ExpectedStatus:   http.StatusOK,
+		},
+		{
+			Name:             "test error when network errors exhaust the retries",
+			Responses:        []int{0, 0, 0, 0, 0},
+			ExpectedAttempts: defaultMaxRetries + 1,
+			ExpectErr:        true,
+		},
+		{
+			Name:             "test non idempotent requests are not retried on server errors",
+			Method:           http.MethodPost,
+			Responses:        []int{http.StatusServiceUnavailable},
+			ExpectedAttempts: 1,
+			ExpectedStatus:   http.StatusServiceUnavailable,
+		},
+		{
+			Name:             "test non idempotent requests are not retried on network errors",
+			Method:           http.MethodPost,
+			Responses:        []int{0},
+			ExpectedAttempts: 1,
+			ExpectErr:        true,
+		},
+		{
+			Name:             "test non idempotent requests are retried when rate limited",
+			Method:           http.MethodPost,
+			Responses:        []int{http.StatusTooManyRequests, http.StatusCreated},
+			ExpectedAttempts: 2,
+			ExpectedStatus:   http.StatusCreated,
+		},
+		{
+			Name:             "test idempotent updates are retried on server errors",
+			Method:           http.MethodPut,
+			Responses:        []int{http.StatusBadGateway, http.StatusOK},
+			ExpectedAttempts: 2,
+			ExpectedStatus:   http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			attempts := 0
+			tsc := newTestThreeScaleClient(func(req *http.Request) (*http.Response, error) {
+				statusCode := tt.Responses[attempts]
+				attempts++
+				if statusCode == 0 {
+					return nil, fmt.Errorf("connection refused")
+				}
+				return newResponse(statusCode, "{}"), nil
+			})
+
+			method := tt.Method
+			if method == "" {
+				method = http.MethodGet
+			}
+			res, err := tsc.makeRequest(context.TODO(), method, "users.json", onlyAccessToken("token"))
+			if (err != nil) != tt.ExpectErr {
+				t.Fatalf("unexpected error value, expected error: %v, got: %v", tt.ExpectErr, err)
+			}
+			if attempts != tt.ExpectedAttempts {
+				t.Fatalf("expected %d attempts, got %d", tt.ExpectedAttempts, attempts)
+			}
+			if err == nil && res.StatusCode != tt.ExpectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.ExpectedStatus, res.StatusCode)
+			}
+		})
+	}
+}
+
+func TestThreeScaleClient_doCancelledContext(t *testing.T) {
+	attempts := 0
+	tsc := newTestThreeScaleClient(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		return newResponse(http.StatusServiceUnavailable, ""), nil
+	})
+	tsc.retryBaseDelay = time.Hour
+	tsc.retryMaxDelay = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := tsc.makeRequest(ctx, http.MethodGet, "users.json", onlyAccessToken("token")); err == nil {
+		t.Fatal("expected error when the context is cancelled")
+	}
+	if attempts != 1 {
+		t.Fatalf("expected 1 attempt, got %d", attempts)
+	}
+}
+
+func TestThreeScaleClient_retryDelay(t *testing.T) {
+	tsc := newTestThreeScaleClient(nil)
+	tsc.retryMaxDelay = 10 * time.Second
+
+	tests := []struct {
+		Name          string
+		RetryAfter    string
+		ExpectedDelay time.Duration
+	}{
+		{
+			Name:          "test retry after in seconds is honoured",
+			RetryAfter:    "3",
+			ExpectedDelay: 3 * time.Second,
+		},
+		{
+			Name:          "test retry after is capped to the maximum retry delay",
+			RetryAfter:    "3600",
+			ExpectedDelay: 10 * time.Second,
+		},
+		{
+			Name:          "test retry after date is capped to the maximum retry delay",
+			RetryAfter:    time.Now().Add(time.Hour).UTC().Format(http.TimeFormat),
+			ExpectedDelay: 10 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			res := newResponse(http.StatusTooManyRequests, "")
+			res.Header.Set("Retry-After", tt.RetryAfter)
+			if delay := tsc.retryDelay(0, res); delay != tt.ExpectedDelay {
+				t.Fatalf("expected delay %v, got %v", tt.ExpectedDelay, delay)
+			}
+		})
+	}
+}
+
+func TestThreeScaleClient_ListApplicationPlansPagination(t *testing.T) {
+	totalPlans := listPageSize + 10
+	tsc := newTestThreeScaleClient(func(req *http.Request) (*http.Response, error) {
+		page, err := strconv.Atoi(req.URL.Query().Get("page"))
+		if err != nil {
+			return nil, err
+		}
+
+		plans := []string{}
+		for id := (page-1)*listPageSize + 1; id <= page*listPageSize && id <= totalPlans; id++ {
+			plans = append(plans, fmt.Sprintf(`{"application_plan":{"id":%d}}`, id))
+		}
+		return newResponse(http.StatusOK, fmt.Sprintf(`{"plans":[%s]}`, strings.Join(plans, ","))), nil
+	})
+
+	plans, err := tsc.ListApplicationPlans(context.TODO(), "token", "1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(plans.Plans) != totalPlans {
+		t.Fatalf("expected %d application plans, got %d", totalPlans, len(plans.Plans))
+	}
+}
+
+func TestThreeScaleClient_ListBackendUsagesPagination(t *testing.T) {
+	totalBackendUsages := listPageSize + 10
+	tsc := newTestThreeScaleClient(func(req *http.Request) (*http.Response, error) {
+		page, err := strconv.Atoi(req.URL.Query().Get("page"))
+		if err != nil {
+			return nil, err
+		}
+
+		backendUsages := []string{}
+		for id := (page-1)*listPageSize + 1; id <= page*listPageSize && id <= totalBackendUsages; id++ {
+			backendUsages = append(backendUsages, fmt.Sprintf(`{"backend_usage":{"id":%d}}`, id))
+		}
+		return newResponse(http.StatusOK, fmt.Sprintf(`[%s]`, strings.Join(backendUsages, ","))), nil
+	})
+
+	backendUsages, err := tsc.ListBackendUsages(context.TODO(), "token", "1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(backendUsages) != totalBackendUsages {
+		t.Fatalf("expected %d backend usages, got %d", totalBackendUsages, len(backendUsages))
+	}
+}
+
+func TestThreeScaleClient_GetUsersPagination(t *testing.T) {
+	totalUsers := listPageSize + 10
+	requestedPages := []string{}
+	tsc := newTestThreeScaleClient(func(req *http.Request) (*http.Response, error) {
+		page, err := strconv.Atoi(req.URL.Query().Get("page"))
+		if err != nil {
+			return nil, err
+		}
+		requestedPages = append(requestedPages, req.URL.Query().Get("page"))
+
+		users := []string{}
+		for id := (page-1)*listPageSize + 1; id <= page*listPageSize && id <= totalUsers; id++ {
+			users = append(users, fmt.Sprintf(`{"user":{"id":%d,"username":"user%d"}}`, id, id))
+		}
+		return newResponse(http.StatusOK, fmt.Sprintf(`{"users":[%s]}`, strings.Join(users, ","))), nil
+	})
+
+	users, err := tsc.GetUsers(context.TODO(), "token")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(users.Users) != totalUsers {
+		t.Fatalf("expected %d users, got %d", totalUsers, len(users.Users))
+	}
+	if strings.Join(requestedPages, ",") != "1,2" {
+		t.Fatalf("expected pages 1 and 2 to be requested, got %v", requestedPages)
+	}
+}
+
+func TestThreeScaleClient_ListPagesIgnoredPageParameter(t *testing.T) {
+	requests := 0
+	tsc := newTestThreeScaleClient(func(req *http.Request) (*http.Response, error) {
+		requests++
+		services := []string{}
+		for id := 1; id <= listPageSize; id++ {
+			services = append(services, fmt.Sprintf(`{"service":{"id":%d}}`, id))
+		}
+		return newResponse(http.StatusOK, fmt.Sprintf(`{"services":[%s]}`, strings.Join(services, ","))), nil
+	})
+
+	services, err := tsc.ListServices(context.TODO(), "token")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(services.Services) != listPageSize {
+		t.Fatalf("expected %d services, got %d", listPageSize, len(services.Services))
+	}
+	if requests != 2 {
+		t.Fatalf("expected listing to stop after a page without new services, got %d requests", requests)
+	}
+}
+
+func TestAssertStatusCode(t *testing.T) {
+	err := assertStatusCode(http.StatusOK, newResponse(http.StatusNotFound, "not found"))
+	if !tsIsNotFoundError(err) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+	if !tsIsNotFoundError(fmt.Errorf("wrapped: %w", err)) {
+		t.Fatal("expected wrapped error to be a not found error")
+	}
+	if err.Error() != "unexpected status code: 404. Body: not found" {
+		t.Fatalf("unexpected error message %s", err.Error())
+	}
+}
+
+func TestRequestEndpoint(t *testing.T) {
+	tests := map[string]string{
+		"https://3scale-admin.apps.example.com/admin/api/users.json?access_token=token":        "/admin/api/users.json",
+		"https://3scale-admin.apps.example.com/admin/api/users/12/admin.json":                  "/admin/api/users/:id/admin.json",
+		"https://3scale-admin.apps.example.com/admin/api/backend_apis/3/mapping_rules/45.json": "/admin/api/backend_apis/:id/mapping_rules/:id.json",
+		"https://master.apps.example.com/master/api/providers/7.xml":                           "/master/api/providers/:id.xml",
+	}
+
+	for rawURL, expected := range tests {
+		if endpoint := requestEndpoint(rawURL); endpoint != expected {
+			t.Fatalf("expected endpoint %s for %s, got %s", expected, rawURL, endpoint)
+		}
+	}
+}
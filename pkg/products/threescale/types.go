@@ -1,6 +1,9 @@
 package threescale
 
-import "net/http"
+import (
+	"errors"
+	"net/http"
+)
 
 type Users struct {
 	Users []*User `json:"users"`
@@ -95,14 +98,103 @@ type XMLUsers struct {
 	User []XMLUserDetails `xml:"user"`
 }
 
+type Backends struct {
+	Backends []*Backend `json:"backend_apis"`
+}
+
+type Backend struct {
+	BackendDetails BackendDetails `json:"backend_api"`
+}
+
+type BackendDetails struct {
+	Id              int    `json:"id"`
+	Name            string `json:"name"`
+	SystemName      string `json:"system_name"`
+	Description     string `json:"description"`
+	PrivateEndpoint string `json:"private_endpoint"`
+}
+
+type Metrics struct {
+	Metrics []*Metric `json:"metrics"`
+}
+
+type Metric struct {
+	MetricDetails MetricDetails `json:"metric"`
+}
+
+type MetricDetails struct {
+	Id           int    `json:"id"`
+	Name         string `json:"name"`
+	SystemName   string `json:"system_name"`
+	FriendlyName string `json:"friendly_name"`
+	Unit         string `json:"unit"`
+}
+
+type MappingRules struct {
+	MappingRules []*MappingRule `json:"mapping_rules"`
+}
+
+type MappingRule struct {
+	MappingRuleDetails MappingRuleDetails `json:"mapping_rule"`
+}
+
+type MappingRuleDetails struct {
+	Id         int    `json:"id"`
+	MetricId   int    `json:"metric_id"`
+	HTTPMethod string `json:"http_method"`
+	Pattern    string `json:"pattern"`
+	Delta      int    `json:"delta"`
+}
+
+type Services struct {
+	Services []*Service `json:"services"`
+}
+
+type Service struct {
+	ServiceDetails ServiceDetails `json:"service"`
+}
+
+type ServiceDetails struct {
+	Id          int    `json:"id"`
+	Name        string `json:"name"`
+	SystemName  string `json:"system_name"`
+	Description string `json:"description"`
+}
+
+type BackendUsage struct {
+	BackendUsageDetails BackendUsageDetails `json:"backend_usage"`
+}
+
+type BackendUsageDetails struct {
+	Id        int    `json:"id"`
+	Path      string `json:"path"`
+	ServiceId int    `json:"service_id"`
+	BackendId int    `json:"backend_id"`
+}
+
+type ApplicationPlans struct {
+	Plans []*ApplicationPlan `json:"plans"`
+}
+
+type ApplicationPlan struct {
+	ApplicationPlanDetails ApplicationPlanDetails `json:"application_plan"`
+}
+
+type ApplicationPlanDetails struct {
+	Id         int    `json:"id"`
+	Name       string `json:"name"`
+	SystemName string `json:"system_name"`
+	State      string `json:"state"`
+}
+
 func (tse *tsError) Error() string {
 	return tse.message
 }
 
 func tsIsNotFoundError(e error) bool {
-	switch e := e.(type) {
-	case *tsError:
-		return e.StatusCode == http.StatusNotFound
+	tse := &tsError{}
+	if errors.As(e, &tse) {
+		return tse.StatusCode == http.StatusNotFound
 	}
 
 	return false
@@ -0,0 +1,186 @@
+// Package capacity forecasts when the Postgres instances provisioned by the
+// cloud resource operator run out of storage, and when the Redis instances
+// run out of memory.
+//
+// The forecasts are computed by Prometheus recording rules, projecting the
+// growth of the cloud resource operator metrics over the last 6 hours with
+// predict_linear, and read back by the operator to be published in the RHMI
+// status
+package capacity
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"github.com/prometheus/client_golang/api"
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// PostgresDaysUntilFullRecord is the number of days until a Postgres
+	// instance runs out of storage
+	PostgresDaysUntilFullRecord = "cro_postgres:free_storage:days_until_full"
+	// RedisDaysUntilFullRecord is the number of days until a Redis instance
+	// runs out of memory
+	RedisDaysUntilFullRecord = "cro_redis:memory_usage:days_until_full"
+
+	PostgresType = "postgres"
+	RedisType    = "redis"
+
+	postgresPredictedFreeStorageRecord = "cro_postgres:free_storage:predict_linear_1d"
+	redisPredictedMemoryUsageRecord    = "cro_redis:memory_usage:predict_linear_1d"
+	postgresAllocatedStorageMetric     = "cro_postgres_current_allocated_storage"
+
+	// forecasts further out than a year are reported as a year
+	maxDaysUntilFull = 365
+	bytesInGiB       = 1024 * 1024 * 1024
+	queryTimeout     = 10 * time.Second
+)
+
+// RecordingRules returns the rules computing the number of days until the
+// cloud resources are full. The usage projected a day ahead is recorded
+// first, the days until full are the remaining capacity divided by the
+// projected daily growth, and are only recorded for growing usage
+func RecordingRules() []monitoringv1.Rule {
+	return []monitoringv1.Rule{
+		{
+			Record: postgresPredictedFreeStorageRecord,
+			Expr:   intstr.FromString("predict_linear(cro_postgres_free_storage_average[6h], 24 * 3600)"),
+		},
+		{
+			Record: PostgresDaysUntilFullRecord,
+			Expr:   intstr.FromString(fmt.Sprintf("cro_postgres_free_storage_average / ((cro_postgres_free_storage_average - %s) > 0)", postgresPredictedFreeStorageRecord)),
+		},
+		{
+			Record: redisPredictedMemoryUsageRecord,
+			Expr:   intstr.FromString("predict_linear(cro_redis_memory_usage_percentage_average[6h], 24 * 3600)"),
+		},
+		{
+			Record: RedisDaysUntilFullRecord,
+			Expr:   intstr.FromString(fmt.Sprintf("(100 - cro_redis_memory_usage_percentage_average) / ((%s - cro_redis_memory_usage_percentage_average) > 0)", redisPredictedMemoryUsageRecord)),
+		},
+	}
+}
+
+// Forecaster returns the capacity forecasts of the cloud resources
+type Forecaster interface {
+	Forecast(ctx context.Context) ([]integreatlyv1alpha1.CapacityForecast, error)
+}
+
+type prometheusForecaster struct {
+	api prometheusv1.API
+}
+
+var _ Forecaster = &prometheusForecaster{}
+
+// NewPrometheusForecaster returns a Forecaster reading the recording rules
+// from the Prometheus at address, authenticating with the bearer token
+func NewPrometheusForecaster(address, bearerToken string) (Forecaster, error) {
+	client, err := api.NewClient(api.Config{
+		Address: address,
+		RoundTripper: &bearerTokenRoundTripper{
+			bearerToken: bearerToken,
+			next:        api.DefaultRoundTripper,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create prometheus client for %s: %w", address, err)
+	}
+	return &prometheusForecaster{api: prometheusv1.NewAPI(client)}, nil
+}
+
+// Forecast returns the forecasts of the growing cloud resources, ordered by
+// type, namespace and name
+func (f *prometheusForecaster) Forecast(ctx context.Context) ([]integreatlyv1alpha1.CapacityForecast, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	allocatedStorage, err := f.query(ctx, postgresAllocatedStorageMetric)
+	if err != nil {
+		return nil, err
+	}
+	allocatedStorageByResource := map[string]int64{}
+	for _, sample := range allocatedStorage {
+		allocatedStorageByResource[resourceKey(sample.Metric)] = int64(float64(sample.Value) / bytesInGiB)
+	}
+
+	forecasts := []integreatlyv1alpha1.CapacityForecast{}
+	for resourceType, record := range map[string]string{
+		PostgresType: PostgresDaysUntilFullRecord,
+		RedisType:    RedisDaysUntilFullRecord,
+	} {
+		samples, err := f.query(ctx, record)
+		if err != nil {
+			return nil, err
+		}
+		for _, sample := range samples {
+			forecast := integreatlyv1alpha1.CapacityForecast{
+				Type:          resourceType,
+				Name:          string(sample.Metric["resourceID"]),
+				Namespace:     string(sample.Metric["namespace"]),
+				ProductName:   string(sample.Metric["productName"]),
+				DaysUntilFull: daysUntilFull(float64(sample.Value)),
+			}
+			if resourceType == PostgresType {
+				forecast.AllocatedStorage = allocatedStorageByResource[resourceKey(sample.Metric)]
+			}
+			forecasts = append(forecasts, forecast)
+		}
+	}
+
+	sort.Slice(forecasts, func(i, j int) bool {
+		if forecasts[i].Type != forecasts[j].Type {
+			return forecasts[i].Type < forecasts[j].Type
+		}
+		if forecasts[i].Namespace != forecasts[j].Namespace {
+			return forecasts[i].Namespace < forecasts[j].Namespace
+		}
+		return forecasts[i].Name < forecasts[j].Name
+	})
+	return forecasts, nil
+}
+
+func (f *prometheusForecaster) query(ctx context.Context, query string) (model.Vector, error) {
+	result, _, err := f.api.Query(ctx, query, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", query, err)
+	}
+	vector, ok := result.(model.Vector)
+	if !ok {
+		return nil, fmt.Errorf("unexpected result type %s for query %s", result.Type(), query)
+	}
+	return vector, nil
+}
+
+func resourceKey(metric model.Metric) string {
+	return fmt.Sprintf("%s/%s", metric["namespace"], metric["resourceID"])
+}
+
+func daysUntilFull(days float64) int32 {
+	if math.IsNaN(days) || days > maxDaysUntilFull {
+		return maxDaysUntilFull
+	}
+	if days < 0 {
+		return 0
+	}
+	return int32(days)
+}
+
+type bearerTokenRoundTripper struct {
+	bearerToken string
+	next        http.RoundTripper
+}
+
+func (rt *bearerTokenRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+rt.bearerToken)
+	return rt.next.RoundTrip(req)
+}
@@ -0,0 +1,82 @@
+package capacity
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+)
+
+func prometheusServer(t *testing.T, results map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("failed to parse query: %v", err)
+		}
+		result, ok := results[r.Form.Get("query")]
+		if !ok {
+			result = "[]"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"status": "success", "data": {"resultType": "vector", "result": %s}}`, result)
+	}))
+}
+
+func TestPrometheusForecaster_Forecast(t *testing.T) {
+	scenarios := []struct {
+		Name              string
+		Results           map[string]string
+		ExpectedForecasts []integreatlyv1alpha1.CapacityForecast
+	}{
+		{
+			Name:              "test no growing resources returns no forecasts",
+			ExpectedForecasts: []integreatlyv1alpha1.CapacityForecast{},
+		},
+		{
+			Name: "test forecasts are returned sorted with the postgres allocated storage",
+			Results: map[string]string{
+				PostgresDaysUntilFullRecord: `[
+					{"metric": {"resourceID": "threescale-postgres-rhmi", "namespace": "redhat-rhoam-operator", "productName": "3scale"}, "value": [1600000000, "3.7"]},
+					{"metric": {"resourceID": "rhsso-postgres-rhmi", "namespace": "redhat-rhoam-operator", "productName": "rhsso"}, "value": [1600000000, "1200"]}
+				]`,
+				RedisDaysUntilFullRecord: `[
+					{"metric": {"resourceID": "threescale-redis-rhmi", "namespace": "redhat-rhoam-operator", "productName": "3scale"}, "value": [1600000000, "12.2"]}
+				]`,
+				postgresAllocatedStorageMetric: `[
+					{"metric": {"resourceID": "threescale-postgres-rhmi", "namespace": "redhat-rhoam-operator"}, "value": [1600000000, "53687091200"]}
+				]`,
+			},
+			ExpectedForecasts: []integreatlyv1alpha1.CapacityForecast{
+				{Type: PostgresType, Name: "rhsso-postgres-rhmi", Namespace: "redhat-rhoam-operator", ProductName: "rhsso", DaysUntilFull: maxDaysUntilFull},
+				{Type: PostgresType, Name: "threescale-postgres-rhmi", Namespace: "redhat-rhoam-operator", ProductName: "3scale", DaysUntilFull: 3, AllocatedStorage: 50},
+				{Type: RedisType, Name: "threescale-redis-rhmi", Namespace: "redhat-rhoam-operator", ProductName: "3scale", DaysUntilFull: 12},
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.Name, func(t *testing.T) {
+			server := prometheusServer(t, scenario.Results)
+			defer server.Close()
+
+			forecaster, err := NewPrometheusForecaster(server.URL, "token")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			forecasts, err := forecaster.Forecast(context.TODO())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(forecasts, scenario.ExpectedForecasts) {
+				t.Errorf("expected forecasts %v, got %v", scenario.ExpectedForecasts, forecasts)
+			}
+		})
+	}
+}
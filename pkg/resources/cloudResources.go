@@ -0,0 +1,121 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	crov1 "github.com/integr8ly/cloud-resource-operator/apis/integreatly/v1alpha1"
+	croUtil "github.com/integr8ly/cloud-resource-operator/pkg/client"
+	croProviders "github.com/integr8ly/cloud-resource-operator/pkg/providers"
+	croAWS "github.com/integr8ly/cloud-resource-operator/pkg/providers/aws"
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/clusterstorage"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/constants"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/owner"
+	corev1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ReconcilePostgres provisions a Postgres instance for the product, in the
+// cluster if the installation uses cluster storage or through the cloud
+// resource operator otherwise
+func ReconcilePostgres(ctx context.Context, client k8sclient.Client, installation *integreatlyv1alpha1.RHMI, productName, name, ns string) (*crov1.Postgres, error) {
+	addOwner := func(cr metav1.Object) error {
+		owner.AddIntegreatlyOwnerAnnotations(cr, installation)
+		return nil
+	}
+
+	inCluster, err := useInClusterProvider(ctx, client, installation, &crov1.Postgres{}, name, ns)
+	if err != nil {
+		return nil, err
+	}
+	if inCluster {
+		return clusterstorage.ReconcilePostgres(ctx, client, productName, name, ns, addOwner)
+	}
+	tier, err := strategyTier(ctx, client, croProviders.PostgresResourceType, name, installation.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	return croUtil.ReconcilePostgres(ctx, client, productName, installation.Spec.Type, tier, name, ns, name, ns, constants.PostgresApplyImmediately, addOwner)
+}
+
+// ReconcileRedis provisions a Redis instance for the product, in the cluster
+// if the installation uses cluster storage or through the cloud resource
+// operator otherwise
+func ReconcileRedis(ctx context.Context, client k8sclient.Client, installation *integreatlyv1alpha1.RHMI, productName, name, ns string) (*crov1.Redis, error) {
+	addOwner := func(cr metav1.Object) error {
+		owner.AddIntegreatlyOwnerAnnotations(cr, installation)
+		return nil
+	}
+
+	inCluster, err := useInClusterProvider(ctx, client, installation, &crov1.Redis{}, name, ns)
+	if err != nil {
+		return nil, err
+	}
+	if inCluster {
+		return clusterstorage.ReconcileRedis(ctx, client, productName, name, ns, addOwner)
+	}
+	tier, err := strategyTier(ctx, client, croProviders.RedisResourceType, name, installation.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	return croUtil.ReconcileRedis(ctx, client, productName, installation.Spec.Type, tier, name, ns, name, ns, false, addOwner)
+}
+
+// StrategyTier returns the tier of the AWS strategies the overrides of the
+// cloud resource named name are written to
+func StrategyTier(name string) string {
+	return fmt.Sprintf("%s-%s", croUtil.TierProduction, name)
+}
+
+// strategyTier returns the tier the cloud resource is provisioned with, its
+// own tier if it has one in the AWS strategies or the production tier
+func strategyTier(ctx context.Context, client k8sclient.Client, resourceType croProviders.ResourceType, name, ns string) (string, error) {
+	cfgMap := &corev1.ConfigMap{}
+	if err := client.Get(ctx, k8sclient.ObjectKey{Name: croAWS.DefaultConfigMapName, Namespace: ns}, cfgMap); err != nil {
+		if k8serr.IsNotFound(err) {
+			return croUtil.TierProduction, nil
+		}
+		return "", fmt.Errorf("failed to get aws strategy config map: %w", err)
+	}
+
+	rawStrategy := map[string]json.RawMessage{}
+	if data, ok := cfgMap.Data[string(resourceType)]; ok {
+		if err := json.Unmarshal([]byte(data), &rawStrategy); err != nil {
+			return "", fmt.Errorf("failed to unmarshal strategy mapping for resource type %s: %w", resourceType, err)
+		}
+	}
+	if _, ok := rawStrategy[StrategyTier(name)]; ok {
+		return StrategyTier(name), nil
+	}
+	return croUtil.TierProduction, nil
+}
+
+// UsesClusterStorage returns true if the installation provisions its
+// Postgres and Redis instances in the cluster
+func UsesClusterStorage(installation *integreatlyv1alpha1.RHMI) bool {
+	return strings.ToLower(installation.Spec.UseClusterStorage) == "true"
+}
+
+// useInClusterProvider returns true if the instance is provisioned in the
+// cluster. Instances that were provisioned by the cloud resource operator
+// before the in-cluster provider was introduced are left to it, so that their
+// data is kept
+func useInClusterProvider(ctx context.Context, client k8sclient.Client, installation *integreatlyv1alpha1.RHMI, cr runtime.Object, name, ns string) (bool, error) {
+	if !UsesClusterStorage(installation) {
+		return false, nil
+	}
+
+	if err := client.Get(ctx, k8sclient.ObjectKey{Name: name, Namespace: ns}, cr); err != nil {
+		if k8serr.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+	return false, nil
+}
@@ -0,0 +1,150 @@
+// alerts for the Postgres and Redis instances provisioned in the cluster, built
+// on the StatefulSet and persistent volume metrics instead of the cloud resource
+// operator metrics. The alert names are the same as for the cloud provider
+// instances so that they are routed and documented the same way
+//
+// alerts created :
+//  * Postgres Availability Alerts (per instance)
+//  * Postgres will run out of space in 4 days (per instance)
+//  * Postgres will run out of space in 4 hours (per instance)
+//  * Postgres low storage (per instance)
+//  * Redis Availability Alerts (per instance)
+//
+// failures of the backup CronJobs are covered by the CronJob alerts of the
+// jobs labelled with the monitoring key
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	crov1 "github.com/integr8ly/cloud-resource-operator/apis/integreatly/v1alpha1"
+	cro1types "github.com/integr8ly/cloud-resource-operator/apis/integreatly/v1alpha1/types"
+	"github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/clusterstorage"
+	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func reconcileClusterStoragePostgresAlerts(ctx context.Context, client k8sclient.Client, inst *v1alpha1.RHMI, cr *crov1.Postgres, log l.Logger) (v1alpha1.StatusPhase, error) {
+	installationName := InstallationNames[inst.Spec.Type]
+	productName := cr.Labels["productName"]
+	postgresCRName := strings.Title(strings.Replace(cr.Name, "postgres-example-rhmi", "", -1))
+	ruleNs := inst.Spec.NamespacePrefix + "observability"
+
+	alertName := postgresCRName + "PostgresInstanceUnavailable"
+	sopURL := sopUrlRhoamBase + alertName + ".asciidoc"
+	alertSeverity := "critical"
+	if strings.Contains(productName, "sso") {
+		// same severity as the cloud provider instances, see createPostgresAvailabilityAlert
+		alertSeverity = "warning"
+		sopURL = sopUrlPostgresInstanceUnavailable
+	}
+	alertExp := intstr.FromString(
+		fmt.Sprintf("absent(kube_statefulset_status_replicas_ready{namespace='%s',statefulset='%s'} >= 1)", cr.Namespace, cr.Name),
+	)
+	alertDescription := fmt.Sprintf("Postgres instance: '%s' (strategy: %s) for product: %s is unavailable", cr.Name, cr.Status.Strategy, productName)
+	labels := map[string]string{
+		"severity":    alertSeverity,
+		"productName": productName,
+		"product":     installationName,
+	}
+	if _, err := reconcilePrometheusRule(ctx, client, fmt.Sprintf("availability-rule-%s", cr.Name), ruleNs, alertName, alertDescription, sopURL, alertFor5Mins, alertExp, labels); err != nil {
+		return v1alpha1.PhaseFailed, fmt.Errorf("failed to create postgres prometheus alert for %s: %w", cr.Name, err)
+	}
+
+	if cr.Status.Phase != cro1types.PhaseComplete {
+		return v1alpha1.PhaseAwaitingComponents, nil
+	}
+
+	volumeSelector := fmt.Sprintf("namespace='%s',persistentvolumeclaim='%s'", cr.Namespace, clusterstorage.DataClaimName(cr.Name))
+	storageAlerts := []struct {
+		alertName   string
+		ruleName    string
+		description string
+		severity    string
+		sopURL      string
+		alertFor    string
+		expr        string
+	}{
+		{
+			alertName:   "PostgresStorageWillFillIn4Hours",
+			ruleName:    "postgres-storage-will-fill-in-4-hours",
+			description: "will run of disk space in the next 4 hours",
+			severity:    "critical",
+			sopURL:      sopUrlRhoamBase + "PostgresStorageWillFillIn4Hours.asciidoc",
+			alertFor:    alertFor60Mins,
+			// same projection as the cloud provider instances, see reconcilePostgresFreeStorageAlerts
+			expr: fmt.Sprintf("predict_linear(kubelet_volume_stats_available_bytes{%s}[1h], 5 * 3600) <= 0 and kubelet_volume_stats_available_bytes{%s} / kubelet_volume_stats_capacity_bytes{%s} < 0.25", volumeSelector, volumeSelector, volumeSelector),
+		},
+		{
+			alertName:   "PostgresStorageWillFillIn4Days",
+			ruleName:    "postgres-storage-will-fill-in-4-days",
+			description: "will run of disk space in the next 4 days",
+			severity:    "warning",
+			sopURL:      sopUrlPostgresWillFill,
+			alertFor:    alertFor60Mins,
+			expr:        fmt.Sprintf("predict_linear(kubelet_volume_stats_available_bytes{%s}[6h], 4 * 24 * 3600) <= 0 and kubelet_volume_stats_available_bytes{%s} / kubelet_volume_stats_capacity_bytes{%s} < 0.25", volumeSelector, volumeSelector, volumeSelector),
+		},
+		{
+			alertName:   "PostgresStorageLow",
+			ruleName:    "postgres-storage-low",
+			description: "storage is currently under 10 percent of its capacity",
+			severity:    "warning",
+			sopURL:      sopUrlPostgresWillFill,
+			alertFor:    alertFor30Mins,
+			expr:        fmt.Sprintf("kubelet_volume_stats_available_bytes{%s} / kubelet_volume_stats_capacity_bytes{%s} < 0.10", volumeSelector, volumeSelector),
+		},
+	}
+	for _, alert := range storageAlerts {
+		description := fmt.Sprintf("The postgres instance %s for product %s %s", cr.Name, productName, alert.description)
+		labels := map[string]string{
+			"severity":    alert.severity,
+			"productName": productName,
+			"product":     installationName,
+		}
+		ruleName := fmt.Sprintf("%s-%s", alert.ruleName, cr.Name)
+		if _, err := reconcilePrometheusRule(ctx, client, ruleName, ruleNs, alert.alertName, description, alert.sopURL, alert.alertFor, intstr.FromString(alert.expr), labels); err != nil {
+			return v1alpha1.PhaseFailed, fmt.Errorf("failed to create postgres free storage prometheus alerts for %s: %w", cr.Name, err)
+		}
+	}
+
+	log.Infof("Reconciled in-cluster postgres alerts", l.Fields{"postgres": cr.Name})
+	return v1alpha1.PhaseCompleted, nil
+}
+
+func reconcileClusterStorageRedisAlerts(ctx context.Context, client k8sclient.Client, inst *v1alpha1.RHMI, cr *crov1.Redis, log l.Logger) (v1alpha1.StatusPhase, error) {
+	productName := cr.Labels["productName"]
+	redisCRName := strings.Title(strings.Replace(cr.Name, "redis-example-rhmi", "", -1))
+
+	alertName := redisCRName + "RedisCacheUnavailable"
+	sopURL := sopUrlRhoamBase + alertName + ".asciidoc"
+	alertSeverity := "critical"
+	if productName == "marin3r" {
+		// same severity as the cloud provider instances, see createRedisAvailabilityAlert
+		alertSeverity = "warning"
+		sopURL = sopUrlRedisCacheUnavailable
+	}
+	alertExp := intstr.FromString(
+		fmt.Sprintf("absent(kube_statefulset_status_replicas_ready{namespace='%s',statefulset='%s'} >= 1)", cr.Namespace, cr.Name),
+	)
+	alertDescription := fmt.Sprintf("Redis instance: '%s' (strategy: %s) for the product: %s is unavailable", cr.Name, cr.Status.Strategy, productName)
+	labels := map[string]string{
+		"severity":    alertSeverity,
+		"productName": productName,
+	}
+	ruleNs := inst.Spec.NamespacePrefix + "observability"
+	if _, err := reconcilePrometheusRule(ctx, client, fmt.Sprintf("availability-rule-%s", cr.Name), ruleNs, alertName, alertDescription, sopURL, alertFor5Mins, alertExp, labels); err != nil {
+		return v1alpha1.PhaseFailed, fmt.Errorf("failed to create redis prometheus alert for %s: %w", cr.Name, err)
+	}
+
+	if cr.Status.Phase != cro1types.PhaseComplete {
+		return v1alpha1.PhaseAwaitingComponents, nil
+	}
+
+	log.Infof("Reconciled in-cluster redis alerts", l.Fields{"redis": cr.Name})
+	return v1alpha1.PhaseCompleted, nil
+}
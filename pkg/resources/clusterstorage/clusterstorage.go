@@ -0,0 +1,342 @@
+// Package clusterstorage provisions Postgres and Redis instances in the
+// cluster, for installs that can't use the cloud provider services, such as
+// self-managed and disconnected installs.
+//
+// Each instance is a single replica StatefulSet with a persistent volume, a
+// headless Service, a Secret with the connection details in the same format
+// as the cloud resource operator, and a CronJob that backs the instance up to
+// a separate persistent volume.
+//
+// The Postgres and Redis custom resources returned are not persisted, they
+// describe the in-cluster instance with the same fields the cloud resource
+// operator sets, so that the connection secrets and alerts are reconciled the
+// same way for both providers
+package clusterstorage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	croTypes "github.com/integr8ly/cloud-resource-operator/apis/integreatly/v1alpha1/types"
+	productsConfig "github.com/integr8ly/integreatly-operator/pkg/config"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/backup"
+	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// Strategy is set as the strategy of the in-cluster Postgres and Redis
+	// custom resources
+	Strategy = "in-cluster"
+
+	// DataVolumeName is the name of the volume claim template of the
+	// StatefulSets. The claim of an instance is data-<name>-0
+	DataVolumeName = "data"
+
+	backupVolumeName  = "backup"
+	backupMountPath   = "/backup"
+	backupSchedule    = "0 3 * * *"
+	backupRetainDays  = 7
+	backupSuffix      = "-backup"
+	backupStorageSize = "10Gi"
+)
+
+// ModifyResourceFunc is called on every resource of the instance before it's
+// created or updated, e.g. to add owner annotations
+type ModifyResourceFunc func(cr metav1.Object) error
+
+var log = l.NewLoggerWithContext(l.Fields{l.ComponentLogContext: "clusterstorage"})
+
+// instance holds what differs between the Postgres and Redis StatefulSets
+type instance struct {
+	name        string
+	namespace   string
+	productName string
+	container   string
+	image       string
+	port        int32
+	dataPath    string
+	storageSize string
+	env         []corev1.EnvVar
+	backupCmd   string
+}
+
+// DataClaimName returns the name of the persistent volume claim holding the
+// data of the instance
+func DataClaimName(name string) string {
+	return fmt.Sprintf("%s-%s-0", DataVolumeName, name)
+}
+
+// BackupCronJobName returns the name of the CronJob that backs up the
+// instance
+func BackupCronJobName(name string) string {
+	return name + backupSuffix
+}
+
+// Host returns the host name of the instance
+func Host(name, namespace string) string {
+	return fmt.Sprintf("%s.%s.svc", name, namespace)
+}
+
+func (i *instance) labels() map[string]string {
+	return map[string]string{
+		"app":         i.name,
+		"productName": i.productName,
+	}
+}
+
+func reconcileService(ctx context.Context, client k8sclient.Client, i *instance, modifyFunc ModifyResourceFunc) error {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      i.name,
+			Namespace: i.namespace,
+		},
+	}
+	if _, err := controllerutil.CreateOrUpdate(ctx, client, service, func() error {
+		if err := modifyFunc(service); err != nil {
+			return err
+		}
+		service.Labels = i.labels()
+		// the cluster IP is immutable, it's only set on creation
+		if service.CreationTimestamp.IsZero() {
+			service.Spec.ClusterIP = corev1.ClusterIPNone
+		}
+		service.Spec.Selector = map[string]string{"app": i.name}
+		service.Spec.Ports = []corev1.ServicePort{
+			{
+				Name:       i.name,
+				Port:       i.port,
+				TargetPort: intstr.FromInt(int(i.port)),
+			},
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to reconcile service %s: %w", i.name, err)
+	}
+	return nil
+}
+
+func reconcileStatefulSet(ctx context.Context, client k8sclient.Client, i *instance, modifyFunc ModifyResourceFunc) (*appsv1.StatefulSet, error) {
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      i.name,
+			Namespace: i.namespace,
+		},
+	}
+	if _, err := controllerutil.CreateOrUpdate(ctx, client, statefulSet, func() error {
+		if err := modifyFunc(statefulSet); err != nil {
+			return err
+		}
+		statefulSet.Labels = i.labels()
+
+		// the selector, service name and volume claim templates are
+		// immutable, they're only set on creation
+		if statefulSet.CreationTimestamp.IsZero() {
+			statefulSet.Spec.ServiceName = i.name
+			statefulSet.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": i.name}}
+			statefulSet.Spec.VolumeClaimTemplates = []corev1.PersistentVolumeClaim{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   DataVolumeName,
+						Labels: i.labels(),
+					},
+					Spec: corev1.PersistentVolumeClaimSpec{
+						AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceStorage: resource.MustParse(i.storageSize),
+							},
+						},
+					},
+				},
+			}
+		}
+
+		replicas := int32(1)
+		statefulSet.Spec.Replicas = &replicas
+		statefulSet.Spec.Template.Labels = i.labels()
+		statefulSet.Spec.Template.Spec.Containers = []corev1.Container{
+			{
+				Name:  i.container,
+				Image: i.image,
+				Ports: []corev1.ContainerPort{
+					{
+						ContainerPort: i.port,
+						Protocol:      corev1.ProtocolTCP,
+					},
+				},
+				Env: i.env,
+				VolumeMounts: []corev1.VolumeMount{
+					{
+						Name:      DataVolumeName,
+						MountPath: i.dataPath,
+					},
+				},
+				ReadinessProbe: &corev1.Probe{
+					Handler: corev1.Handler{
+						TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt(int(i.port))},
+					},
+					InitialDelaySeconds: 5,
+					PeriodSeconds:       10,
+				},
+				LivenessProbe: &corev1.Probe{
+					Handler: corev1.Handler{
+						TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt(int(i.port))},
+					},
+					InitialDelaySeconds: 30,
+					PeriodSeconds:       10,
+				},
+			},
+		}
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to reconcile stateful set %s: %w", i.name, err)
+	}
+	return statefulSet, nil
+}
+
+// reconcileBackup reconciles the volume the backups are written to, and the
+// CronJob that writes them and removes the ones older than backupRetainDays
+func reconcileBackup(ctx context.Context, client k8sclient.Client, i *instance, modifyFunc ModifyResourceFunc) error {
+	claim := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      BackupCronJobName(i.name),
+			Namespace: i.namespace,
+		},
+	}
+	if _, err := controllerutil.CreateOrUpdate(ctx, client, claim, func() error {
+		if err := modifyFunc(claim); err != nil {
+			return err
+		}
+		claim.Labels = i.labels()
+		// the claim spec is immutable, it's only set on creation
+		if claim.CreationTimestamp.IsZero() {
+			claim.Spec.AccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+			claim.Spec.Resources.Requests = corev1.ResourceList{
+				corev1.ResourceStorage: resource.MustParse(backupStorageSize),
+			}
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to reconcile backup volume claim for %s: %w", i.name, err)
+	}
+
+	monitoringConfig := productsConfig.NewMonitoring(productsConfig.ProductConfig{})
+	cronJob := &batchv1beta1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      BackupCronJobName(i.name),
+			Namespace: i.namespace,
+		},
+	}
+	if _, err := controllerutil.CreateOrUpdate(ctx, client, cronJob, func() error {
+		if err := modifyFunc(cronJob); err != nil {
+			return err
+		}
+		cronJob.Labels = map[string]string{"integreatly": "yes", monitoringConfig.GetLabelSelectorKey(): monitoringConfig.GetLabelSelector()}
+		cronJob.Spec = batchv1beta1.CronJobSpec{
+			Schedule:          backupSchedule,
+			ConcurrencyPolicy: batchv1beta1.ForbidConcurrent,
+			JobTemplate: batchv1beta1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"integreatly": "yes", "cronjob-name": BackupCronJobName(i.name), monitoringConfig.GetLabelSelectorKey(): monitoringConfig.GetLabelSelector()},
+				},
+				Spec: batchv1.JobSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: map[string]string{"integreatly": "yes", "cronjob-name": BackupCronJobName(i.name)},
+						},
+						Spec: corev1.PodSpec{
+							RestartPolicy: corev1.RestartPolicyOnFailure,
+							Containers: []corev1.Container{
+								{
+									Name:    "backup",
+									Image:   i.image,
+									Command: []string{"/bin/sh", "-c", fmt.Sprintf("%s && find %s -type f -mtime +%d -delete", i.backupCmd, backupMountPath, backupRetainDays)},
+									Env:     i.env,
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      backupVolumeName,
+											MountPath: backupMountPath,
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: backupVolumeName,
+									VolumeSource: corev1.VolumeSource{
+										PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+											ClaimName: BackupCronJobName(i.name),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to reconcile backup cron job for %s: %w", i.name, err)
+	}
+	return nil
+}
+
+// phase returns the phase of the instance from the StatefulSet status
+func phase(statefulSet *appsv1.StatefulSet) croTypes.StatusPhase {
+	if statefulSet.Status.ReadyReplicas < 1 {
+		return croTypes.PhaseInProgress
+	}
+	return croTypes.PhaseComplete
+}
+
+func generatePassword() (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// backupExecutor performs a backup through the backup CronJob of an
+// in-cluster instance. Instances provisioned by the cloud resource operator
+// before the in-cluster provider was introduced have no backup CronJob, in
+// which case no backup is performed
+type backupExecutor struct {
+	name      string
+	namespace string
+}
+
+// NewBackupExecutor returns a backup executor for the in-cluster instance
+func NewBackupExecutor(name, namespace string) backup.BackupExecutor {
+	return &backupExecutor{
+		name:      name,
+		namespace: namespace,
+	}
+}
+
+func (e *backupExecutor) PerformBackup(client k8sclient.Client, timeout time.Duration) error {
+	cronJob := &batchv1beta1.CronJob{}
+	if err := client.Get(context.TODO(), k8sclient.ObjectKey{Name: BackupCronJobName(e.name), Namespace: e.namespace}, cronJob); err != nil {
+		if k8serr.IsNotFound(err) {
+			log.Infof("No backup cron job found, skipping backup", l.Fields{"instance": e.name, "ns": e.namespace})
+			return nil
+		}
+		return fmt.Errorf("failed to get backup cron job for %s: %w", e.name, err)
+	}
+
+	return backup.NewCronJobBackupExecutor(BackupCronJobName(e.name), e.namespace, BackupCronJobName(e.name)).PerformBackup(client, timeout)
+}
@@ -0,0 +1,168 @@
+package clusterstorage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	croTypes "github.com/integr8ly/cloud-resource-operator/apis/integreatly/v1alpha1/types"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	testNamespace = "redhat-rhoam-3scale"
+	testProduct   = "3scale"
+)
+
+func buildScheme(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	for _, addToScheme := range []func(*runtime.Scheme) error{
+		corev1.AddToScheme,
+		appsv1.AddToScheme,
+		batchv1beta1.AddToScheme,
+	} {
+		if err := addToScheme(scheme); err != nil {
+			t.Fatalf("failed to build scheme: %v", err)
+		}
+	}
+	return scheme
+}
+
+func noopModify(cr metav1.Object) error {
+	return nil
+}
+
+func TestReconcilePostgres(t *testing.T) {
+	name := "threescale-postgres-rhmi"
+
+	scenarios := []struct {
+		Name          string
+		InitObjs      []runtime.Object
+		ExpectedPhase croTypes.StatusPhase
+		Validate      func(*testing.T, k8sclient.Client)
+	}{
+		{
+			Name:          "test postgres instance is created and in progress",
+			ExpectedPhase: croTypes.PhaseInProgress,
+			Validate: func(t *testing.T, client k8sclient.Client) {
+				secret := &corev1.Secret{}
+				if err := client.Get(context.TODO(), k8sclient.ObjectKey{Name: name, Namespace: testNamespace}, secret); err != nil {
+					t.Fatalf("failed to get postgres secret: %v", err)
+				}
+				for _, key := range []string{"username", "password", "database", "host", "port"} {
+					if len(secret.Data[key]) == 0 {
+						t.Errorf("expected postgres secret to have key %s", key)
+					}
+				}
+				if string(secret.Data["host"]) != Host(name, testNamespace) {
+					t.Errorf("expected host %s, got %s", Host(name, testNamespace), secret.Data["host"])
+				}
+
+				service := &corev1.Service{}
+				if err := client.Get(context.TODO(), k8sclient.ObjectKey{Name: name, Namespace: testNamespace}, service); err != nil {
+					t.Fatalf("failed to get postgres service: %v", err)
+				}
+				if service.Spec.ClusterIP != corev1.ClusterIPNone {
+					t.Errorf("expected headless service, got cluster ip %s", service.Spec.ClusterIP)
+				}
+
+				statefulSet := &appsv1.StatefulSet{}
+				if err := client.Get(context.TODO(), k8sclient.ObjectKey{Name: name, Namespace: testNamespace}, statefulSet); err != nil {
+					t.Fatalf("failed to get postgres stateful set: %v", err)
+				}
+				if len(statefulSet.Spec.VolumeClaimTemplates) != 1 || statefulSet.Spec.VolumeClaimTemplates[0].Name != DataVolumeName {
+					t.Errorf("expected a single %s volume claim template", DataVolumeName)
+				}
+
+				cronJob := &batchv1beta1.CronJob{}
+				if err := client.Get(context.TODO(), k8sclient.ObjectKey{Name: BackupCronJobName(name), Namespace: testNamespace}, cronJob); err != nil {
+					t.Fatalf("failed to get postgres backup cron job: %v", err)
+				}
+				if cronJob.Labels["monitoring-key"] != "middleware" {
+					t.Errorf("expected backup cron job to be monitored, got labels %v", cronJob.Labels)
+				}
+			},
+		},
+		{
+			Name: "test existing password is kept and ready instance is complete",
+			InitObjs: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+					Data:       map[string][]byte{"password": []byte("existing")},
+				},
+				&appsv1.StatefulSet{
+					ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+					Status:     appsv1.StatefulSetStatus{ReadyReplicas: 1},
+				},
+			},
+			ExpectedPhase: croTypes.PhaseComplete,
+			Validate: func(t *testing.T, client k8sclient.Client) {
+				secret := &corev1.Secret{}
+				if err := client.Get(context.TODO(), k8sclient.ObjectKey{Name: name, Namespace: testNamespace}, secret); err != nil {
+					t.Fatalf("failed to get postgres secret: %v", err)
+				}
+				if string(secret.Data["password"]) != "existing" {
+					t.Errorf("expected password to be kept, got %s", secret.Data["password"])
+				}
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.Name, func(t *testing.T) {
+			client := fake.NewFakeClientWithScheme(buildScheme(t), scenario.InitObjs...)
+
+			postgres, err := ReconcilePostgres(context.TODO(), client, testProduct, name, testNamespace, noopModify)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if postgres.Status.Strategy != Strategy {
+				t.Errorf("expected strategy %s, got %s", Strategy, postgres.Status.Strategy)
+			}
+			if postgres.Status.Phase != scenario.ExpectedPhase {
+				t.Errorf("expected phase %s, got %s", scenario.ExpectedPhase, postgres.Status.Phase)
+			}
+			if postgres.Status.SecretRef == nil || postgres.Status.SecretRef.Name != name {
+				t.Errorf("expected secret ref %s, got %v", name, postgres.Status.SecretRef)
+			}
+			scenario.Validate(t, client)
+		})
+	}
+}
+
+func TestReconcileRedis(t *testing.T) {
+	name := "threescale-redis-rhmi"
+	client := fake.NewFakeClientWithScheme(buildScheme(t))
+
+	redis, err := ReconcileRedis(context.TODO(), client, testProduct, name, testNamespace, noopModify)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if redis.Status.Strategy != Strategy || redis.Status.Phase != croTypes.PhaseInProgress {
+		t.Errorf("unexpected redis status %v", redis.Status)
+	}
+
+	secret := &corev1.Secret{}
+	if err := client.Get(context.TODO(), k8sclient.ObjectKey{Name: name, Namespace: testNamespace}, secret); err != nil {
+		t.Fatalf("failed to get redis secret: %v", err)
+	}
+	if string(secret.Data["uri"]) != Host(name, testNamespace) || string(secret.Data["port"]) != "6379" {
+		t.Errorf("unexpected redis secret data %v", secret.Data)
+	}
+}
+
+func TestBackupExecutor_PerformBackup(t *testing.T) {
+	client := fake.NewFakeClientWithScheme(buildScheme(t))
+
+	// instances provisioned by the cloud resource operator have no backup
+	// cron job, the backup is skipped
+	if err := NewBackupExecutor("threescale-postgres-rhmi", testNamespace).PerformBackup(client, time.Second); err != nil {
+		t.Errorf("expected backup to be skipped, got error: %v", err)
+	}
+}
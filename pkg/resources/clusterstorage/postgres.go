@@ -0,0 +1,115 @@
+package clusterstorage
+
+import (
+	"context"
+	"fmt"
+
+	crov1 "github.com/integr8ly/cloud-resource-operator/apis/integreatly/v1alpha1"
+	croTypes "github.com/integr8ly/cloud-resource-operator/apis/integreatly/v1alpha1/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	PostgresImage = "registry.redhat.io/rhscl/postgresql-10-rhel7:1"
+
+	postgresPort        = 5432
+	postgresUser        = "rhmi"
+	postgresDatabase    = "rhmi"
+	postgresDataPath    = "/var/lib/pgsql/data"
+	postgresStorageSize = "10Gi"
+)
+
+// ReconcilePostgres provisions an in-cluster Postgres instance and returns a
+// Postgres custom resource describing it. The connection details are in a
+// Secret with the same name as the instance, with the username, password,
+// host, port and database keys set by the cloud resource operator
+func ReconcilePostgres(ctx context.Context, client k8sclient.Client, productName, name, ns string, modifyFunc ModifyResourceFunc) (*crov1.Postgres, error) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ns,
+		},
+	}
+	if _, err := controllerutil.CreateOrUpdate(ctx, client, secret, func() error {
+		if err := modifyFunc(secret); err != nil {
+			return err
+		}
+		secret.Labels = map[string]string{"productName": productName}
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		// the password is only generated once
+		if len(secret.Data["password"]) == 0 {
+			password, err := generatePassword()
+			if err != nil {
+				return fmt.Errorf("failed to generate password: %w", err)
+			}
+			secret.Data["password"] = []byte(password)
+		}
+		secret.Data["username"] = []byte(postgresUser)
+		secret.Data["database"] = []byte(postgresDatabase)
+		secret.Data["host"] = []byte(Host(name, ns))
+		secret.Data["port"] = []byte(fmt.Sprintf("%d", postgresPort))
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to reconcile postgres credentials for %s: %w", name, err)
+	}
+
+	envFromSecret := func(envName, key string) corev1.EnvVar {
+		return corev1.EnvVar{
+			Name: envName,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: name},
+					Key:                  key,
+				},
+			},
+		}
+	}
+	i := &instance{
+		name:        name,
+		namespace:   ns,
+		productName: productName,
+		container:   "postgresql",
+		image:       PostgresImage,
+		port:        postgresPort,
+		dataPath:    postgresDataPath,
+		storageSize: postgresStorageSize,
+		env: []corev1.EnvVar{
+			envFromSecret("POSTGRESQL_USER", "username"),
+			envFromSecret("POSTGRESQL_PASSWORD", "password"),
+			envFromSecret("POSTGRESQL_DATABASE", "database"),
+		},
+		backupCmd: fmt.Sprintf("PGPASSWORD=$POSTGRESQL_PASSWORD pg_dump -h %s -U $POSTGRESQL_USER -d $POSTGRESQL_DATABASE -Fc -f %s/%s-$(date +%%Y%%m%%d%%H%%M%%S).dump", Host(name, ns), backupMountPath, name),
+	}
+
+	if err := reconcileService(ctx, client, i, modifyFunc); err != nil {
+		return nil, err
+	}
+	statefulSet, err := reconcileStatefulSet(ctx, client, i, modifyFunc)
+	if err != nil {
+		return nil, err
+	}
+	if err := reconcileBackup(ctx, client, i, modifyFunc); err != nil {
+		return nil, err
+	}
+
+	return &crov1.Postgres{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ns,
+			Labels:    i.labels(),
+		},
+		Spec: croTypes.ResourceTypeSpec{
+			SecretRef: &croTypes.SecretRef{Name: name, Namespace: ns},
+		},
+		Status: croTypes.ResourceTypeStatus{
+			Strategy:  Strategy,
+			SecretRef: &croTypes.SecretRef{Name: name, Namespace: ns},
+			Phase:     phase(statefulSet),
+		},
+	}, nil
+}
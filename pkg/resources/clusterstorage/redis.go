@@ -0,0 +1,86 @@
+package clusterstorage
+
+import (
+	"context"
+	"fmt"
+
+	crov1 "github.com/integr8ly/cloud-resource-operator/apis/integreatly/v1alpha1"
+	croTypes "github.com/integr8ly/cloud-resource-operator/apis/integreatly/v1alpha1/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	RedisImage = "registry.redhat.io/rhscl/redis-5-rhel7:5"
+
+	redisPort        = 6379
+	redisDataPath    = "/var/lib/redis/data"
+	redisStorageSize = "1Gi"
+)
+
+// ReconcileRedis provisions an in-cluster Redis instance and returns a Redis
+// custom resource describing it. The connection details are in a Secret with
+// the same name as the instance, with the uri and port keys set by the cloud
+// resource operator
+func ReconcileRedis(ctx context.Context, client k8sclient.Client, productName, name, ns string, modifyFunc ModifyResourceFunc) (*crov1.Redis, error) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ns,
+		},
+	}
+	if _, err := controllerutil.CreateOrUpdate(ctx, client, secret, func() error {
+		if err := modifyFunc(secret); err != nil {
+			return err
+		}
+		secret.Labels = map[string]string{"productName": productName}
+		secret.Data = map[string][]byte{
+			"uri":  []byte(Host(name, ns)),
+			"port": []byte(fmt.Sprintf("%d", redisPort)),
+		}
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to reconcile redis credentials for %s: %w", name, err)
+	}
+
+	i := &instance{
+		name:        name,
+		namespace:   ns,
+		productName: productName,
+		container:   "redis",
+		image:       RedisImage,
+		port:        redisPort,
+		dataPath:    redisDataPath,
+		storageSize: redisStorageSize,
+		backupCmd:   fmt.Sprintf("redis-cli -h %s --rdb %s/%s-$(date +%%Y%%m%%d%%H%%M%%S).rdb", Host(name, ns), backupMountPath, name),
+	}
+
+	if err := reconcileService(ctx, client, i, modifyFunc); err != nil {
+		return nil, err
+	}
+	statefulSet, err := reconcileStatefulSet(ctx, client, i, modifyFunc)
+	if err != nil {
+		return nil, err
+	}
+	if err := reconcileBackup(ctx, client, i, modifyFunc); err != nil {
+		return nil, err
+	}
+
+	return &crov1.Redis{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ns,
+			Labels:    i.labels(),
+		},
+		Spec: croTypes.ResourceTypeSpec{
+			SecretRef: &croTypes.SecretRef{Name: name, Namespace: ns},
+		},
+		Status: croTypes.ResourceTypeStatus{
+			Strategy:  Strategy,
+			SecretRef: &croTypes.SecretRef{Name: name, Namespace: ns},
+			Phase:     phase(statefulSet),
+		},
+	}, nil
+}
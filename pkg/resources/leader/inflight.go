@@ -0,0 +1,61 @@
+// Package leader supports running several replicas of the operator, of which
+// only the leader of the leader election reconciles. When the leader shuts
+// down, the reconciles in flight are drained before the manager cancels the
+// leader election, and the lease is released so another replica takes over
+// without waiting for it to expire
+package leader
+
+import (
+	"sync"
+
+	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+)
+
+var log = l.NewLoggerWithContext(l.Fields{l.ControllerLogContext: "leader_election"})
+
+// InFlight tracks the reconciles in flight. It's a runnable of the manager
+// that, once the manager stops, refuses new reconciles and returns when the
+// reconciles in flight are done. The manager waits for its runnables up to
+// its graceful shutdown timeout before it cancels the leader election
+type InFlight struct {
+	mu       sync.Mutex
+	stopping bool
+	running  sync.WaitGroup
+}
+
+// NewInFlight returns a tracker without reconciles in flight
+func NewInFlight() *InFlight {
+	return &InFlight{}
+}
+
+// Begin marks the start of a reconcile. It returns false once the shutdown
+// started, in which case the reconcile must not run. Every successful Begin
+// is followed by a Done
+func (f *InFlight) Begin() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.stopping {
+		return false
+	}
+	f.running.Add(1)
+	return true
+}
+
+// Done marks the end of a reconcile
+func (f *InFlight) Done() {
+	f.running.Done()
+}
+
+// Start waits for stop, then for the reconciles in flight to be done
+func (f *InFlight) Start(stop <-chan struct{}) error {
+	<-stop
+
+	f.mu.Lock()
+	f.stopping = true
+	f.mu.Unlock()
+
+	log.Info("Waiting for the reconciles in flight before releasing leadership")
+	f.running.Wait()
+	log.Info("Reconciles in flight drained")
+	return nil
+}
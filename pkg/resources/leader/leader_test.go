@@ -0,0 +1,125 @@
+package leader
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestInFlight(t *testing.T) {
+	inFlight := NewInFlight()
+	if !inFlight.Begin() {
+		t.Fatalf("expected a reconcile to begin before the shutdown")
+	}
+
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		if err := inFlight.Start(stop); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		close(stopped)
+	}()
+	close(stop)
+
+	// wait for the shutdown to refuse new reconciles
+	for i := 0; i < 100 && inFlight.Begin(); i++ {
+		inFlight.Done()
+		time.Sleep(10 * time.Millisecond)
+	}
+	if inFlight.Begin() {
+		t.Fatalf("expected reconciles to be refused once the shutdown started")
+	}
+
+	select {
+	case <-stopped:
+		t.Fatalf("expected the shutdown to wait for the reconcile in flight")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	inFlight.Done()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatalf("expected the shutdown to complete once the reconcile is done")
+	}
+}
+
+func getLock(holder string) *corev1.ConfigMap {
+	record, _ := json.Marshal(resourcelock.LeaderElectionRecord{
+		HolderIdentity:       holder,
+		LeaseDurationSeconds: 15,
+		LeaderTransitions:    3,
+	})
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "28185cee.integreatly.org",
+			Namespace: "redhat-rhoam-operator",
+			Annotations: map[string]string{
+				resourcelock.LeaderElectionRecordAnnotationKey: string(record),
+			},
+		},
+	}
+}
+
+func TestReleaseLease(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		Name           string
+		Objects        []runtime.Object
+		ExpectedHolder string
+	}{
+		{
+			Name:           "test lease held by the replica is released",
+			Objects:        []runtime.Object{getLock("rhmi-operator-abc_5f0c")},
+			ExpectedHolder: "",
+		},
+		{
+			Name:           "test lease held by another replica is kept",
+			Objects:        []runtime.Object{getLock("rhmi-operator-def_8a2b")},
+			ExpectedHolder: "rhmi-operator-def_8a2b",
+		},
+		{
+			Name: "test missing lock",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			client := fakeclient.NewFakeClientWithScheme(scheme, tt.Objects...)
+			if err := releaseLease(context.TODO(), client, "redhat-rhoam-operator", "28185cee.integreatly.org", "rhmi-operator-abc"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(tt.Objects) == 0 {
+				return
+			}
+
+			lock := &corev1.ConfigMap{}
+			if err := client.Get(context.TODO(), k8sclient.ObjectKey{Name: "28185cee.integreatly.org", Namespace: "redhat-rhoam-operator"}, lock); err != nil {
+				t.Fatal(err)
+			}
+			record := resourcelock.LeaderElectionRecord{}
+			if err := json.Unmarshal([]byte(lock.Annotations[resourcelock.LeaderElectionRecordAnnotationKey]), &record); err != nil {
+				t.Fatal(err)
+			}
+			if record.HolderIdentity != tt.ExpectedHolder {
+				t.Errorf("expected holder %q, got %q", tt.ExpectedHolder, record.HolderIdentity)
+			}
+			if record.LeaderTransitions != 3 {
+				t.Errorf("expected the leader transitions to be kept, got %d", record.LeaderTransitions)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package leader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+	corev1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ReleaseLease releases the leader election lease held by this replica in the
+// config map lock of the manager, so the other replicas acquire it on their
+// next retry instead of waiting for it to expire. The manager doesn't release
+// the lease itself, it stops renewing it. The lease is released only when it's
+// held by this replica, which the manager identifies by its hostname
+func ReleaseLease(ctx context.Context, client k8sclient.Client, namespace, id string) error {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return err
+	}
+	return releaseLease(ctx, client, namespace, id, hostname)
+}
+
+func releaseLease(ctx context.Context, client k8sclient.Client, namespace, id, hostname string) error {
+	lock := &corev1.ConfigMap{}
+	if err := client.Get(ctx, k8sclient.ObjectKey{Name: id, Namespace: namespace}, lock); err != nil {
+		if k8serr.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get leader election lock %s: %w", id, err)
+	}
+
+	annotation, ok := lock.Annotations[resourcelock.LeaderElectionRecordAnnotationKey]
+	if !ok {
+		return nil
+	}
+	record := resourcelock.LeaderElectionRecord{}
+	if err := json.Unmarshal([]byte(annotation), &record); err != nil {
+		return fmt.Errorf("invalid leader election record in lock %s: %w", id, err)
+	}
+	// the identity of the manager is its hostname followed by a random suffix
+	if !strings.HasPrefix(record.HolderIdentity, hostname+"_") {
+		return nil
+	}
+
+	now := metav1.Now()
+	released, err := json.Marshal(resourcelock.LeaderElectionRecord{
+		LeaseDurationSeconds: 1,
+		AcquireTime:          now,
+		RenewTime:            now,
+		LeaderTransitions:    record.LeaderTransitions,
+	})
+	if err != nil {
+		return err
+	}
+	lock.Annotations[resourcelock.LeaderElectionRecordAnnotationKey] = string(released)
+	if err := client.Update(ctx, lock); err != nil {
+		return fmt.Errorf("failed to release leader election lock %s: %w", id, err)
+	}
+
+	log.Infof("Released leader election lease", l.Fields{"holder": record.HolderIdentity, "lock": id})
+	return nil
+}
@@ -0,0 +1,121 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	logrus "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+
+	// LevelConfigMapName is the config map in the operator namespace the log
+	// level is read from, in its level key. The level set at startup is
+	// restored when the config map is deleted
+	LevelConfigMapName = "rhmi-operator-logging"
+	levelKey           = "level"
+
+	DefaultLevelWatchInterval = 30 * time.Second
+)
+
+// SetFormat sets the format of the logs, text or json. JSON logs have one
+// object per line, with the fields of the logger as keys
+func SetFormat(format string) error {
+	switch format {
+	case FormatText:
+		logrus.SetFormatter(&logrus.TextFormatter{
+			ForceColors:      true,
+			FullTimestamp:    true,
+			QuoteEmptyFields: false,
+		})
+	case FormatJSON:
+		logrus.SetFormatter(&logrus.JSONFormatter{
+			TimestampFormat: time.RFC3339Nano,
+		})
+	default:
+		return fmt.Errorf("invalid log format %q, expected %s or %s", format, FormatText, FormatJSON)
+	}
+	return nil
+}
+
+// SetLevel sets the level of the logs, one of the logrus levels such as debug
+// or info
+func SetLevel(level string) error {
+	parsed, err := logrus.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	logrus.SetLevel(parsed)
+	return nil
+}
+
+// LevelWatcher sets the log level from the LevelConfigMapName config map,
+// polled so the level can be changed without restarting the operator. It
+// runs in every replica of the operator
+type LevelWatcher struct {
+	reader       k8sclient.Reader
+	namespace    string
+	interval     time.Duration
+	defaultLevel logrus.Level
+}
+
+// NewLevelWatcher returns a watcher of the config map in namespace. The
+// current level is restored when the config map doesn't exist
+func NewLevelWatcher(reader k8sclient.Reader, namespace string, interval time.Duration) *LevelWatcher {
+	return &LevelWatcher{
+		reader:       reader,
+		namespace:    namespace,
+		interval:     interval,
+		defaultLevel: logrus.GetLevel(),
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, the level is
+// set in every replica
+func (w *LevelWatcher) NeedLeaderElection() bool {
+	return false
+}
+
+// Start polls the config map until stop is closed
+func (w *LevelWatcher) Start(stop <-chan struct{}) error {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		if err := w.Check(context.TODO()); err != nil {
+			logrus.WithField(ComponentLogContext, "log_level").WithError(err).Warning("Failed to read the log level")
+		}
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check sets the log level from the config map
+func (w *LevelWatcher) Check(ctx context.Context) error {
+	configMap := &corev1.ConfigMap{}
+	level := w.defaultLevel.String()
+	if err := w.reader.Get(ctx, k8sclient.ObjectKey{Name: LevelConfigMapName, Namespace: w.namespace}, configMap); err != nil {
+		if !k8serr.IsNotFound(err) {
+			return fmt.Errorf("failed to get config map %s: %w", LevelConfigMapName, err)
+		}
+	} else if configured, ok := configMap.Data[levelKey]; ok {
+		level = configured
+	}
+
+	if level == logrus.GetLevel().String() {
+		return nil
+	}
+	if err := SetLevel(level); err != nil {
+		return fmt.Errorf("invalid level in config map %s: %w", LevelConfigMapName, err)
+	}
+	logrus.WithField(ComponentLogContext, "log_level").Infof("Log level set to %s", level)
+	return nil
+}
@@ -0,0 +1,82 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	logrus "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSetFormat(t *testing.T) {
+	defer SetFormat(FormatText)
+
+	if err := SetFormat(FormatJSON); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := logrus.StandardLogger().Formatter.(*logrus.JSONFormatter); !ok {
+		t.Errorf("expected the json formatter to be set")
+	}
+	if err := SetFormat("xml"); err == nil {
+		t.Errorf("expected an error for an invalid format")
+	}
+}
+
+func TestLevelWatcher(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	defer logrus.SetLevel(logrus.GetLevel())
+	logrus.SetLevel(logrus.InfoLevel)
+
+	getConfigMap := func(level string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      LevelConfigMapName,
+				Namespace: "redhat-rhoam-operator",
+			},
+			Data: map[string]string{levelKey: level},
+		}
+	}
+
+	tests := []struct {
+		Name          string
+		Objects       []runtime.Object
+		ExpectedLevel logrus.Level
+		ExpectError   bool
+	}{
+		{
+			Name:          "test level set from the config map",
+			Objects:       []runtime.Object{getConfigMap("debug")},
+			ExpectedLevel: logrus.DebugLevel,
+		},
+		{
+			Name:          "test default level restored without the config map",
+			ExpectedLevel: logrus.InfoLevel,
+		},
+		{
+			Name:          "test invalid level is ignored",
+			Objects:       []runtime.Object{getConfigMap("verbose")},
+			ExpectedLevel: logrus.InfoLevel,
+			ExpectError:   true,
+		},
+	}
+
+	watcher := NewLevelWatcher(nil, "redhat-rhoam-operator", DefaultLevelWatchInterval)
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			watcher.reader = fakeclient.NewFakeClientWithScheme(scheme, tt.Objects...)
+			err := watcher.Check(context.TODO())
+			if (err != nil) != tt.ExpectError {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if logrus.GetLevel() != tt.ExpectedLevel {
+				t.Errorf("expected level %s, got %s", tt.ExpectedLevel, logrus.GetLevel())
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"context"
+	"net/http"
+
+	"k8s.io/apimachinery/pkg/util/uuid"
+)
+
+// RequestIDHeader is the header the reconcile ID is sent in with the requests
+// made during a reconcile
+const RequestIDHeader = "X-Request-ID"
+
+type fieldsKey struct{}
+
+// NewReconcileID returns a new ID to correlate the logs and requests of a
+// reconcile
+func NewReconcileID() string {
+	return string(uuid.NewUUID())
+}
+
+// ContextWithFields returns a context carrying fields, added to the fields
+// of ctx. Loggers add the fields of the context with ForContext
+func ContextWithFields(ctx context.Context, fields Fields) context.Context {
+	merged := Fields{}
+	for key, value := range FieldsFromContext(ctx) {
+		merged[key] = value
+	}
+	for key, value := range fields {
+		merged[key] = value
+	}
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// FieldsFromContext returns the fields carried by ctx
+func FieldsFromContext(ctx context.Context) Fields {
+	if ctx == nil {
+		return Fields{}
+	}
+	fields, ok := ctx.Value(fieldsKey{}).(Fields)
+	if !ok {
+		return Fields{}
+	}
+	return fields
+}
+
+// ReconcileID returns the reconcile ID carried by ctx, or an empty string
+func ReconcileID(ctx context.Context) string {
+	id, _ := FieldsFromContext(ctx)[ReconcileIDLogContext].(string)
+	return id
+}
+
+type requestIDTransport struct {
+	base        http.RoundTripper
+	reconcileID string
+}
+
+// NewTransport returns a transport that sends the reconcile ID in the
+// RequestIDHeader of the requests, so the requests of a reconcile can be
+// found in the logs of the services it calls. The ID is read from the
+// context of the request, or else from ctx. The base transport defaults to
+// http.DefaultTransport.
+//
+// Clients that don't accept a transport, such as the Keycloak client of the
+// keycloak-operator KeycloakClientFactory, don't send the reconcile ID
+func NewTransport(ctx context.Context, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &requestIDTransport{base: base, reconcileID: ReconcileID(ctx)}
+}
+
+func (t *requestIDTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	id := ReconcileID(request.Context())
+	if id == "" {
+		id = t.reconcileID
+	}
+	if id != "" && request.Header.Get(RequestIDHeader) == "" {
+		// a round tripper must not modify the request
+		request = request.Clone(request.Context())
+		request.Header.Set(RequestIDHeader, id)
+	}
+	return t.base.RoundTrip(request)
+}
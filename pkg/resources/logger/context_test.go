@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestContextWithFields(t *testing.T) {
+	ctx := ContextWithFields(context.TODO(), Fields{ReconcileIDLogContext: "abc", InstallationLogContext: "rhoam"})
+	ctx = ContextWithFields(ctx, Fields{StageLogContext: "installation"})
+
+	fields := FieldsFromContext(ctx)
+	if len(fields) != 3 || fields[StageLogContext] != "installation" {
+		t.Errorf("expected the fields of the contexts to be merged, got %v", fields)
+	}
+	if ReconcileID(ctx) != "abc" {
+		t.Errorf("expected reconcile ID abc, got %q", ReconcileID(ctx))
+	}
+	if ReconcileID(context.TODO()) != "" {
+		t.Errorf("expected no reconcile ID in an empty context")
+	}
+}
+
+func TestTransport(t *testing.T) {
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get(RequestIDHeader)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		Name       string
+		ClientCtx  context.Context
+		RequestCtx context.Context
+		ExpectedID string
+	}{
+		{
+			Name:       "test reconcile ID of the client",
+			ClientCtx:  ContextWithFields(context.TODO(), Fields{ReconcileIDLogContext: "client"}),
+			RequestCtx: context.TODO(),
+			ExpectedID: "client",
+		},
+		{
+			Name:       "test reconcile ID of the request takes precedence",
+			ClientCtx:  ContextWithFields(context.TODO(), Fields{ReconcileIDLogContext: "client"}),
+			RequestCtx: ContextWithFields(context.TODO(), Fields{ReconcileIDLogContext: "request"}),
+			ExpectedID: "request",
+		},
+		{
+			Name:       "test no reconcile ID",
+			ClientCtx:  context.TODO(),
+			RequestCtx: context.TODO(),
+			ExpectedID: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			received = ""
+			client := &http.Client{Transport: NewTransport(tt.ClientCtx, nil)}
+			request, err := http.NewRequestWithContext(tt.RequestCtx, http.MethodGet, server.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			response, err := client.Do(request)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			response.Body.Close()
+
+			if received != tt.ExpectedID {
+				t.Errorf("expected request ID %q, got %q", tt.ExpectedID, received)
+			}
+			if request.Header.Get(RequestIDHeader) != "" {
+				t.Errorf("expected the request of the caller not to be modified")
+			}
+		})
+	}
+}
@@ -1,14 +1,19 @@
 package logger
 
 import (
+	"context"
+
 	logrus "github.com/sirupsen/logrus"
 )
 
+// Standard keys of the log fields
 const (
-	ControllerLogContext = "controller"
-	StageLogContext      = "stage"
-	ProductLogContext    = "product"
-	ComponentLogContext  = "component"
+	ControllerLogContext   = "controller"
+	InstallationLogContext = "installation"
+	StageLogContext        = "stage"
+	ProductLogContext      = "product"
+	ComponentLogContext    = "component"
+	ReconcileIDLogContext  = "reconcile_id"
 )
 
 type Logger struct {
@@ -33,6 +38,19 @@ func (l Logger) WithContext(fields Fields) *logrus.Entry {
 	return l.Logger.WithFields(logrus.Fields(fields))
 }
 
+// WithFields returns a logger that adds fields to the fields of l
+func (l Logger) WithFields(fields Fields) Logger {
+	return Logger{
+		Logger: l.Logger.WithFields(logrus.Fields(fields)),
+	}
+}
+
+// ForContext returns a logger that adds the fields of ctx, such as the
+// reconcile ID, to the fields of l
+func (l Logger) ForContext(ctx context.Context) Logger {
+	return l.WithFields(FieldsFromContext(ctx))
+}
+
 func (l Logger) Infof(message string, fields map[string]interface{}) {
 	l.Logger.WithFields(fields).Info(message)
 }
@@ -31,6 +31,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	"github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/clusterstorage"
 
 	crov1 "github.com/integr8ly/cloud-resource-operator/apis/integreatly/v1alpha1"
 	croResources "github.com/integr8ly/cloud-resource-operator/pkg/resources"
@@ -53,6 +54,10 @@ const (
 )
 
 func ReconcilePostgresAlerts(ctx context.Context, client k8sclient.Client, inst *v1alpha1.RHMI, cr *crov1.Postgres, log l.Logger) (v1alpha1.StatusPhase, error) {
+	if cr.Status.Strategy == clusterstorage.Strategy {
+		return reconcileClusterStoragePostgresAlerts(ctx, client, inst, cr, log)
+	}
+
 	// create prometheus failed rule
 	_, err := createPostgresResourceStatusPhaseFailedAlert(ctx, client, inst, cr, log, inst.Spec.Type)
 	if err != nil {
@@ -107,6 +112,9 @@ func ReconcilePostgresAlerts(ctx context.Context, client k8sclient.Client, inst
 }
 
 func ReconcileRedisAlerts(ctx context.Context, client k8sclient.Client, inst *v1alpha1.RHMI, cr *crov1.Redis, log l.Logger) (v1alpha1.StatusPhase, error) {
+	if cr.Status.Strategy == clusterstorage.Strategy {
+		return reconcileClusterStorageRedisAlerts(ctx, client, inst, cr, log)
+	}
 
 	// redis cr returning a failed state
 	_, err := createRedisResourceStatusPhaseFailedAlert(ctx, client, inst, cr, log)
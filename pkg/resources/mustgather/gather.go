@@ -0,0 +1,348 @@
+// Package mustgather gathers the state of an installation to debug it: the
+// RHMI and RHMIConfig CRs, the config maps of the operator, the product CRs,
+// the OLM resources and the recent events of the RHOAM namespaces. Secret
+// values are redacted
+package mustgather
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/resources"
+	operatorsv1alpha1 "github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+const (
+	// RedactedValue replaces the values of the secrets
+	RedactedValue = "<redacted>"
+
+	// DefaultEventsSince is how far back the events are gathered
+	DefaultEventsSince = 24 * time.Hour
+)
+
+// productKinds are the CRs of the products, gathered from every RHOAM
+// namespace. The kinds not installed in the cluster are skipped
+var productKinds = []schema.GroupVersionKind{
+	{Group: "apps.3scale.net", Version: "v1alpha1", Kind: "APIManager"},
+	{Group: "keycloak.org", Version: "v1alpha1", Kind: "Keycloak"},
+	{Group: "keycloak.org", Version: "v1alpha1", Kind: "KeycloakRealm"},
+	{Group: "integreatly.org", Version: "v1alpha1", Kind: "Grafana"},
+	{Group: "integreatly.org", Version: "v1alpha1", Kind: "Postgres"},
+	{Group: "integreatly.org", Version: "v1alpha1", Kind: "Redis"},
+	{Group: "integreatly.org", Version: "v1alpha1", Kind: "BlobStorage"},
+	{Group: "marin3r.3scale.net", Version: "v1alpha1", Kind: "EnvoyConfig"},
+	{Group: "observability.redhat.com", Version: "v1", Kind: "Observability"},
+}
+
+// Options select what is gathered
+type Options struct {
+	// Secrets gathers the secrets of the RHOAM namespaces, with their values
+	// redacted
+	Secrets bool
+	// EventsSince is how far back the events are gathered
+	EventsSince time.Duration
+}
+
+// Bundle is the state gathered from the cluster
+type Bundle struct {
+	// Files are the YAML manifests of the objects gathered, by path in the
+	// archive, <namespace>/<kind>/<name>.yaml
+	Files map[string][]byte
+	// Errors are the objects that couldn't be gathered, the gather carries on
+	// past them
+	Errors []string
+
+	GatheredAt    time.Time
+	Namespaces    []string
+	Installation  *integreatlyv1alpha1.RHMI
+	Config        *integreatlyv1alpha1.RHMIConfig
+	CSVs          []operatorsv1alpha1.ClusterServiceVersion
+	Subscriptions []operatorsv1alpha1.Subscription
+	Warnings      []corev1.Event
+}
+
+// Gatherer gathers the state of the installation in a namespace
+type Gatherer struct {
+	client    k8sclient.Client
+	scheme    *runtime.Scheme
+	namespace string
+	options   Options
+}
+
+// NewGatherer returns a gatherer of the installation in the operator
+// namespace
+func NewGatherer(client k8sclient.Client, scheme *runtime.Scheme, namespace string, options Options) *Gatherer {
+	if options.EventsSince == 0 {
+		options.EventsSince = DefaultEventsSince
+	}
+	return &Gatherer{
+		client:    client,
+		scheme:    scheme,
+		namespace: namespace,
+		options:   options,
+	}
+}
+
+// Gather gathers the state of the installation. It fails only if the
+// namespaces of the installation can't be listed, other failures are
+// recorded in the errors of the bundle
+func (g *Gatherer) Gather(ctx context.Context) (*Bundle, error) {
+	bundle := &Bundle{
+		Files:      map[string][]byte{},
+		GatheredAt: time.Now(),
+		Namespaces: []string{g.namespace},
+	}
+
+	installations := &integreatlyv1alpha1.RHMIList{}
+	g.gatherList(ctx, bundle, installations, g.namespace)
+	if len(installations.Items) > 0 {
+		bundle.Installation = &installations.Items[0]
+
+		namespaces, err := resources.GetRHOAMNamespaces(ctx, g.client, bundle.Installation.Spec.NamespacePrefix)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the namespaces of the installation: %w", err)
+		}
+		bundle.Namespaces = uniqueNamespaces(append(namespaces, g.namespace))
+	}
+
+	configs := &integreatlyv1alpha1.RHMIConfigList{}
+	g.gatherList(ctx, bundle, configs, g.namespace)
+	if len(configs.Items) > 0 {
+		bundle.Config = &configs.Items[0]
+	}
+
+	// the installation config, the quota config and the cloud resources
+	// strategies are config maps of the operator namespace
+	g.gatherList(ctx, bundle, &corev1.ConfigMapList{}, g.namespace)
+
+	for _, namespace := range bundle.Namespaces {
+		subscriptions := &operatorsv1alpha1.SubscriptionList{}
+		g.gatherList(ctx, bundle, subscriptions, namespace)
+		bundle.Subscriptions = append(bundle.Subscriptions, subscriptions.Items...)
+
+		csvs := &operatorsv1alpha1.ClusterServiceVersionList{}
+		g.gatherList(ctx, bundle, csvs, namespace)
+		bundle.CSVs = append(bundle.CSVs, csvs.Items...)
+
+		g.gatherList(ctx, bundle, &operatorsv1alpha1.InstallPlanList{}, namespace)
+
+		for _, gvk := range productKinds {
+			products := &unstructured.UnstructuredList{}
+			products.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+			g.gatherList(ctx, bundle, products, namespace)
+		}
+
+		g.gatherEvents(ctx, bundle, namespace)
+
+		if g.options.Secrets {
+			g.gatherList(ctx, bundle, &corev1.SecretList{}, namespace)
+		}
+	}
+
+	return bundle, nil
+}
+
+// gatherList lists the objects of list in namespace and adds them to the
+// bundle
+func (g *Gatherer) gatherList(ctx context.Context, bundle *Bundle, list runtime.Object, namespace string) {
+	kind := strings.TrimSuffix(list.GetObjectKind().GroupVersionKind().Kind, "List")
+	if kind == "" {
+		gvk, err := apiutil.GVKForObject(list, g.scheme)
+		if err != nil {
+			bundle.Errors = append(bundle.Errors, fmt.Sprintf("%T: %v", list, err))
+			return
+		}
+		kind = strings.TrimSuffix(gvk.Kind, "List")
+	}
+
+	if err := g.client.List(ctx, list, k8sclient.InNamespace(namespace)); err != nil {
+		if meta.IsNoMatchError(err) {
+			return
+		}
+		bundle.Errors = append(bundle.Errors, fmt.Sprintf("%s/%s: %v", namespace, kind, err))
+		return
+	}
+
+	objects, err := meta.ExtractList(list)
+	if err != nil {
+		bundle.Errors = append(bundle.Errors, fmt.Sprintf("%s/%s: %v", namespace, kind, err))
+		return
+	}
+	for _, object := range objects {
+		g.addObject(bundle, namespace, kind, object)
+	}
+}
+
+// gatherEvents adds the events of namespace since the EventsSince option
+func (g *Gatherer) gatherEvents(ctx context.Context, bundle *Bundle, namespace string) {
+	events := &corev1.EventList{}
+	if err := g.client.List(ctx, events, k8sclient.InNamespace(namespace)); err != nil {
+		bundle.Errors = append(bundle.Errors, fmt.Sprintf("%s/Event: %v", namespace, err))
+		return
+	}
+
+	since := bundle.GatheredAt.Add(-g.options.EventsSince)
+	recent := []corev1.Event{}
+	for _, event := range events.Items {
+		if eventTime(event).Before(since) {
+			continue
+		}
+		recent = append(recent, event)
+		if event.Type == corev1.EventTypeWarning {
+			bundle.Warnings = append(bundle.Warnings, event)
+		}
+	}
+	sort.Slice(recent, func(i, j int) bool {
+		return eventTime(recent[i]).Before(eventTime(recent[j]))
+	})
+
+	// the events are gathered in one file per namespace, in order
+	events.Items = recent
+	g.addFile(bundle, path.Join(namespace, "events.yaml"), events)
+}
+
+func (g *Gatherer) addObject(bundle *Bundle, namespace, kind string, object runtime.Object) {
+	accessor, err := meta.Accessor(object)
+	if err != nil {
+		bundle.Errors = append(bundle.Errors, fmt.Sprintf("%s/%s: %v", namespace, kind, err))
+		return
+	}
+	accessor.SetManagedFields(nil)
+
+	switch typed := object.(type) {
+	case *corev1.Secret:
+		object = redactSecret(typed)
+	case *unstructured.Unstructured:
+		object = redactUnstructured(typed)
+	}
+	// the objects listed have no type meta
+	if gvk, err := apiutil.GVKForObject(object, g.scheme); err == nil {
+		object.GetObjectKind().SetGroupVersionKind(gvk)
+	}
+
+	g.addFile(bundle, path.Join(namespace, strings.ToLower(kind), accessor.GetName()+".yaml"), object)
+}
+
+func (g *Gatherer) addFile(bundle *Bundle, name string, object interface{}) {
+	content, err := yaml.Marshal(object)
+	if err != nil {
+		bundle.Errors = append(bundle.Errors, fmt.Sprintf("%s: %v", name, err))
+		return
+	}
+	bundle.Files[name] = content
+}
+
+// redactSecret returns a copy of secret with its values replaced, the keys
+// are kept to check the secret is complete
+func redactSecret(secret *corev1.Secret) *corev1.Secret {
+	redacted := secret.DeepCopy()
+	redacted.Data = nil
+	redacted.StringData = map[string]string{}
+	for key := range secret.Data {
+		redacted.StringData[key] = RedactedValue
+	}
+	for key := range secret.StringData {
+		redacted.StringData[key] = RedactedValue
+	}
+	// the last applied configuration holds the values of the secret
+	delete(redacted.Annotations, corev1.LastAppliedConfigAnnotation)
+	return redacted
+}
+
+// redactUnstructured returns a copy of a product CR with the values of its
+// secret and password fields replaced, such as the client secrets of the
+// identity providers of a KeycloakRealm
+func redactUnstructured(object *unstructured.Unstructured) *unstructured.Unstructured {
+	redacted := object.DeepCopy()
+	annotations := redacted.GetAnnotations()
+	if _, ok := annotations[corev1.LastAppliedConfigAnnotation]; ok {
+		delete(annotations, corev1.LastAppliedConfigAnnotation)
+		redacted.SetAnnotations(annotations)
+	}
+	redactFields(redacted.Object)
+	return redacted
+}
+
+// redactFields replaces the string values of the sensitive fields of object,
+// recursively
+func redactFields(object interface{}) {
+	switch typed := object.(type) {
+	case map[string]interface{}:
+		for key, value := range typed {
+			if _, ok := value.(string); ok && isSensitiveField(key) {
+				typed[key] = RedactedValue
+				continue
+			}
+			// the credentials of the users of a realm hold their passwords
+			// in their values
+			if strings.EqualFold(key, "credentials") {
+				redactCredentials(value)
+			}
+			redactFields(value)
+		}
+	case []interface{}:
+		for _, value := range typed {
+			redactFields(value)
+		}
+	}
+}
+
+func redactCredentials(credentials interface{}) {
+	list, ok := credentials.([]interface{})
+	if !ok {
+		return
+	}
+	for _, credential := range list {
+		if fields, ok := credential.(map[string]interface{}); ok {
+			if _, ok := fields["value"]; ok {
+				fields["value"] = RedactedValue
+			}
+		}
+	}
+}
+
+// isSensitiveField returns whether the field named key holds a secret value.
+// The fields naming a secret, such as secretName, are references only
+func isSensitiveField(key string) bool {
+	key = strings.ToLower(key)
+	if strings.HasSuffix(key, "name") {
+		return false
+	}
+	return strings.Contains(key, "secret") || strings.Contains(key, "password") || strings.Contains(key, "token")
+}
+
+func eventTime(event corev1.Event) time.Time {
+	if !event.LastTimestamp.IsZero() {
+		return event.LastTimestamp.Time
+	}
+	if !event.EventTime.IsZero() {
+		return event.EventTime.Time
+	}
+	return event.CreationTimestamp.Time
+}
+
+func uniqueNamespaces(namespaces []string) []string {
+	seen := map[string]bool{}
+	unique := []string{}
+	for _, namespace := range namespaces {
+		if !seen[namespace] {
+			seen[namespace] = true
+			unique = append(unique, namespace)
+		}
+	}
+	sort.Strings(unique)
+	return unique
+}
@@ -0,0 +1,47 @@
+package mustgather
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// Annotation on the RHMI CR requests a gather by the operator. The
+	// summary of the gather is written to the SummaryConfigMapName config
+	// map, and the annotation removed
+	Annotation = "integreatly.org/must-gather"
+
+	// SummaryConfigMapName is the config map in the operator namespace the
+	// summary of the gather is written to
+	SummaryConfigMapName = "rhmi-must-gather"
+
+	summaryKey    = "summary"
+	gatheredAtKey = "gatheredAt"
+)
+
+// WriteSummary writes the summary of bundle to the SummaryConfigMapName
+// config map in namespace
+func WriteSummary(ctx context.Context, client k8sclient.Client, namespace string, bundle *Bundle) error {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      SummaryConfigMapName,
+			Namespace: namespace,
+		},
+	}
+	if _, err := controllerutil.CreateOrUpdate(ctx, client, configMap, func() error {
+		configMap.Data = map[string]string{
+			summaryKey:    bundle.Summary(),
+			gatheredAtKey: bundle.GatheredAt.UTC().Format(time.RFC3339),
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to write the summary to config map %s: %w", SummaryConfigMapName, err)
+	}
+	return nil
+}
@@ -0,0 +1,324 @@
+package mustgather
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	keycloak "github.com/keycloak/keycloak-operator/pkg/apis/keycloak/v1alpha1"
+	operatorsv1alpha1 "github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	operatorNamespace = "redhat-rhoam-operator"
+	productNamespace  = "redhat-rhoam-3scale"
+)
+
+func buildScheme(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	if err := integreatlyv1alpha1.SchemeBuilder.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := operatorsv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := keycloak.SchemeBuilder.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	return scheme
+}
+
+func buildObjects() []runtime.Object {
+	now := time.Now()
+	return []runtime.Object{
+		&corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   operatorNamespace,
+				Labels: map[string]string{"integreatly": "true"},
+			},
+		},
+		&corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   productNamespace,
+				Labels: map[string]string{"integreatly": "true"},
+			},
+		},
+		&integreatlyv1alpha1.RHMI{
+			ObjectMeta: metav1.ObjectMeta{Name: "rhoam", Namespace: operatorNamespace},
+			Spec: integreatlyv1alpha1.RHMISpec{
+				Type:            string(integreatlyv1alpha1.InstallationTypeManagedApi),
+				NamespacePrefix: "redhat-rhoam-",
+			},
+			Status: integreatlyv1alpha1.RHMIStatus{
+				Stage:     integreatlyv1alpha1.ProductsStage,
+				LastError: "3scale not ready",
+				Stages: map[integreatlyv1alpha1.StageName]integreatlyv1alpha1.RHMIStageStatus{
+					integreatlyv1alpha1.ProductsStage: {
+						Name:  integreatlyv1alpha1.ProductsStage,
+						Phase: integreatlyv1alpha1.PhaseInProgress,
+						Products: map[integreatlyv1alpha1.ProductName]integreatlyv1alpha1.RHMIProductStatus{
+							integreatlyv1alpha1.Product3Scale: {
+								Name:  integreatlyv1alpha1.Product3Scale,
+								Phase: integreatlyv1alpha1.PhaseInProgress,
+							},
+						},
+					},
+				},
+			},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "installation-config", Namespace: operatorNamespace},
+			Data:       map[string]string{"3scale": "NAMESPACE: redhat-rhoam-3scale"},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "system-seed",
+				Namespace: productNamespace,
+				Annotations: map[string]string{
+					corev1.LastAppliedConfigAnnotation: `{"data":{"ADMIN_PASSWORD":"c2VjcmV0"}}`,
+				},
+			},
+			Data: map[string][]byte{"ADMIN_PASSWORD": []byte("secret")},
+		},
+		&keycloak.KeycloakRealm{
+			ObjectMeta: metav1.ObjectMeta{Name: "openshift", Namespace: productNamespace},
+			Spec: keycloak.KeycloakRealmSpec{
+				Realm: &keycloak.KeycloakAPIRealm{
+					Realm: "openshift",
+					IdentityProviders: []*keycloak.KeycloakIdentityProvider{{
+						Alias: "openshift-v4",
+						Config: map[string]string{
+							"clientId":     "rhsso",
+							"clientSecret": "idp-client-secret",
+						},
+					}},
+				},
+			},
+		},
+		&operatorsv1alpha1.Subscription{
+			ObjectMeta: metav1.ObjectMeta{Name: "rhmi-3scale", Namespace: productNamespace},
+			Status: operatorsv1alpha1.SubscriptionStatus{
+				InstalledCSV: "3scale-operator.v0.7.0",
+				CurrentCSV:   "3scale-operator.v0.8.0",
+			},
+		},
+		&operatorsv1alpha1.ClusterServiceVersion{
+			ObjectMeta: metav1.ObjectMeta{Name: "3scale-operator.v0.7.0", Namespace: productNamespace},
+			Status:     operatorsv1alpha1.ClusterServiceVersionStatus{Phase: operatorsv1alpha1.CSVPhaseSucceeded},
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "recent", Namespace: productNamespace},
+			InvolvedObject: corev1.ObjectReference{Name: "apicast-production"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "BackOff",
+			Message:        "Back-off restarting failed container",
+			LastTimestamp:  metav1.NewTime(now.Add(-time.Hour)),
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "old", Namespace: productNamespace},
+			InvolvedObject: corev1.ObjectReference{Name: "system-app"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "FailedScheduling",
+			LastTimestamp:  metav1.NewTime(now.Add(-48 * time.Hour)),
+		},
+	}
+}
+
+func TestGatherer_Gather(t *testing.T) {
+	scheme := buildScheme(t)
+
+	cases := []struct {
+		Name        string
+		Options     Options
+		Validate    func(t *testing.T, bundle *Bundle)
+		ExpectFiles []string
+	}{
+		{
+			Name:    "test the installation, config maps, OLM resources and recent events are gathered",
+			Options: Options{},
+			ExpectFiles: []string{
+				operatorNamespace + "/rhmi/rhoam.yaml",
+				operatorNamespace + "/configmap/installation-config.yaml",
+				productNamespace + "/subscription/rhmi-3scale.yaml",
+				productNamespace + "/clusterserviceversion/3scale-operator.v0.7.0.yaml",
+				productNamespace + "/events.yaml",
+			},
+			Validate: func(t *testing.T, bundle *Bundle) {
+				if len(bundle.Namespaces) != 2 {
+					t.Errorf("expected the operator and product namespaces, got %v", bundle.Namespaces)
+				}
+				if bundle.Installation == nil || bundle.Installation.Name != "rhoam" {
+					t.Errorf("expected the installation to be gathered, got %v", bundle.Installation)
+				}
+				if _, ok := bundle.Files[productNamespace+"/secret/system-seed.yaml"]; ok {
+					t.Errorf("expected the secrets not to be gathered")
+				}
+				events := string(bundle.Files[productNamespace+"/events.yaml"])
+				if !strings.Contains(events, "BackOff") {
+					t.Errorf("expected the recent event to be gathered, got %s", events)
+				}
+				if strings.Contains(events, "FailedScheduling") {
+					t.Errorf("expected the old event not to be gathered, got %s", events)
+				}
+				if len(bundle.Warnings) != 1 {
+					t.Errorf("expected 1 recent warning, got %d", len(bundle.Warnings))
+				}
+			},
+		},
+		{
+			Name:        "test the secrets are gathered redacted",
+			Options:     Options{Secrets: true},
+			ExpectFiles: []string{productNamespace + "/secret/system-seed.yaml"},
+			Validate: func(t *testing.T, bundle *Bundle) {
+				secret := string(bundle.Files[productNamespace+"/secret/system-seed.yaml"])
+				if !strings.Contains(secret, "ADMIN_PASSWORD: "+RedactedValue) {
+					t.Errorf("expected the secret keys to be kept with redacted values, got %s", secret)
+				}
+				if strings.Contains(secret, "c2VjcmV0") || strings.Contains(secret, corev1.LastAppliedConfigAnnotation) {
+					t.Errorf("expected the secret values to be redacted, got %s", secret)
+				}
+			},
+		},
+		{
+			Name:        "test the secret fields of the product CRs are redacted",
+			Options:     Options{},
+			ExpectFiles: []string{productNamespace + "/keycloakrealm/openshift.yaml"},
+			Validate: func(t *testing.T, bundle *Bundle) {
+				realm := string(bundle.Files[productNamespace+"/keycloakrealm/openshift.yaml"])
+				if strings.Contains(realm, "idp-client-secret") {
+					t.Errorf("expected the identity provider client secret to be redacted, got %s", realm)
+				}
+				if !strings.Contains(realm, "clientSecret: "+RedactedValue) || !strings.Contains(realm, "clientId: rhsso") {
+					t.Errorf("expected only the client secret to be redacted, got %s", realm)
+				}
+			},
+		},
+		{
+			Name:    "test the events are gathered since the events since option",
+			Options: Options{EventsSince: 72 * time.Hour},
+			Validate: func(t *testing.T, bundle *Bundle) {
+				if len(bundle.Warnings) != 2 {
+					t.Errorf("expected 2 warnings, got %d", len(bundle.Warnings))
+				}
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			client := fake.NewFakeClientWithScheme(scheme, buildObjects()...)
+			bundle, err := NewGatherer(client, scheme, operatorNamespace, tc.Options).Gather(context.TODO())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, file := range tc.ExpectFiles {
+				if _, ok := bundle.Files[file]; !ok {
+					t.Errorf("expected %s to be gathered", file)
+				}
+			}
+			tc.Validate(t, bundle)
+		})
+	}
+}
+
+func TestBundle_Summary(t *testing.T) {
+	scheme := buildScheme(t)
+	client := fake.NewFakeClientWithScheme(scheme, buildObjects()...)
+	bundle, err := NewGatherer(client, scheme, operatorNamespace, Options{}).Gather(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	summary := bundle.Summary()
+	for _, expected := range []string{
+		"Installation: rhoam (managed-api)",
+		"Last error: 3scale not ready",
+		"3scale : in progress",
+		productNamespace + "/3scale-operator.v0.7.0: Succeeded",
+		"installed 3scale-operator.v0.7.0, upgrading to 3scale-operator.v0.8.0",
+		"BackOff: Back-off restarting failed container",
+	} {
+		if !strings.Contains(summary, expected) {
+			t.Errorf("expected the summary to contain %q, got\n%s", expected, summary)
+		}
+	}
+}
+
+func TestBundle_WriteArchive(t *testing.T) {
+	bundle := &Bundle{
+		Files:      map[string][]byte{operatorNamespace + "/configmap/installation-config.yaml": []byte("kind: ConfigMap\n")},
+		Errors:     []string{operatorNamespace + "/RHMIConfig: forbidden"},
+		GatheredAt: time.Now(),
+	}
+
+	buffer := &bytes.Buffer{}
+	if err := bundle.WriteArchive(buffer); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	gz, err := gzip.NewReader(buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive := tar.NewReader(gz)
+	files := map[string]string{}
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(archive)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[header.Name] = string(content)
+	}
+
+	if files[operatorNamespace+"/configmap/installation-config.yaml"] != "kind: ConfigMap\n" {
+		t.Errorf("expected the gathered file in the archive, got %v", files)
+	}
+	if !strings.Contains(files[summaryFile], "No installation found") {
+		t.Errorf("expected the summary in the archive, got %q", files[summaryFile])
+	}
+	if !strings.Contains(files[errorsFile], "forbidden") {
+		t.Errorf("expected the errors in the archive, got %q", files[errorsFile])
+	}
+}
+
+func TestWriteSummary(t *testing.T) {
+	scheme := buildScheme(t)
+	client := fake.NewFakeClientWithScheme(scheme)
+	bundle := &Bundle{GatheredAt: time.Now()}
+
+	// the summary is updated on every gather
+	for i := 0; i < 2; i++ {
+		if err := WriteSummary(context.TODO(), client, operatorNamespace, bundle); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	configMap := &corev1.ConfigMap{}
+	if err := client.Get(context.TODO(), types.NamespacedName{Name: SummaryConfigMapName, Namespace: operatorNamespace}, configMap); err != nil {
+		t.Fatalf("expected the summary config map, got %v", err)
+	}
+	if configMap.Data[summaryKey] != bundle.Summary() {
+		t.Errorf("expected the summary of the bundle, got %q", configMap.Data[summaryKey])
+	}
+}
@@ -0,0 +1,145 @@
+package mustgather
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	operatorsv1alpha1 "github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators/v1alpha1"
+)
+
+const (
+	summaryFile = "summary.txt"
+	errorsFile  = "errors.txt"
+
+	// maxSummaryWarnings is the number of warning events listed in the
+	// summary, the most recent ones
+	maxSummaryWarnings = 20
+)
+
+// Summary returns a summary of the bundle: the stage and the phases of the
+// installation, the phases of the operators and the most recent warnings
+func (b *Bundle) Summary() string {
+	var s strings.Builder
+	fmt.Fprintf(&s, "Gathered at: %s\n", b.GatheredAt.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&s, "Namespaces: %s\n", strings.Join(b.Namespaces, ", "))
+
+	if b.Installation == nil {
+		s.WriteString("\nNo installation found\n")
+	} else {
+		status := b.Installation.Status
+		fmt.Fprintf(&s, "\nInstallation: %s (%s)\n", b.Installation.Name, b.Installation.Spec.Type)
+		fmt.Fprintf(&s, "Version: %s\n", status.Version)
+		if status.ToVersion != "" {
+			fmt.Fprintf(&s, "Upgrading to: %s\n", status.ToVersion)
+		}
+		fmt.Fprintf(&s, "Stage: %s\n", status.Stage)
+		if status.LastError != "" {
+			fmt.Fprintf(&s, "Last error: %s\n", status.LastError)
+		}
+
+		stages := []integreatlyv1alpha1.StageName{}
+		for name := range status.Stages {
+			stages = append(stages, name)
+		}
+		sort.Slice(stages, func(i, j int) bool { return stages[i] < stages[j] })
+		for _, name := range stages {
+			stage := status.Stages[name]
+			fmt.Fprintf(&s, "  %s: %s\n", name, stage.Phase)
+			products := []integreatlyv1alpha1.ProductName{}
+			for product := range stage.Products {
+				products = append(products, product)
+			}
+			sort.Slice(products, func(i, j int) bool { return products[i] < products[j] })
+			for _, product := range products {
+				productStatus := stage.Products[product]
+				fmt.Fprintf(&s, "    %s %s: %s\n", product, productStatus.Version, productStatus.Phase)
+			}
+		}
+	}
+
+	if b.Config != nil && b.Config.Status.UpgradeAvailable != nil {
+		fmt.Fprintf(&s, "\nUpgrade available: %s\n", b.Config.Status.UpgradeAvailable.TargetVersion)
+	}
+
+	s.WriteString("\nOperators:\n")
+	csvs := append([]operatorsv1alpha1.ClusterServiceVersion{}, b.CSVs...)
+	sort.Slice(csvs, func(i, j int) bool {
+		return csvs[i].Namespace+csvs[i].Name < csvs[j].Namespace+csvs[j].Name
+	})
+	for _, csv := range csvs {
+		fmt.Fprintf(&s, "  %s/%s: %s\n", csv.Namespace, csv.Name, csv.Status.Phase)
+	}
+	for _, subscription := range b.Subscriptions {
+		if subscription.Status.InstalledCSV != subscription.Status.CurrentCSV {
+			fmt.Fprintf(&s, "  %s/%s: installed %s, upgrading to %s\n", subscription.Namespace, subscription.Name, subscription.Status.InstalledCSV, subscription.Status.CurrentCSV)
+		}
+	}
+
+	if len(b.Warnings) > 0 {
+		warnings := append(b.Warnings[:0:0], b.Warnings...)
+		sort.Slice(warnings, func(i, j int) bool {
+			return eventTime(warnings[i]).After(eventTime(warnings[j]))
+		})
+		if len(warnings) > maxSummaryWarnings {
+			warnings = warnings[:maxSummaryWarnings]
+		}
+		fmt.Fprintf(&s, "\nRecent warnings (%d):\n", len(b.Warnings))
+		for _, event := range warnings {
+			fmt.Fprintf(&s, "  %s %s/%s %s: %s\n", eventTime(event).UTC().Format(time.RFC3339), event.Namespace, event.InvolvedObject.Name, event.Reason, event.Message)
+		}
+	}
+
+	if len(b.Errors) > 0 {
+		fmt.Fprintf(&s, "\nFailed to gather (%d):\n", len(b.Errors))
+		for _, err := range b.Errors {
+			fmt.Fprintf(&s, "  %s\n", err)
+		}
+	}
+	return s.String()
+}
+
+// WriteArchive writes the files of the bundle, its summary and its errors to
+// w as a gzipped tarball
+func (b *Bundle) WriteArchive(w io.Writer) error {
+	gz := gzip.NewWriter(w)
+	archive := tar.NewWriter(gz)
+
+	files := map[string][]byte{summaryFile: []byte(b.Summary())}
+	if len(b.Errors) > 0 {
+		files[errorsFile] = []byte(strings.Join(b.Errors, "\n") + "\n")
+	}
+	for name, content := range b.Files {
+		files[name] = content
+	}
+
+	names := []string{}
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		header := &tar.Header{
+			Name:    name,
+			Mode:    0644,
+			Size:    int64(len(files[name])),
+			ModTime: b.GatheredAt,
+		}
+		if err := archive.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		if _, err := archive.Write(files[name]); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
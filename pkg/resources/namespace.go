@@ -0,0 +1,30 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GetRHOAMNamespaces returns the namespaces of the products of the
+// installation with the nsPrefix namespace prefix, and the namespace of the
+// operator
+func GetRHOAMNamespaces(ctx context.Context, serverClient k8sclient.Client, nsPrefix string) ([]string, error) {
+	var namespaces []string
+	namespaceList := &corev1.NamespaceList{}
+	err := serverClient.List(ctx, namespaceList)
+	if err != nil {
+		return nil, err
+	}
+	// Only return namespaces that have the integreatly label and nsPrefix, but also return rhoam operator ns (it does not have the integreatly label on)
+	for _, namespace := range namespaceList.Items {
+		if !strings.Contains(namespace.Name, "observability") && strings.Contains(namespace.Name, nsPrefix) || namespace.Name == fmt.Sprintf("%soperator", nsPrefix) {
+			namespaces = append(namespaces, namespace.Name)
+		}
+	}
+
+	return namespaces, nil
+}
@@ -0,0 +1,118 @@
+package networkpolicy
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// serviceAddress matches the cluster DNS names of services, with an optional
+// port: <service>.<namespace>.svc[.cluster.local][:<port>]
+var serviceAddress = regexp.MustCompile(`\b([a-z0-9]([-a-z0-9]*[a-z0-9])?)\.([a-z0-9]([-a-z0-9]*[a-z0-9])?)\.svc(\.cluster\.local)?(:([0-9]{1,5}))?\b`)
+
+// Flow is traffic from the pods of a namespace to a service of another
+// namespace
+type Flow struct {
+	SourceNamespace string
+	Namespace       string
+	Service         string
+	// Port of the service, 0 when unknown
+	Port int32
+	// Pod that references the service
+	Pod string
+}
+
+func (f Flow) String() string {
+	target := fmt.Sprintf("%s.%s", f.Service, f.Namespace)
+	if f.Port != 0 {
+		target = fmt.Sprintf("%s:%d", target, f.Port)
+	}
+	return fmt.Sprintf("%s/%s -> %s", f.SourceNamespace, f.Pod, target)
+}
+
+// Audit returns the flows into the namespaces of rules that the rules would
+// block. The flows are discovered from the references to the services of the
+// namespaces in the environment variables, commands and arguments of the pods
+// of the cluster
+func Audit(ctx context.Context, reader k8sclient.Reader, rules map[string][]Rule) ([]Flow, error) {
+	flows, err := DiscoverFlows(ctx, reader, rules)
+	if err != nil {
+		return nil, err
+	}
+
+	namespaceLabels := map[string]map[string]string{}
+	blocked := []Flow{}
+	for _, flow := range flows {
+		labels, ok := namespaceLabels[flow.SourceNamespace]
+		if !ok {
+			namespace := &corev1.Namespace{}
+			if err := reader.Get(ctx, k8sclient.ObjectKey{Name: flow.SourceNamespace}, namespace); err != nil && !k8serr.IsNotFound(err) {
+				return nil, fmt.Errorf("failed to get namespace %s: %w", flow.SourceNamespace, err)
+			}
+			labels = namespace.Labels
+			namespaceLabels[flow.SourceNamespace] = labels
+		}
+		if !Allowed(rules[flow.Namespace], flow.SourceNamespace, labels, flow.Port) {
+			blocked = append(blocked, flow)
+		}
+	}
+	return blocked, nil
+}
+
+// DiscoverFlows returns the flows from the pods of the cluster to the services
+// of the namespaces of rules, one per source namespace and service address
+func DiscoverFlows(ctx context.Context, reader k8sclient.Reader, rules map[string][]Rule) ([]Flow, error) {
+	pods := &corev1.PodList{}
+	if err := reader.List(ctx, pods); err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	seen := map[string]bool{}
+	flows := []Flow{}
+	for _, pod := range pods.Items {
+		for _, value := range podReferences(pod) {
+			for _, match := range serviceAddress.FindAllStringSubmatch(value, -1) {
+				flow := Flow{
+					SourceNamespace: pod.Namespace,
+					Service:         match[1],
+					Namespace:       match[3],
+					Pod:             pod.Name,
+				}
+				if _, ok := rules[flow.Namespace]; !ok || flow.Namespace == flow.SourceNamespace {
+					continue
+				}
+				if port, err := strconv.Atoi(match[7]); err == nil {
+					flow.Port = int32(port)
+				}
+				key := fmt.Sprintf("%s/%s.%s:%d", flow.SourceNamespace, flow.Service, flow.Namespace, flow.Port)
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				flows = append(flows, flow)
+			}
+		}
+	}
+
+	sort.Slice(flows, func(i, j int) bool { return flows[i].String() < flows[j].String() })
+	return flows, nil
+}
+
+func podReferences(pod corev1.Pod) []string {
+	values := []string{}
+	containers := append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+	for _, container := range containers {
+		values = append(values, container.Command...)
+		values = append(values, container.Args...)
+		for _, env := range container.Env {
+			values = append(values, env.Value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,59 @@
+package networkpolicy
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func podReferencing(namespace, name string, env ...string) *corev1.Pod {
+	container := corev1.Container{Name: "app"}
+	for _, value := range env {
+		container.Env = append(container.Env, corev1.EnvVar{Name: "URL", Value: value})
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{container}},
+	}
+}
+
+func TestAudit(t *testing.T) {
+	client := fakeclient.NewFakeClientWithScheme(buildScheme(t),
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "customer-project"}},
+		podReferencing("customer-project", "client",
+			"http://apicast-production.redhat-rhoam-3scale.svc:8080/api",
+			"https://apicast-production.redhat-rhoam-3scale.svc.cluster.local:8080",
+		),
+		podReferencing("redhat-rhoam-3scale", "apicast-production",
+			"ratelimit.redhat-rhoam-marin3r.svc:8081",
+			"backend-listener.redhat-rhoam-3scale.svc:3000",
+		),
+		podReferencing("redhat-rhoam-3scale", "system-app",
+			"http://keycloak.redhat-rhoam-rhsso.svc:8080",
+		),
+		podReferencing("redhat-rhoam-3scale", "debug",
+			"ratelimit.redhat-rhoam-marin3r.svc:6060",
+		),
+		podReferencing("other-project", "unrelated",
+			"http://service.other-project.svc:8080",
+		),
+	)
+
+	rules := Rules(rhoamProducts, "redhat-rhoam-operator", "redhat-rhoam-observability")
+	blocked, err := Audit(context.TODO(), client, rules)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []Flow{
+		{SourceNamespace: "customer-project", Namespace: "redhat-rhoam-3scale", Service: "apicast-production", Port: 8080, Pod: "client"},
+		{SourceNamespace: "redhat-rhoam-3scale", Namespace: "redhat-rhoam-marin3r", Service: "ratelimit", Port: 6060, Pod: "debug"},
+	}
+	if !reflect.DeepEqual(blocked, expected) {
+		t.Errorf("expected blocked flows %v, got %v", expected, blocked)
+	}
+}
@@ -0,0 +1,203 @@
+package networkpolicy
+
+import (
+	"reflect"
+	"sort"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+)
+
+const (
+	ratelimitPort = 8081
+	webhookPort   = 9443
+)
+
+var (
+	routerPeer     = Peer{NamespaceLabels: map[string]string{PolicyGroupLabel: "ingress"}}
+	monitoringPeer = Peer{NamespaceLabels: map[string]string{PolicyGroupLabel: "monitoring"}}
+)
+
+// ProductNamespaces are the namespaces a product is installed in
+type ProductNamespaces struct {
+	Namespace         string
+	OperatorNamespace string
+}
+
+// ingress is a flow into the namespace of a product
+type ingress struct {
+	name string
+	// fromProduct is the product whose namespace the traffic comes from
+	fromProduct integreatlyv1alpha1.ProductName
+	// fromRouter is traffic of the routes of the product
+	fromRouter bool
+	// fromAny is traffic from everywhere, like the API server calling
+	// webhooks
+	fromAny bool
+	ports   []int32
+}
+
+// productIngress are the flows into the namespace of each product, besides
+// the flows common to all the namespaces
+var productIngress = map[integreatlyv1alpha1.ProductName][]ingress{
+	integreatlyv1alpha1.Product3Scale: {
+		{name: "router", fromRouter: true},
+		// back-channel requests of the SSO servers 3scale is a client of
+		{name: "rhsso", fromProduct: integreatlyv1alpha1.ProductRHSSO},
+		{name: "rhssouser", fromProduct: integreatlyv1alpha1.ProductRHSSOUser},
+	},
+	integreatlyv1alpha1.ProductMarin3r: {
+		// rate limiting requests of the envoy sidecars of apicast
+		{name: "3scale", fromProduct: integreatlyv1alpha1.Product3Scale, ports: []int32{ratelimitPort}},
+	},
+	integreatlyv1alpha1.ProductRHSSO: {
+		{name: "router", fromRouter: true},
+		{name: "3scale", fromProduct: integreatlyv1alpha1.Product3Scale},
+	},
+	integreatlyv1alpha1.ProductRHSSOUser: {
+		{name: "router", fromRouter: true},
+		{name: "3scale", fromProduct: integreatlyv1alpha1.Product3Scale},
+	},
+	integreatlyv1alpha1.ProductGrafana: {
+		{name: "router", fromRouter: true},
+	},
+	integreatlyv1alpha1.ProductObservability: {
+		{name: "router", fromRouter: true},
+		// the customer Grafana queries the Prometheus of the observability
+		// namespace
+		{name: "grafana", fromProduct: integreatlyv1alpha1.ProductGrafana},
+	},
+}
+
+// operatorIngress are the flows into the operator namespace of each product,
+// besides the flows common to all the namespaces
+var operatorIngress = map[integreatlyv1alpha1.ProductName][]ingress{
+	integreatlyv1alpha1.ProductMarin3r: {
+		// the webhook injecting the envoy sidecars is called by the API server
+		{name: "webhook", fromAny: true, ports: []int32{webhookPort}},
+	},
+}
+
+// Rules returns the rules allowing the flows into each of the namespaces of
+// the products, keyed by namespace. operatorNamespace is the namespace of the
+// RHMI operator, and observabilityNamespace the namespace of the Prometheus
+// scraping the products
+func Rules(products map[integreatlyv1alpha1.ProductName]ProductNamespaces, operatorNamespace, observabilityNamespace string) map[string][]Rule {
+	rules := map[string][]Rule{}
+	for _, product := range sortedProducts(products) {
+		namespaces := products[product]
+		if namespaces.Namespace != "" {
+			namespaceRules := commonRules(namespaces.Namespace, operatorNamespace, observabilityNamespace)
+			if namespaces.OperatorNamespace != "" && namespaces.OperatorNamespace != namespaces.Namespace {
+				namespaceRules = append(namespaceRules, Rule{Name: "product-operator", From: []Peer{{Namespace: namespaces.OperatorNamespace}}})
+			}
+			namespaceRules = append(namespaceRules, ingressRules(productIngress[product], products)...)
+			rules[namespaces.Namespace] = mergeRules(rules[namespaces.Namespace], namespaceRules)
+		}
+		if namespaces.OperatorNamespace != "" && namespaces.OperatorNamespace != namespaces.Namespace {
+			namespaceRules := commonRules(namespaces.OperatorNamespace, operatorNamespace, observabilityNamespace)
+			namespaceRules = append(namespaceRules, ingressRules(operatorIngress[product], products)...)
+			rules[namespaces.OperatorNamespace] = mergeRules(rules[namespaces.OperatorNamespace], namespaceRules)
+		}
+	}
+	return rules
+}
+
+// commonRules allow the traffic within the namespace, the scraping of the
+// metrics and the requests of the RHMI operator
+func commonRules(namespace, operatorNamespace, observabilityNamespace string) []Rule {
+	rules := []Rule{
+		{Name: "same-namespace", From: []Peer{{Namespace: namespace}}},
+		{Name: "monitoring", From: []Peer{monitoringPeer}},
+		{Name: "rhmi-operator", From: []Peer{{Namespace: operatorNamespace}}},
+	}
+	if observabilityNamespace != "" && observabilityNamespace != namespace {
+		rules[1].From = append(rules[1].From, Peer{Namespace: observabilityNamespace})
+	}
+	return rules
+}
+
+func ingressRules(flows []ingress, products map[integreatlyv1alpha1.ProductName]ProductNamespaces) []Rule {
+	rules := []Rule{}
+	for _, flow := range flows {
+		rule := Rule{Name: flow.name, Ports: flow.ports}
+		switch {
+		case flow.fromAny:
+		case flow.fromRouter:
+			rule.From = []Peer{routerPeer}
+		default:
+			// flows from products that are not installed are not allowed
+			source, ok := products[flow.fromProduct]
+			if !ok || source.Namespace == "" {
+				continue
+			}
+			rule.From = []Peer{{Namespace: source.Namespace}}
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// mergeRules adds the rules to the rules of a namespace shared by products,
+// merging the sources and ports of the rules with the same name
+func mergeRules(rules, added []Rule) []Rule {
+	for _, rule := range added {
+		merged := false
+		for i := range rules {
+			if rules[i].Name != rule.Name {
+				continue
+			}
+			rules[i].From = mergePeers(rules[i].From, rule.From)
+			rules[i].Ports = mergePorts(rules[i].Ports, rule.Ports)
+			merged = true
+			break
+		}
+		if !merged {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// mergePeers and mergePorts keep allowing everything when either of the rules
+// does
+func mergePeers(peers, added []Peer) []Peer {
+	if len(peers) == 0 || len(added) == 0 {
+		return nil
+	}
+	for _, peer := range added {
+		if !containsPeer(peers, peer) {
+			peers = append(peers, peer)
+		}
+	}
+	return peers
+}
+
+func mergePorts(ports, added []int32) []int32 {
+	if len(ports) == 0 || len(added) == 0 {
+		return nil
+	}
+	for _, port := range added {
+		if !containsPort(ports, port) {
+			ports = append(ports, port)
+		}
+	}
+	return ports
+}
+
+func containsPeer(peers []Peer, peer Peer) bool {
+	for _, p := range peers {
+		if reflect.DeepEqual(p, peer) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedProducts(products map[integreatlyv1alpha1.ProductName]ProductNamespaces) []integreatlyv1alpha1.ProductName {
+	names := make([]integreatlyv1alpha1.ProductName, 0, len(products))
+	for name := range products {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
@@ -0,0 +1,128 @@
+package networkpolicy
+
+import (
+	"testing"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+)
+
+var rhoamProducts = map[integreatlyv1alpha1.ProductName]ProductNamespaces{
+	integreatlyv1alpha1.Product3Scale:        {Namespace: "redhat-rhoam-3scale", OperatorNamespace: "redhat-rhoam-3scale-operator"},
+	integreatlyv1alpha1.ProductMarin3r:       {Namespace: "redhat-rhoam-marin3r", OperatorNamespace: "redhat-rhoam-marin3r-operator"},
+	integreatlyv1alpha1.ProductRHSSO:         {Namespace: "redhat-rhoam-rhsso", OperatorNamespace: "redhat-rhoam-rhsso-operator"},
+	integreatlyv1alpha1.ProductObservability: {Namespace: "redhat-rhoam-observability", OperatorNamespace: "redhat-rhoam-observability-operator"},
+}
+
+func TestRules(t *testing.T) {
+	rules := Rules(rhoamProducts, "redhat-rhoam-operator", "redhat-rhoam-observability")
+
+	if len(rules) != 8 {
+		t.Fatalf("expected rules for the 8 product and operator namespaces, got %d", len(rules))
+	}
+
+	router := map[string]string{PolicyGroupLabel: "ingress"}
+	scenarios := []struct {
+		Name            string
+		Namespace       string
+		SourceNamespace string
+		SourceLabels    map[string]string
+		Port            int32
+		ExpectAllowed   bool
+	}{
+		{
+			Name:            "test traffic within the namespace is allowed",
+			Namespace:       "redhat-rhoam-3scale",
+			SourceNamespace: "redhat-rhoam-3scale",
+			ExpectAllowed:   true,
+		},
+		{
+			Name:            "test router traffic to 3scale is allowed",
+			Namespace:       "redhat-rhoam-3scale",
+			SourceNamespace: "openshift-ingress",
+			SourceLabels:    router,
+			ExpectAllowed:   true,
+		},
+		{
+			Name:            "test router traffic to the rate limiter is blocked",
+			Namespace:       "redhat-rhoam-marin3r",
+			SourceNamespace: "openshift-ingress",
+			SourceLabels:    router,
+		},
+		{
+			Name:            "test 3scale traffic to the rate limiter port is allowed",
+			Namespace:       "redhat-rhoam-marin3r",
+			SourceNamespace: "redhat-rhoam-3scale",
+			Port:            ratelimitPort,
+			ExpectAllowed:   true,
+		},
+		{
+			Name:            "test 3scale traffic to other rate limiter ports is blocked",
+			Namespace:       "redhat-rhoam-marin3r",
+			SourceNamespace: "redhat-rhoam-3scale",
+			Port:            8080,
+		},
+		{
+			Name:            "test rhsso back-channel traffic to 3scale is allowed",
+			Namespace:       "redhat-rhoam-3scale",
+			SourceNamespace: "redhat-rhoam-rhsso",
+			ExpectAllowed:   true,
+		},
+		{
+			Name:            "test prometheus scraping is allowed",
+			Namespace:       "redhat-rhoam-rhsso-operator",
+			SourceNamespace: "redhat-rhoam-observability",
+			ExpectAllowed:   true,
+		},
+		{
+			Name:            "test product operator traffic to its product is allowed",
+			Namespace:       "redhat-rhoam-rhsso",
+			SourceNamespace: "redhat-rhoam-rhsso-operator",
+			ExpectAllowed:   true,
+		},
+		{
+			Name:            "test traffic from other product operators is blocked",
+			Namespace:       "redhat-rhoam-rhsso",
+			SourceNamespace: "redhat-rhoam-3scale-operator",
+		},
+		{
+			Name:            "test api server calls to the marin3r webhook are allowed",
+			Namespace:       "redhat-rhoam-marin3r-operator",
+			SourceNamespace: "openshift-kube-apiserver",
+			Port:            webhookPort,
+			ExpectAllowed:   true,
+		},
+		{
+			Name:            "test customer traffic to 3scale services is blocked",
+			Namespace:       "redhat-rhoam-3scale",
+			SourceNamespace: "customer-project",
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.Name, func(t *testing.T) {
+			allowed := Allowed(rules[scenario.Namespace], scenario.SourceNamespace, scenario.SourceLabels, scenario.Port)
+			if allowed != scenario.ExpectAllowed {
+				t.Errorf("expected allowed %v, got %v", scenario.ExpectAllowed, allowed)
+			}
+		})
+	}
+}
+
+func TestRules_SharedNamespace(t *testing.T) {
+	rules := Rules(map[integreatlyv1alpha1.ProductName]ProductNamespaces{
+		integreatlyv1alpha1.ProductRHSSO:     {Namespace: "redhat-rhoam-sso"},
+		integreatlyv1alpha1.ProductRHSSOUser: {Namespace: "redhat-rhoam-sso"},
+		integreatlyv1alpha1.Product3Scale:    {Namespace: "redhat-rhoam-3scale"},
+	}, "redhat-rhoam-operator", "")
+
+	names := map[string]bool{}
+	for _, policy := range Policies("redhat-rhoam-sso", rules["redhat-rhoam-sso"]) {
+		if names[policy.Name] {
+			t.Errorf("expected the rules of products sharing a namespace to be merged, got duplicate policy %s", policy.Name)
+		}
+		names[policy.Name] = true
+	}
+	if !names[DefaultDenyPolicyName] || !names["rhoam-allow-3scale"] {
+		t.Errorf("expected the default deny and 3scale policies, got %v", names)
+	}
+}
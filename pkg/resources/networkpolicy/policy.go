@@ -0,0 +1,160 @@
+// Package networkpolicy isolates the RHOAM namespaces with NetworkPolicies.
+//
+// Every namespace gets a policy denying all ingress, and a policy allowing
+// each of the flows into the namespace derived from the products installed:
+// the flows common to all namespaces (same namespace, monitoring, the RHMI
+// operator), and the flows between products declared in productIngress.
+// Only ingress is restricted, egress is left open
+package networkpolicy
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// ManagedLabel marks the NetworkPolicies reconciled by the operator
+	ManagedLabel = "integreatly.org/network-policy"
+
+	// NamespaceNameLabel is set on the namespaces to select them by name in
+	// the policies. Kubernetes 1.21 sets it on every namespace
+	NamespaceNameLabel = "kubernetes.io/metadata.name"
+
+	// PolicyGroupLabel selects the OpenShift namespaces hosting the router
+	// and the cluster monitoring stack
+	PolicyGroupLabel = "network.openshift.io/policy-group"
+
+	DefaultDenyPolicyName = "rhoam-default-deny"
+	policyNamePrefix      = "rhoam-allow-"
+)
+
+// Peer is a source of ingress traffic
+type Peer struct {
+	// Namespace the traffic comes from
+	Namespace string
+	// NamespaceLabels of the namespaces the traffic comes from, when they
+	// are not selected by name
+	NamespaceLabels map[string]string
+}
+
+// Rule allows ingress traffic into a namespace
+type Rule struct {
+	// Name of the flow, the NetworkPolicy is named after it
+	Name string
+	// From are the sources allowed. Traffic from everywhere is allowed when
+	// empty
+	From []Peer
+	// Ports are the TCP ports allowed. All ports are allowed when empty
+	Ports []int32
+}
+
+// PolicyName returns the name of the NetworkPolicy of the rule
+func (r Rule) PolicyName() string {
+	return policyNamePrefix + r.Name
+}
+
+// Allows returns whether the rule allows traffic from the namespace with the
+// name and labels to port. A port of 0 is an unknown port, allowed when the
+// source is
+func (r Rule) Allows(namespace string, labels map[string]string, port int32) bool {
+	if port != 0 && len(r.Ports) > 0 && !containsPort(r.Ports, port) {
+		return false
+	}
+	if len(r.From) == 0 {
+		return true
+	}
+	for _, peer := range r.From {
+		if peer.matches(namespace, labels) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p Peer) matches(namespace string, labels map[string]string) bool {
+	if p.Namespace != "" {
+		return p.Namespace == namespace
+	}
+	for key, value := range p.NamespaceLabels {
+		if labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Allowed returns whether any of the rules allows traffic from the namespace
+// with the name and labels to port
+func Allowed(rules []Rule, namespace string, labels map[string]string, port int32) bool {
+	for _, rule := range rules {
+		if rule.Allows(namespace, labels, port) {
+			return true
+		}
+	}
+	return false
+}
+
+// Policies returns the NetworkPolicies isolating the namespace: the default
+// deny policy and a policy for each of the rules
+func Policies(namespace string, rules []Rule) []*networkingv1.NetworkPolicy {
+	policies := []*networkingv1.NetworkPolicy{
+		newPolicy(DefaultDenyPolicyName, namespace, nil),
+	}
+	for _, rule := range rules {
+		ingress := networkingv1.NetworkPolicyIngressRule{}
+		for _, peer := range rule.From {
+			ingress.From = append(ingress.From, peer.policyPeer())
+		}
+		for _, port := range rule.Ports {
+			ingress.Ports = append(ingress.Ports, policyPort(port))
+		}
+		policies = append(policies, newPolicy(rule.PolicyName(), namespace, []networkingv1.NetworkPolicyIngressRule{ingress}))
+	}
+	return policies
+}
+
+func newPolicy(name, namespace string, ingress []networkingv1.NetworkPolicyIngressRule) *networkingv1.NetworkPolicy {
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				ManagedLabel:  "true",
+				"integreatly": "true",
+			},
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			// an empty selector selects all the pods of the namespace
+			PodSelector: metav1.LabelSelector{},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			Ingress:     ingress,
+		},
+	}
+}
+
+func (p Peer) policyPeer() networkingv1.NetworkPolicyPeer {
+	labels := p.NamespaceLabels
+	if p.Namespace != "" {
+		labels = map[string]string{NamespaceNameLabel: p.Namespace}
+	}
+	return networkingv1.NetworkPolicyPeer{
+		NamespaceSelector: &metav1.LabelSelector{MatchLabels: labels},
+	}
+}
+
+func policyPort(port int32) networkingv1.NetworkPolicyPort {
+	protocol := corev1.ProtocolTCP
+	value := intstr.FromInt(int(port))
+	return networkingv1.NetworkPolicyPort{Protocol: &protocol, Port: &value}
+}
+
+func containsPort(ports []int32, port int32) bool {
+	for _, p := range ports {
+		if p == port {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package networkpolicy
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// Reconcile creates the NetworkPolicies isolating the namespace with the
+// rules, and deletes the policies of rules that no longer apply. Namespaces
+// that don't exist yet are skipped
+func Reconcile(ctx context.Context, client k8sclient.Client, namespace string, rules []Rule) error {
+	exists, err := labelNamespace(ctx, client, namespace)
+	if err != nil || !exists {
+		return err
+	}
+
+	desired := map[string]bool{}
+	for _, policy := range Policies(namespace, rules) {
+		desired[policy.Name] = true
+		spec := policy.Spec
+		labels := policy.Labels
+		if _, err := controllerutil.CreateOrUpdate(ctx, client, policy, func() error {
+			if policy.Labels == nil {
+				policy.Labels = map[string]string{}
+			}
+			for key, value := range labels {
+				policy.Labels[key] = value
+			}
+			policy.Spec = spec
+			return nil
+		}); err != nil {
+			return fmt.Errorf("failed to reconcile network policy %s in namespace %s: %w", policy.Name, namespace, err)
+		}
+	}
+
+	return deletePolicies(ctx, client, namespace, desired)
+}
+
+// Remove deletes the NetworkPolicies reconciled in the namespace
+func Remove(ctx context.Context, client k8sclient.Client, namespace string) error {
+	return deletePolicies(ctx, client, namespace, map[string]bool{})
+}
+
+func deletePolicies(ctx context.Context, client k8sclient.Client, namespace string, keep map[string]bool) error {
+	policies := &networkingv1.NetworkPolicyList{}
+	if err := client.List(ctx, policies, k8sclient.InNamespace(namespace), k8sclient.MatchingLabels{ManagedLabel: "true"}); err != nil {
+		return fmt.Errorf("failed to list network policies in namespace %s: %w", namespace, err)
+	}
+	for i := range policies.Items {
+		policy := &policies.Items[i]
+		if keep[policy.Name] {
+			continue
+		}
+		if err := client.Delete(ctx, policy); err != nil && !k8serr.IsNotFound(err) {
+			return fmt.Errorf("failed to delete network policy %s in namespace %s: %w", policy.Name, namespace, err)
+		}
+	}
+	return nil
+}
+
+// labelNamespace sets the NamespaceNameLabel the policies select namespaces
+// by, on clusters that don't set it
+func labelNamespace(ctx context.Context, client k8sclient.Client, name string) (bool, error) {
+	namespace := &corev1.Namespace{}
+	if err := client.Get(ctx, k8sclient.ObjectKey{Name: name}, namespace); err != nil {
+		if k8serr.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get namespace %s: %w", name, err)
+	}
+	if namespace.Labels[NamespaceNameLabel] == name {
+		return true, nil
+	}
+	if namespace.Labels == nil {
+		namespace.Labels = map[string]string{}
+	}
+	namespace.Labels[NamespaceNameLabel] = name
+	if err := client.Update(ctx, namespace); err != nil {
+		return false, fmt.Errorf("failed to label namespace %s: %w", name, err)
+	}
+	return true, nil
+}
+
+// LabelNamespace sets the NamespaceNameLabel on a namespace the rules select
+// by name, like the RHMI operator namespace
+func LabelNamespace(ctx context.Context, client k8sclient.Client, name string) error {
+	_, err := labelNamespace(ctx, client, name)
+	return err
+}
@@ -0,0 +1,98 @@
+package networkpolicy
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func buildScheme(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to build scheme: %v", err)
+	}
+	if err := networkingv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to build scheme: %v", err)
+	}
+	return scheme
+}
+
+func TestReconcile(t *testing.T) {
+	namespace := "redhat-rhoam-marin3r"
+	stale := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rhoam-allow-removed",
+			Namespace: namespace,
+			Labels:    map[string]string{ManagedLabel: "true"},
+		},
+	}
+	customer := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "allow-customer", Namespace: namespace},
+	}
+	client := fakeclient.NewFakeClientWithScheme(buildScheme(t),
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}},
+		stale,
+		customer,
+	)
+
+	rules := Rules(rhoamProducts, "redhat-rhoam-operator", "redhat-rhoam-observability")[namespace]
+	if err := Reconcile(context.TODO(), client, namespace, rules); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	policies := &networkingv1.NetworkPolicyList{}
+	if err := client.List(context.TODO(), policies, k8sclient.InNamespace(namespace)); err != nil {
+		t.Fatalf("failed to list policies: %v", err)
+	}
+	names := map[string]bool{}
+	for _, policy := range policies.Items {
+		names[policy.Name] = true
+	}
+	if len(names) != len(rules)+2 {
+		t.Errorf("expected the default deny, rule and customer policies, got %v", names)
+	}
+	if !names[DefaultDenyPolicyName] || !names["rhoam-allow-3scale"] || !names[customer.Name] {
+		t.Errorf("expected the default deny, 3scale and customer policies, got %v", names)
+	}
+	if names[stale.Name] {
+		t.Errorf("expected the stale policy to be deleted")
+	}
+
+	reconciled := &corev1.Namespace{}
+	if err := client.Get(context.TODO(), k8sclient.ObjectKey{Name: namespace}, reconciled); err != nil {
+		t.Fatalf("failed to get namespace: %v", err)
+	}
+	if reconciled.Labels[NamespaceNameLabel] != namespace {
+		t.Errorf("expected the namespace to be labelled with its name, got %v", reconciled.Labels)
+	}
+
+	if err := Remove(context.TODO(), client, namespace); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := client.List(context.TODO(), policies, k8sclient.InNamespace(namespace)); err != nil {
+		t.Fatalf("failed to list policies: %v", err)
+	}
+	if len(policies.Items) != 1 || policies.Items[0].Name != customer.Name {
+		t.Errorf("expected only the customer policy to be left, got %v", policies.Items)
+	}
+}
+
+func TestReconcile_MissingNamespace(t *testing.T) {
+	client := fakeclient.NewFakeClientWithScheme(buildScheme(t))
+	if err := Reconcile(context.TODO(), client, "redhat-rhoam-3scale", []Rule{{Name: "router"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	policies := &networkingv1.NetworkPolicyList{}
+	if err := client.List(context.TODO(), policies); err != nil {
+		t.Fatalf("failed to list policies: %v", err)
+	}
+	if len(policies.Items) != 0 {
+		t.Errorf("expected no policies in a missing namespace, got %d", len(policies.Items))
+	}
+}
@@ -0,0 +1,54 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const WorkerNodeLabel = "node-role.kubernetes.io/worker"
+
+// GetSchedulableCapacity returns the resources of the schedulable worker nodes
+// that aren't requested by the pods running on them
+func GetSchedulableCapacity(ctx context.Context, client k8sclient.Client) (corev1.ResourceList, error) {
+	nodes := &corev1.NodeList{}
+	if err := client.List(ctx, nodes, k8sclient.HasLabels{WorkerNodeLabel}); err != nil {
+		return nil, fmt.Errorf("failed to list the worker nodes: %w", err)
+	}
+	free := corev1.ResourceList{}
+	workers := map[string]bool{}
+	for _, node := range nodes.Items {
+		if node.Spec.Unschedulable {
+			continue
+		}
+		workers[node.Name] = true
+		for name, quantity := range node.Status.Allocatable {
+			sum := free[name]
+			sum.Add(quantity)
+			free[name] = sum
+		}
+	}
+	if len(workers) == 0 {
+		return nil, fmt.Errorf("no schedulable worker nodes found")
+	}
+
+	pods := &corev1.PodList{}
+	if err := client.List(ctx, pods); err != nil {
+		return nil, fmt.Errorf("failed to list the pods: %w", err)
+	}
+	for _, pod := range pods.Items {
+		if !workers[pod.Spec.NodeName] || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		for _, container := range pod.Spec.Containers {
+			for name, quantity := range container.Resources.Requests {
+				difference := free[name]
+				difference.Sub(quantity)
+				free[name] = difference
+			}
+		}
+	}
+	return free, nil
+}
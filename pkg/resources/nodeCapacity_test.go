@@ -0,0 +1,61 @@
+package resources
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetSchedulableCapacity(t *testing.T) {
+	scheme, err := buildScheme()
+	if err != nil {
+		t.Fatalf("failed to build scheme: %v", err)
+	}
+	unschedulable := getWorkerNode("unschedulable", "4")
+	unschedulable.Spec.Unschedulable = true
+	pod := func(name, node string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "other"},
+			Spec: corev1.PodSpec{
+				NodeName: node,
+				Containers: []corev1.Container{{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+					},
+				}},
+			},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+	client := fake.NewFakeClientWithScheme(scheme,
+		getWorkerNode("worker", "2"),
+		unschedulable,
+		pod("running", "worker", corev1.PodRunning),
+		pod("succeeded", "worker", corev1.PodSucceeded),
+		pod("elsewhere", "unschedulable", corev1.PodRunning),
+	)
+
+	free, err := GetSchedulableCapacity(context.TODO(), client)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cpu := free[corev1.ResourceCPU]; cpu.Cmp(resource.MustParse("1500m")) != 0 {
+		t.Errorf("expected 1500m cpu free, got %s", cpu.String())
+	}
+}
+
+func getWorkerNode(name, cpu string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{WorkerNodeLabel: ""},
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)},
+		},
+	}
+}
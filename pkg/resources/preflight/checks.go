@@ -0,0 +1,243 @@
+package preflight
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/resources"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/quota"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/secretprovider"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/smtphealth"
+	configv1 "github.com/openshift/api/config/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	ClusterVersionCheckName   = "cluster-version"
+	NodeCapacityCheckName     = "node-capacity"
+	MultiAZCheckName          = "multi-az"
+	PullSecretCheckName       = "pull-secret"
+	SMTPSecretCheckName       = "smtp-secret"
+	CloudCredentialsCheckName = "cloud-credentials"
+
+	// the product images are pulled from the Red Hat registry
+	productRegistry = "registry.redhat.io"
+
+	// cloudResourcesCredentialsName is the credentials request of the cloud
+	// resources operator, and the secret its credentials are minted in
+	cloudResourcesCredentialsName = "cloud-resources-aws-credentials"
+	cloudCredentialsModeManual    = "Manual"
+
+	// clusterConfigName is the name of the cluster wide configs
+	clusterConfigName = "cluster"
+)
+
+var (
+	cloudCredentialGVK    = schema.GroupVersionKind{Group: "operator.openshift.io", Version: "v1", Kind: "CloudCredential"}
+	credentialsRequestGVK = schema.GroupVersionKind{Group: "cloudcredential.openshift.io", Version: "v1", Kind: "CredentialsRequest"}
+)
+
+// DefaultChecks returns the checks of the cluster run before every
+// installation. The node capacity is checked against the resources requested
+// by installationQuota, when the installation has a quota. The credentials of
+// the cloud resources operator are checked in cloudResourcesNamespace, once
+// it is installed
+func DefaultChecks(client k8sclient.Client, installationQuota *quota.Quota, cloudResourcesNamespace string) []Check {
+	return []Check{
+		NewCheck(ClusterVersionCheckName, integreatlyv1alpha1.PreflightSeverityWarning, clusterVersionCheck(client)),
+		NewCheck(NodeCapacityCheckName, integreatlyv1alpha1.PreflightSeverityWarning, nodeCapacityCheck(client, installationQuota)),
+		NewCheck(MultiAZCheckName, integreatlyv1alpha1.PreflightSeverityWarning, multiAZCheck(client)),
+		NewCheck(PullSecretCheckName, integreatlyv1alpha1.PreflightSeverityBlocking, pullSecretCheck(client)),
+		NewCheck(SMTPSecretCheckName, integreatlyv1alpha1.PreflightSeverityWarning, smtpSecretCheck(client)),
+		NewCheck(CloudCredentialsCheckName, integreatlyv1alpha1.PreflightSeverityWarning, cloudCredentialsCheck(client, cloudResourcesNamespace)),
+	}
+}
+
+// clusterVersionCheck fails on clusters before 4.9, some of the metrics the
+// dashboards and alerts rely on were renamed in 4.9
+func clusterVersionCheck(client k8sclient.Client) CheckFunc {
+	return func(ctx context.Context, installation *integreatlyv1alpha1.RHMI) error {
+		before49, err := resources.ClusterVersionBefore49(ctx, client, log)
+		if err != nil {
+			return err
+		}
+		if before49 {
+			return fmt.Errorf("the cluster version is before 4.9, upgrade the cluster to 4.9 or later")
+		}
+		return nil
+	}
+}
+
+// nodeCapacityCheck fails when the schedulable worker nodes don't have the
+// CPU and memory free the quota requests
+func nodeCapacityCheck(client k8sclient.Client, installationQuota *quota.Quota) CheckFunc {
+	return func(ctx context.Context, installation *integreatlyv1alpha1.RHMI) error {
+		if installationQuota == nil {
+			return nil
+		}
+
+		free, err := resources.GetSchedulableCapacity(ctx, client)
+		if err != nil {
+			return err
+		}
+
+		requests := installationQuota.GetResourceRequests()
+		insufficient := []string{}
+		for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			request, available := requests[name], free[name]
+			if request.Cmp(available) > 0 {
+				insufficient = append(insufficient, fmt.Sprintf("%s requests %s of %s, %s free", installationQuota.GetName(), request.String(), name, available.String()))
+			}
+		}
+		if len(insufficient) > 0 {
+			return fmt.Errorf("the worker nodes don't have the capacity for the quota: %s", strings.Join(insufficient, ", "))
+		}
+		return nil
+	}
+}
+
+// multiAZCheck fails when the nodes are all in the same availability zone,
+// the products are then down while the zone is
+func multiAZCheck(client k8sclient.Client) CheckFunc {
+	return func(ctx context.Context, installation *integreatlyv1alpha1.RHMI) error {
+		multiAZ, err := resources.IsMultiAZCluster(ctx, client)
+		if err != nil {
+			return fmt.Errorf("failed to check the availability zones of the nodes: %w", err)
+		}
+		if !multiAZ {
+			return fmt.Errorf("the nodes are all in the same availability zone, the products won't be highly available")
+		}
+		return nil
+	}
+}
+
+// pullSecretCheck fails when the pull secret the product images are pulled
+// with doesn't hold credentials for the Red Hat registry
+func pullSecretCheck(client k8sclient.Client) CheckFunc {
+	return func(ctx context.Context, installation *integreatlyv1alpha1.RHMI) error {
+		spec := installation.GetPullSecretSpec()
+		secret := &corev1.Secret{}
+		if err := client.Get(ctx, k8sclient.ObjectKey{Name: spec.Name, Namespace: spec.Namespace}, secret); err != nil {
+			return fmt.Errorf("failed to get pull secret %s/%s: %w", spec.Namespace, spec.Name, err)
+		}
+
+		config := struct {
+			Auths map[string]struct {
+				Auth string `json:"auth"`
+			} `json:"auths"`
+		}{}
+		if err := json.Unmarshal(secret.Data[corev1.DockerConfigJsonKey], &config); err != nil {
+			return fmt.Errorf("pull secret %s/%s has no valid %s: %w", spec.Namespace, spec.Name, corev1.DockerConfigJsonKey, err)
+		}
+		if config.Auths[productRegistry].Auth == "" {
+			return fmt.Errorf("pull secret %s/%s has no credentials for %s", spec.Namespace, spec.Name, productRegistry)
+		}
+		return nil
+	}
+}
+
+// smtpSecretCheck fails when the SMTP secret is missing or incomplete, no
+// email is sent by the alerts and 3scale then
+func smtpSecretCheck(client k8sclient.Client) CheckFunc {
+	return func(ctx context.Context, installation *integreatlyv1alpha1.RHMI) error {
+		if installation.Spec.SMTPSecret == "" {
+			return fmt.Errorf("no SMTP secret configured, email won't be sent")
+		}
+
+		secretProvider, err := secretprovider.New(client, installation.Namespace)
+		if err != nil {
+			return err
+		}
+		data, err := secretProvider.GetSecret(ctx, installation.Spec.SMTPSecret)
+		if err != nil {
+			return fmt.Errorf("failed to get SMTP secret %s: %w", installation.Spec.SMTPSecret, err)
+		}
+		if _, err := smtphealth.CredentialsFromSecret(data); err != nil {
+			return fmt.Errorf("SMTP secret %s is invalid: %w", installation.Spec.SMTPSecret, err)
+		}
+		return nil
+	}
+}
+
+// cloudCredentialsCheck warns when the cloud resources operator can't get
+// credentials to provision the cloud resources, on installations that don't
+// use the cluster storage. The credentials are minted by the cloud credential
+// operator from the credentials request of the cloud resources operator, or
+// provided in its secret when the cloud credential operator is in manual mode
+func cloudCredentialsCheck(client k8sclient.Client, cloudResourcesNamespace string) CheckFunc {
+	return func(ctx context.Context, installation *integreatlyv1alpha1.RHMI) error {
+		if strings.ToLower(installation.Spec.UseClusterStorage) != "false" {
+			return nil
+		}
+
+		infrastructure := &configv1.Infrastructure{}
+		if err := client.Get(ctx, k8sclient.ObjectKey{Name: clusterConfigName}, infrastructure); err != nil {
+			return fmt.Errorf("failed to get the cluster infrastructure: %w", err)
+		}
+		platform := infrastructure.Status.Platform
+		if infrastructure.Status.PlatformStatus != nil {
+			platform = infrastructure.Status.PlatformStatus.Type
+		}
+		if platform != configv1.AWSPlatformType {
+			return fmt.Errorf("cloud resources can't be provisioned on %s, set useClusterStorage to true", platform)
+		}
+
+		cloudCredential := &unstructured.Unstructured{}
+		cloudCredential.SetGroupVersionKind(cloudCredentialGVK)
+		if err := client.Get(ctx, k8sclient.ObjectKey{Name: clusterConfigName}, cloudCredential); err != nil && !k8serr.IsNotFound(err) {
+			return fmt.Errorf("failed to get the cloud credential operator config: %w", err)
+		}
+		mode, _, _ := unstructured.NestedString(cloudCredential.Object, "spec", "credentialsMode")
+		manual := mode == cloudCredentialsModeManual
+
+		// the cloud resources operator isn't installed yet, its credentials
+		// are requested once it is
+		if cloudResourcesNamespace == "" {
+			if manual {
+				return fmt.Errorf("the cloud credential operator is in manual mode, the credentials of the cloud resources operator must be provided in secret %s", cloudResourcesCredentialsName)
+			}
+			return nil
+		}
+
+		credentialsRequest := &unstructured.Unstructured{}
+		credentialsRequest.SetGroupVersionKind(credentialsRequestGVK)
+		err := client.Get(ctx, k8sclient.ObjectKey{Name: cloudResourcesCredentialsName, Namespace: cloudResourcesNamespace}, credentialsRequest)
+		if err != nil && !k8serr.IsNotFound(err) {
+			return fmt.Errorf("failed to get credentials request %s/%s: %w", cloudResourcesNamespace, cloudResourcesCredentialsName, err)
+		}
+		secretName, secretNamespace := cloudResourcesCredentialsName, cloudResourcesNamespace
+		if err == nil {
+			if provisioned, _, _ := unstructured.NestedBool(credentialsRequest.Object, "status", "provisioned"); !provisioned && !manual {
+				return fmt.Errorf("credentials request %s/%s isn't provisioned by the cloud credential operator", cloudResourcesNamespace, cloudResourcesCredentialsName)
+			}
+			if name, _, _ := unstructured.NestedString(credentialsRequest.Object, "spec", "secretRef", "name"); name != "" {
+				secretName = name
+			}
+			if namespace, _, _ := unstructured.NestedString(credentialsRequest.Object, "spec", "secretRef", "namespace"); namespace != "" {
+				secretNamespace = namespace
+			}
+		} else if !manual {
+			// the cloud resources operator hasn't requested its credentials
+			// yet
+			return nil
+		}
+
+		secret := &corev1.Secret{}
+		if err := client.Get(ctx, k8sclient.ObjectKey{Name: secretName, Namespace: secretNamespace}, secret); err != nil {
+			return fmt.Errorf("failed to get the cloud resources operator credentials %s/%s: %w", secretNamespace, secretName, err)
+		}
+		for _, key := range []string{"aws_access_key_id", "aws_secret_access_key"} {
+			if len(secret.Data[key]) == 0 {
+				return fmt.Errorf("the cloud resources operator credentials %s/%s have no %s", secretNamespace, secretName, key)
+			}
+		}
+		return nil
+	}
+}
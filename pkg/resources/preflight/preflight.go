@@ -0,0 +1,100 @@
+// Package preflight checks the cluster is ready for an installation before
+// its stages are reconciled, so the problems that would fail the installation
+// late are reported upfront. Each check has a severity: the installation
+// doesn't start while a blocking check fails, a failed warning check is
+// reported only.
+//
+// The results of the checks are listed in the preflightChecks status field
+// of the RHMI CR
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+)
+
+var log = l.NewLoggerWithContext(l.Fields{l.ComponentLogContext: "preflight"})
+
+// Check is a preflight check of the installation
+type Check interface {
+	// Name identifies the check in the status of the installation
+	Name() string
+	// Severity of the check when it fails
+	Severity() integreatlyv1alpha1.PreflightCheckSeverity
+	// Run returns an error explaining why the check fails
+	Run(ctx context.Context, installation *integreatlyv1alpha1.RHMI) error
+}
+
+// CheckFunc runs a check, returning an error explaining why it fails
+type CheckFunc func(ctx context.Context, installation *integreatlyv1alpha1.RHMI) error
+
+type check struct {
+	name     string
+	severity integreatlyv1alpha1.PreflightCheckSeverity
+	run      CheckFunc
+}
+
+// NewCheck returns a check named name, run by run
+func NewCheck(name string, severity integreatlyv1alpha1.PreflightCheckSeverity, run CheckFunc) Check {
+	return &check{
+		name:     name,
+		severity: severity,
+		run:      run,
+	}
+}
+
+func (c *check) Name() string {
+	return c.name
+}
+
+func (c *check) Severity() integreatlyv1alpha1.PreflightCheckSeverity {
+	return c.severity
+}
+
+func (c *check) Run(ctx context.Context, installation *integreatlyv1alpha1.RHMI) error {
+	return c.run(ctx, installation)
+}
+
+// Run runs the checks in order and returns their results
+func Run(ctx context.Context, installation *integreatlyv1alpha1.RHMI, checks []Check) []integreatlyv1alpha1.PreflightCheckResult {
+	results := make([]integreatlyv1alpha1.PreflightCheckResult, 0, len(checks))
+	for _, check := range checks {
+		result := integreatlyv1alpha1.PreflightCheckResult{
+			Name:     check.Name(),
+			Severity: check.Severity(),
+			Passed:   true,
+		}
+		if err := check.Run(ctx, installation); err != nil {
+			result.Passed = false
+			result.Message = err.Error()
+			log.Warningf("Preflight check failed", l.Fields{"check": result.Name, "severity": result.Severity, "error": result.Message})
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// Failed returns the results of the failed checks with severity
+func Failed(results []integreatlyv1alpha1.PreflightCheckResult, severity integreatlyv1alpha1.PreflightCheckSeverity) []integreatlyv1alpha1.PreflightCheckResult {
+	failed := []integreatlyv1alpha1.PreflightCheckResult{}
+	for _, result := range results {
+		if !result.Passed && result.Severity == severity {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// Message summarises the failed results, for the preflightMessage status
+// field
+func Message(failed []integreatlyv1alpha1.PreflightCheckResult) string {
+	messages := make([]string, 0, len(failed))
+	for _, result := range failed {
+		messages = append(messages, fmt.Sprintf("%s: %s", result.Name, result.Message))
+	}
+	return strings.Join(messages, "; ")
+}
@@ -0,0 +1,399 @@
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/addon"
+	"github.com/integr8ly/integreatly-operator/pkg/resources"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/quota"
+	configv1 "github.com/openshift/api/config/v1"
+	cloudcredentialv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	installationNamespace   = "redhat-rhoam-operator"
+	cloudResourcesNamespace = "redhat-rhoam-cloud-resources-operator"
+)
+
+func buildScheme(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := configv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := cloudcredentialv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	return scheme
+}
+
+func buildInstallation() *integreatlyv1alpha1.RHMI {
+	return &integreatlyv1alpha1.RHMI{
+		ObjectMeta: metav1.ObjectMeta{Name: "rhoam", Namespace: installationNamespace},
+		Spec: integreatlyv1alpha1.RHMISpec{
+			Type:              string(integreatlyv1alpha1.InstallationTypeManagedApi),
+			SMTPSecret:        "redhat-rhoam-smtp",
+			UseClusterStorage: "false",
+		},
+	}
+}
+
+func buildNode(name, zone, cpu, memory string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{resources.WorkerNodeLabel: "", resources.ZoneLabel: zone},
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(memory),
+			},
+		},
+	}
+}
+
+func buildPullSecret(dockerConfig string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      integreatlyv1alpha1.DefaultOriginPullSecretName,
+			Namespace: integreatlyv1alpha1.DefaultOriginPullSecretNamespace,
+		},
+		Data: map[string][]byte{corev1.DockerConfigJsonKey: []byte(dockerConfig)},
+	}
+}
+
+func buildQuota(t *testing.T, param string) *quota.Quota {
+	installationQuota := &quota.Quota{}
+	quotaConfig := &corev1.ConfigMap{
+		Data: map[string]string{quota.ConfigMapData: addon.GetQuotaConfig(string(integreatlyv1alpha1.InstallationTypeManagedApi))},
+	}
+	if err := quota.GetQuota(param, quotaConfig, installationQuota); err != nil {
+		t.Fatal(err)
+	}
+	return installationQuota
+}
+
+func TestRun(t *testing.T) {
+	checks := []Check{
+		NewCheck("passing", integreatlyv1alpha1.PreflightSeverityBlocking, func(ctx context.Context, installation *integreatlyv1alpha1.RHMI) error {
+			return nil
+		}),
+		NewCheck("blocking", integreatlyv1alpha1.PreflightSeverityBlocking, func(ctx context.Context, installation *integreatlyv1alpha1.RHMI) error {
+			return fmt.Errorf("blocked")
+		}),
+		NewCheck("warning", integreatlyv1alpha1.PreflightSeverityWarning, func(ctx context.Context, installation *integreatlyv1alpha1.RHMI) error {
+			return fmt.Errorf("warned")
+		}),
+	}
+
+	results := Run(context.TODO(), buildInstallation(), checks)
+	if len(results) != 3 || results[0].Name != "passing" || !results[0].Passed {
+		t.Fatalf("expected the results of the checks in order, got %v", results)
+	}
+
+	blocking := Failed(results, integreatlyv1alpha1.PreflightSeverityBlocking)
+	if len(blocking) != 1 || blocking[0].Name != "blocking" {
+		t.Errorf("expected the blocking check to fail, got %v", blocking)
+	}
+	if message := Message(blocking); message != "blocking: blocked" {
+		t.Errorf("unexpected message %q", message)
+	}
+	if warnings := Failed(results, integreatlyv1alpha1.PreflightSeverityWarning); len(warnings) != 1 || warnings[0].Message != "warned" {
+		t.Errorf("expected the warning check to fail, got %v", warnings)
+	}
+}
+
+func TestChecks(t *testing.T) {
+	scheme := buildScheme(t)
+
+	cases := []struct {
+		Name         string
+		Check        func(client k8sclient.Client) CheckFunc
+		Installation func(installation *integreatlyv1alpha1.RHMI)
+		Objects      []runtime.Object
+		ExpectError  string
+	}{
+		{
+			Name:  "test cluster version check passes on 4.9",
+			Check: clusterVersionCheck,
+			Objects: []runtime.Object{&configv1.ClusterVersion{
+				ObjectMeta: metav1.ObjectMeta{Name: "version"},
+				Status:     configv1.ClusterVersionStatus{History: []configv1.UpdateHistory{{Version: "4.9.4"}}},
+			}},
+		},
+		{
+			Name:  "test cluster version check fails before 4.9",
+			Check: clusterVersionCheck,
+			Objects: []runtime.Object{&configv1.ClusterVersion{
+				ObjectMeta: metav1.ObjectMeta{Name: "version"},
+				Status:     configv1.ClusterVersionStatus{History: []configv1.UpdateHistory{{Version: "4.8.12"}}},
+			}},
+			ExpectError: "before 4.9",
+		},
+		{
+			Name:    "test multi AZ check passes with nodes in different zones",
+			Check:   multiAZCheck,
+			Objects: []runtime.Object{buildNode("worker-a", "eu-west-1a", "4", "16Gi"), buildNode("worker-b", "eu-west-1b", "4", "16Gi")},
+		},
+		{
+			Name:        "test multi AZ check fails with nodes in the same zone",
+			Check:       multiAZCheck,
+			Objects:     []runtime.Object{buildNode("worker-a", "eu-west-1a", "4", "16Gi"), buildNode("worker-b", "eu-west-1a", "4", "16Gi")},
+			ExpectError: "same availability zone",
+		},
+		{
+			Name:    "test pull secret check passes with credentials for the Red Hat registry",
+			Check:   pullSecretCheck,
+			Objects: []runtime.Object{buildPullSecret(`{"auths":{"registry.redhat.io":{"auth":"dXNlcjpwYXNz"}}}`)},
+		},
+		{
+			Name:        "test pull secret check fails without credentials for the Red Hat registry",
+			Check:       pullSecretCheck,
+			Objects:     []runtime.Object{buildPullSecret(`{"auths":{"quay.io":{"auth":"dXNlcjpwYXNz"}}}`)},
+			ExpectError: "no credentials for registry.redhat.io",
+		},
+		{
+			Name:        "test pull secret check fails on an invalid docker config",
+			Check:       pullSecretCheck,
+			Objects:     []runtime.Object{buildPullSecret(`{`)},
+			ExpectError: "no valid .dockerconfigjson",
+		},
+		{
+			Name:        "test pull secret check fails when the pull secret is missing",
+			Check:       pullSecretCheck,
+			ExpectError: "failed to get pull secret",
+		},
+		{
+			Name:  "test SMTP secret check passes with a complete secret",
+			Check: smtpSecretCheck,
+			Objects: []runtime.Object{&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "redhat-rhoam-smtp", Namespace: installationNamespace},
+				Data:       map[string][]byte{"host": []byte("smtp.example.com"), "port": []byte("587")},
+			}},
+		},
+		{
+			Name:  "test SMTP secret check fails with an incomplete secret",
+			Check: smtpSecretCheck,
+			Objects: []runtime.Object{&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "redhat-rhoam-smtp", Namespace: installationNamespace},
+				Data:       map[string][]byte{"host": []byte("smtp.example.com")},
+			}},
+			ExpectError: "invalid port",
+		},
+		{
+			Name:  "test SMTP secret check fails when no secret is configured",
+			Check: smtpSecretCheck,
+			Installation: func(installation *integreatlyv1alpha1.RHMI) {
+				installation.Spec.SMTPSecret = ""
+			},
+			ExpectError: "no SMTP secret configured",
+		},
+		{
+			Name:  "test cloud credentials check is skipped with cluster storage",
+			Check: croCredentialsCheck,
+			Installation: func(installation *integreatlyv1alpha1.RHMI) {
+				installation.Spec.UseClusterStorage = "true"
+			},
+		},
+		{
+			Name:        "test cloud credentials check fails on platforms other than AWS",
+			Check:       croCredentialsCheck,
+			Objects:     []runtime.Object{buildInfrastructure(configv1.GCPPlatformType)},
+			ExpectError: "can't be provisioned on GCP",
+		},
+		{
+			Name:    "test cloud credentials check passes before the credentials are requested",
+			Check:   croCredentialsCheck,
+			Objects: []runtime.Object{buildInfrastructure(configv1.AWSPlatformType)},
+		},
+		{
+			Name:  "test cloud credentials check fails while the credentials request isn't provisioned",
+			Check: croCredentialsCheck,
+			Objects: []runtime.Object{
+				buildInfrastructure(configv1.AWSPlatformType),
+				buildCredentialsRequest(false),
+			},
+			ExpectError: "isn't provisioned",
+		},
+		{
+			Name:  "test cloud credentials check fails when the minted credentials are incomplete",
+			Check: croCredentialsCheck,
+			Objects: []runtime.Object{
+				buildInfrastructure(configv1.AWSPlatformType),
+				buildCredentialsRequest(true),
+				buildCROCredentials(map[string][]byte{"aws_access_key_id": []byte("id")}),
+			},
+			ExpectError: "have no aws_secret_access_key",
+		},
+		{
+			Name:  "test cloud credentials check passes with the minted credentials",
+			Check: croCredentialsCheck,
+			Objects: []runtime.Object{
+				buildInfrastructure(configv1.AWSPlatformType),
+				buildCredentialsRequest(true),
+				buildCROCredentials(map[string][]byte{
+					"aws_access_key_id":     []byte("id"),
+					"aws_secret_access_key": []byte("key"),
+				}),
+			},
+		},
+		{
+			Name: "test cloud credentials check fails in manual mode before the cloud resources operator is installed",
+			Check: func(client k8sclient.Client) CheckFunc {
+				return cloudCredentialsCheck(client, "")
+			},
+			Objects: []runtime.Object{
+				buildInfrastructure(configv1.AWSPlatformType),
+				buildCloudCredential(cloudCredentialsModeManual),
+			},
+			ExpectError: "manual mode",
+		},
+		{
+			Name:  "test cloud credentials check passes in manual mode with the credentials provided",
+			Check: croCredentialsCheck,
+			Objects: []runtime.Object{
+				buildInfrastructure(configv1.AWSPlatformType),
+				buildCloudCredential(cloudCredentialsModeManual),
+				buildCROCredentials(map[string][]byte{
+					"aws_access_key_id":     []byte("id"),
+					"aws_secret_access_key": []byte("key"),
+				}),
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			installation := buildInstallation()
+			if tc.Installation != nil {
+				tc.Installation(installation)
+			}
+			client := fake.NewFakeClientWithScheme(scheme, tc.Objects...)
+
+			err := tc.Check(client)(context.TODO(), installation)
+			if tc.ExpectError == "" && err != nil {
+				t.Fatalf("expected the check to pass, got %v", err)
+			}
+			if tc.ExpectError != "" && (err == nil || !strings.Contains(err.Error(), tc.ExpectError)) {
+				t.Fatalf("expected the check to fail with %q, got %v", tc.ExpectError, err)
+			}
+		})
+	}
+}
+
+func TestNodeCapacityCheck(t *testing.T) {
+	scheme := buildScheme(t)
+	installationQuota := buildQuota(t, "200")
+
+	cases := []struct {
+		Name        string
+		Quota       *quota.Quota
+		Objects     []runtime.Object
+		ExpectError string
+	}{
+		{
+			Name:  "test node capacity check is skipped without a quota",
+			Quota: nil,
+		},
+		{
+			Name:    "test node capacity check passes when the workers have the capacity for the quota",
+			Quota:   installationQuota,
+			Objects: []runtime.Object{buildNode("worker-a", "eu-west-1a", "64", "256Gi")},
+		},
+		{
+			Name:  "test node capacity check fails when the pods running use the capacity",
+			Quota: installationQuota,
+			Objects: []runtime.Object{
+				buildNode("worker-a", "eu-west-1a", "64", "256Gi"),
+				&corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Name: "workload", Namespace: "default"},
+					Spec: corev1.PodSpec{
+						NodeName: "worker-a",
+						Containers: []corev1.Container{{
+							Name: "workload",
+							Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+								corev1.ResourceCPU: resource.MustParse("64"),
+							}},
+						}},
+					},
+					Status: corev1.PodStatus{Phase: corev1.PodRunning},
+				},
+			},
+			ExpectError: "of cpu",
+		},
+		{
+			Name:  "test node capacity check fails without schedulable workers",
+			Quota: installationQuota,
+			Objects: []runtime.Object{func() runtime.Object {
+				node := buildNode("worker-a", "eu-west-1a", "64", "256Gi")
+				node.Spec.Unschedulable = true
+				return node
+			}()},
+			ExpectError: "no schedulable worker nodes",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			client := fake.NewFakeClientWithScheme(scheme, tc.Objects...)
+
+			err := nodeCapacityCheck(client, tc.Quota)(context.TODO(), buildInstallation())
+			if tc.ExpectError == "" && err != nil {
+				t.Fatalf("expected the check to pass, got %v", err)
+			}
+			if tc.ExpectError != "" && (err == nil || !strings.Contains(err.Error(), tc.ExpectError)) {
+				t.Fatalf("expected the check to fail with %q, got %v", tc.ExpectError, err)
+			}
+		})
+	}
+}
+
+func croCredentialsCheck(client k8sclient.Client) CheckFunc {
+	return cloudCredentialsCheck(client, cloudResourcesNamespace)
+}
+
+func buildInfrastructure(platform configv1.PlatformType) *configv1.Infrastructure {
+	return &configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{Name: clusterConfigName},
+		Status:     configv1.InfrastructureStatus{PlatformStatus: &configv1.PlatformStatus{Type: platform}},
+	}
+}
+
+func buildCredentialsRequest(provisioned bool) *cloudcredentialv1.CredentialsRequest {
+	return &cloudcredentialv1.CredentialsRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: cloudResourcesCredentialsName, Namespace: cloudResourcesNamespace},
+		Spec: cloudcredentialv1.CredentialsRequestSpec{
+			SecretRef: corev1.ObjectReference{Name: cloudResourcesCredentialsName, Namespace: cloudResourcesNamespace},
+		},
+		Status: cloudcredentialv1.CredentialsRequestStatus{Provisioned: provisioned},
+	}
+}
+
+func buildCROCredentials(data map[string][]byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: cloudResourcesCredentialsName, Namespace: cloudResourcesNamespace},
+		Data:       data,
+	}
+}
+
+func buildCloudCredential(mode string) *unstructured.Unstructured {
+	cloudCredential := &unstructured.Unstructured{}
+	cloudCredential.SetGroupVersionKind(cloudCredentialGVK)
+	cloudCredential.SetName(clusterConfigName)
+	_ = unstructured.SetNestedField(cloudCredential.Object, mode, "spec", "credentialsMode")
+	return cloudCredential
+}
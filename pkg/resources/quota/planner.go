@@ -0,0 +1,311 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/resources"
+	appsv1 "github.com/openshift/api/apps/v1"
+	appsv12 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// workload is the object whose pods run with the resources of a resource
+// config of the quota
+type workload struct {
+	name string
+	// get returns the state of the rollout of the object
+	get func(ctx context.Context, client k8sclient.Client, key k8sclient.ObjectKey) (*workloadState, error)
+}
+
+// workloadState is the state of the rollout of a workload
+type workloadState struct {
+	template *corev1.PodTemplateSpec
+	// replicas is the number of replicas of the spec of the workload
+	replicas int32
+	// observed is whether the controller of the workload observed its latest
+	// spec
+	observed        bool
+	updatedReplicas int32
+	readyReplicas   int32
+}
+
+// workloads by resource config name. The grafana resource config isn't
+// applied by the operator
+var workloads = map[string]workload{
+	BackendListenerName:   {name: "backend-listener", get: getDeploymentConfigState},
+	BackendWorkerName:     {name: "backend-worker", get: getDeploymentConfigState},
+	ApicastProductionName: {name: "apicast-production", get: getDeploymentConfigState},
+	ApicastStagingName:    {name: "apicast-staging", get: getDeploymentConfigState},
+	KeycloakName:          {name: "keycloak", get: getStatefulSetState},
+	RateLimitName:         {name: RateLimitName, get: getDeploymentState},
+}
+
+// Plan is the rollout of a change of quota, one resource config at a time
+type Plan struct {
+	from  *Quota
+	to    *Quota
+	steps []Step
+}
+
+// Step of a plan, the change of the resources of a resource config
+type Step struct {
+	Product v1alpha1.ProductName
+	Name    string
+	// Delta is the change of the resources requested by the replicas of the
+	// resource config
+	Delta corev1.ResourceList
+}
+
+// NewPlan plans the change from the from quota to the to quota. The resource
+// configs whose requests decrease are rolled out first, to free capacity for
+// the ones whose requests increase
+func NewPlan(from, to *Quota) *Plan {
+	plan := &Plan{from: from, to: to}
+	for product, ddcssNames := range products {
+		for _, ddcssName := range ddcssNames {
+			fromConfig := from.productConfigs[product].resourceConfigs[ddcssName]
+			toConfig := to.productConfigs[product].resourceConfigs[ddcssName]
+			if fromConfig.Replicas == toConfig.Replicas && resourceListEqual(fromConfig.Resources.Requests, toConfig.Resources.Requests) &&
+				resourceListEqual(fromConfig.Resources.Limits, toConfig.Resources.Limits) {
+				continue
+			}
+
+			delta := toConfig.requests()
+			subtractResources(delta, fromConfig.requests())
+			plan.steps = append(plan.steps, Step{Product: product, Name: ddcssName, Delta: delta})
+		}
+	}
+
+	sort.SliceStable(plan.steps, func(i, j int) bool {
+		increasesI, increasesJ := increases(plan.steps[i].Delta), increases(plan.steps[j].Delta)
+		if increasesI != increasesJ {
+			return !increasesI
+		}
+		return plan.steps[i].Name < plan.steps[j].Name
+	})
+	return plan
+}
+
+// Steps returns the steps of the plan in rollout order
+func (p *Plan) Steps() []Step {
+	return p.steps
+}
+
+// Delta returns the change of the resources requested by the products
+func (p *Plan) Delta() corev1.ResourceList {
+	delta := corev1.ResourceList{}
+	for _, step := range p.steps {
+		addResources(delta, step.Delta)
+	}
+	return delta
+}
+
+// Validate checks the schedulable worker nodes have the capacity for the
+// increase of the CPU and memory requested, and that it fits in the resource
+// quotas of the namespaces of the products
+func (p *Plan) Validate(ctx context.Context, client k8sclient.Client, namespaces map[v1alpha1.ProductName]string) error {
+	free, err := resources.GetSchedulableCapacity(ctx, client)
+	if err != nil {
+		return err
+	}
+	delta := p.Delta()
+	insufficient := []string{}
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		increase, available := delta[name], free[name]
+		if increase.Sign() > 0 && increase.Cmp(available) > 0 {
+			insufficient = append(insufficient, fmt.Sprintf("%s more %s requested, %s free", increase.String(), name, available.String()))
+		}
+	}
+	if len(insufficient) > 0 {
+		return fmt.Errorf("the worker nodes don't have the capacity for quota %s: %s", p.to.GetName(), strings.Join(insufficient, ", "))
+	}
+
+	namespaceDeltas := map[string]corev1.ResourceList{}
+	for _, step := range p.steps {
+		namespace, ok := namespaces[step.Product]
+		if !ok {
+			continue
+		}
+		if namespaceDeltas[namespace] == nil {
+			namespaceDeltas[namespace] = corev1.ResourceList{}
+		}
+		addResources(namespaceDeltas[namespace], step.Delta)
+	}
+	for namespace, namespaceDelta := range namespaceDeltas {
+		resourceQuotas := &corev1.ResourceQuotaList{}
+		if err := client.List(ctx, resourceQuotas, k8sclient.InNamespace(namespace)); err != nil {
+			return fmt.Errorf("failed to list the resource quotas of %s: %w", namespace, err)
+		}
+		for _, resourceQuota := range resourceQuotas.Items {
+			for hardName, hard := range resourceQuota.Status.Hard {
+				increase := namespaceDelta[requestedResource(hardName)]
+				if increase.Sign() <= 0 {
+					continue
+				}
+				used := resourceQuota.Status.Used[hardName]
+				used.Add(increase)
+				if used.Cmp(hard) > 0 {
+					return fmt.Errorf("quota %s exceeds the %s of resource quota %s/%s: %s used of %s", p.to.GetName(), hardName, namespace, resourceQuota.Name, used.String(), hard.String())
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Ready returns whether the workload of step runs with the replicas and the
+// resources of the new quota, with all its replicas updated and ready. The
+// steps without a workload, or whose workload doesn't exist, are ready
+func (p *Plan) Ready(ctx context.Context, client k8sclient.Client, step Step, namespace string) (bool, error) {
+	workload, ok := workloads[step.Name]
+	if !ok {
+		return true, nil
+	}
+	state, err := workload.get(ctx, client, k8sclient.ObjectKey{Name: workload.name, Namespace: namespace})
+	if k8serr.IsNotFound(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get workload %s/%s: %w", namespace, workload.name, err)
+	}
+
+	target := p.to.productConfigs[step.Product].resourceConfigs[step.Name]
+	if state.replicas != target.Replicas || !state.observed {
+		return false, nil
+	}
+	if state.updatedReplicas != target.Replicas || state.readyReplicas != target.Replicas {
+		return false, nil
+	}
+
+	// the pods of the workload are updated once one of its containers
+	// requests the resources of the new quota
+	for _, container := range state.template.Spec.Containers {
+		if resourceListEqual(container.Resources.Requests, target.Resources.Requests) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Apply sets retQuota to the new quota, with the resource configs that
+// aren't in rolledOut kept as in the previous quota. The resources are
+// applied to the workloads as in a quota update
+func (p *Plan) Apply(rolledOut []string, retQuota *Quota) {
+	retQuota.name = p.to.name
+	retQuota.rateLimitConfig = p.to.rateLimitConfig
+	retQuota.isUpdated = true
+	retQuota.productConfigs = map[v1alpha1.ProductName]QuotaProductConfig{}
+
+	applied := map[string]bool{}
+	for _, name := range rolledOut {
+		applied[name] = true
+	}
+	for product, ddcssNames := range products {
+		pc := QuotaProductConfig{
+			quota:           retQuota,
+			productName:     product,
+			resourceConfigs: map[string]ResourceConfig{},
+		}
+		for _, ddcssName := range ddcssNames {
+			if applied[ddcssName] {
+				pc.resourceConfigs[ddcssName] = p.to.productConfigs[product].resourceConfigs[ddcssName]
+			} else {
+				pc.resourceConfigs[ddcssName] = p.from.productConfigs[product].resourceConfigs[ddcssName]
+			}
+		}
+		retQuota.productConfigs[product] = pc
+	}
+}
+
+// requestedResource returns the resource requested that a resource quota
+// limits, the requests.cpu and cpu quotas both limit the CPU requested
+func requestedResource(name corev1.ResourceName) corev1.ResourceName {
+	return corev1.ResourceName(strings.TrimPrefix(string(name), "requests."))
+}
+
+func increases(delta corev1.ResourceList) bool {
+	for _, quantity := range delta {
+		if quantity.Sign() > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func subtractResources(total, resources corev1.ResourceList) {
+	for name, quantity := range resources {
+		difference := total[name]
+		difference.Sub(quantity)
+		total[name] = difference
+	}
+}
+
+func resourceListEqual(a, b corev1.ResourceList) bool {
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		quantityA, quantityB := a[name], b[name]
+		if quantityA.Cmp(quantityB) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func getDeploymentConfigState(ctx context.Context, client k8sclient.Client, key k8sclient.ObjectKey) (*workloadState, error) {
+	dc := &appsv1.DeploymentConfig{}
+	if err := client.Get(ctx, key, dc); err != nil {
+		return nil, err
+	}
+	template := dc.Spec.Template
+	if template == nil {
+		template = &corev1.PodTemplateSpec{}
+	}
+	return &workloadState{
+		template:        template,
+		replicas:        dc.Spec.Replicas,
+		observed:        dc.Status.ObservedGeneration >= dc.Generation,
+		updatedReplicas: dc.Status.UpdatedReplicas,
+		readyReplicas:   dc.Status.ReadyReplicas,
+	}, nil
+}
+
+func getDeploymentState(ctx context.Context, client k8sclient.Client, key k8sclient.ObjectKey) (*workloadState, error) {
+	deployment := &appsv12.Deployment{}
+	if err := client.Get(ctx, key, deployment); err != nil {
+		return nil, err
+	}
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	return &workloadState{
+		template:        &deployment.Spec.Template,
+		replicas:        replicas,
+		observed:        deployment.Status.ObservedGeneration >= deployment.Generation,
+		updatedReplicas: deployment.Status.UpdatedReplicas,
+		readyReplicas:   deployment.Status.ReadyReplicas,
+	}, nil
+}
+
+func getStatefulSetState(ctx context.Context, client k8sclient.Client, key k8sclient.ObjectKey) (*workloadState, error) {
+	statefulSet := &appsv12.StatefulSet{}
+	if err := client.Get(ctx, key, statefulSet); err != nil {
+		return nil, err
+	}
+	replicas := int32(1)
+	if statefulSet.Spec.Replicas != nil {
+		replicas = *statefulSet.Spec.Replicas
+	}
+	return &workloadState{
+		template:        &statefulSet.Spec.Template,
+		replicas:        replicas,
+		observed:        statefulSet.Status.ObservedGeneration >= statefulSet.Generation,
+		updatedReplicas: statefulSet.Status.UpdatedReplicas,
+		readyReplicas:   statefulSet.Status.ReadyReplicas,
+	}, nil
+}
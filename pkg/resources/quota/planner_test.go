@@ -0,0 +1,326 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/resources"
+	v1 "github.com/openshift/api/apps/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const planNamespace = "redhat-rhoam-3scale"
+
+func getPlanScheme() (*runtime.Scheme, error) {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	if err := v1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	return scheme, nil
+}
+
+// getPlan plans the change from the 100K quota to the 20M quota: the apicast
+// production replica is removed and 3 backend listener replicas are added
+func getPlan(t *testing.T) *Plan {
+	from, to := &Quota{}, &Quota{}
+	if err := GetQuota(DEVQUOTAPARAM, getQuotaConfig(nil), from); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := GetQuota(TWENTYMILLIONQUOTAPARAM, getQuotaConfig(nil), to); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return NewPlan(from, to)
+}
+
+func getWorkerNode(cpu, memory string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "worker",
+			Labels: map[string]string{resources.WorkerNodeLabel: ""},
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(memory),
+			},
+		},
+	}
+}
+
+func TestNewPlan(t *testing.T) {
+	plan := getPlan(t)
+
+	names := []string{}
+	for _, step := range plan.Steps() {
+		names = append(names, step.Name)
+	}
+	if want := []string{ApicastProductionName, BackendListenerName}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("expected the decrease to be rolled out first, steps %v, got %v", want, names)
+	}
+
+	delta := plan.Delta()
+	cpu, memory := delta[corev1.ResourceCPU], delta[corev1.ResourceMemory]
+	if cpu.Cmp(resource.MustParse("700m")) != 0 {
+		t.Errorf("expected a cpu delta of 700m, got %s", cpu.String())
+	}
+	if memory.Cmp(resource.MustParse("1350")) >= 0 {
+		t.Errorf("expected the memory delta to be reduced by the apicast production replica, got %s", memory.String())
+	}
+}
+
+func TestPlan_Validate(t *testing.T) {
+	scheme, err := getPlanScheme()
+	if err != nil {
+		t.Fatalf("failed to build scheme: %v", err)
+	}
+	namespaces := map[v1alpha1.ProductName]string{v1alpha1.Product3Scale: planNamespace}
+
+	tests := []struct {
+		name    string
+		objects []runtime.Object
+		wantErr string
+	}{
+		{
+			name:    "passes when the worker nodes have the capacity",
+			objects: []runtime.Object{getWorkerNode("2", "8Gi")},
+		},
+		{
+			name: "fails when the worker nodes don't have the capacity",
+			objects: []runtime.Object{
+				getWorkerNode("2", "8Gi"),
+				&corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "other"},
+					Spec: corev1.PodSpec{
+						NodeName: "worker",
+						Containers: []corev1.Container{{
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1500m")},
+							},
+						}},
+					},
+				},
+			},
+			wantErr: "the worker nodes don't have the capacity",
+		},
+		{
+			name:    "fails without schedulable worker nodes",
+			objects: []runtime.Object{},
+			wantErr: "no schedulable worker nodes found",
+		},
+		{
+			name: "fails when the change exceeds a resource quota",
+			objects: []runtime.Object{
+				getWorkerNode("2", "8Gi"),
+				&corev1.ResourceQuota{
+					ObjectMeta: metav1.ObjectMeta{Name: "compute", Namespace: planNamespace},
+					Status: corev1.ResourceQuotaStatus{
+						Hard: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("1")},
+						Used: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("500m")},
+					},
+				},
+			},
+			wantErr: "exceeds the requests.cpu of resource quota " + planNamespace + "/compute",
+		},
+		{
+			name: "passes when the change fits in the resource quotas",
+			objects: []runtime.Object{
+				getWorkerNode("2", "8Gi"),
+				&corev1.ResourceQuota{
+					ObjectMeta: metav1.ObjectMeta{Name: "compute", Namespace: planNamespace},
+					Status: corev1.ResourceQuotaStatus{
+						Hard: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+						Used: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewFakeClientWithScheme(scheme, tt.objects...)
+			err := getPlan(t).Validate(context.TODO(), client, namespaces)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestPlan_Ready(t *testing.T) {
+	scheme, err := getPlanScheme()
+	if err != nil {
+		t.Fatalf("failed to build scheme: %v", err)
+	}
+	plan := getPlan(t)
+	step := plan.Steps()[1]
+
+	backendListener := func(cpu string, replicas, readyReplicas int32) *v1.DeploymentConfig {
+		dc := getDeploymentConfig("backend-listener", func(dc *v1.DeploymentConfig) {
+			dc.Namespace = planNamespace
+			dc.Spec.Replicas = replicas
+			dc.Spec.Template.Spec.Containers = []corev1.Container{{
+				Name: "backend-listener",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse(cpu),
+						corev1.ResourceMemory: resource.MustParse("450"),
+					},
+				},
+			}}
+			dc.Status.UpdatedReplicas = replicas
+			dc.Status.ReadyReplicas = readyReplicas
+		})
+		return dc
+	}
+
+	tests := []struct {
+		name    string
+		objects []runtime.Object
+		want    bool
+	}{
+		{
+			name:    "ready when the workload doesn't exist",
+			objects: []runtime.Object{},
+			want:    true,
+		},
+		{
+			name:    "not ready before the workload requests the new resources",
+			objects: []runtime.Object{backendListener("100m", 3, 3)},
+			want:    false,
+		},
+		{
+			name:    "not ready while the replicas aren't ready",
+			objects: []runtime.Object{backendListener("250m", 3, 2)},
+			want:    false,
+		},
+		{
+			name:    "not ready before the workload is scaled to the new replicas",
+			objects: []runtime.Object{backendListener("250m", 2, 2)},
+			want:    false,
+		},
+		{
+			name:    "ready once the replicas are updated and ready",
+			objects: []runtime.Object{backendListener("250m", 3, 3)},
+			want:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewFakeClientWithScheme(scheme, tt.objects...)
+			ready, err := plan.Ready(context.TODO(), client, step, planNamespace)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ready != tt.want {
+				t.Errorf("expected ready %v, got %v", tt.want, ready)
+			}
+		})
+	}
+
+	// a step without a workload is always ready
+	ready, err := plan.Ready(context.TODO(), fake.NewFakeClientWithScheme(scheme), Step{Product: v1alpha1.ProductGrafana, Name: GrafanaName}, "")
+	if err != nil || !ready {
+		t.Errorf("expected a step without a workload to be ready, got %v, %v", ready, err)
+	}
+}
+
+// TestPlan_Ready_Replicas checks a change of the replicas only, such as the
+// keycloak replicas of the 1 Million and 5 Million quotas, waits for the
+// replicas of the workload
+func TestPlan_Ready_Replicas(t *testing.T) {
+	scheme, err := getPlanScheme()
+	if err != nil {
+		t.Fatalf("failed to build scheme: %v", err)
+	}
+	if err := appsv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to build scheme: %v", err)
+	}
+	keycloakConfig := func(replicas int) string {
+		return fmt.Sprintf(`{"%s": {"replicas": %d, "resources": {"requests": {"cpu": "650m", "memory": "2G"}}}}`, KeycloakName, replicas)
+	}
+	config := getQuotaConfig(func(cm *corev1.ConfigMap) {
+		cm.Data[ConfigMapData] = fmt.Sprintf(`[{"name": "1M", "param": "10", "resources": %s}, {"name": "5M", "param": "50", "resources": %s}]`, keycloakConfig(2), keycloakConfig(3))
+	})
+	from, to := &Quota{}, &Quota{}
+	if err := GetQuota("10", config, from); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := GetQuota("50", config, to); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	plan := NewPlan(from, to)
+	if len(plan.Steps()) != 1 || plan.Steps()[0].Name != KeycloakName {
+		t.Fatalf("expected a keycloak step, got %v", plan.Steps())
+	}
+
+	keycloak := func(replicas int32) *appsv1.StatefulSet {
+		return getStatefulSet("keycloak", func(ss *appsv1.StatefulSet) {
+			ss.Namespace = planNamespace
+			ss.Spec.Replicas = &replicas
+			ss.Spec.Template.Spec.Containers = []corev1.Container{{
+				Name: "keycloak",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("650m"),
+						corev1.ResourceMemory: resource.MustParse("2G"),
+					},
+				},
+			}}
+			ss.Status.UpdatedReplicas = replicas
+			ss.Status.ReadyReplicas = replicas
+		})
+	}
+	for replicas, want := range map[int32]bool{2: false, 3: true} {
+		ready, err := plan.Ready(context.TODO(), fake.NewFakeClientWithScheme(scheme, keycloak(replicas)), plan.Steps()[0], planNamespace)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ready != want {
+			t.Errorf("expected ready %v with %d keycloak replicas, got %v", want, replicas, ready)
+		}
+	}
+}
+
+func TestPlan_Apply(t *testing.T) {
+	plan := getPlan(t)
+	quota := &Quota{}
+	plan.Apply([]string{ApicastProductionName}, quota)
+
+	if quota.GetName() != TWENTYMILLIONQUOTACONFIGNAME || !quota.IsUpdated() {
+		t.Fatalf("expected the updated %s quota, got %s updated %v", TWENTYMILLIONQUOTACONFIGNAME, quota.GetName(), quota.IsUpdated())
+	}
+	if quota.GetRateLimitConfig().RequestsPerUnit != 347 {
+		t.Errorf("expected the rate limit of the new quota, got %d", quota.GetRateLimitConfig().RequestsPerUnit)
+	}
+
+	threescale := quota.GetProduct(v1alpha1.Product3Scale)
+	if replicas := threescale.GetReplicas(ApicastProductionName); replicas != 0 {
+		t.Errorf("expected the rolled out apicast production config of the new quota, got %d replicas", replicas)
+	}
+	if replicas := threescale.GetReplicas(BackendListenerName); replicas != 0 {
+		t.Errorf("expected the backend listener config of the previous quota, got %d replicas", replicas)
+	}
+	if threescale.GetActiveQuota() != TWENTYMILLIONQUOTACONFIGNAME {
+		t.Errorf("expected the product config to belong to the applied quota, got %s", threescale.GetActiveQuota())
+	}
+
+	plan.Apply([]string{ApicastProductionName, BackendListenerName}, quota)
+	if replicas := quota.GetProduct(v1alpha1.Product3Scale).GetReplicas(BackendListenerName); replicas != 3 {
+		t.Errorf("expected the rolled out backend listener config of the new quota, got %d replicas", replicas)
+	}
+}
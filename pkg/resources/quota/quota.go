@@ -87,6 +87,20 @@ type quotaConfigReceiver struct {
 }
 
 func GetQuota(quotaParam string, QuotaConfig *corev1.ConfigMap, retQuota *Quota) error {
+	return getQuota(QuotaConfig, retQuota, func(quota quotaConfigReceiver) bool {
+		return quota.Param == quotaParam
+	}, fmt.Sprintf("wasn't able to find a quota in the quota config which matches the '%s' quota parameter", quotaParam))
+}
+
+// GetQuotaByName gets the quota named name, the name of a quota is recorded in
+// the status of the installation
+func GetQuotaByName(name string, QuotaConfig *corev1.ConfigMap, retQuota *Quota) error {
+	return getQuota(QuotaConfig, retQuota, func(quota quotaConfigReceiver) bool {
+		return quota.Name == name
+	}, fmt.Sprintf("wasn't able to find a quota in the quota config named '%s'", name))
+}
+
+func getQuota(QuotaConfig *corev1.ConfigMap, retQuota *Quota, matches func(quota quotaConfigReceiver) bool, notFound string) error {
 	allQuotas := &[]quotaConfigReceiver{}
 	err := json.Unmarshal([]byte(QuotaConfig.Data[ConfigMapData]), allQuotas)
 	if err != nil {
@@ -95,7 +109,7 @@ func GetQuota(quotaParam string, QuotaConfig *corev1.ConfigMap, retQuota *Quota)
 	quotaReceiver := quotaConfigReceiver{}
 
 	for _, quota := range *allQuotas {
-		if quota.Param == quotaParam {
+		if matches(quota) {
 			quotaReceiver = quota
 			break
 		}
@@ -103,7 +117,7 @@ func GetQuota(quotaParam string, QuotaConfig *corev1.ConfigMap, retQuota *Quota)
 	// if the quota receiver is empty at this point we haven't found a quota which matches the config
 	// return in progress
 	if quotaReceiver.Name == "" {
-		return errors.New(fmt.Sprintf("wasn't able to find a quota in the quota config which matches the '%s' quota parameter", quotaParam))
+		return errors.New(notFound)
 	}
 
 	retQuota.name = quotaReceiver.Name
@@ -144,6 +158,44 @@ func (s *Quota) SetIsUpdated(isUpdated bool) {
 	s.isUpdated = isUpdated
 }
 
+// GetResourceRequests returns the resources requested by the pods of the
+// products with the replicas of the quota
+func (s *Quota) GetResourceRequests() corev1.ResourceList {
+	total := corev1.ResourceList{}
+	for _, productConfig := range s.productConfigs {
+		for _, resourceConfig := range productConfig.resourceConfigs {
+			addResources(total, resourceConfig.requests())
+		}
+	}
+	return total
+}
+
+// requests returns the resources requested by the replicas of the resource
+// config
+func (c ResourceConfig) requests() corev1.ResourceList {
+	replicas := c.Replicas
+	if replicas < 1 {
+		replicas = 1
+	}
+	requests := corev1.ResourceList{}
+	for name, request := range c.Resources.Requests {
+		sum := resource.Quantity{}
+		for i := int32(0); i < replicas; i++ {
+			sum.Add(request)
+		}
+		requests[name] = sum
+	}
+	return requests
+}
+
+func addResources(total, resources corev1.ResourceList) {
+	for name, quantity := range resources {
+		sum := total[name]
+		sum.Add(quantity)
+		total[name] = sum
+	}
+}
+
 func (p QuotaProductConfig) GetResourceConfig(ddcssName string) (corev1.ResourceRequirements, bool) {
 	if _, ok := p.resourceConfigs[ddcssName]; !ok {
 		return corev1.ResourceRequirements{}, false
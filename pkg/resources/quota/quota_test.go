@@ -880,6 +880,22 @@ func TestProductConfig_Configure(t *testing.T) {
 	}
 }
 
+func TestQuota_GetResourceRequests(t *testing.T) {
+	quota := &Quota{}
+	if err := GetQuota(TWENTYMILLIONQUOTAPARAM, getQuotaConfig(nil), quota); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	requests := quota.GetResourceRequests()
+	cpu, memory := requests[corev1.ResourceCPU], requests[corev1.ResourceMemory]
+	if cpu.Cmp(resource.MustParse("750m")) != 0 {
+		t.Errorf("expected 3 backend listener replicas to request 750m cpu, got %s", cpu.String())
+	}
+	if memory.Cmp(resource.MustParse("1350")) != 0 {
+		t.Errorf("expected 3 backend listener replicas to request 1350 memory, got %s", memory.String())
+	}
+}
+
 func getResourceConfig(modifyFn func(rcs map[string]ResourceConfig)) map[string]ResourceConfig {
 	mock := map[string]ResourceConfig{}
 	if modifyFn != nil {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	productsConfig "github.com/integr8ly/integreatly-operator/pkg/config"
 
@@ -12,6 +13,7 @@ import (
 	"github.com/integr8ly/integreatly-operator/pkg/resources/backup"
 	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/marketplace"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/tracing"
 	oauthv1 "github.com/openshift/api/oauth/v1"
 	projectv1 "github.com/openshift/api/project/v1"
 	operatorsv1alpha1 "github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators/v1alpha1"
@@ -29,6 +31,15 @@ var (
 	OwnerLabelKey = integreatlyv1alpha1.GroupVersion.Group + "/installation-uid"
 )
 
+const (
+	// previousSecretExpiryAnnotation is the time the previous secret of a
+	// rotated OAuth client stops being accepted
+	previousSecretExpiryAnnotation = "integreatly.org/previous-secret-expiry"
+	// previousSecretGracePeriod gives the identity providers using an OAuth
+	// client time to pick up its rotated secret
+	previousSecretGracePeriod = time.Hour
+)
+
 // This is the base reconciler that all the other reconcilers extend. It handles things like namespace creation, subscription creation etc
 
 type Reconciler struct {
@@ -69,6 +80,7 @@ func (r *Reconciler) ReconcileOauthClient(ctx context.Context, inst *integreatly
 	PrepareObject(client, inst, true, false)
 	client.RedirectURIs = redirectUris
 	client.GrantMethod = grantMethod
+	reconcileOauthClientSecretRotation(client, secret, time.Now())
 	client.Secret = secret
 
 	if err := apiClient.Update(ctx, client); err != nil {
@@ -77,6 +89,30 @@ func (r *Reconciler) ReconcileOauthClient(ctx context.Context, inst *integreatly
 	return integreatlyv1alpha1.PhaseCompleted, nil
 }
 
+// reconcileOauthClientSecretRotation keeps accepting the current secret of
+// the client for a grace period when it's rotated, and drops it once the grace
+// period expires
+func reconcileOauthClientSecretRotation(client *oauthv1.OAuthClient, secret string, now time.Time) {
+	if client.Secret != "" && client.Secret != secret {
+		client.AdditionalSecrets = []string{client.Secret}
+		if client.Annotations == nil {
+			client.Annotations = map[string]string{}
+		}
+		client.Annotations[previousSecretExpiryAnnotation] = now.Add(previousSecretGracePeriod).UTC().Format(time.RFC3339)
+		return
+	}
+
+	expiry, ok := client.Annotations[previousSecretExpiryAnnotation]
+	if !ok {
+		return
+	}
+	if expiresAt, err := time.Parse(time.RFC3339, expiry); err == nil && now.Before(expiresAt) {
+		return
+	}
+	client.AdditionalSecrets = nil
+	delete(client.Annotations, previousSecretExpiryAnnotation)
+}
+
 // GetNS gets the specified corev1.Namespace from the k8s API server
 func GetNS(ctx context.Context, namespace string, client k8sclient.Client) (*corev1.Namespace, error) {
 	ns := &corev1.Namespace{
@@ -204,7 +240,20 @@ func (r *Reconciler) ReconcilePullSecret(ctx context.Context, destSecretNamespac
 	return integreatlyv1alpha1.PhaseCompleted, nil
 }
 
+// ReconcileSubscription installs the operator of target and approves its
+// install plan, the phase is in progress until the CSV of the operator
+// succeeds. The wait for OLM is traced in a span of its own
 func (r *Reconciler) ReconcileSubscription(ctx context.Context, target marketplace.Target, operandNS []string, preUpgradeBackupExecutor backup.BackupExecutor, client k8sclient.Client, catalogSourceReconciler marketplace.CatalogSourceReconciler, log l.Logger) (integreatlyv1alpha1.StatusPhase, error) {
+	ctx, span := tracing.Start(ctx, "ReconcileSubscription",
+		tracing.NamespaceKey.String(target.Namespace),
+		tracing.PackageKey.String(target.Package),
+	)
+	phase, err := r.reconcileSubscription(ctx, target, operandNS, preUpgradeBackupExecutor, client, catalogSourceReconciler, log)
+	tracing.End(span, phase, err)
+	return phase, err
+}
+
+func (r *Reconciler) reconcileSubscription(ctx context.Context, target marketplace.Target, operandNS []string, preUpgradeBackupExecutor backup.BackupExecutor, client k8sclient.Client, catalogSourceReconciler marketplace.CatalogSourceReconciler, log l.Logger) (integreatlyv1alpha1.StatusPhase, error) {
 	log.Infof("Reconciling subscription", l.Fields{"subscription": target.SubscriptionName, "channel": marketplace.IntegreatlyChannel, "ns": target.Namespace})
 	err := r.mpm.InstallOperator(ctx, client, target, operandNS, operatorsv1alpha1.ApprovalManual, catalogSourceReconciler)
 
@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
 	"testing"
 	"time"
 
@@ -493,6 +494,63 @@ func TestReconciler_ReconcileOauthClient(t *testing.T) {
 	}
 }
 
+func TestReconcileOauthClientSecretRotation(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		Name                      string
+		OauthClient               *oauthv1.OAuthClient
+		Secret                    string
+		ExpectedAdditionalSecrets []string
+		ExpectedExpiry            string
+	}{
+		{
+			Name:        "test unchanged secret keeps no previous secret",
+			OauthClient: &oauthv1.OAuthClient{Secret: "current"},
+			Secret:      "current",
+		},
+		{
+			Name:                      "test rotated secret keeps accepting the previous secret",
+			OauthClient:               &oauthv1.OAuthClient{Secret: "current"},
+			Secret:                    "rotated",
+			ExpectedAdditionalSecrets: []string{"current"},
+			ExpectedExpiry:            "2021-06-01T13:00:00Z",
+		},
+		{
+			Name: "test previous secret is kept during the grace period",
+			OauthClient: &oauthv1.OAuthClient{
+				ObjectMeta:        metav1.ObjectMeta{Annotations: map[string]string{previousSecretExpiryAnnotation: "2021-06-01T12:30:00Z"}},
+				Secret:            "rotated",
+				AdditionalSecrets: []string{"current"},
+			},
+			Secret:                    "rotated",
+			ExpectedAdditionalSecrets: []string{"current"},
+			ExpectedExpiry:            "2021-06-01T12:30:00Z",
+		},
+		{
+			Name: "test previous secret is dropped after the grace period",
+			OauthClient: &oauthv1.OAuthClient{
+				ObjectMeta:        metav1.ObjectMeta{Annotations: map[string]string{previousSecretExpiryAnnotation: "2021-06-01T11:30:00Z"}},
+				Secret:            "rotated",
+				AdditionalSecrets: []string{"current"},
+			},
+			Secret: "rotated",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			reconcileOauthClientSecretRotation(tc.OauthClient, tc.Secret, now)
+			if !reflect.DeepEqual(tc.OauthClient.AdditionalSecrets, tc.ExpectedAdditionalSecrets) {
+				t.Errorf("expected additional secrets %v, got %v", tc.ExpectedAdditionalSecrets, tc.OauthClient.AdditionalSecrets)
+			}
+			if expiry := tc.OauthClient.Annotations[previousSecretExpiryAnnotation]; expiry != tc.ExpectedExpiry {
+				t.Errorf("expected previous secret expiry %q, got %q", tc.ExpectedExpiry, expiry)
+			}
+		})
+	}
+}
+
 func TestReconciler_ReconcileNamespace(t *testing.T) {
 	nsName := "test-ns"
 	installation := &integreatlyv1alpha1.RHMI{
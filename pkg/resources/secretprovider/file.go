@@ -0,0 +1,63 @@
+package secretprovider
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type fileProvider struct {
+	path string
+}
+
+var _ Provider = &fileProvider{}
+
+// NewFileProvider returns a provider reading the secrets from the file tree
+// under path. Each secret is a directory named after it, with a file per key
+func NewFileProvider(path string) Provider {
+	return &fileProvider{path: path}
+}
+
+func (p *fileProvider) GetSecret(ctx context.Context, name string) (map[string][]byte, error) {
+	// secret names are single path elements, this prevents reading
+	// outside of the mounted tree
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return nil, fmt.Errorf("invalid secret name %q", name)
+	}
+
+	dir := filepath.Join(p.path, name)
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("secret %s in %s: %w", name, p.path, ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to read secret %s in %s: %w", name, p.path, err)
+	}
+
+	data := map[string][]byte{}
+	for _, file := range files {
+		// the directories and links of atomic writes, e.g. ..data, are
+		// hidden
+		if strings.HasPrefix(file.Name(), ".") {
+			continue
+		}
+		// keys are links to the current version of the file when the
+		// tree is mounted by the kubelet, Stat follows them
+		info, err := os.Stat(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key %s of secret %s: %w", file.Name(), name, err)
+		}
+		if info.IsDir() {
+			continue
+		}
+		value, err := ioutil.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key %s of secret %s: %w", file.Name(), name, err)
+		}
+		data[file.Name()] = value
+	}
+	return data, nil
+}
@@ -0,0 +1,93 @@
+package secretprovider
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeSecretTree writes the secrets in the layout the kubelet mounts them
+// with, the keys link to the files of the current ..data directory
+func writeSecretTree(t *testing.T, secrets map[string]map[string]string) string {
+	root, err := ioutil.TempDir("", "secretprovider")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	for name, data := range secrets {
+		dataDir := filepath.Join(root, name, "..2021_01_01_00_00_00.000000000")
+		if err := os.MkdirAll(dataDir, 0700); err != nil {
+			t.Fatalf("failed to create secret dir: %v", err)
+		}
+		if err := os.Symlink(filepath.Base(dataDir), filepath.Join(root, name, "..data")); err != nil {
+			t.Fatalf("failed to link data dir: %v", err)
+		}
+		for key, value := range data {
+			if err := ioutil.WriteFile(filepath.Join(dataDir, key), []byte(value), 0600); err != nil {
+				t.Fatalf("failed to write key: %v", err)
+			}
+			if err := os.Symlink(filepath.Join("..data", key), filepath.Join(root, name, key)); err != nil {
+				t.Fatalf("failed to link key: %v", err)
+			}
+		}
+	}
+	return root
+}
+
+func TestFileProvider_GetSecret(t *testing.T) {
+	root := writeSecretTree(t, map[string]map[string]string{
+		"redhat-rhoam-smtp": {"host": "smtp.example.com", "password": "secret"},
+	})
+	defer os.RemoveAll(root)
+
+	scenarios := []struct {
+		Name         string
+		SecretName   string
+		ExpectedData map[string][]byte
+		Verify       func(err error, t *testing.T)
+	}{
+		{
+			Name:       "test keys are read through the links of the mounted tree",
+			SecretName: "redhat-rhoam-smtp",
+			ExpectedData: map[string][]byte{
+				"host":     []byte("smtp.example.com"),
+				"password": []byte("secret"),
+			},
+			Verify: func(err error, t *testing.T) {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			},
+		},
+		{
+			Name:       "test missing secret returns not found",
+			SecretName: "redhat-rhoam-pagerduty",
+			Verify: func(err error, t *testing.T) {
+				if !IsNotFound(err) {
+					t.Fatalf("expected not found error, got %v", err)
+				}
+			},
+		},
+		{
+			Name:       "test names outside of the tree are rejected",
+			SecretName: "../etc",
+			Verify: func(err error, t *testing.T) {
+				if err == nil || IsNotFound(err) {
+					t.Fatalf("expected invalid name error, got %v", err)
+				}
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.Name, func(t *testing.T) {
+			data, err := NewFileProvider(root).GetSecret(context.TODO(), scenario.SecretName)
+			scenario.Verify(err, t)
+			if scenario.ExpectedData != nil && !reflect.DeepEqual(data, scenario.ExpectedData) {
+				t.Errorf("expected data %v, got %v", scenario.ExpectedData, data)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package secretprovider
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type kubernetesProvider struct {
+	client    k8sclient.Client
+	namespace string
+}
+
+var _ Provider = &kubernetesProvider{}
+
+// NewKubernetesProvider returns a provider reading the Secrets in namespace
+func NewKubernetesProvider(client k8sclient.Client, namespace string) Provider {
+	return &kubernetesProvider{
+		client:    client,
+		namespace: namespace,
+	}
+}
+
+func (p *kubernetesProvider) GetSecret(ctx context.Context, name string) (map[string][]byte, error) {
+	secret := &corev1.Secret{}
+	// the errors of the client are returned as they are, IsNotFound
+	// recognises them
+	if err := p.client.Get(ctx, k8sclient.ObjectKey{Name: name, Namespace: p.namespace}, secret); err != nil {
+		return nil, err
+	}
+	if secret.Data == nil {
+		return map[string][]byte{}, nil
+	}
+	return secret.Data, nil
+}
@@ -0,0 +1,113 @@
+// Package secretprovider reads the credentials the operator is configured
+// with, such as the SMTP, PagerDuty, Dead Mans Snitch and GitHub OAuth
+// credentials and the addon parameters, from a secret backend.
+//
+// The backend is selected with the SECRET_PROVIDER environment variable of
+// the operator:
+//
+//   - kubernetes (default): Secrets in the operator namespace
+//   - file: a file tree mounted in the operator pod, such as the secrets store
+//     CSI driver mounts, with a directory per secret and a file per key, under
+//     SECRET_PROVIDER_FILE_PATH
+//   - vault: a HashiCorp Vault KV version 2 secrets engine, see NewVaultProvider
+//
+// Secrets are looked up by the same name in every backend, e.g. the name set
+// in the smtpSecret field of the RHMI CR
+package secretprovider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	BackendKubernetes = "kubernetes"
+	BackendFile       = "file"
+	BackendVault      = "vault"
+
+	EnvKeyBackend  = "SECRET_PROVIDER"
+	EnvKeyFilePath = "SECRET_PROVIDER_FILE_PATH"
+
+	DefaultFilePath = "/etc/rhmi/secrets"
+)
+
+// ErrNotFound is returned when the secret doesn't exist in the backend
+var ErrNotFound = errors.New("secret not found")
+
+// Provider reads secrets from a secret backend
+type Provider interface {
+	// GetSecret returns the data of the secret, or an error IsNotFound
+	// returns true for if it doesn't exist
+	GetSecret(ctx context.Context, name string) (map[string][]byte, error)
+}
+
+// IsNotFound returns true if the error is returned for a secret that doesn't
+// exist
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound) || k8serr.IsNotFound(err)
+}
+
+var (
+	configuredOnce     sync.Once
+	configuredProvider Provider
+	configuredErr      error
+)
+
+// Backend returns the secret backend the operator is configured with
+func Backend() string {
+	if backend := os.Getenv(EnvKeyBackend); backend != "" {
+		return backend
+	}
+	return BackendKubernetes
+}
+
+// New returns the provider of the configured backend. The kubernetes backend
+// reads the Secrets in namespace through client, the other backends are
+// shared by all the callers
+func New(client k8sclient.Client, namespace string) (Provider, error) {
+	if Backend() == BackendKubernetes {
+		return NewKubernetesProvider(client, namespace), nil
+	}
+
+	configuredOnce.Do(func() {
+		switch Backend() {
+		case BackendFile:
+			path := os.Getenv(EnvKeyFilePath)
+			if path == "" {
+				path = DefaultFilePath
+			}
+			configuredProvider = NewFileProvider(path)
+		case BackendVault:
+			configuredProvider, configuredErr = NewVaultProviderFromEnv()
+		default:
+			configuredErr = fmt.Errorf("unsupported secret provider %s, expected one of %s, %s or %s", Backend(), BackendKubernetes, BackendFile, BackendVault)
+		}
+	})
+	return configuredProvider, configuredErr
+}
+
+// Hash returns a hash of the secret data, used to detect rotations
+func Hash(data map[string][]byte) string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	hash := sha256.New()
+	for _, key := range keys {
+		// the lengths keep the key and value boundaries unambiguous
+		fmt.Fprintf(hash, "%d:%s%d:", len(key), key, len(data[key]))
+		hash.Write(data[key])
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,114 @@
+package secretprovider
+
+import (
+	"context"
+	"time"
+
+	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+// DefaultRotationInterval is how often the watched secrets are read to
+// detect rotations
+const DefaultRotationInterval = time.Minute
+
+var log = l.NewLoggerWithContext(l.Fields{l.ComponentLogContext: "secretprovider"})
+
+// SecretNamesFunc returns the names of the secrets to watch
+type SecretNamesFunc func(ctx context.Context) ([]string, error)
+
+// RotationWatcher detects rotations of the secrets of a provider that can't
+// be watched through the Kubernetes API, and sends an event for every rotated
+// secret. The events are meant for a source.Channel watch of the controllers
+// depending on the secrets
+type RotationWatcher struct {
+	provider Provider
+	names    SecretNamesFunc
+	interval time.Duration
+	events   chan event.GenericEvent
+	hashes   map[string]string
+}
+
+// NewRotationWatcher returns a watcher reading the secrets named by names from
+// provider every interval
+func NewRotationWatcher(provider Provider, names SecretNamesFunc, interval time.Duration) *RotationWatcher {
+	return &RotationWatcher{
+		provider: provider,
+		names:    names,
+		interval: interval,
+		events:   make(chan event.GenericEvent),
+		hashes:   map[string]string{},
+	}
+}
+
+// Events returns the channel the rotation events are sent to. The events are
+// for a Secret named after the rotated secret
+func (w *RotationWatcher) Events() <-chan event.GenericEvent {
+	return w.events
+}
+
+// Start implements manager.Runnable
+func (w *RotationWatcher) Start(stop <-chan struct{}) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stop
+		cancel()
+	}()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		for _, name := range w.Check(ctx) {
+			secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name}}
+			select {
+			case w.events <- event.GenericEvent{Meta: secret, Object: secret}:
+			case <-stop:
+				return nil
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return nil
+		}
+	}
+}
+
+// Check reads the watched secrets and returns the names of the ones that
+// changed since the previous check. Secrets are not reported as rotated the
+// first time they're read
+func (w *RotationWatcher) Check(ctx context.Context) []string {
+	names, err := w.names(ctx)
+	if err != nil {
+		log.Error("failed to get the names of the secrets to watch for rotation", err)
+		return nil
+	}
+
+	rotated := []string{}
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		hash := ""
+		data, err := w.provider.GetSecret(ctx, name)
+		if err != nil && !IsNotFound(err) {
+			log.Error("failed to read secret to detect rotation", err)
+			continue
+		}
+		if err == nil {
+			hash = Hash(data)
+		}
+
+		previous, seen := w.hashes[name]
+		w.hashes[name] = hash
+		if seen && previous != hash {
+			log.Infof("secret rotated", l.Fields{"secret": name, "backend": Backend()})
+			rotated = append(rotated, name)
+		}
+	}
+	return rotated
+}
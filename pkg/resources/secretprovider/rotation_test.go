@@ -0,0 +1,65 @@
+package secretprovider
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRotationWatcher_Check(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to build scheme: %v", err)
+	}
+	smtpSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "redhat-rhoam-smtp", Namespace: "redhat-rhoam-operator"},
+		Data:       map[string][]byte{"password": []byte("secret")},
+	}
+	client := fakeclient.NewFakeClientWithScheme(scheme, smtpSecret)
+
+	watcher := NewRotationWatcher(
+		NewKubernetesProvider(client, "redhat-rhoam-operator"),
+		func(ctx context.Context) ([]string, error) {
+			return []string{"redhat-rhoam-smtp", "redhat-rhoam-pagerduty", ""}, nil
+		},
+		DefaultRotationInterval,
+	)
+
+	if rotated := watcher.Check(context.TODO()); len(rotated) != 0 {
+		t.Fatalf("expected no rotations on the first check, got %v", rotated)
+	}
+	if rotated := watcher.Check(context.TODO()); len(rotated) != 0 {
+		t.Fatalf("expected no rotations for unchanged secrets, got %v", rotated)
+	}
+
+	smtpSecret.Data["password"] = []byte("rotated")
+	if err := client.Update(context.TODO(), smtpSecret); err != nil {
+		t.Fatalf("failed to rotate secret: %v", err)
+	}
+	if err := client.Create(context.TODO(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "redhat-rhoam-pagerduty", Namespace: "redhat-rhoam-operator"},
+		Data:       map[string][]byte{"serviceKey": []byte("key")},
+	}); err != nil {
+		t.Fatalf("failed to create secret: %v", err)
+	}
+
+	expected := []string{"redhat-rhoam-smtp", "redhat-rhoam-pagerduty"}
+	if rotated := watcher.Check(context.TODO()); !reflect.DeepEqual(rotated, expected) {
+		t.Errorf("expected rotations %v, got %v", expected, rotated)
+	}
+}
+
+func TestHash(t *testing.T) {
+	hash := Hash(map[string][]byte{"ab": []byte("c"), "d": []byte("e")})
+	if hash != Hash(map[string][]byte{"d": []byte("e"), "ab": []byte("c")}) {
+		t.Errorf("expected the hash to not depend on the key order")
+	}
+	if hash == Hash(map[string][]byte{"a": []byte("bc"), "d": []byte("e")}) {
+		t.Errorf("expected the hash to depend on the key and value boundaries")
+	}
+}
@@ -0,0 +1,221 @@
+package secretprovider
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	EnvKeyVaultAddress    = "VAULT_ADDR"
+	EnvKeyVaultCACert     = "VAULT_CACERT"
+	EnvKeyVaultRole       = "VAULT_ROLE"
+	EnvKeyVaultAuthPath   = "VAULT_AUTH_PATH"
+	EnvKeyVaultMount      = "VAULT_KV_MOUNT"
+	EnvKeyVaultPathPrefix = "VAULT_KV_PATH_PREFIX"
+
+	DefaultVaultAuthPath = "kubernetes"
+	DefaultVaultMount    = "secret"
+
+	serviceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	vaultRequestTimeout     = 10 * time.Second
+)
+
+// VaultConfig configures the Vault provider
+type VaultConfig struct {
+	// Address of the Vault server, e.g. https://vault.example.com:8200
+	Address string
+	// Role of the Kubernetes auth method the operator logs in with
+	Role string
+	// AuthPath the Kubernetes auth method is mounted at
+	AuthPath string
+	// Mount path of the KV version 2 secrets engine
+	Mount string
+	// PathPrefix of the secrets in the secrets engine
+	PathPrefix string
+	// TokenPath is the service account token the operator logs in with
+	TokenPath string
+	// HTTPClient used for the requests to Vault
+	HTTPClient *http.Client
+}
+
+type vaultProvider struct {
+	config VaultConfig
+
+	mutex       sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+var _ Provider = &vaultProvider{}
+
+// NewVaultProvider returns a provider reading the secrets from a Vault KV
+// version 2 secrets engine, at <mount>/data/<path prefix>/<name>. Every key of
+// the Vault secret is a key of the secret.
+//
+// The operator logs in with its service account token through the
+// Kubernetes auth method, no Vault token is stored. The token returned by the
+// login is used until it's close to expiring, when the operator logs in again
+func NewVaultProvider(config VaultConfig) (Provider, error) {
+	if config.Address == "" || config.Role == "" {
+		return nil, fmt.Errorf("the vault address and role are required")
+	}
+	if config.AuthPath == "" {
+		config.AuthPath = DefaultVaultAuthPath
+	}
+	if config.Mount == "" {
+		config.Mount = DefaultVaultMount
+	}
+	if config.TokenPath == "" {
+		config.TokenPath = serviceAccountTokenPath
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: vaultRequestTimeout}
+	}
+	return &vaultProvider{config: config}, nil
+}
+
+// NewVaultProviderFromEnv returns a Vault provider configured from the
+// VAULT_* environment variables of the operator
+func NewVaultProviderFromEnv() (Provider, error) {
+	httpClient := &http.Client{Timeout: vaultRequestTimeout}
+	if caCertPath := os.Getenv(EnvKeyVaultCACert); caCertPath != "" {
+		caCert, err := ioutil.ReadFile(caCertPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read vault ca certificate: %w", err)
+		}
+		caCertPool := x509.NewCertPool()
+		if !caCertPool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no certificates found in vault ca certificate %s", caCertPath)
+		}
+		httpClient.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: caCertPool},
+		}
+	}
+
+	return NewVaultProvider(VaultConfig{
+		Address:    os.Getenv(EnvKeyVaultAddress),
+		Role:       os.Getenv(EnvKeyVaultRole),
+		AuthPath:   os.Getenv(EnvKeyVaultAuthPath),
+		Mount:      os.Getenv(EnvKeyVaultMount),
+		PathPrefix: os.Getenv(EnvKeyVaultPathPrefix),
+		HTTPClient: httpClient,
+	})
+}
+
+func (p *vaultProvider) GetSecret(ctx context.Context, name string) (map[string][]byte, error) {
+	token, err := p.login(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	secretPath := path.Join("/v1", p.config.Mount, "data", p.config.PathPrefix, name)
+	response := &struct {
+		Data struct {
+			Data map[string]string `json:"data"`
+		} `json:"data"`
+	}{}
+	status, err := p.do(ctx, http.MethodGet, secretPath, token, nil, response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vault secret %s: %w", secretPath, err)
+	}
+	// deleted versions of the secret are returned with no data
+	if status == http.StatusNotFound || response.Data.Data == nil {
+		return nil, fmt.Errorf("vault secret %s: %w", secretPath, ErrNotFound)
+	}
+
+	data := make(map[string][]byte, len(response.Data.Data))
+	for key, value := range response.Data.Data {
+		data[key] = []byte(value)
+	}
+	return data, nil
+}
+
+// login returns the current Vault token, logging in again when it's within
+// a third of its lease from expiring
+func (p *vaultProvider) login(ctx context.Context) (string, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.token != "" && time.Now().Before(p.tokenExpiry) {
+		return p.token, nil
+	}
+
+	jwt, err := ioutil.ReadFile(p.config.TokenPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read service account token: %w", err)
+	}
+	request := map[string]string{
+		"role": p.config.Role,
+		"jwt":  strings.TrimSpace(string(jwt)),
+	}
+	response := &struct {
+		Auth struct {
+			ClientToken   string `json:"client_token"`
+			LeaseDuration int64  `json:"lease_duration"`
+		} `json:"auth"`
+	}{}
+	loginPath := path.Join("/v1/auth", p.config.AuthPath, "login")
+	status, err := p.do(ctx, http.MethodPost, loginPath, "", request, response)
+	if err != nil {
+		return "", fmt.Errorf("failed to log in to vault: %w", err)
+	}
+	if status != http.StatusOK || response.Auth.ClientToken == "" {
+		return "", fmt.Errorf("failed to log in to vault with role %s: status %d", p.config.Role, status)
+	}
+
+	p.token = response.Auth.ClientToken
+	leaseDuration := time.Duration(response.Auth.LeaseDuration) * time.Second
+	p.tokenExpiry = time.Now().Add(leaseDuration * 2 / 3)
+	return p.token, nil
+}
+
+// do sends a request to Vault and decodes the response into response.
+// Responses other than not found are returned as errors
+func (p *vaultProvider) do(ctx context.Context, method, requestPath, token string, request, response interface{}) (int, error) {
+	var body *bytes.Buffer
+	if request != nil {
+		body = &bytes.Buffer{}
+		if err := json.NewEncoder(body).Encode(request); err != nil {
+			return 0, fmt.Errorf("failed to encode request: %w", err)
+		}
+	} else {
+		body = bytes.NewBuffer(nil)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(p.config.Address, "/")+requestPath, body)
+	if err != nil {
+		return 0, err
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.config.HTTPClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return resp.StatusCode, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+		return resp.StatusCode, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return resp.StatusCode, nil
+}
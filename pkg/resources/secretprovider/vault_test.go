@@ -0,0 +1,123 @@
+package secretprovider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func vaultServer(t *testing.T, logins *int, secrets map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/auth/kubernetes/login" {
+			request := map[string]string{}
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				t.Fatalf("failed to decode login: %v", err)
+			}
+			if request["role"] != "rhmi-operator" || request["jwt"] != "service-account-token" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			*logins++
+			fmt.Fprint(w, `{"auth": {"client_token": "vault-token", "lease_duration": 3600}}`)
+			return
+		}
+
+		if r.Header.Get("X-Vault-Token") != "vault-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		secret, ok := secrets[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, secret)
+	}))
+}
+
+func TestVaultProvider_GetSecret(t *testing.T) {
+	tokenFile, err := ioutil.TempFile("", "token")
+	if err != nil {
+		t.Fatalf("failed to create token file: %v", err)
+	}
+	defer os.Remove(tokenFile.Name())
+	if _, err := tokenFile.WriteString("service-account-token\n"); err != nil {
+		t.Fatalf("failed to write token file: %v", err)
+	}
+
+	logins := 0
+	server := vaultServer(t, &logins, map[string]string{
+		"/v1/secret/data/rhoam/redhat-rhoam-smtp":      `{"data": {"data": {"host": "smtp.example.com", "password": "secret"}}}`,
+		"/v1/secret/data/rhoam/redhat-rhoam-pagerduty": `{"data": {"data": null}}`,
+	})
+	defer server.Close()
+
+	provider, err := NewVaultProvider(VaultConfig{
+		Address:    server.URL,
+		Role:       "rhmi-operator",
+		PathPrefix: "rhoam",
+		TokenPath:  tokenFile.Name(),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	scenarios := []struct {
+		Name         string
+		SecretName   string
+		ExpectedData map[string][]byte
+		Verify       func(err error, t *testing.T)
+	}{
+		{
+			Name:       "test secret data is read from the kv engine",
+			SecretName: "redhat-rhoam-smtp",
+			ExpectedData: map[string][]byte{
+				"host":     []byte("smtp.example.com"),
+				"password": []byte("secret"),
+			},
+			Verify: func(err error, t *testing.T) {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			},
+		},
+		{
+			Name:       "test deleted secret returns not found",
+			SecretName: "redhat-rhoam-pagerduty",
+			Verify: func(err error, t *testing.T) {
+				if !IsNotFound(err) {
+					t.Fatalf("expected not found error, got %v", err)
+				}
+			},
+		},
+		{
+			Name:       "test missing secret returns not found",
+			SecretName: "redhat-rhoam-deadmanssnitch",
+			Verify: func(err error, t *testing.T) {
+				if !IsNotFound(err) {
+					t.Fatalf("expected not found error, got %v", err)
+				}
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.Name, func(t *testing.T) {
+			data, err := provider.GetSecret(context.TODO(), scenario.SecretName)
+			scenario.Verify(err, t)
+			if scenario.ExpectedData != nil && !reflect.DeepEqual(data, scenario.ExpectedData) {
+				t.Errorf("expected data %v, got %v", scenario.ExpectedData, data)
+			}
+		})
+	}
+
+	if logins != 1 {
+		t.Errorf("expected the vault token to be reused, got %d logins", logins)
+	}
+}
@@ -0,0 +1,183 @@
+// Package secretrotation rotates the values of the secrets generated by the
+// operator once they're older than the maximum age of their policy.
+//
+// The time each key of a secret was last rotated is recorded in the
+// LastRotatedAnnotation of the secret. The reconcilers of the consumers of a
+// secret pick up the new values on their next reconcile, which is triggered by
+// the update of the secret. The passwords of the users of the products are
+// rotated with RotateWith, which sets the new password on the user before the
+// secret is updated
+package secretrotation
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/metrics"
+	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+	"github.com/keycloak/keycloak-operator/pkg/model"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// LastRotatedAnnotation holds the time each key of the secret was last
+	// rotated, as a JSON object of RFC3339 timestamps
+	LastRotatedAnnotation = "integreatly.org/last-rotated"
+
+	OauthClientSecretsName     = "oauth-client-secrets"
+	GrafanaProxySecretName     = "grafana-k8s-proxy"
+	TenantAccountPasswordsName = "tenant-account-passwords"
+	// RHSSOAdminCredentialsName and RHSSOUserAdminCredentialsName are the
+	// credentials of the admin users of the master realms of the Keycloak
+	// instances
+	RHSSOAdminCredentialsName     = "credential-rhsso"
+	RHSSOUserAdminCredentialsName = "credential-rhssouser"
+
+	day = 24 * time.Hour
+)
+
+var log = l.NewLoggerWithContext(l.Fields{l.ComponentLogContext: "secretrotation"})
+
+// Policy is the rotation policy of a secret
+type Policy struct {
+	// MaxAge of the secret values before they're rotated. 0 disables the
+	// rotation
+	MaxAge time.Duration
+	// Keys of the secret that are rotated, all the keys when empty
+	Keys []string
+}
+
+// defaultPolicies of the secrets whose consumers are all updated by the
+// operator when the values change
+var defaultPolicies = map[string]Policy{
+	OauthClientSecretsName:        {MaxAge: 90 * day},
+	GrafanaProxySecretName:        {MaxAge: 30 * day},
+	TenantAccountPasswordsName:    {MaxAge: 90 * day},
+	RHSSOAdminCredentialsName:     {MaxAge: 90 * day, Keys: []string{model.AdminPasswordProperty}},
+	RHSSOUserAdminCredentialsName: {MaxAge: 90 * day, Keys: []string{model.AdminPasswordProperty}},
+}
+
+// PolicyFor returns the policy of the secret, overridden by the secretRotation
+// field of the installation
+func PolicyFor(installation *integreatlyv1alpha1.RHMI, secretName string) Policy {
+	policy := defaultPolicies[secretName]
+	for _, override := range installation.Spec.SecretRotation {
+		if override.Secret == secretName {
+			policy.MaxAge = time.Duration(override.MaxAgeDays) * day
+		}
+	}
+	return policy
+}
+
+// Rotate replaces the values of the keys of secret that are older than the
+// policy max age with values from generate, and records the rotation times.
+// Keys that have no recorded rotation are recorded as rotated at now, the age
+// of values set before their rotation was recorded is unknown. It returns
+// the rotated keys
+func Rotate(secret *corev1.Secret, policy Policy, generate func() string, now time.Time) []string {
+	rotated, _ := RotateWith(secret, policy, func(string) ([]byte, error) {
+		return []byte(generate()), nil
+	}, now)
+	return rotated
+}
+
+// RotateWith rotates the keys of secret as Rotate, with the values returned by
+// rotate, which sets them on their consumer before they're returned. A key
+// whose rotate fails keeps its value and rotation time, so that it's rotated
+// on the next call, and a nil value skips the rotation of the key. It returns
+// the rotated keys, and the errors of the keys that failed
+func RotateWith(secret *corev1.Secret, policy Policy, rotate func(key string) ([]byte, error), now time.Time) ([]string, error) {
+	lastRotated := LastRotated(secret)
+
+	rotated := []string{}
+	failed := []string{}
+	for _, key := range policyKeys(secret.Data, policy) {
+		rotatedAt, ok := lastRotated[key]
+		if !ok {
+			rotatedAt = now
+		}
+
+		if policy.MaxAge > 0 && now.Sub(rotatedAt) >= policy.MaxAge {
+			value, err := rotate(key)
+			if err != nil {
+				failed = append(failed, fmt.Sprintf("%s: %v", key, err))
+				log.Warningf("Failed to rotate secret key", l.Fields{"secret": secret.Name, "key": key, "error": err.Error()})
+			} else if value != nil {
+				secret.Data[key] = value
+				rotatedAt = now
+				rotated = append(rotated, key)
+				metrics.IncSecretRotations(secret.Name, key)
+				log.Infof("Rotated secret key", l.Fields{"secret": secret.Name, "key": key, "maxAge": policy.MaxAge.String()})
+			}
+		}
+
+		lastRotated[key] = rotatedAt
+		metrics.SetSecretLastRotation(secret.Name, key, rotatedAt)
+	}
+
+	// drop the keys removed from the secret
+	for key := range lastRotated {
+		if _, ok := secret.Data[key]; !ok {
+			delete(lastRotated, key)
+		}
+	}
+	setLastRotated(secret, lastRotated)
+
+	if len(failed) > 0 {
+		return rotated, fmt.Errorf("failed to rotate keys of secret %s: %s", secret.Name, strings.Join(failed, "; "))
+	}
+	return rotated, nil
+}
+
+// LastRotated returns the time each key of the secret was last rotated
+func LastRotated(secret *corev1.Secret) map[string]time.Time {
+	lastRotated := map[string]time.Time{}
+	value, ok := secret.Annotations[LastRotatedAnnotation]
+	if !ok {
+		return lastRotated
+	}
+	if err := json.Unmarshal([]byte(value), &lastRotated); err != nil {
+		log.Warningf("Ignoring invalid rotation times", l.Fields{"secret": secret.Name, "error": err.Error()})
+		return map[string]time.Time{}
+	}
+	return lastRotated
+}
+
+func setLastRotated(secret *corev1.Secret, lastRotated map[string]time.Time) {
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	for key, rotatedAt := range lastRotated {
+		lastRotated[key] = rotatedAt.UTC().Truncate(time.Second)
+	}
+	// the keys of maps are encoded in sorted order, keeping the annotation
+	// stable across reconciles
+	value, _ := json.Marshal(lastRotated)
+	secret.Annotations[LastRotatedAnnotation] = string(value)
+}
+
+// policyKeys returns the keys of data rotated by the policy, in sorted order
+func policyKeys(data map[string][]byte, policy Policy) []string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		if len(policy.Keys) > 0 && !containsString(policy.Keys, key) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,162 @@
+package secretrotation
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRotate(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	generate := func() string { return "generated" }
+
+	scenarios := []struct {
+		Name                string
+		Secret              *corev1.Secret
+		Policy              Policy
+		ExpectedRotated     []string
+		ExpectedData        map[string][]byte
+		ExpectedLastRotated string
+	}{
+		{
+			Name: "test keys without a recorded rotation are recorded as rotated now",
+			Secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              OauthClientSecretsName,
+					CreationTimestamp: metav1.NewTime(now.Add(-100 * day)),
+				},
+				Data: map[string][]byte{"rhsso": []byte("old")},
+			},
+			Policy:              Policy{MaxAge: 90 * day},
+			ExpectedRotated:     []string{},
+			ExpectedData:        map[string][]byte{"rhsso": []byte("old")},
+			ExpectedLastRotated: `{"rhsso":"2021-06-01T12:00:00Z"}`,
+		},
+		{
+			Name: "test only keys older than the max age are rotated",
+			Secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: OauthClientSecretsName,
+					Annotations: map[string]string{
+						LastRotatedAnnotation: `{"3scale":"2021-05-01T00:00:00Z","rhsso":"2021-01-01T00:00:00Z","removed":"2021-01-01T00:00:00Z"}`,
+					},
+				},
+				Data: map[string][]byte{"rhsso": []byte("old"), "3scale": []byte("recent")},
+			},
+			Policy:              Policy{MaxAge: 90 * day},
+			ExpectedRotated:     []string{"rhsso"},
+			ExpectedData:        map[string][]byte{"rhsso": []byte("generated"), "3scale": []byte("recent")},
+			ExpectedLastRotated: `{"3scale":"2021-05-01T00:00:00Z","rhsso":"2021-06-01T12:00:00Z"}`,
+		},
+		{
+			Name: "test disabled policy records the rotation times without rotating",
+			Secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              GrafanaProxySecretName,
+					CreationTimestamp: metav1.NewTime(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)),
+				},
+				Data: map[string][]byte{"session_secret": []byte("old")},
+			},
+			ExpectedRotated:     []string{},
+			ExpectedData:        map[string][]byte{"session_secret": []byte("old")},
+			ExpectedLastRotated: `{"session_secret":"2021-06-01T12:00:00Z"}`,
+		},
+		{
+			Name: "test only the keys of the policy are rotated",
+			Secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: RHSSOAdminCredentialsName,
+					Annotations: map[string]string{
+						LastRotatedAnnotation: `{"ADMIN_PASSWORD":"2021-01-01T00:00:00Z"}`,
+					},
+				},
+				Data: map[string][]byte{"ADMIN_USERNAME": []byte("admin"), "ADMIN_PASSWORD": []byte("old")},
+			},
+			Policy:              Policy{MaxAge: 90 * day, Keys: []string{"ADMIN_PASSWORD"}},
+			ExpectedRotated:     []string{"ADMIN_PASSWORD"},
+			ExpectedData:        map[string][]byte{"ADMIN_USERNAME": []byte("admin"), "ADMIN_PASSWORD": []byte("generated")},
+			ExpectedLastRotated: `{"ADMIN_PASSWORD":"2021-06-01T12:00:00Z"}`,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.Name, func(t *testing.T) {
+			rotated := Rotate(scenario.Secret, scenario.Policy, generate, now)
+			if !reflect.DeepEqual(rotated, scenario.ExpectedRotated) {
+				t.Errorf("expected rotated keys %v, got %v", scenario.ExpectedRotated, rotated)
+			}
+			if !reflect.DeepEqual(scenario.Secret.Data, scenario.ExpectedData) {
+				t.Errorf("expected data %v, got %v", scenario.ExpectedData, scenario.Secret.Data)
+			}
+			if lastRotated := scenario.Secret.Annotations[LastRotatedAnnotation]; lastRotated != scenario.ExpectedLastRotated {
+				t.Errorf("expected last rotated %s, got %s", scenario.ExpectedLastRotated, lastRotated)
+			}
+		})
+	}
+}
+
+func TestRotateWith(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: TenantAccountPasswordsName,
+			Annotations: map[string]string{
+				LastRotatedAnnotation: `{"deleted":"2021-01-01T00:00:00Z","failing":"2021-01-01T00:00:00Z","user":"2021-01-01T00:00:00Z"}`,
+			},
+		},
+		Data: map[string][]byte{"deleted": []byte("old"), "failing": []byte("old"), "user": []byte("old")},
+	}
+
+	rotated, err := RotateWith(secret, Policy{MaxAge: 90 * day}, func(key string) ([]byte, error) {
+		switch key {
+		case "failing":
+			return nil, fmt.Errorf("connection refused")
+		case "deleted":
+			return nil, nil
+		}
+		return []byte("generated"), nil
+	}, now)
+	if err == nil {
+		t.Errorf("expected an error for the key that failed to rotate")
+	}
+	if !reflect.DeepEqual(rotated, []string{"user"}) {
+		t.Errorf("expected only the user key to be rotated, got %v", rotated)
+	}
+	expectedData := map[string][]byte{"deleted": []byte("old"), "failing": []byte("old"), "user": []byte("generated")}
+	if !reflect.DeepEqual(secret.Data, expectedData) {
+		t.Errorf("expected data %v, got %v", expectedData, secret.Data)
+	}
+	expectedLastRotated := `{"deleted":"2021-01-01T00:00:00Z","failing":"2021-01-01T00:00:00Z","user":"2021-06-01T12:00:00Z"}`
+	if lastRotated := secret.Annotations[LastRotatedAnnotation]; lastRotated != expectedLastRotated {
+		t.Errorf("expected the keys that weren't rotated to keep their rotation time, got %s", lastRotated)
+	}
+}
+
+func TestPolicyFor(t *testing.T) {
+	installation := &integreatlyv1alpha1.RHMI{
+		Spec: integreatlyv1alpha1.RHMISpec{
+			SecretRotation: []integreatlyv1alpha1.SecretRotationPolicy{
+				{Secret: GrafanaProxySecretName, MaxAgeDays: 7},
+				{Secret: RHSSOAdminCredentialsName, MaxAgeDays: 30},
+			},
+		},
+	}
+
+	if policy := PolicyFor(installation, GrafanaProxySecretName); policy.MaxAge != 7*day {
+		t.Errorf("expected the overridden max age, got %v", policy.MaxAge)
+	}
+	if policy := PolicyFor(installation, RHSSOAdminCredentialsName); policy.MaxAge != 30*day || !reflect.DeepEqual(policy.Keys, []string{"ADMIN_PASSWORD"}) {
+		t.Errorf("expected the overridden max age to keep the keys of the policy, got %v", policy)
+	}
+	if policy := PolicyFor(installation, OauthClientSecretsName); policy.MaxAge != 90*day {
+		t.Errorf("expected the default max age, got %v", policy.MaxAge)
+	}
+	if policy := PolicyFor(installation, "unknown"); policy.MaxAge != 0 {
+		t.Errorf("expected no rotation of secrets without a policy, got %v", policy.MaxAge)
+	}
+}
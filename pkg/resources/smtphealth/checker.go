@@ -0,0 +1,136 @@
+// Package smtphealth checks the SMTP providers the installation is configured
+// with, by performing an authenticated handshake with each of them, and
+// selects the provider the alerts and 3scale send email with: the first
+// healthy provider of the SMTPSecret and SMTPFailoverSecrets of the RHMI CR
+package smtphealth
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+const (
+	// implicitTLSPort is the SMTP submission port that expects TLS from the
+	// start of the connection, instead of upgrading it with STARTTLS
+	implicitTLSPort = 465
+
+	DefaultCheckTimeout = 10 * time.Second
+)
+
+// Credentials of an SMTP provider, read from the fields of an SMTP secret
+type Credentials struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+}
+
+// CredentialsFromSecret reads the credentials from the data of an SMTP secret
+func CredentialsFromSecret(data map[string][]byte) (Credentials, error) {
+	credentials := Credentials{
+		Host:     string(data["host"]),
+		Username: string(data["username"]),
+		Password: string(data["password"]),
+	}
+	if credentials.Host == "" {
+		return credentials, fmt.Errorf("host is undefined in smtp secret")
+	}
+	port, err := strconv.Atoi(string(data["port"]))
+	if err != nil {
+		return credentials, fmt.Errorf("invalid port %q in smtp secret", string(data["port"]))
+	}
+	credentials.Port = port
+	return credentials, nil
+}
+
+//go:generate moq -out checker_moq.go . Checker
+
+// Checker checks the health of an SMTP provider
+type Checker interface {
+	// Check returns an error if the provider can't be connected to or
+	// rejects the credentials
+	Check(ctx context.Context, credentials Credentials) error
+}
+
+type handshakeChecker struct {
+	timeout time.Duration
+}
+
+var _ Checker = &handshakeChecker{}
+
+// NewChecker returns a checker that connects to the provider, upgrades the
+// connection to TLS when the provider supports it and authenticates with the
+// credentials, without sending any email
+func NewChecker(timeout time.Duration) Checker {
+	return &handshakeChecker{timeout: timeout}
+}
+
+func (c *handshakeChecker) Check(ctx context.Context, credentials Credentials) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	client, err := Dial(ctx, credentials)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	return client.Quit()
+}
+
+// Dial connects to the provider, upgrades the connection to TLS when the
+// provider supports it and authenticates with the credentials. The deadline
+// of ctx applies to the whole session. The caller closes the client
+func Dial(ctx context.Context, credentials Credentials) (*smtp.Client, error) {
+	address := net.JoinHostPort(credentials.Host, strconv.Itoa(credentials.Port))
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", address, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	tlsConfig := &tls.Config{ServerName: credentials.Host}
+	if credentials.Port == implicitTLSPort {
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	client, err := smtp.NewClient(conn, credentials.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to start smtp session with %s: %w", address, err)
+	}
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("failed to start tls with %s: %w", address, err)
+		}
+	}
+
+	if credentials.Username != "" {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			client.Close()
+			return nil, fmt.Errorf("%s does not support authentication", address)
+		}
+		// PlainAuth refuses to send the credentials over connections that
+		// aren't encrypted, except to localhost
+		auth := smtp.PlainAuth("", credentials.Username, credentials.Password, credentials.Host)
+		if err := client.Auth(auth); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("failed to authenticate with %s: %w", address, err)
+		}
+	}
+
+	return client, nil
+}
@@ -0,0 +1,134 @@
+package smtphealth
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSMTPServer accepts plain authentication with username and password,
+// and returns its port
+func fakeSMTPServer(t *testing.T, username, password string) int {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start fake smtp server: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveSMTP(conn, username, password)
+		}
+	}()
+
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func serveSMTP(conn net.Conn, username, password string) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	fmt.Fprint(conn, "220 localhost ESMTP fake\r\n")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.Fields(strings.TrimSpace(line))
+		if len(command) == 0 {
+			continue
+		}
+		switch strings.ToUpper(command[0]) {
+		case "EHLO":
+			fmt.Fprint(conn, "250-localhost\r\n250 AUTH PLAIN\r\n")
+		case "AUTH":
+			credentials, _ := base64.StdEncoding.DecodeString(command[len(command)-1])
+			if string(credentials) == "\x00"+username+"\x00"+password {
+				fmt.Fprint(conn, "235 2.7.0 Authentication successful\r\n")
+			} else {
+				fmt.Fprint(conn, "535 5.7.8 Authentication credentials invalid\r\n")
+			}
+		case "QUIT":
+			fmt.Fprint(conn, "221 2.0.0 Bye\r\n")
+			return
+		default:
+			fmt.Fprint(conn, "502 5.5.2 Command not recognized\r\n")
+		}
+	}
+}
+
+func TestHandshakeChecker_Check(t *testing.T) {
+	port := fakeSMTPServer(t, "apikey", "secret")
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to reserve port: %v", err)
+	}
+	closedPort := closed.Addr().(*net.TCPAddr).Port
+	closed.Close()
+
+	scenarios := []struct {
+		Name        string
+		Credentials Credentials
+		ExpectError bool
+	}{
+		{
+			Name:        "test valid credentials are accepted",
+			Credentials: Credentials{Host: "127.0.0.1", Port: port, Username: "apikey", Password: "secret"},
+		},
+		{
+			Name:        "test invalid credentials are rejected",
+			Credentials: Credentials{Host: "127.0.0.1", Port: port, Username: "apikey", Password: "expired"},
+			ExpectError: true,
+		},
+		{
+			Name:        "test unreachable provider is unhealthy",
+			Credentials: Credentials{Host: "127.0.0.1", Port: closedPort, Username: "apikey", Password: "secret"},
+			ExpectError: true,
+		},
+	}
+
+	checker := NewChecker(5 * time.Second)
+	for _, scenario := range scenarios {
+		t.Run(scenario.Name, func(t *testing.T) {
+			err := checker.Check(context.TODO(), scenario.Credentials)
+			if scenario.ExpectError && err == nil {
+				t.Fatal("expected error but got none")
+			}
+			if !scenario.ExpectError && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestCredentialsFromSecret(t *testing.T) {
+	credentials, err := CredentialsFromSecret(map[string][]byte{
+		"host":     []byte("smtp.example.com"),
+		"port":     []byte("587"),
+		"username": []byte("apikey"),
+		"password": []byte("secret"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := Credentials{Host: "smtp.example.com", Port: 587, Username: "apikey", Password: "secret"}
+	if credentials != expected {
+		t.Errorf("expected credentials %v, got %v", expected, credentials)
+	}
+
+	if _, err := CredentialsFromSecret(map[string][]byte{"host": []byte("smtp.example.com")}); err == nil {
+		t.Errorf("expected error for missing port")
+	}
+	if _, err := CredentialsFromSecret(map[string][]byte{"port": []byte("587")}); err == nil {
+		t.Errorf("expected error for missing host")
+	}
+}
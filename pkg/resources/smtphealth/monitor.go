@@ -0,0 +1,151 @@
+package smtphealth
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/integr8ly/integreatly-operator/pkg/metrics"
+	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/secretprovider"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+// DefaultCheckInterval is how often the SMTP providers are checked
+const DefaultCheckInterval = 5 * time.Minute
+
+var log = l.NewLoggerWithContext(l.Fields{l.ComponentLogContext: "smtphealth"})
+
+// Result of the check of an SMTP provider
+type Result struct {
+	Secret  string
+	Healthy bool
+	// Error of an unhealthy provider
+	Error string
+}
+
+// HealthMonitor checks the SMTP providers on a schedule, and sends an event
+// when their health changes, for a source.Channel watch of the installation
+// controller to pick the provider email is sent with
+type HealthMonitor struct {
+	provider secretprovider.Provider
+	checker  Checker
+	secrets  secretprovider.SecretNamesFunc
+	interval time.Duration
+	events   chan event.GenericEvent
+
+	mu      sync.RWMutex
+	results []Result
+}
+
+// NewHealthMonitor returns a monitor checking the SMTP providers of the
+// secrets named by secrets, in failover order, every interval
+func NewHealthMonitor(provider secretprovider.Provider, checker Checker, secrets secretprovider.SecretNamesFunc, interval time.Duration) *HealthMonitor {
+	return &HealthMonitor{
+		provider: provider,
+		checker:  checker,
+		secrets:  secrets,
+		interval: interval,
+		events:   make(chan event.GenericEvent),
+	}
+}
+
+// Events returns the channel the health change events are sent to. The
+// events are for a Secret named after the primary SMTP secret
+func (m *HealthMonitor) Events() <-chan event.GenericEvent {
+	return m.events
+}
+
+// Results returns the results of the last check, in failover order
+func (m *HealthMonitor) Results() []Result {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]Result(nil), m.results...)
+}
+
+// Start implements manager.Runnable
+func (m *HealthMonitor) Start(stop <-chan struct{}) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stop
+		cancel()
+	}()
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		if results, changed := m.Check(ctx); changed && len(results) > 0 {
+			secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: results[0].Secret}}
+			select {
+			case m.events <- event.GenericEvent{Meta: secret, Object: secret}:
+			case <-stop:
+				return nil
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return nil
+		}
+	}
+}
+
+// Check checks the SMTP providers and returns the results, and whether the
+// health of any of them changed since the previous check
+func (m *HealthMonitor) Check(ctx context.Context) ([]Result, bool) {
+	secrets, err := m.secrets(ctx)
+	if err != nil {
+		log.Error("failed to get the names of the smtp secrets to check", err)
+		return m.Results(), false
+	}
+
+	results := []Result{}
+	healthy := []bool{}
+	for _, secret := range secrets {
+		result := Result{Secret: secret, Healthy: true}
+		if err := m.checkSecret(ctx, secret); err != nil {
+			log.Warningf("SMTP provider is unhealthy", l.Fields{"secret": secret, "error": err.Error()})
+			result = Result{Secret: secret, Error: err.Error()}
+		}
+		results = append(results, result)
+		healthy = append(healthy, result.Healthy)
+	}
+	metrics.SetSMTPProvidersHealthy(secrets, healthy)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	changed := !reflect.DeepEqual(healthStates(m.results), healthStates(results))
+	m.results = results
+	return results, changed
+}
+
+func (m *HealthMonitor) checkSecret(ctx context.Context, secret string) error {
+	data, err := m.provider.GetSecret(ctx, secret)
+	if err != nil {
+		if secretprovider.IsNotFound(err) {
+			return fmt.Errorf("secret %s not found", secret)
+		}
+		return fmt.Errorf("failed to read secret %s: %w", secret, err)
+	}
+	credentials, err := CredentialsFromSecret(data)
+	if err != nil {
+		return err
+	}
+	return m.checker.Check(ctx, credentials)
+}
+
+// healthStates ignores the errors of the results, which can differ between
+// checks of a provider that stays unhealthy
+func healthStates(results []Result) map[string]bool {
+	states := map[string]bool{}
+	for _, result := range results {
+		states[result.Secret] = result.Healthy
+	}
+	return states
+}
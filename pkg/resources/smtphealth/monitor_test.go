@@ -0,0 +1,171 @@
+package smtphealth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/secretprovider"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func smtpSecret(name, host string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "redhat-rhoam-operator"},
+		Data: map[string][]byte{
+			"host":     []byte(host),
+			"port":     []byte("587"),
+			"username": []byte("apikey"),
+			"password": []byte("secret"),
+		},
+	}
+}
+
+func TestHealthMonitor_Check(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to build scheme: %v", err)
+	}
+	client := fakeclient.NewFakeClientWithScheme(scheme,
+		smtpSecret("redhat-rhoam-smtp", "primary.example.com"),
+		smtpSecret("redhat-rhoam-smtp-fallback", "fallback.example.com"),
+	)
+
+	unhealthyHosts := map[string]bool{}
+	checker := &CheckerMock{
+		CheckFunc: func(ctx context.Context, credentials Credentials) error {
+			if unhealthyHosts[credentials.Host] {
+				return errors.New("535 5.7.8 Authentication credentials invalid")
+			}
+			return nil
+		},
+	}
+	monitor := NewHealthMonitor(
+		secretprovider.NewKubernetesProvider(client, "redhat-rhoam-operator"),
+		checker,
+		func(ctx context.Context) ([]string, error) {
+			return []string{"redhat-rhoam-smtp", "redhat-rhoam-smtp-fallback", "redhat-rhoam-smtp-missing"}, nil
+		},
+		DefaultCheckInterval,
+	)
+
+	results, changed := monitor.Check(context.TODO())
+	if !changed {
+		t.Fatalf("expected the first check to report a change")
+	}
+	if !results[0].Healthy || !results[1].Healthy || results[2].Healthy {
+		t.Fatalf("unexpected results %v", results)
+	}
+
+	if _, changed := monitor.Check(context.TODO()); changed {
+		t.Fatalf("expected no change for providers with the same health")
+	}
+
+	unhealthyHosts["primary.example.com"] = true
+	results, changed = monitor.Check(context.TODO())
+	if !changed {
+		t.Fatalf("expected the primary provider failure to report a change")
+	}
+	if results[0].Healthy || results[0].Error == "" {
+		t.Errorf("expected the primary provider to be unhealthy, got %v", results[0])
+	}
+	if len(monitor.Results()) != 3 {
+		t.Errorf("expected the results of the last check to be stored, got %v", monitor.Results())
+	}
+}
+
+func TestSetStatus(t *testing.T) {
+	spec := integreatlyv1alpha1.RHMISpec{
+		SMTPSecret:          "redhat-rhoam-smtp",
+		SMTPFailoverSecrets: []string{"redhat-rhoam-smtp-fallback"},
+	}
+
+	scenarios := []struct {
+		Name                   string
+		Spec                   integreatlyv1alpha1.RHMISpec
+		Results                []Result
+		ExpectedActiveSecret   string
+		ExpectedConditionState metav1.ConditionStatus
+		ExpectedReason         string
+	}{
+		{
+			Name: "test primary provider is active when healthy",
+			Spec: spec,
+			Results: []Result{
+				{Secret: "redhat-rhoam-smtp", Healthy: true},
+				{Secret: "redhat-rhoam-smtp-fallback", Healthy: true},
+			},
+			ExpectedActiveSecret:   "redhat-rhoam-smtp",
+			ExpectedConditionState: metav1.ConditionTrue,
+			ExpectedReason:         ReasonPrimaryHealthy,
+		},
+		{
+			Name: "test failover provider is active when the primary is unhealthy",
+			Spec: spec,
+			Results: []Result{
+				{Secret: "redhat-rhoam-smtp", Error: "connection refused"},
+				{Secret: "redhat-rhoam-smtp-fallback", Healthy: true},
+			},
+			ExpectedActiveSecret:   "redhat-rhoam-smtp-fallback",
+			ExpectedConditionState: metav1.ConditionTrue,
+			ExpectedReason:         ReasonFailoverActive,
+		},
+		{
+			Name: "test primary provider is active when no provider is healthy",
+			Spec: spec,
+			Results: []Result{
+				{Secret: "redhat-rhoam-smtp", Error: "connection refused"},
+				{Secret: "redhat-rhoam-smtp-fallback", Error: "connection refused"},
+			},
+			ExpectedActiveSecret:   "redhat-rhoam-smtp",
+			ExpectedConditionState: metav1.ConditionFalse,
+			ExpectedReason:         ReasonNoHealthy,
+		},
+		{
+			Name: "test results of other secrets are ignored",
+			Spec: spec,
+			Results: []Result{
+				{Secret: "redhat-rhoam-smtp", Healthy: true},
+			},
+			ExpectedActiveSecret:   "redhat-rhoam-smtp",
+			ExpectedConditionState: metav1.ConditionUnknown,
+			ExpectedReason:         ReasonCheckPending,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.Name, func(t *testing.T) {
+			installation := &integreatlyv1alpha1.RHMI{Spec: scenario.Spec}
+			SetStatus(installation, scenario.Results)
+
+			if installation.Status.SMTPActiveSecret != scenario.ExpectedActiveSecret {
+				t.Errorf("expected active secret %s, got %s", scenario.ExpectedActiveSecret, installation.Status.SMTPActiveSecret)
+			}
+			if active := ActiveSecret(installation); active != scenario.ExpectedActiveSecret {
+				t.Errorf("expected email to be sent with %s, got %s", scenario.ExpectedActiveSecret, active)
+			}
+			condition := meta.FindStatusCondition(installation.Status.Conditions, ConditionType)
+			if condition == nil {
+				t.Fatalf("expected the %s condition to be set", ConditionType)
+			}
+			if condition.Status != scenario.ExpectedConditionState || condition.Reason != scenario.ExpectedReason {
+				t.Errorf("expected condition %s/%s, got %s/%s", scenario.ExpectedConditionState, scenario.ExpectedReason, condition.Status, condition.Reason)
+			}
+		})
+	}
+}
+
+func TestActiveSecret(t *testing.T) {
+	installation := &integreatlyv1alpha1.RHMI{
+		Spec:   integreatlyv1alpha1.RHMISpec{SMTPSecret: "redhat-rhoam-smtp"},
+		Status: integreatlyv1alpha1.RHMIStatus{SMTPActiveSecret: "redhat-rhoam-smtp-removed"},
+	}
+	if active := ActiveSecret(installation); active != "redhat-rhoam-smtp" {
+		t.Errorf("expected the primary secret for a removed failover secret, got %s", active)
+	}
+}
@@ -0,0 +1,106 @@
+package smtphealth
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ConditionType of the installation condition reporting the health of
+	// the SMTP provider email is sent with
+	ConditionType = "SMTPHealthy"
+
+	ReasonPrimaryHealthy = "PrimaryProviderHealthy"
+	ReasonFailoverActive = "FailoverProviderActive"
+	ReasonNoHealthy      = "NoHealthyProvider"
+	ReasonCheckPending   = "CheckPending"
+)
+
+// Secrets returns the names of the SMTP secrets of the installation, in
+// failover order
+func Secrets(installation *integreatlyv1alpha1.RHMI) []string {
+	secrets := []string{}
+	for _, secret := range append([]string{installation.Spec.SMTPSecret}, installation.Spec.SMTPFailoverSecrets...) {
+		if secret != "" {
+			secrets = append(secrets, secret)
+		}
+	}
+	return secrets
+}
+
+// ActiveSecret returns the name of the SMTP secret email is sent with. It's
+// the primary SMTP secret until the health of the providers is checked
+func ActiveSecret(installation *integreatlyv1alpha1.RHMI) string {
+	for _, secret := range Secrets(installation) {
+		if secret == installation.Status.SMTPActiveSecret {
+			return secret
+		}
+	}
+	return installation.Spec.SMTPSecret
+}
+
+// SetStatus sets the active SMTP secret of the installation to the first
+// healthy provider of results, and the SMTPHealthy condition. The primary
+// SMTP secret stays active when none of the providers is healthy. Results
+// of a check of other secrets than the ones of the installation are ignored
+func SetStatus(installation *integreatlyv1alpha1.RHMI, results []Result) {
+	secrets := Secrets(installation)
+	if len(secrets) == 0 {
+		installation.Status.SMTPActiveSecret = ""
+		meta.RemoveStatusCondition(&installation.Status.Conditions, ConditionType)
+		return
+	}
+
+	checked := make([]string, 0, len(results))
+	for _, result := range results {
+		checked = append(checked, result.Secret)
+	}
+	if !reflect.DeepEqual(secrets, checked) {
+		installation.Status.SMTPActiveSecret = ActiveSecret(installation)
+		meta.SetStatusCondition(&installation.Status.Conditions, metav1.Condition{
+			Type:    ConditionType,
+			Status:  metav1.ConditionUnknown,
+			Reason:  ReasonCheckPending,
+			Message: "The SMTP providers have not been checked yet",
+		})
+		return
+	}
+
+	condition := metav1.Condition{
+		Type:    ConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  ReasonNoHealthy,
+		Message: fmt.Sprintf("None of the SMTP providers is healthy: %s", unhealthyMessage(results)),
+	}
+	installation.Status.SMTPActiveSecret = installation.Spec.SMTPSecret
+	for priority, result := range results {
+		if !result.Healthy {
+			continue
+		}
+		installation.Status.SMTPActiveSecret = result.Secret
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = ReasonPrimaryHealthy
+		condition.Message = fmt.Sprintf("Email is sent with the primary SMTP provider %s", result.Secret)
+		if priority > 0 {
+			condition.Reason = ReasonFailoverActive
+			condition.Message = fmt.Sprintf("Email is sent with the failover SMTP provider %s: %s", result.Secret, unhealthyMessage(results[:priority]))
+		}
+		break
+	}
+	meta.SetStatusCondition(&installation.Status.Conditions, condition)
+}
+
+func unhealthyMessage(results []Result) string {
+	errors := []string{}
+	for _, result := range results {
+		if !result.Healthy {
+			errors = append(errors, fmt.Sprintf("%s: %s", result.Secret, result.Error))
+		}
+	}
+	return strings.Join(errors, "; ")
+}
@@ -0,0 +1,96 @@
+// Package tracing traces the reconciles of the installation with
+// OpenTelemetry. Spans are started for the reconcile, its stages, the products
+// and the requests they make, and exported over OTLP. Tracing is disabled
+// unless an endpoint is configured, the spans are then no-ops
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	tracerName  = "github.com/integr8ly/integreatly-operator"
+	serviceName = "rhmi-operator"
+
+	InstallationKey = attribute.Key("rhmi.installation")
+	StageKey        = attribute.Key("rhmi.stage")
+	ProductKey      = attribute.Key("rhmi.product")
+	PhaseKey        = attribute.Key("rhmi.phase")
+	NamespaceKey    = attribute.Key("rhmi.namespace")
+	PackageKey      = attribute.Key("rhmi.package")
+)
+
+// Config configures the export of the spans
+type Config struct {
+	// Endpoint is the host and port of the OTLP HTTP collector, tracing is
+	// disabled when empty
+	Endpoint string
+	// Insecure exports the spans over HTTP instead of HTTPS
+	Insecure bool
+	// SampleRatio is the ratio of the reconciles traced, between 0 and 1
+	SampleRatio float64
+}
+
+// Setup sets the tracer provider exporting the spans to the configured
+// endpoint. The returned function flushes the spans and stops the export, it
+// is a no-op when tracing is disabled
+func Setup(ctx context.Context, config Config) (func(context.Context) error, error) {
+	if config.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(config.Endpoint)}
+	if config.Insecure {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the OTLP exporter: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String(serviceName))),
+	)
+	SetProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// SetProvider sets the provider the spans are started with, tests set a
+// provider recording the spans in memory
+func SetProvider(provider trace.TracerProvider) {
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+}
+
+// Start starts a span named name, child of the span of ctx
+func Start(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// End sets the phase of span and records err, then ends the span
+func End(span trace.Span, phase integreatlyv1alpha1.StatusPhase, err error) {
+	if phase != "" {
+		span.SetAttributes(PhaseKey.String(string(phase)))
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else if phase == integreatlyv1alpha1.PhaseFailed {
+		span.SetStatus(codes.Error, "phase failed")
+	}
+	span.End()
+}
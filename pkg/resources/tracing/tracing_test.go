@@ -0,0 +1,143 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func setupExporter() *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	SetProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	return exporter
+}
+
+func getAttribute(span tracetest.SpanStub, key attribute.Key) string {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return kv.Value.Emit()
+		}
+	}
+	return ""
+}
+
+func TestEnd(t *testing.T) {
+	exporter := setupExporter()
+	defer SetProvider(trace.NewNoopTracerProvider())
+
+	tests := []struct {
+		Name           string
+		Phase          integreatlyv1alpha1.StatusPhase
+		Err            error
+		ExpectedStatus codes.Code
+	}{
+		{
+			Name:           "test completed phase",
+			Phase:          integreatlyv1alpha1.PhaseCompleted,
+			ExpectedStatus: codes.Unset,
+		},
+		{
+			Name:           "test failed phase",
+			Phase:          integreatlyv1alpha1.PhaseFailed,
+			ExpectedStatus: codes.Error,
+		},
+		{
+			Name:           "test error",
+			Phase:          integreatlyv1alpha1.PhaseInProgress,
+			Err:            errors.New("subscription not found"),
+			ExpectedStatus: codes.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			exporter.Reset()
+			_, span := Start(context.TODO(), "Product", ProductKey.String("rhsso"))
+			End(span, tt.Phase, tt.Err)
+
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("expected 1 span, got %d", len(spans))
+			}
+			if phase := getAttribute(spans[0], PhaseKey); phase != string(tt.Phase) {
+				t.Errorf("expected phase %s, got %s", tt.Phase, phase)
+			}
+			if product := getAttribute(spans[0], ProductKey); product != "rhsso" {
+				t.Errorf("expected product rhsso, got %s", product)
+			}
+			if spans[0].Status.Code != tt.ExpectedStatus {
+				t.Errorf("expected status %v, got %v", tt.ExpectedStatus, spans[0].Status.Code)
+			}
+		})
+	}
+}
+
+func TestTransport(t *testing.T) {
+	exporter := setupExporter()
+	defer SetProvider(trace.NewNoopTracerProvider())
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	productCtx, productSpan := Start(context.TODO(), "Product")
+	client := &http.Client{Transport: NewTransport(productCtx, nil)}
+
+	// the request has no context, as the requests of the 3scale client
+	request, err := http.NewRequest(http.MethodGet, server.URL+"/admin/api/accounts.json?access_token=secret", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	response.Body.Close()
+	productSpan.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	httpSpan := spans[0]
+	if httpSpan.Parent.SpanID() != productSpan.SpanContext().SpanID() {
+		t.Errorf("expected the request span to be a child of the product span")
+	}
+	if url := getAttribute(httpSpan, "http.url"); url != server.URL+"/admin/api/accounts.json" {
+		t.Errorf("expected the url without the query, got %s", url)
+	}
+	if code := getAttribute(httpSpan, "http.status_code"); code != "404" {
+		t.Errorf("expected status code 404, got %s", code)
+	}
+	if httpSpan.Status.Code != codes.Error {
+		t.Errorf("expected the span of the failed request to have an error status")
+	}
+	if traceparent == "" {
+		t.Errorf("expected the trace context to be sent")
+	}
+	if request.Header.Get("traceparent") != "" {
+		t.Errorf("expected the request of the caller not to be modified")
+	}
+}
+
+func TestSetupDisabled(t *testing.T) {
+	shutdown, err := Setup(context.TODO(), Config{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := shutdown(context.TODO()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
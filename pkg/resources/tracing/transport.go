@@ -0,0 +1,67 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type transport struct {
+	base http.RoundTripper
+	ctx  context.Context
+}
+
+// NewTransport returns a transport that starts a span for each request,
+// child of the span of the context of the request, or else of the span of
+// ctx. Clients such as the 3scale client don't set the context of their
+// requests, their spans are children of the product reconciling. The trace
+// context is sent in the traceparent header. The base transport defaults to
+// http.DefaultTransport
+func NewTransport(ctx context.Context, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return &transport{base: base, ctx: ctx}
+}
+
+func (t *transport) RoundTrip(request *http.Request) (*http.Response, error) {
+	parent := request.Context()
+	if !trace.SpanContextFromContext(parent).IsValid() {
+		parent = trace.ContextWithSpan(parent, trace.SpanFromContext(t.ctx))
+	}
+
+	// the query and credentials are left out of the URL, the 3scale access
+	// token is sent in the query
+	url := *request.URL
+	url.User = nil
+	url.RawQuery = ""
+	ctx, span := Start(parent, fmt.Sprintf("HTTP %s", request.Method),
+		semconv.HTTPMethodKey.String(request.Method),
+		semconv.HTTPURLKey.String(url.String()),
+		semconv.NetPeerNameKey.String(request.URL.Hostname()),
+	)
+	defer span.End()
+
+	// a round tripper must not modify the request
+	request = request.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(request.Header))
+
+	response, err := t.base.RoundTrip(request)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return response, err
+	}
+	span.SetAttributes(semconv.HTTPStatusCodeKey.Int(response.StatusCode))
+	span.SetStatus(semconv.SpanStatusFromHTTPStatusCode(response.StatusCode))
+	return response, nil
+}
@@ -0,0 +1,104 @@
+package user
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	usersv1 "github.com/openshift/api/user/v1"
+	"github.com/pkg/errors"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const rhmiConfigName = "rhmi-config"
+
+// GetUserRoleMappings returns the user role mappings configured in the
+// RHMIConfig of the given namespace, or nil if the RHMIConfig doesn't exist
+func GetUserRoleMappings(ctx context.Context, serverClient k8sclient.Client, ns string) ([]integreatlyv1alpha1.UserRoleMapping, error) {
+	rhmiConfig := &integreatlyv1alpha1.RHMIConfig{}
+	err := serverClient.Get(ctx, k8sclient.ObjectKey{Name: rhmiConfigName, Namespace: ns}, rhmiConfig)
+	if k8serr.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get rhmi config")
+	}
+
+	return rhmiConfig.Spec.UserRoles, nil
+}
+
+// MatchUserRoleMappings returns the mappings that apply to each user, keyed by
+// lower case user name. A user matches a mapping if it is a member of any of
+// its groups, or if one of its identities has all of its claims
+func MatchUserRoleMappings(ctx context.Context, serverClient k8sclient.Client, mappings []integreatlyv1alpha1.UserRoleMapping) (map[string][]integreatlyv1alpha1.UserRoleMapping, error) {
+	matches := map[string][]integreatlyv1alpha1.UserRoleMapping{}
+	if len(mappings) == 0 {
+		return matches, nil
+	}
+
+	groupMembers := map[string][]string{}
+	identities := &usersv1.IdentityList{}
+	identitiesListed := false
+
+	for _, mapping := range mappings {
+		users := map[string]bool{}
+
+		for _, groupName := range mapping.Groups {
+			members, ok := groupMembers[groupName]
+			if !ok {
+				group := &usersv1.Group{}
+				err := serverClient.Get(ctx, k8sclient.ObjectKey{Name: groupName}, group)
+				if err != nil && !k8serr.IsNotFound(err) {
+					return nil, errors.Wrapf(err, "could not get group %s", groupName)
+				}
+				members = group.Users
+				groupMembers[groupName] = members
+			}
+			for _, member := range members {
+				users[strings.ToLower(member)] = true
+			}
+		}
+
+		if len(mapping.Claims) > 0 {
+			if !identitiesListed {
+				if err := serverClient.List(ctx, identities); err != nil {
+					return nil, errors.Wrap(err, "could not list identities")
+				}
+				identitiesListed = true
+			}
+			for _, identity := range identities.Items {
+				if identity.User.Name != "" && identityHasClaims(identity, mapping.Claims) {
+					users[strings.ToLower(identity.User.Name)] = true
+				}
+			}
+		}
+
+		for userName := range users {
+			matches[userName] = append(matches[userName], mapping)
+		}
+	}
+
+	return matches, nil
+}
+
+// UserRoleMappingsInput returns a stable representation of the mappings, to
+// be included in the fingerprint of a user sync so that configuration changes
+// trigger a sync
+func UserRoleMappingsInput(mappings []integreatlyv1alpha1.UserRoleMapping) string {
+	data, err := json.Marshal(mappings)
+	if err != nil {
+		return ""
+	}
+	return "userRoles:" + string(data)
+}
+
+func identityHasClaims(identity usersv1.Identity, claims map[string]string) bool {
+	for key, value := range claims {
+		if identity.Extra[key] != value {
+			return false
+		}
+	}
+	return true
+}
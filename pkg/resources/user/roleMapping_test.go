@@ -0,0 +1,79 @@
+package user
+
+import (
+	"context"
+	"testing"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	userv1 "github.com/openshift/api/user/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetUserRoleMappings(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := integreatlyv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("Error creating build scheme")
+	}
+
+	mappings, err := GetUserRoleMappings(context.TODO(), fake.NewFakeClientWithScheme(scheme), "redhat-rhoam-operator")
+	if err != nil || mappings != nil {
+		t.Fatalf("expected no mappings when the rhmi config doesn't exist, got %v, %v", mappings, err)
+	}
+
+	client := fake.NewFakeClientWithScheme(scheme, &integreatlyv1alpha1.RHMIConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "rhmi-config", Namespace: "redhat-rhoam-operator"},
+		Spec: integreatlyv1alpha1.RHMIConfigSpec{
+			UserRoles: []integreatlyv1alpha1.UserRoleMapping{{Name: "admins", Groups: []string{"api-admins"}}},
+		},
+	})
+	mappings, err = GetUserRoleMappings(context.TODO(), client, "redhat-rhoam-operator")
+	if err != nil || len(mappings) != 1 {
+		t.Fatalf("expected the configured mapping, got %v, %v", mappings, err)
+	}
+}
+
+func TestMatchUserRoleMappings(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := userv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("Error creating build scheme")
+	}
+
+	client := fake.NewFakeClientWithScheme(scheme,
+		&userv1.Group{
+			ObjectMeta: metav1.ObjectMeta{Name: "api-admins"},
+			Users:      []string{"Admin"},
+		},
+		&userv1.Identity{
+			ObjectMeta: metav1.ObjectMeta{Name: "sso:finance"},
+			User:       corev1.ObjectReference{Name: "finance"},
+			Extra:      map[string]string{"department": "finance", "country": "ie"},
+		},
+		&userv1.Identity{
+			ObjectMeta: metav1.ObjectMeta{Name: "sso:sales"},
+			User:       corev1.ObjectReference{Name: "sales"},
+			Extra:      map[string]string{"department": "sales"},
+		},
+	)
+
+	matches, err := MatchUserRoleMappings(context.TODO(), client, []integreatlyv1alpha1.UserRoleMapping{
+		{Name: "admins", Groups: []string{"api-admins", "missing-group"}},
+		{Name: "finance", Claims: map[string]string{"department": "finance"}},
+		{Name: "finance-ie", Groups: []string{"api-admins"}, Claims: map[string]string{"department": "finance", "country": "ie"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(matches["admin"]) != 2 {
+		t.Fatalf("expected admin to match the group mappings, got %v", matches["admin"])
+	}
+	if len(matches["finance"]) != 2 {
+		t.Fatalf("expected finance to match the claim mappings, got %v", matches["finance"])
+	}
+	if _, ok := matches["sales"]; ok {
+		t.Fatalf("expected sales not to match any mapping")
+	}
+}
@@ -0,0 +1,378 @@
+// Package usersync keeps track of the OpenShift users that have been synced
+// by the user controller, so that the product reconcilers syncing users to
+// RHSSO and 3scale only sync the users that changed since their last run
+package usersync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	usersv1 "github.com/openshift/api/user/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// StateConfigMapName is the name of the ConfigMap holding the revision and
+	// the consumers. The users are stored in ShardCount ConfigMaps named after
+	// it, so that the state doesn't reach the ConfigMap size limit on clusters
+	// with many users
+	StateConfigMapName = "user-sync-state"
+	stateKey           = "state.json"
+	usersKey           = "users.json"
+
+	// ShardCount is the number of ConfigMaps the users are spread across. A
+	// shard holds around 7000 users before reaching the 1MiB limit
+	ShardCount = 16
+
+	// FullResyncPeriod is the maximum time a consumer can sync only the
+	// changed users for, so that changes made outside of OpenShift are
+	// eventually reverted. Removed users are kept for as long so that the
+	// consumers syncing only the changed users see them
+	FullResyncPeriod = 30 * time.Minute
+
+	ConsumerThreeScale = "3scale"
+	ConsumerRHSSOUser  = "rhssouser"
+)
+
+// State is the persisted sync state. Users and Revision are owned by the user
+// controller, Consumers by the product reconcilers
+type State struct {
+	// Revision is incremented every time a user is added, changed or removed
+	Revision  int64
+	Users     map[string]UserState
+	Consumers map[string]ConsumerState
+
+	// dirty are the shards with users changed since the state was loaded or
+	// last saved
+	dirty map[string]bool
+}
+
+type UserState struct {
+	Hash     string      `json:"hash"`
+	SyncedAt metav1.Time `json:"syncedAt"`
+	// Revision is the state revision of the last change of the user
+	Revision int64 `json:"revision"`
+	// Removed is set when the user is deleted, until FullResyncPeriod has
+	// elapsed
+	Removed bool `json:"removed,omitempty"`
+}
+
+type ConsumerState struct {
+	Fingerprint string      `json:"fingerprint"`
+	SyncedAt    metav1.Time `json:"syncedAt"`
+	// Revision is the state revision the consumer last synced
+	Revision int64 `json:"revision"`
+}
+
+// stateIndex is the content of the StateConfigMapName ConfigMap
+type stateIndex struct {
+	Revision  int64                    `json:"revision"`
+	Consumers map[string]ConsumerState `json:"consumers"`
+}
+
+// SyncPlan is the set of users a consumer has to sync
+type SyncPlan struct {
+	// Full is set when all the users have to be synced
+	Full bool
+	// Revision is the state revision the plan was made at, to be recorded
+	// with MarkSynced once the users are synced
+	Revision int64
+	// Changed and Removed are the lower cased names of the users added or
+	// changed, and removed, since the last sync of the consumer
+	Changed map[string]bool
+	Removed map[string]bool
+}
+
+// Skip returns true if no user has to be synced
+func (p SyncPlan) Skip() bool {
+	return !p.Full && len(p.Changed) == 0 && len(p.Removed) == 0
+}
+
+// Includes returns true if the user has to be synced
+func (p SyncPlan) Includes(userName string) bool {
+	name := strings.ToLower(userName)
+	return p.Full || p.Changed[name] || p.Removed[name]
+}
+
+func NewState() *State {
+	return &State{
+		Users:     map[string]UserState{},
+		Consumers: map[string]ConsumerState{},
+		dirty:     map[string]bool{},
+	}
+}
+
+// SetUser records the hash of a synced user, incrementing the revision if the
+// hash changed. It returns true if the user changed
+func (s *State) SetUser(name, hash string, now time.Time) bool {
+	if existing, ok := s.Users[name]; ok && !existing.Removed && existing.Hash == hash {
+		return false
+	}
+	s.Revision++
+	s.Users[name] = UserState{Hash: hash, SyncedAt: metav1.NewTime(now), Revision: s.Revision}
+	s.dirty[shardName(name)] = true
+	return true
+}
+
+// RemoveUser marks a deleted user as removed, incrementing the revision if
+// the user was known. It returns true if the user was removed
+func (s *State) RemoveUser(name string, now time.Time) bool {
+	if existing, ok := s.Users[name]; !ok || existing.Removed {
+		return false
+	}
+	s.Revision++
+	s.Users[name] = UserState{SyncedAt: metav1.NewTime(now), Revision: s.Revision, Removed: true}
+	s.dirty[shardName(name)] = true
+	return true
+}
+
+// Plan returns the users the consumer has to sync. All the users are synced
+// if the consumer never synced, if the fingerprint of its other inputs
+// changed, or if its last sync is older than FullResyncPeriod. Otherwise only
+// the users changed since its last synced revision are
+func (s *State) Plan(consumer, fingerprint string, now time.Time) SyncPlan {
+	plan := SyncPlan{
+		Revision: s.Revision,
+		Changed:  map[string]bool{},
+		Removed:  map[string]bool{},
+	}
+
+	existing, ok := s.Consumers[consumer]
+	if !ok || existing.Fingerprint != fingerprint || now.Sub(existing.SyncedAt.Time) > FullResyncPeriod {
+		plan.Full = true
+		return plan
+	}
+
+	for name, user := range s.Users {
+		if user.Revision <= existing.Revision {
+			continue
+		}
+		if user.Removed {
+			plan.Removed[strings.ToLower(name)] = true
+		} else {
+			plan.Changed[strings.ToLower(name)] = true
+		}
+	}
+	return plan
+}
+
+// Fingerprint returns the fingerprint of the inputs of a consumer sync other
+// than the users, whose changes are tracked by the state revision
+func Fingerprint(inputs ...string) string {
+	sorted := append([]string{}, inputs...)
+	sort.Strings(sorted)
+	return hash(sorted...)
+}
+
+// UserHash returns a hash of the user fields that are relevant to the user
+// sync: its identities and the groups it belongs to
+func UserHash(user *usersv1.User, identities []usersv1.Identity, groups []string) string {
+	parts := []string{"user:" + user.Name, "uid:" + string(user.UID), "fullName:" + user.FullName}
+	for _, identity := range identities {
+		parts = append(parts, "identity:"+identity.ProviderName+":"+identity.ProviderUserName)
+		for key, value := range identity.Extra {
+			parts = append(parts, "extra:"+identity.Name+":"+key+"="+value)
+		}
+	}
+	for _, group := range groups {
+		parts = append(parts, "group:"+group)
+	}
+	sort.Strings(parts[3:])
+
+	return hash(parts...)
+}
+
+// shardName returns the name of the ConfigMap the user is stored in
+func shardName(userName string) string {
+	sum := sha256.Sum256([]byte(userName))
+	return shardConfigMapName(int(sum[0] % ShardCount))
+}
+
+func shardConfigMapName(shard int) string {
+	return fmt.Sprintf("%s-%x", StateConfigMapName, shard)
+}
+
+// GetState returns the persisted state in the given namespace, or an empty
+// state if it hasn't been persisted yet
+func GetState(ctx context.Context, serverClient k8sclient.Client, ns string) (*State, error) {
+	state := NewState()
+
+	// the revision is read before the users, as the users are saved first.
+	// Users changed after the revision are planned again on the next sync
+	index, _, err := getIndex(ctx, serverClient, ns)
+	if err != nil {
+		return nil, err
+	}
+	state.Revision = index.Revision
+	state.Consumers = index.Consumers
+
+	for shard := 0; shard < ShardCount; shard++ {
+		configMap := &corev1.ConfigMap{}
+		err := serverClient.Get(ctx, k8sclient.ObjectKey{Name: shardConfigMapName(shard), Namespace: ns}, configMap)
+		if k8serr.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		data, ok := configMap.Data[usersKey]
+		if !ok {
+			continue
+		}
+		users := map[string]UserState{}
+		if err := json.Unmarshal([]byte(data), &users); err != nil {
+			return nil, fmt.Errorf("failed to read user sync state shard %s: %w", configMap.Name, err)
+		}
+		for name, user := range users {
+			state.Users[name] = user
+		}
+	}
+
+	return state, nil
+}
+
+// SaveUsers persists the users and the revision of the state. Only the shards
+// with changed users are updated. Removed users older than FullResyncPeriod
+// are dropped first
+func SaveUsers(ctx context.Context, serverClient k8sclient.Client, ns string, state *State) error {
+	now := time.Now()
+	for name, user := range state.Users {
+		if user.Removed && now.Sub(user.SyncedAt.Time) > FullResyncPeriod {
+			delete(state.Users, name)
+			state.dirty[shardName(name)] = true
+		}
+	}
+
+	shards := map[string]map[string]UserState{}
+	for shard := range state.dirty {
+		shards[shard] = map[string]UserState{}
+	}
+	for name, user := range state.Users {
+		if users, ok := shards[shardName(name)]; ok {
+			users[name] = user
+		}
+	}
+
+	for shard, users := range shards {
+		if err := saveShard(ctx, serverClient, ns, shard, users); err != nil {
+			return fmt.Errorf("failed to save user sync state shard %s: %w", shard, err)
+		}
+		delete(state.dirty, shard)
+	}
+
+	return updateIndex(ctx, serverClient, ns, func(index *stateIndex) {
+		index.Revision = state.Revision
+	})
+}
+
+// MarkSynced records that the consumer synced the given fingerprint and
+// revision
+func MarkSynced(ctx context.Context, serverClient k8sclient.Client, ns, consumer, fingerprint string, revision int64) error {
+	now := metav1.Now()
+	return updateIndex(ctx, serverClient, ns, func(index *stateIndex) {
+		index.Consumers[consumer] = ConsumerState{Fingerprint: fingerprint, SyncedAt: now, Revision: revision}
+	})
+}
+
+// saveShard replaces the users of a shard. The shards are only written by the
+// user controller, but the update is retried in case the cached version is
+// stale
+func saveShard(ctx context.Context, serverClient k8sclient.Client, ns, name string, users map[string]UserState) error {
+	data, err := json.Marshal(users)
+	if err != nil {
+		return err
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		configMap := &corev1.ConfigMap{}
+		err := serverClient.Get(ctx, k8sclient.ObjectKey{Name: name, Namespace: ns}, configMap)
+		if k8serr.IsNotFound(err) {
+			configMap = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: ns,
+				},
+				Data: map[string]string{usersKey: string(data)},
+			}
+			return serverClient.Create(ctx, configMap)
+		}
+		if err != nil {
+			return err
+		}
+
+		configMap.Data = map[string]string{usersKey: string(data)}
+		return serverClient.Update(ctx, configMap)
+	})
+}
+
+// updateIndex applies mutate to the persisted revision and consumers,
+// retrying on conflicts with concurrent updates
+func updateIndex(ctx context.Context, serverClient k8sclient.Client, ns string, mutate func(*stateIndex)) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		index, configMap, err := getIndex(ctx, serverClient, ns)
+		if err != nil {
+			return err
+		}
+
+		mutate(index)
+
+		data, err := json.Marshal(index)
+		if err != nil {
+			return err
+		}
+
+		if configMap == nil {
+			return serverClient.Create(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      StateConfigMapName,
+					Namespace: ns,
+				},
+				Data: map[string]string{stateKey: string(data)},
+			})
+		}
+		configMap.Data = map[string]string{stateKey: string(data)}
+		return serverClient.Update(ctx, configMap)
+	})
+}
+
+// getIndex returns the persisted revision and consumers, and the ConfigMap
+// they're stored in, which is nil if it doesn't exist yet
+func getIndex(ctx context.Context, serverClient k8sclient.Client, ns string) (*stateIndex, *corev1.ConfigMap, error) {
+	index := &stateIndex{Consumers: map[string]ConsumerState{}}
+
+	configMap := &corev1.ConfigMap{}
+	err := serverClient.Get(ctx, k8sclient.ObjectKey{Name: StateConfigMapName, Namespace: ns}, configMap)
+	if k8serr.IsNotFound(err) {
+		return index, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if data, ok := configMap.Data[stateKey]; ok {
+		if err := json.Unmarshal([]byte(data), index); err != nil {
+			return nil, nil, err
+		}
+	}
+	if index.Consumers == nil {
+		index.Consumers = map[string]ConsumerState{}
+	}
+
+	return index, configMap, nil
+}
+
+func hash(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,232 @@
+package usersync
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	usersv1 "github.com/openshift/api/user/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testNamespace = "redhat-rhoam-operator"
+
+func TestState_SetUser(t *testing.T) {
+	state := NewState()
+	now := time.Now()
+
+	if !state.SetUser("test-user", "hash", now) {
+		t.Fatal("expected new user to be reported as changed")
+	}
+	if state.SetUser("test-user", "hash", now) {
+		t.Fatal("expected user with the same hash to be reported as unchanged")
+	}
+	if !state.SetUser("test-user", "new-hash", now) {
+		t.Fatal("expected user with a new hash to be reported as changed")
+	}
+	if state.Revision != 2 || state.Users["test-user"].Revision != 2 {
+		t.Fatalf("expected revision 2, got %d", state.Revision)
+	}
+
+	if !state.RemoveUser("test-user", now) {
+		t.Fatal("expected known user to be removed")
+	}
+	if state.RemoveUser("test-user", now) {
+		t.Fatal("expected removed user not to be removed again")
+	}
+	if !state.Users["test-user"].Removed || state.Revision != 3 {
+		t.Fatalf("expected user removed at revision 3, got %v at revision %d", state.Users["test-user"], state.Revision)
+	}
+
+	if !state.SetUser("test-user", "new-hash", now) {
+		t.Fatal("expected removed user with the same hash to be reported as changed")
+	}
+}
+
+func TestState_Plan(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		Name            string
+		Consumers       map[string]ConsumerState
+		Fingerprint     string
+		ExpectedFull    bool
+		ExpectedChanged map[string]bool
+		ExpectedRemoved map[string]bool
+	}{
+		{
+			Name:         "test full sync when the consumer never synced",
+			Consumers:    map[string]ConsumerState{},
+			Fingerprint:  "fingerprint",
+			ExpectedFull: true,
+		},
+		{
+			Name: "test full sync when the fingerprint changed",
+			Consumers: map[string]ConsumerState{
+				ConsumerThreeScale: {Fingerprint: "old", SyncedAt: metav1.NewTime(now), Revision: 3},
+			},
+			Fingerprint:  "fingerprint",
+			ExpectedFull: true,
+		},
+		{
+			Name: "test full sync after the full resync period",
+			Consumers: map[string]ConsumerState{
+				ConsumerThreeScale: {Fingerprint: "fingerprint", SyncedAt: metav1.NewTime(now.Add(-FullResyncPeriod - time.Minute)), Revision: 3},
+			},
+			Fingerprint:  "fingerprint",
+			ExpectedFull: true,
+		},
+		{
+			Name: "test users changed since the last synced revision are planned",
+			Consumers: map[string]ConsumerState{
+				ConsumerThreeScale: {Fingerprint: "fingerprint", SyncedAt: metav1.NewTime(now.Add(-time.Minute)), Revision: 1},
+			},
+			Fingerprint:     "fingerprint",
+			ExpectedChanged: map[string]bool{"changed-user": true},
+			ExpectedRemoved: map[string]bool{"removed-user": true},
+		},
+		{
+			Name: "test sync skipped when the last revision was synced",
+			Consumers: map[string]ConsumerState{
+				ConsumerThreeScale: {Fingerprint: "fingerprint", SyncedAt: metav1.NewTime(now.Add(-time.Minute)), Revision: 4},
+			},
+			Fingerprint:     "fingerprint",
+			ExpectedChanged: map[string]bool{},
+			ExpectedRemoved: map[string]bool{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			state := NewState()
+			state.SetUser("synced-user", "hash", now)
+			state.SetUser("Changed-User", "hash", now)
+			state.SetUser("removed-user", "hash", now)
+			state.RemoveUser("removed-user", now)
+			state.Consumers = tt.Consumers
+
+			plan := state.Plan(ConsumerThreeScale, tt.Fingerprint, now)
+			if plan.Revision != state.Revision {
+				t.Fatalf("expected plan at revision %d, got %d", state.Revision, plan.Revision)
+			}
+			if plan.Full != tt.ExpectedFull {
+				t.Fatalf("expected full sync to be %v, got %v", tt.ExpectedFull, plan.Full)
+			}
+			if tt.ExpectedFull {
+				if !plan.Includes("synced-user") {
+					t.Fatal("expected full sync to include all the users")
+				}
+				return
+			}
+			if !reflect.DeepEqual(plan.Changed, tt.ExpectedChanged) || !reflect.DeepEqual(plan.Removed, tt.ExpectedRemoved) {
+				t.Fatalf("expected changed %v and removed %v, got %v and %v", tt.ExpectedChanged, tt.ExpectedRemoved, plan.Changed, plan.Removed)
+			}
+			if plan.Skip() != (len(tt.ExpectedChanged) == 0 && len(tt.ExpectedRemoved) == 0) {
+				t.Fatalf("unexpected skip %v", plan.Skip())
+			}
+			if plan.Includes("synced-user") {
+				t.Fatal("expected synced user not to be included")
+			}
+		})
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	if Fingerprint("a", "b") != Fingerprint("b", "a") {
+		t.Fatal("expected fingerprint not to depend on the order of the inputs")
+	}
+	if Fingerprint("a", "b") == Fingerprint("a", "c") {
+		t.Fatal("expected fingerprint to change with the inputs")
+	}
+}
+
+func TestUserHash(t *testing.T) {
+	user := &usersv1.User{ObjectMeta: metav1.ObjectMeta{Name: "test-user", UID: "1234"}}
+	identities := []usersv1.Identity{
+		{ProviderName: "devsandbox", ProviderUserName: "test-user"},
+	}
+
+	hash := UserHash(user, identities, []string{"dedicated-admins", "other"})
+	if hash != UserHash(user, identities, []string{"other", "dedicated-admins"}) {
+		t.Fatal("expected hash not to depend on the order of the groups")
+	}
+	if hash == UserHash(user, identities, []string{"other"}) {
+		t.Fatal("expected hash to change with the group membership")
+	}
+	if hash == UserHash(user, nil, []string{"dedicated-admins", "other"}) {
+		t.Fatal("expected hash to change with the identities")
+	}
+}
+
+func TestSaveUsers(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	client := fakeclient.NewFakeClientWithScheme(scheme)
+	ctx := context.TODO()
+
+	state, err := GetState(ctx, client, testNamespace)
+	if err != nil {
+		t.Fatalf("unexpected error getting missing state: %v", err)
+	}
+	if state.Revision != 0 || len(state.Users) != 0 {
+		t.Fatal("expected empty state when it isn't persisted")
+	}
+
+	now := time.Now()
+	for i := 0; i < 100; i++ {
+		state.SetUser(fmt.Sprintf("test-user-%d", i), "hash", now)
+	}
+	state.RemoveUser("test-user-0", now)
+	state.Users["expired-user"] = UserState{SyncedAt: metav1.NewTime(now.Add(-FullResyncPeriod - time.Minute)), Removed: true}
+	if err := SaveUsers(ctx, client, testNamespace, state); err != nil {
+		t.Fatalf("unexpected error saving users: %v", err)
+	}
+	if err := MarkSynced(ctx, client, testNamespace, ConsumerThreeScale, "fingerprint", state.Revision); err != nil {
+		t.Fatalf("unexpected error marking consumer synced: %v", err)
+	}
+
+	configMaps := &corev1.ConfigMapList{}
+	if err := client.List(ctx, configMaps); err != nil {
+		t.Fatal(err)
+	}
+	if len(configMaps.Items) != ShardCount+1 {
+		t.Fatalf("expected the users to be spread across %d shards, got %d config maps", ShardCount, len(configMaps.Items))
+	}
+
+	persisted, err := GetState(ctx, client, testNamespace)
+	if err != nil {
+		t.Fatalf("unexpected error getting state: %v", err)
+	}
+	if persisted.Revision != 101 || len(persisted.Users) != 100 {
+		t.Fatalf("expected 100 users at revision 101, got %d users at revision %d", len(persisted.Users), persisted.Revision)
+	}
+	if _, ok := persisted.Users["expired-user"]; ok {
+		t.Fatal("expected removed user older than the full resync period to be dropped")
+	}
+	if !persisted.Users["test-user-0"].Removed {
+		t.Fatal("expected removed user to be persisted")
+	}
+	if !persisted.Plan(ConsumerThreeScale, "fingerprint", time.Now()).Skip() {
+		t.Fatal("expected persisted consumer to be synced")
+	}
+
+	persisted.SetUser("test-user-1", "new-hash", now)
+	if err := SaveUsers(ctx, client, testNamespace, persisted); err != nil {
+		t.Fatalf("unexpected error saving users: %v", err)
+	}
+	persisted, err = GetState(ctx, client, testNamespace)
+	if err != nil {
+		t.Fatalf("unexpected error getting state: %v", err)
+	}
+	plan := persisted.Plan(ConsumerThreeScale, "fingerprint", time.Now())
+	if plan.Full || !reflect.DeepEqual(plan.Changed, map[string]bool{"test-user-1": true}) {
+		t.Fatalf("expected only the changed user to be planned, got %v", plan)
+	}
+}
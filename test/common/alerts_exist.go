@@ -65,6 +65,12 @@ func rhmi2ExpectedRules(installationName string) []alertsTestRule {
 				fmt.Sprintf("%sUserSsoAvailability6hto3dErrorBudgetBurn", strings.ToUpper(titledName)),
 			},
 		},
+		{
+			File: ObservabilityNamespacePrefix + "user-sso-realm-drift-alerts.yaml",
+			Rules: []string{
+				"RHMIUserRhssoRealmSettingsDrift",
+			},
+		},
 		{
 			File: NamespacePrefix + "amq-online-backupjobs-exist-alerts.yaml",
 			Rules: []string{
@@ -315,6 +321,12 @@ func managedApiSpecificRules(installationName string) []alertsTestRule {
 				fmt.Sprintf("%sUserSsoAvailability6hto3dErrorBudgetBurn", strings.ToUpper(titledName)),
 			},
 		},
+		{
+			File: ObservabilityNamespacePrefix + "user-sso-realm-drift-alerts.yaml",
+			Rules: []string{
+				"RHMIUserRhssoRealmSettingsDrift",
+			},
+		},
 		{
 			File: ObservabilityNamespacePrefix + "marin3r-ksm-endpoint-alerts.yaml",
 			Rules: []string{
@@ -412,6 +424,13 @@ func managedApiSpecificRules(installationName string) []alertsTestRule {
 				"RHOAMInstallationControllerIsInReconcilingErrorState",
 			},
 		},
+		{
+			File: ObservabilityNamespacePrefix + "smtp-health-alerts.yaml",
+			Rules: []string{
+				"RHOAMSMTPProvidersUnhealthy",
+				"RHOAMSMTPFailoverActive",
+			},
+		},
 	}
 }
 
@@ -502,6 +521,13 @@ func mtManagedApiSpecificRules() []alertsTestRule {
 				"RHOAMApiUsageOverLimit",
 			},
 		},
+		{
+			File: ObservabilityNamespacePrefix + "smtp-health-alerts.yaml",
+			Rules: []string{
+				"RHOAMSMTPProvidersUnhealthy",
+				"RHOAMSMTPFailoverActive",
+			},
+		},
 	}
 }
 
@@ -663,6 +689,12 @@ func commonExpectedRules(installationName string) []alertsTestRule {
 				fmt.Sprintf("%sRhssoAvailability6hto3dErrorBudgetBurn", strings.ToUpper(titledName)),
 			},
 		},
+		{
+			File: ObservabilityNamespacePrefix + "rhsso-realm-drift-alerts.yaml",
+			Rules: []string{
+				"RHMIRhssoRealmSettingsDrift",
+			},
+		},
 		{
 			File: ObservabilityNamespacePrefix + "test-alerts.yaml",
 			Rules: []string{
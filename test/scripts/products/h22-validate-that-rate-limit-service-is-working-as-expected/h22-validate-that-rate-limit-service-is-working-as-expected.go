@@ -333,7 +333,7 @@ func createAPI(ctx context.Context, client k8sclient.Client, namespacePrefix, ba
 
 	threescaleClient := newThreescaleClient(installation)
 
-	accountID, err := threescaleClient.CreateAccount(accessToken,
+	accountID, err := threescaleClient.CreateAccount(ctx, accessToken,
 		baseName,
 		fmt.Sprintf("%s-user", baseName),
 	)
@@ -342,7 +342,8 @@ func createAPI(ctx context.Context, client k8sclient.Client, namespacePrefix, ba
 	}
 	fmt.Printf("  ✔️  Created Account ID: %s\n", accountID)
 
-	backendID, err := threescaleClient.CreateBackend(accessToken,
+	backendID, err := threescaleClient.CreateBackend(ctx, accessToken,
+		fmt.Sprintf("%s-backend", baseName),
 		fmt.Sprintf("%s-backend", baseName),
 		"https://echo-api.3scale.net:443",
 	)
@@ -351,7 +352,7 @@ func createAPI(ctx context.Context, client k8sclient.Client, namespacePrefix, ba
 	}
 	fmt.Printf("  ✔️  Created Backend ID: %d\n", backendID)
 
-	metricID, err := threescaleClient.CreateMetric(accessToken,
+	metricID, err := threescaleClient.CreateMetric(ctx, accessToken,
 		backendID,
 		fmt.Sprintf("%s-metric", baseName),
 		"hit",
@@ -362,7 +363,7 @@ func createAPI(ctx context.Context, client k8sclient.Client, namespacePrefix, ba
 
 	fmt.Printf("  ✔️  Created Metric ID: %d\n", metricID)
 
-	if err := threescaleClient.CreateBackendMappingRule(accessToken,
+	if err := threescaleClient.CreateBackendMappingRule(ctx, accessToken,
 		backendID,
 		metricID,
 		"GET",
@@ -373,13 +374,13 @@ func createAPI(ctx context.Context, client k8sclient.Client, namespacePrefix, ba
 	}
 	fmt.Println("  ✔️  Mapping rule created")
 
-	serviceID, err := threescaleClient.CreateService(accessToken,
+	serviceID, err := threescaleClient.CreateService(ctx, accessToken,
 		fmt.Sprintf("%s-api", baseName),
 		fmt.Sprintf("%s-api", baseName),
 	)
 	fmt.Printf("  ✔️  Created Service ID: %s\n", serviceID)
 
-	if err = threescaleClient.CreateBackendUsage(accessToken,
+	if err = threescaleClient.CreateBackendUsage(ctx, accessToken,
 		serviceID,
 		backendID,
 		"/",
@@ -388,7 +389,7 @@ func createAPI(ctx context.Context, client k8sclient.Client, namespacePrefix, ba
 	}
 	fmt.Println("  ✔️  Backend usage created")
 
-	applicationPlanID, err := threescaleClient.CreateApplicationPlan(accessToken,
+	applicationPlanID, err := threescaleClient.CreateApplicationPlan(ctx, accessToken,
 		serviceID,
 		fmt.Sprintf("%s-api-plan", baseName),
 	)
@@ -397,7 +398,7 @@ func createAPI(ctx context.Context, client k8sclient.Client, namespacePrefix, ba
 	}
 	fmt.Printf("  ✔️  Created Application Plan ID: %s\n", applicationPlanID)
 
-	userKey, err := threescaleClient.CreateApplication(accessToken,
+	userKey, err := threescaleClient.CreateApplication(ctx, accessToken,
 		accountID,
 		applicationPlanID,
 		fmt.Sprintf("%s-api-app", baseName),
@@ -410,14 +411,14 @@ func createAPI(ctx context.Context, client k8sclient.Client, namespacePrefix, ba
 
 	time.Sleep(5 * time.Second)
 
-	if err := threescaleClient.DeployProxy(accessToken, serviceID); err != nil {
+	if err := threescaleClient.DeployProxy(ctx, accessToken, serviceID); err != nil {
 		return nil, err
 	}
 	fmt.Println("  ✔️  Proxy deployed")
 
 	time.Sleep(5 * time.Second)
 
-	endpoint, err := threescaleClient.PromoteProxy(accessToken, serviceID, "sandbox", "production")
+	endpoint, err := threescaleClient.PromoteProxy(ctx, accessToken, serviceID, "sandbox", "production")
 	if err != nil {
 		return nil, err
 	}
@@ -445,19 +446,19 @@ func deleteAPI(cleanup bool, api *threescaleAPI, ctx context.Context, client k8s
 		api = mock3scaleAPI(ctx, client, namespacePrefix)
 	}
 
-	err := api.threescaleClient.DeleteService(api.accessToken, api.ServiceID)
+	err := api.threescaleClient.DeleteService(ctx, api.accessToken, api.ServiceID)
 	if err != nil && !strings.Contains(err.Error(), "Not Found") {
 		fmt.Printf("Failed to clean up API: failed to delete service: %v", err)
 	} else {
 		fmt.Println("  ✔️️  Deleted API service")
 	}
-	err = api.threescaleClient.DeleteBackend(api.accessToken, api.BackendID)
+	err = api.threescaleClient.DeleteBackend(ctx, api.accessToken, api.BackendID)
 	if err != nil && !strings.Contains(err.Error(), "Not Found") {
 		fmt.Printf("Failed to clean up API: failed to delete backend: %v", err)
 	} else {
 		fmt.Println("  ✔️️  Deleted API backend")
 	}
-	err = api.threescaleClient.DeleteAccount(api.accessToken, api.AccountID)
+	err = api.threescaleClient.DeleteAccount(ctx, api.accessToken, api.AccountID)
 	if err != nil && !strings.Contains(err.Error(), "Not Found") {
 		fmt.Printf("Failed to clean up API: failed to delete account: %v", err)
 	} else {